@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// ErrInvalidCatalogEntityOwnership is returned for catalog entities with malformed owner, on-call, runbooks or tier metadata
+var ErrInvalidCatalogEntityOwnership = errors.New("The catalog entity ownership metadata is invalid")
+
+const (
+	CatalogOwnerTypeGroup        = "group"
+	CatalogOwnerTypeOrganization = "organization"
+
+	// CatalogOwnerIdentityProvider marks the groups and organizations added to a pipeline because of its catalog owner, so only those are removed when the owner changes
+	CatalogOwnerIdentityProvider = "catalog"
+
+	// maxCatalogEntityDepth guards against parent cycles when walking up the catalog
+	maxCatalogEntityDepth = 10
+)
+
+// CatalogEntityOwnership is the ownership of a catalog entity, stored in the owner, onCall, runbooks and tier keys of its metadata
+type CatalogEntityOwnership struct {
+	Owner    *CatalogEntityOwner     `json:"owner,omitempty"`
+	OnCall   *CatalogEntityOnCall    `json:"onCall,omitempty"`
+	Runbooks []*CatalogEntityRunbook `json:"runbooks,omitempty"`
+	Tier     string                  `json:"tier,omitempty"`
+}
+
+// CatalogEntityOwner is the group or organization owning a catalog entity
+type CatalogEntityOwner struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// CatalogEntityOnCall is who to contact when something under a catalog entity breaks
+type CatalogEntityOnCall struct {
+	Name         string `json:"name,omitempty"`
+	Email        string `json:"email,omitempty"`
+	SlackChannel string `json:"slackChannel,omitempty"`
+	URL          string `json:"url,omitempty"`
+}
+
+// CatalogEntityRunbook links to documentation for operating what's under a catalog entity
+type CatalogEntityRunbook struct {
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url"`
+}
+
+// CatalogEntityPipeline is a pipeline linked to a catalog entity or one of its descendants, with its most recent builds and releases
+type CatalogEntityPipeline struct {
+	CatalogEntityID string                  `json:"catalogEntityID"`
+	Pipeline        *contracts.Pipeline     `json:"pipeline"`
+	Builds          []*contracts.Build      `json:"builds"`
+	Releases        []*contracts.Release    `json:"releases"`
+	Ownership       *CatalogEntityOwnership `json:"ownership,omitempty"`
+}
+
+// GetCatalogEntityOwnership returns the ownership set in the metadata of the catalog entity itself
+func GetCatalogEntityOwnership(entity *contracts.CatalogEntity) (*CatalogEntityOwnership, error) {
+
+	ownership := &CatalogEntityOwnership{}
+	if entity == nil || len(entity.Metadata) == 0 {
+		return ownership, nil
+	}
+
+	// round trip through json to get the typed keys out of the untyped metadata
+	bytes, err := json.Marshal(entity.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(bytes, ownership); err != nil {
+		return nil, fmt.Errorf("%v: %w", err.Error(), ErrInvalidCatalogEntityOwnership)
+	}
+
+	return ownership, nil
+}
+
+// ValidateCatalogEntityOwnership returns ErrInvalidCatalogEntityOwnership if the ownership metadata of the catalog entity is malformed
+func ValidateCatalogEntityOwnership(entity *contracts.CatalogEntity) error {
+
+	ownership, err := GetCatalogEntityOwnership(entity)
+	if err != nil {
+		return err
+	}
+
+	if ownership.Owner != nil {
+		if ownership.Owner.Type != CatalogOwnerTypeGroup && ownership.Owner.Type != CatalogOwnerTypeOrganization {
+			return fmt.Errorf("Owner type %v should be %v or %v: %w", ownership.Owner.Type, CatalogOwnerTypeGroup, CatalogOwnerTypeOrganization, ErrInvalidCatalogEntityOwnership)
+		}
+		if ownership.Owner.Name == "" {
+			return fmt.Errorf("Owner has no name: %w", ErrInvalidCatalogEntityOwnership)
+		}
+	}
+
+	if ownership.OnCall != nil && ownership.OnCall.URL != "" && !isAbsoluteURL(ownership.OnCall.URL) {
+		return fmt.Errorf("On-call url %v is not an absolute url: %w", ownership.OnCall.URL, ErrInvalidCatalogEntityOwnership)
+	}
+
+	for _, r := range ownership.Runbooks {
+		if r == nil || !isAbsoluteURL(r.URL) {
+			return fmt.Errorf("Runbook without absolute url: %w", ErrInvalidCatalogEntityOwnership)
+		}
+	}
+
+	return nil
+}
+
+// GetInheritedCatalogEntityOwnership returns the ownership of the catalog entity, with the fields it doesn't set itself taken from its closest ancestor that does;
+// getCatalogEntity returns the entity matching the filters or nil if there is none
+func GetInheritedCatalogEntityOwnership(entity *contracts.CatalogEntity, getCatalogEntity func(filters map[FilterType][]string) (*contracts.CatalogEntity, error)) (*CatalogEntityOwnership, error) {
+
+	inherited := &CatalogEntityOwnership{}
+
+	for depth := 0; entity != nil && depth < maxCatalogEntityDepth; depth++ {
+		ownership, err := GetCatalogEntityOwnership(entity)
+		if err != nil {
+			return nil, err
+		}
+
+		if inherited.Owner == nil {
+			inherited.Owner = ownership.Owner
+		}
+		if inherited.OnCall == nil {
+			inherited.OnCall = ownership.OnCall
+		}
+		if len(inherited.Runbooks) == 0 {
+			inherited.Runbooks = ownership.Runbooks
+		}
+		if inherited.Tier == "" {
+			inherited.Tier = ownership.Tier
+		}
+
+		if entity.ParentKey == "" || entity.ParentValue == "" {
+			break
+		}
+
+		entity, err = getCatalogEntity(map[FilterType][]string{
+			FilterEntity: {fmt.Sprintf("%v=%v", entity.ParentKey, entity.ParentValue)},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return inherited, nil
+}
+
+// GetPipelineOwnership returns the inherited ownership of the catalog entity the pipeline is linked to, or nil if it isn't linked to any
+func GetPipelineOwnership(repoSource, repoOwner, repoName string, getCatalogEntity func(filters map[FilterType][]string) (*contracts.CatalogEntity, error)) (*CatalogEntityOwnership, error) {
+
+	entity, err := getCatalogEntity(map[FilterType][]string{
+		FilterPipeline: {fmt.Sprintf("%v/%v/%v", repoSource, repoOwner, repoName)},
+	})
+	if err != nil || entity == nil {
+		return nil, err
+	}
+
+	return GetInheritedCatalogEntityOwnership(entity, getCatalogEntity)
+}
+
+// ApplyOwner adds the owning group or organization to the groups and organizations a pipeline, build or release belongs to, marked as coming from the catalog
+func (o *CatalogEntityOwnership) ApplyOwner(groups []*contracts.Group, organizations []*contracts.Organization) ([]*contracts.Group, []*contracts.Organization) {
+	if o == nil || o.Owner == nil {
+		return groups, organizations
+	}
+
+	switch o.Owner.Type {
+	case CatalogOwnerTypeGroup:
+		for _, g := range groups {
+			if g != nil && g.Name == o.Owner.Name {
+				return groups, organizations
+			}
+		}
+		groups = append(groups, &contracts.Group{
+			Name:       o.Owner.Name,
+			Identities: []*contracts.GroupIdentity{{Provider: CatalogOwnerIdentityProvider}},
+		})
+	case CatalogOwnerTypeOrganization:
+		for _, org := range organizations {
+			if org != nil && org.Name == o.Owner.Name {
+				return groups, organizations
+			}
+		}
+		organizations = append(organizations, &contracts.Organization{
+			Name:       o.Owner.Name,
+			Identities: []*contracts.OrganizationIdentity{{Provider: CatalogOwnerIdentityProvider}},
+		})
+	}
+
+	return groups, organizations
+}
+
+// RemoveOwner removes the owning group or organization from the groups and organizations of a pipeline, when the pipeline no longer inherits the owner;
+// a group or organization with the same name that was assigned to the pipeline by hand is kept
+func (o *CatalogEntityOwnership) RemoveOwner(groups []*contracts.Group, organizations []*contracts.Organization) ([]*contracts.Group, []*contracts.Organization) {
+	if o == nil || o.Owner == nil {
+		return groups, organizations
+	}
+
+	switch o.Owner.Type {
+	case CatalogOwnerTypeGroup:
+		remainingGroups := []*contracts.Group{}
+		for _, g := range groups {
+			if g == nil || g.Name != o.Owner.Name || !isGroupFromCatalog(g) {
+				remainingGroups = append(remainingGroups, g)
+			}
+		}
+		groups = remainingGroups
+	case CatalogOwnerTypeOrganization:
+		remainingOrganizations := []*contracts.Organization{}
+		for _, org := range organizations {
+			if org == nil || org.Name != o.Owner.Name || !isOrganizationFromCatalog(org) {
+				remainingOrganizations = append(remainingOrganizations, org)
+			}
+		}
+		organizations = remainingOrganizations
+	}
+
+	return groups, organizations
+}
+
+func isGroupFromCatalog(group *contracts.Group) bool {
+	for _, i := range group.Identities {
+		if i != nil && i.Provider == CatalogOwnerIdentityProvider {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isOrganizationFromCatalog(organization *contracts.Organization) bool {
+	for _, i := range organization.Identities {
+		if i != nil && i.Provider == CatalogOwnerIdentityProvider {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasSameOwner returns true if both ownerships have the same owner or neither has one
+func (o *CatalogEntityOwnership) HasSameOwner(other *CatalogEntityOwnership) bool {
+	var owner, otherOwner *CatalogEntityOwner
+	if o != nil {
+		owner = o.Owner
+	}
+	if other != nil {
+		otherOwner = other.Owner
+	}
+
+	if owner == nil || otherOwner == nil {
+		return owner == otherOwner
+	}
+
+	return *owner == *otherOwner
+}
+
+// CatalogEntityChangeAffectsOwners returns true if changing a catalog entity from before to after can change the owner of linked pipelines; before is nil for new entities and after is nil for deleted ones
+func CatalogEntityChangeAffectsOwners(before, after *contracts.CatalogEntity) bool {
+	if before == nil || after == nil {
+		// creating or deleting an entity can change what its existing descendants inherit
+		return true
+	}
+
+	ownershipBefore, errBefore := GetCatalogEntityOwnership(before)
+	ownershipAfter, errAfter := GetCatalogEntityOwnership(after)
+	if errBefore != nil || errAfter != nil {
+		return true
+	}
+
+	return !ownershipBefore.HasSameOwner(ownershipAfter) ||
+		before.LinkedPipeline != after.LinkedPipeline ||
+		before.Key != after.Key || before.Value != after.Value ||
+		before.ParentKey != after.ParentKey || before.ParentValue != after.ParentValue
+}
+
+// GetEnvironmentVariables returns the ownership as global environment variables, so notification stages can reach the owner and on-call contact
+func (o *CatalogEntityOwnership) GetEnvironmentVariables() map[string]string {
+
+	envvars := map[string]string{}
+	if o == nil {
+		return envvars
+	}
+
+	if o.Owner != nil {
+		envvars["ESTAFETTE_OWNER_TYPE"] = o.Owner.Type
+		envvars["ESTAFETTE_OWNER_NAME"] = o.Owner.Name
+	}
+	if o.OnCall != nil {
+		setIfNotEmpty(envvars, "ESTAFETTE_ONCALL_NAME", o.OnCall.Name)
+		setIfNotEmpty(envvars, "ESTAFETTE_ONCALL_EMAIL", o.OnCall.Email)
+		setIfNotEmpty(envvars, "ESTAFETTE_ONCALL_SLACK_CHANNEL", o.OnCall.SlackChannel)
+		setIfNotEmpty(envvars, "ESTAFETTE_ONCALL_URL", o.OnCall.URL)
+	}
+	if len(o.Runbooks) > 0 {
+		envvars["ESTAFETTE_RUNBOOK_URL"] = o.Runbooks[0].URL
+	}
+	setIfNotEmpty(envvars, "ESTAFETTE_TIER", o.Tier)
+
+	return envvars
+}
+
+func setIfNotEmpty(envvars map[string]string, name, value string) {
+	if value != "" {
+		envvars[name] = value
+	}
+}
+
+func isAbsoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
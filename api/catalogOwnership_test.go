@@ -0,0 +1,326 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCatalogEntityOwnership(t *testing.T) {
+
+	t.Run("ReturnsNilForEntityWithoutOwnership", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Key: "team", Value: "estafette", Metadata: map[string]interface{}{"description": "ci"}}
+
+		// act
+		err := ValidateCatalogEntityOwnership(entity)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsNilForValidOwnership", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{
+			Key:   "team",
+			Value: "estafette",
+			Metadata: map[string]interface{}{
+				"owner":    map[string]interface{}{"type": "group", "name": "team-estafette"},
+				"onCall":   map[string]interface{}{"name": "Estafette on-call", "url": "https://pagerduty.com/schedules/estafette"},
+				"runbooks": []interface{}{map[string]interface{}{"title": "Restart", "url": "https://wiki.estafette.io/runbooks/restart"}},
+				"tier":     "1",
+			},
+		}
+
+		// act
+		err := ValidateCatalogEntityOwnership(entity)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorForUnknownOwnerType", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Metadata: map[string]interface{}{"owner": map[string]interface{}{"type": "user", "name": "me@estafette.io"}}}
+
+		// act
+		err := ValidateCatalogEntityOwnership(entity)
+
+		assert.True(t, errors.Is(err, ErrInvalidCatalogEntityOwnership))
+	})
+
+	t.Run("ReturnsErrorForOwnerWithoutName", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Metadata: map[string]interface{}{"owner": map[string]interface{}{"type": "organization"}}}
+
+		// act
+		err := ValidateCatalogEntityOwnership(entity)
+
+		assert.True(t, errors.Is(err, ErrInvalidCatalogEntityOwnership))
+	})
+
+	t.Run("ReturnsErrorForRunbookWithRelativeURL", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Metadata: map[string]interface{}{"runbooks": []interface{}{map[string]interface{}{"url": "runbooks/restart"}}}}
+
+		// act
+		err := ValidateCatalogEntityOwnership(entity)
+
+		assert.True(t, errors.Is(err, ErrInvalidCatalogEntityOwnership))
+	})
+
+	t.Run("ReturnsErrorForMalformedOwner", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Metadata: map[string]interface{}{"owner": "team-estafette"}}
+
+		// act
+		err := ValidateCatalogEntityOwnership(entity)
+
+		assert.True(t, errors.Is(err, ErrInvalidCatalogEntityOwnership))
+	})
+}
+
+func TestGetInheritedCatalogEntityOwnership(t *testing.T) {
+
+	entities := map[string]*contracts.CatalogEntity{
+		"organization=estafette": {
+			Key:   "organization",
+			Value: "estafette",
+			Metadata: map[string]interface{}{
+				"owner":  map[string]interface{}{"type": "organization", "name": "estafette"},
+				"onCall": map[string]interface{}{"email": "oncall@estafette.io"},
+			},
+		},
+		"team=ci": {
+			ParentKey:   "organization",
+			ParentValue: "estafette",
+			Key:         "team",
+			Value:       "ci",
+			Metadata: map[string]interface{}{
+				"owner": map[string]interface{}{"type": "group", "name": "team-ci"},
+			},
+		},
+		"service=estafette-ci-api": {
+			ParentKey:      "team",
+			ParentValue:    "ci",
+			Key:            "service",
+			Value:          "estafette-ci-api",
+			LinkedPipeline: "github.com/estafette/estafette-ci-api",
+			Metadata: map[string]interface{}{
+				"tier": "1",
+			},
+		},
+	}
+
+	getCatalogEntity := func(filters map[FilterType][]string) (*contracts.CatalogEntity, error) {
+		if values, ok := filters[FilterEntity]; ok {
+			return entities[values[0]], nil
+		}
+		if values, ok := filters[FilterPipeline]; ok {
+			for _, e := range entities {
+				if e.LinkedPipeline == values[0] {
+					return e, nil
+				}
+			}
+		}
+		return nil, nil
+	}
+
+	t.Run("TakesEachFieldFromClosestAncestorSettingIt", func(t *testing.T) {
+
+		// act
+		ownership, err := GetInheritedCatalogEntityOwnership(entities["service=estafette-ci-api"], getCatalogEntity)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "1", ownership.Tier)
+		if assert.NotNil(t, ownership.Owner) {
+			assert.Equal(t, CatalogOwnerTypeGroup, ownership.Owner.Type)
+			assert.Equal(t, "team-ci", ownership.Owner.Name)
+		}
+		if assert.NotNil(t, ownership.OnCall) {
+			assert.Equal(t, "oncall@estafette.io", ownership.OnCall.Email)
+		}
+	})
+
+	t.Run("ReturnsOwnershipOfCatalogEntityLinkedToPipeline", func(t *testing.T) {
+
+		// act
+		ownership, err := GetPipelineOwnership("github.com", "estafette", "estafette-ci-api", getCatalogEntity)
+
+		assert.Nil(t, err)
+		if assert.NotNil(t, ownership) && assert.NotNil(t, ownership.Owner) {
+			assert.Equal(t, "team-ci", ownership.Owner.Name)
+		}
+	})
+
+	t.Run("ReturnsNilForPipelineNotLinkedToCatalogEntity", func(t *testing.T) {
+
+		// act
+		ownership, err := GetPipelineOwnership("github.com", "estafette", "estafette-ci-web", getCatalogEntity)
+
+		assert.Nil(t, err)
+		assert.Nil(t, ownership)
+	})
+}
+
+func TestApplyOwner(t *testing.T) {
+
+	t.Run("AddsOwningGroupIfMissing", func(t *testing.T) {
+
+		ownership := &CatalogEntityOwnership{Owner: &CatalogEntityOwner{Type: CatalogOwnerTypeGroup, Name: "team-ci"}}
+
+		// act
+		groups, organizations := ownership.ApplyOwner([]*contracts.Group{{Name: "team-web"}}, nil)
+
+		assert.Equal(t, 2, len(groups))
+		assert.Equal(t, "team-ci", groups[1].Name)
+		assert.Equal(t, 0, len(organizations))
+	})
+
+	t.Run("DoesNotDuplicateOwningOrganization", func(t *testing.T) {
+
+		ownership := &CatalogEntityOwnership{Owner: &CatalogEntityOwner{Type: CatalogOwnerTypeOrganization, Name: "estafette"}}
+
+		// act
+		_, organizations := ownership.ApplyOwner(nil, []*contracts.Organization{{Name: "estafette"}})
+
+		assert.Equal(t, 1, len(organizations))
+	})
+
+	t.Run("LeavesGroupsAndOrganizationsUnchangedForNilOwnership", func(t *testing.T) {
+
+		var ownership *CatalogEntityOwnership
+
+		// act
+		groups, organizations := ownership.ApplyOwner([]*contracts.Group{{Name: "team-web"}}, nil)
+
+		assert.Equal(t, 1, len(groups))
+		assert.Equal(t, 0, len(organizations))
+	})
+}
+
+func TestGetEnvironmentVariables(t *testing.T) {
+
+	t.Run("ReturnsOwnerOnCallRunbookAndTier", func(t *testing.T) {
+
+		ownership := &CatalogEntityOwnership{
+			Owner:    &CatalogEntityOwner{Type: CatalogOwnerTypeGroup, Name: "team-ci"},
+			OnCall:   &CatalogEntityOnCall{SlackChannel: "#ci-oncall"},
+			Runbooks: []*CatalogEntityRunbook{{URL: "https://wiki.estafette.io/runbooks/restart"}},
+			Tier:     "1",
+		}
+
+		// act
+		envvars := ownership.GetEnvironmentVariables()
+
+		assert.Equal(t, "group", envvars["ESTAFETTE_OWNER_TYPE"])
+		assert.Equal(t, "team-ci", envvars["ESTAFETTE_OWNER_NAME"])
+		assert.Equal(t, "#ci-oncall", envvars["ESTAFETTE_ONCALL_SLACK_CHANNEL"])
+		assert.Equal(t, "https://wiki.estafette.io/runbooks/restart", envvars["ESTAFETTE_RUNBOOK_URL"])
+		assert.Equal(t, "1", envvars["ESTAFETTE_TIER"])
+		_, hasOnCallEmail := envvars["ESTAFETTE_ONCALL_EMAIL"]
+		assert.False(t, hasOnCallEmail)
+	})
+}
+
+func TestRemoveOwner(t *testing.T) {
+
+	t.Run("RemovesOwningGroupAddedFromCatalog", func(t *testing.T) {
+
+		ownership := &CatalogEntityOwnership{Owner: &CatalogEntityOwner{Type: CatalogOwnerTypeGroup, Name: "team-ci"}}
+		groups, _ := ownership.ApplyOwner([]*contracts.Group{{Name: "team-web"}}, nil)
+
+		// act
+		groups, organizations := ownership.RemoveOwner(groups, []*contracts.Organization{{Name: "team-ci"}})
+
+		if assert.Equal(t, 1, len(groups)) {
+			assert.Equal(t, "team-web", groups[0].Name)
+		}
+		assert.Equal(t, 1, len(organizations))
+	})
+
+	t.Run("KeepsGroupWithSameNameAssignedByHand", func(t *testing.T) {
+
+		ownership := &CatalogEntityOwnership{Owner: &CatalogEntityOwner{Type: CatalogOwnerTypeGroup, Name: "team-ci"}}
+
+		// act
+		groups, _ := ownership.RemoveOwner([]*contracts.Group{{ID: "3", Name: "team-ci"}}, nil)
+
+		assert.Equal(t, 1, len(groups))
+	})
+
+	t.Run("LeavesGroupsAndOrganizationsUnchangedForNilOwnership", func(t *testing.T) {
+
+		var ownership *CatalogEntityOwnership
+
+		// act
+		groups, _ := ownership.RemoveOwner([]*contracts.Group{{Name: "team-ci"}}, nil)
+
+		assert.Equal(t, 1, len(groups))
+	})
+}
+
+func TestHasSameOwner(t *testing.T) {
+
+	t.Run("ReturnsTrueIfNeitherHasOwner", func(t *testing.T) {
+
+		var ownership *CatalogEntityOwnership
+
+		assert.True(t, ownership.HasSameOwner(&CatalogEntityOwnership{Tier: "1"}))
+	})
+
+	t.Run("ReturnsFalseForDifferentOwners", func(t *testing.T) {
+
+		ownership := &CatalogEntityOwnership{Owner: &CatalogEntityOwner{Type: CatalogOwnerTypeGroup, Name: "team-ci"}}
+
+		assert.False(t, ownership.HasSameOwner(&CatalogEntityOwnership{Owner: &CatalogEntityOwner{Type: CatalogOwnerTypeOrganization, Name: "team-ci"}}))
+		assert.False(t, ownership.HasSameOwner(nil))
+	})
+}
+
+func TestCatalogEntityChangeAffectsOwners(t *testing.T) {
+
+	entity := contracts.CatalogEntity{
+		Key:            "service",
+		Value:          "estafette-ci-api",
+		LinkedPipeline: "github.com/estafette/estafette-ci-api",
+		Metadata: map[string]interface{}{
+			"owner":       map[string]interface{}{"type": "group", "name": "team-ci"},
+			"description": "api",
+		},
+	}
+
+	t.Run("ReturnsTrueForNewOrDeletedEntity", func(t *testing.T) {
+		assert.True(t, CatalogEntityChangeAffectsOwners(nil, &entity))
+		assert.True(t, CatalogEntityChangeAffectsOwners(&entity, nil))
+	})
+
+	t.Run("ReturnsFalseForChangeToOtherMetadata", func(t *testing.T) {
+
+		after := entity
+		after.Metadata = map[string]interface{}{
+			"owner":       map[string]interface{}{"type": "group", "name": "team-ci"},
+			"description": "estafette ci api",
+		}
+
+		assert.False(t, CatalogEntityChangeAffectsOwners(&entity, &after))
+	})
+
+	t.Run("ReturnsTrueForChangedOwner", func(t *testing.T) {
+
+		after := entity
+		after.Metadata = map[string]interface{}{
+			"owner": map[string]interface{}{"type": "group", "name": "team-web"},
+		}
+
+		assert.True(t, CatalogEntityChangeAffectsOwners(&entity, &after))
+	})
+
+	t.Run("ReturnsTrueForChangedLinkedPipeline", func(t *testing.T) {
+
+		after := entity
+		after.LinkedPipeline = "github.com/estafette/estafette-ci-web"
+
+		assert.True(t, CatalogEntityChangeAffectsOwners(&entity, &after))
+	})
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+var (
+	// ErrCatalogEntitySchemaViolation is returned for catalog entities that don't match the schema configured for their key
+	ErrCatalogEntitySchemaViolation = errors.New("The catalog entity does not match the schema for its key")
+
+	// ErrInvalidCatalogSchemaPattern is returned when loading a catalog schema with a pattern that isn't a valid regular expression
+	ErrInvalidCatalogSchemaPattern = errors.New("The catalog schema pattern is not a valid regular expression")
+)
+
+// CatalogEntityViolation lists the ways a stored catalog entity violates the schema for its key
+type CatalogEntityViolation struct {
+	CatalogEntityID string   `json:"catalogEntityID"`
+	Key             string   `json:"key"`
+	Value           string   `json:"value"`
+	Violations      []string `json:"violations"`
+}
+
+// CompilePatterns compiles the patterns of all catalog schemas, so they're compiled only once; it returns ErrInvalidCatalogSchemaPattern for the first invalid pattern
+func (c *CatalogConfig) CompilePatterns() error {
+	if c == nil {
+		return nil
+	}
+
+	for _, schema := range c.Schemas {
+		if schema == nil {
+			continue
+		}
+		if schema.Metadata != nil {
+			err := schema.Metadata.compilePatterns(fmt.Sprintf("%v.metadata", schema.Key))
+			if err != nil {
+				return err
+			}
+		}
+		for _, lc := range schema.Labels {
+			if lc == nil || lc.Pattern == "" {
+				continue
+			}
+			re, err := regexp.Compile(lc.Pattern)
+			if err != nil {
+				return fmt.Errorf("%w: %v label %v has pattern %v: %v", ErrInvalidCatalogSchemaPattern, schema.Key, lc.Key, lc.Pattern, err)
+			}
+			lc.patternRegex = re
+		}
+	}
+
+	return nil
+}
+
+func (s *JSONSchema) compilePatterns(path string) error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%w: %v has pattern %v: %v", ErrInvalidCatalogSchemaPattern, path, s.Pattern, err)
+		}
+		s.patternRegex = re
+	}
+
+	for k, ps := range s.Properties {
+		if ps != nil {
+			if err := ps.compilePatterns(path + "." + k); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Items != nil {
+		if err := s.Items.compilePatterns(path + "[]"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateCatalogEntitySchema returns ErrCatalogEntitySchemaViolation if the catalog entity doesn't match the schema configured for its key
+func ValidateCatalogEntitySchema(entity *contracts.CatalogEntity, config *CatalogConfig) error {
+
+	violations := GetCatalogEntitySchemaViolations(entity, config)
+	if len(violations) > 0 {
+		return fmt.Errorf("%v: %w", strings.Join(violations, "; "), ErrCatalogEntitySchemaViolation)
+	}
+
+	return nil
+}
+
+// GetCatalogEntitySchemaViolations returns a description of each way the catalog entity violates the schema configured for its key
+func GetCatalogEntitySchemaViolations(entity *contracts.CatalogEntity, config *CatalogConfig) (violations []string) {
+
+	if entity == nil {
+		return
+	}
+
+	schema := config.GetSchema(entity.Key)
+	if schema == nil {
+		if config != nil && config.RequireSchema {
+			violations = append(violations, fmt.Sprintf("Key %v has no schema", entity.Key))
+		}
+		return
+	}
+
+	if len(schema.AllowedParentKeys) > 0 && !StringArrayContains(schema.AllowedParentKeys, entity.ParentKey) {
+		violations = append(violations, fmt.Sprintf("Parent key %v is not one of %v", entity.ParentKey, strings.Join(schema.AllowedParentKeys, ", ")))
+	}
+
+	if schema.Metadata != nil {
+		var metadata interface{} = map[string]interface{}{}
+		if entity.Metadata != nil {
+			metadata = entity.Metadata
+		}
+		violations = append(violations, schema.Metadata.validate("metadata", metadata)...)
+	}
+
+	for _, lc := range schema.Labels {
+		if lc != nil {
+			violations = append(violations, lc.validate(entity.Labels)...)
+		}
+	}
+
+	return
+}
+
+func (lc *CatalogLabelConstraint) validate(labels []contracts.Label) (violations []string) {
+
+	found := false
+	for _, l := range labels {
+		if l.Key != lc.Key {
+			continue
+		}
+		found = true
+
+		if len(lc.Values) > 0 && !StringArrayContains(lc.Values, l.Value) {
+			violations = append(violations, fmt.Sprintf("Label %v value %v is not one of %v", l.Key, l.Value, strings.Join(lc.Values, ", ")))
+		}
+		if lc.Pattern != "" && !matchesPattern(lc.patternRegex, lc.Pattern, l.Value) {
+			violations = append(violations, fmt.Sprintf("Label %v value %v does not match pattern %v", l.Key, l.Value, lc.Pattern))
+		}
+	}
+
+	if lc.Required && !found {
+		violations = append(violations, fmt.Sprintf("Label %v is required", lc.Key))
+	}
+
+	return
+}
+
+// validate checks the value against the schema; path is used to point at the offending value in violations
+func (s *JSONSchema) validate(path string, value interface{}) (violations []string) {
+
+	if s.Type != "" && !hasJSONType(value, s.Type) {
+		return []string{fmt.Sprintf("%v should be of type %v", path, s.Type)}
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := false
+		for _, e := range s.Enum {
+			// compare formatted values, since json numbers decode as float64 and yaml numbers as int
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			violations = append(violations, fmt.Sprintf("%v value %v is not one of %v", path, value, s.Enum))
+		}
+	}
+
+	if s.Pattern != "" {
+		if str, ok := value.(string); ok && !matchesPattern(s.patternRegex, s.Pattern, str) {
+			violations = append(violations, fmt.Sprintf("%v value %v does not match pattern %v", path, str, s.Pattern))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, r := range s.Required {
+			if _, ok := v[r]; !ok {
+				violations = append(violations, fmt.Sprintf("%v.%v is required", path, r))
+			}
+		}
+
+		// iterate in sorted order to keep violations stable
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok && ps != nil {
+				violations = append(violations, ps.validate(path+"."+k, v[k])...)
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				violations = append(violations, fmt.Sprintf("%v.%v is not allowed", path, k))
+			}
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				violations = append(violations, s.Items.validate(fmt.Sprintf("%v[%v]", path, i), item)...)
+			}
+		}
+	}
+
+	return
+}
+
+func hasJSONType(value interface{}, jsonType string) bool {
+	switch jsonType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		switch value.(type) {
+		case float64, float32, int, int64:
+			return true
+		}
+	case "integer":
+		switch v := value.(type) {
+		case float64:
+			return v == math.Trunc(v)
+		case int, int64:
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesPattern uses the pattern compiled by CompilePatterns; schemas that didn't go through config loading have their pattern compiled on each call
+func matchesPattern(re *regexp.Regexp, pattern, value string) bool {
+	if re == nil {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return false
+		}
+	}
+
+	return re.MatchString(value)
+}
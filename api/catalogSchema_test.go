@@ -0,0 +1,233 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCatalogEntitySchemaViolations(t *testing.T) {
+
+	additionalProperties := false
+	config := &CatalogConfig{
+		Schemas: []*CatalogEntitySchema{
+			{
+				Key:               "cloud",
+				AllowedParentKeys: []string{"organization"},
+				Metadata: &JSONSchema{
+					Type:                 "object",
+					Required:             []string{"provider"},
+					AdditionalProperties: &additionalProperties,
+					Properties: map[string]*JSONSchema{
+						"provider": {Type: "string", Enum: []interface{}{"gcp", "aws", "azure"}},
+						"regions":  {Type: "array", Items: &JSONSchema{Type: "string", Pattern: "^[a-z]+-[a-z]+[0-9]$"}},
+						"projects": {Type: "integer"},
+					},
+				},
+				Labels: []*CatalogLabelConstraint{
+					{Key: "environment", Required: true, Values: []string{"development", "staging", "production"}},
+					{Key: "cost-center", Pattern: "^[0-9]{4}$"},
+				},
+			},
+		},
+	}
+	assert.Nil(t, config.CompilePatterns())
+
+	t.Run("ReturnsNoViolationsForMatchingEntity", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{
+			ParentKey:   "organization",
+			ParentValue: "estafette",
+			Key:         "cloud",
+			Value:       "gcp",
+			Labels:      []contracts.Label{{Key: "environment", Value: "production"}, {Key: "cost-center", Value: "1234"}},
+			Metadata: map[string]interface{}{
+				"provider": "gcp",
+				"regions":  []interface{}{"europe-west1"},
+				"projects": float64(3),
+			},
+		}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, config)
+
+		assert.Equal(t, 0, len(violations))
+	})
+
+	t.Run("ReturnsViolationForDisallowedParentKey", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{
+			ParentKey: "team",
+			Key:       "cloud",
+			Labels:    []contracts.Label{{Key: "environment", Value: "production"}},
+			Metadata:  map[string]interface{}{"provider": "gcp"},
+		}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, config)
+
+		if assert.Equal(t, 1, len(violations)) {
+			assert.Equal(t, "Parent key team is not one of organization", violations[0])
+		}
+	})
+
+	t.Run("ReturnsViolationsForMetadataNotMatchingSchema", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{
+			ParentKey: "organization",
+			Key:       "cloud",
+			Labels:    []contracts.Label{{Key: "environment", Value: "production"}},
+			Metadata: map[string]interface{}{
+				"cloud":    "gcp",
+				"regions":  []interface{}{"europe-west1", "EU"},
+				"projects": 2.5,
+			},
+		}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, config)
+
+		assert.Equal(t, []string{
+			"metadata.provider is required",
+			"metadata.cloud is not allowed",
+			"metadata.projects should be of type integer",
+			"metadata.regions[1] value EU does not match pattern ^[a-z]+-[a-z]+[0-9]$",
+		}, violations)
+	})
+
+	t.Run("ReturnsViolationForValueNotInEnum", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{
+			ParentKey: "organization",
+			Key:       "cloud",
+			Labels:    []contracts.Label{{Key: "environment", Value: "production"}},
+			Metadata:  map[string]interface{}{"provider": "digitalocean"},
+		}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, config)
+
+		if assert.Equal(t, 1, len(violations)) {
+			assert.Equal(t, "metadata.provider value digitalocean is not one of [gcp aws azure]", violations[0])
+		}
+	})
+
+	t.Run("ReturnsViolationsForLabelsNotMatchingConstraints", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{
+			ParentKey: "organization",
+			Key:       "cloud",
+			Labels:    []contracts.Label{{Key: "cost-center", Value: "ci"}},
+			Metadata:  map[string]interface{}{"provider": "aws"},
+		}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, config)
+
+		assert.Equal(t, []string{
+			"Label environment is required",
+			"Label cost-center value ci does not match pattern ^[0-9]{4}$",
+		}, violations)
+	})
+
+	t.Run("ReturnsNoViolationsForKeyWithoutSchema", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Key: "cloud-provider", Value: "gcp"}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, config)
+
+		assert.Equal(t, 0, len(violations))
+	})
+
+	t.Run("ReturnsViolationForKeyWithoutSchemaIfSchemaIsRequired", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Key: "cloud-provider", Value: "gcp"}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, &CatalogConfig{Schemas: config.Schemas, RequireSchema: true})
+
+		if assert.Equal(t, 1, len(violations)) {
+			assert.Equal(t, "Key cloud-provider has no schema", violations[0])
+		}
+	})
+
+	t.Run("ReturnsNoViolationsWithoutCatalogConfig", func(t *testing.T) {
+
+		entity := &contracts.CatalogEntity{Key: "cloud", Value: "gcp"}
+
+		// act
+		violations := GetCatalogEntitySchemaViolations(entity, nil)
+
+		assert.Equal(t, 0, len(violations))
+	})
+}
+
+func TestValidateCatalogEntitySchema(t *testing.T) {
+
+	t.Run("ReturnsSchemaViolationError", func(t *testing.T) {
+
+		config := &CatalogConfig{
+			Schemas: []*CatalogEntitySchema{
+				{Key: "team", AllowedParentKeys: []string{"organization"}},
+			},
+		}
+		entity := &contracts.CatalogEntity{Key: "team", Value: "ci"}
+
+		// act
+		err := ValidateCatalogEntitySchema(entity, config)
+
+		assert.True(t, errors.Is(err, ErrCatalogEntitySchemaViolation))
+	})
+}
+
+func TestCompilePatterns(t *testing.T) {
+
+	t.Run("ReturnsErrorForInvalidMetadataPattern", func(t *testing.T) {
+
+		config := &CatalogConfig{
+			Schemas: []*CatalogEntitySchema{
+				{
+					Key: "cloud",
+					Metadata: &JSONSchema{
+						Type: "object",
+						Properties: map[string]*JSONSchema{
+							"regions": {Type: "array", Items: &JSONSchema{Type: "string", Pattern: "^[a-z"}},
+						},
+					},
+				},
+			},
+		}
+
+		// act
+		err := config.CompilePatterns()
+
+		assert.True(t, errors.Is(err, ErrInvalidCatalogSchemaPattern))
+	})
+
+	t.Run("ReturnsErrorForInvalidLabelPattern", func(t *testing.T) {
+
+		config := &CatalogConfig{
+			Schemas: []*CatalogEntitySchema{
+				{Key: "cloud", Labels: []*CatalogLabelConstraint{{Key: "cost-center", Pattern: "([0-9]"}}},
+			},
+		}
+
+		// act
+		err := config.CompilePatterns()
+
+		assert.True(t, errors.Is(err, ErrInvalidCatalogSchemaPattern))
+	})
+
+	t.Run("ReturnsNilWithoutCatalogConfig", func(t *testing.T) {
+
+		var config *CatalogConfig
+
+		// act
+		err := config.CompilePatterns()
+
+		assert.Nil(t, err)
+	})
+}
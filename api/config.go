@@ -2,12 +2,16 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	contracts "github.com/estafette/estafette-ci-contracts"
 	crypt "github.com/estafette/estafette-ci-crypt"
@@ -29,6 +33,13 @@ type APIConfig struct {
 	Database            *DatabaseConfig                        `yaml:"database,omitempty"`
 	ManifestPreferences *manifest.EstafetteManifestPreferences `yaml:"manifestPreferences,omitempty"`
 	Catalog             *CatalogConfig                         `yaml:"catalog,omitempty"`
+	Audit               *AuditConfig                           `yaml:"audit,omitempty"`
+	Triggers            *TriggersConfig                        `yaml:"triggers,omitempty"`
+	Linting             *LintingConfig                         `yaml:"linting,omitempty"`
+	Dora                *DoraConfig                            `yaml:"dora,omitempty"`
+	Cost                *CostConfig                            `yaml:"cost,omitempty"`
+	Health              *HealthConfig                          `yaml:"health,omitempty"`
+	SecretProviders     []*SecretProviderConfig                `yaml:"secretProviders,omitempty" json:"-"`
 	Credentials         []*contracts.CredentialConfig          `yaml:"credentials,omitempty" json:"credentials,omitempty"`
 	TrustedImages       []*contracts.TrustedImageConfig        `yaml:"trustedImages,omitempty" json:"trustedImages,omitempty"`
 	RegistryMirror      *string                                `yaml:"registryMirror,omitempty" json:"registryMirror,omitempty"`
@@ -65,11 +76,28 @@ func (c *APIServerConfig) ReadLogFromCloudStorage() bool {
 	return c.LogReader == "cloudstorage"
 }
 
-// AuthConfig determines whether to use IAP for authentication and authorization
+// AuthConfig determines whether to use IAP for authentication and authorization; without EnforcePipelinePermissions any valid token can operate pipelines, so scoped custom roles can't be created since their scope wouldn't restrict anything
 type AuthConfig struct {
-	JWT            *JWTConfig                `yaml:"jwt"`
-	Administrators []string                  `yaml:"administrators"`
-	Organizations  []*AuthOrganizationConfig `yaml:"organizations"`
+	JWT                        *JWTConfig                `yaml:"jwt"`
+	Administrators             []string                  `yaml:"administrators"`
+	Organizations              []*AuthOrganizationConfig `yaml:"organizations"`
+	EnforcePipelinePermissions bool                      `yaml:"enforcePipelinePermissions"`
+	SCIM                       *SCIMConfig               `yaml:"scim"`
+}
+
+// SCIMConfig configures provisioning of users and groups by an identity provider through scim
+type SCIMConfig struct {
+	// IdentityProvider is the name of the oauth provider provisioned users log in with; it defaults to scim
+	IdentityProvider string `yaml:"identityProvider"`
+}
+
+// GetIdentityProvider returns the provider name used for identities of provisioned users and groups
+func (c *SCIMConfig) GetIdentityProvider() string {
+	if c == nil || c.IdentityProvider == "" {
+		return "scim"
+	}
+
+	return c.IdentityProvider
 }
 
 // AuthOrganizationConfig configures things relevant to each organization using the system
@@ -93,14 +121,31 @@ func (config *AuthConfig) IsConfiguredAsAdministrator(email string) bool {
 	return false
 }
 
-// OAuthProvider is used to configure one or more oauth providers like google, github, microsoft
+// OAuthProvider is used to configure one or more oauth providers like google, github, microsoft or any openid connect provider by setting its issuer
 type OAuthProvider struct {
-	Name                   string `yaml:"name"`
-	ClientID               string `yaml:"clientID"`
-	ClientSecret           string `yaml:"clientSecret"`
-	AllowedIdentitiesRegex string `yaml:"allowedIdentitiesRegex"`
+	Name                   string   `yaml:"name"`
+	ClientID               string   `yaml:"clientID"`
+	ClientSecret           string   `yaml:"clientSecret"`
+	AllowedIdentitiesRegex string   `yaml:"allowedIdentitiesRegex"`
+	Issuer                 string   `yaml:"issuer"`
+	Scopes                 []string `yaml:"scopes"`
+	GroupsClaim            string   `yaml:"groupsClaim"`
+	BaseURL                string   `yaml:"baseURL"`
+}
+
+// OpenIDConfiguration holds the endpoints published by an openid connect issuer at /.well-known/openid-configuration
+type OpenIDConfiguration struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
 }
 
+var (
+	openIDConfigurations      = map[string]*OpenIDConfiguration{}
+	openIDConfigurationsMutex = sync.RWMutex{}
+)
+
 // OAuthProviderInfo provides non configurable information for oauth providers
 type OAuthProviderInfo struct {
 	AuthURL  string
@@ -119,6 +164,22 @@ func (p *OAuthProvider) GetConfig(baseURL string) *oauth2.Config {
 		RedirectURL:  redirectURI,
 	}
 
+	if p.IsOpenIDConnect() {
+		openIDConfiguration, err := p.GetOpenIDConfiguration()
+		if err != nil {
+			log.Error().Err(err).Msgf("Retrieving openid configuration for provider %v failed", p.Name)
+			return nil
+		}
+
+		oauthConfig.Endpoint = oauth2.Endpoint{
+			AuthURL:  openIDConfiguration.AuthorizationEndpoint,
+			TokenURL: openIDConfiguration.TokenEndpoint,
+		}
+		oauthConfig.Scopes = append([]string{"openid", "profile", "email"}, p.Scopes...)
+
+		return &oauthConfig
+	}
+
 	switch p.Name {
 	case "google":
 		oauthConfig.Endpoint = endpoints.Google
@@ -134,10 +195,24 @@ func (p *OAuthProvider) GetConfig(baseURL string) *oauth2.Config {
 		oauthConfig.Endpoint = endpoints.Facebook
 	case "github":
 		oauthConfig.Endpoint = endpoints.GitHub
+		oauthConfig.Scopes = []string{
+			"read:user",
+			"user:email",
+		}
 	case "bitbucket":
 		oauthConfig.Endpoint = endpoints.Bitbucket
+		oauthConfig.Scopes = []string{
+			"account",
+			"email",
+		}
 	case "gitlab":
-		oauthConfig.Endpoint = endpoints.GitLab
+		oauthConfig.Endpoint = oauth2.Endpoint{
+			AuthURL:  p.getGitlabBaseURL() + "/oauth/authorize",
+			TokenURL: p.getGitlabBaseURL() + "/oauth/token",
+		}
+		oauthConfig.Scopes = []string{
+			"read_user",
+		}
 
 	default:
 		return nil
@@ -151,8 +226,95 @@ func (p *OAuthProvider) AuthCodeURL(baseURL, state string) string {
 	return p.GetConfig(baseURL).AuthCodeURL(state, oauth2.AccessTypeOnline)
 }
 
-// GetUserIdentity returns the user info after a token has been retrieved
+// IsOpenIDConnect returns true if the provider is a generic openid connect provider configured by its issuer
+func (p *OAuthProvider) IsOpenIDConnect() bool {
+	return p.Issuer != ""
+}
+
+// GetOpenIDConfiguration retrieves the endpoints for the provider's issuer through openid connect discovery; they're cached for the lifetime of the application
+func (p *OAuthProvider) GetOpenIDConfiguration() (openIDConfiguration *OpenIDConfiguration, err error) {
+
+	issuer := strings.TrimSuffix(p.Issuer, "/")
+
+	openIDConfigurationsMutex.RLock()
+	openIDConfiguration, ok := openIDConfigurations[issuer]
+	openIDConfigurationsMutex.RUnlock()
+	if ok {
+		return openIDConfiguration, nil
+	}
+
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	err = getJSON(client, issuer+"/.well-known/openid-configuration", &openIDConfiguration)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(openIDConfiguration.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("Issuer %v in openid configuration does not match configured issuer %v", openIDConfiguration.Issuer, p.Issuer)
+	}
+	if openIDConfiguration.AuthorizationEndpoint == "" || openIDConfiguration.TokenEndpoint == "" || openIDConfiguration.UserinfoEndpoint == "" {
+		return nil, fmt.Errorf("Openid configuration for issuer %v is missing authorization, token or userinfo endpoint", p.Issuer)
+	}
+
+	openIDConfigurationsMutex.Lock()
+	openIDConfigurations[issuer] = openIDConfiguration
+	openIDConfigurationsMutex.Unlock()
+
+	return openIDConfiguration, nil
+}
+
+// GetUserIdentity returns the user info after a token has been retrieved; it fails if the provider doesn't return a verified email address, since users are matched on it
 func (p *OAuthProvider) GetUserIdentity(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (identity *contracts.UserIdentity, err error) {
+
+	identity, err = p.getUserIdentity(ctx, config, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity == nil || identity.Email == "" {
+		return nil, fmt.Errorf("Provider %v returned no email address: %w", p.Name, ErrUnverifiedEmail)
+	}
+
+	return identity, nil
+}
+
+func (p *OAuthProvider) getUserIdentity(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (identity *contracts.UserIdentity, err error) {
+
+	if p.IsOpenIDConnect() {
+		userInfo, err := p.getOpenIDUserInfo(ctx, config, token)
+		if err != nil {
+			return nil, err
+		}
+
+		email, _ := userInfo["email"].(string)
+		if !isEmailVerifiedClaim(userInfo["email_verified"]) {
+			return nil, fmt.Errorf("Provider %v returned email address %v without email_verified claim set to true: %w", p.Name, email, ErrUnverifiedEmail)
+		}
+		name, _ := userInfo["name"].(string)
+		if name == "" {
+			name, _ = userInfo["preferred_username"].(string)
+		}
+		if name == "" {
+			name = email
+		}
+		id, _ := userInfo["sub"].(string)
+		picture, _ := userInfo["picture"].(string)
+
+		// map userinfo to user identity
+		identity = &contracts.UserIdentity{
+			Provider: p.Name,
+			Email:    email,
+			Name:     name,
+			ID:       id,
+			Avatar:   picture,
+		}
+
+		return identity, nil
+	}
+
 	switch p.Name {
 	case "google":
 		oauth2Service, err := googleoauth2v2.NewService(ctx, option.WithTokenSource(config.TokenSource(ctx, token)))
@@ -165,6 +327,9 @@ func (p *OAuthProvider) GetUserIdentity(ctx context.Context, config *oauth2.Conf
 		if err != nil {
 			return nil, err
 		}
+		if userInfo.VerifiedEmail == nil || !*userInfo.VerifiedEmail {
+			return nil, fmt.Errorf("Provider %v returned unverified email address %v: %w", p.Name, userInfo.Email, ErrUnverifiedEmail)
+		}
 
 		username := userInfo.Name
 		if username == "" && (userInfo.GivenName != "" || userInfo.FamilyName != "") {
@@ -222,12 +387,290 @@ func (p *OAuthProvider) GetUserIdentity(ctx context.Context, config *oauth2.Conf
 			Avatar:   userInfo.Picture,
 		}
 
+		return identity, nil
+
+	case "github":
+
+		client := config.Client(ctx, token)
+
+		// retrieve userinfo
+		var userInfo struct {
+			ID        int    `json:"id"`
+			Login     string `json:"login"`
+			Name      string `json:"name"`
+			Email     string `json:"email"`
+			AvatarURL string `json:"avatar_url"`
+		}
+		err = getJSON(client, "https://api.github.com/user", &userInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		// the public email address can be empty or unverified, so use it only if it's verified and otherwise fall back to the primary verified email address
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+		err = getJSON(client, "https://api.github.com/user/emails", &emails)
+		if err != nil {
+			return nil, err
+		}
+		publicEmail := userInfo.Email
+		userInfo.Email = ""
+		for _, e := range emails {
+			if e.Verified && (e.Email == publicEmail || publicEmail == "" && e.Primary) {
+				userInfo.Email = e.Email
+				break
+			}
+		}
+		if userInfo.Email == "" {
+			return nil, fmt.Errorf("Provider %v returned no verified email address: %w", p.Name, ErrUnverifiedEmail)
+		}
+
+		username := userInfo.Name
+		if username == "" {
+			username = userInfo.Login
+		}
+
+		// map userinfo to user identity
+		identity = &contracts.UserIdentity{
+			Provider: p.Name,
+			Email:    userInfo.Email,
+			Name:     username,
+			ID:       strconv.Itoa(userInfo.ID),
+			Avatar:   userInfo.AvatarURL,
+		}
+
+		return identity, nil
+
+	case "gitlab":
+
+		client := config.Client(ctx, token)
+
+		// retrieve userinfo
+		var userInfo struct {
+			ID          int    `json:"id"`
+			Username    string `json:"username"`
+			Name        string `json:"name"`
+			Email       string `json:"email"`
+			AvatarURL   string `json:"avatar_url"`
+			ConfirmedAt string `json:"confirmed_at"`
+		}
+		err = getJSON(client, p.getGitlabBaseURL()+"/api/v4/user", &userInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		// an instance can allow signing in before the email address is confirmed, so only accept it once confirmed
+		if userInfo.Email == "" || userInfo.ConfirmedAt == "" {
+			return nil, fmt.Errorf("Provider %v returned no confirmed email address: %w", p.Name, ErrUnverifiedEmail)
+		}
+
+		username := userInfo.Name
+		if username == "" {
+			username = userInfo.Username
+		}
+
+		// map userinfo to user identity
+		identity = &contracts.UserIdentity{
+			Provider: p.Name,
+			Email:    userInfo.Email,
+			Name:     username,
+			ID:       strconv.Itoa(userInfo.ID),
+			Avatar:   userInfo.AvatarURL,
+		}
+
+		return identity, nil
+
+	case "bitbucket":
+
+		client := config.Client(ctx, token)
+
+		// retrieve userinfo
+		var userInfo struct {
+			AccountID   string `json:"account_id"`
+			Nickname    string `json:"nickname"`
+			DisplayName string `json:"display_name"`
+			Links       struct {
+				Avatar struct {
+					Href string `json:"href"`
+				} `json:"avatar"`
+			} `json:"links"`
+		}
+		err = getJSON(client, "https://api.bitbucket.org/2.0/user", &userInfo)
+		if err != nil {
+			return nil, err
+		}
+
+		// the user endpoint doesn't return email addresses, so use the primary confirmed email address
+		var emails struct {
+			Values []struct {
+				Email       string `json:"email"`
+				IsPrimary   bool   `json:"is_primary"`
+				IsConfirmed bool   `json:"is_confirmed"`
+			} `json:"values"`
+		}
+		err = getJSON(client, "https://api.bitbucket.org/2.0/user/emails", &emails)
+		if err != nil {
+			return nil, err
+		}
+		email := ""
+		for _, e := range emails.Values {
+			if e.IsPrimary && e.IsConfirmed {
+				email = e.Email
+				break
+			}
+		}
+		if email == "" {
+			return nil, fmt.Errorf("Provider %v returned no confirmed primary email address: %w", p.Name, ErrUnverifiedEmail)
+		}
+
+		username := userInfo.DisplayName
+		if username == "" {
+			username = userInfo.Nickname
+		}
+
+		// map userinfo to user identity
+		identity = &contracts.UserIdentity{
+			Provider: p.Name,
+			Email:    email,
+			Name:     username,
+			ID:       userInfo.AccountID,
+			Avatar:   userInfo.Links.Avatar.Href,
+		}
+
 		return identity, nil
 	}
 
 	return nil, fmt.Errorf("The GetUser function has not been implemented for provider '%v'", p.Name)
 }
 
+// getGitlabBaseURL returns the url of the gitlab instance, which can be set for self-hosted gitlab and defaults to gitlab.com
+func (p *OAuthProvider) getGitlabBaseURL() string {
+	if p.BaseURL != "" {
+		return strings.TrimSuffix(p.BaseURL, "/")
+	}
+
+	return "https://gitlab.com"
+}
+
+// GetUserGroups returns the groups the identity provider claims the user is a member of; it's only supported for openid connect providers with a groups claim
+func (p *OAuthProvider) GetUserGroups(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (groups []string, err error) {
+
+	if !p.IsOpenIDConnect() || p.GroupsClaim == "" {
+		return nil, nil
+	}
+
+	// the id token is received directly from the token endpoint over tls, which allows skipping signature validation as described in openid connect core 3.1.3.7
+	if idToken, ok := token.Extra("id_token").(string); ok && idToken != "" {
+		claims, err := getUnverifiedJWTClaims(idToken)
+		if err != nil {
+			return nil, err
+		}
+		if claim, ok := claims[p.GroupsClaim]; ok {
+			return getGroupsFromClaim(claim), nil
+		}
+	}
+
+	// not all providers add groups to the id token, so fall back to the userinfo endpoint
+	userInfo, err := p.getOpenIDUserInfo(ctx, config, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return getGroupsFromClaim(userInfo[p.GroupsClaim]), nil
+}
+
+func (p *OAuthProvider) getOpenIDUserInfo(ctx context.Context, config *oauth2.Config, token *oauth2.Token) (userInfo map[string]interface{}, err error) {
+
+	openIDConfiguration, err := p.GetOpenIDConfiguration()
+	if err != nil {
+		return nil, err
+	}
+
+	err = getJSON(config.Client(ctx, token), openIDConfiguration.UserinfoEndpoint, &userInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return userInfo, nil
+}
+
+// isEmailVerifiedClaim supports both a boolean and a string email_verified claim, since some providers send it as a string
+func isEmailVerifiedClaim(claim interface{}) bool {
+	switch v := claim.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+
+	return false
+}
+
+func getUnverifiedJWTClaims(token string) (claims map[string]interface{}, err error) {
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("Token is not a valid jwt")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// getGroupsFromClaim supports both an array of group names and a single group name as claim value
+func getGroupsFromClaim(claim interface{}) (groups []string) {
+	switch v := claim.(type) {
+	case string:
+		if v != "" {
+			groups = append(groups, v)
+		}
+	case []interface{}:
+		for _, g := range v {
+			if group, ok := g.(string); ok && group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	return groups
+}
+
+func getJSON(client *http.Client, url string, target interface{}) (err error) {
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Calling %v returned unexpected status code %v", url, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("Unmarshalling body from %v failed: %v", url, err)
+	}
+
+	return nil
+}
+
 // UserIsAllowed checks if user email address matches allowedIdentitiesRegex
 func (p *OAuthProvider) UserIsAllowed(ctx context.Context, email string) (isAllowed bool, err error) {
 
@@ -279,6 +722,56 @@ type DatabaseConfig struct {
 // CatalogConfig configures various aspect of the catalog page
 type CatalogConfig struct {
 	Filters []string `yaml:"filters,omitempty" json:"filters,omitempty"`
+
+	// Schemas constrain the shape of catalog entities per entity key; with RequireSchema set entities with a key without schema are rejected
+	Schemas       []*CatalogEntitySchema `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	RequireSchema bool                   `yaml:"requireSchema,omitempty" json:"requireSchema,omitempty"`
+}
+
+// CatalogEntitySchema defines the allowed parents, metadata and labels for catalog entities with a specific key
+type CatalogEntitySchema struct {
+	Key               string                    `yaml:"key" json:"key"`
+	AllowedParentKeys []string                  `yaml:"allowedParentKeys,omitempty" json:"allowedParentKeys,omitempty"`
+	Metadata          *JSONSchema               `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	Labels            []*CatalogLabelConstraint `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// JSONSchema is the subset of json schema used to validate catalog entity metadata
+type JSONSchema struct {
+	Type                 string                 `yaml:"type,omitempty" json:"type,omitempty"`
+	Required             []string               `yaml:"required,omitempty" json:"required,omitempty"`
+	Properties           map[string]*JSONSchema `yaml:"properties,omitempty" json:"properties,omitempty"`
+	AdditionalProperties *bool                  `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `yaml:"items,omitempty" json:"items,omitempty"`
+	Enum                 []interface{}          `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Pattern              string                 `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+
+	patternRegex *regexp.Regexp
+}
+
+// CatalogLabelConstraint requires a label and/or restricts its values
+type CatalogLabelConstraint struct {
+	Key      string   `yaml:"key" json:"key"`
+	Required bool     `yaml:"required,omitempty" json:"required,omitempty"`
+	Values   []string `yaml:"values,omitempty" json:"values,omitempty"`
+	Pattern  string   `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+
+	patternRegex *regexp.Regexp
+}
+
+// GetSchema returns the schema for catalog entities with the key, or nil if there is none
+func (c *CatalogConfig) GetSchema(key string) *CatalogEntitySchema {
+	if c == nil {
+		return nil
+	}
+
+	for _, s := range c.Schemas {
+		if s != nil && s.Key == key {
+			return s
+		}
+	}
+
+	return nil
 }
 
 // APIConfigIntegrations contains config for 3rd party integrations
@@ -291,6 +784,7 @@ type APIConfigIntegrations struct {
 	BigQuery     *BigQueryConfig     `yaml:"bigquery,omitempty"`
 	CloudStorage *CloudStorageConfig `yaml:"gcs,omitempty"`
 	CloudSource  *CloudSourceConfig  `yaml:"cloudsource,omitempty"`
+	Docker       *DockerConfig       `yaml:"docker,omitempty"`
 }
 
 // GithubConfig is used to configure github integration
@@ -333,6 +827,11 @@ type SlackConfig struct {
 	AppOAuthAccessToken  string `yaml:"appOAuthAccessToken"`
 }
 
+// DockerConfig is used to accept push events from docker hub and other registries for triggering pipelines
+type DockerConfig struct {
+	WebhookToken string `yaml:"webhookToken"`
+}
+
 // PubsubConfig is used to be able to subscribe to pub/sub topics for triggering pipelines based on pub/sub events
 type PubsubConfig struct {
 	DefaultProject                 string `yaml:"defaultProject"`
@@ -341,6 +840,16 @@ type PubsubConfig struct {
 	ServiceAccountEmail            string `yaml:"serviceAccountEmail"`
 	SubscriptionNameSuffix         string `yaml:"subscriptionNameSuffix"`
 	SubscriptionIdleExpirationDays int    `yaml:"subscriptionIdleExpirationDays"`
+	ReconcileIntervalMinutes       int    `yaml:"reconcileIntervalMinutes"`
+}
+
+// GetReconcileInterval returns how often the subscriptions for pubsub triggers are reconciled
+func (c *PubsubConfig) GetReconcileInterval() time.Duration {
+	if c == nil || c.ReconcileIntervalMinutes <= 0 {
+		return 60 * time.Minute
+	}
+
+	return time.Duration(c.ReconcileIntervalMinutes) * time.Minute
 }
 
 // CloudStorageConfig is used to configure a google cloud storage bucket to be used to store logs
@@ -350,6 +859,209 @@ type CloudStorageConfig struct {
 	LogsDirectory string `yaml:"logsDir"`
 }
 
+// AuditConfig configures where audit events are stored besides the database
+type AuditConfig struct {
+	StreamToCloudStorage  bool   `yaml:"streamToCloudStorage"`
+	CloudStorageDirectory string `yaml:"cloudStorageDir"`
+}
+
+// TriggersConfig limits the builds and releases fired by pipeline and release triggers, to protect against trigger loops and fan-out storms
+type TriggersConfig struct {
+	MaxChainDepth         int `yaml:"maxChainDepth"`
+	FanOutBurst           int `yaml:"fanOutBurst"`
+	FanOutIntervalSeconds int `yaml:"fanOutIntervalSeconds"`
+}
+
+// GetMaxChainDepth returns the maximum number of builds and releases triggering each other in a row
+func (c *TriggersConfig) GetMaxChainDepth() int {
+	if c == nil || c.MaxChainDepth <= 0 {
+		return 10
+	}
+
+	return c.MaxChainDepth
+}
+
+// GetFanOutBurst returns the number of builds and releases a single trigger event fires without delay
+func (c *TriggersConfig) GetFanOutBurst() int {
+	if c == nil || c.FanOutBurst <= 0 {
+		return 10
+	}
+
+	return c.FanOutBurst
+}
+
+// GetFanOutInterval returns the delay between each build or release fired by a single trigger event once the burst is used up
+func (c *TriggersConfig) GetFanOutInterval() time.Duration {
+	if c == nil || c.FanOutIntervalSeconds <= 0 {
+		return 2 * time.Second
+	}
+
+	return time.Duration(c.FanOutIntervalSeconds) * time.Second
+}
+
+// DoraConfig configures how the DORA metrics are computed from releases and whether they're exported as prometheus gauges
+type DoraConfig struct {
+	ProductionReleaseTargets []string `yaml:"productionReleaseTargets,omitempty"`
+	DefaultWindow            string   `yaml:"defaultWindow,omitempty"`
+	ExportMetrics            bool     `yaml:"exportMetrics"`
+	ExportIntervalMinutes    int      `yaml:"exportIntervalMinutes"`
+	ExportLabelKeys          []string `yaml:"exportLabelKeys,omitempty"`
+}
+
+// GetProductionReleaseTargets returns the names of the release targets that count as deployments to production
+func (c *DoraConfig) GetProductionReleaseTargets() []string {
+	if c == nil || len(c.ProductionReleaseTargets) == 0 {
+		return []string{"production"}
+	}
+
+	return c.ProductionReleaseTargets
+}
+
+// GetDefaultWindow returns the filter[since] value used when the window for the DORA metrics isn't specified
+func (c *DoraConfig) GetDefaultWindow() string {
+	if c == nil || c.DefaultWindow == "" {
+		return "P4W"
+	}
+
+	return c.DefaultWindow
+}
+
+// GetExportInterval returns how often the DORA metrics are recomputed for the prometheus gauges
+func (c *DoraConfig) GetExportInterval() time.Duration {
+	if c == nil || c.ExportIntervalMinutes <= 0 {
+		return 15 * time.Minute
+	}
+
+	return time.Duration(c.ExportIntervalMinutes) * time.Minute
+}
+
+// CostConfig sets the prices used to attribute the cost of the resources requested by builds and releases
+type CostConfig struct {
+	Currency          string                `yaml:"currency,omitempty"`
+	CPUCoreHourPrice  float64               `yaml:"cpuCoreHourPrice"`
+	MemoryGBHourPrice float64               `yaml:"memoryGBHourPrice"`
+	SavingsThreshold  float64               `yaml:"savingsThreshold,omitempty"`
+	NodePools         []*CostNodePoolConfig `yaml:"nodePools,omitempty"`
+}
+
+// CostNodePoolConfig overrides the prices for jobs of a type, since builds and releases are scheduled on different node pools
+type CostNodePoolConfig struct {
+	Name              string  `yaml:"name"`
+	JobType           string  `yaml:"jobType"`
+	CPUCoreHourPrice  float64 `yaml:"cpuCoreHourPrice"`
+	MemoryGBHourPrice float64 `yaml:"memoryGBHourPrice"`
+}
+
+// GetCurrency returns the currency the prices are in
+func (c *CostConfig) GetCurrency() string {
+	if c == nil || c.Currency == "" {
+		return "USD"
+	}
+
+	return c.Currency
+}
+
+// GetPrices returns the price per core hour and per GB hour for a build or release job, from the node pool for the job type if configured
+func (c *CostConfig) GetPrices(jobType string) (cpuCoreHourPrice, memoryGBHourPrice float64) {
+	if c == nil {
+		return 0, 0
+	}
+
+	for _, np := range c.NodePools {
+		if np.JobType == jobType {
+			return np.CPUCoreHourPrice, np.MemoryGBHourPrice
+		}
+	}
+
+	return c.CPUCoreHourPrice, c.MemoryGBHourPrice
+}
+
+// GetSavingsThreshold returns the share of its requests a pipeline has to use at most to show up as savings opportunity
+func (c *CostConfig) GetSavingsThreshold() float64 {
+	if c == nil || c.SavingsThreshold <= 0 {
+		return 0.5
+	}
+
+	return c.SavingsThreshold
+}
+
+// HealthConfig overrides the timeout and criticality of the dependency health checks
+type HealthConfig struct {
+	Checks []*HealthCheckConfig `yaml:"checks,omitempty"`
+}
+
+// HealthCheckConfig overrides the defaults for the health check of a single dependency
+type HealthCheckConfig struct {
+	Name           string `yaml:"name"`
+	Critical       *bool  `yaml:"critical,omitempty"`
+	TimeoutSeconds int    `yaml:"timeoutSeconds,omitempty"`
+}
+
+// GetCheck returns the overrides for the health check with name, or nil if there are none
+func (c *HealthConfig) GetCheck(name string) *HealthCheckConfig {
+	if c == nil {
+		return nil
+	}
+
+	for _, check := range c.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+
+	return nil
+}
+
+// LintingConfig overrides the defaults of the manifest lint rules
+type LintingConfig struct {
+	Rules []*LintRuleConfig `yaml:"rules,omitempty"`
+}
+
+// LintRuleConfig enables or disables a lint rule, sets its severity and parameters, optionally different for some organizations
+type LintRuleConfig struct {
+	ID            string                        `yaml:"id"`
+	Enabled       *bool                         `yaml:"enabled,omitempty"`
+	Severity      string                        `yaml:"severity,omitempty"`
+	Parameters    map[string]interface{}        `yaml:"parameters,omitempty"`
+	Organizations []*LintRuleOrganizationConfig `yaml:"organizations,omitempty"`
+}
+
+// LintRuleOrganizationConfig overrides the lint rule config for pipelines of an organization
+type LintRuleOrganizationConfig struct {
+	Name       string                 `yaml:"name"`
+	Enabled    *bool                  `yaml:"enabled,omitempty"`
+	Severity   string                 `yaml:"severity,omitempty"`
+	Parameters map[string]interface{} `yaml:"parameters,omitempty"`
+}
+
+// SecretProviderConfig configures an external secret store that credentials can reference with estafette.secretref(<name>:<path>#<key>) values
+type SecretProviderConfig struct {
+	Name            string                          `yaml:"name"`
+	Type            string                          `yaml:"type"`
+	CacheTTLSeconds int                             `yaml:"cacheTTLSeconds,omitempty"`
+	Vault           *VaultSecretProviderConfig      `yaml:"vault,omitempty"`
+	Kubernetes      *KubernetesSecretProviderConfig `yaml:"kubernetes,omitempty"`
+	File            *FileSecretProviderConfig       `yaml:"file,omitempty"`
+}
+
+// VaultSecretProviderConfig configures a HashiCorp Vault compatible http api; the token is read from tokenPath on each fetch if set, so it can be renewed by a sidecar
+type VaultSecretProviderConfig struct {
+	Address   string `yaml:"address"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Token     string `yaml:"token,omitempty"`
+	TokenPath string `yaml:"tokenPath,omitempty"`
+}
+
+// KubernetesSecretProviderConfig configures the namespace to read Kubernetes secrets from if the reference doesn't include a namespace
+type KubernetesSecretProviderConfig struct {
+	Namespace string `yaml:"namespace"`
+}
+
+// FileSecretProviderConfig configures a directory with yaml files holding secrets, as a local stand-in for other secret providers
+type FileSecretProviderConfig struct {
+	Directory string `yaml:"directory"`
+}
+
 // PrometheusConfig configures where to find prometheus for retrieving max cpu and memory consumption of build and release jobs
 type PrometheusConfig struct {
 	ServerURL             string `yaml:"serverURL"`
@@ -404,7 +1116,7 @@ func (h *configReaderImpl) ReadConfigFromFile(configPath string, decryptSecrets
 	// decrypt secrets before unmarshalling
 	if decryptSecrets {
 
-		decryptedData, err := h.secretHelper.DecryptAllEnvelopes(string(data), "")
+		decryptedData, err := DecryptScopedEnvelopes(h.secretHelper, string(data), "")
 		if err != nil {
 			log.Fatal().Err(err).Msg("Failed decrypting secrets in config file")
 		}
@@ -417,6 +1129,13 @@ func (h *configReaderImpl) ReadConfigFromFile(configPath string, decryptSecrets
 		return config, err
 	}
 
+	// compile catalog schema patterns once, so an invalid pattern fails startup instead of rejecting every entity
+	if config != nil {
+		if err := config.Catalog.CompilePatterns(); err != nil {
+			return config, err
+		}
+	}
+
 	log.Info().Msgf("Finished reading %v file successfully", configPath)
 
 	return
@@ -1,12 +1,20 @@
 package api
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	crypt "github.com/estafette/estafette-ci-crypt"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 func TestReadConfigFromFile(t *testing.T) {
@@ -84,6 +92,18 @@ func TestReadConfigFromFile(t *testing.T) {
 		assert.Equal(t, "Estafette", cloudsourceConfig.ProjectOrganizations[0].Organizations[0].Name)
 	})
 
+	t.Run("ReturnsDockerConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		dockerConfig := config.Integrations.Docker
+
+		assert.Equal(t, "this is my secret", dockerConfig.WebhookToken)
+	})
+
 	t.Run("ReturnsSlackConfig", func(t *testing.T) {
 
 		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
@@ -126,6 +146,21 @@ func TestReadConfigFromFile(t *testing.T) {
 		assert.Equal(t, "my-dataset", bigqueryConfig.Dataset)
 	})
 
+	t.Run("ReturnsPubsubConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		pubsubConfig := config.Integrations.Pubsub
+
+		assert.Equal(t, "my-gcp-project", pubsubConfig.DefaultProject)
+		assert.Equal(t, "~estafette-ci-pubsub-trigger", pubsubConfig.SubscriptionNameSuffix)
+		assert.Equal(t, 365, pubsubConfig.SubscriptionIdleExpirationDays)
+		assert.Equal(t, 30*time.Minute, pubsubConfig.GetReconcileInterval())
+	})
+
 	t.Run("ReturnsCloudStorageConfig", func(t *testing.T) {
 
 		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
@@ -169,7 +204,7 @@ func TestReadConfigFromFile(t *testing.T) {
 		assert.Equal(t, "ci.estafette.io", authConfig.JWT.Domain)
 		assert.Equal(t, "this is my secret", authConfig.JWT.Key)
 
-		assert.Equal(t, 3, len(authConfig.Organizations))
+		assert.Equal(t, 4, len(authConfig.Organizations))
 		assert.Equal(t, "Org A", authConfig.Organizations[0].Name)
 		assert.Equal(t, 1, len(authConfig.Organizations[0].OAuthProviders))
 		assert.Equal(t, "google", authConfig.Organizations[0].OAuthProviders[0].Name)
@@ -183,9 +218,18 @@ func TestReadConfigFromFile(t *testing.T) {
 		assert.Equal(t, "Org C", authConfig.Organizations[2].Name)
 		assert.Equal(t, 1, len(authConfig.Organizations[2].OAuthProviders))
 
+		assert.Equal(t, "Org D", authConfig.Organizations[3].Name)
+		assert.Equal(t, 1, len(authConfig.Organizations[3].OAuthProviders))
+		assert.Equal(t, "okta", authConfig.Organizations[3].OAuthProviders[0].Name)
+		assert.Equal(t, "https://estafette.okta.com", authConfig.Organizations[3].OAuthProviders[0].Issuer)
+		assert.Equal(t, []string{"groups"}, authConfig.Organizations[3].OAuthProviders[0].Scopes)
+		assert.Equal(t, "groups", authConfig.Organizations[3].OAuthProviders[0].GroupsClaim)
+
 		assert.Equal(t, 2, len(authConfig.Administrators))
 		assert.Equal(t, "admin1@server.com", authConfig.Administrators[0])
 		assert.Equal(t, "admin2@server.com", authConfig.Administrators[1])
+		assert.True(t, authConfig.EnforcePipelinePermissions)
+		assert.Equal(t, "okta", authConfig.SCIM.GetIdentityProvider())
 	})
 
 	t.Run("ReturnsJobsConfig", func(t *testing.T) {
@@ -261,6 +305,147 @@ func TestReadConfigFromFile(t *testing.T) {
 		assert.Equal(t, 2, len(catalogConfig.Filters))
 		assert.Equal(t, "type", catalogConfig.Filters[0])
 		assert.Equal(t, "team", catalogConfig.Filters[1])
+		assert.True(t, catalogConfig.RequireSchema)
+		assert.Equal(t, 3, len(catalogConfig.Schemas))
+
+		cloudSchema := catalogConfig.GetSchema("cloud")
+		if assert.NotNil(t, cloudSchema) {
+			assert.Equal(t, []string{"organization"}, cloudSchema.AllowedParentKeys)
+			assert.Equal(t, "object", cloudSchema.Metadata.Type)
+			assert.Equal(t, []string{"provider"}, cloudSchema.Metadata.Required)
+			assert.Equal(t, 3, len(cloudSchema.Metadata.Properties["provider"].Enum))
+			assert.Equal(t, 1, len(cloudSchema.Labels))
+			assert.True(t, cloudSchema.Labels[0].Required)
+		}
+		assert.Nil(t, catalogConfig.GetSchema("cloud-provider"))
+	})
+
+	t.Run("ReturnsAuditConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		auditConfig := config.Audit
+
+		assert.True(t, auditConfig.StreamToCloudStorage)
+		assert.Equal(t, "audit", auditConfig.CloudStorageDirectory)
+	})
+
+	t.Run("ReturnsTriggersConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		triggersConfig := config.Triggers
+
+		assert.Equal(t, 5, triggersConfig.GetMaxChainDepth())
+		assert.Equal(t, 20, triggersConfig.GetFanOutBurst())
+		assert.Equal(t, 3*time.Second, triggersConfig.GetFanOutInterval())
+	})
+
+	t.Run("ReturnsDoraConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		doraConfig := config.Dora
+
+		assert.Equal(t, []string{"production", "production-eu"}, doraConfig.GetProductionReleaseTargets())
+		assert.Equal(t, "P2W", doraConfig.GetDefaultWindow())
+		assert.True(t, doraConfig.ExportMetrics)
+		assert.Equal(t, 5*time.Minute, doraConfig.GetExportInterval())
+		assert.Equal(t, []string{"team"}, doraConfig.ExportLabelKeys)
+	})
+
+	t.Run("ReturnsCostConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		costConfig := config.Cost
+
+		assert.Equal(t, "EUR", costConfig.GetCurrency())
+		assert.Equal(t, 0.25, costConfig.GetSavingsThreshold())
+		assert.Equal(t, 1, len(costConfig.NodePools))
+		assert.Equal(t, "preemptibles", costConfig.NodePools[0].Name)
+
+		cpuPrice, memoryPrice := costConfig.GetPrices("build")
+		assert.Equal(t, 0.01, cpuPrice)
+		assert.Equal(t, 0.001, memoryPrice)
+
+		cpuPrice, memoryPrice = costConfig.GetPrices("release")
+		assert.Equal(t, 0.03, cpuPrice)
+		assert.Equal(t, 0.004, memoryPrice)
+	})
+
+	t.Run("ReturnsHealthConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		healthConfig := config.Health
+
+		checkConfig := healthConfig.GetCheck("githubapi")
+		if assert.NotNil(t, checkConfig) {
+			assert.True(t, *checkConfig.Critical)
+			assert.Equal(t, 3, checkConfig.TimeoutSeconds)
+		}
+		assert.Nil(t, healthConfig.GetCheck("cockroachdb"))
+	})
+
+	t.Run("ReturnsLintingConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		lintingConfig := config.Linting
+
+		assert.Equal(t, 2, len(lintingConfig.Rules))
+		assert.Equal(t, "median-build-time", lintingConfig.Rules[0].ID)
+		assert.Nil(t, lintingConfig.Rules[0].Enabled)
+		assert.Equal(t, 180, lintingConfig.Rules[0].Parameters["warningSeconds"])
+		assert.Equal(t, "allowed-image-registries", lintingConfig.Rules[1].ID)
+		assert.True(t, *lintingConfig.Rules[1].Enabled)
+		assert.Equal(t, "warning", lintingConfig.Rules[1].Severity)
+		assert.Equal(t, []interface{}{"eu.gcr.io/my-project", "extensions"}, lintingConfig.Rules[1].Parameters["registries"])
+		assert.Equal(t, 1, len(lintingConfig.Rules[1].Organizations))
+		assert.Equal(t, "Estafette", lintingConfig.Rules[1].Organizations[0].Name)
+		assert.Equal(t, "error", lintingConfig.Rules[1].Organizations[0].Severity)
+	})
+
+	t.Run("ReturnsSecretProvidersConfig", func(t *testing.T) {
+
+		configReader := NewConfigReader(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+
+		// act
+		config, _ := configReader.ReadConfigFromFile("test-config.yaml", true)
+
+		secretProviders := config.SecretProviders
+
+		if assert.Equal(t, 3, len(secretProviders)) {
+			assert.Equal(t, "vault", secretProviders[0].Name)
+			assert.Equal(t, "vault", secretProviders[0].Type)
+			assert.Equal(t, 120, secretProviders[0].CacheTTLSeconds)
+			assert.Equal(t, "https://vault.estafette.io", secretProviders[0].Vault.Address)
+			assert.Equal(t, "ci", secretProviders[0].Vault.Namespace)
+			assert.Equal(t, "this is my secret", secretProviders[0].Vault.Token)
+			assert.Equal(t, "kubernetes", secretProviders[1].Type)
+			assert.Equal(t, "estafette-ci", secretProviders[1].Kubernetes.Namespace)
+			assert.Equal(t, "file", secretProviders[2].Type)
+			assert.Equal(t, "/secrets", secretProviders[2].File.Directory)
+		}
 	})
 
 	t.Run("ReturnsCredentialsConfig", func(t *testing.T) {
@@ -541,6 +726,360 @@ func TestReadLogFromCloudStorage(t *testing.T) {
 	})
 }
 
+func TestOAuthProviderGetConfig(t *testing.T) {
+
+	t.Run("ReturnsEndpointsFromOpenIDConfigurationForIssuer", func(t *testing.T) {
+
+		server := getOpenIDConnectTestServer(t, map[string]interface{}{"sub": "123"})
+		defer server.Close()
+
+		provider := OAuthProvider{
+			Name:     "okta",
+			ClientID: "abc",
+			Issuer:   server.URL,
+			Scopes:   []string{"groups"},
+		}
+
+		// act
+		config := provider.GetConfig("https://ci.estafette.io/")
+
+		if assert.NotNil(t, config) {
+			assert.Equal(t, server.URL+"/authorize", config.Endpoint.AuthURL)
+			assert.Equal(t, server.URL+"/token", config.Endpoint.TokenURL)
+			assert.Equal(t, []string{"openid", "profile", "email", "groups"}, config.Scopes)
+			assert.Equal(t, "https://ci.estafette.io/api/auth/handle/okta", config.RedirectURL)
+		}
+	})
+
+	t.Run("ReturnsEndpointsOfSelfHostedGitlabForBaseURL", func(t *testing.T) {
+
+		provider := OAuthProvider{
+			Name:    "gitlab",
+			BaseURL: "https://gitlab.estafette.io/",
+		}
+
+		// act
+		config := provider.GetConfig("https://ci.estafette.io/")
+
+		if assert.NotNil(t, config) {
+			assert.Equal(t, "https://gitlab.estafette.io/oauth/authorize", config.Endpoint.AuthURL)
+			assert.Equal(t, "https://gitlab.estafette.io/oauth/token", config.Endpoint.TokenURL)
+		}
+	})
+
+	t.Run("ReturnsGitlabComEndpointsWithoutBaseURL", func(t *testing.T) {
+
+		provider := OAuthProvider{
+			Name: "gitlab",
+		}
+
+		// act
+		config := provider.GetConfig("https://ci.estafette.io/")
+
+		if assert.NotNil(t, config) {
+			assert.Equal(t, "https://gitlab.com/oauth/authorize", config.Endpoint.AuthURL)
+			assert.Equal(t, "https://gitlab.com/oauth/token", config.Endpoint.TokenURL)
+		}
+	})
+
+	t.Run("ReturnsNilForUnknownProviderWithoutIssuer", func(t *testing.T) {
+
+		provider := OAuthProvider{
+			Name: "unknown",
+		}
+
+		// act
+		config := provider.GetConfig("https://ci.estafette.io/")
+
+		assert.Nil(t, config)
+	})
+}
+
+func TestOAuthProviderGetUserIdentity(t *testing.T) {
+
+	t.Run("ReturnsIdentityFromUserinfoEndpointForIssuer", func(t *testing.T) {
+
+		server := getOpenIDConnectTestServer(t, map[string]interface{}{
+			"sub":            "123",
+			"email":          "me@estafette.io",
+			"email_verified": true,
+			"name":           "Me",
+			"picture":        "https://estafette.io/me.png",
+		})
+		defer server.Close()
+
+		provider := OAuthProvider{
+			Name:   "okta",
+			Issuer: server.URL,
+		}
+		config := provider.GetConfig("https://ci.estafette.io")
+
+		// act
+		identity, err := provider.GetUserIdentity(context.Background(), config, &oauth2.Token{AccessToken: "abc"})
+
+		assert.Nil(t, err)
+		if assert.NotNil(t, identity) {
+			assert.Equal(t, "okta", identity.Provider)
+			assert.Equal(t, "123", identity.ID)
+			assert.Equal(t, "me@estafette.io", identity.Email)
+			assert.Equal(t, "Me", identity.Name)
+			assert.Equal(t, "https://estafette.io/me.png", identity.Avatar)
+		}
+	})
+
+	t.Run("ReturnsErrorIfEmailIsNotVerifiedByIssuer", func(t *testing.T) {
+
+		for _, emailVerified := range []interface{}{nil, false, "false"} {
+			userInfo := map[string]interface{}{"sub": "123", "email": "me@estafette.io"}
+			if emailVerified != nil {
+				userInfo["email_verified"] = emailVerified
+			}
+			server := getOpenIDConnectTestServer(t, userInfo)
+
+			provider := OAuthProvider{
+				Name:   "okta",
+				Issuer: server.URL,
+			}
+			config := provider.GetConfig("https://ci.estafette.io")
+
+			// act
+			identity, err := provider.GetUserIdentity(context.Background(), config, &oauth2.Token{AccessToken: "abc"})
+
+			assert.True(t, errors.Is(err, ErrUnverifiedEmail), "email_verified %v", emailVerified)
+			assert.Nil(t, identity)
+			server.Close()
+		}
+	})
+
+	t.Run("ReturnsErrorIfIssuerReturnsNoEmail", func(t *testing.T) {
+
+		server := getOpenIDConnectTestServer(t, map[string]interface{}{"sub": "123", "email_verified": "true"})
+		defer server.Close()
+
+		provider := OAuthProvider{
+			Name:   "okta",
+			Issuer: server.URL,
+		}
+		config := provider.GetConfig("https://ci.estafette.io")
+
+		// act
+		_, err := provider.GetUserIdentity(context.Background(), config, &oauth2.Token{AccessToken: "abc"})
+
+		assert.True(t, errors.Is(err, ErrUnverifiedEmail))
+	})
+
+	t.Run("ReturnsVerifiedPublicEmailForGithub", func(t *testing.T) {
+
+		ctx := getProviderAPITestContext(t, map[string]interface{}{
+			"/user":        map[string]interface{}{"id": 5, "login": "me", "email": "me@estafette.io"},
+			"/user/emails": []map[string]interface{}{{"email": "primary@estafette.io", "primary": true, "verified": true}, {"email": "me@estafette.io", "verified": true}},
+		})
+		provider := OAuthProvider{Name: "github"}
+
+		// act
+		identity, err := provider.GetUserIdentity(ctx, &oauth2.Config{}, &oauth2.Token{AccessToken: "abc"})
+
+		assert.Nil(t, err)
+		if assert.NotNil(t, identity) {
+			assert.Equal(t, "me@estafette.io", identity.Email)
+		}
+	})
+
+	t.Run("ReturnsErrorForUnverifiedPublicEmailForGithub", func(t *testing.T) {
+
+		ctx := getProviderAPITestContext(t, map[string]interface{}{
+			"/user":        map[string]interface{}{"id": 5, "login": "me", "email": "someone-else@estafette.io"},
+			"/user/emails": []map[string]interface{}{{"email": "me@estafette.io", "primary": true, "verified": true}},
+		})
+		provider := OAuthProvider{Name: "github"}
+
+		// act
+		_, err := provider.GetUserIdentity(ctx, &oauth2.Config{}, &oauth2.Token{AccessToken: "abc"})
+
+		assert.True(t, errors.Is(err, ErrUnverifiedEmail))
+	})
+
+	t.Run("ReturnsConfirmedEmailForGitlab", func(t *testing.T) {
+
+		ctx := getProviderAPITestContext(t, map[string]interface{}{
+			"/api/v4/user": map[string]interface{}{"id": 5, "username": "me", "email": "me@estafette.io", "confirmed_at": "2020-01-01T00:00:00.000Z"},
+		})
+		provider := OAuthProvider{Name: "gitlab"}
+
+		// act
+		identity, err := provider.GetUserIdentity(ctx, &oauth2.Config{}, &oauth2.Token{AccessToken: "abc"})
+
+		assert.Nil(t, err)
+		if assert.NotNil(t, identity) {
+			assert.Equal(t, "me@estafette.io", identity.Email)
+			assert.Equal(t, "me", identity.Name)
+			assert.Equal(t, "5", identity.ID)
+		}
+	})
+
+	t.Run("ReturnsErrorWithoutConfirmedEmailForGitlab", func(t *testing.T) {
+
+		ctx := getProviderAPITestContext(t, map[string]interface{}{
+			"/api/v4/user": map[string]interface{}{"id": 5, "username": "me", "email": "me@estafette.io", "confirmed_at": nil},
+		})
+		provider := OAuthProvider{Name: "gitlab"}
+
+		// act
+		_, err := provider.GetUserIdentity(ctx, &oauth2.Config{}, &oauth2.Token{AccessToken: "abc"})
+
+		assert.True(t, errors.Is(err, ErrUnverifiedEmail))
+	})
+
+	t.Run("ReturnsUserFromSelfHostedGitlab", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/gitlab/api/v4/user" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 5, "username": "me", "email": "me@estafette.io", "confirmed_at": "2020-01-01T00:00:00.000Z"})
+		}))
+		defer server.Close()
+
+		provider := OAuthProvider{Name: "gitlab", BaseURL: server.URL + "/gitlab/"}
+
+		// act
+		identity, err := provider.GetUserIdentity(context.Background(), &oauth2.Config{}, &oauth2.Token{AccessToken: "abc"})
+
+		assert.Nil(t, err)
+		if assert.NotNil(t, identity) {
+			assert.Equal(t, "me@estafette.io", identity.Email)
+		}
+	})
+
+	t.Run("ReturnsErrorWithoutConfirmedPrimaryEmailForBitbucket", func(t *testing.T) {
+
+		ctx := getProviderAPITestContext(t, map[string]interface{}{
+			"/2.0/user":        map[string]interface{}{"account_id": "abc", "nickname": "me"},
+			"/2.0/user/emails": map[string]interface{}{"values": []map[string]interface{}{{"email": "me@estafette.io", "is_primary": true, "is_confirmed": false}}},
+		})
+		provider := OAuthProvider{Name: "bitbucket"}
+
+		// act
+		_, err := provider.GetUserIdentity(ctx, &oauth2.Config{}, &oauth2.Token{AccessToken: "abc"})
+
+		assert.True(t, errors.Is(err, ErrUnverifiedEmail))
+	})
+}
+
+func TestOAuthProviderGetUserGroups(t *testing.T) {
+
+	t.Run("ReturnsGroupsFromIDToken", func(t *testing.T) {
+
+		server := getOpenIDConnectTestServer(t, map[string]interface{}{"groups": []string{"from-userinfo"}})
+		defer server.Close()
+
+		provider := OAuthProvider{
+			Name:        "okta",
+			Issuer:      server.URL,
+			GroupsClaim: "groups",
+		}
+		config := provider.GetConfig("https://ci.estafette.io")
+
+		payload, _ := json.Marshal(map[string]interface{}{"sub": "123", "groups": []string{"team-a", "team-b"}})
+		idToken := "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+		token := (&oauth2.Token{AccessToken: "abc"}).WithExtra(map[string]interface{}{"id_token": idToken})
+
+		// act
+		groups, err := provider.GetUserGroups(context.Background(), config, token)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"team-a", "team-b"}, groups)
+	})
+
+	t.Run("ReturnsGroupsFromUserinfoIfIDTokenLacksClaim", func(t *testing.T) {
+
+		server := getOpenIDConnectTestServer(t, map[string]interface{}{"groups": "team-c"})
+		defer server.Close()
+
+		provider := OAuthProvider{
+			Name:        "okta",
+			Issuer:      server.URL,
+			GroupsClaim: "groups",
+		}
+		config := provider.GetConfig("https://ci.estafette.io")
+
+		// act
+		groups, err := provider.GetUserGroups(context.Background(), config, &oauth2.Token{AccessToken: "abc"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"team-c"}, groups)
+	})
+
+	t.Run("ReturnsNoGroupsWithoutGroupsClaim", func(t *testing.T) {
+
+		provider := OAuthProvider{
+			Name: "github",
+		}
+
+		// act
+		groups, err := provider.GetUserGroups(context.Background(), nil, &oauth2.Token{AccessToken: "abc"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(groups))
+	})
+}
+
+func getOpenIDConnectTestServer(t *testing.T, userInfo map[string]interface{}) *httptest.Server {
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(OpenIDConfiguration{
+				Issuer:                server.URL,
+				AuthorizationEndpoint: server.URL + "/authorize",
+				TokenEndpoint:         server.URL + "/token",
+				UserinfoEndpoint:      server.URL + "/userinfo",
+			})
+		case "/userinfo":
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(userInfo)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+// getProviderAPITestContext returns a context whose oauth2 http client serves the responses by path instead of calling the provider's api
+func getProviderAPITestContext(t *testing.T, responses map[string]interface{}) context.Context {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, _ := url.Parse(server.URL)
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		r.URL.Scheme = serverURL.Scheme
+		r.URL.Host = serverURL.Host
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	return context.WithValue(context.Background(), oauth2.HTTPClient, client)
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
 // // ReadLogFromDatabase indicates if logReader config is database
 // func (c *APIServerConfig) ReadLogFromDatabase() bool {
 // 	return c.LogReader == "database"
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// DefaultCostWindow is the filter[since] value used for cost reports when no time range is specified
+const DefaultCostWindow = "P4W"
+
+const (
+	CostJobTypeBuild   = "build"
+	CostJobTypeRelease = "release"
+
+	bytesPerGB = 1024 * 1024 * 1024
+)
+
+// JobUsage is the duration and the requested and maximum used resources of a finished build or release job; cpu is in cores, memory in bytes
+type JobUsage struct {
+	JobType        string
+	RepoSource     string
+	RepoOwner      string
+	RepoName       string
+	InsertedAt     time.Time
+	Duration       time.Duration
+	CPURequest     float64
+	CPUMaxUsage    float64
+	MemoryRequest  float64
+	MemoryMaxUsage float64
+	Labels         []contracts.Label
+	Groups         []*contracts.Group
+	Organizations  []*contracts.Organization
+}
+
+// CostReport is the cost of the resources requested by the builds and releases of a pipeline, group, organization or label value
+type CostReport struct {
+	GroupBy       string  `json:"groupBy,omitempty"`
+	Key           string  `json:"key,omitempty"`
+	Builds        int     `json:"builds"`
+	Releases      int     `json:"releases"`
+	CPUCoreHours  float64 `json:"cpuCoreHours"`
+	MemoryGBHours float64 `json:"memoryGBHours"`
+	Cost          float64 `json:"cost"`
+}
+
+// SavingsOpportunity is a pipeline whose jobs use a small share of the resources they request, with the cost of the unused part
+type SavingsOpportunity struct {
+	RepoSource             string  `json:"repoSource"`
+	RepoOwner              string  `json:"repoOwner"`
+	RepoName               string  `json:"repoName"`
+	Jobs                   int     `json:"jobs"`
+	RequestedCPUCoreHours  float64 `json:"requestedCPUCoreHours"`
+	UsedCPUCoreHours       float64 `json:"usedCPUCoreHours"`
+	CPUUsageRatio          float64 `json:"cpuUsageRatio"`
+	RequestedMemoryGBHours float64 `json:"requestedMemoryGBHours"`
+	UsedMemoryGBHours      float64 `json:"usedMemoryGBHours"`
+	MemoryUsageRatio       float64 `json:"memoryUsageRatio"`
+	Cost                   float64 `json:"cost"`
+	PotentialSavings       float64 `json:"potentialSavings"`
+}
+
+// GetFullRepoPath returns the pipeline the savings opportunity is for
+func (o *SavingsOpportunity) GetFullRepoPath() string {
+	return fmt.Sprintf("%v/%v/%v", o.RepoSource, o.RepoOwner, o.RepoName)
+}
+
+// GroupKeys returns the keys of the groups the job counts towards for groupBy
+func (u *JobUsage) GroupKeys(groupBy string) []string {
+	return getGroupKeys(groupBy, u.RepoSource, u.RepoOwner, u.RepoName, u.Labels, u.Groups, u.Organizations)
+}
+
+// cost returns the price of the requested resources for the duration of the job, or of the used ones if used is true
+func (u *JobUsage) cost(config *CostConfig, used bool) float64 {
+	cpuPrice, memoryPrice := config.GetPrices(u.JobType)
+	if used {
+		return u.cpuCoreHours(u.CPUMaxUsage)*cpuPrice + u.memoryGBHours(u.MemoryMaxUsage)*memoryPrice
+	}
+
+	return u.cpuCoreHours(u.CPURequest)*cpuPrice + u.memoryGBHours(u.MemoryRequest)*memoryPrice
+}
+
+func (u *JobUsage) cpuCoreHours(cores float64) float64 {
+	return cores * u.Duration.Hours()
+}
+
+func (u *JobUsage) memoryGBHours(bytes float64) float64 {
+	return bytes / bytesPerGB * u.Duration.Hours()
+}
+
+// ComputeCostReports returns the cost of the jobs per group key, most expensive first; jobs are charged for the resources they request, since those are reserved on the node for their full duration
+func ComputeCostReports(usages []*JobUsage, groupBy string, config *CostConfig) []*CostReport {
+
+	reports := map[string]*CostReport{}
+	for _, u := range usages {
+		for _, key := range u.GroupKeys(groupBy) {
+			report, ok := reports[key]
+			if !ok {
+				report = &CostReport{GroupBy: groupBy, Key: key}
+				reports[key] = report
+			}
+
+			switch u.JobType {
+			case CostJobTypeBuild:
+				report.Builds++
+			case CostJobTypeRelease:
+				report.Releases++
+			}
+			report.CPUCoreHours += u.cpuCoreHours(u.CPURequest)
+			report.MemoryGBHours += u.memoryGBHours(u.MemoryRequest)
+			report.Cost += u.cost(config, false)
+		}
+	}
+
+	result := make([]*CostReport, 0, len(reports))
+	for _, report := range reports {
+		result = append(result, report)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		return result[i].Key < result[j].Key
+	})
+
+	return result
+}
+
+// ComputeSavingsOpportunities returns the pipelines that use at most the configured savings threshold of their requested cpu or memory, highest potential savings first.
+// Only jobs with measured usage count, and the potential savings are the cost of the requested resources that went unused.
+func ComputeSavingsOpportunities(usages []*JobUsage, config *CostConfig) []*SavingsOpportunity {
+
+	opportunities := map[string]*SavingsOpportunity{}
+	for _, u := range usages {
+		if u.CPUMaxUsage <= 0 || u.MemoryMaxUsage <= 0 {
+			continue
+		}
+
+		pipeline := fmt.Sprintf("%v/%v/%v", u.RepoSource, u.RepoOwner, u.RepoName)
+		o, ok := opportunities[pipeline]
+		if !ok {
+			o = &SavingsOpportunity{RepoSource: u.RepoSource, RepoOwner: u.RepoOwner, RepoName: u.RepoName}
+			opportunities[pipeline] = o
+		}
+
+		o.Jobs++
+		o.RequestedCPUCoreHours += u.cpuCoreHours(u.CPURequest)
+		o.UsedCPUCoreHours += u.cpuCoreHours(u.CPUMaxUsage)
+		o.RequestedMemoryGBHours += u.memoryGBHours(u.MemoryRequest)
+		o.UsedMemoryGBHours += u.memoryGBHours(u.MemoryMaxUsage)
+		o.Cost += u.cost(config, false)
+		if savings := u.cost(config, false) - u.cost(config, true); savings > 0 {
+			o.PotentialSavings += savings
+		}
+	}
+
+	threshold := config.GetSavingsThreshold()
+
+	result := []*SavingsOpportunity{}
+	for _, o := range opportunities {
+		if o.RequestedCPUCoreHours > 0 {
+			o.CPUUsageRatio = o.UsedCPUCoreHours / o.RequestedCPUCoreHours
+		}
+		if o.RequestedMemoryGBHours > 0 {
+			o.MemoryUsageRatio = o.UsedMemoryGBHours / o.RequestedMemoryGBHours
+		}
+		if (o.RequestedCPUCoreHours > 0 && o.CPUUsageRatio <= threshold) || (o.RequestedMemoryGBHours > 0 && o.MemoryUsageRatio <= threshold) {
+			result = append(result, o)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PotentialSavings != result[j].PotentialSavings {
+			return result[i].PotentialSavings > result[j].PotentialSavings
+		}
+		return result[i].GetFullRepoPath() < result[j].GetFullRepoPath()
+	})
+
+	return result
+}
+
+// WriteCostReportsCSV writes the cost reports as csv with a header row, for importing them in a spreadsheet or chargeback system
+func WriteCostReportsCSV(w io.Writer, reports []*CostReport, currency string) error {
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"groupBy", "key", "builds", "releases", "cpuCoreHours", "memoryGBHours", "cost", "currency"}); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		err := writer.Write([]string{
+			r.GroupBy,
+			r.Key,
+			strconv.Itoa(r.Builds),
+			strconv.Itoa(r.Releases),
+			strconv.FormatFloat(r.CPUCoreHours, 'f', 4, 64),
+			strconv.FormatFloat(r.MemoryGBHours, 'f', 4, 64),
+			strconv.FormatFloat(r.Cost, 'f', 2, 64),
+			currency,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
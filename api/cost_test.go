@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeCostReports(t *testing.T) {
+
+	config := &CostConfig{
+		CPUCoreHourPrice:  0.04,
+		MemoryGBHourPrice: 0.005,
+		NodePools: []*CostNodePoolConfig{
+			{Name: "preemptibles", JobType: "build", CPUCoreHourPrice: 0.01, MemoryGBHourPrice: 0.001},
+		},
+	}
+
+	usages := []*JobUsage{
+		{JobType: "build", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", Duration: 2 * time.Hour, CPURequest: 2, MemoryRequest: 4 * bytesPerGB, Labels: []contracts.Label{{Key: "team", Value: "ci"}}},
+		{JobType: "release", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", Duration: time.Hour, CPURequest: 1, MemoryRequest: 2 * bytesPerGB, Labels: []contracts.Label{{Key: "team", Value: "ci"}}},
+		{JobType: "build", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-web", Duration: time.Hour, CPURequest: 1, MemoryRequest: bytesPerGB},
+	}
+
+	t.Run("ChargesRequestedResourcesAtNodePoolPricesPerPipeline", func(t *testing.T) {
+
+		// act
+		reports := ComputeCostReports(usages, GroupByPipeline, config)
+
+		if assert.Equal(t, 2, len(reports)) {
+			assert.Equal(t, "github.com/estafette/estafette-ci-api", reports[0].Key)
+			assert.Equal(t, 1, reports[0].Builds)
+			assert.Equal(t, 1, reports[0].Releases)
+			assert.InDelta(t, 5.0, reports[0].CPUCoreHours, 0.0001)
+			assert.InDelta(t, 10.0, reports[0].MemoryGBHours, 0.0001)
+			// build 4*0.01 + 8*0.001, release 1*0.04 + 2*0.005
+			assert.InDelta(t, 0.098, reports[0].Cost, 0.0001)
+
+			assert.Equal(t, "github.com/estafette/estafette-ci-web", reports[1].Key)
+			assert.InDelta(t, 0.011, reports[1].Cost, 0.0001)
+		}
+	})
+
+	t.Run("LeavesOutJobsWithoutTheLabelWhenGroupingByLabel", func(t *testing.T) {
+
+		// act
+		reports := ComputeCostReports(usages, "label:team", config)
+
+		if assert.Equal(t, 1, len(reports)) {
+			assert.Equal(t, "ci", reports[0].Key)
+			assert.Equal(t, 2, reports[0].Builds+reports[0].Releases)
+		}
+	})
+
+	t.Run("ReturnsZeroCostWithoutConfig", func(t *testing.T) {
+
+		// act
+		reports := ComputeCostReports(usages, "", nil)
+
+		if assert.Equal(t, 1, len(reports)) {
+			assert.Equal(t, 0.0, reports[0].Cost)
+			assert.InDelta(t, 6.0, reports[0].CPUCoreHours, 0.0001)
+		}
+	})
+}
+
+func TestComputeSavingsOpportunities(t *testing.T) {
+
+	config := &CostConfig{
+		CPUCoreHourPrice:  0.04,
+		MemoryGBHourPrice: 0.005,
+	}
+
+	t.Run("ReturnsPipelinesUsingLessThanThresholdOfRequests", func(t *testing.T) {
+
+		usages := []*JobUsage{
+			{JobType: "build", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", Duration: time.Hour, CPURequest: 4, CPUMaxUsage: 1, MemoryRequest: 4 * bytesPerGB, MemoryMaxUsage: 3 * bytesPerGB},
+			{JobType: "build", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-web", Duration: time.Hour, CPURequest: 1, CPUMaxUsage: 0.9, MemoryRequest: bytesPerGB, MemoryMaxUsage: 0.8 * bytesPerGB},
+		}
+
+		// act
+		opportunities := ComputeSavingsOpportunities(usages, config)
+
+		if assert.Equal(t, 1, len(opportunities)) {
+			assert.Equal(t, "github.com/estafette/estafette-ci-api", opportunities[0].GetFullRepoPath())
+			assert.InDelta(t, 0.25, opportunities[0].CPUUsageRatio, 0.0001)
+			assert.InDelta(t, 0.75, opportunities[0].MemoryUsageRatio, 0.0001)
+			// 3 unused cores and 1 unused GB for an hour
+			assert.InDelta(t, 0.125, opportunities[0].PotentialSavings, 0.0001)
+		}
+	})
+
+	t.Run("IgnoresJobsWithoutMeasuredUsage", func(t *testing.T) {
+
+		usages := []*JobUsage{
+			{JobType: "build", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", Duration: time.Hour, CPURequest: 4, MemoryRequest: 4 * bytesPerGB},
+		}
+
+		// act
+		opportunities := ComputeSavingsOpportunities(usages, config)
+
+		assert.Equal(t, 0, len(opportunities))
+	})
+}
+
+func TestWriteCostReportsCSV(t *testing.T) {
+
+	t.Run("WritesHeaderAndRowPerReport", func(t *testing.T) {
+
+		reports := []*CostReport{
+			{GroupBy: "label:team", Key: "ci", Builds: 3, Releases: 1, CPUCoreHours: 1.5, MemoryGBHours: 3, Cost: 0.123},
+		}
+		var buffer bytes.Buffer
+
+		// act
+		err := WriteCostReportsCSV(&buffer, reports, "EUR")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "groupBy,key,builds,releases,cpuCoreHours,memoryGBHours,cost,currency\nlabel:team,ci,3,1,1.5000,3.0000,0.12,EUR\n", buffer.String())
+	})
+}
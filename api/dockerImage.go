@@ -0,0 +1,186 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+const (
+	dockerHubRegistry = "docker.io"
+
+	// DockerEventPush is the event of a docker trigger for an image pushed to a registry
+	DockerEventPush = "push"
+)
+
+// DockerImageReference is a parsed container image reference, like registry.example.com/team/app:1.0.0@sha256:3e1f...
+type DockerImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseDockerImageReference splits an image reference in its parts; images without registry are on docker hub, official images get the library/ prefix and a missing tag defaults to latest, unless the image is pinned by digest
+func ParseDockerImageReference(reference string) DockerImageReference {
+
+	ref := DockerImageReference{}
+
+	name := strings.TrimSpace(reference)
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+	}
+
+	// a colon after the last slash separates the tag, a colon before it is part of the registry host
+	if i := strings.LastIndex(name, ":"); i >= 0 && i > strings.LastIndex(name, "/") {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = strings.ToLower(parts[0])
+		ref.Repository = parts[1]
+	} else {
+		ref.Registry = dockerHubRegistry
+		ref.Repository = name
+	}
+
+	if ref.Registry == "index.docker.io" || ref.Registry == "registry-1.docker.io" {
+		ref.Registry = dockerHubRegistry
+	}
+	if ref.Registry == dockerHubRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+
+	return ref
+}
+
+// Name returns the image name without tag or digest, leaving out docker hub as registry the way docker itself shows them
+func (r DockerImageReference) Name() string {
+	if r.Registry == dockerHubRegistry {
+		return strings.TrimPrefix(r.Repository, "library/")
+	}
+
+	return fmt.Sprintf("%v/%v", r.Registry, r.Repository)
+}
+
+// FullName returns the registry and repository in lower case, the form in which the images referenced by pipelines are stored
+func (r DockerImageReference) FullName() string {
+	return strings.ToLower(fmt.Sprintf("%v/%v", r.Registry, r.Repository))
+}
+
+// SameImage returns true if both references point to the same image repository, regardless of tag or digest
+func (r DockerImageReference) SameImage(other DockerImageReference) bool {
+	return strings.EqualFold(r.Registry, other.Registry) && strings.EqualFold(r.Repository, other.Repository)
+}
+
+// NewDockerPushEvent returns the event for an image pushed to a registry; the digest is recorded as part of the tag, as in image:tag@digest, since the event doesn't have a separate field for it
+func NewDockerPushEvent(image, tag, digest string) manifest.EstafetteDockerEvent {
+	if digest != "" {
+		tag = fmt.Sprintf("%v@%v", tag, digest)
+	}
+
+	return manifest.EstafetteDockerEvent{
+		Event: DockerEventPush,
+		Image: image,
+		Tag:   tag,
+	}
+}
+
+// GetDockerEventImageReference returns the image reference for the pushed image, including tag and digest
+func GetDockerEventImageReference(e manifest.EstafetteDockerEvent) DockerImageReference {
+	if e.Tag == "" {
+		return ParseDockerImageReference(e.Image)
+	}
+
+	return ParseDockerImageReference(fmt.Sprintf("%v:%v", e.Image, e.Tag))
+}
+
+// DockerTriggerFires returns true if a docker trigger fires for the event; an empty event or tag in the trigger matches any
+func DockerTriggerFires(t *manifest.EstafetteDockerTrigger, e *manifest.EstafetteDockerEvent) bool {
+	if t == nil || e == nil {
+		return false
+	}
+	if t.Event != "" && t.Event != e.Event {
+		return false
+	}
+
+	pushed := GetDockerEventImageReference(*e)
+	triggerImage := ParseDockerImageReference(t.Image)
+	if !pushed.SameImage(triggerImage) {
+		return false
+	}
+
+	return t.Tag == "" || t.Tag == pushed.Tag
+}
+
+// ManifestReferencesImage returns true if any build stage or service uses the pushed image with the same tag; images pinned by digest are left alone on purpose
+func ManifestReferencesImage(mft manifest.EstafetteManifest, pushed DockerImageReference) bool {
+	return stagesReferenceImage(mft.Stages, pushed)
+}
+
+// GetManifestImageNames returns the full names of the images used by build stages and services that aren't pinned by digest, so pipelines can be looked up by the images they reference
+func GetManifestImageNames(mft manifest.EstafetteManifest) []string {
+	names := []string{}
+	addStageImageNames(mft.Stages, map[string]bool{}, &names)
+	sort.Strings(names)
+
+	return names
+}
+
+func addStageImageNames(stages []*manifest.EstafetteStage, seen map[string]bool, names *[]string) {
+	add := func(image string) {
+		if image == "" {
+			return
+		}
+		ref := ParseDockerImageReference(image)
+		if ref.Digest != "" || seen[ref.FullName()] {
+			return
+		}
+		seen[ref.FullName()] = true
+		*names = append(*names, ref.FullName())
+	}
+
+	for _, s := range stages {
+		add(s.ContainerImage)
+		for _, svc := range s.Services {
+			add(svc.ContainerImage)
+		}
+		addStageImageNames(s.ParallelStages, seen, names)
+	}
+}
+
+func stagesReferenceImage(stages []*manifest.EstafetteStage, pushed DockerImageReference) bool {
+	for _, s := range stages {
+		if imageMatches(s.ContainerImage, pushed) {
+			return true
+		}
+		for _, svc := range s.Services {
+			if imageMatches(svc.ContainerImage, pushed) {
+				return true
+			}
+		}
+		if stagesReferenceImage(s.ParallelStages, pushed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func imageMatches(image string, pushed DockerImageReference) bool {
+	if image == "" {
+		return false
+	}
+
+	ref := ParseDockerImageReference(image)
+
+	return ref.Digest == "" && ref.SameImage(pushed) && ref.Tag == pushed.Tag
+}
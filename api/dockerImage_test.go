@@ -0,0 +1,138 @@
+package api
+
+import (
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDockerImageReference(t *testing.T) {
+	t.Run("DefaultsToDockerHubLibraryAndLatestTag", func(t *testing.T) {
+
+		// act
+		ref := ParseDockerImageReference("golang")
+
+		assert.Equal(t, "docker.io", ref.Registry)
+		assert.Equal(t, "library/golang", ref.Repository)
+		assert.Equal(t, "latest", ref.Tag)
+		assert.Equal(t, "golang", ref.Name())
+	})
+
+	t.Run("ParsesRegistryWithPortTagAndDigest", func(t *testing.T) {
+
+		// act
+		ref := ParseDockerImageReference("registry.example.com:5000/team/app:1.0.0@sha256:abc")
+
+		assert.Equal(t, "registry.example.com:5000", ref.Registry)
+		assert.Equal(t, "team/app", ref.Repository)
+		assert.Equal(t, "1.0.0", ref.Tag)
+		assert.Equal(t, "sha256:abc", ref.Digest)
+		assert.Equal(t, "registry.example.com:5000/team/app", ref.Name())
+	})
+
+	t.Run("TreatsExplicitDockerHubRegistryAsSameImage", func(t *testing.T) {
+
+		// act
+		ref := ParseDockerImageReference("docker.io/library/golang:1.14")
+
+		assert.True(t, ref.SameImage(ParseDockerImageReference("golang:1.15")))
+	})
+}
+
+func TestDockerTriggerFires(t *testing.T) {
+	t.Run("FiresForSameImageAndAnyTag", func(t *testing.T) {
+
+		trigger := &manifest.EstafetteDockerTrigger{Image: "estafette/estafette-ci-builder"}
+		event := NewDockerPushEvent("estafette/estafette-ci-builder", "dev", "sha256:abc")
+
+		// act
+		fires := DockerTriggerFires(trigger, &event)
+
+		assert.True(t, fires)
+		assert.Equal(t, "dev@sha256:abc", event.Tag)
+	})
+
+	t.Run("DoesNotFireForOtherTag", func(t *testing.T) {
+
+		trigger := &manifest.EstafetteDockerTrigger{Image: "estafette/estafette-ci-builder", Tag: "stable"}
+		event := NewDockerPushEvent("estafette/estafette-ci-builder", "dev", "sha256:abc")
+
+		// act
+		fires := DockerTriggerFires(trigger, &event)
+
+		assert.False(t, fires)
+	})
+}
+
+func TestManifestReferencesImage(t *testing.T) {
+
+	mft := manifest.EstafetteManifest{
+		Stages: []*manifest.EstafetteStage{
+			{Name: "build", ContainerImage: "golang:1.14-alpine"},
+			{Name: "pinned", ContainerImage: "node:14@sha256:def"},
+			{
+				Name: "tests",
+				ParallelStages: []*manifest.EstafetteStage{
+					{Name: "integration", ContainerImage: "alpine:3.12", Services: []*manifest.EstafetteService{{Name: "db", ContainerImage: "registry.example.com/team/cockroach:v20"}}},
+				},
+			},
+		},
+	}
+
+	t.Run("ReturnsTrueForStageImageWithSameTag", func(t *testing.T) {
+		assert.True(t, ManifestReferencesImage(mft, ParseDockerImageReference("golang:1.14-alpine")))
+	})
+
+	t.Run("ReturnsTrueForServiceInParallelStage", func(t *testing.T) {
+		assert.True(t, ManifestReferencesImage(mft, ParseDockerImageReference("registry.example.com/team/cockroach:v20@sha256:123")))
+	})
+
+	t.Run("ReturnsFalseForOtherTag", func(t *testing.T) {
+		assert.False(t, ManifestReferencesImage(mft, ParseDockerImageReference("golang:1.15-alpine")))
+	})
+
+	t.Run("ReturnsFalseForImagePinnedByDigest", func(t *testing.T) {
+		assert.False(t, ManifestReferencesImage(mft, ParseDockerImageReference("node:14")))
+	})
+}
+
+func TestGetManifestImageNames(t *testing.T) {
+
+	t.Run("ReturnsFullNamesOfStageAndServiceImagesNotPinnedByDigest", func(t *testing.T) {
+
+		mft := manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.14-alpine"},
+				{Name: "pinned", ContainerImage: "node:14@sha256:def"},
+				{Name: "lint", ContainerImage: "golang:1.15-alpine"},
+				{
+					Name: "tests",
+					ParallelStages: []*manifest.EstafetteStage{
+						{Name: "integration", ContainerImage: "alpine:3.12", Services: []*manifest.EstafetteService{{Name: "db", ContainerImage: "Registry.Example.com/team/cockroach:v20"}}},
+					},
+				},
+			},
+		}
+
+		// act
+		names := GetManifestImageNames(mft)
+
+		assert.Equal(t, []string{"docker.io/library/alpine", "docker.io/library/golang", "registry.example.com/team/cockroach"}, names)
+	})
+
+	t.Run("DoesNotMatchImagesWithPrefixOrWildcardCharacters", func(t *testing.T) {
+
+		mft := manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "exporter", ContainerImage: "nginx-exporter:1.0"},
+			},
+		}
+
+		// act
+		names := GetManifestImageNames(mft)
+
+		assert.NotContains(t, names, ParseDockerImageReference("nginx").FullName())
+		assert.NotContains(t, names, ParseDockerImageReference("nginx_exporter").FullName())
+	})
+}
@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 // {
 // 	"keys" : [
 // 	   {
@@ -142,6 +144,10 @@ type Permission int
 
 const (
 	PermissionRolesList Permission = iota
+	PermissionRolesGet
+	PermissionRolesCreate
+	PermissionRolesUpdate
+	PermissionRolesDelete
 
 	PermissionUsersList
 	PermissionUsersGet
@@ -176,6 +182,7 @@ const (
 
 	PermissionBuildsList
 	PermissionBuildsGet
+	PermissionBuildsCreate
 	PermissionBuildsCancel
 	PermissionBuildsRebuild
 
@@ -189,10 +196,18 @@ const (
 	PermissionCatalogEntitiesCreate
 	PermissionCatalogEntitiesUpdate
 	PermissionCatalogEntitiesDelete
+
+	PermissionAuditEventsList
+
+	PermissionHealthGet
 )
 
 var permissions = []string{
 	"rbac.roles.list",
+	"rbac.roles.get",
+	"rbac.roles.create",
+	"rbac.roles.update",
+	"rbac.roles.delete",
 
 	"rbac.users.list",
 	"rbac.users.get",
@@ -215,6 +230,7 @@ var permissions = []string{
 
 	"rbac.clients.list",
 	"rbac.clients.get",
+	"rbac.clients.viewsecret",
 	"rbac.clients.create",
 	"rbac.clients.update",
 	"rbac.clients.delete",
@@ -226,6 +242,7 @@ var permissions = []string{
 
 	"ci.builds.list",
 	"ci.builds.get",
+	"ci.builds.create",
 	"ci.builds.cancel",
 	"ci.builds.rebuild",
 
@@ -239,6 +256,10 @@ var permissions = []string{
 	"catalog.entities.create",
 	"catalog.entities.update",
 	"catalog.entities.delete",
+
+	"audit.events.list",
+
+	"health.get",
 }
 
 func (p Permission) String() string {
@@ -264,6 +285,10 @@ func Permissions() []string {
 var rolesToPermissionMap = map[Role][]Permission{
 	RoleAdministrator: {
 		PermissionRolesList,
+		PermissionRolesGet,
+		PermissionRolesCreate,
+		PermissionRolesUpdate,
+		PermissionRolesDelete,
 		PermissionUsersList,
 		PermissionUsersGet,
 		PermissionUsersCreate,
@@ -292,6 +317,7 @@ var rolesToPermissionMap = map[Role][]Permission{
 		PermissionPipelinesArchive,
 		PermissionBuildsList,
 		PermissionBuildsGet,
+		PermissionBuildsCreate,
 		PermissionBuildsCancel,
 		PermissionBuildsRebuild,
 		PermissionReleasesList,
@@ -303,9 +329,12 @@ var rolesToPermissionMap = map[Role][]Permission{
 		PermissionCatalogEntitiesCreate,
 		PermissionCatalogEntitiesUpdate,
 		PermissionCatalogEntitiesDelete,
+		PermissionAuditEventsList,
+		PermissionHealthGet,
 	},
 	RoleRoleViewer: {
 		PermissionRolesList,
+		PermissionRolesGet,
 	},
 	RoleUserViewer: {
 		PermissionUsersList,
@@ -365,6 +394,7 @@ var rolesToPermissionMap = map[Role][]Permission{
 		PermissionPipelinesGet,
 		PermissionBuildsList,
 		PermissionBuildsGet,
+		PermissionBuildsCreate,
 		PermissionBuildsCancel,
 		PermissionBuildsRebuild,
 		PermissionReleasesList,
@@ -385,6 +415,7 @@ var rolesToPermissionMap = map[Role][]Permission{
 		PermissionPipelinesGet,
 		PermissionBuildsList,
 		PermissionBuildsGet,
+		PermissionBuildsCreate,
 		PermissionBuildsCancel,
 		PermissionBuildsRebuild,
 		PermissionReleasesList,
@@ -405,6 +436,140 @@ var rolesToPermissionMap = map[Role][]Permission{
 	},
 }
 
+// PersonalAccessToken allows a user to call the api from scripts and tools with a subset of its own permissions
+type PersonalAccessToken struct {
+	ID           string     `json:"id,omitempty"`
+	UserID       string     `json:"userID,omitempty"`
+	Name         string     `json:"name"`
+	Scopes       []string   `json:"scopes,omitempty"`
+	ExpiresAt    *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+	LastUsedFrom string     `json:"lastUsedFrom,omitempty"`
+	InsertedAt   *time.Time `json:"insertedAt,omitempty"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy    string     `json:"revokedBy,omitempty"`
+	Active       bool       `json:"active"`
+
+	// Value holds the signed token; it's only returned once upon creation and never stored
+	Value string `json:"value,omitempty"`
+}
+
+// IsValid returns true if the token is not revoked and not expired
+func (t *PersonalAccessToken) IsValid() bool {
+	if !t.Active || t.RevokedAt != nil {
+		return false
+	}
+	if t.ExpiresAt != nil && t.ExpiresAt.Before(time.Now().UTC()) {
+		return false
+	}
+
+	return true
+}
+
+// CustomRole is a role composed by administrators from a set of permissions; it's assigned by name just like the built-in roles
+type CustomRole struct {
+	ID          string           `json:"id,omitempty"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Permissions []string         `json:"permissions"`
+	Scope       *CustomRoleScope `json:"scope,omitempty"`
+	InsertedAt  *time.Time       `json:"insertedAt,omitempty"`
+	UpdatedAt   *time.Time       `json:"updatedAt,omitempty"`
+	Active      bool             `json:"active"`
+}
+
+// CustomRoleScope limits the permissions of a custom role to pipelines matching all of the non-empty fields
+type CustomRoleScope struct {
+	Organizations  []string          `json:"organizations,omitempty"`
+	Groups         []string          `json:"groups,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	LabelSelector  string            `json:"labelSelector,omitempty"`
+	ReleaseTargets []string          `json:"releaseTargets,omitempty"`
+}
+
+// IsScoped returns true if the scope limits the permissions to a subset of pipelines
+func (s *CustomRoleScope) IsScoped() bool {
+	return s != nil && (len(s.Organizations) > 0 || len(s.Groups) > 0 || len(s.Labels) > 0 || s.LabelSelector != "" || len(s.ReleaseTargets) > 0)
+}
+
+// Matches returns true if the resource falls within the scope; a scope with release targets only matches release operations on one of those targets, never build or pipeline operations
+func (s *CustomRoleScope) Matches(resource PermissionResource) bool {
+	if !s.IsScoped() {
+		return true
+	}
+
+	if len(s.Organizations) > 0 && !stringArraysIntersect(s.Organizations, resource.Organizations) {
+		return false
+	}
+	if len(s.Groups) > 0 && !stringArraysIntersect(s.Groups, resource.Groups) {
+		return false
+	}
+	for key, value := range s.Labels {
+		if resourceValue, ok := resource.Labels[key]; !ok || resourceValue != value {
+			return false
+		}
+	}
+	if s.LabelSelector != "" {
+		// an invalid selector doesn't match anything, so it never grants more than intended
+		selector, err := ParseLabelSelectors([]string{s.LabelSelector})
+		if err != nil || !selector.Matches(resource.Labels) {
+			return false
+		}
+	}
+	if len(s.ReleaseTargets) > 0 && !StringArrayContains(s.ReleaseTargets, resource.ReleaseTarget) {
+		return false
+	}
+
+	return true
+}
+
+// PermissionResource describes the pipeline (and optionally release target) a permission is requested for
+type PermissionResource struct {
+	Organizations []string
+	Groups        []string
+	Labels        map[string]string
+	ReleaseTarget string
+}
+
+// AuditEvent records who changed what through the api; events are only ever appended, never updated
+type AuditEvent struct {
+	ID                string        `json:"id,omitempty"`
+	ActorID           string        `json:"actorID,omitempty"`
+	ActorEmail        string        `json:"actorEmail,omitempty"`
+	ImpersonatorID    string        `json:"impersonatorID,omitempty"`
+	ImpersonatorEmail string        `json:"impersonatorEmail,omitempty"`
+	ClientID          string        `json:"clientID,omitempty"`
+	SourceIP          string        `json:"sourceIP,omitempty"`
+	Action            string        `json:"action"`
+	TargetType        string        `json:"targetType"`
+	TargetID          string        `json:"targetID,omitempty"`
+	Before            interface{}   `json:"before,omitempty"`
+	After             interface{}   `json:"after,omitempty"`
+	Changes           []AuditChange `json:"changes,omitempty"`
+	InsertedAt        *time.Time    `json:"insertedAt,omitempty"`
+}
+
+// AuditChange is a single field that differs between the before and after state of an audit event
+type AuditChange struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// CursorListResponse is a container for list items paged with keyset cursors instead of page numbers
+type CursorListResponse struct {
+	Items      []interface{}    `json:"items"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
+// CursorPagination holds the cursor to request the next page with, empty on the last page; the total is only counted when requested with page[count]=true
+type CursorPagination struct {
+	Size       int    `json:"size"`
+	After      string `json:"after,omitempty"`
+	Next       string `json:"next,omitempty"`
+	TotalItems *int   `json:"totalItems,omitempty"`
+}
+
 // OrderField determines sorting direction
 type OrderField struct {
 	FieldName string
@@ -429,6 +594,15 @@ const (
 	FilterOrganizations
 	FilterLast
 	FilterArchived
+	FilterActor
+	FilterAction
+	FilterTargetType
+	FilterTargetID
+	FilterAfter
+	FilterUntil
+	FilterActive
+	FilterIdentityEmail
+	FilterIdentityID
 )
 
 var filters = []string{
@@ -447,6 +621,15 @@ var filters = []string{
 	"organizations",
 	"last",
 	"archived",
+	"actor",
+	"action",
+	"target-type",
+	"target-id",
+	"after",
+	"until",
+	"active",
+	"identity-email",
+	"identity-id",
 }
 
 func (f FilterType) String() string {
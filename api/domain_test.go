@@ -176,3 +176,46 @@ func TestFiltersToString(t *testing.T) {
 		}
 	})
 }
+
+func TestCustomRoleScopeMatches(t *testing.T) {
+
+	t.Run("ReturnsTrueForReleaseTargetInScope", func(t *testing.T) {
+
+		scope := &CustomRoleScope{ReleaseTargets: []string{"staging"}}
+
+		// act
+		matches := scope.Matches(PermissionResource{ReleaseTarget: "staging"})
+
+		assert.True(t, matches)
+	})
+
+	t.Run("ReturnsFalseForReleaseTargetOutsideOfScope", func(t *testing.T) {
+
+		scope := &CustomRoleScope{ReleaseTargets: []string{"staging"}}
+
+		// act
+		matches := scope.Matches(PermissionResource{ReleaseTarget: "production"})
+
+		assert.False(t, matches)
+	})
+
+	t.Run("ReturnsFalseForResourceWithoutReleaseTargetIfScopedToReleaseTargets", func(t *testing.T) {
+
+		scope := &CustomRoleScope{Organizations: []string{"Org A"}, ReleaseTargets: []string{"staging"}}
+
+		// act
+		matches := scope.Matches(PermissionResource{Organizations: []string{"Org A"}})
+
+		assert.False(t, matches)
+	})
+
+	t.Run("ReturnsTrueForResourceWithoutReleaseTargetIfNotScopedToReleaseTargets", func(t *testing.T) {
+
+		scope := &CustomRoleScope{Organizations: []string{"Org A"}}
+
+		// act
+		matches := scope.Matches(PermissionResource{Organizations: []string{"Org A"}})
+
+		assert.True(t, matches)
+	})
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// DoraDeployment is a finished release to a production release target, with the time the released version was built
+type DoraDeployment struct {
+	RepoSource      string
+	RepoOwner       string
+	RepoName        string
+	ReleaseTarget   string
+	ReleaseVersion  string
+	ReleaseStatus   string
+	InsertedAt      time.Time
+	UpdatedAt       time.Time
+	BuildInsertedAt *time.Time
+	Labels          []contracts.Label
+	Groups          []*contracts.Group
+	Organizations   []*contracts.Organization
+}
+
+// DoraMetrics are the four key metrics of software delivery performance for a pipeline, group, organization or label value
+type DoraMetrics struct {
+	GroupBy             string        `json:"groupBy,omitempty"`
+	Key                 string        `json:"key,omitempty"`
+	Deployments         int           `json:"deployments"`
+	FailedDeployments   int           `json:"failedDeployments"`
+	DeploymentFrequency float64       `json:"deploymentFrequency"`
+	LeadTimeForChanges  time.Duration `json:"leadTimeForChanges"`
+	ChangeFailureRate   float64       `json:"changeFailureRate"`
+	TimeToRestore       time.Duration `json:"timeToRestore"`
+}
+
+// GroupKeys returns the keys of the groups the deployment counts towards for groupBy
+func (d *DoraDeployment) GroupKeys(groupBy string) []string {
+	return getGroupKeys(groupBy, d.RepoSource, d.RepoOwner, d.RepoName, d.Labels, d.Groups, d.Organizations)
+}
+
+// ComputeDoraMetrics returns the DORA metrics for the deployments finished in the window from since to until, per group key ordered by key. A zero since starts the window at the first deployment, a zero until ends it now.
+//
+// Deployment frequency is the number of succeeded deployments per day, lead time for changes is the median time from building a version to
+// succeeding its deployment, change failure rate is the share of failed deployments and time to restore is the median time from a failed
+// deployment to the next succeeded deployment of the same pipeline and release target.
+func ComputeDoraMetrics(deployments []*DoraDeployment, groupBy string, since, until time.Time) []*DoraMetrics {
+
+	if until.IsZero() {
+		until = time.Now().UTC()
+	}
+
+	sorted := make([]*DoraDeployment, len(deployments))
+	copy(sorted, deployments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].UpdatedAt.Before(sorted[j].UpdatedAt)
+	})
+
+	groups := map[string][]*DoraDeployment{}
+	for _, d := range sorted {
+		for _, key := range d.GroupKeys(groupBy) {
+			groups[key] = append(groups[key], d)
+		}
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	metrics := make([]*DoraMetrics, 0, len(keys))
+	for _, key := range keys {
+		m := computeDoraMetricsForGroup(groups[key], since, until)
+		m.GroupBy = groupBy
+		m.Key = key
+		metrics = append(metrics, m)
+	}
+
+	return metrics
+}
+
+func computeDoraMetricsForGroup(deployments []*DoraDeployment, since, until time.Time) *DoraMetrics {
+
+	metrics := &DoraMetrics{}
+
+	leadTimes := []time.Duration{}
+	restoreTimes := []time.Duration{}
+	failedSince := map[string]time.Time{}
+
+	for _, d := range deployments {
+		target := fmt.Sprintf("%v/%v/%v/%v", d.RepoSource, d.RepoOwner, d.RepoName, d.ReleaseTarget)
+
+		switch d.ReleaseStatus {
+		case "succeeded":
+			metrics.Deployments++
+			if d.BuildInsertedAt != nil && d.UpdatedAt.After(*d.BuildInsertedAt) {
+				leadTimes = append(leadTimes, d.UpdatedAt.Sub(*d.BuildInsertedAt))
+			}
+			if failedAt, ok := failedSince[target]; ok {
+				restoreTimes = append(restoreTimes, d.UpdatedAt.Sub(failedAt))
+				delete(failedSince, target)
+			}
+		case "failed":
+			metrics.FailedDeployments++
+			// restoring starts at the first of consecutive failures
+			if _, ok := failedSince[target]; !ok {
+				failedSince[target] = d.UpdatedAt
+			}
+		}
+	}
+
+	if since.IsZero() && len(deployments) > 0 {
+		since = deployments[0].UpdatedAt
+	}
+	if days := until.Sub(since).Hours() / 24; days > 0 {
+		metrics.DeploymentFrequency = float64(metrics.Deployments) / days
+	}
+	if total := metrics.Deployments + metrics.FailedDeployments; total > 0 {
+		metrics.ChangeFailureRate = float64(metrics.FailedDeployments) / float64(total)
+	}
+	metrics.LeadTimeForChanges = medianDuration(leadTimes)
+	metrics.TimeToRestore = medianDuration(restoreTimes)
+
+	return metrics
+}
+
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeDoraMetrics(t *testing.T) {
+
+	since := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := since.Add(4 * 24 * time.Hour)
+	at := func(hours int) time.Time {
+		return since.Add(time.Duration(hours) * time.Hour)
+	}
+	builtAt := func(hours int) *time.Time {
+		t := at(hours)
+		return &t
+	}
+
+	deployments := []*DoraDeployment{
+		{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", ReleaseTarget: "production", ReleaseStatus: "succeeded", UpdatedAt: at(2), BuildInsertedAt: builtAt(1), Labels: []contracts.Label{{Key: "team", Value: "estafette"}}},
+		{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", ReleaseTarget: "production", ReleaseStatus: "failed", UpdatedAt: at(10), BuildInsertedAt: builtAt(8), Labels: []contracts.Label{{Key: "team", Value: "estafette"}}},
+		{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", ReleaseTarget: "production", ReleaseStatus: "failed", UpdatedAt: at(11), BuildInsertedAt: builtAt(8), Labels: []contracts.Label{{Key: "team", Value: "estafette"}}},
+		{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", ReleaseTarget: "production", ReleaseStatus: "succeeded", UpdatedAt: at(14), BuildInsertedAt: builtAt(11), Labels: []contracts.Label{{Key: "team", Value: "estafette"}}},
+		{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-b", ReleaseTarget: "production", ReleaseStatus: "succeeded", UpdatedAt: at(20), BuildInsertedAt: builtAt(15), Groups: []*contracts.Group{{Name: "Team A"}}},
+	}
+
+	t.Run("ReturnsMetricsForAllDeploymentsWithoutGroupBy", func(t *testing.T) {
+
+		// act
+		metrics := ComputeDoraMetrics(deployments, "", since, until)
+
+		if assert.Equal(t, 1, len(metrics)) {
+			assert.Equal(t, 3, metrics[0].Deployments)
+			assert.Equal(t, 2, metrics[0].FailedDeployments)
+			assert.Equal(t, 0.75, metrics[0].DeploymentFrequency)
+			assert.Equal(t, 3*time.Hour, metrics[0].LeadTimeForChanges)
+			assert.Equal(t, 0.4, metrics[0].ChangeFailureRate)
+			assert.Equal(t, 4*time.Hour, metrics[0].TimeToRestore)
+		}
+	})
+
+	t.Run("ReturnsMetricsPerPipeline", func(t *testing.T) {
+
+		// act
+		metrics := ComputeDoraMetrics(deployments, GroupByPipeline, since, until)
+
+		if assert.Equal(t, 2, len(metrics)) {
+			assert.Equal(t, "github.com/estafette/repo-a", metrics[0].Key)
+			assert.Equal(t, 2, metrics[0].Deployments)
+			assert.Equal(t, 2*time.Hour, metrics[0].LeadTimeForChanges)
+			assert.Equal(t, "github.com/estafette/repo-b", metrics[1].Key)
+			assert.Equal(t, 0.0, metrics[1].ChangeFailureRate)
+			assert.Equal(t, time.Duration(0), metrics[1].TimeToRestore)
+		}
+	})
+
+	t.Run("ReturnsMetricsOnlyForDeploymentsWithLabelOrGroup", func(t *testing.T) {
+
+		// act
+		labelMetrics := ComputeDoraMetrics(deployments, "label:team", since, until)
+		groupMetrics := ComputeDoraMetrics(deployments, GroupByGroup, since, until)
+
+		if assert.Equal(t, 1, len(labelMetrics)) {
+			assert.Equal(t, "estafette", labelMetrics[0].Key)
+			assert.Equal(t, 4, labelMetrics[0].Deployments+labelMetrics[0].FailedDeployments)
+		}
+		if assert.Equal(t, 1, len(groupMetrics)) {
+			assert.Equal(t, "Team A", groupMetrics[0].Key)
+			assert.Equal(t, 1, groupMetrics[0].Deployments)
+		}
+	})
+}
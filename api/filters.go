@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidTimeFilter is returned for filter[since] or filter[until] values that aren't a timestamp or duration
+var ErrInvalidTimeFilter = errors.New("is not an ISO-8601 timestamp, a positive duration like 36h or P2W, or one of 1h, 1d, 1w, 1m, 1y or eternity")
+
+var isoDurationRegex = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+var timeFilterLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseTimeFilter returns the time for a filter[since] or filter[until] value relative to now; it accepts the original 1h, 1d, 1w, 1m (month) and 1y values, ISO-8601 timestamps and dates, and Go (36h) or ISO-8601 (P2W, PT12H) durations meaning that long ago. The zero time is returned for eternity, meaning no bound
+func ParseTimeFilter(value string, now time.Time) (time.Time, error) {
+
+	switch value {
+	case "", "eternity":
+		return time.Time{}, nil
+	case "1h":
+		return now.Add(time.Duration(-1) * time.Hour), nil
+	case "1d":
+		return now.AddDate(0, 0, -1), nil
+	case "1w":
+		return now.AddDate(0, 0, -7), nil
+	case "1m":
+		return now.AddDate(0, -1, 0), nil
+	case "1y":
+		return now.AddDate(-1, 0, 0), nil
+	}
+
+	for _, layout := range timeFilterLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+
+	if strings.HasPrefix(value, "P") {
+		return parseISODurationAgo(value, now)
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("%v %w", value, ErrInvalidTimeFilter)
+		}
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%v %w", value, ErrInvalidTimeFilter)
+}
+
+func parseISODurationAgo(value string, now time.Time) (time.Time, error) {
+
+	matches := isoDurationRegex.FindStringSubmatch(value)
+	if matches == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return time.Time{}, fmt.Errorf("%v %w", value, ErrInvalidTimeFilter)
+	}
+
+	atoi := func(s string) int {
+		i, _ := strconv.Atoi(s)
+		return i
+	}
+
+	t := now.AddDate(-atoi(matches[1]), -atoi(matches[2]), -(7*atoi(matches[3]) + atoi(matches[4])))
+	t = t.Add(-time.Duration(atoi(matches[5])) * time.Hour)
+	t = t.Add(-time.Duration(atoi(matches[6])) * time.Minute)
+	if matches[7] != "" {
+		seconds, _ := strconv.ParseFloat(matches[7], 64)
+		t = t.Add(-time.Duration(seconds * float64(time.Second)))
+	}
+
+	return t, nil
+}
+
+// GetUntilFilter extracts a filter on build/release date
+func GetUntilFilter(c *gin.Context) []string {
+	return GetGenericFilter(c, FilterUntil)
+}
+
+// FilterValidationMiddleware returns a 400 for requests with a filter[since], filter[until] or filter[labels] value that can't be parsed, instead of ignoring the filter
+func FilterValidationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		now := time.Now().UTC()
+		for _, filter := range []FilterType{FilterSince, FilterUntil} {
+			for _, value := range GetGenericFilter(c, filter) {
+				if _, err := ParseTimeFilter(value, now); err != nil {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Invalid filter[%v] value: %v", filter, err)})
+					return
+				}
+			}
+		}
+
+		if _, err := ParseLabelSelectors(GetLabelsFilter(c)); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Invalid filter[labels] value: %v", err)})
+			return
+		}
+
+		c.Next()
+	}
+}
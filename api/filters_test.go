@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeFilter(t *testing.T) {
+
+	now := time.Date(2020, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("ReturnsZeroTimeForEternity", func(t *testing.T) {
+
+		// act
+		since, err := ParseTimeFilter("eternity", now)
+
+		assert.Nil(t, err)
+		assert.True(t, since.IsZero())
+	})
+
+	t.Run("ReturnsMonthAgoForOriginal1mValue", func(t *testing.T) {
+
+		// act
+		since, err := ParseTimeFilter("1m", now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2020, 4, 15, 12, 0, 0, 0, time.UTC), since)
+	})
+
+	t.Run("ReturnsTimestampForISO8601Timestamp", func(t *testing.T) {
+
+		// act
+		since, err := ParseTimeFilter("2020-05-01T10:30:00+02:00", now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2020, 5, 1, 8, 30, 0, 0, time.UTC), since)
+	})
+
+	t.Run("ReturnsMidnightForISO8601Date", func(t *testing.T) {
+
+		// act
+		since, err := ParseTimeFilter("2020-05-01", now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), since)
+	})
+
+	t.Run("ReturnsTimeAgoForGoDuration", func(t *testing.T) {
+
+		// act
+		since, err := ParseTimeFilter("36h", now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2020, 5, 14, 0, 0, 0, 0, time.UTC), since)
+	})
+
+	t.Run("ReturnsTimeAgoForISO8601Duration", func(t *testing.T) {
+
+		// act
+		since, err := ParseTimeFilter("P1W2DT3H", now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2020, 5, 6, 9, 0, 0, 0, time.UTC), since)
+	})
+
+	t.Run("ReturnsErrInvalidTimeFilterForUnparseableValue", func(t *testing.T) {
+
+		// act
+		_, err := ParseTimeFilter("last-week", now)
+
+		assert.True(t, errors.Is(err, ErrInvalidTimeFilter))
+	})
+
+	t.Run("ReturnsErrInvalidTimeFilterForEmptyISO8601Duration", func(t *testing.T) {
+
+		// act
+		_, err := ParseTimeFilter("PT", now)
+
+		assert.True(t, errors.Is(err, ErrInvalidTimeFilter))
+	})
+
+	t.Run("ReturnsErrInvalidTimeFilterForNegativeDuration", func(t *testing.T) {
+
+		// act
+		_, err := ParseTimeFilter("-2h", now)
+
+		assert.True(t, errors.Is(err, ErrInvalidTimeFilter))
+	})
+}
+
+func TestFilterValidationMiddleware(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(FilterValidationMiddleware())
+	router.GET("/api/stats/buildscount", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("Returns400ForUnparseableUntilFilter", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/api/stats/buildscount?filter[since]=1w&filter[until]=yesterday", nil)
+
+		// act
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "Invalid filter[until] value: yesterday is not an ISO-8601 timestamp")
+	})
+
+	t.Run("Returns400ForUnparseableLabelsFilter", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/api/stats/buildscount?filter[labels]=team%20in%20(a,b", nil)
+
+		// act
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "Invalid filter[labels] value")
+	})
+
+	t.Run("CallsHandlerForValidFilters", func(t *testing.T) {
+
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", "/api/stats/buildscount?filter[since]=P30D&filter[until]=2020-05-01&filter[labels]=team%20in%20(a,b),tier!%3Dexperimental", nil)
+
+		// act
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// DefaultFlakinessWindow is the filter[since] value used for analysing flakiness when no window is specified
+const DefaultFlakinessWindow = "P4W"
+
+// BuildAttempt is a finished build of a revision; StageStatuses is only set for builds of retried revisions with their log stored in the database
+type BuildAttempt struct {
+	ID            string
+	RepoSource    string
+	RepoOwner     string
+	RepoName      string
+	RepoRevision  string
+	BuildStatus   string
+	StageStatuses map[string]string
+}
+
+// FlakinessReport shows how often building the same revision of a pipeline had differing outcomes; only revisions built more than once count, since a single build can't show flakiness
+type FlakinessReport struct {
+	RepoSource     string            `json:"repoSource"`
+	RepoOwner      string            `json:"repoOwner"`
+	RepoName       string            `json:"repoName"`
+	Revisions      int               `json:"revisions"`
+	FlakyRevisions int               `json:"flakyRevisions"`
+	Score          float64           `json:"score"`
+	Stages         []*StageFlakiness `json:"stages,omitempty"`
+}
+
+// StageFlakiness shows how often a stage had differing outcomes for the same revision; only retried revisions count, since a single build can't show flakiness
+type StageFlakiness struct {
+	Name           string  `json:"name"`
+	Revisions      int     `json:"revisions"`
+	FlakyRevisions int     `json:"flakyRevisions"`
+	Score          float64 `json:"score"`
+}
+
+// GetFullRepoPath returns the pipeline the report is for
+func (r *FlakinessReport) GetFullRepoPath() string {
+	return fmt.Sprintf("%v/%v/%v", r.RepoSource, r.RepoOwner, r.RepoName)
+}
+
+// GetRetriedBuildIDs returns the ids of builds of revisions that were built more than once, the only ones stage statuses are needed for
+func GetRetriedBuildIDs(attempts []*BuildAttempt) (ids []string) {
+
+	attemptsPerRevision := map[string][]*BuildAttempt{}
+	for _, a := range attempts {
+		key := a.revisionKey()
+		attemptsPerRevision[key] = append(attemptsPerRevision[key], a)
+	}
+
+	for _, a := range attempts {
+		if len(attemptsPerRevision[a.revisionKey()]) > 1 {
+			ids = append(ids, a.ID)
+		}
+	}
+
+	return
+}
+
+// ComputeFlakinessReports returns a report per pipeline, ordered by descending score; of the revisions built more than once a revision counts as flaky when its builds both succeeded and failed, a stage when it both succeeded and failed for the same revision
+func ComputeFlakinessReports(attempts []*BuildAttempt) []*FlakinessReport {
+
+	reports := map[string]*FlakinessReport{}
+	revisionOutcomes := map[string]map[string]bool{}
+	revisionAttempts := map[string]int{}
+	stageOutcomes := map[string]map[string]map[string]bool{}
+	revisionPipelines := map[string]string{}
+	revisionOrder := []string{}
+
+	for _, a := range attempts {
+		pipeline := fmt.Sprintf("%v/%v/%v", a.RepoSource, a.RepoOwner, a.RepoName)
+		if _, ok := reports[pipeline]; !ok {
+			reports[pipeline] = &FlakinessReport{RepoSource: a.RepoSource, RepoOwner: a.RepoOwner, RepoName: a.RepoName}
+		}
+
+		key := a.revisionKey()
+		if _, ok := revisionOutcomes[key]; !ok {
+			revisionOutcomes[key] = map[string]bool{}
+			stageOutcomes[key] = map[string]map[string]bool{}
+			revisionPipelines[key] = pipeline
+			revisionOrder = append(revisionOrder, key)
+		}
+		revisionOutcomes[key][a.BuildStatus] = true
+		revisionAttempts[key]++
+
+		for stage, status := range a.StageStatuses {
+			if _, ok := stageOutcomes[key][stage]; !ok {
+				stageOutcomes[key][stage] = map[string]bool{}
+			}
+			stageOutcomes[key][stage][status] = true
+		}
+	}
+
+	stages := map[string]map[string]*StageFlakiness{}
+	for _, key := range revisionOrder {
+		pipeline := revisionPipelines[key]
+		report := reports[pipeline]
+
+		if revisionAttempts[key] < 2 {
+			continue
+		}
+
+		report.Revisions++
+		if isFlaky(revisionOutcomes[key], "succeeded", "failed") {
+			report.FlakyRevisions++
+		}
+
+		if _, ok := stages[pipeline]; !ok {
+			stages[pipeline] = map[string]*StageFlakiness{}
+		}
+		for stage, outcomes := range stageOutcomes[key] {
+			if _, ok := stages[pipeline][stage]; !ok {
+				stages[pipeline][stage] = &StageFlakiness{Name: stage}
+			}
+			stages[pipeline][stage].Revisions++
+			if isFlaky(outcomes, contracts.StatusSucceeded, contracts.StatusFailed) {
+				stages[pipeline][stage].FlakyRevisions++
+			}
+		}
+	}
+
+	result := make([]*FlakinessReport, 0, len(reports))
+	for pipeline, report := range reports {
+		report.Score = flakinessScore(report.FlakyRevisions, report.Revisions)
+		for _, s := range stages[pipeline] {
+			s.Score = flakinessScore(s.FlakyRevisions, s.Revisions)
+			report.Stages = append(report.Stages, s)
+		}
+		sort.Slice(report.Stages, func(i, j int) bool {
+			if report.Stages[i].Score != report.Stages[j].Score {
+				return report.Stages[i].Score > report.Stages[j].Score
+			}
+			return report.Stages[i].Name < report.Stages[j].Name
+		})
+		result = append(result, report)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return result[i].GetFullRepoPath() < result[j].GetFullRepoPath()
+	})
+
+	return result
+}
+
+// GetStageStatuses returns the status of each stage in the build log steps, with nested stages prefixed by the name of their parent
+func GetStageStatuses(steps []*contracts.BuildLogStep) map[string]string {
+
+	statuses := map[string]string{}
+
+	var add func(prefix string, steps []*contracts.BuildLogStep)
+	add = func(prefix string, steps []*contracts.BuildLogStep) {
+		for _, s := range steps {
+			if s == nil {
+				continue
+			}
+			statuses[prefix+s.Step] = s.Status
+			add(prefix+s.Step+"/", s.NestedSteps)
+		}
+	}
+	add("", steps)
+
+	return statuses
+}
+
+func (a *BuildAttempt) revisionKey() string {
+	return fmt.Sprintf("%v/%v/%v@%v", a.RepoSource, a.RepoOwner, a.RepoName, a.RepoRevision)
+}
+
+func isFlaky(outcomes map[string]bool, succeeded, failed string) bool {
+	return outcomes[succeeded] && outcomes[failed]
+}
+
+func flakinessScore(flaky, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(flaky) / float64(total)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"testing"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeFlakinessReports(t *testing.T) {
+
+	t.Run("ReturnsScoresForPipelinesAndStagesFlakiestFirst", func(t *testing.T) {
+
+		attempts := []*BuildAttempt{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r1", BuildStatus: "failed", StageStatuses: map[string]string{"build": contracts.StatusSucceeded, "test": contracts.StatusFailed}},
+			{ID: "2", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r1", BuildStatus: "succeeded", StageStatuses: map[string]string{"build": contracts.StatusSucceeded, "test": contracts.StatusSucceeded}},
+			{ID: "3", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r2", BuildStatus: "succeeded"},
+			{ID: "4", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-b", RepoRevision: "r3", BuildStatus: "failed"},
+			{ID: "5", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-b", RepoRevision: "r3", BuildStatus: "failed"},
+		}
+
+		// act
+		reports := ComputeFlakinessReports(attempts)
+
+		if assert.Equal(t, 2, len(reports)) {
+			assert.Equal(t, "github.com/estafette/repo-a", reports[0].GetFullRepoPath())
+			assert.Equal(t, 1, reports[0].Revisions)
+			assert.Equal(t, 1, reports[0].FlakyRevisions)
+			assert.Equal(t, 1.0, reports[0].Score)
+			if assert.Equal(t, 2, len(reports[0].Stages)) {
+				assert.Equal(t, "test", reports[0].Stages[0].Name)
+				assert.Equal(t, 1.0, reports[0].Stages[0].Score)
+				assert.Equal(t, "build", reports[0].Stages[1].Name)
+				assert.Equal(t, 0.0, reports[0].Stages[1].Score)
+			}
+			assert.Equal(t, "github.com/estafette/repo-b", reports[1].GetFullRepoPath())
+			assert.Equal(t, 1, reports[1].Revisions)
+			assert.Equal(t, 0, reports[1].FlakyRevisions)
+		}
+	})
+
+	t.Run("DoesNotCountRevisionsBuiltOnlyOnce", func(t *testing.T) {
+
+		attempts := []*BuildAttempt{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r1", BuildStatus: "failed"},
+			{ID: "2", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r1", BuildStatus: "succeeded"},
+			{ID: "3", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r2", BuildStatus: "succeeded"},
+			{ID: "4", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r3", BuildStatus: "failed"},
+			{ID: "5", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r4", BuildStatus: "succeeded"},
+			{ID: "6", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r4", BuildStatus: "succeeded"},
+		}
+
+		// act
+		reports := ComputeFlakinessReports(attempts)
+
+		if assert.Equal(t, 1, len(reports)) {
+			assert.Equal(t, 2, reports[0].Revisions)
+			assert.Equal(t, 1, reports[0].FlakyRevisions)
+			assert.Equal(t, 0.5, reports[0].Score)
+		}
+	})
+}
+
+func TestGetRetriedBuildIDs(t *testing.T) {
+
+	t.Run("ReturnsIDsOfBuildsOfRevisionsBuiltMoreThanOnce", func(t *testing.T) {
+
+		attempts := []*BuildAttempt{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r1"},
+			{ID: "2", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r2"},
+			{ID: "3", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", RepoRevision: "r1"},
+		}
+
+		// act
+		ids := GetRetriedBuildIDs(attempts)
+
+		assert.Equal(t, []string{"1", "3"}, ids)
+	})
+}
+
+func TestGetStageStatuses(t *testing.T) {
+
+	t.Run("ReturnsStatusesOfStagesAndNestedStages", func(t *testing.T) {
+
+		steps := []*contracts.BuildLogStep{
+			{Step: "build", Status: contracts.StatusSucceeded},
+			{Step: "tests", Status: contracts.StatusFailed, NestedSteps: []*contracts.BuildLogStep{
+				{Step: "unit", Status: contracts.StatusSucceeded},
+				{Step: "integration", Status: contracts.StatusFailed},
+			}},
+		}
+
+		// act
+		statuses := GetStageStatuses(steps)
+
+		assert.Equal(t, map[string]string{
+			"build":             contracts.StatusSucceeded,
+			"tests":             contracts.StatusFailed,
+			"tests/unit":        contracts.StatusSucceeded,
+			"tests/integration": contracts.StatusFailed,
+		}, statuses)
+	})
+}
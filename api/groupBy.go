@@ -0,0 +1,59 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// ErrInvalidGroupBy is returned for a groupBy that isn't pipeline, group, organization or label:<key>
+var ErrInvalidGroupBy = errors.New("groupBy should be one of pipeline, group, organization or label:<key>")
+
+const (
+	GroupByPipeline     = "pipeline"
+	GroupByGroup        = "group"
+	GroupByOrganization = "organization"
+	GroupByLabelPrefix  = "label:"
+)
+
+// ValidateGroupBy returns ErrInvalidGroupBy if stats can't be grouped by groupBy; empty means no grouping
+func ValidateGroupBy(groupBy string) error {
+	switch groupBy {
+	case "", GroupByPipeline, GroupByGroup, GroupByOrganization:
+		return nil
+	}
+	if strings.HasPrefix(groupBy, GroupByLabelPrefix) && strings.TrimPrefix(groupBy, GroupByLabelPrefix) != "" {
+		return nil
+	}
+
+	return fmt.Errorf("%v %w", groupBy, ErrInvalidGroupBy)
+}
+
+// getGroupKeys returns the keys of the groups an item of a pipeline counts towards; an item can be part of multiple groups or organizations, or of none
+func getGroupKeys(groupBy, repoSource, repoOwner, repoName string, labels []contracts.Label, groups []*contracts.Group, organizations []*contracts.Organization) (keys []string) {
+	switch {
+	case groupBy == "":
+		return []string{""}
+	case groupBy == GroupByPipeline:
+		return []string{fmt.Sprintf("%v/%v/%v", repoSource, repoOwner, repoName)}
+	case groupBy == GroupByGroup:
+		for _, g := range groups {
+			keys = append(keys, g.Name)
+		}
+	case groupBy == GroupByOrganization:
+		for _, o := range organizations {
+			keys = append(keys, o.Name)
+		}
+	case strings.HasPrefix(groupBy, GroupByLabelPrefix):
+		labelKey := strings.TrimPrefix(groupBy, GroupByLabelPrefix)
+		for _, l := range labels {
+			if l.Key == labelKey {
+				keys = append(keys, l.Value)
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGroupBy(t *testing.T) {
+
+	t.Run("ReturnsNilForSupportedGroupBys", func(t *testing.T) {
+
+		for _, groupBy := range []string{"", "pipeline", "group", "organization", "label:team"} {
+			assert.Nil(t, ValidateGroupBy(groupBy), groupBy)
+		}
+	})
+
+	t.Run("ReturnsErrInvalidGroupByForLabelWithoutKey", func(t *testing.T) {
+
+		// act
+		err := ValidateGroupBy("label:")
+
+		assert.True(t, errors.Is(err, ErrInvalidGroupBy))
+	})
+}
@@ -24,6 +24,15 @@ import (
 var (
 	// ErrInvalidSigningAlgorithm indicates signing algorithm is invalid, needs to be HS256, HS384, HS512, RS256, RS384 or RS512
 	ErrInvalidSigningAlgorithm = errors.New("invalid signing algorithm")
+
+	// ErrInvalidPageCursor indicates the page[after] cursor wasn't returned by the same list endpoint
+	ErrInvalidPageCursor = errors.New("invalid page cursor")
+
+	// ErrRevisionNotOnBranch indicates a revision to build isn't reachable from the branch it's built for
+	ErrRevisionNotOnBranch = errors.New("revision is not reachable from branch")
+
+	// ErrUnverifiedEmail indicates an identity provider returned no email address or one that isn't verified; users are matched on email address, so these can't log in
+	ErrUnverifiedEmail = errors.New("identity has no verified email address")
 )
 
 func GenerateJWT(config *APIConfig, validDuration time.Duration, optionalClaims jwtgo.MapClaims) (tokenString string, err error) {
@@ -34,7 +43,7 @@ func GenerateJWT(config *APIConfig, validDuration time.Duration, optionalClaims
 
 	// set required claims
 	now := time.Now()
-	expire := now.Add(time.Hour)
+	expire := now.Add(validDuration)
 	claims["exp"] = expire.Unix()
 	claims["orig_iat"] = now.Unix()
 
@@ -244,7 +253,8 @@ func RequestTokenHasRole(c *gin.Context, role Role) bool {
 
 	for _, r := range roles {
 		if rval, ok := r.(string); ok && rval == role.String() {
-			return true
+			// a personal access token only carries a role if its scopes include all of the role's permissions
+			return requestTokenScopesAllowRole(c, role)
 		}
 	}
 
@@ -298,15 +308,207 @@ func GetRolesFromRequest(c *gin.Context) (roles []Role) {
 
 func GetPermissionsFromRequest(c *gin.Context) (permissions []Permission) {
 
-	roles := GetRolesFromRequest(c)
+	permissions = GetPermissionsForRoles(GetRolesFromRequest(c))
+
+	// add permissions from custom roles that aren't limited to specific pipelines
+	for _, r := range GetCustomRolesFromRequest(c) {
+		if r.Scope.IsScoped() {
+			continue
+		}
+		for _, p := range getPermissionsForCustomRole(r) {
+			if !PermissionArrayContains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+
+	// limit permissions to the scopes of a personal access token
+	if scopes, isPersonalAccessToken := getScopesFromRequest(c); isPersonalAccessToken {
+		scopedPermissions := []Permission{}
+		for _, p := range permissions {
+			if StringArrayContains(scopes, p.String()) {
+				scopedPermissions = append(scopedPermissions, p)
+			}
+		}
+		return scopedPermissions
+	}
 
+	return
+}
+
+// GetPermissionsForRoles returns all permissions granted by a list of roles
+func GetPermissionsForRoles(roles []Role) (permissions []Permission) {
 	for _, r := range roles {
-		permissions = append(permissions, rolesToPermissionMap[r]...)
+		for _, p := range rolesToPermissionMap[r] {
+			if !PermissionArrayContains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
 	}
 
 	return
 }
 
+// GetCustomRolesFromRequest returns the custom roles resolved at login time
+func GetCustomRolesFromRequest(c *gin.Context) (customRoles []*CustomRole) {
+
+	if !RequestTokenIsValid(c) {
+		return
+	}
+
+	claims := jwt.ExtractClaims(c)
+	val, ok := claims["customRoles"]
+	if !ok {
+		return
+	}
+
+	// the claim is deserialized into generic maps, so round-trip it through json to get typed values
+	customRolesBytes, err := json.Marshal(val)
+	if err != nil {
+		return nil
+	}
+	if err = json.Unmarshal(customRolesBytes, &customRoles); err != nil {
+		return nil
+	}
+
+	return
+}
+
+func getPermissionsForCustomRole(customRole *CustomRole) (permissions []Permission) {
+	if customRole == nil {
+		return
+	}
+
+	for _, p := range customRole.Permissions {
+		permission := ToPermission(p)
+		if permission != nil && !PermissionArrayContains(permissions, *permission) {
+			permissions = append(permissions, *permission)
+		}
+	}
+
+	return
+}
+
+// NewAuditEventFromRequest returns an audit event for an action, with the actor, impersonator and client taken from the request
+func NewAuditEventFromRequest(c *gin.Context, action, targetType, targetID string, before, after interface{}) AuditEvent {
+
+	event := AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     before,
+		After:      after,
+		SourceIP:   c.ClientIP(),
+	}
+
+	if !RequestTokenIsValid(c) {
+		return event
+	}
+
+	claims := jwt.ExtractClaims(c)
+	event.ActorID, _ = claims[jwt.IdentityKey].(string)
+	event.ActorEmail, _ = claims["email"].(string)
+	event.ImpersonatorID, _ = claims["impersonatorID"].(string)
+	event.ImpersonatorEmail, _ = claims["impersonatorEmail"].(string)
+	event.ClientID, _ = claims["clientID"].(string)
+
+	return event
+}
+
+// RequestTokenIsPersonalAccessToken returns true if the request is authenticated with a personal access token instead of a login session
+func RequestTokenIsPersonalAccessToken(c *gin.Context) bool {
+	return GetPersonalAccessTokenIDFromRequest(c) != ""
+}
+
+// RequestTokenIsImpersonationOrClient returns true if the request identity is a user impersonated by someone else or an api client instead of the user themselves
+func RequestTokenIsImpersonationOrClient(c *gin.Context) bool {
+
+	claims := jwt.ExtractClaims(c)
+	if impersonatorID, ok := claims["impersonatorID"].(string); ok && impersonatorID != "" {
+		return true
+	}
+	if clientID, ok := claims["clientID"].(string); ok && clientID != "" {
+		return true
+	}
+
+	return false
+}
+
+// GetPersonalAccessTokenIDFromRequest returns the id of the personal access token used for the request, if any
+func GetPersonalAccessTokenIDFromRequest(c *gin.Context) string {
+
+	claims := jwt.ExtractClaims(c)
+	val, ok := claims["tokenID"]
+	if !ok {
+		return ""
+	}
+	tokenID, ok := val.(string)
+	if !ok {
+		return ""
+	}
+
+	return tokenID
+}
+
+func getScopesFromRequest(c *gin.Context) (scopes []string, isPersonalAccessToken bool) {
+
+	if !RequestTokenIsPersonalAccessToken(c) {
+		return nil, false
+	}
+
+	claims := jwt.ExtractClaims(c)
+	val, ok := claims["scopes"]
+	if !ok {
+		return []string{}, true
+	}
+
+	scopesFromClaim, ok := val.([]interface{})
+	if !ok {
+		return []string{}, true
+	}
+
+	scopes = []string{}
+	for _, s := range scopesFromClaim {
+		if sval, ok := s.(string); ok {
+			scopes = append(scopes, sval)
+		}
+	}
+
+	return scopes, true
+}
+
+func requestTokenScopesAllowRole(c *gin.Context, role Role) bool {
+
+	scopes, isPersonalAccessToken := getScopesFromRequest(c)
+	if !isPersonalAccessToken {
+		return true
+	}
+
+	// roles that don't map to any permission can't be scoped, so they're not available to personal access tokens
+	rolePermissions := rolesToPermissionMap[role]
+	if len(rolePermissions) == 0 {
+		return false
+	}
+
+	for _, p := range rolePermissions {
+		if !StringArrayContains(scopes, p.String()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// PermissionArrayContains returns true if the permission is in the array
+func PermissionArrayContains(array []Permission, value Permission) bool {
+	for _, v := range array {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func RequestTokenHasPermission(c *gin.Context, permission Permission) bool {
 
 	permissions := GetPermissionsFromRequest(c)
@@ -320,6 +522,57 @@ func RequestTokenHasPermission(c *gin.Context, permission Permission) bool {
 	return false
 }
 
+// RequestTokenHasPermissionForResource checks whether the request has a permission in general or through a custom role scoped to the resource
+func RequestTokenHasPermissionForResource(c *gin.Context, permission Permission, resource PermissionResource) bool {
+
+	if RequestTokenHasPermission(c, permission) {
+		return true
+	}
+
+	// a personal access token can't carry a permission outside of its scopes
+	if scopes, isPersonalAccessToken := getScopesFromRequest(c); isPersonalAccessToken && !StringArrayContains(scopes, permission.String()) {
+		return false
+	}
+
+	for _, r := range GetCustomRolesFromRequest(c) {
+		if !r.Scope.IsScoped() || !PermissionArrayContains(getPermissionsForCustomRole(r), permission) {
+			continue
+		}
+		if r.Scope.Matches(resource) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetPermissionResourceForPipeline returns the resource to check scoped permissions against for a pipeline and optional release target
+func GetPermissionResourceForPipeline(organizations []*contracts.Organization, groups []*contracts.Group, labels []contracts.Label, releaseTarget string) PermissionResource {
+
+	resource := PermissionResource{
+		Organizations: []string{},
+		Groups:        []string{},
+		Labels:        map[string]string{},
+		ReleaseTarget: releaseTarget,
+	}
+
+	for _, o := range organizations {
+		if o != nil {
+			resource.Organizations = append(resource.Organizations, o.Name)
+		}
+	}
+	for _, g := range groups {
+		if g != nil {
+			resource.Groups = append(resource.Groups, g.Name)
+		}
+	}
+	for _, l := range labels {
+		resource.Labels[l.Key] = l.Value
+	}
+
+	return resource
+}
+
 func GetGroupsFromRequest(c *gin.Context) (groups []string) {
 
 	if !RequestTokenIsValid(c) {
@@ -408,6 +661,15 @@ func StringArrayContains(array []string, value string) bool {
 	return false
 }
 
+func stringArraysIntersect(a, b []string) bool {
+	for _, v := range a {
+		if StringArrayContains(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetQueryParameters extracts query parameters specified according to https://jsonapi.org/format/
 func GetQueryParameters(c *gin.Context) (int, int, map[FilterType][]string, []OrderField) {
 	return GetPageNumber(c), GetPageSize(c), GetFilters(c), GetSorting(c)
@@ -440,6 +702,37 @@ func GetPageSize(c *gin.Context) int {
 	return pageSize
 }
 
+// GetPageAfter extracts the keyset cursor to page after; an empty page[after] requests the first page by cursor instead of page number
+func GetPageAfter(c *gin.Context) (after string, usesCursor bool) {
+	return c.GetQuery("page[after]")
+}
+
+// GetPageCount returns true if the total count is requested for paging by cursor, since counting is expensive for large lists
+func GetPageCount(c *gin.Context) bool {
+	withCount, _ := strconv.ParseBool(c.DefaultQuery("page[count]", "false"))
+	return withCount
+}
+
+// EncodePageCursor returns an opaque cursor for the values of the key columns of the last item of a page
+func EncodePageCursor(values ...string) string {
+	cursorBytes, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+// DecodePageCursor returns the values of the key columns in a cursor created by EncodePageCursor
+func DecodePageCursor(cursor string) (values []string, err error) {
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidPageCursor
+	}
+	err = json.Unmarshal(cursorBytes, &values)
+	if err != nil || len(values) == 0 {
+		return nil, ErrInvalidPageCursor
+	}
+
+	return values, nil
+}
+
 // GetSorting extracts sorting parameters specified according to https://jsonapi.org/format/
 func GetSorting(c *gin.Context) (sorting []OrderField) {
 	// ?sort=-created,title
@@ -465,10 +758,11 @@ func GetSorting(c *gin.Context) (sorting []OrderField) {
 
 // GetFilters extracts specific filter parameters specified according to https://jsonapi.org/format/
 func GetFilters(c *gin.Context) map[FilterType][]string {
-	// get filters (?filter[status]=running,succeeded&filter[since]=1w&filter[labels]=team%3Destafette-team)
+	// get filters (?filter[status]=running,succeeded&filter[since]=1w&filter[until]=2020-05-01&filter[labels]=team%3Destafette-team)
 	filters := map[FilterType][]string{}
 	filters[FilterStatus] = GetStatusFilter(c)
 	filters[FilterSince] = GetSinceFilter(c)
+	filters[FilterUntil] = GetUntilFilter(c)
 	filters[FilterLabels] = GetLabelsFilter(c)
 	filters[FilterSearch] = GetGenericFilter(c, FilterSearch)
 	filters[FilterRecentCommitter] = GetGenericFilter(c, FilterRecentCommitter)
@@ -478,6 +772,10 @@ func GetFilters(c *gin.Context) map[FilterType][]string {
 	filters[FilterPipeline] = GetGenericFilter(c, FilterPipeline)
 	filters[FilterParent] = GetGenericFilter(c, FilterParent)
 	filters[FilterEntity] = GetGenericFilter(c, FilterEntity)
+	filters[FilterActor] = GetGenericFilter(c, FilterActor)
+	filters[FilterAction] = GetGenericFilter(c, FilterAction)
+	filters[FilterTargetType] = GetGenericFilter(c, FilterTargetType)
+	filters[FilterTargetID] = GetGenericFilter(c, FilterTargetID)
 
 	return filters
 }
@@ -504,6 +802,18 @@ func GetLastFilter(c *gin.Context, defaultValue int) []string {
 	return GetGenericFilter(c, FilterLast, strconv.Itoa(defaultValue))
 }
 
+// GetCappedLastFilter extracts a filter to select the last n items like GetLastFilter does, but never more than maxValue
+func GetCappedLastFilter(c *gin.Context, defaultValue, maxValue int) []string {
+	last := GetLastFilter(c, defaultValue)
+	if len(last) == 1 {
+		if value, err := strconv.Atoi(last[0]); err == nil && value > maxValue {
+			return []string{strconv.Itoa(maxValue)}
+		}
+	}
+
+	return last
+}
+
 // GetSinceFilter extracts a filter on build/release date
 func GetSinceFilter(c *gin.Context) []string {
 	return GetGenericFilter(c, FilterSince, "eternity")
@@ -577,3 +887,70 @@ func GetPagedListResponse(itemsFunc func() ([]interface{}, error), countFunc fun
 
 	return response, nil
 }
+
+// GetListResponse pages by cursor if page[after] is set and returns a CursorListResponse, otherwise it pages by page number and returns a contracts.ListResponse; itemsFunc gets the number of items to retrieve
+func GetListResponse(c *gin.Context, pageNumber, pageSize int, filters map[FilterType][]string, itemsFunc func(pageSize int) ([]interface{}, error), countFunc func() (int, error), cursorFunc func(item interface{}) string) (interface{}, error) {
+
+	after, usesCursor := GetPageAfter(c)
+	if !usesCursor {
+		return GetPagedListResponse(func() ([]interface{}, error) { return itemsFunc(pageSize) }, countFunc, pageNumber, pageSize)
+	}
+
+	if after != "" {
+		if _, err := DecodePageCursor(after); err != nil {
+			return nil, err
+		}
+	}
+	filters[FilterAfter] = []string{after}
+
+	return GetCursorListResponse(func() ([]interface{}, error) { return itemsFunc(pageSize + 1) }, countFunc, cursorFunc, after, pageSize, GetPageCount(c))
+}
+
+// GetCursorListResponse runs an item query for pageSize+1 items to find out whether there's a next page, and optionally a count query in parallel, and returns them as a CursorListResponse
+func GetCursorListResponse(itemsFunc func() ([]interface{}, error), countFunc func() (int, error), cursorFunc func(item interface{}) string, after string, pageSize int, withCount bool) (CursorListResponse, error) {
+
+	type CountResult struct {
+		count int
+		err   error
+	}
+
+	// buffered so the count query doesn't block if the item query fails
+	countChannel := make(chan CountResult, 1)
+	go func() {
+		defer close(countChannel)
+		if !withCount {
+			return
+		}
+		count, err := countFunc()
+
+		countChannel <- CountResult{count, err}
+	}()
+
+	items, err := itemsFunc()
+	if err != nil {
+		return CursorListResponse{}, err
+	}
+
+	response := CursorListResponse{
+		Items: items,
+		Pagination: CursorPagination{
+			Size:  pageSize,
+			After: after,
+		},
+	}
+
+	if len(items) > pageSize {
+		response.Items = items[:pageSize]
+		response.Pagination.Next = cursorFunc(items[pageSize-1])
+	}
+
+	countResult, ok := <-countChannel
+	if ok {
+		if countResult.err != nil {
+			return CursorListResponse{}, countResult.err
+		}
+		response.Pagination.TotalItems = &countResult.count
+	}
+
+	return response, nil
+}
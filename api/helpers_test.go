@@ -1,10 +1,16 @@
 package api
 
 import (
+	"errors"
 	"io/ioutil"
+	"net/http/httptest"
 	"regexp"
+	"strconv"
 	"testing"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/gin-gonic/gin"
 	"github.com/sethgrid/pester"
 	"github.com/stretchr/testify/assert"
 )
@@ -43,3 +49,457 @@ func TestRetrievingGoogleJSONWebKeys(t *testing.T) {
 		}
 	})
 }
+
+func TestGetPermissionsFromRequest(t *testing.T) {
+	t.Run("ReturnsAllPermissionsForRolesInLoginSession", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"catalog.entities.viewer"},
+		})
+
+		// act
+		permissions := GetPermissionsFromRequest(c)
+
+		assert.Equal(t, []Permission{PermissionCatalogEntitiesList, PermissionCatalogEntitiesGet}, permissions)
+	})
+
+	t.Run("ReturnsOnlyScopedPermissionsForPersonalAccessToken", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"administrator"},
+			"tokenID":       "12",
+			"scopes":        []interface{}{"ci.builds.list", "ci.builds.get"},
+		})
+
+		// act
+		permissions := GetPermissionsFromRequest(c)
+
+		assert.Equal(t, []Permission{PermissionBuildsList, PermissionBuildsGet}, permissions)
+	})
+
+	t.Run("ReturnsNoPermissionsForPersonalAccessTokenWithoutScopes", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"administrator"},
+			"tokenID":       "12",
+		})
+
+		// act
+		permissions := GetPermissionsFromRequest(c)
+
+		assert.Equal(t, 0, len(permissions))
+	})
+
+	t.Run("ReturnsPermissionsForUnscopedCustomRoles", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"catalog.entities.viewer", "release-staging", "build-operator"},
+			"customRoles": []interface{}{
+				map[string]interface{}{
+					"name":        "release-staging",
+					"permissions": []interface{}{"ci.releases.create"},
+					"scope": map[string]interface{}{
+						"releaseTargets": []interface{}{"staging"},
+					},
+				},
+				map[string]interface{}{
+					"name":        "build-operator",
+					"permissions": []interface{}{"ci.builds.cancel", "ci.builds.rebuild"},
+				},
+			},
+		})
+
+		// act
+		permissions := GetPermissionsFromRequest(c)
+
+		assert.Equal(t, []Permission{PermissionCatalogEntitiesList, PermissionCatalogEntitiesGet, PermissionBuildsCancel, PermissionBuildsRebuild}, permissions)
+	})
+}
+
+func TestRequestTokenHasPermissionForResource(t *testing.T) {
+
+	getContext := func(claims jwt.MapClaims) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", claims)
+		return c
+	}
+
+	releaseStagingClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"release-staging"},
+			"customRoles": []interface{}{
+				map[string]interface{}{
+					"name":        "release-staging",
+					"permissions": []interface{}{"ci.releases.create"},
+					"scope": map[string]interface{}{
+						"organizations":  []interface{}{"Org A"},
+						"labels":         map[string]interface{}{"team": "estafette"},
+						"releaseTargets": []interface{}{"staging"},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("ReturnsTrueIfScopedCustomRoleMatchesResource", func(t *testing.T) {
+
+		c := getContext(releaseStagingClaims())
+
+		// act
+		hasPermission := RequestTokenHasPermissionForResource(c, PermissionReleasesCreate, PermissionResource{
+			Organizations: []string{"Org A"},
+			Labels:        map[string]string{"team": "estafette", "language": "golang"},
+			ReleaseTarget: "staging",
+		})
+
+		assert.True(t, hasPermission)
+	})
+
+	t.Run("ReturnsFalseIfReleaseTargetIsOutsideOfScope", func(t *testing.T) {
+
+		c := getContext(releaseStagingClaims())
+
+		// act
+		hasPermission := RequestTokenHasPermissionForResource(c, PermissionReleasesCreate, PermissionResource{
+			Organizations: []string{"Org A"},
+			Labels:        map[string]string{"team": "estafette"},
+			ReleaseTarget: "production",
+		})
+
+		assert.False(t, hasPermission)
+	})
+
+	t.Run("ReturnsFalseForBuildPermissionOfCustomRoleScopedToReleaseTargets", func(t *testing.T) {
+
+		claims := releaseStagingClaims()
+		claims["customRoles"].([]interface{})[0].(map[string]interface{})["permissions"] = []interface{}{"ci.releases.create", "ci.builds.create"}
+		c := getContext(claims)
+
+		// act
+		hasPermission := RequestTokenHasPermissionForResource(c, PermissionBuildsCreate, PermissionResource{
+			Organizations: []string{"Org A"},
+			Labels:        map[string]string{"team": "estafette"},
+		})
+
+		assert.False(t, hasPermission)
+	})
+
+	t.Run("ReturnsFalseIfLabelsDoNotMatchScope", func(t *testing.T) {
+
+		c := getContext(releaseStagingClaims())
+
+		// act
+		hasPermission := RequestTokenHasPermissionForResource(c, PermissionReleasesCreate, PermissionResource{
+			Organizations: []string{"Org A"},
+			Labels:        map[string]string{"team": "other"},
+			ReleaseTarget: "staging",
+		})
+
+		assert.False(t, hasPermission)
+	})
+
+	t.Run("ReturnsTrueIfLabelSelectorScopeMatchesResource", func(t *testing.T) {
+
+		c := getContext(jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"release-staging"},
+			"customRoles": []interface{}{
+				map[string]interface{}{
+					"name":        "release-staging",
+					"permissions": []interface{}{"ci.releases.create"},
+					"scope": map[string]interface{}{
+						"labelSelector": "team in (estafette,other),tier!=experimental",
+					},
+				},
+			},
+		})
+
+		// act
+		hasPermission := RequestTokenHasPermissionForResource(c, PermissionReleasesCreate, PermissionResource{
+			Labels: map[string]string{"team": "other"},
+		})
+		hasPermissionForExperimental := RequestTokenHasPermissionForResource(c, PermissionReleasesCreate, PermissionResource{
+			Labels: map[string]string{"team": "other", "tier": "experimental"},
+		})
+
+		assert.True(t, hasPermission)
+		assert.False(t, hasPermissionForExperimental)
+	})
+
+	t.Run("ReturnsFalseForScopedCustomRoleWithoutResource", func(t *testing.T) {
+
+		c := getContext(releaseStagingClaims())
+
+		// act
+		hasPermission := RequestTokenHasPermission(c, PermissionReleasesCreate)
+
+		assert.False(t, hasPermission)
+	})
+
+	t.Run("ReturnsTrueIfBuiltInRoleHasPermission", func(t *testing.T) {
+
+		c := getContext(jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"administrator"},
+		})
+
+		// act
+		hasPermission := RequestTokenHasPermissionForResource(c, PermissionReleasesCreate, PermissionResource{
+			ReleaseTarget: "production",
+		})
+
+		assert.True(t, hasPermission)
+	})
+
+	t.Run("ReturnsFalseIfPersonalAccessTokenScopesExcludePermission", func(t *testing.T) {
+
+		claims := releaseStagingClaims()
+		claims["tokenID"] = "12"
+		claims["scopes"] = []interface{}{"ci.builds.list"}
+		c := getContext(claims)
+
+		// act
+		hasPermission := RequestTokenHasPermissionForResource(c, PermissionReleasesCreate, PermissionResource{
+			Organizations: []string{"Org A"},
+			Labels:        map[string]string{"team": "estafette"},
+			ReleaseTarget: "staging",
+		})
+
+		assert.False(t, hasPermission)
+	})
+}
+
+func TestNewAuditEventFromRequest(t *testing.T) {
+
+	t.Run("ReturnsEventWithActorAndImpersonatorFromClaims", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/api/users", nil)
+		c.Request.RemoteAddr = "10.0.0.1:1234"
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey:     "5",
+			"email":             "me@estafette.io",
+			"impersonatorID":    "1",
+			"impersonatorEmail": "admin@estafette.io",
+		})
+
+		// act
+		event := NewAuditEventFromRequest(c, PermissionUsersCreate.String(), "user", "6", nil, map[string]string{"name": "Other"})
+
+		assert.Equal(t, "5", event.ActorID)
+		assert.Equal(t, "me@estafette.io", event.ActorEmail)
+		assert.Equal(t, "1", event.ImpersonatorID)
+		assert.Equal(t, "admin@estafette.io", event.ImpersonatorEmail)
+		assert.Equal(t, "10.0.0.1", event.SourceIP)
+		assert.Equal(t, "rbac.users.create", event.Action)
+		assert.Equal(t, "user", event.TargetType)
+		assert.Equal(t, "6", event.TargetID)
+	})
+}
+
+func TestRequestTokenHasRole(t *testing.T) {
+	t.Run("ReturnsTrueIfLoginSessionHasRole", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"administrator"},
+		})
+
+		// act
+		hasRole := RequestTokenHasRole(c, RoleAdministrator)
+
+		assert.True(t, hasRole)
+	})
+
+	t.Run("ReturnsFalseIfPersonalAccessTokenScopesDoNotCoverRole", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"administrator"},
+			"tokenID":       "12",
+			"scopes":        []interface{}{"ci.builds.list"},
+		})
+
+		// act
+		hasRole := RequestTokenHasRole(c, RoleAdministrator)
+
+		assert.False(t, hasRole)
+	})
+
+	t.Run("ReturnsTrueIfPersonalAccessTokenScopesCoverRole", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"catalog.entities.viewer"},
+			"tokenID":       "12",
+			"scopes":        []interface{}{"catalog.entities.list", "catalog.entities.get"},
+		})
+
+		// act
+		hasRole := RequestTokenHasRole(c, RoleCatalogEntitiesViewer)
+
+		assert.True(t, hasRole)
+	})
+
+	t.Run("ReturnsFalseForPersonalAccessTokenWithRoleWithoutPermissions", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"cron.trigger"},
+			"tokenID":       "12",
+			"scopes":        []interface{}{"ci.builds.list"},
+		})
+
+		// act
+		hasRole := RequestTokenHasRole(c, RoleCronTrigger)
+
+		assert.False(t, hasRole)
+	})
+}
+
+func TestDecodePageCursor(t *testing.T) {
+	t.Run("ReturnsValuesEncodedWithEncodePageCursor", func(t *testing.T) {
+
+		cursor := EncodePageCursor("2020-04-01T10:00:00.123456Z", "551447279823060993")
+
+		// act
+		values, err := DecodePageCursor(cursor)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"2020-04-01T10:00:00.123456Z", "551447279823060993"}, values)
+	})
+
+	t.Run("ReturnsErrInvalidPageCursorForTamperedCursor", func(t *testing.T) {
+
+		// act
+		_, err := DecodePageCursor("not-a-cursor")
+
+		assert.True(t, errors.Is(err, ErrInvalidPageCursor))
+	})
+}
+
+func TestGetListResponse(t *testing.T) {
+
+	itemsFunc := func(pageSize int) ([]interface{}, error) {
+		items := make([]interface{}, pageSize)
+		for i := range items {
+			items[i] = strconv.Itoa(i + 1)
+		}
+		return items, nil
+	}
+	countFunc := func() (int, error) {
+		return 55, nil
+	}
+	cursorFunc := func(item interface{}) string {
+		return EncodePageCursor(item.(string))
+	}
+
+	t.Run("ReturnsPagedListResponseWithoutPageAfter", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/pipelines?page[number]=2&page[size]=10", nil)
+		filters := map[FilterType][]string{}
+
+		// act
+		response, err := GetListResponse(c, 2, 10, filters, itemsFunc, countFunc, cursorFunc)
+
+		assert.Nil(t, err)
+		listResponse, ok := response.(contracts.ListResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, 10, len(listResponse.Items))
+			assert.Equal(t, 6, listResponse.Pagination.TotalPages)
+		}
+		_, usesCursor := filters[FilterAfter]
+		assert.False(t, usesCursor)
+	})
+
+	t.Run("ReturnsCursorListResponseWithNextCursorAndWithoutCount", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/pipelines?page[after]=&page[size]=10", nil)
+		filters := map[FilterType][]string{}
+
+		// act
+		response, err := GetListResponse(c, 1, 10, filters, itemsFunc, countFunc, cursorFunc)
+
+		assert.Nil(t, err)
+		cursorResponse, ok := response.(CursorListResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, 10, len(cursorResponse.Items))
+			assert.Equal(t, EncodePageCursor("10"), cursorResponse.Pagination.Next)
+			assert.Nil(t, cursorResponse.Pagination.TotalItems)
+		}
+		assert.Equal(t, []string{""}, filters[FilterAfter])
+	})
+
+	t.Run("ReturnsCursorListResponseWithoutNextCursorOnLastPage", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		cursor := EncodePageCursor("10")
+		c.Request = httptest.NewRequest("GET", "/api/pipelines?page[after]="+cursor+"&page[count]=true", nil)
+		filters := map[FilterType][]string{}
+
+		// act
+		response, err := GetListResponse(c, 1, 10, filters, func(pageSize int) ([]interface{}, error) { return []interface{}{"11", "12"}, nil }, countFunc, cursorFunc)
+
+		assert.Nil(t, err)
+		cursorResponse, ok := response.(CursorListResponse)
+		if assert.True(t, ok) {
+			assert.Equal(t, 2, len(cursorResponse.Items))
+			assert.Equal(t, "", cursorResponse.Pagination.Next)
+			assert.Equal(t, cursor, cursorResponse.Pagination.After)
+			if assert.NotNil(t, cursorResponse.Pagination.TotalItems) {
+				assert.Equal(t, 55, *cursorResponse.Pagination.TotalItems)
+			}
+		}
+	})
+
+	t.Run("ReturnsErrInvalidPageCursorForInvalidCursor", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/pipelines?page[after]=abc", nil)
+
+		// act
+		_, err := GetListResponse(c, 1, 10, map[FilterType][]string{}, itemsFunc, countFunc, cursorFunc)
+
+		assert.True(t, errors.Is(err, ErrInvalidPageCursor))
+	})
+}
+
+func TestGetCappedLastFilter(t *testing.T) {
+
+	t.Run("ReturnsDefaultValueWithoutFilter", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/stats/stageimages", nil)
+
+		// act
+		last := GetCappedLastFilter(c, 1000, 5000)
+
+		assert.Equal(t, []string{"1000"}, last)
+	})
+
+	t.Run("ReturnsMaxValueForLargerFilter", func(t *testing.T) {
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/stats/stageimages?filter[last]=1000000", nil)
+
+		// act
+		last := GetCappedLastFilter(c, 1000, 5000)
+
+		assert.Equal(t, []string{"5000"}, last)
+	})
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidLabelSelector is returned for label selectors that can't be parsed
+var ErrInvalidLabelSelector = errors.New("invalid label selector")
+
+// LabelSelectorOperator is the way a label requirement matches the value of a label
+type LabelSelectorOperator string
+
+const (
+	LabelSelectorEquals       LabelSelectorOperator = "="
+	LabelSelectorNotEquals    LabelSelectorOperator = "!="
+	LabelSelectorIn           LabelSelectorOperator = "in"
+	LabelSelectorNotIn        LabelSelectorOperator = "notin"
+	LabelSelectorExists       LabelSelectorOperator = "exists"
+	LabelSelectorDoesNotExist LabelSelectorOperator = "!"
+)
+
+var (
+	labelKeyRegex         = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_./]*[a-zA-Z0-9])?$`)
+	labelSetRequirementRe = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+	labelSetOperatorRe    = regexp.MustCompile(`\s(in|notin)\s*\(`)
+)
+
+// LabelRequirement is a single requirement of a label selector, like team in (a,b)
+type LabelRequirement struct {
+	Key      string
+	Operator LabelSelectorOperator
+	Values   []string
+}
+
+// LabelSelector selects labeled items matching all of its requirements, using the Kubernetes label selector syntax
+type LabelSelector []LabelRequirement
+
+// ParseLabelSelectors parses one or more label selectors like team in (a,b),tier!=experimental into a single selector requiring all of them
+func ParseLabelSelectors(selectors []string) (LabelSelector, error) {
+
+	selector := LabelSelector{}
+	for _, s := range selectors {
+		// filter[labels] used to take plain key=value pairs with anything after the = as value, so keep reading those the same way instead of splitting a value like a,b into requirements
+		if isLegacyLabelFilter(s) {
+			if legacyRequirement, ok := parseLegacyLabelRequirement(s); ok {
+				selector = append(selector, legacyRequirement)
+				continue
+			}
+		}
+
+		requirements, err := parseLabelSelector(s)
+		if err != nil {
+			// keep accepting other values the selector syntax can't parse as well
+			legacyRequirement, ok := parseLegacyLabelRequirement(s)
+			if !ok {
+				return nil, err
+			}
+			requirements = []LabelRequirement{legacyRequirement}
+		}
+		selector = append(selector, requirements...)
+	}
+
+	return selector, nil
+}
+
+func parseLabelSelector(selector string) (requirements []LabelRequirement, err error) {
+	for _, r := range splitLabelSelector(selector) {
+		requirement, err := parseLabelRequirement(r)
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, nil
+}
+
+// isLegacyLabelFilter returns true for a single key=value pair without any of the operators only the selector syntax has
+func isLegacyLabelFilter(selector string) bool {
+	if strings.Count(selector, "=") != 1 || strings.Contains(selector, "!=") || labelSetOperatorRe.MatchString(selector) {
+		return false
+	}
+	for _, r := range splitLabelSelector(selector) {
+		if strings.HasPrefix(strings.TrimSpace(r), "!") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseLegacyLabelRequirement parses a key=value pair the way filter[labels] did before it supported label selectors
+func parseLegacyLabelRequirement(selector string) (LabelRequirement, bool) {
+	keyValue := strings.SplitN(selector, "=", 2)
+	if len(keyValue) != 2 || !labelKeyRegex.MatchString(keyValue[0]) {
+		return LabelRequirement{}, false
+	}
+
+	return LabelRequirement{Key: keyValue[0], Operator: LabelSelectorEquals, Values: []string{keyValue[1]}}, true
+}
+
+// Matches returns true if the labels meet all requirements; like in Kubernetes != and notin also match labels without the key
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		value, hasKey := labels[r.Key]
+		switch r.Operator {
+		case LabelSelectorEquals, LabelSelectorIn:
+			if !hasKey || !StringArrayContains(r.Values, value) {
+				return false
+			}
+		case LabelSelectorNotEquals, LabelSelectorNotIn:
+			if hasKey && StringArrayContains(r.Values, value) {
+				return false
+			}
+		case LabelSelectorExists:
+			if !hasKey {
+				return false
+			}
+		case LabelSelectorDoesNotExist:
+			if hasKey {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// splitLabelSelector splits a selector into its requirements on commas outside of parentheses
+func splitLabelSelector(selector string) (requirements []string) {
+	depth := 0
+	start := 0
+	for i, ch := range selector {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				requirements = append(requirements, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	requirements = append(requirements, selector[start:])
+
+	return
+}
+
+func parseLabelRequirement(requirement string) (LabelRequirement, error) {
+
+	requirement = strings.TrimSpace(requirement)
+
+	if matches := labelSetRequirementRe.FindStringSubmatch(requirement); matches != nil {
+		values := []string{}
+		for _, v := range strings.Split(matches[3], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return LabelRequirement{}, fmt.Errorf("%w: %v needs at least one value", ErrInvalidLabelSelector, requirement)
+		}
+		return validateLabelRequirement(requirement, LabelRequirement{Key: matches[1], Operator: LabelSelectorOperator(matches[2]), Values: values})
+	}
+
+	if strings.Contains(requirement, "!=") {
+		keyValue := strings.SplitN(requirement, "!=", 2)
+		return validateLabelRequirement(requirement, LabelRequirement{Key: strings.TrimSpace(keyValue[0]), Operator: LabelSelectorNotEquals, Values: []string{strings.TrimSpace(keyValue[1])}})
+	}
+
+	if strings.Contains(requirement, "=") {
+		keyValue := strings.SplitN(strings.Replace(requirement, "==", "=", 1), "=", 2)
+		return validateLabelRequirement(requirement, LabelRequirement{Key: strings.TrimSpace(keyValue[0]), Operator: LabelSelectorEquals, Values: []string{strings.TrimSpace(keyValue[1])}})
+	}
+
+	if strings.HasPrefix(requirement, "!") {
+		return validateLabelRequirement(requirement, LabelRequirement{Key: strings.TrimSpace(strings.TrimPrefix(requirement, "!")), Operator: LabelSelectorDoesNotExist})
+	}
+
+	return validateLabelRequirement(requirement, LabelRequirement{Key: requirement, Operator: LabelSelectorExists})
+}
+
+func validateLabelRequirement(requirement string, r LabelRequirement) (LabelRequirement, error) {
+	if !labelKeyRegex.MatchString(r.Key) {
+		return r, fmt.Errorf("%w: %v has invalid label key '%v'", ErrInvalidLabelSelector, requirement, r.Key)
+	}
+	for _, v := range r.Values {
+		if strings.ContainsAny(v, "=!(),") {
+			return r, fmt.Errorf("%w: %v has invalid label value '%v'", ErrInvalidLabelSelector, requirement, v)
+		}
+	}
+
+	return r, nil
+}
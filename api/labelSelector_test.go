@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabelSelectors(t *testing.T) {
+
+	t.Run("ReturnsEqualityRequirementForKeyValuePair", func(t *testing.T) {
+
+		// act
+		selector, err := ParseLabelSelectors([]string{"team=estafette"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, LabelSelector{{Key: "team", Operator: LabelSelectorEquals, Values: []string{"estafette"}}}, selector)
+	})
+
+	t.Run("ReturnsRequirementsForAllOperators", func(t *testing.T) {
+
+		// act
+		selector, err := ParseLabelSelectors([]string{"team in (estafette, other),tier!=experimental", "language notin (java),app,!deprecated"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, LabelSelector{
+			{Key: "team", Operator: LabelSelectorIn, Values: []string{"estafette", "other"}},
+			{Key: "tier", Operator: LabelSelectorNotEquals, Values: []string{"experimental"}},
+			{Key: "language", Operator: LabelSelectorNotIn, Values: []string{"java"}},
+			{Key: "app", Operator: LabelSelectorExists},
+			{Key: "deprecated", Operator: LabelSelectorDoesNotExist},
+		}, selector)
+	})
+
+	t.Run("ReturnsEqualityRequirementForKeyValuePairWithValueOutsideSelectorSyntax", func(t *testing.T) {
+
+		// act
+		selector, err := ParseLabelSelectors([]string{"note=hi!", "expression=(a)", "query=a=b"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, LabelSelector{
+			{Key: "note", Operator: LabelSelectorEquals, Values: []string{"hi!"}},
+			{Key: "expression", Operator: LabelSelectorEquals, Values: []string{"(a)"}},
+			{Key: "query", Operator: LabelSelectorEquals, Values: []string{"a=b"}},
+		}, selector)
+	})
+
+	t.Run("ReturnsEqualityRequirementForKeyValuePairWithCommaInValue", func(t *testing.T) {
+
+		// act
+		selector, err := ParseLabelSelectors([]string{"team=a,b"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, LabelSelector{{Key: "team", Operator: LabelSelectorEquals, Values: []string{"a,b"}}}, selector)
+	})
+
+	t.Run("ReturnsRequirementsForSelectorWithSingleEqualsAndOtherOperators", func(t *testing.T) {
+
+		// act
+		selector, err := ParseLabelSelectors([]string{"team=a,!deprecated"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, LabelSelector{
+			{Key: "team", Operator: LabelSelectorEquals, Values: []string{"a"}},
+			{Key: "deprecated", Operator: LabelSelectorDoesNotExist},
+		}, selector)
+	})
+
+	t.Run("ReturnsErrorForEmptySet", func(t *testing.T) {
+
+		// act
+		_, err := ParseLabelSelectors([]string{"team in ()"})
+
+		assert.True(t, errors.Is(err, ErrInvalidLabelSelector))
+	})
+
+	t.Run("ReturnsErrorForInvalidKey", func(t *testing.T) {
+
+		// act
+		_, err := ParseLabelSelectors([]string{"team name=estafette"})
+
+		assert.True(t, errors.Is(err, ErrInvalidLabelSelector))
+	})
+
+	t.Run("ReturnsErrorForUnbalancedParentheses", func(t *testing.T) {
+
+		// act
+		_, err := ParseLabelSelectors([]string{"team in (a,b"})
+
+		assert.True(t, errors.Is(err, ErrInvalidLabelSelector))
+	})
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+
+	selector, _ := ParseLabelSelectors([]string{"team in (estafette,other),tier!=experimental,!deprecated"})
+
+	t.Run("ReturnsTrueIfAllRequirementsMatch", func(t *testing.T) {
+
+		// act
+		matches := selector.Matches(map[string]string{"team": "other", "tier": "stable"})
+
+		assert.True(t, matches)
+	})
+
+	t.Run("ReturnsTrueIfNotEqualsKeyIsMissing", func(t *testing.T) {
+
+		// act
+		matches := selector.Matches(map[string]string{"team": "estafette"})
+
+		assert.True(t, matches)
+	})
+
+	t.Run("ReturnsFalseIfValueIsNotInSet", func(t *testing.T) {
+
+		// act
+		matches := selector.Matches(map[string]string{"team": "third"})
+
+		assert.False(t, matches)
+	})
+
+	t.Run("ReturnsFalseIfExcludedKeyExists", func(t *testing.T) {
+
+		// act
+		matches := selector.Matches(map[string]string{"team": "estafette", "deprecated": "true"})
+
+		assert.False(t, matches)
+	})
+}
@@ -0,0 +1,496 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	crypt "github.com/estafette/estafette-ci-crypt"
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+// LintSeverity indicates how serious a rule violation is; violations of error rules block builds
+type LintSeverity string
+
+const (
+	LintSeverityInfo    LintSeverity = "info"
+	LintSeverityWarning LintSeverity = "warning"
+	LintSeverityError   LintSeverity = "error"
+)
+
+// IsValid returns true for the known severity levels
+func (s LintSeverity) IsValid() bool {
+	return s == LintSeverityInfo || s == LintSeverityWarning || s == LintSeverityError
+}
+
+// LintRule is a check applied to pipeline manifests; the exported fields describe the rule as configured
+type LintRule struct {
+	ID          string                 `json:"id"`
+	Description string                 `json:"description"`
+	Severity    LintSeverity           `json:"severity"`
+	Enabled     bool                   `json:"enabled"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+
+	check func(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error)
+}
+
+// LintInput holds what rules can check; MedianBuildTime and Flakiness are only set when checking the warnings of a stored pipeline
+type LintInput struct {
+	Manifest        *manifest.EstafetteManifest
+	FullRepoPath    string
+	Organizations   []*contracts.Organization
+	MedianBuildTime *time.Duration
+	Flakiness       *FlakinessReport
+}
+
+// LintFinding is a violation of a rule
+type LintFinding struct {
+	RuleID   string       `json:"ruleID"`
+	Severity LintSeverity `json:"severity"`
+	Message  string       `json:"message"`
+}
+
+// ToWarning converts the finding to a warning as shown for pipelines and builds; error findings show as danger
+func (f LintFinding) ToWarning() contracts.Warning {
+	status := string(f.Severity)
+	if f.Severity == LintSeverityError {
+		status = "danger"
+	}
+
+	return contracts.Warning{
+		Status:  status,
+		Message: f.Message,
+	}
+}
+
+// GetBlockingLintFindings returns the findings of error rules
+func GetBlockingLintFindings(findings []LintFinding) []LintFinding {
+	blocking := []LintFinding{}
+	for _, f := range findings {
+		if f.Severity == LintSeverityError {
+			blocking = append(blocking, f)
+		}
+	}
+
+	return blocking
+}
+
+// getDefaultLintRules returns all available rules with their default settings
+func getDefaultLintRules() []*LintRule {
+	return []*LintRule{
+		{
+			ID:          "median-build-time",
+			Description: "The median build time should stay below warningSeconds; above errorSeconds it's reported as an error.",
+			Severity:    LintSeverityWarning,
+			Enabled:     true,
+			Parameters: map[string]interface{}{
+				"warningSeconds": 120,
+				"errorSeconds":   300,
+			},
+			check: checkMedianBuildTime,
+		},
+		{
+			ID:          "pinned-image-versions",
+			Description: "Stage images should be pinned to a specific version instead of the latest or no tag.",
+			Severity:    LintSeverityWarning,
+			Enabled:     true,
+			check:       checkPinnedImageVersions,
+		},
+		{
+			ID:          "no-extension-dev-tag",
+			Description: "Stages should not use the dev tag of estafette extensions.",
+			Severity:    LintSeverityWarning,
+			Enabled:     true,
+			check:       checkNoExtensionDevTag,
+		},
+		{
+			ID:          "no-builder-dev-track",
+			Description: "Pipelines should not use the dev track of the builder.",
+			Severity:    LintSeverityWarning,
+			Enabled:     true,
+			check:       checkNoBuilderDevTrack,
+		},
+		{
+			ID:          "restricted-secrets",
+			Description: "Pipelines should only use secrets restricted to the pipeline itself.",
+			Severity:    LintSeverityWarning,
+			Enabled:     true,
+			check:       checkRestrictedSecrets,
+		},
+		{
+			ID:          "allowed-image-registries",
+			Description: "Stage and service images have to come from one of the registries, for example 'eu.gcr.io/my-project'.",
+			Severity:    LintSeverityError,
+			Enabled:     false,
+			Parameters: map[string]interface{}{
+				"registries": []interface{}{},
+			},
+			check: checkAllowedImageRegistries,
+		},
+		{
+			ID:          "release-target-required-actions",
+			Description: "Release targets need at least one of the actions, for example a freeze-aware deploy action.",
+			Severity:    LintSeverityError,
+			Enabled:     false,
+			Parameters: map[string]interface{}{
+				"actions": []interface{}{},
+			},
+			check: checkReleaseTargetRequiredActions,
+		},
+		{
+			ID:          "flaky-builds",
+			Description: "At most warningPercentage of the revisions built more than once should both fail and succeed, once at least minimumRevisions revisions are built more than once.",
+			Severity:    LintSeverityWarning,
+			Enabled:     true,
+			Parameters: map[string]interface{}{
+				"warningPercentage": 10,
+				"minimumRevisions":  10,
+			},
+			check: checkFlakyBuilds,
+		},
+	}
+}
+
+// getLintRules returns the rules with the configuration applied, including overrides for the first of the organizations having any
+func getLintRules(config *LintingConfig, organizations []*contracts.Organization) []*LintRule {
+
+	rules := getDefaultLintRules()
+	if config == nil {
+		return rules
+	}
+
+	for _, rule := range rules {
+		for _, rc := range config.Rules {
+			if rc == nil || rc.ID != rule.ID {
+				continue
+			}
+
+			rule.apply(rc.Enabled, rc.Severity, rc.Parameters)
+
+			for _, oc := range rc.Organizations {
+				if oc != nil && hasOrganization(organizations, oc.Name) {
+					rule.apply(oc.Enabled, oc.Severity, oc.Parameters)
+					break
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+func (r *LintRule) apply(enabled *bool, severity string, parameters map[string]interface{}) {
+	if enabled != nil {
+		r.Enabled = *enabled
+	}
+	if LintSeverity(severity).IsValid() {
+		r.Severity = LintSeverity(severity)
+	}
+	if len(parameters) > 0 {
+		merged := map[string]interface{}{}
+		for k, v := range r.Parameters {
+			merged[k] = v
+		}
+		for k, v := range parameters {
+			merged[k] = v
+		}
+		r.Parameters = merged
+	}
+}
+
+func (r *LintRule) newFinding(message string) LintFinding {
+	return LintFinding{
+		RuleID:   r.ID,
+		Severity: r.Severity,
+		Message:  message,
+	}
+}
+
+func (r *LintRule) getIntParameter(name string) int {
+	switch v := r.Parameters[name].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+
+	return 0
+}
+
+func (r *LintRule) getStringsParameter(name string) []string {
+	values := []string{}
+	switch v := r.Parameters[name].(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	case []string:
+		values = append(values, v...)
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+
+	return values
+}
+
+func hasOrganization(organizations []*contracts.Organization, name string) bool {
+	for _, o := range organizations {
+		if o != nil && o.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getStageImages returns the images of all build and release stages, including nested ones, keyed by stage path
+func getStageImages(mft *manifest.EstafetteManifest, includeServices bool) (paths []string, images map[string]string) {
+	images = map[string]string{}
+
+	var add func(prefix string, stages []*manifest.EstafetteStage)
+	add = func(prefix string, stages []*manifest.EstafetteStage) {
+		for _, s := range stages {
+			if len(s.ParallelStages) > 0 {
+				add(prefix, s.ParallelStages)
+			} else {
+				paths = append(paths, prefix+s.Name)
+				images[prefix+s.Name] = s.ContainerImage
+			}
+			if includeServices {
+				for _, svc := range s.Services {
+					path := fmt.Sprintf("%v%v/%v", prefix, s.Name, svc.Name)
+					paths = append(paths, path)
+					images[path] = svc.ContainerImage
+				}
+			}
+		}
+	}
+
+	add("", mft.Stages)
+	for _, r := range mft.Releases {
+		add(r.Name+"/", r.Stages)
+	}
+
+	return
+}
+
+func checkPinnedImageVersions(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	paths, images := getStageImages(input.Manifest, false)
+
+	stagesUsingLatestTag := []string{}
+	for _, path := range paths {
+		_, _, containerImageTag := w.GetContainerImageParts(images[path])
+		if containerImageTag == "latest" {
+			stagesUsingLatestTag = append(stagesUsingLatestTag, path)
+		}
+	}
+	if len(stagesUsingLatestTag) == 0 {
+		return nil, nil
+	}
+
+	return []LintFinding{rule.newFinding(fmt.Sprintf("This pipeline has one or more stages that use **latest** or no tag for its container image: `%v`; it is [best practice](https://estafette.io/usage/best-practices/#pin-image-versions) to pin stage images to specific versions so you don't spend hours tracking down build failures because the used image has changed.", strings.Join(stagesUsingLatestTag, ", ")))}, nil
+}
+
+func checkNoExtensionDevTag(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	if isEstafettePipeline(input.FullRepoPath) {
+		return nil, nil
+	}
+
+	paths, images := getStageImages(input.Manifest, false)
+
+	stagesUsingDevTag := []string{}
+	for _, path := range paths {
+		containerImageRepo, _, containerImageTag := w.GetContainerImageParts(images[path])
+		if containerImageTag == "dev" && containerImageRepo == "extensions" {
+			stagesUsingDevTag = append(stagesUsingDevTag, path)
+		}
+	}
+	if len(stagesUsingDevTag) == 0 {
+		return nil, nil
+	}
+
+	return []LintFinding{rule.newFinding(fmt.Sprintf("This pipeline has one or more stages that use the **dev** tag for its container image: `%v`; it is [best practice](https://estafette.io/usage/best-practices/#avoid-using-estafette-s-dev-or-beta-tags) to avoid the dev tag alltogether, since it can be broken at any time.", strings.Join(stagesUsingDevTag, ", ")))}, nil
+}
+
+func checkNoBuilderDevTrack(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	if input.Manifest.Builder.Track != "dev" || isEstafettePipeline(input.FullRepoPath) {
+		return nil, nil
+	}
+
+	return []LintFinding{rule.newFinding("This pipeline uses the **dev** track for the builder; it is [best practice](https://estafette.io/usage/best-practices/#avoid-using-estafette-s-builder-dev-track) to avoid the dev track, since it can be broken at any time.")}, nil
+}
+
+func checkRestrictedSecrets(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	// secrets can't be inspected without the secret helper
+	if w.secretHelper == nil {
+		return nil, nil
+	}
+
+	manifestBytes, err := json.Marshal(input.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	secretValues, err := w.secretHelper.GetAllSecrets(string(manifestBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sv := range secretValues {
+		_, pipelineWhitelist, err := w.secretHelper.Decrypt(sv, input.FullRepoPath)
+		if err != nil && !errors.Is(err, crypt.ErrRestrictedSecret) {
+			return nil, err
+		}
+
+		if errors.Is(err, crypt.ErrRestrictedSecret) {
+			return []LintFinding{rule.newFinding("This pipeline uses a _restricted secret_ created for another pipeline; please replace it with one created for this pipeline.")}, nil
+		}
+
+		if pipelineWhitelist == crypt.DefaultPipelineWhitelist {
+			return []LintFinding{rule.newFinding("This pipeline uses _global_ secrets which can be used by any pipeline; it is [best practice](https://estafette.io/usage/best-practices/#use-pipeline-restricted-secrets-instead-of-global-secrets) to use _restricted_ secrets instead, that can only be used by this pipeline. Please rotate the value stored in the secret and create a new one in the pipeline's secrets tab.")}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func checkMedianBuildTime(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	if input.MedianBuildTime == nil {
+		return nil, nil
+	}
+
+	duration := *input.MedianBuildTime
+	durationInSeconds := duration.Seconds()
+	warningSeconds := rule.getIntParameter("warningSeconds")
+	errorSeconds := rule.getIntParameter("errorSeconds")
+
+	if errorSeconds > 0 && durationInSeconds > float64(errorSeconds) {
+		finding := rule.newFinding(fmt.Sprintf("The [median build time](/pipelines/%v/statistics?last=25) of this pipeline is **%v**. This is too slow, please optimize your build speed by using smaller images or running less intensive steps to ensure it finishes at least within %v, but preferably within %v.", input.FullRepoPath, duration, formatLintSeconds(errorSeconds), formatLintSeconds(warningSeconds)))
+		finding.Severity = LintSeverityError
+		return []LintFinding{finding}, nil
+	}
+	if warningSeconds > 0 && durationInSeconds > float64(warningSeconds) {
+		return []LintFinding{rule.newFinding(fmt.Sprintf("The [median build time](/pipelines/%v/statistics?last=25) of this pipeline is **%v**. This is a bit too slow, please optimize your build speed by using smaller images or running less intensive steps to ensure it finishes within %v.", input.FullRepoPath, duration, formatLintSeconds(warningSeconds)))}, nil
+	}
+
+	return nil, nil
+}
+
+func checkFlakyBuilds(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	if input.Flakiness == nil || input.Flakiness.Revisions == 0 || input.Flakiness.Revisions < rule.getIntParameter("minimumRevisions") {
+		return nil, nil
+	}
+
+	percentage := input.Flakiness.Score * 100
+	if percentage <= float64(rule.getIntParameter("warningPercentage")) {
+		return nil, nil
+	}
+
+	flakyStages := []string{}
+	for _, s := range input.Flakiness.Stages {
+		if s.FlakyRevisions > 0 {
+			flakyStages = append(flakyStages, fmt.Sprintf("%v (%.0f%%)", s.Name, s.Score*100))
+		}
+	}
+
+	message := fmt.Sprintf("For **%.0f%%** of the revisions of this pipeline that were built more than once re-running the build changed its outcome, which makes its builds flaky.", percentage)
+	if len(flakyStages) > 0 {
+		message += fmt.Sprintf(" The stages with differing outcomes are `%v`.", strings.Join(flakyStages, ", "))
+	}
+	message += " Please make sure the build doesn't depend on timing, ordering or external services, so failures point at actual problems."
+
+	return []LintFinding{rule.newFinding(message)}, nil
+}
+
+func checkAllowedImageRegistries(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	registries := rule.getStringsParameter("registries")
+	if len(registries) == 0 {
+		return nil, nil
+	}
+
+	paths, images := getStageImages(input.Manifest, true)
+
+	stagesUsingOtherRegistries := []string{}
+	for _, path := range paths {
+		image := images[path]
+		if image == "" {
+			continue
+		}
+
+		allowed := false
+		for _, registry := range registries {
+			if strings.HasPrefix(image, strings.TrimSuffix(registry, "/")+"/") {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			stagesUsingOtherRegistries = append(stagesUsingOtherRegistries, path)
+		}
+	}
+	if len(stagesUsingOtherRegistries) == 0 {
+		return nil, nil
+	}
+
+	return []LintFinding{rule.newFinding(fmt.Sprintf("This pipeline has one or more stages or services with images from registries that are not allowed: `%v`; only images from `%v` can be used.", strings.Join(stagesUsingOtherRegistries, ", "), strings.Join(registries, ", ")))}, nil
+}
+
+func checkReleaseTargetRequiredActions(w *warningHelperImpl, rule *LintRule, input LintInput) ([]LintFinding, error) {
+
+	requiredActions := rule.getStringsParameter("actions")
+	if len(requiredActions) == 0 {
+		return nil, nil
+	}
+
+	releasesWithoutAction := []string{}
+	for _, r := range input.Manifest.Releases {
+		hasAction := false
+		for _, a := range r.Actions {
+			for _, ra := range requiredActions {
+				if a.Name == ra {
+					hasAction = true
+				}
+			}
+		}
+		if !hasAction {
+			releasesWithoutAction = append(releasesWithoutAction, r.Name)
+		}
+	}
+	if len(releasesWithoutAction) == 0 {
+		return nil, nil
+	}
+
+	return []LintFinding{rule.newFinding(fmt.Sprintf("This pipeline has one or more release targets without any of the required actions `%v`: `%v`.", strings.Join(requiredActions, ", "), strings.Join(releasesWithoutAction, ", ")))}, nil
+}
+
+func isEstafettePipeline(fullRepoPath string) bool {
+	return strings.HasPrefix(fullRepoPath, "github.com/estafette/")
+}
+
+func formatLintSeconds(seconds int) string {
+	if seconds%60 == 0 {
+		return fmt.Sprintf("%v minutes", seconds/60)
+	}
+
+	return fmt.Sprintf("%v seconds", seconds)
+}
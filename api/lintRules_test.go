@@ -0,0 +1,199 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLintRules(t *testing.T) {
+	t.Run("ReturnsDefaultRulesWithoutConfig", func(t *testing.T) {
+
+		// act
+		rules := getLintRules(nil, nil)
+
+		assert.Equal(t, 8, len(rules))
+		assert.Equal(t, "median-build-time", rules[0].ID)
+		assert.True(t, rules[0].Enabled)
+		assert.Equal(t, LintSeverityWarning, rules[0].Severity)
+		assert.Equal(t, "allowed-image-registries", rules[5].ID)
+		assert.False(t, rules[5].Enabled)
+	})
+
+	t.Run("AppliesOrganizationOverridesOnTopOfRuleConfig", func(t *testing.T) {
+
+		enabled := true
+		disabled := false
+		config := &LintingConfig{
+			Rules: []*LintRuleConfig{
+				{
+					ID:       "allowed-image-registries",
+					Enabled:  &enabled,
+					Severity: "warning",
+					Parameters: map[string]interface{}{
+						"registries": []interface{}{"eu.gcr.io/my-project"},
+					},
+					Organizations: []*LintRuleOrganizationConfig{
+						{Name: "Estafette", Severity: "error"},
+						{Name: "Other", Enabled: &disabled},
+					},
+				},
+			},
+		}
+
+		// act
+		rules := getLintRules(config, []*contracts.Organization{{Name: "Estafette"}})
+
+		assert.True(t, rules[5].Enabled)
+		assert.Equal(t, LintSeverityError, rules[5].Severity)
+		assert.Equal(t, []string{"eu.gcr.io/my-project"}, rules[5].getStringsParameter("registries"))
+	})
+
+	t.Run("IgnoresUnknownSeverity", func(t *testing.T) {
+
+		config := &LintingConfig{
+			Rules: []*LintRuleConfig{
+				{ID: "no-builder-dev-track", Severity: "fatal"},
+			},
+		}
+
+		// act
+		rules := getLintRules(config, nil)
+
+		assert.Equal(t, LintSeverityWarning, rules[3].Severity)
+	})
+}
+
+func TestLint(t *testing.T) {
+	t.Run("ReturnsErrorFindingForImageFromOtherRegistry", func(t *testing.T) {
+
+		enabled := true
+		helper := NewWarningHelper(nil, &APIConfig{
+			Linting: &LintingConfig{
+				Rules: []*LintRuleConfig{
+					{
+						ID:         "allowed-image-registries",
+						Enabled:    &enabled,
+						Parameters: map[string]interface{}{"registries": []interface{}{"eu.gcr.io/my-project/"}},
+					},
+				},
+			},
+		})
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "eu.gcr.io/my-project/golang:1.14"},
+				{Name: "test", ContainerImage: "golang:1.14", Services: []*manifest.EstafetteService{{Name: "db", ContainerImage: "eu.gcr.io/my-project/cockroach:v20"}}},
+			},
+		}
+
+		// act
+		findings, err := helper.Lint(LintInput{Manifest: mft, FullRepoPath: "github.com/estafette/estafette-ci-api"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(findings))
+		assert.Equal(t, "allowed-image-registries", findings[0].RuleID)
+		assert.Equal(t, LintSeverityError, findings[0].Severity)
+		assert.Equal(t, "danger", findings[0].ToWarning().Status)
+		assert.Equal(t, 1, len(GetBlockingLintFindings(findings)))
+	})
+
+	t.Run("ReturnsFindingForReleaseTargetWithoutRequiredAction", func(t *testing.T) {
+
+		enabled := true
+		helper := NewWarningHelper(nil, &APIConfig{
+			Linting: &LintingConfig{
+				Rules: []*LintRuleConfig{
+					{
+						ID:         "release-target-required-actions",
+						Enabled:    &enabled,
+						Parameters: map[string]interface{}{"actions": "deploy-canary, deploy-stable"},
+					},
+				},
+			},
+		})
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.14"},
+			},
+			Releases: []*manifest.EstafetteRelease{
+				{Name: "production", Actions: []*manifest.EstafetteReleaseAction{{Name: "deploy-stable"}}},
+				{Name: "development"},
+			},
+		}
+
+		// act
+		findings, err := helper.Lint(LintInput{Manifest: mft, FullRepoPath: "github.com/estafette/estafette-ci-api"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(findings))
+		assert.Equal(t, "This pipeline has one or more release targets without any of the required actions `deploy-canary, deploy-stable`: `development`.", findings[0].Message)
+	})
+
+	t.Run("ReturnsDangerForMedianBuildTimeAboveErrorThreshold", func(t *testing.T) {
+
+		helper := NewWarningHelper(nil, &APIConfig{})
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.14"},
+			},
+		}
+		medianBuildTime := 6 * time.Minute
+
+		// act
+		findings, err := helper.Lint(LintInput{Manifest: mft, FullRepoPath: "github.com/estafette/estafette-ci-api", MedianBuildTime: &medianBuildTime})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(findings))
+		assert.Equal(t, "danger", findings[0].ToWarning().Status)
+		assert.Equal(t, "The [median build time](/pipelines/github.com/estafette/estafette-ci-api/statistics?last=25) of this pipeline is **6m0s**. This is too slow, please optimize your build speed by using smaller images or running less intensive steps to ensure it finishes at least within 5 minutes, but preferably within 2 minutes.", findings[0].Message)
+	})
+
+	t.Run("ReturnsWarningForFlakyBuildsAboveThreshold", func(t *testing.T) {
+
+		helper := NewWarningHelper(nil, &APIConfig{})
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.14"},
+			},
+		}
+		flakiness := &FlakinessReport{
+			Revisions:      20,
+			FlakyRevisions: 5,
+			Score:          0.25,
+			Stages: []*StageFlakiness{
+				{Name: "integration-tests", Revisions: 5, FlakyRevisions: 4, Score: 0.8},
+				{Name: "build", Revisions: 5, Score: 0},
+			},
+		}
+
+		// act
+		findings, err := helper.Lint(LintInput{Manifest: mft, FullRepoPath: "github.com/estafette/estafette-ci-api", Flakiness: flakiness})
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(findings)) {
+			assert.Equal(t, "flaky-builds", findings[0].RuleID)
+			assert.Equal(t, "warning", findings[0].ToWarning().Status)
+			assert.Contains(t, findings[0].Message, "**25%**")
+			assert.Contains(t, findings[0].Message, "`integration-tests (80%)`")
+		}
+	})
+
+	t.Run("ReturnsNoFlakyBuildsWarningBelowMinimumRevisions", func(t *testing.T) {
+
+		helper := NewWarningHelper(nil, &APIConfig{})
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.14"},
+			},
+		}
+
+		// act
+		findings, err := helper.Lint(LintInput{Manifest: mft, FullRepoPath: "github.com/estafette/estafette-ci-api", Flakiness: &FlakinessReport{Revisions: 4, FlakyRevisions: 2, Score: 0.5}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(findings))
+	})
+}
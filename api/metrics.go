@@ -47,3 +47,20 @@ func NewRequestHistogram(subsystem string) metrics.Histogram {
 
 	return requestHistograms[subsystem]
 }
+
+var doraGauges map[string]metrics.Gauge = map[string]metrics.Gauge{}
+
+// NewDoraGauge returns a gauge for one of the DORA metrics, labeled with what the metric is grouped by and the group key
+func NewDoraGauge(name, help string) metrics.Gauge {
+
+	if _, ok := doraGauges[name]; !ok {
+		doraGauges[name] = kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "api",
+			Subsystem: "dora",
+			Name:      name,
+			Help:      help,
+		}, []string{"group_by", "key"})
+	}
+
+	return doraGauges[name]
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"time"
@@ -18,17 +19,34 @@ type Middleware interface {
 	GinJWTMiddlewareForClientLogin(authenticator func(c *gin.Context) (interface{}, error)) (middleware *jwt.GinJWTMiddleware, err error)
 }
 
+// PersonalAccessTokenAuthorizator checks whether a personal access token used in a request is still valid
+type PersonalAccessTokenAuthorizator func(c *gin.Context, tokenID string) bool
+
+// ImpersonatedUser is returned by an impersonation authenticator, so the jwt keeps track of who is impersonating the user
+type ImpersonatedUser struct {
+	User              *contracts.User
+	ImpersonatorID    string
+	ImpersonatorEmail string
+}
+
+// CustomRolesResolver looks up the custom roles among the role names assigned to a user or client
+type CustomRolesResolver func(ctx context.Context, roles []*string) (customRoles []*CustomRole, err error)
+
 // NewAuthMiddleware returns a new api.AuthMiddleware
-func NewAuthMiddleware(config *APIConfig) (authMiddleware Middleware) {
+func NewAuthMiddleware(config *APIConfig, personalAccessTokenAuthorizator PersonalAccessTokenAuthorizator, customRolesResolver CustomRolesResolver) (authMiddleware Middleware) {
 	authMiddleware = &authMiddlewareImpl{
-		config: config,
+		config:                          config,
+		personalAccessTokenAuthorizator: personalAccessTokenAuthorizator,
+		customRolesResolver:             customRolesResolver,
 	}
 
 	return
 }
 
 type authMiddlewareImpl struct {
-	config *APIConfig
+	config                          *APIConfig
+	personalAccessTokenAuthorizator PersonalAccessTokenAuthorizator
+	customRolesResolver             CustomRolesResolver
 }
 
 func (m *authMiddlewareImpl) GoogleJWTMiddlewareFunc() gin.HandlerFunc {
@@ -64,10 +82,26 @@ func (m *authMiddlewareImpl) coreGinJWTMiddleware(authenticator func(c *gin.Cont
 		Key:           []byte(m.config.Auth.JWT.Key),
 		TokenLookup:   "header:Authorization, cookie:jwt",
 		Authenticator: authenticator,
+		Authorizator:  m.authorizator,
 		TimeFunc:      time.Now,
 	})
 }
 
+func (m *authMiddlewareImpl) authorizator(data interface{}, c *gin.Context) bool {
+
+	// personal access tokens are long-lived, so check whether they haven't been revoked in the meantime
+	tokenID := GetPersonalAccessTokenIDFromRequest(c)
+	if tokenID == "" {
+		return true
+	}
+
+	if m.personalAccessTokenAuthorizator == nil {
+		return false
+	}
+
+	return m.personalAccessTokenAuthorizator(c, tokenID)
+}
+
 func (m *authMiddlewareImpl) GinJWTMiddleware(authenticator func(c *gin.Context) (interface{}, error)) (middleware *jwt.GinJWTMiddleware, err error) {
 	middleware, err = m.coreGinJWTMiddleware(authenticator)
 	if err != nil {
@@ -102,24 +136,14 @@ func (m *authMiddlewareImpl) GinJWTMiddleware(authenticator func(c *gin.Context)
 	middleware.PayloadFunc = func(data interface{}) jwt.MapClaims {
 		// add user properties as claims
 		if user, ok := data.(*contracts.User); ok {
-
-			organizations := []string{}
-			for _, o := range user.Organizations {
-				organizations = append(organizations, o.Name)
-			}
-
-			groups := []string{}
-			for _, g := range user.Groups {
-				groups = append(groups, g.Name)
-			}
-
-			return jwt.MapClaims{
-				jwt.IdentityKey: user.ID,
-				"email":         user.GetEmail(),
-				"roles":         user.Roles,
-				"groups":        groups,
-				"organizations": organizations,
-			}
+			return m.getUserClaims(user)
+		}
+		// add impersonator as claims as well, so actions can be traced back to them
+		if impersonatedUser, ok := data.(*ImpersonatedUser); ok && impersonatedUser.User != nil {
+			claims := m.getUserClaims(impersonatedUser.User)
+			claims["impersonatorID"] = impersonatedUser.ImpersonatorID
+			claims["impersonatorEmail"] = impersonatedUser.ImpersonatorEmail
+			return claims
 		}
 		return jwt.MapClaims{}
 	}
@@ -127,6 +151,28 @@ func (m *authMiddlewareImpl) GinJWTMiddleware(authenticator func(c *gin.Context)
 	return middleware, nil
 }
 
+func (m *authMiddlewareImpl) getUserClaims(user *contracts.User) jwt.MapClaims {
+
+	organizations := []string{}
+	for _, o := range user.Organizations {
+		organizations = append(organizations, o.Name)
+	}
+
+	groups := []string{}
+	for _, g := range user.Groups {
+		groups = append(groups, g.Name)
+	}
+
+	return jwt.MapClaims{
+		jwt.IdentityKey: user.ID,
+		"email":         user.GetEmail(),
+		"roles":         user.Roles,
+		"customRoles":   m.getCustomRolesClaim(user.Roles),
+		"groups":        groups,
+		"organizations": organizations,
+	}
+}
+
 func (m *authMiddlewareImpl) GinJWTMiddlewareForClientLogin(authenticator func(c *gin.Context) (interface{}, error)) (middleware *jwt.GinJWTMiddleware, err error) {
 	middleware, err = m.coreGinJWTMiddleware(authenticator)
 	if err != nil {
@@ -141,6 +187,7 @@ func (m *authMiddlewareImpl) GinJWTMiddlewareForClientLogin(authenticator func(c
 				jwt.IdentityKey: client.ID,
 				"clientID":      client.ClientID,
 				"roles":         client.Roles,
+				"customRoles":   m.getCustomRolesClaim(client.Roles),
 			}
 		}
 		return jwt.MapClaims{}
@@ -148,3 +195,29 @@ func (m *authMiddlewareImpl) GinJWTMiddlewareForClientLogin(authenticator func(c
 
 	return middleware, nil
 }
+
+// getCustomRolesClaim embeds the permissions of custom roles in the jwt, so they can be checked without hitting the database
+func (m *authMiddlewareImpl) getCustomRolesClaim(roles []*string) (customRoles []*CustomRole) {
+
+	customRoles = []*CustomRole{}
+
+	if m.customRolesResolver == nil {
+		return
+	}
+
+	resolvedCustomRoles, err := m.customRolesResolver(context.Background(), roles)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed resolving custom roles, leaving them out of the jwt")
+		return
+	}
+
+	for _, r := range resolvedCustomRoles {
+		customRoles = append(customRoles, &CustomRole{
+			Name:        r.Name,
+			Permissions: r.Permissions,
+			Scope:       r.Scope,
+		})
+	}
+
+	return
+}
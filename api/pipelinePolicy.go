@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	crypt "github.com/estafette/estafette-ci-crypt"
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+// PipelinePolicy shows which privileges, credentials and secrets the stages of a pipeline get when running, and why any are refused
+type PipelinePolicy struct {
+	Stages              []*StagePolicy      `json:"stages"`
+	RegistryCredentials []*CredentialPolicy `json:"registryCredentials"`
+}
+
+// StagePolicy is the evaluation of a single stage or service container
+type StagePolicy struct {
+	Name                string              `json:"name"`
+	Release             string              `json:"release,omitempty"`
+	Service             string              `json:"service,omitempty"`
+	Image               string              `json:"image"`
+	Trusted             bool                `json:"trusted"`
+	RunPrivileged       bool                `json:"runPrivileged"`
+	RunDocker           bool                `json:"runDocker"`
+	AllowCommands       bool                `json:"allowCommands"`
+	Reason              string              `json:"reason,omitempty"`
+	InjectedCredentials []*CredentialPolicy `json:"injectedCredentials"`
+	RefusedCredentials  []*CredentialPolicy `json:"refusedCredentials"`
+	Secrets             []*SecretPolicy     `json:"secrets"`
+}
+
+// CredentialPolicy tells whether a credential is injected; the reason explains a refusal
+type CredentialPolicy struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SecretPolicy tells whether an encrypted secret in the stage can be decrypted by the pipeline
+type SecretPolicy struct {
+	Secret            string       `json:"secret"`
+	Allowed           bool         `json:"allowed"`
+	PipelineWhitelist string       `json:"pipelineWhitelist,omitempty"`
+	Scope             *SecretScope `json:"scope,omitempty"`
+	Reason            string       `json:"reason,omitempty"`
+}
+
+// EvaluatePipelinePolicy evaluates the build and release stages of the manifest against the trusted images and credentials config and the restrictions of the secrets it uses, the same way they're applied when creating a build or release job; scoped secrets are checked against the scope context, with the release target set for release stages
+func EvaluatePipelinePolicy(mft *manifest.EstafetteManifest, fullRepoPath string, scopeContext SecretScopeContext, trustedImages []*contracts.TrustedImageConfig, credentials []*contracts.CredentialConfig, secretHelper crypt.SecretHelper) (policy PipelinePolicy, err error) {
+
+	policy = PipelinePolicy{
+		Stages:              []*StagePolicy{},
+		RegistryCredentials: []*CredentialPolicy{},
+	}
+
+	if mft == nil {
+		return
+	}
+
+	var evaluate func(release string, stages []*manifest.EstafetteStage) error
+	evaluate = func(release string, stages []*manifest.EstafetteStage) error {
+		releaseScopeContext := scopeContext
+		releaseScopeContext.ReleaseTarget = release
+
+		for _, s := range stages {
+			if len(s.ParallelStages) > 0 {
+				err := evaluate(release, s.ParallelStages)
+				if err != nil {
+					return err
+				}
+			} else {
+				stagePolicy, err := evaluateStagePolicy(s.Name, release, "", s.ContainerImage, s, fullRepoPath, releaseScopeContext, trustedImages, credentials, secretHelper)
+				if err != nil {
+					return err
+				}
+				policy.Stages = append(policy.Stages, stagePolicy)
+			}
+
+			for _, svc := range s.Services {
+				stagePolicy, err := evaluateStagePolicy(s.Name, release, svc.Name, svc.ContainerImage, svc, fullRepoPath, releaseScopeContext, trustedImages, credentials, secretHelper)
+				if err != nil {
+					return err
+				}
+				policy.Stages = append(policy.Stages, stagePolicy)
+			}
+		}
+		return nil
+	}
+
+	err = evaluate("", mft.Stages)
+	if err != nil {
+		return
+	}
+	for _, r := range mft.Releases {
+		err = evaluate(r.Name, r.Stages)
+		if err != nil {
+			return
+		}
+	}
+
+	// container-registry credentials are available to every stage to pull private images
+	for _, c := range contracts.GetCredentialsByType(credentials, "container-registry") {
+		policy.RegistryCredentials = append(policy.RegistryCredentials, evaluateCredentialPolicy(c, nil, fullRepoPath))
+	}
+
+	return
+}
+
+func evaluateStagePolicy(name, release, service, image string, container interface{}, fullRepoPath string, scopeContext SecretScopeContext, trustedImages []*contracts.TrustedImageConfig, credentials []*contracts.CredentialConfig, secretHelper crypt.SecretHelper) (*StagePolicy, error) {
+
+	stagePolicy := &StagePolicy{
+		Name:                name,
+		Release:             release,
+		Service:             service,
+		Image:               image,
+		InjectedCredentials: []*CredentialPolicy{},
+		RefusedCredentials:  []*CredentialPolicy{},
+		Secrets:             []*SecretPolicy{},
+	}
+
+	trustedImage := contracts.GetTrustedImage(trustedImages, image)
+	switch {
+	case trustedImage == nil:
+		stagePolicy.Reason = "Image is not configured as trusted image."
+	case !contracts.IsWhitelistedPipelineForTrustedImage(*trustedImage, fullRepoPath):
+		stagePolicy.Reason = fmt.Sprintf("Image is only trusted for pipelines matching `%v`.", trustedImage.WhitelistedPipelines)
+	default:
+		stagePolicy.Trusted = true
+		stagePolicy.RunPrivileged = trustedImage.RunPrivileged
+		stagePolicy.RunDocker = trustedImage.RunDocker
+		stagePolicy.AllowCommands = trustedImage.AllowCommands
+
+		for _, credentialType := range trustedImage.InjectedCredentialTypes {
+			for _, c := range contracts.GetCredentialsByType(credentials, credentialType) {
+				credentialPolicy := evaluateCredentialPolicy(c, trustedImage, fullRepoPath)
+				if credentialPolicy.Reason == "" {
+					stagePolicy.InjectedCredentials = append(stagePolicy.InjectedCredentials, credentialPolicy)
+				} else {
+					stagePolicy.RefusedCredentials = append(stagePolicy.RefusedCredentials, credentialPolicy)
+				}
+			}
+		}
+	}
+
+	if secretHelper == nil {
+		return stagePolicy, nil
+	}
+
+	containerBytes, err := json.Marshal(container)
+	if err != nil {
+		return nil, err
+	}
+
+	secretValues, err := secretHelper.GetAllSecrets(string(containerBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sv := range secretValues {
+		secretPolicy := &SecretPolicy{
+			Secret: sv,
+		}
+
+		decryptedText, pipelineWhitelist, err := secretHelper.Decrypt(sv, fullRepoPath)
+		_, secretPolicy.Scope = ParseScopedSecret(decryptedText)
+		scopeErr := secretPolicy.Scope.Allows(scopeContext)
+		switch {
+		case errors.Is(err, crypt.ErrRestrictedSecret):
+			secretPolicy.Reason = "Secret is restricted to another pipeline."
+		case err != nil:
+			secretPolicy.Reason = "Secret can't be decrypted; it's probably encrypted with another key."
+		case secretPolicy.Scope.HasExpired(scopeContext.Time):
+			secretPolicy.PipelineWhitelist = pipelineWhitelist
+			secretPolicy.Reason = fmt.Sprintf("Secret has expired on %v.", secretPolicy.Scope.ExpiresAt.Format(time.RFC3339))
+		case scopeErr != nil:
+			secretPolicy.PipelineWhitelist = pipelineWhitelist
+			secretPolicy.Reason = fmt.Sprintf("%v.", scopeErr)
+		case pipelineWhitelist == crypt.DefaultPipelineWhitelist:
+			secretPolicy.Allowed = true
+			secretPolicy.PipelineWhitelist = pipelineWhitelist
+			secretPolicy.Reason = "Secret is global and can be used by any pipeline."
+		default:
+			secretPolicy.Allowed = true
+			secretPolicy.PipelineWhitelist = pipelineWhitelist
+		}
+
+		stagePolicy.Secrets = append(stagePolicy.Secrets, secretPolicy)
+	}
+
+	return stagePolicy, nil
+}
+
+// evaluateCredentialPolicy checks the credential against the trusted images and pipelines whitelists; without trusted image only the pipelines whitelist applies
+func evaluateCredentialPolicy(credential *contracts.CredentialConfig, trustedImage *contracts.TrustedImageConfig, fullRepoPath string) *CredentialPolicy {
+
+	credentialPolicy := &CredentialPolicy{
+		Name: credential.Name,
+		Type: credential.Type,
+	}
+
+	reasons := []string{}
+	if trustedImage != nil && !contracts.IsWhitelistedTrustedImageForCredential(*credential, *trustedImage) {
+		reasons = append(reasons, fmt.Sprintf("Credential is only injected into trusted images matching `%v`.", credential.WhitelistedTrustedImages))
+	}
+	if !contracts.IsWhitelistedPipelineForCredential(*credential, fullRepoPath) {
+		reasons = append(reasons, fmt.Sprintf("Credential is only injected for pipelines matching `%v`.", credential.WhitelistedPipelines))
+	}
+	credentialPolicy.Reason = strings.Join(reasons, " ")
+
+	return credentialPolicy
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	crypt "github.com/estafette/estafette-ci-crypt"
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluatePipelinePolicy(t *testing.T) {
+
+	trustedImages := []*contracts.TrustedImageConfig{
+		{
+			ImagePath:               "extensions/docker",
+			RunDocker:               true,
+			InjectedCredentialTypes: []string{"container-registry"},
+		},
+		{
+			ImagePath:            "extensions/gke",
+			RunPrivileged:        true,
+			WhitelistedPipelines: "github.com/estafette/.+",
+		},
+	}
+	credentials := []*contracts.CredentialConfig{
+		{Name: "registry-public", Type: "container-registry"},
+		{Name: "registry-private", Type: "container-registry", WhitelistedPipelines: "github.com/other/.+"},
+		{Name: "registry-kaniko", Type: "container-registry", WhitelistedTrustedImages: "extensions/kaniko"},
+	}
+
+	t.Run("ReturnsInjectedAndRefusedCredentialsForTrustedImage", func(t *testing.T) {
+
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "push", ContainerImage: "extensions/docker:stable"},
+			},
+		}
+
+		// act
+		policy, err := EvaluatePipelinePolicy(mft, "github.com/estafette/estafette-ci-api", SecretScopeContext{Branch: "master", Time: time.Now().UTC()}, trustedImages, credentials, nil)
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(policy.Stages)) {
+			stage := policy.Stages[0]
+			assert.True(t, stage.Trusted)
+			assert.True(t, stage.RunDocker)
+			assert.False(t, stage.RunPrivileged)
+			if assert.Equal(t, 1, len(stage.InjectedCredentials)) {
+				assert.Equal(t, "registry-public", stage.InjectedCredentials[0].Name)
+			}
+			if assert.Equal(t, 2, len(stage.RefusedCredentials)) {
+				assert.Equal(t, "registry-private", stage.RefusedCredentials[0].Name)
+				assert.Equal(t, "Credential is only injected for pipelines matching `github.com/other/.+`.", stage.RefusedCredentials[0].Reason)
+				assert.Equal(t, "registry-kaniko", stage.RefusedCredentials[1].Name)
+				assert.Equal(t, "Credential is only injected into trusted images matching `extensions/kaniko`.", stage.RefusedCredentials[1].Reason)
+			}
+		}
+		assert.Equal(t, 3, len(policy.RegistryCredentials))
+	})
+
+	t.Run("ReturnsUntrustedImageForPipelineNotWhitelisted", func(t *testing.T) {
+
+		mft := &manifest.EstafetteManifest{
+			Releases: []*manifest.EstafetteRelease{
+				{
+					Name: "production",
+					Stages: []*manifest.EstafetteStage{
+						{Name: "deploy", ContainerImage: "extensions/gke:stable"},
+					},
+				},
+			},
+		}
+
+		// act
+		policy, err := EvaluatePipelinePolicy(mft, "github.com/other/app", SecretScopeContext{Branch: "master", Time: time.Now().UTC()}, trustedImages, credentials, nil)
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(policy.Stages)) {
+			assert.Equal(t, "production", policy.Stages[0].Release)
+			assert.False(t, policy.Stages[0].Trusted)
+			assert.False(t, policy.Stages[0].RunPrivileged)
+			assert.Equal(t, "Image is only trusted for pipelines matching `github.com/estafette/.+`.", policy.Stages[0].Reason)
+		}
+	})
+
+	t.Run("ReturnsRefusedSecretRestrictedToOtherPipeline", func(t *testing.T) {
+
+		secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+		restrictedSecret, err := secretHelper.EncryptEnvelope("my secret", "github.com/other/app")
+		assert.Nil(t, err)
+		ownSecret, err := secretHelper.EncryptEnvelope("my secret", "github.com/estafette/estafette-ci-api")
+		assert.Nil(t, err)
+
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{
+					Name:           "build",
+					ContainerImage: "golang:1.14",
+					EnvVars: map[string]string{
+						"A": restrictedSecret,
+						"B": ownSecret,
+					},
+				},
+			},
+		}
+
+		// act
+		policy, err := EvaluatePipelinePolicy(mft, "github.com/estafette/estafette-ci-api", SecretScopeContext{Branch: "master", Time: time.Now().UTC()}, trustedImages, credentials, secretHelper)
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(policy.Stages)) && assert.Equal(t, 2, len(policy.Stages[0].Secrets)) {
+			assert.False(t, policy.Stages[0].Secrets[0].Allowed)
+			assert.Equal(t, "Secret is restricted to another pipeline.", policy.Stages[0].Secrets[0].Reason)
+			assert.True(t, policy.Stages[0].Secrets[1].Allowed)
+			assert.Equal(t, "github.com/estafette/estafette-ci-api", policy.Stages[0].Secrets[1].PipelineWhitelist)
+		}
+	})
+
+	t.Run("ReturnsRefusedSecretOutOfScopeForBuildStages", func(t *testing.T) {
+
+		secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+		scopedSecret, err := EncryptScopedSecretEnvelope(secretHelper, "my secret", crypt.DefaultPipelineWhitelist, &SecretScope{ReleaseTargets: []string{"production"}, Groups: []string{"team-ci"}})
+		assert.Nil(t, err)
+
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.14", EnvVars: map[string]string{"A": scopedSecret}},
+			},
+			Releases: []*manifest.EstafetteRelease{
+				{Name: "production", Stages: []*manifest.EstafetteStage{
+					{Name: "deploy", ContainerImage: "extensions/gke:stable", EnvVars: map[string]string{"A": scopedSecret}},
+				}},
+			},
+		}
+
+		// act
+		policy, err := EvaluatePipelinePolicy(mft, "github.com/estafette/estafette-ci-api", SecretScopeContext{Groups: []string{"team-ci"}, Branch: "master", Time: time.Now().UTC()}, trustedImages, credentials, secretHelper)
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 2, len(policy.Stages)) && assert.Equal(t, 1, len(policy.Stages[0].Secrets)) && assert.Equal(t, 1, len(policy.Stages[1].Secrets)) {
+			assert.False(t, policy.Stages[0].Secrets[0].Allowed)
+			assert.Equal(t, "The secret is not available in this scope; it's restricted to release targets production.", policy.Stages[0].Secrets[0].Reason)
+			assert.True(t, policy.Stages[1].Secrets[0].Allowed)
+		}
+	})
+
+	t.Run("ReturnsRefusedSecretOutOfScopeForPipelineGroups", func(t *testing.T) {
+
+		secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+		scopedSecret, err := EncryptScopedSecretEnvelope(secretHelper, "my secret", crypt.DefaultPipelineWhitelist, &SecretScope{Groups: []string{"team-ci"}})
+		assert.Nil(t, err)
+
+		mft := &manifest.EstafetteManifest{
+			Stages: []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.14", EnvVars: map[string]string{"A": scopedSecret}},
+			},
+		}
+
+		// act
+		policy, err := EvaluatePipelinePolicy(mft, "github.com/estafette/estafette-ci-api", SecretScopeContext{Groups: []string{"team-web"}, Branch: "master", Time: time.Now().UTC()}, trustedImages, credentials, secretHelper)
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(policy.Stages)) && assert.Equal(t, 1, len(policy.Stages[0].Secrets)) {
+			assert.False(t, policy.Stages[0].Secrets[0].Allowed)
+		}
+	})
+}
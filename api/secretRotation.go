@@ -0,0 +1,249 @@
+package api
+
+import (
+	"errors"
+	"regexp"
+
+	crypt "github.com/estafette/estafette-ci-crypt"
+)
+
+// SecretKeyVersion tells which decryption key a secret is encrypted with
+type SecretKeyVersion string
+
+const (
+	SecretKeyCurrent  SecretKeyVersion = "current"
+	SecretKeyPrevious SecretKeyVersion = "previous"
+	SecretKeyUnknown  SecretKeyVersion = "unknown"
+)
+
+// KeyRotatingSecretHelper decrypts secrets encrypted with either the current or the previous decryption key and encrypts them with the current key, so secrets keep working while they're re-encrypted after a key rotation
+type KeyRotatingSecretHelper interface {
+	crypt.SecretHelper
+	GetSecretKeyVersion(encryptedTextPlusNonce, pipeline string) SecretKeyVersion
+	RotateAllEnvelopes(encryptedTextWithEnvelopes, pipeline string) (rotatedText string, rotatedSecrets int, err error)
+}
+
+type keyRotatingSecretHelperImpl struct {
+	current  crypt.SecretHelper
+	previous crypt.SecretHelper
+}
+
+// NewKeyRotatingSecretHelper returns a new api.KeyRotatingSecretHelper; previous can be nil if no key rotation is in progress
+func NewKeyRotatingSecretHelper(current, previous crypt.SecretHelper) KeyRotatingSecretHelper {
+	return &keyRotatingSecretHelperImpl{
+		current:  current,
+		previous: previous,
+	}
+}
+
+func (h *keyRotatingSecretHelperImpl) Encrypt(unencryptedText, pipelineWhitelist string) (encryptedTextPlusNonce string, err error) {
+	return h.current.Encrypt(unencryptedText, pipelineWhitelist)
+}
+
+func (h *keyRotatingSecretHelperImpl) Decrypt(encryptedTextPlusNonce, pipeline string) (decryptedText, pipelineWhitelist string, err error) {
+	decryptedText, pipelineWhitelist, err = h.current.Decrypt(encryptedTextPlusNonce, pipeline)
+	if h.shouldTryPrevious(err) {
+		return h.previous.Decrypt(encryptedTextPlusNonce, pipeline)
+	}
+
+	return
+}
+
+func (h *keyRotatingSecretHelperImpl) EncryptEnvelope(unencryptedText, pipelineWhitelist string) (encryptedTextInEnvelope string, err error) {
+	return h.current.EncryptEnvelope(unencryptedText, pipelineWhitelist)
+}
+
+func (h *keyRotatingSecretHelperImpl) DecryptEnvelope(encryptedTextInEnvelope, pipeline string) (decryptedText, pipelineWhitelist string, err error) {
+	decryptedText, pipelineWhitelist, err = h.current.DecryptEnvelope(encryptedTextInEnvelope, pipeline)
+	if h.shouldTryPrevious(err) {
+		return h.previous.DecryptEnvelope(encryptedTextInEnvelope, pipeline)
+	}
+
+	return
+}
+
+func (h *keyRotatingSecretHelperImpl) DecryptAllEnvelopes(encryptedTextWithEnvelopes, pipeline string) (decryptedText string, err error) {
+
+	r, err := regexp.Compile(crypt.SecretEnvelopeRegex)
+	if err != nil {
+		return
+	}
+
+	decryptedText = string(r.ReplaceAllFunc([]byte(encryptedTextWithEnvelopes), func(in []byte) []byte {
+		decrypted, _, err := h.DecryptEnvelope(string(in), pipeline)
+		if err != nil {
+			return nil
+		}
+		return []byte(decrypted)
+	}))
+
+	return
+}
+
+func (h *keyRotatingSecretHelperImpl) ReencryptAllEnvelopes(encryptedTextWithEnvelopes, pipeline string, base64encodedKey bool) (reencryptedText string, key string, err error) {
+
+	key, err = h.current.GenerateKey(32, base64encodedKey)
+	if err != nil {
+		return encryptedTextWithEnvelopes, key, err
+	}
+
+	jobSecretHelper := crypt.NewSecretHelper(key, base64encodedKey)
+
+	r, err := regexp.Compile(crypt.SecretEnvelopeRegex)
+	if err != nil {
+		return
+	}
+
+	reencryptedText = string(r.ReplaceAllFunc([]byte(encryptedTextWithEnvelopes), func(encryptedTextInEnvelope []byte) []byte {
+
+		decryptedText, pipelineWhitelist, err := h.DecryptEnvelope(string(encryptedTextInEnvelope), pipeline)
+		if err != nil {
+			return nil
+		}
+
+		reencryptedTextInEnvelope, err := jobSecretHelper.EncryptEnvelope(decryptedText, pipelineWhitelist)
+		if err != nil {
+			return nil
+		}
+
+		return []byte(reencryptedTextInEnvelope)
+	}))
+
+	return reencryptedText, key, nil
+}
+
+func (h *keyRotatingSecretHelperImpl) GenerateKey(numberOfBytes int, base64encodedKey bool) (key string, err error) {
+	return h.current.GenerateKey(numberOfBytes, base64encodedKey)
+}
+
+func (h *keyRotatingSecretHelperImpl) GetAllSecretEnvelopes(input string) (envelopes []string, err error) {
+	return h.current.GetAllSecretEnvelopes(input)
+}
+
+func (h *keyRotatingSecretHelperImpl) GetAllSecrets(input string) (secrets []string, err error) {
+	return h.current.GetAllSecrets(input)
+}
+
+func (h *keyRotatingSecretHelperImpl) GetAllSecretValues(input, pipeline string) (values []string, err error) {
+
+	secrets, err := h.GetAllSecrets(input)
+	if err != nil {
+		return
+	}
+
+	for _, s := range secrets {
+		decryptedText, _, err := h.Decrypt(s, pipeline)
+		if err != nil {
+			return []string{}, err
+		}
+		values = append(values, decryptedText)
+	}
+
+	return
+}
+
+// GetSecretKeyVersion returns which key the secret is encrypted with; a secret restricted to another pipeline is still recognized, since its whitelist can only be read with the right key
+func (h *keyRotatingSecretHelperImpl) GetSecretKeyVersion(encryptedTextPlusNonce, pipeline string) SecretKeyVersion {
+
+	_, _, err := h.current.Decrypt(encryptedTextPlusNonce, pipeline)
+	if err == nil || errors.Is(err, crypt.ErrRestrictedSecret) {
+		return SecretKeyCurrent
+	}
+
+	if h.previous != nil {
+		_, _, err = h.previous.Decrypt(encryptedTextPlusNonce, pipeline)
+		if err == nil || errors.Is(err, crypt.ErrRestrictedSecret) {
+			return SecretKeyPrevious
+		}
+	}
+
+	return SecretKeyUnknown
+}
+
+// RotateAllEnvelopes re-encrypts secrets encrypted with the previous key with the current key, keeping their pipeline whitelist and scope; secrets that can't be decrypted for the pipeline are left untouched
+func (h *keyRotatingSecretHelperImpl) RotateAllEnvelopes(encryptedTextWithEnvelopes, pipeline string) (rotatedText string, rotatedSecrets int, err error) {
+
+	if h.previous == nil {
+		return encryptedTextWithEnvelopes, 0, nil
+	}
+
+	r, err := regexp.Compile(crypt.SecretEnvelopeRegex)
+	if err != nil {
+		return
+	}
+
+	rotatedText = r.ReplaceAllStringFunc(encryptedTextWithEnvelopes, func(encryptedTextInEnvelope string) string {
+
+		_, _, err := h.current.DecryptEnvelope(encryptedTextInEnvelope, pipeline)
+		if err == nil || errors.Is(err, crypt.ErrRestrictedSecret) {
+			return encryptedTextInEnvelope
+		}
+
+		decryptedText, pipelineWhitelist, err := h.previous.DecryptEnvelope(encryptedTextInEnvelope, pipeline)
+		if err != nil {
+			return encryptedTextInEnvelope
+		}
+
+		rotatedTextInEnvelope, err := h.current.EncryptEnvelope(decryptedText, pipelineWhitelist)
+		if err != nil {
+			return encryptedTextInEnvelope
+		}
+
+		rotatedSecrets++
+		return rotatedTextInEnvelope
+	})
+
+	return
+}
+
+func (h *keyRotatingSecretHelperImpl) shouldTryPrevious(err error) bool {
+	return err != nil && !errors.Is(err, crypt.ErrRestrictedSecret) && h.previous != nil
+}
+
+// SecretKeyUsage counts the secrets per key they're encrypted with
+type SecretKeyUsage struct {
+	Current  int `json:"current"`
+	Previous int `json:"previous"`
+	Unknown  int `json:"unknown"`
+}
+
+// PipelineSecretKeyUsage counts the secrets in a pipeline's manifest per key
+type PipelineSecretKeyUsage struct {
+	RepoSource string `json:"repoSource"`
+	RepoOwner  string `json:"repoOwner"`
+	RepoName   string `json:"repoName"`
+	SecretKeyUsage
+}
+
+// SecretKeyRotationReport lists the config and the pipelines that still hold secrets that aren't encrypted with the current key
+type SecretKeyRotationReport struct {
+	Config    SecretKeyUsage            `json:"config"`
+	Pipelines []*PipelineSecretKeyUsage `json:"pipelines"`
+}
+
+// GetSecretKeyUsage counts the secrets in the text per key they're encrypted with
+func GetSecretKeyUsage(secretHelper KeyRotatingSecretHelper, text, pipeline string) (usage SecretKeyUsage, err error) {
+
+	secrets, err := secretHelper.GetAllSecrets(text)
+	if err != nil {
+		return
+	}
+
+	for _, s := range secrets {
+		switch secretHelper.GetSecretKeyVersion(s, pipeline) {
+		case SecretKeyCurrent:
+			usage.Current++
+		case SecretKeyPrevious:
+			usage.Previous++
+		default:
+			usage.Unknown++
+		}
+	}
+
+	return
+}
+
+// NeedsRotation returns true if any secrets aren't encrypted with the current key
+func (u SecretKeyUsage) NeedsRotation() bool {
+	return u.Previous > 0 || u.Unknown > 0
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+
+	crypt "github.com/estafette/estafette-ci-crypt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyRotatingSecretHelper(t *testing.T) {
+
+	currentSecretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+	previousSecretHelper := crypt.NewSecretHelper("7pB6HdaPt3ZUwfbEpQCh3ZRGcNgGYm6g", false)
+	pipeline := "github.com/estafette/estafette-ci-api"
+
+	t.Run("DecryptsEnvelopeEncryptedWithPreviousKey", func(t *testing.T) {
+
+		secretHelper := NewKeyRotatingSecretHelper(currentSecretHelper, previousSecretHelper)
+		envelope, err := previousSecretHelper.EncryptEnvelope("my secret", crypt.DefaultPipelineWhitelist)
+		assert.Nil(t, err)
+
+		// act
+		decryptedText, _, err := secretHelper.DecryptEnvelope(envelope, pipeline)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "my secret", decryptedText)
+	})
+
+	t.Run("ReturnsSecretKeyVersionPerKey", func(t *testing.T) {
+
+		secretHelper := NewKeyRotatingSecretHelper(currentSecretHelper, previousSecretHelper)
+		currentSecret, err := currentSecretHelper.Encrypt("my secret", "github.com/other/app")
+		assert.Nil(t, err)
+		previousSecret, err := previousSecretHelper.Encrypt("my secret", crypt.DefaultPipelineWhitelist)
+		assert.Nil(t, err)
+		unknownSecret, err := crypt.NewSecretHelper("gFXq4ksENHMfHkYDCDtmLFRzGxcJ2CUz", false).Encrypt("my secret", crypt.DefaultPipelineWhitelist)
+		assert.Nil(t, err)
+
+		assert.Equal(t, SecretKeyCurrent, secretHelper.GetSecretKeyVersion(currentSecret, pipeline))
+		assert.Equal(t, SecretKeyPrevious, secretHelper.GetSecretKeyVersion(previousSecret, pipeline))
+		assert.Equal(t, SecretKeyUnknown, secretHelper.GetSecretKeyVersion(unknownSecret, pipeline))
+	})
+
+	t.Run("RotatesOnlyEnvelopesEncryptedWithPreviousKey", func(t *testing.T) {
+
+		secretHelper := NewKeyRotatingSecretHelper(currentSecretHelper, previousSecretHelper)
+		currentEnvelope, err := currentSecretHelper.EncryptEnvelope("current secret", crypt.DefaultPipelineWhitelist)
+		assert.Nil(t, err)
+		previousEnvelope, err := previousSecretHelper.EncryptEnvelope("previous secret", pipeline)
+		assert.Nil(t, err)
+
+		// act
+		rotatedText, rotatedSecrets, err := secretHelper.RotateAllEnvelopes("a: "+currentEnvelope+"\nb: "+previousEnvelope, pipeline)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, rotatedSecrets)
+		decryptedText, err := currentSecretHelper.DecryptAllEnvelopes(rotatedText, pipeline)
+		assert.Nil(t, err)
+		assert.Equal(t, "a: current secret\nb: previous secret", decryptedText)
+
+		usage, err := GetSecretKeyUsage(secretHelper, rotatedText, pipeline)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, usage.Current)
+		assert.False(t, usage.NeedsRotation())
+	})
+}
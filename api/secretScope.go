@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	crypt "github.com/estafette/estafette-ci-crypt"
+	"github.com/rs/zerolog/log"
+)
+
+const scopedSecretPrefix = "estafette.scoped:"
+
+var (
+	// ErrSecretExpired is returned for a scoped secret past its expiry date
+	ErrSecretExpired = errors.New("The secret has expired")
+
+	// ErrSecretOutOfScope is returned for a scoped secret used outside of its organizations, groups, branches or release targets
+	ErrSecretOutOfScope = errors.New("The secret is not available in this scope")
+)
+
+// SecretScope further restricts where a secret can be used, on top of the pipeline whitelist; empty fields don't restrict anything
+type SecretScope struct {
+	Organizations  []string   `json:"organizations,omitempty"`
+	Groups         []string   `json:"groups,omitempty"`
+	Branches       []string   `json:"branches,omitempty"`
+	ReleaseTargets []string   `json:"releaseTargets,omitempty"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+}
+
+// SecretScopeContext is the build or release job a scoped secret is checked against
+type SecretScopeContext struct {
+	Organizations []string
+	Groups        []string
+	Branch        string
+	ReleaseTarget string
+	Time          time.Time
+}
+
+// NewSecretScopeContext returns the context scoped secrets are checked against for a build or release of a pipeline; the release target is empty for builds
+func NewSecretScopeContext(organizations []*contracts.Organization, groups []*contracts.Group, branch, releaseTarget string) SecretScopeContext {
+
+	scopeContext := SecretScopeContext{
+		Organizations: []string{},
+		Groups:        []string{},
+		Branch:        branch,
+		ReleaseTarget: releaseTarget,
+		Time:          time.Now().UTC(),
+	}
+	for _, o := range organizations {
+		if o != nil {
+			scopeContext.Organizations = append(scopeContext.Organizations, o.Name)
+		}
+	}
+	for _, g := range groups {
+		if g != nil {
+			scopeContext.Groups = append(scopeContext.Groups, g.Name)
+		}
+	}
+
+	return scopeContext
+}
+
+// scopedSecret is the value encrypted inside the envelope for secrets with a scope, since the envelope itself only holds the pipeline whitelist
+type scopedSecret struct {
+	Value string      `json:"value"`
+	Scope SecretScope `json:"scope"`
+}
+
+// IsEmpty returns true if the scope doesn't restrict anything
+func (s *SecretScope) IsEmpty() bool {
+	return s == nil || (len(s.Organizations) == 0 && len(s.Groups) == 0 && len(s.Branches) == 0 && len(s.ReleaseTargets) == 0 && s.ExpiresAt == nil)
+}
+
+// Validate checks the branch patterns to be valid regular expressions
+func (s *SecretScope) Validate() error {
+	if s == nil {
+		return nil
+	}
+	for _, b := range s.Branches {
+		if _, err := regexp.Compile(fmt.Sprintf("^%v$", b)); err != nil {
+			return fmt.Errorf("Branch pattern %v is not a valid regular expression: %w", b, err)
+		}
+	}
+
+	return nil
+}
+
+// HasExpired returns true if the scope has an expiry date that's not after t
+func (s *SecretScope) HasExpired(t time.Time) bool {
+	return s != nil && s.ExpiresAt != nil && !t.Before(*s.ExpiresAt)
+}
+
+// Allows returns nil if the secret can be used by the job; branches are regular expressions like the pipeline whitelist, a secret scoped to release targets can't be used in builds
+func (s *SecretScope) Allows(c SecretScopeContext) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.HasExpired(c.Time) {
+		return fmt.Errorf("%w on %v", ErrSecretExpired, s.ExpiresAt.Format(time.RFC3339))
+	}
+	if len(s.Organizations) > 0 && !stringArraysIntersect(s.Organizations, c.Organizations) {
+		return fmt.Errorf("%w; it's restricted to organizations %v", ErrSecretOutOfScope, strings.Join(s.Organizations, ", "))
+	}
+	if len(s.Groups) > 0 && !stringArraysIntersect(s.Groups, c.Groups) {
+		return fmt.Errorf("%w; it's restricted to groups %v", ErrSecretOutOfScope, strings.Join(s.Groups, ", "))
+	}
+	if len(s.Branches) > 0 {
+		matchesBranch := false
+		for _, b := range s.Branches {
+			if isMatch, _ := regexp.MatchString(fmt.Sprintf("^%v$", b), c.Branch); isMatch {
+				matchesBranch = true
+				break
+			}
+		}
+		if !matchesBranch {
+			return fmt.Errorf("%w; it's restricted to branches %v", ErrSecretOutOfScope, strings.Join(s.Branches, ", "))
+		}
+	}
+	if len(s.ReleaseTargets) > 0 && !StringArrayContains(s.ReleaseTargets, c.ReleaseTarget) {
+		return fmt.Errorf("%w; it's restricted to release targets %v", ErrSecretOutOfScope, strings.Join(s.ReleaseTargets, ", "))
+	}
+
+	return nil
+}
+
+// EncryptScopedSecretEnvelope encrypts the value like crypt.SecretHelper.EncryptEnvelope does, including the scope if it restricts anything
+func EncryptScopedSecretEnvelope(secretHelper crypt.SecretHelper, value, pipelineWhitelist string, scope *SecretScope) (string, error) {
+	if scope.IsEmpty() {
+		return secretHelper.EncryptEnvelope(value, pipelineWhitelist)
+	}
+
+	scopedBytes, err := json.Marshal(scopedSecret{Value: value, Scope: *scope})
+	if err != nil {
+		return "", err
+	}
+
+	return secretHelper.EncryptEnvelope(scopedSecretPrefix+string(scopedBytes), pipelineWhitelist)
+}
+
+// ParseScopedSecret returns the value and scope of a decrypted secret; the scope is nil for secrets without scope
+func ParseScopedSecret(decryptedText string) (value string, scope *SecretScope) {
+	if !strings.HasPrefix(decryptedText, scopedSecretPrefix) {
+		return decryptedText, nil
+	}
+
+	var s scopedSecret
+	err := json.Unmarshal([]byte(strings.TrimPrefix(decryptedText, scopedSecretPrefix)), &s)
+	if err != nil {
+		return decryptedText, nil
+	}
+
+	return s.Value, &s.Scope
+}
+
+var secretEnvelopeRegex = regexp.MustCompile(crypt.SecretEnvelopeRegex)
+
+// ContainsSecretEnvelope returns true if the value holds an estafette.secret(...) envelope; values that don't come from the pipeline's own manifest shouldn't carry one, otherwise a secret copied from elsewhere gets decrypted for the job
+func ContainsSecretEnvelope(value string) bool {
+	return secretEnvelopeRegex.MatchString(value)
+}
+
+// ReencryptScopedEnvelopes re-encrypts all secrets for the job with a newly generated key, like crypt.SecretHelper.ReencryptAllEnvelopes does, but leaves out secrets that are out of scope or expired
+func ReencryptScopedEnvelopes(secretHelper crypt.SecretHelper, encryptedTextWithEnvelopes, pipeline string, c SecretScopeContext) (reencryptedText string, key string, err error) {
+
+	key, err = secretHelper.GenerateKey(32, false)
+	if err != nil {
+		return encryptedTextWithEnvelopes, key, err
+	}
+	jobSecretHelper := crypt.NewSecretHelper(key, false)
+
+	r, err := regexp.Compile(crypt.SecretEnvelopeRegex)
+	if err != nil {
+		return
+	}
+
+	reencryptedText = string(r.ReplaceAllFunc([]byte(encryptedTextWithEnvelopes), func(encryptedTextInEnvelope []byte) []byte {
+
+		decryptedText, pipelineWhitelist, err := secretHelper.DecryptEnvelope(string(encryptedTextInEnvelope), pipeline)
+		if err != nil {
+			return nil
+		}
+
+		value, scope := ParseScopedSecret(decryptedText)
+		if err := scope.Allows(c); err != nil {
+			log.Info().Err(err).Msgf("Withholding scoped secret from job for pipeline %v", pipeline)
+			return nil
+		}
+
+		reencryptedTextInEnvelope, err := jobSecretHelper.EncryptEnvelope(value, pipelineWhitelist)
+		if err != nil {
+			return nil
+		}
+
+		return []byte(reencryptedTextInEnvelope)
+	}))
+
+	return reencryptedText, key, nil
+}
+
+// DecryptScopedEnvelopes decrypts all secrets like crypt.SecretHelper.DecryptAllEnvelopes does, but strips the scope from scoped secrets and leaves out expired ones; it's meant for the api's own config, which isn't used by a build or release job
+func DecryptScopedEnvelopes(secretHelper crypt.SecretHelper, encryptedTextWithEnvelopes, pipeline string) (decryptedText string, err error) {
+
+	r, err := regexp.Compile(crypt.SecretEnvelopeRegex)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	decryptedText = string(r.ReplaceAllFunc([]byte(encryptedTextWithEnvelopes), func(encryptedTextInEnvelope []byte) []byte {
+
+		decryptedTextInEnvelope, _, err := secretHelper.DecryptEnvelope(string(encryptedTextInEnvelope), pipeline)
+		if err != nil {
+			return nil
+		}
+
+		value, scope := ParseScopedSecret(decryptedTextInEnvelope)
+		if scope.HasExpired(now) {
+			log.Warn().Msgf("Leaving out scoped secret that has expired on %v", scope.ExpiresAt.Format(time.RFC3339))
+			return nil
+		}
+
+		return []byte(value)
+	}))
+
+	return
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	crypt "github.com/estafette/estafette-ci-crypt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretScopeAllows(t *testing.T) {
+
+	t.Run("ReturnsNilForNilScope", func(t *testing.T) {
+
+		var scope *SecretScope
+
+		// act
+		err := scope.Allows(SecretScopeContext{Branch: "master", Time: time.Now().UTC()})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrSecretExpiredIfExpiresAtIsInThePast", func(t *testing.T) {
+
+		expiresAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		scope := &SecretScope{ExpiresAt: &expiresAt}
+
+		// act
+		err := scope.Allows(SecretScopeContext{Time: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)})
+
+		assert.True(t, errors.Is(err, ErrSecretExpired))
+	})
+
+	t.Run("ReturnsErrSecretOutOfScopeIfBranchDoesNotMatch", func(t *testing.T) {
+
+		scope := &SecretScope{Branches: []string{"master", "release-.+"}}
+
+		// act
+		err := scope.Allows(SecretScopeContext{Branch: "feature-a"})
+
+		assert.True(t, errors.Is(err, ErrSecretOutOfScope))
+	})
+
+	t.Run("ReturnsNilIfBranchMatchesPattern", func(t *testing.T) {
+
+		scope := &SecretScope{Branches: []string{"master", "release-.+"}}
+
+		// act
+		err := scope.Allows(SecretScopeContext{Branch: "release-1"})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrSecretOutOfScopeForBuildIfRestrictedToReleaseTargets", func(t *testing.T) {
+
+		scope := &SecretScope{ReleaseTargets: []string{"production"}}
+
+		// act
+		err := scope.Allows(SecretScopeContext{Branch: "master"})
+
+		assert.True(t, errors.Is(err, ErrSecretOutOfScope))
+	})
+
+	t.Run("ReturnsErrSecretOutOfScopeIfOrganizationsDoNotIntersect", func(t *testing.T) {
+
+		scope := &SecretScope{Organizations: []string{"team-a"}}
+
+		// act
+		err := scope.Allows(SecretScopeContext{Organizations: []string{"team-b"}})
+
+		assert.True(t, errors.Is(err, ErrSecretOutOfScope))
+	})
+}
+
+func TestEncryptScopedSecretEnvelope(t *testing.T) {
+
+	secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+
+	t.Run("ReturnsPlainEnvelopeForEmptyScope", func(t *testing.T) {
+
+		// act
+		envelope, err := EncryptScopedSecretEnvelope(secretHelper, "my secret", crypt.DefaultPipelineWhitelist, &SecretScope{})
+
+		assert.Nil(t, err)
+		decryptedText, _, err := secretHelper.DecryptEnvelope(envelope, "github.com/estafette/estafette-ci-api")
+		assert.Nil(t, err)
+		assert.Equal(t, "my secret", decryptedText)
+	})
+
+	t.Run("ReturnsEnvelopeThatParsesBackIntoValueAndScope", func(t *testing.T) {
+
+		// act
+		envelope, err := EncryptScopedSecretEnvelope(secretHelper, "my secret", crypt.DefaultPipelineWhitelist, &SecretScope{ReleaseTargets: []string{"production"}})
+
+		assert.Nil(t, err)
+		decryptedText, _, err := secretHelper.DecryptEnvelope(envelope, "github.com/estafette/estafette-ci-api")
+		assert.Nil(t, err)
+		value, scope := ParseScopedSecret(decryptedText)
+		assert.Equal(t, "my secret", value)
+		if assert.NotNil(t, scope) {
+			assert.Equal(t, []string{"production"}, scope.ReleaseTargets)
+		}
+	})
+}
+
+func TestReencryptScopedEnvelopes(t *testing.T) {
+
+	t.Run("LeavesOutSecretsThatAreOutOfScope", func(t *testing.T) {
+
+		secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+		scopedEnvelope, err := EncryptScopedSecretEnvelope(secretHelper, "production secret", crypt.DefaultPipelineWhitelist, &SecretScope{ReleaseTargets: []string{"production"}})
+		assert.Nil(t, err)
+		envelope, err := secretHelper.EncryptEnvelope("build secret", crypt.DefaultPipelineWhitelist)
+		assert.Nil(t, err)
+
+		// act
+		reencryptedText, key, err := ReencryptScopedEnvelopes(secretHelper, "a: "+scopedEnvelope+"\nb: "+envelope, "github.com/estafette/estafette-ci-api", SecretScopeContext{Branch: "master", Time: time.Now().UTC()})
+
+		assert.Nil(t, err)
+		decryptedText, err := crypt.NewSecretHelper(key, false).DecryptAllEnvelopes(reencryptedText, "github.com/estafette/estafette-ci-api")
+		assert.Nil(t, err)
+		assert.Equal(t, "a: \nb: build secret", decryptedText)
+	})
+
+	t.Run("KeepsValueOfSecretThatIsInScope", func(t *testing.T) {
+
+		secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+		scopedEnvelope, err := EncryptScopedSecretEnvelope(secretHelper, "production secret", crypt.DefaultPipelineWhitelist, &SecretScope{ReleaseTargets: []string{"production"}})
+		assert.Nil(t, err)
+
+		// act
+		reencryptedText, key, err := ReencryptScopedEnvelopes(secretHelper, "a: "+scopedEnvelope, "github.com/estafette/estafette-ci-api", SecretScopeContext{ReleaseTarget: "production", Time: time.Now().UTC()})
+
+		assert.Nil(t, err)
+		decryptedText, err := crypt.NewSecretHelper(key, false).DecryptAllEnvelopes(reencryptedText, "github.com/estafette/estafette-ci-api")
+		assert.Nil(t, err)
+		assert.Equal(t, "a: production secret", decryptedText)
+	})
+}
+
+func TestDecryptScopedEnvelopes(t *testing.T) {
+
+	secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+
+	t.Run("ReturnsValueOfScopedSecretWithoutScope", func(t *testing.T) {
+
+		scopedEnvelope, err := EncryptScopedSecretEnvelope(secretHelper, "production secret", crypt.DefaultPipelineWhitelist, &SecretScope{ReleaseTargets: []string{"production"}})
+		assert.Nil(t, err)
+		envelope, err := secretHelper.EncryptEnvelope("global secret", crypt.DefaultPipelineWhitelist)
+		assert.Nil(t, err)
+
+		// act
+		decryptedText, err := DecryptScopedEnvelopes(secretHelper, "a: "+scopedEnvelope+"\nb: "+envelope, "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "a: production secret\nb: global secret", decryptedText)
+	})
+
+	t.Run("LeavesOutExpiredSecrets", func(t *testing.T) {
+
+		expiresAt := time.Now().UTC().Add(-time.Hour)
+		scopedEnvelope, err := EncryptScopedSecretEnvelope(secretHelper, "expired secret", crypt.DefaultPipelineWhitelist, &SecretScope{ExpiresAt: &expiresAt})
+		assert.Nil(t, err)
+
+		// act
+		decryptedText, err := DecryptScopedEnvelopes(secretHelper, "a: "+scopedEnvelope, "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "a: ", decryptedText)
+	})
+}
+
+func TestContainsSecretEnvelope(t *testing.T) {
+
+	t.Run("ReturnsTrueForValueWithEnvelope", func(t *testing.T) {
+		assert.True(t, ContainsSecretEnvelope("tag-estafette.secret(deFTz5Bdjg6SUe29.oPIkXbze5G9PNEWS2-ZnArl8BCqHnx4MdTdxHg37th9u)"))
+	})
+
+	t.Run("ReturnsFalseForPlainValue", func(t *testing.T) {
+		assert.False(t, ContainsSecretEnvelope("1.2.3"))
+	})
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// StageRun is a stage or nested stage that ran as part of a build, as recorded in the build log
+type StageRun struct {
+	BuildID      string
+	RepoSource   string
+	RepoOwner    string
+	RepoName     string
+	InsertedAt   time.Time
+	Stage        string
+	Image        string
+	Status       string
+	Duration     time.Duration
+	PullDuration time.Duration
+}
+
+// StageStats aggregates the runs of a stage of a pipeline, or of all stages using the same image across pipelines
+type StageStats struct {
+	Stage              string             `json:"stage,omitempty"`
+	Image              string             `json:"image,omitempty"`
+	Pipelines          int                `json:"pipelines"`
+	Runs               int                `json:"runs"`
+	FailedRuns         int                `json:"failedRuns"`
+	FailureRate        float64            `json:"failureRate"`
+	MedianDuration     time.Duration      `json:"medianDuration"`
+	P95Duration        time.Duration      `json:"p95Duration"`
+	MedianPullDuration time.Duration      `json:"medianPullDuration"`
+	P95PullDuration    time.Duration      `json:"p95PullDuration"`
+	Trend              []*StageTrendPoint `json:"trend,omitempty"`
+}
+
+// StageTrendPoint is a single run of a stage, to show how its duration develops over builds
+type StageTrendPoint struct {
+	BuildID      string        `json:"buildID"`
+	InsertedAt   time.Time     `json:"insertedAt"`
+	Status       string        `json:"status"`
+	Duration     time.Duration `json:"duration"`
+	PullDuration time.Duration `json:"pullDuration"`
+}
+
+// GetStageRuns returns the stages of a build log that finished running, with nested stages prefixed by the name of their parent; skipped and canceled stages are left out
+func GetStageRuns(buildLog *contracts.BuildLog) (runs []*StageRun) {
+
+	var add func(prefix string, steps []*contracts.BuildLogStep)
+	add = func(prefix string, steps []*contracts.BuildLogStep) {
+		for _, s := range steps {
+			if s == nil {
+				continue
+			}
+			if s.Status == contracts.StatusSucceeded || s.Status == contracts.StatusFailed {
+				run := &StageRun{
+					BuildID:    buildLog.BuildID,
+					RepoSource: buildLog.RepoSource,
+					RepoOwner:  buildLog.RepoOwner,
+					RepoName:   buildLog.RepoName,
+					InsertedAt: buildLog.InsertedAt,
+					Stage:      prefix + s.Step,
+					Status:     s.Status,
+					Duration:   s.Duration,
+				}
+				if s.Image != nil {
+					run.Image = s.Image.Name
+					if s.Image.Tag != "" {
+						run.Image = fmt.Sprintf("%v:%v", s.Image.Name, s.Image.Tag)
+					}
+					if s.Image.IsPulled {
+						run.PullDuration = s.Image.PullDuration
+					}
+				}
+				runs = append(runs, run)
+			}
+			add(prefix+s.Step+"/", s.NestedSteps)
+		}
+	}
+	add("", buildLog.Steps)
+
+	return
+}
+
+// ComputeStageStats returns the stats per stage including its trend over builds, in the order the stages ran
+func ComputeStageStats(runs []*StageRun) []*StageStats {
+	return computeStageStats(runs, func(r *StageRun) string { return r.Stage }, func(s *StageStats, key string) { s.Stage = key }, true)
+}
+
+// ComputeStageImageStats returns the stats per stage image across pipelines, slowest median duration first
+func ComputeStageImageStats(runs []*StageRun) []*StageStats {
+
+	stats := computeStageStats(runs, func(r *StageRun) string { return r.Image }, func(s *StageStats, key string) { s.Image = key }, false)
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].MedianDuration > stats[j].MedianDuration
+	})
+
+	return stats
+}
+
+func computeStageStats(runs []*StageRun, keyFunc func(*StageRun) string, setKey func(*StageStats, string), withTrend bool) []*StageStats {
+
+	// show trends from oldest to newest build
+	sorted := make([]*StageRun, len(runs))
+	copy(sorted, runs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].InsertedAt.Before(sorted[j].InsertedAt)
+	})
+
+	keys := []string{}
+	runsPerKey := map[string][]*StageRun{}
+	for _, r := range sorted {
+		key := keyFunc(r)
+		if key == "" {
+			continue
+		}
+		if _, ok := runsPerKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		runsPerKey[key] = append(runsPerKey[key], r)
+	}
+
+	stats := make([]*StageStats, 0, len(keys))
+	for _, key := range keys {
+		s := &StageStats{}
+		setKey(s, key)
+
+		durations := []time.Duration{}
+		pullDurations := []time.Duration{}
+		pipelines := map[string]bool{}
+		for _, r := range runsPerKey[key] {
+			s.Runs++
+			if r.Status == contracts.StatusFailed {
+				s.FailedRuns++
+			}
+			durations = append(durations, r.Duration)
+			pullDurations = append(pullDurations, r.PullDuration)
+			pipelines[fmt.Sprintf("%v/%v/%v", r.RepoSource, r.RepoOwner, r.RepoName)] = true
+
+			if withTrend {
+				s.Trend = append(s.Trend, &StageTrendPoint{
+					BuildID:      r.BuildID,
+					InsertedAt:   r.InsertedAt,
+					Status:       r.Status,
+					Duration:     r.Duration,
+					PullDuration: r.PullDuration,
+				})
+			}
+		}
+
+		s.Pipelines = len(pipelines)
+		s.FailureRate = float64(s.FailedRuns) / float64(s.Runs)
+		s.MedianDuration = medianDuration(durations)
+		s.P95Duration = percentileDuration(durations, 95)
+		s.MedianPullDuration = medianDuration(pullDurations)
+		s.P95PullDuration = percentileDuration(pullDurations, 95)
+
+		stats = append(stats, s)
+	}
+
+	return stats
+}
+
+// percentileDuration returns the nearest-rank percentile of the durations
+func percentileDuration(durations []time.Duration, percentile int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := (percentile*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStageRuns(t *testing.T) {
+
+	t.Run("ReturnsFinishedStagesAndNestedStagesWithImagePullDuration", func(t *testing.T) {
+
+		buildLog := &contracts.BuildLog{
+			BuildID:    "15",
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "estafette-ci-api",
+			Steps: []*contracts.BuildLogStep{
+				{Step: "build", Status: contracts.StatusSucceeded, Duration: 30 * time.Second, Image: &contracts.BuildLogStepDockerImage{Name: "golang", Tag: "1.14", IsPulled: true, PullDuration: 5 * time.Second}},
+				{Step: "tests", Status: contracts.StatusFailed, NestedSteps: []*contracts.BuildLogStep{
+					{Step: "unit", Status: contracts.StatusFailed, Duration: 10 * time.Second, Image: &contracts.BuildLogStepDockerImage{Name: "golang", Tag: "1.14", PullDuration: 5 * time.Second}},
+				}},
+				{Step: "push", Status: contracts.StatusSkipped},
+			},
+		}
+
+		// act
+		runs := GetStageRuns(buildLog)
+
+		if assert.Equal(t, 3, len(runs)) {
+			assert.Equal(t, "build", runs[0].Stage)
+			assert.Equal(t, "golang:1.14", runs[0].Image)
+			assert.Equal(t, 5*time.Second, runs[0].PullDuration)
+			assert.Equal(t, "15", runs[0].BuildID)
+			assert.Equal(t, "tests", runs[1].Stage)
+			assert.Equal(t, "tests/unit", runs[2].Stage)
+			assert.Equal(t, time.Duration(0), runs[2].PullDuration)
+		}
+	})
+}
+
+func TestComputeStageStats(t *testing.T) {
+
+	start := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	runs := []*StageRun{}
+	for i := 1; i <= 20; i++ {
+		status := contracts.StatusSucceeded
+		if i%5 == 0 {
+			status = contracts.StatusFailed
+		}
+		runs = append(runs, &StageRun{
+			BuildID:      strconv.Itoa(i),
+			RepoSource:   "github.com",
+			RepoOwner:    "estafette",
+			RepoName:     "estafette-ci-api",
+			InsertedAt:   start.Add(time.Duration(i) * time.Hour),
+			Stage:        "build",
+			Image:        "golang:1.14",
+			Status:       status,
+			Duration:     time.Duration(i) * time.Second,
+			PullDuration: time.Second,
+		})
+	}
+
+	t.Run("ReturnsMedianAndP95DurationsAndFailureRatePerStage", func(t *testing.T) {
+
+		// act
+		stats := ComputeStageStats(runs)
+
+		if assert.Equal(t, 1, len(stats)) {
+			assert.Equal(t, "build", stats[0].Stage)
+			assert.Equal(t, 20, stats[0].Runs)
+			assert.Equal(t, 4, stats[0].FailedRuns)
+			assert.Equal(t, 0.2, stats[0].FailureRate)
+			assert.Equal(t, 10500*time.Millisecond, stats[0].MedianDuration)
+			assert.Equal(t, 19*time.Second, stats[0].P95Duration)
+			assert.Equal(t, time.Second, stats[0].MedianPullDuration)
+			if assert.Equal(t, 20, len(stats[0].Trend)) {
+				assert.Equal(t, time.Second, stats[0].Trend[0].Duration)
+				assert.Equal(t, 20*time.Second, stats[0].Trend[19].Duration)
+			}
+		}
+	})
+
+	t.Run("ReturnsStatsPerImageAcrossPipelinesSlowestFirst", func(t *testing.T) {
+
+		otherRuns := append(append([]*StageRun{}, runs...), &StageRun{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "other", Stage: "deploy", Image: "extensions/gke:stable", Status: contracts.StatusSucceeded, Duration: time.Minute}, &StageRun{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "other", Stage: "build", Image: "golang:1.14", Status: contracts.StatusSucceeded, Duration: time.Second})
+
+		// act
+		stats := ComputeStageImageStats(otherRuns)
+
+		if assert.Equal(t, 2, len(stats)) {
+			assert.Equal(t, "extensions/gke:stable", stats[0].Image)
+			assert.Equal(t, "golang:1.14", stats[1].Image)
+			assert.Equal(t, 2, stats[1].Pipelines)
+			assert.Equal(t, 21, stats[1].Runs)
+			assert.Nil(t, stats[1].Trend)
+		}
+	})
+}
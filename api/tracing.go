@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/opentracing/opentracing-go/log"
@@ -21,3 +23,13 @@ func FinishSpanWithError(span opentracing.Span, err error) {
 	}
 	FinishSpan(span)
 }
+
+// DetachContext returns a context that isn't cancelled along with ctx but carries its span, for work that outlives the request, like firing triggers in the background
+func DetachContext(ctx context.Context) context.Context {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return context.Background()
+	}
+
+	return opentracing.ContextWithSpan(context.Background(), span)
+}
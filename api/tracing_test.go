@@ -0,0 +1,26 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetachContext(t *testing.T) {
+
+	t.Run("ReturnsContextThatIsNotCancelledWithParentButCarriesItsSpan", func(t *testing.T) {
+
+		span := opentracing.NoopTracer{}.StartSpan("FinishBuild")
+		ctx, cancel := context.WithCancel(opentracing.ContextWithSpan(context.Background(), span))
+
+		// act
+		detachedCtx := DetachContext(ctx)
+		cancel()
+
+		assert.NotNil(t, ctx.Err())
+		assert.Nil(t, detachedCtx.Err())
+		assert.Equal(t, span, opentracing.SpanFromContext(detachedCtx))
+	})
+}
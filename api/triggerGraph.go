@@ -0,0 +1,153 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+// TriggerFiring is a pipeline action a trigger fires for an event
+type TriggerFiring struct {
+	RepoSource string                    `json:"repoSource"`
+	RepoOwner  string                    `json:"repoOwner"`
+	RepoName   string                    `json:"repoName"`
+	Action     string                    `json:"action"`
+	Trigger    manifest.EstafetteTrigger `json:"trigger"`
+}
+
+// TriggerGraph holds the pipelines and other event sources connected to each other by triggers
+type TriggerGraph struct {
+	Nodes []*TriggerGraphNode `json:"nodes"`
+	Edges []*TriggerGraphEdge `json:"edges"`
+}
+
+// TriggerGraphNode is a pipeline or any other source of trigger events, like a git repository, docker image, pubsub topic or cron schedule
+type TriggerGraphNode struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// TriggerGraphEdge is a trigger of the To pipeline firing for events of the From node
+type TriggerGraphEdge struct {
+	From    string                    `json:"from"`
+	To      string                    `json:"to"`
+	Trigger manifest.EstafetteTrigger `json:"trigger"`
+}
+
+// NewTriggerGraph returns an empty graph
+func NewTriggerGraph() *TriggerGraph {
+	return &TriggerGraph{
+		Nodes: []*TriggerGraphNode{},
+		Edges: []*TriggerGraphEdge{},
+	}
+}
+
+// HasNode returns true if a node with the id is part of the graph
+func (g *TriggerGraph) HasNode(id string) bool {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddNode adds a node unless a node with the same id already exists
+func (g *TriggerGraph) AddNode(node *TriggerGraphNode) {
+	if !g.HasNode(node.ID) {
+		g.Nodes = append(g.Nodes, node)
+	}
+}
+
+// AddEdge adds the trigger as an edge unless the exact same edge already exists
+func (g *TriggerGraph) AddEdge(edge *TriggerGraphEdge) {
+	label := edge.Label()
+	for _, e := range g.Edges {
+		if e.From == edge.From && e.To == edge.To && e.Label() == label {
+			return
+		}
+	}
+
+	g.Edges = append(g.Edges, edge)
+}
+
+// DOT renders the graph in the graphviz dot language
+func (g *TriggerGraph) DOT(name string) string {
+
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("digraph %v {\n", strconv.Quote(name)))
+	sb.WriteString("  rankdir=LR;\n")
+
+	for _, n := range g.Nodes {
+		shape := "box"
+		if n.Type != "pipeline" {
+			shape = "ellipse"
+		}
+		sb.WriteString(fmt.Sprintf("  %v [label=%v, shape=%v];\n", strconv.Quote(n.ID), strconv.Quote(n.Name), shape))
+	}
+
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %v -> %v [label=%v];\n", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Label())))
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// Label describes the event the trigger fires for and the action it takes, like 'finished -> builds master'
+func (e *TriggerGraphEdge) Label() string {
+
+	var event string
+	switch {
+	case e.Trigger.Pipeline != nil:
+		event = e.Trigger.Pipeline.Event
+	case e.Trigger.Release != nil:
+		event = strings.TrimSpace(fmt.Sprintf("%v %v", e.Trigger.Release.Target, e.Trigger.Release.Event))
+	case e.Trigger.Git != nil:
+		event = strings.TrimSpace(fmt.Sprintf("%v %v", e.Trigger.Git.Event, e.Trigger.Git.Branch))
+	case e.Trigger.PubSub != nil:
+		event = "message"
+	case e.Trigger.Cron != nil:
+		event = "schedule"
+	case e.Trigger.Docker != nil:
+		event = strings.TrimSpace(fmt.Sprintf("%v %v", e.Trigger.Docker.Event, e.Trigger.Docker.Tag))
+	}
+
+	var action string
+	switch {
+	case e.Trigger.BuildAction != nil:
+		action = strings.TrimSpace(fmt.Sprintf("builds %v", e.Trigger.BuildAction.Branch))
+	case e.Trigger.ReleaseAction != nil:
+		action = strings.TrimSpace(fmt.Sprintf("releases %v %v", e.Trigger.ReleaseAction.Target, e.Trigger.ReleaseAction.Action))
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%v -> %v", event, action))
+}
+
+// GetTriggerSourceNode returns the node for the source of the events a trigger fires for
+func GetTriggerSourceNode(t manifest.EstafetteTrigger) *TriggerGraphNode {
+	switch {
+	case t.Pipeline != nil:
+		return &TriggerGraphNode{ID: strings.ToLower(t.Pipeline.Name), Type: "pipeline", Name: t.Pipeline.Name}
+	case t.Release != nil:
+		return &TriggerGraphNode{ID: strings.ToLower(t.Release.Name), Type: "pipeline", Name: t.Release.Name}
+	case t.Git != nil:
+		return &TriggerGraphNode{ID: "git:" + strings.ToLower(t.Git.Repository), Type: "git", Name: t.Git.Repository}
+	case t.PubSub != nil:
+		name := fmt.Sprintf("projects/%v/topics/%v", t.PubSub.Project, t.PubSub.Topic)
+		return &TriggerGraphNode{ID: "pubsub:" + name, Type: "pubsub", Name: name}
+	case t.Cron != nil:
+		return &TriggerGraphNode{ID: "cron:" + t.Cron.Schedule, Type: "cron", Name: t.Cron.Schedule}
+	case t.Docker != nil:
+		name := ParseDockerImageReference(t.Docker.Image).Name()
+		return &TriggerGraphNode{ID: "docker:" + strings.ToLower(name), Type: "docker", Name: name}
+	}
+
+	return nil
+}
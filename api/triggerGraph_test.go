@@ -0,0 +1,50 @@
+package api
+
+import (
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTriggerGraph(t *testing.T) {
+	t.Run("AddEdgeSkipsIdenticalEdges", func(t *testing.T) {
+
+		graph := NewTriggerGraph()
+		trigger := manifest.EstafetteTrigger{
+			Pipeline:    &manifest.EstafettePipelineTrigger{Name: "github.com/estafette/repo-a", Event: "finished"},
+			BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"},
+		}
+
+		// act
+		graph.AddEdge(&TriggerGraphEdge{From: "github.com/estafette/repo-a", To: "github.com/estafette/repo-b", Trigger: trigger})
+		graph.AddEdge(&TriggerGraphEdge{From: "github.com/estafette/repo-a", To: "github.com/estafette/repo-b", Trigger: trigger})
+
+		assert.Equal(t, 1, len(graph.Edges))
+	})
+
+	t.Run("DOTRendersNodesAndEdges", func(t *testing.T) {
+
+		graph := NewTriggerGraph()
+		trigger := manifest.EstafetteTrigger{
+			Release:       &manifest.EstafetteReleaseTrigger{Name: "github.com/estafette/repo-a", Target: "staging", Event: "finished"},
+			ReleaseAction: &manifest.EstafetteTriggerReleaseAction{Target: "production"},
+		}
+		graph.AddNode(GetTriggerSourceNode(trigger))
+		graph.AddNode(&TriggerGraphNode{ID: "github.com/estafette/repo-b", Type: "pipeline", Name: "github.com/estafette/repo-b"})
+		graph.AddNode(&TriggerGraphNode{ID: "pubsub:projects/p/topics/t", Type: "pubsub", Name: "projects/p/topics/t"})
+		graph.AddEdge(&TriggerGraphEdge{From: "github.com/estafette/repo-a", To: "github.com/estafette/repo-b", Trigger: trigger})
+
+		// act
+		dot := graph.DOT("github.com/estafette/repo-b")
+
+		assert.Equal(t, `digraph "github.com/estafette/repo-b" {
+  rankdir=LR;
+  "github.com/estafette/repo-a" [label="github.com/estafette/repo-a", shape=box];
+  "github.com/estafette/repo-b" [label="github.com/estafette/repo-b", shape=box];
+  "pubsub:projects/p/topics/t" [label="projects/p/topics/t", shape=ellipse];
+  "github.com/estafette/repo-a" -> "github.com/estafette/repo-b" [label="staging finished -> releases production"];
+}
+`, dot)
+	})
+}
@@ -1,9 +1,6 @@
 package api
 
 import (
-	"encoding/json"
-	"errors"
-	"fmt"
 	"strings"
 
 	contracts "github.com/estafette/estafette-ci-contracts"
@@ -15,17 +12,21 @@ import (
 type WarningHelper interface {
 	GetManifestWarnings(*manifest.EstafetteManifest, string) ([]contracts.Warning, error)
 	GetContainerImageParts(string) (string, string, string)
+	GetLintRules(organizations []*contracts.Organization) []*LintRule
+	Lint(input LintInput) ([]LintFinding, error)
 }
 
 type warningHelperImpl struct {
 	secretHelper crypt.SecretHelper
+	config       *APIConfig
 }
 
 // NewWarningHelper returns a new estafette.WarningHelper
-func NewWarningHelper(secretHelper crypt.SecretHelper) (warningHelper WarningHelper) {
+func NewWarningHelper(secretHelper crypt.SecretHelper, config *APIConfig) (warningHelper WarningHelper) {
 
 	warningHelper = &warningHelperImpl{
 		secretHelper: secretHelper,
+		config:       config,
 	}
 
 	return
@@ -34,110 +35,43 @@ func NewWarningHelper(secretHelper crypt.SecretHelper) (warningHelper WarningHel
 func (w *warningHelperImpl) GetManifestWarnings(manifest *manifest.EstafetteManifest, fullRepoPath string) (warnings []contracts.Warning, err error) {
 	warnings = []contracts.Warning{}
 
-	isEstafettePipeline := strings.HasPrefix(fullRepoPath, "github.com/estafette/")
-
-	if manifest != nil {
-		// check all build and release stages to have a pinned version
-		stagesUsingLatestTag := []string{}
-		stagesUsingDevTag := []string{}
-		for _, s := range manifest.Stages {
-			if len(s.ParallelStages) > 0 {
-				for _, ns := range s.ParallelStages {
-					containerImageRepo, _, containerImageTag := w.GetContainerImageParts(ns.ContainerImage)
-					if containerImageTag == "latest" {
-						stagesUsingLatestTag = append(stagesUsingLatestTag, ns.Name)
-					}
-					if containerImageTag == "dev" && containerImageRepo == "extensions" && !isEstafettePipeline {
-						stagesUsingDevTag = append(stagesUsingDevTag, ns.Name)
-					}
-				}
-			} else {
-				containerImageRepo, _, containerImageTag := w.GetContainerImageParts(s.ContainerImage)
-				if containerImageTag == "latest" {
-					stagesUsingLatestTag = append(stagesUsingLatestTag, s.Name)
-				}
-				if containerImageTag == "dev" && containerImageRepo == "extensions" && !isEstafettePipeline {
-					stagesUsingDevTag = append(stagesUsingDevTag, s.Name)
-				}
-			}
-		}
+	findings, err := w.Lint(LintInput{
+		Manifest:     manifest,
+		FullRepoPath: fullRepoPath,
+	})
+	for _, f := range findings {
+		warnings = append(warnings, f.ToWarning())
+	}
 
-		for _, r := range manifest.Releases {
-			for _, s := range r.Stages {
-				if len(s.ParallelStages) > 0 {
-					for _, ns := range s.ParallelStages {
-						containerImageRepo, _, containerImageTag := w.GetContainerImageParts(ns.ContainerImage)
-						if containerImageTag == "latest" {
-							stagesUsingLatestTag = append(stagesUsingLatestTag, ns.Name)
-						}
-						if containerImageTag == "dev" && containerImageRepo == "extensions" && !isEstafettePipeline {
-							stagesUsingDevTag = append(stagesUsingDevTag, ns.Name)
-						}
-					}
-				} else {
-					containerImageRepo, _, containerImageTag := w.GetContainerImageParts(s.ContainerImage)
-					if containerImageTag == "latest" {
-						stagesUsingLatestTag = append(stagesUsingLatestTag, fmt.Sprintf("%v/%v", r.Name, s.Name))
-					}
-					if containerImageTag == "dev" && containerImageRepo == "extensions" && !isEstafettePipeline {
-						stagesUsingDevTag = append(stagesUsingDevTag, s.Name)
-					}
-				}
-			}
-		}
+	return
+}
 
-		if len(stagesUsingLatestTag) > 0 {
-			warnings = append(warnings, contracts.Warning{
-				Status:  "warning",
-				Message: fmt.Sprintf("This pipeline has one or more stages that use **latest** or no tag for its container image: `%v`; it is [best practice](https://estafette.io/usage/best-practices/#pin-image-versions) to pin stage images to specific versions so you don't spend hours tracking down build failures because the used image has changed.", strings.Join(stagesUsingLatestTag, ", ")),
-			})
-		}
-		if len(stagesUsingDevTag) > 0 {
-			warnings = append(warnings, contracts.Warning{
-				Status:  "warning",
-				Message: fmt.Sprintf("This pipeline has one or more stages that use the **dev** tag for its container image: `%v`; it is [best practice](https://estafette.io/usage/best-practices/#avoid-using-estafette-s-dev-or-beta-tags) to avoid the dev tag alltogether, since it can be broken at any time.", strings.Join(stagesUsingDevTag, ", ")),
-			})
-		}
+func (w *warningHelperImpl) GetLintRules(organizations []*contracts.Organization) []*LintRule {
+	var lintingConfig *LintingConfig
+	if w.config != nil {
+		lintingConfig = w.config.Linting
+	}
 
-		if manifest.Builder.Track == "dev" && !isEstafettePipeline {
-			warnings = append(warnings, contracts.Warning{
-				Status:  "warning",
-				Message: "This pipeline uses the **dev** track for the builder; it is [best practice](https://estafette.io/usage/best-practices/#avoid-using-estafette-s-builder-dev-track) to avoid the dev track, since it can be broken at any time.",
-			})
-		}
+	return getLintRules(lintingConfig, organizations)
+}
 
-		manifestBytes, err := json.Marshal(manifest)
-		if err != nil {
-			return warnings, err
-		}
+func (w *warningHelperImpl) Lint(input LintInput) (findings []LintFinding, err error) {
+	findings = []LintFinding{}
 
-		secretValues, err := w.secretHelper.GetAllSecrets(string(manifestBytes))
-		if err != nil {
-			return warnings, err
+	if input.Manifest == nil {
+		return
+	}
+
+	for _, rule := range w.GetLintRules(input.Organizations) {
+		if !rule.Enabled {
+			continue
 		}
 
-		for _, sv := range secretValues {
-			_, pipelineWhitelist, err := w.secretHelper.Decrypt(sv, fullRepoPath)
-			if err != nil && !errors.Is(err, crypt.ErrRestrictedSecret) {
-				return warnings, err
-			}
-
-			if errors.Is(err, crypt.ErrRestrictedSecret) {
-				warnings = append(warnings, contracts.Warning{
-					Status:  "warning",
-					Message: "This pipeline uses a _restricted secret_ created for another pipeline; please replace it with one created for this pipeline.",
-				})
-				break
-			}
-
-			if pipelineWhitelist == crypt.DefaultPipelineWhitelist {
-				warnings = append(warnings, contracts.Warning{
-					Status:  "warning",
-					Message: "This pipeline uses _global_ secrets which can be used by any pipeline; it is [best practice](https://estafette.io/usage/best-practices/#use-pipeline-restricted-secrets-instead-of-global-secrets) to use _restricted_ secrets instead, that can only be used by this pipeline. Please rotate the value stored in the secret and create a new one in the pipeline's secrets tab.",
-				})
-				break
-			}
+		ruleFindings, err := rule.check(w, rule, input)
+		if err != nil {
+			return findings, err
 		}
+		findings = append(findings, ruleFindings...)
 	}
 
 	return
@@ -9,7 +9,7 @@ import (
 )
 
 var (
-	helper = NewWarningHelper(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false))
+	helper = NewWarningHelper(crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false), &APIConfig{})
 )
 
 func TestGetManifestWarnings(t *testing.T) {
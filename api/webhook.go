@@ -0,0 +1,194 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+const (
+	// WebhookDeliveryStatusFired is the status of a delivery that started a build or release
+	WebhookDeliveryStatusFired = "fired"
+	// WebhookDeliveryStatusFailed is the status of a delivery that didn't start a build or release
+	WebhookDeliveryStatusFailed = "failed"
+
+	// WebhookEventUserIDPrefix marks the manual event recorded for builds and releases started by a webhook; the manifest has no webhook event type, so the webhook name is carried as user id behind this prefix
+	WebhookEventUserIDPrefix = "webhook:"
+
+	webhookVariablePrefix = "ESTAFETTE_WEBHOOK_"
+)
+
+var webhookVariableNameRegex = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+// Webhook is an inbound endpoint for a pipeline that external systems can call to start a build or release
+type Webhook struct {
+	ID            string                                  `json:"id,omitempty"`
+	RepoSource    string                                  `json:"repoSource"`
+	RepoOwner     string                                  `json:"repoOwner"`
+	RepoName      string                                  `json:"repoName"`
+	Name          string                                  `json:"name"`
+	BuildAction   *manifest.EstafetteTriggerBuildAction   `json:"builds,omitempty"`
+	ReleaseAction *manifest.EstafetteTriggerReleaseAction `json:"releases,omitempty"`
+	CreatedBy     string                                  `json:"createdBy,omitempty"`
+	InsertedAt    *time.Time                              `json:"insertedAt,omitempty"`
+	Active        bool                                    `json:"active"`
+
+	// TokenHash holds the sha256 hash of the secret token, the token itself is never stored
+	TokenHash string `json:"-"`
+
+	// Token holds the secret token; it's only returned once upon creation
+	Token string `json:"token,omitempty"`
+}
+
+// WebhookDelivery is a call to a webhook, with the payload it received and whether it started a build or release
+type WebhookDelivery struct {
+	ID          string            `json:"id,omitempty"`
+	WebhookID   string            `json:"webhookID"`
+	WebhookName string            `json:"webhookName"`
+	RepoSource  string            `json:"repoSource"`
+	RepoOwner   string            `json:"repoOwner"`
+	RepoName    string            `json:"repoName"`
+	Status      string            `json:"status"`
+	Message     string            `json:"message,omitempty"`
+	Payload     interface{}       `json:"payload,omitempty"`
+	Variables   map[string]string `json:"variables,omitempty"`
+	InsertedAt  *time.Time        `json:"insertedAt,omitempty"`
+}
+
+// Validate checks whether the webhook has a name and exactly one action to fire
+func (w *Webhook) Validate() error {
+	if w.Name == "" {
+		return errors.New("Webhook name is required")
+	}
+	if w.BuildAction == nil && w.ReleaseAction == nil {
+		return errors.New("Webhook needs either a builds or releases action")
+	}
+	if w.BuildAction != nil && w.ReleaseAction != nil {
+		return errors.New("Webhook can't have both a builds and releases action")
+	}
+	if w.BuildAction != nil && w.BuildAction.Branch == "" {
+		return errors.New("Webhook builds action needs a branch")
+	}
+	if w.ReleaseAction != nil && w.ReleaseAction.Target == "" {
+		return errors.New("Webhook releases action needs a target")
+	}
+
+	return nil
+}
+
+// GetTrigger returns the webhook's action as a trigger, so it can be fired like any manifest trigger
+func (w *Webhook) GetTrigger() manifest.EstafetteTrigger {
+	return manifest.EstafetteTrigger{
+		BuildAction:   w.BuildAction,
+		ReleaseAction: w.ReleaseAction,
+	}
+}
+
+// GetEvent returns the event recorded on builds and releases started by the webhook
+func (w *Webhook) GetEvent() manifest.EstafetteEvent {
+	return manifest.EstafetteEvent{
+		Manual: &manifest.EstafetteManualEvent{
+			UserID: WebhookEventUserIDPrefix + w.Name,
+		},
+	}
+}
+
+// GetWebhookEventName returns the name of the webhook that caused the event, or false if the event wasn't caused by a webhook
+func GetWebhookEventName(e manifest.EstafetteEvent) (string, bool) {
+	if e.Manual == nil || !strings.HasPrefix(e.Manual.UserID, WebhookEventUserIDPrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(e.Manual.UserID, WebhookEventUserIDPrefix), true
+}
+
+// SetToken generates a new secret token and stores its hash
+func (w *Webhook) SetToken() error {
+	tokenBytes := make([]byte, 32)
+	_, err := rand.Read(tokenBytes)
+	if err != nil {
+		return err
+	}
+
+	w.Token = hex.EncodeToString(tokenBytes)
+	w.TokenHash = hashWebhookToken(w.Token)
+
+	return nil
+}
+
+// HasToken returns true if the token matches the webhook's secret token
+func (w *Webhook) HasToken(token string) bool {
+	if token == "" || w.TokenHash == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(hashWebhookToken(token)), []byte(w.TokenHash)) == 1
+}
+
+func hashWebhookToken(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+}
+
+// GetWebhookVariables flattens a webhook payload into environment variables; nested fields are joined by underscores, for example {"image":{"tag":"1.0.0"}} becomes ESTAFETTE_WEBHOOK_PAYLOAD_IMAGE_TAG=1.0.0
+func GetWebhookVariables(webhookName string, payload interface{}) map[string]string {
+
+	variables := map[string]string{
+		webhookVariablePrefix + "NAME": webhookName,
+	}
+
+	if payload == nil {
+		return variables
+	}
+
+	// the raw payload allows for parsing it in a stage if the flattened variables don't suffice
+	payloadBytes, err := json.Marshal(payload)
+	if err == nil {
+		variables[webhookVariablePrefix+"PAYLOAD"] = string(payloadBytes)
+	}
+
+	flattenWebhookPayload(webhookVariablePrefix+"PAYLOAD", payload, variables)
+
+	return variables
+}
+
+func flattenWebhookPayload(name string, value interface{}, variables map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			flattenWebhookPayload(name+"_"+toWebhookVariableName(key), v[key], variables)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flattenWebhookPayload(name+"_"+strconv.Itoa(i), item, variables)
+		}
+	case string:
+		variables[name] = v
+	case nil:
+		variables[name] = ""
+	default:
+		valueBytes, err := json.Marshal(v)
+		if err == nil {
+			variables[name] = string(valueBytes)
+		}
+	}
+}
+
+func toWebhookVariableName(key string) string {
+	return strings.Trim(webhookVariableNameRegex.ReplaceAllString(strings.ToUpper(key), "_"), "_")
+}
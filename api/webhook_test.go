@@ -0,0 +1,125 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Run("ValidateReturnsErrorIfWebhookHasNoAction", func(t *testing.T) {
+
+		webhook := Webhook{Name: "monitoring"}
+
+		// act
+		err := webhook.Validate()
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ValidateReturnsErrorIfWebhookHasBothActions", func(t *testing.T) {
+
+		webhook := Webhook{
+			Name:          "monitoring",
+			BuildAction:   &manifest.EstafetteTriggerBuildAction{Branch: "master"},
+			ReleaseAction: &manifest.EstafetteTriggerReleaseAction{Target: "production"},
+		}
+
+		// act
+		err := webhook.Validate()
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ValidateReturnsNilIfWebhookHasReleaseAction", func(t *testing.T) {
+
+		webhook := Webhook{
+			Name:          "vendor",
+			ReleaseAction: &manifest.EstafetteTriggerReleaseAction{Target: "production"},
+		}
+
+		// act
+		err := webhook.Validate()
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("HasTokenReturnsTrueForGeneratedTokenOnly", func(t *testing.T) {
+
+		webhook := Webhook{Name: "vendor"}
+		err := webhook.SetToken()
+		assert.Nil(t, err)
+
+		// act
+		assert.True(t, webhook.HasToken(webhook.Token))
+		assert.False(t, webhook.HasToken(webhook.TokenHash))
+		assert.False(t, webhook.HasToken(""))
+	})
+
+	t.Run("TokenIsNotMarshalledAsHash", func(t *testing.T) {
+
+		webhook := Webhook{Name: "vendor"}
+		err := webhook.SetToken()
+		assert.Nil(t, err)
+
+		// act
+		bytes, err := json.Marshal(webhook)
+
+		assert.Nil(t, err)
+		assert.NotContains(t, string(bytes), webhook.TokenHash)
+	})
+}
+
+func TestGetWebhookVariables(t *testing.T) {
+	t.Run("FlattensNestedPayloadFields", func(t *testing.T) {
+
+		var payload interface{}
+		err := json.Unmarshal([]byte(`{"image":{"tag":"1.0.0","digest":"sha256:abc"},"alerts":[{"status":"firing"}],"count":3,"dry-run":true}`), &payload)
+		assert.Nil(t, err)
+
+		// act
+		variables := GetWebhookVariables("vendor", payload)
+
+		assert.Equal(t, "vendor", variables["ESTAFETTE_WEBHOOK_NAME"])
+		assert.Equal(t, "1.0.0", variables["ESTAFETTE_WEBHOOK_PAYLOAD_IMAGE_TAG"])
+		assert.Equal(t, "sha256:abc", variables["ESTAFETTE_WEBHOOK_PAYLOAD_IMAGE_DIGEST"])
+		assert.Equal(t, "firing", variables["ESTAFETTE_WEBHOOK_PAYLOAD_ALERTS_0_STATUS"])
+		assert.Equal(t, "3", variables["ESTAFETTE_WEBHOOK_PAYLOAD_COUNT"])
+		assert.Equal(t, "true", variables["ESTAFETTE_WEBHOOK_PAYLOAD_DRY_RUN"])
+		assert.Contains(t, variables["ESTAFETTE_WEBHOOK_PAYLOAD"], `"tag":"1.0.0"`)
+	})
+
+	t.Run("ReturnsNameOnlyWithoutPayload", func(t *testing.T) {
+
+		// act
+		variables := GetWebhookVariables("vendor", nil)
+
+		assert.Equal(t, 1, len(variables))
+	})
+}
+
+func TestGetWebhookEventName(t *testing.T) {
+
+	t.Run("ReturnsNameForEventOfWebhook", func(t *testing.T) {
+
+		webhook := Webhook{Name: "vendor"}
+
+		// act
+		name, ok := GetWebhookEventName(webhook.GetEvent())
+
+		assert.True(t, ok)
+		assert.Equal(t, "vendor", name)
+	})
+
+	t.Run("ReturnsFalseForManualEventOfUser", func(t *testing.T) {
+
+		event := manifest.EstafetteEvent{Manual: &manifest.EstafetteManualEvent{UserID: "me@estafette.io"}}
+
+		// act
+		_, ok := GetWebhookEventName(event)
+
+		assert.False(t, ok)
+	})
+}
@@ -23,7 +23,11 @@ type Client interface {
 	GetAccessToken(ctx context.Context) (accesstoken AccessToken, err error)
 	GetAuthenticatedRepositoryURL(ctx context.Context, accesstoken AccessToken, htmlURL string) (url string, err error)
 	GetEstafetteManifest(ctx context.Context, accesstoken AccessToken, event RepositoryPushEvent) (valid bool, manifest string, err error)
+	GetRevision(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error)
+	IsRevisionOnBranch(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error)
 	JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error)
+	ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error)
+	CheckHealth(ctx context.Context) (err error)
 }
 
 // NewClient returns a new bitbucket.Client
@@ -160,6 +164,131 @@ func (c *client) GetEstafetteManifest(ctx context.Context, accesstoken AccessTok
 	return
 }
 
+// GetRevision returns the commit hash for a branch or (abbreviated) revision; it's empty if the ref doesn't exist
+func (c *client) GetRevision(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error) {
+
+	// create client, in order to add headers
+	client := pester.NewExtendedClient(&http.Client{Transport: &nethttp.Transport{}})
+	client.MaxRetries = 3
+	client.Backoff = pester.ExponentialJitterBackoff
+	client.KeepLog = true
+	client.Timeout = time.Second * 10
+	request, err := http.NewRequest("GET", fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%v/commit/%v", repoFullName, ref), nil)
+
+	if err != nil {
+		return
+	}
+
+	span := opentracing.SpanFromContext(ctx)
+	var ht *nethttp.Tracer
+	if span != nil {
+		// add tracing context
+		request = request.WithContext(opentracing.ContextWithSpan(request.Context(), span))
+
+		// collect additional information on setting up connections
+		request, ht = nethttp.TraceRequest(span.Tracer(), request)
+	}
+
+	// add headers
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %v", accesstoken.AccessToken))
+
+	// perform actual request
+	response, err := client.Do(request)
+	if err != nil {
+		return
+	}
+
+	defer response.Body.Close()
+	if ht != nil {
+		ht.Finish()
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+
+	var commit struct {
+		Hash string `json:"hash"`
+	}
+
+	// unmarshal json body
+	err = json.Unmarshal(body, &commit)
+	if err != nil {
+		return
+	}
+
+	return commit.Hash, nil
+}
+
+// IsRevisionOnBranch returns true if the revision is the head of the branch or one of its ancestors, which is the case when it's its own merge base with the branch
+func (c *client) IsRevisionOnBranch(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error) {
+
+	// create client, in order to add headers
+	client := pester.NewExtendedClient(&http.Client{Transport: &nethttp.Transport{}})
+	client.MaxRetries = 3
+	client.Backoff = pester.ExponentialJitterBackoff
+	client.KeepLog = true
+	client.Timeout = time.Second * 10
+	request, err := http.NewRequest("GET", fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%v/merge-base/%v..%v", repoFullName, url.PathEscape(revision), url.PathEscape(branch)), nil)
+
+	if err != nil {
+		return
+	}
+
+	span := opentracing.SpanFromContext(ctx)
+	var ht *nethttp.Tracer
+	if span != nil {
+		// add tracing context
+		request = request.WithContext(opentracing.ContextWithSpan(request.Context(), span))
+
+		// collect additional information on setting up connections
+		request, ht = nethttp.TraceRequest(span.Tracer(), request)
+	}
+
+	// add headers
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %v", accesstoken.AccessToken))
+
+	// perform actual request
+	response, err := client.Do(request)
+	if err != nil {
+		return
+	}
+
+	defer response.Body.Close()
+	if ht != nil {
+		ht.Finish()
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Retrieving merge base of revision %v and branch %v of %v returned status code %v", revision, branch, repoFullName, response.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+
+	var mergeBase struct {
+		Hash string `json:"hash"`
+	}
+
+	// unmarshal json body
+	err = json.Unmarshal(body, &mergeBase)
+	if err != nil {
+		return
+	}
+
+	return mergeBase.Hash != "" && mergeBase.Hash == revision, nil
+}
+
 // JobVarsFunc returns a function that can get an access token and authenticated url for a repository
 func (c *client) JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
 	return func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
@@ -177,3 +306,70 @@ func (c *client) JobVarsFunc(ctx context.Context) func(ctx context.Context, repo
 		return accessToken.AccessToken, url, nil
 	}
 }
+
+// ManifestFunc returns a function that can resolve a branch or revision of a repository and retrieve the manifest at that revision
+func (c *client) ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	return func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+		// get access token
+		accessToken, err := c.GetAccessToken(ctx)
+		if err != nil {
+			return
+		}
+
+		ref := revision
+		if ref == "" {
+			ref = branch
+		}
+
+		repoFullName := fmt.Sprintf("%v/%v", repoOwner, repoName)
+		resolvedRevision, err = c.GetRevision(ctx, accessToken, repoFullName, ref)
+		if err != nil || resolvedRevision == "" {
+			return
+		}
+
+		if revision != "" {
+			onBranch, err := c.IsRevisionOnBranch(ctx, accessToken, repoFullName, branch, resolvedRevision)
+			if err != nil {
+				return "", false, "", err
+			}
+			if !onBranch {
+				return "", false, "", fmt.Errorf("Revision %v of %v is not on branch %v: %w", resolvedRevision, repoFullName, branch, api.ErrRevisionNotOnBranch)
+			}
+		}
+
+		exists, manifest, err = c.GetEstafetteManifest(ctx, accessToken, RepositoryPushEvent{
+			Repository: Repository{
+				FullName: repoFullName,
+			},
+			Push: PushEvent{
+				Changes: []PushEventChange{
+					{
+						New: &PushEventChangeObject{
+							Type: "branch",
+							Name: branch,
+							Target: PushEventChangeObjectTarget{
+								Hash: resolvedRevision,
+							},
+						},
+					},
+				},
+			},
+		})
+
+		return
+	}
+}
+
+// CheckHealth verifies Bitbucket hands out an access token for the app's oauth credentials
+func (c *client) CheckHealth(ctx context.Context) (err error) {
+
+	accessToken, err := c.GetAccessToken(ctx)
+	if err != nil {
+		return
+	}
+	if accessToken.AccessToken == "" {
+		return fmt.Errorf("Bitbucket returned an empty access token")
+	}
+
+	return
+}
@@ -8,7 +8,11 @@ type MockClient struct {
 	GetAccessTokenFunc                func(ctx context.Context) (accesstoken AccessToken, err error)
 	GetAuthenticatedRepositoryURLFunc func(ctx context.Context, accesstoken AccessToken, htmlURL string) (url string, err error)
 	GetEstafetteManifestFunc          func(ctx context.Context, accesstoken AccessToken, event RepositoryPushEvent) (valid bool, manifest string, err error)
+	GetRevisionFunc                   func(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error)
+	IsRevisionOnBranchFunc            func(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error)
 	JobVarsFuncFunc                   func(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error)
+	ManifestFuncFunc                  func(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error)
+	CheckHealthFunc                   func(ctx context.Context) (err error)
 }
 
 func (c MockClient) GetAccessToken(ctx context.Context) (accesstoken AccessToken, err error) {
@@ -32,6 +36,20 @@ func (c MockClient) GetEstafetteManifest(ctx context.Context, accesstoken Access
 	return c.GetEstafetteManifestFunc(ctx, accesstoken, event)
 }
 
+func (c MockClient) GetRevision(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error) {
+	if c.GetRevisionFunc == nil {
+		return
+	}
+	return c.GetRevisionFunc(ctx, accesstoken, repoFullName, ref)
+}
+
+func (c MockClient) IsRevisionOnBranch(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error) {
+	if c.IsRevisionOnBranchFunc == nil {
+		return
+	}
+	return c.IsRevisionOnBranchFunc(ctx, accesstoken, repoFullName, branch, revision)
+}
+
 func (c MockClient) JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
 	if c.JobVarsFuncFunc == nil {
 		return func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
@@ -40,3 +58,19 @@ func (c MockClient) JobVarsFunc(ctx context.Context) func(ctx context.Context, r
 	}
 	return c.JobVarsFuncFunc(ctx)
 }
+
+func (c MockClient) ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	if c.ManifestFuncFunc == nil {
+		return func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+			return
+		}
+	}
+	return c.ManifestFuncFunc(ctx)
+}
+
+func (c MockClient) CheckHealth(ctx context.Context) (err error) {
+	if c.CheckHealthFunc == nil {
+		return
+	}
+	return c.CheckHealthFunc(ctx)
+}
@@ -38,9 +38,37 @@ func (c *tracingClient) GetEstafetteManifest(ctx context.Context, accesstoken Ac
 	return c.Client.GetEstafetteManifest(ctx, accesstoken, event)
 }
 
+func (c *tracingClient) GetRevision(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetRevision"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetRevision(ctx, accesstoken, repoFullName, ref)
+}
+
+func (c *tracingClient) IsRevisionOnBranch(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "IsRevisionOnBranch"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.IsRevisionOnBranch(ctx, accesstoken, repoFullName, branch, revision)
+}
+
 func (c *tracingClient) JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "JobVarsFunc"))
 	defer func() { api.FinishSpan(span) }()
 
 	return c.Client.JobVarsFunc(ctx)
 }
+
+func (c *tracingClient) ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "ManifestFunc"))
+	defer func() { api.FinishSpan(span) }()
+
+	return c.Client.ManifestFunc(ctx)
+}
+
+func (c *tracingClient) CheckHealth(ctx context.Context) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "CheckHealth"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/dockerhubapi"
+	"github.com/estafette/estafette-ci-api/clients/secretstore"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	crypt "github.com/estafette/estafette-ci-crypt"
 	manifest "github.com/estafette/estafette-ci-manifest"
@@ -39,26 +40,29 @@ type Client interface {
 	RemoveCiBuilderSecret(ctx context.Context, secretName string) (err error)
 	TailCiBuilderJobLogs(ctx context.Context, jobName string, logChannel chan contracts.TailLogLine) (err error)
 	GetJobName(ctx context.Context, jobType, repoOwner, repoName, id string) (jobname string)
+	CheckHealth(ctx context.Context) (err error)
 }
 
 // NewClient returns a new estafette.Client
-func NewClient(config *api.APIConfig, encryptedConfig *api.APIConfig, secretHelper crypt.SecretHelper, kubeClientset *kubernetes.Clientset, dockerHubClient dockerhubapi.Client) Client {
+func NewClient(config *api.APIConfig, encryptedConfig *api.APIConfig, secretHelper crypt.SecretHelper, kubeClientset *kubernetes.Clientset, dockerHubClient dockerhubapi.Client, secretStoreClient secretstore.Client) Client {
 
 	return &client{
-		kubeClientset:   kubeClientset,
-		dockerHubClient: dockerHubClient,
-		config:          config,
-		encryptedConfig: encryptedConfig,
-		secretHelper:    secretHelper,
+		kubeClientset:     kubeClientset,
+		dockerHubClient:   dockerHubClient,
+		secretStoreClient: secretStoreClient,
+		config:            config,
+		encryptedConfig:   encryptedConfig,
+		secretHelper:      secretHelper,
 	}
 }
 
 type client struct {
-	kubeClientset   *kubernetes.Clientset
-	dockerHubClient dockerhubapi.Client
-	config          *api.APIConfig
-	encryptedConfig *api.APIConfig
-	secretHelper    crypt.SecretHelper
+	kubeClientset     *kubernetes.Clientset
+	dockerHubClient   dockerhubapi.Client
+	secretStoreClient secretstore.Client
+	config            *api.APIConfig
+	encryptedConfig   *api.APIConfig
+	secretHelper      crypt.SecretHelper
 }
 
 // CreateCiBuilderJob creates an estafette-ci-builder job in Kubernetes to run the estafette build
@@ -77,7 +81,7 @@ func (c *client) CreateCiBuilderJob(ctx context.Context, ciBuilderParams CiBuild
 		return
 	}
 	builderConfigValue := string(builderConfigJSONBytes)
-	builderConfigValue, newKey, err := c.secretHelper.ReencryptAllEnvelopes(builderConfigValue, ciBuilderParams.GetFullRepoPath(), false)
+	builderConfigValue, newKey, err := api.ReencryptScopedEnvelopes(c.secretHelper, builderConfigValue, ciBuilderParams.GetFullRepoPath(), ciBuilderParams.GetSecretScopeContext())
 	if err != nil {
 		return
 	}
@@ -561,6 +565,15 @@ func (c *client) getBuilderConfig(ctx context.Context, ciBuilderParams CiBuilder
 	// add container-registry credentials to allow private registry images to be used in stages
 	credentials = contracts.AddCredentialsIfNotPresent(credentials, contracts.FilterCredentialsByPipelinesWhitelist(contracts.GetCredentialsByType(c.encryptedConfig.Credentials, "container-registry"), ciBuilderParams.GetFullRepoPath()))
 
+	// fetch secrets referenced from external secret stores, so rotated values are used by the next job
+	if c.secretStoreClient != nil {
+		var err error
+		credentials, err = c.secretStoreClient.ResolveCredentials(ctx, credentials)
+		if err != nil {
+			return contracts.BuilderConfig{}, err
+		}
+	}
+
 	localBuilderConfig := contracts.BuilderConfig{
 		Credentials:     credentials,
 		TrustedImages:   trustedImages,
@@ -953,3 +966,9 @@ func (c *client) getCiBuilderJobName(ctx context.Context, ciBuilderParams CiBuil
 
 	return c.GetJobName(ctx, ciBuilderParams.JobType, ciBuilderParams.RepoOwner, ciBuilderParams.RepoName, id)
 }
+
+// CheckHealth verifies the Kubernetes api is reachable and the service account can access the namespace jobs are created in
+func (c *client) CheckHealth(ctx context.Context) (err error) {
+	_, err = c.kubeClientset.BatchV1().Jobs(c.config.Jobs.Namespace).List(metav1.ListOptions{Limit: 1})
+	return
+}
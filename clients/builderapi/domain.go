@@ -3,6 +3,7 @@ package builderapi
 import (
 	"fmt"
 
+	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	manifest "github.com/estafette/estafette-ci-manifest"
@@ -31,6 +32,19 @@ type CiBuilderParams struct {
 	BuildID            int
 	TriggeredByEvents  []manifest.EstafetteEvent
 	JobResources       cockroachdb.JobResources
+	Organizations      []*contracts.Organization
+	Groups             []*contracts.Group
+}
+
+// GetSecretScopeContext returns what scoped secrets are checked against when creating the job
+func (cbp *CiBuilderParams) GetSecretScopeContext() api.SecretScopeContext {
+
+	releaseTarget := ""
+	if cbp.JobType == "release" {
+		releaseTarget = cbp.ReleaseName
+	}
+
+	return api.NewSecretScopeContext(cbp.Organizations, cbp.Groups, cbp.RepoBranch, releaseTarget)
 }
 
 // GetFullRepoPath returns the full path of the pipeline / build / release repository with source, owner and name
@@ -57,3 +57,9 @@ func (c *loggingClient) TailCiBuilderJobLogs(ctx context.Context, jobName string
 func (c *loggingClient) GetJobName(ctx context.Context, jobType, repoOwner, repoName, id string) string {
 	return c.Client.GetJobName(ctx, jobType, repoOwner, repoName, id)
 }
+
+func (c *loggingClient) CheckHealth(ctx context.Context) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "CheckHealth", err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
@@ -74,3 +74,11 @@ func (c *metricsClient) GetJobName(ctx context.Context, jobType, repoOwner, repo
 
 	return c.Client.GetJobName(ctx, jobType, repoOwner, repoName, id)
 }
+
+func (c *metricsClient) CheckHealth(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "CheckHealth", begin)
+	}(time.Now())
+
+	return c.Client.CheckHealth(ctx)
+}
@@ -15,6 +15,7 @@ type MockClient struct {
 	RemoveCiBuilderSecretFunc    func(ctx context.Context, secretName string) (err error)
 	TailCiBuilderJobLogsFunc     func(ctx context.Context, jobName string, logChannel chan contracts.TailLogLine) (err error)
 	GetJobNameFunc               func(ctx context.Context, jobType, repoOwner, repoName, id string) (jobname string)
+	CheckHealthFunc              func(ctx context.Context) (err error)
 }
 
 func (c MockClient) CreateCiBuilderJob(ctx context.Context, params CiBuilderParams) (job *batchv1.Job, err error) {
@@ -65,3 +66,10 @@ func (c MockClient) GetJobName(ctx context.Context, jobType, repoOwner, repoName
 	}
 	return c.GetJobNameFunc(ctx, jobType, repoOwner, repoName, id)
 }
+
+func (c MockClient) CheckHealth(ctx context.Context) (err error) {
+	if c.CheckHealthFunc == nil {
+		return
+	}
+	return c.CheckHealthFunc(ctx)
+}
@@ -67,3 +67,10 @@ func (c *tracingClient) GetJobName(ctx context.Context, jobType, repoOwner, repo
 
 	return c.Client.GetJobName(ctx, jobType, repoOwner, repoName, id)
 }
+
+func (c *tracingClient) CheckHealth(ctx context.Context) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "CheckHealth"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
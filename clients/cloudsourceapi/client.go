@@ -2,6 +2,7 @@ package cloudsourceapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -12,6 +13,9 @@ import (
 	sourcerepo "google.golang.org/api/sourcerepo/v1"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+	"gopkg.in/src-d/go-git.v4/storage/memory"
 )
 
 // Client is the interface for communicating with the Google Cloud Source Repository api
@@ -19,7 +23,9 @@ type Client interface {
 	GetAccessToken(ctx context.Context) (accesstoken AccessToken, err error)
 	GetAuthenticatedRepositoryURL(ctx context.Context, accesstoken AccessToken, htmlURL string) (url string, err error)
 	GetEstafetteManifest(ctx context.Context, accesstoken AccessToken, notification PubSubNotification, gitClone func(string, string, string) error) (valid bool, manifest string, err error)
+	GetEstafetteManifestAtRevision(ctx context.Context, accesstoken AccessToken, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error)
 	JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error)
+	ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error)
 }
 
 // NewClient creates an cloudsource.Client to communicate with the Google Cloud Source Repository api
@@ -109,6 +115,75 @@ func (c *client) GetEstafetteManifest(ctx context.Context, accesstoken AccessTok
 	return
 }
 
+// GetEstafetteManifestAtRevision clones a branch in memory and reads the manifest at its head or at a (abbreviated) revision in its most recent history; the resolved revision is empty if the branch or revision doesn't exist
+func (c *client) GetEstafetteManifestAtRevision(ctx context.Context, accesstoken AccessToken, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+
+	gitUrl := fmt.Sprintf("https://estafette:%v@%v/p/%v/r/%v", accesstoken.AccessToken, repoSource, repoOwner, repoName)
+
+	repository, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:           gitUrl,
+		ReferenceName: plumbing.NewBranchReferenceName(branch),
+		SingleBranch:  true,
+		Depth:         50,
+	})
+	if err != nil {
+		// go-git doesn't return a typed error for a missing branch
+		if strings.HasPrefix(err.Error(), "couldn't find remote ref") {
+			return "", false, "", nil
+		}
+		return
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return
+	}
+
+	commit, err := repository.CommitObject(head.Hash())
+	if err != nil {
+		return
+	}
+
+	if revision != "" && !strings.HasPrefix(commit.Hash.String(), revision) {
+		commit = nil
+
+		commits, err := repository.Log(&git.LogOptions{From: head.Hash()})
+		if err != nil {
+			return "", false, "", err
+		}
+		err = commits.ForEach(func(cmt *object.Commit) error {
+			if strings.HasPrefix(cmt.Hash.String(), revision) {
+				commit = cmt
+				return storer.ErrStop
+			}
+			return nil
+		})
+		if err != nil {
+			return "", false, "", err
+		}
+		if commit == nil {
+			return "", false, "", nil
+		}
+	}
+
+	resolvedRevision = commit.Hash.String()
+
+	file, err := commit.File(".estafette.yaml")
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return resolvedRevision, false, "", nil
+		}
+		return
+	}
+
+	manifest, err = file.Contents()
+	if err != nil {
+		return
+	}
+
+	return resolvedRevision, true, manifest, nil
+}
+
 // JobVarsFunc returns a function that can get an access token and authenticated url for a repository
 func (c *client) JobVarsFunc(ctx context.Context) func(context.Context, string, string, string) (string, string, error) {
 	return func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
@@ -129,6 +204,19 @@ func (c *client) JobVarsFunc(ctx context.Context) func(context.Context, string,
 	}
 }
 
+// ManifestFunc returns a function that can resolve a branch or revision of a repository and retrieve the manifest at that revision
+func (c *client) ManifestFunc(ctx context.Context) func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+	return func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+		// get access token
+		accesstoken, err := c.GetAccessToken(ctx)
+		if err != nil {
+			return
+		}
+
+		return c.GetEstafetteManifestAtRevision(ctx, accesstoken, repoSource, repoOwner, repoName, branch, revision)
+	}
+}
+
 func (c *client) gitClone(dir, gitUrl, repoRefName string) error {
 	// Clones the repository into the given dir, just as a normal git clone does
 	_, err := git.PlainClone(dir, false, &git.CloneOptions{
@@ -34,6 +34,16 @@ func (c *loggingClient) GetEstafetteManifest(ctx context.Context, accesstoken Ac
 	return c.Client.GetEstafetteManifest(ctx, accesstoken, notification, gitClone)
 }
 
+func (c *loggingClient) GetEstafetteManifestAtRevision(ctx context.Context, accesstoken AccessToken, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetEstafetteManifestAtRevision", err) }()
+
+	return c.Client.GetEstafetteManifestAtRevision(ctx, accesstoken, repoSource, repoOwner, repoName, branch, revision)
+}
+
 func (c *loggingClient) JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
 	return c.Client.JobVarsFunc(ctx)
 }
+
+func (c *loggingClient) ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	return c.Client.ManifestFunc(ctx)
+}
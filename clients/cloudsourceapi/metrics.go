@@ -43,8 +43,22 @@ func (c *metricsClient) GetEstafetteManifest(ctx context.Context, accesstoken Ac
 	return c.Client.GetEstafetteManifest(ctx, accesstoken, notification, gitClone)
 }
 
+func (c *metricsClient) GetEstafetteManifestAtRevision(ctx context.Context, accesstoken AccessToken, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetEstafetteManifestAtRevision", begin)
+	}(time.Now())
+
+	return c.Client.GetEstafetteManifestAtRevision(ctx, accesstoken, repoSource, repoOwner, repoName, branch, revision)
+}
+
 func (c *metricsClient) JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
 	defer func(begin time.Time) { api.UpdateMetrics(c.requestCount, c.requestLatency, "JobVarsFunc", begin) }(time.Now())
 
 	return c.Client.JobVarsFunc(ctx)
 }
+
+func (c *metricsClient) ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	defer func(begin time.Time) { api.UpdateMetrics(c.requestCount, c.requestLatency, "ManifestFunc", begin) }(time.Now())
+
+	return c.Client.ManifestFunc(ctx)
+}
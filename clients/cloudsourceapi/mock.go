@@ -5,10 +5,12 @@ import (
 )
 
 type MockClient struct {
-	GetAccessTokenFunc                func(ctx context.Context) (accesstoken AccessToken, err error)
-	GetAuthenticatedRepositoryURLFunc func(ctx context.Context, accesstoken AccessToken, htmlURL string) (url string, err error)
-	GetEstafetteManifestFunc          func(ctx context.Context, accesstoken AccessToken, notification PubSubNotification, gitClone func(string, string, string) error) (valid bool, manifest string, err error)
-	JobVarsFuncFunc                   func(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error)
+	GetAccessTokenFunc                 func(ctx context.Context) (accesstoken AccessToken, err error)
+	GetAuthenticatedRepositoryURLFunc  func(ctx context.Context, accesstoken AccessToken, htmlURL string) (url string, err error)
+	GetEstafetteManifestFunc           func(ctx context.Context, accesstoken AccessToken, notification PubSubNotification, gitClone func(string, string, string) error) (valid bool, manifest string, err error)
+	GetEstafetteManifestAtRevisionFunc func(ctx context.Context, accesstoken AccessToken, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error)
+	JobVarsFuncFunc                    func(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error)
+	ManifestFuncFunc                   func(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error)
 }
 
 func (c MockClient) GetAccessToken(ctx context.Context) (accesstoken AccessToken, err error) {
@@ -32,6 +34,13 @@ func (c MockClient) GetEstafetteManifest(ctx context.Context, accesstoken Access
 	return c.GetEstafetteManifestFunc(ctx, accesstoken, notification, gitClone)
 }
 
+func (c MockClient) GetEstafetteManifestAtRevision(ctx context.Context, accesstoken AccessToken, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	if c.GetEstafetteManifestAtRevisionFunc == nil {
+		return
+	}
+	return c.GetEstafetteManifestAtRevisionFunc(ctx, accesstoken, repoSource, repoOwner, repoName, branch, revision)
+}
+
 func (c MockClient) JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
 	if c.JobVarsFuncFunc == nil {
 		return func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
@@ -40,3 +49,12 @@ func (c MockClient) JobVarsFunc(ctx context.Context) func(ctx context.Context, r
 	}
 	return c.JobVarsFuncFunc(ctx)
 }
+
+func (c MockClient) ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	if c.ManifestFuncFunc == nil {
+		return func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+			return
+		}
+	}
+	return c.ManifestFuncFunc(ctx)
+}
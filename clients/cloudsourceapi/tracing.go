@@ -38,9 +38,23 @@ func (c *tracingClient) GetEstafetteManifest(ctx context.Context, accesstoken Ac
 	return c.Client.GetEstafetteManifest(ctx, accesstoken, notification, gitClone)
 }
 
+func (c *tracingClient) GetEstafetteManifestAtRevision(ctx context.Context, accesstoken AccessToken, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetEstafetteManifestAtRevision"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetEstafetteManifestAtRevision(ctx, accesstoken, repoSource, repoOwner, repoName, branch, revision)
+}
+
 func (c *tracingClient) JobVarsFunc(ctx context.Context) func(context.Context, string, string, string) (string, string, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "JobVarsFunc"))
 	defer func() { api.FinishSpan(span) }()
 
 	return c.Client.JobVarsFunc(ctx)
 }
+
+func (c *tracingClient) ManifestFunc(ctx context.Context) func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "ManifestFunc"))
+	defer func() { api.FinishSpan(span) }()
+
+	return c.Client.ManifestFunc(ctx)
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"path"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/storage"
 	"github.com/estafette/estafette-ci-api/api"
@@ -31,6 +32,8 @@ type Client interface {
 	GetPipelineBuildLogs(ctx context.Context, buildLog contracts.BuildLog, acceptGzipEncoding bool, responseWriter http.ResponseWriter) (err error)
 	GetPipelineReleaseLogs(ctx context.Context, releaseLog contracts.ReleaseLog, acceptGzipEncoding bool, responseWriter http.ResponseWriter) (err error)
 	Rename(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error)
+	InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (err error)
+	CheckHealth(ctx context.Context) (err error)
 }
 
 // NewClient returns new cloudstorage.Client
@@ -223,6 +226,44 @@ func (c *client) Rename(ctx context.Context, fromRepoSource, fromRepoOwner, from
 	return nil
 }
 
+func (c *client) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (err error) {
+
+	bucket := c.client.Bucket(c.config.Integrations.CloudStorage.Bucket)
+
+	// marshal json
+	jsonBytes, err := json.Marshal(auditEvent)
+	if err != nil {
+		return err
+	}
+
+	// store events per day, so they're easy to export or clean up with a lifecycle rule
+	auditDirectory := "audit"
+	if c.config.Audit != nil && c.config.Audit.CloudStorageDirectory != "" {
+		auditDirectory = c.config.Audit.CloudStorageDirectory
+	}
+	insertedAt := time.Now().UTC()
+	if auditEvent.InsertedAt != nil {
+		insertedAt = *auditEvent.InsertedAt
+	}
+	auditEventPath := path.Join(auditDirectory, insertedAt.Format("2006/01/02"), fmt.Sprintf("%v.json", auditEvent.ID))
+
+	return foundation.Retry(func() error {
+		writer := bucket.Object(auditEventPath).NewWriter(ctx)
+		if writer == nil {
+			return fmt.Errorf("Writer for audit event object %v is nil", auditEventPath)
+		}
+		writer.ContentType = "application/json"
+
+		_, err := writer.Write(jsonBytes)
+		if err != nil {
+			_ = writer.Close()
+			return err
+		}
+
+		return writer.Close()
+	})
+}
+
 func (c *client) renameFilesInDirectory(ctx context.Context, bucket *storage.BucketHandle, fromLogFileDirectory, toLogFileDirectory string) (err error) {
 
 	query := &storage.Query{
@@ -266,3 +307,9 @@ func (c *client) renameFile(ctx context.Context, bucket *storage.BucketHandle, f
 
 	return nil
 }
+
+// CheckHealth verifies the logs bucket can be accessed
+func (c *client) CheckHealth(ctx context.Context) (err error) {
+	_, err = c.client.Bucket(c.config.Integrations.CloudStorage.Bucket).Attrs(ctx)
+	return
+}
@@ -47,3 +47,15 @@ func (c *loggingClient) Rename(ctx context.Context, fromRepoSource, fromRepoOwne
 
 	return c.Client.Rename(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
 }
+
+func (c *loggingClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "InsertAuditEvent", err) }()
+
+	return c.Client.InsertAuditEvent(ctx, auditEvent)
+}
+
+func (c *loggingClient) CheckHealth(ctx context.Context) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "CheckHealth", err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
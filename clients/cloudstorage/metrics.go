@@ -60,3 +60,19 @@ func (c *metricsClient) Rename(ctx context.Context, fromRepoSource, fromRepoOwne
 
 	return c.Client.Rename(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
 }
+
+func (c *metricsClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertAuditEvent", begin)
+	}(time.Now())
+
+	return c.Client.InsertAuditEvent(ctx, auditEvent)
+}
+
+func (c *metricsClient) CheckHealth(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "CheckHealth", begin)
+	}(time.Now())
+
+	return c.Client.CheckHealth(ctx)
+}
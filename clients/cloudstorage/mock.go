@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/estafette/estafette-ci-api/api"
 	contracts "github.com/estafette/estafette-ci-contracts"
 )
 
@@ -13,6 +14,8 @@ type MockClient struct {
 	GetPipelineBuildLogsFunc   func(ctx context.Context, buildLog contracts.BuildLog, acceptGzipEncoding bool, responseWriter http.ResponseWriter) (err error)
 	GetPipelineReleaseLogsFunc func(ctx context.Context, releaseLog contracts.ReleaseLog, acceptGzipEncoding bool, responseWriter http.ResponseWriter) (err error)
 	RenameFunc                 func(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error)
+	InsertAuditEventFunc       func(ctx context.Context, auditEvent api.AuditEvent) (err error)
+	CheckHealthFunc            func(ctx context.Context) (err error)
 }
 
 func (c MockClient) InsertBuildLog(ctx context.Context, buildLog contracts.BuildLog) (err error) {
@@ -49,3 +52,17 @@ func (c MockClient) Rename(ctx context.Context, fromRepoSource, fromRepoOwner, f
 	}
 	return c.RenameFunc(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
 }
+
+func (c MockClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (err error) {
+	if c.InsertAuditEventFunc == nil {
+		return
+	}
+	return c.InsertAuditEventFunc(ctx, auditEvent)
+}
+
+func (c MockClient) CheckHealth(ctx context.Context) (err error) {
+	if c.CheckHealthFunc == nil {
+		return
+	}
+	return c.CheckHealthFunc(ctx)
+}
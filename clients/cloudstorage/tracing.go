@@ -53,3 +53,17 @@ func (c *tracingClient) Rename(ctx context.Context, fromRepoSource, fromRepoOwne
 
 	return c.Client.Rename(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
 }
+
+func (c *tracingClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertAuditEvent"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.InsertAuditEvent(ctx, auditEvent)
+}
+
+func (c *tracingClient) CheckHealth(ctx context.Context) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "CheckHealth"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
@@ -37,6 +37,15 @@ var (
 
 	// ErrCatalogEntityNotFound is returned if a query for a catalog entity returns no results
 	ErrCatalogEntityNotFound = errors.New("The catalog entity can't be found")
+
+	// ErrPersonalAccessTokenNotFound is returned if a query for a personal access token returns no results
+	ErrPersonalAccessTokenNotFound = errors.New("The personal access token can't be found")
+
+	// ErrCustomRoleNotFound is returned if a query for a custom role returns no results
+	ErrCustomRoleNotFound = errors.New("The custom role can't be found")
+
+	// ErrWebhookNotFound is returned if a query for a webhook returns no results
+	ErrWebhookNotFound = errors.New("The webhook can't be found")
 )
 
 // Client is the interface for communicating with CockroachDB
@@ -48,6 +57,8 @@ type Client interface {
 	InsertBuild(ctx context.Context, build contracts.Build, jobResources JobResources) (b *contracts.Build, err error)
 	UpdateBuildStatus(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error)
 	UpdateBuildResourceUtilization(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, jobResources JobResources) (err error)
+	UpdateBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, parameters map[string]string) (err error)
+	GetBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int) (parameters map[string]string, err error)
 	InsertRelease(ctx context.Context, release contracts.Release, jobResources JobResources) (r *contracts.Release, err error)
 	UpdateReleaseStatus(ctx context.Context, repoSource, repoOwner, repoName string, id int, releaseStatus string) (err error)
 	UpdateReleaseResourceUtilization(ctx context.Context, repoSource, repoOwner, repoName string, id int, jobResources JobResources) (err error)
@@ -107,6 +118,13 @@ type Client interface {
 	GetPipelinesWithMostBuildsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
 	GetPipelinesWithMostReleases(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string) (pipelines []map[string]interface{}, err error)
 	GetPipelinesWithMostReleasesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+	GetDoraDeployments(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error)
+	GetBuildAttempts(ctx context.Context, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error)
+	GetPipelineBuildAttempts(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error)
+	GetBuildStageStatuses(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error)
+	GetStageRuns(ctx context.Context, filters map[api.FilterType][]string) (runs []*api.StageRun, err error)
+	GetPipelineStageRuns(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (runs []*api.StageRun, err error)
+	GetJobUsages(ctx context.Context, filters map[api.FilterType][]string) (usages []*api.JobUsage, err error)
 
 	GetTriggers(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error)
 	GetGitTriggers(ctx context.Context, gitEvent manifest.EstafetteGitEvent) (pipelines []*contracts.Pipeline, err error)
@@ -114,6 +132,7 @@ type Client interface {
 	GetReleaseTriggers(ctx context.Context, release contracts.Release, event string) (pipelines []*contracts.Pipeline, err error)
 	GetPubSubTriggers(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (pipelines []*contracts.Pipeline, err error)
 	GetCronTriggers(ctx context.Context) (pipelines []*contracts.Pipeline, err error)
+	GetDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error)
 
 	Rename(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) (err error)
 	RenameBuildVersion(ctx context.Context, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName string) (err error)
@@ -157,6 +176,36 @@ type Client interface {
 	GetClients(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (clients []*contracts.Client, err error)
 	GetClientsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
 
+	InsertPersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error)
+	UpdatePersonalAccessTokenLastUsed(ctx context.Context, id, lastUsedFrom string) (err error)
+	RevokePersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (err error)
+	GetPersonalAccessTokenByID(ctx context.Context, id string) (token *api.PersonalAccessToken, err error)
+	GetPersonalAccessTokens(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error)
+	GetPersonalAccessTokensCount(ctx context.Context, userID string, filters map[api.FilterType][]string) (count int, err error)
+
+	InsertCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error)
+	UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error)
+	DeleteCustomRole(ctx context.Context, customRole api.CustomRole) (err error)
+	GetCustomRoleByID(ctx context.Context, id string) (customRole *api.CustomRole, err error)
+	GetCustomRolesByNames(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error)
+	GetCustomRoles(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (customRoles []*api.CustomRole, err error)
+	GetCustomRolesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+
+	InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error)
+	GetAuditEvents(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (auditEvents []*api.AuditEvent, err error)
+	GetAuditEventsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+
+	InsertPipelineWarning(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error)
+	GetPipelineWarnings(ctx context.Context, repoSource, repoOwner, repoName string, since time.Time) (warnings []contracts.Warning, err error)
+
+	InsertWebhook(ctx context.Context, webhook api.Webhook) (insertedWebhook *api.Webhook, err error)
+	DeleteWebhook(ctx context.Context, webhook api.Webhook) (err error)
+	GetWebhookByID(ctx context.Context, id string) (webhook *api.Webhook, err error)
+	GetPipelineWebhooks(ctx context.Context, repoSource, repoOwner, repoName string) (webhooks []*api.Webhook, err error)
+	InsertWebhookDelivery(ctx context.Context, delivery api.WebhookDelivery) (insertedDelivery *api.WebhookDelivery, err error)
+	GetPipelineWebhookDeliveries(ctx context.Context, repoSource, repoOwner, repoName string, pageNumber, pageSize int) (deliveries []*api.WebhookDelivery, err error)
+	GetPipelineWebhookDeliveriesCount(ctx context.Context, repoSource, repoOwner, repoName string) (count int, err error)
+
 	InsertCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error)
 	UpdateCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (err error)
 	DeleteCatalogEntity(ctx context.Context, id string) (err error)
@@ -174,6 +223,7 @@ type Client interface {
 	GetCatalogEntityValuesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
 	GetCatalogEntityLabels(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string) (labels []map[string]interface{}, err error)
 	GetCatalogEntityLabelsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+	CheckHealth(ctx context.Context) (err error)
 }
 
 // NewClient returns a new cockroach.Client
@@ -476,6 +526,66 @@ func (c *client) UpdateBuildResourceUtilization(ctx context.Context, repoSource,
 	return
 }
 
+// UpdateBuildParameters stores the parameters a build was dispatched or triggered with, so they can be audited afterwards
+func (c *client) UpdateBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, parameters map[string]string) (err error) {
+
+	parametersBytes, err := json.Marshal(parameters)
+	if err != nil {
+		return
+	}
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("builds").
+		Set("parameters", parametersBytes).
+		Where(sq.Eq{"id": buildID}).
+		Where(sq.Eq{"repo_source": repoSource}).
+		Where(sq.Eq{"repo_owner": repoOwner}).
+		Where(sq.Eq{"repo_name": repoName})
+
+	// update build parameters
+	_, err = query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetBuildParameters returns the parameters a build was dispatched or triggered with, or an empty map if it had none
+func (c *client) GetBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int) (parameters map[string]string, err error) {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Select("parameters").
+		From("builds").
+		Where(sq.Eq{"id": buildID}).
+		Where(sq.Eq{"repo_source": repoSource}).
+		Where(sq.Eq{"repo_owner": repoOwner}).
+		Where(sq.Eq{"repo_name": repoName}).
+		Limit(uint64(1))
+
+	var parametersData []uint8
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	if err = row.Scan(&parametersData); err != nil {
+		if err == sql.ErrNoRows {
+			return map[string]string{}, nil
+		}
+		return
+	}
+
+	parameters = map[string]string{}
+	if len(parametersData) > 0 {
+		if err = json.Unmarshal(parametersData, &parameters); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
 func (c *client) InsertRelease(ctx context.Context, release contracts.Release, jobResources JobResources) (insertedRelease *contracts.Release, err error) {
 
 	eventsBytes, err := json.Marshal(release.Events)
@@ -917,6 +1027,10 @@ func (c *client) UpsertComputedPipeline(ctx context.Context, repoSource, repoOwn
 		// extract recent releasers from last releases
 		for _, r := range lastReleases {
 			for _, e := range r.Events {
+				// releases started by a webhook aren't released by a person
+				if _, isWebhookEvent := api.GetWebhookEventName(e); isWebhookEvent {
+					continue
+				}
 				if e.Manual != nil && e.Manual.UserID != "" && !foundation.StringArrayContains(upsertedPipeline.RecentReleasers, e.Manual.UserID) {
 					upsertedPipeline.RecentReleasers = append(upsertedPipeline.RecentReleasers, e.Manual.UserID)
 				}
@@ -1003,6 +1117,17 @@ func (c *client) UpsertComputedPipeline(ctx context.Context, repoSource, repoOwn
 		return
 	}
 
+	// store the images used by the stages, to look up the pipelines to trigger when one of them is pushed
+	containerImages := []string{}
+	if mft, mftErr := manifest.ReadManifest(c.config.ManifestPreferences, upsertedPipeline.Manifest, false); mftErr == nil {
+		containerImages = api.GetManifestImageNames(mft)
+	}
+	containerImagesBytes, err := json.Marshal(containerImages)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed upserting computed pipeline %v/%v/%v", upsertedPipeline.RepoSource, upsertedPipeline.RepoOwner, upsertedPipeline.RepoName)
+		return
+	}
+
 	// upsert computed pipeline
 	_, err = c.databaseConnection.Exec(
 		`
@@ -1033,7 +1158,8 @@ func (c *client) UpsertComputedPipeline(ctx context.Context, repoSource, repoOwn
 			recent_releasers,
 			extra_info,
 			organizations,
-			groups
+			groups,
+			container_images
 		)
 		VALUES
 		(
@@ -1061,7 +1187,8 @@ func (c *client) UpsertComputedPipeline(ctx context.Context, repoSource, repoOwn
 			$22,
 			$23,
 			$24,
-			$25
+			$25,
+			$26
 		)
 		ON CONFLICT
 		(
@@ -1088,7 +1215,8 @@ func (c *client) UpsertComputedPipeline(ctx context.Context, repoSource, repoOwn
 			triggered_by_event = excluded.triggered_by_event,
 			recent_committers = excluded.recent_committers,
 			recent_releasers = excluded.recent_releasers,
-			extra_info = excluded.extra_info
+			extra_info = excluded.extra_info,
+			container_images = excluded.container_images
 		`,
 		upsertedPipeline.ID,
 		upsertedPipeline.RepoSource,
@@ -1115,6 +1243,7 @@ func (c *client) UpsertComputedPipeline(ctx context.Context, repoSource, repoOwn
 		extraInfoBytes,
 		organizationsBytes,
 		groupsBytes,
+		containerImagesBytes,
 	)
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed upserting computed pipeline %v/%v/%v", repoSource, repoOwner, repoName)
@@ -1446,12 +1575,10 @@ func (c *client) UnarchiveComputedPipeline(ctx context.Context, repoSource, repo
 func (c *client) GetPipelines(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField, optimized bool) (pipelines []*contracts.Pipeline, err error) {
 
 	// generate query
-	query := c.selectPipelinesQuery().
-		Limit(uint64(pageSize)).
-		Offset(uint64((pageNumber - 1) * pageSize))
+	query := c.selectPipelinesQuery()
 
-	// dynamically set order by clause
-	query, err = orderByClauseGeneratorForSortings(query, "a", "a.repo_source,a.repo_owner,a.repo_name", sortings)
+	// dynamically set order by and paging clauses
+	query, err = pagingClauseGenerator(query, "a", "a.repo_source,a.repo_owner,a.repo_name", []string{"repo_source", "repo_owner", "repo_name"}, "ASC", pageNumber, pageSize, sortings, filters)
 	if err != nil {
 
 		return
@@ -1595,12 +1722,10 @@ func (c *client) GetPipelineBuilds(ctx context.Context, repoSource, repoOwner, r
 	query := c.selectBuildsQuery().
 		Where(sq.Eq{"a.repo_source": repoSource}).
 		Where(sq.Eq{"a.repo_owner": repoOwner}).
-		Where(sq.Eq{"a.repo_name": repoName}).
-		Limit(uint64(pageSize)).
-		Offset(uint64((pageNumber - 1) * pageSize))
+		Where(sq.Eq{"a.repo_name": repoName})
 
-	// dynamically set order by clause
-	query, err = orderByClauseGeneratorForSortings(query, "a", "a.inserted_at DESC", sortings)
+	// dynamically set order by and paging clauses
+	query, err = pagingClauseGenerator(query, "a", "a.inserted_at DESC", []string{"inserted_at", "id"}, "DESC", pageNumber, pageSize, sortings, filters)
 	if err != nil {
 
 		return
@@ -2003,12 +2128,10 @@ func (c *client) GetPipelineReleases(ctx context.Context, repoSource, repoOwner,
 	query := c.selectReleasesQuery().
 		Where(sq.Eq{"a.repo_source": repoSource}).
 		Where(sq.Eq{"a.repo_owner": repoOwner}).
-		Where(sq.Eq{"a.repo_name": repoName}).
-		Limit(uint64(pageSize)).
-		Offset(uint64((pageNumber - 1) * pageSize))
+		Where(sq.Eq{"a.repo_name": repoName})
 
-		// dynamically set order by clause
-	query, err = orderByClauseGeneratorForSortings(query, "a", "a.inserted_at DESC", sortings)
+	// dynamically set order by and paging clauses
+	query, err = pagingClauseGenerator(query, "a", "a.inserted_at DESC", []string{"inserted_at", "id"}, "DESC", pageNumber, pageSize, sortings, filters)
 	if err != nil {
 
 		return
@@ -2441,6 +2564,12 @@ func (c *client) GetPipelineBuildsDurations(ctx context.Context, repoSource, rep
 		return
 	}
 
+	innerquery, err = whereClauseGeneratorForTimeRangeFilter(innerquery, "a", "inserted_at", filters)
+	if err != nil {
+
+		return
+	}
+
 	innerquery, err = limitClauseGeneratorForLastFilter(innerquery, filters)
 	if err != nil {
 
@@ -2507,6 +2636,12 @@ func (c *client) GetPipelineReleasesDurations(ctx context.Context, repoSource, r
 		return
 	}
 
+	innerquery, err = whereClauseGeneratorForTimeRangeFilter(innerquery, "a", "inserted_at", filters)
+	if err != nil {
+
+		return
+	}
+
 	innerquery, err = limitClauseGeneratorForLastFilter(innerquery, filters)
 	if err != nil {
 
@@ -2579,6 +2714,12 @@ func (c *client) GetPipelineBuildsCPUUsageMeasurements(ctx context.Context, repo
 		return
 	}
 
+	innerquery, err = whereClauseGeneratorForTimeRangeFilter(innerquery, "a", "inserted_at", filters)
+	if err != nil {
+
+		return
+	}
+
 	innerquery, err = limitClauseGeneratorForLastFilter(innerquery, filters)
 	if err != nil {
 
@@ -2640,6 +2781,12 @@ func (c *client) GetPipelineReleasesCPUUsageMeasurements(ctx context.Context, re
 		return
 	}
 
+	innerquery, err = whereClauseGeneratorForTimeRangeFilter(innerquery, "a", "inserted_at", filters)
+	if err != nil {
+
+		return
+	}
+
 	innerquery, err = limitClauseGeneratorForLastFilter(innerquery, filters)
 	if err != nil {
 
@@ -2704,6 +2851,12 @@ func (c *client) GetPipelineBuildsMemoryUsageMeasurements(ctx context.Context, r
 		return
 	}
 
+	innerquery, err = whereClauseGeneratorForTimeRangeFilter(innerquery, "a", "inserted_at", filters)
+	if err != nil {
+
+		return
+	}
+
 	innerquery, err = limitClauseGeneratorForLastFilter(innerquery, filters)
 	if err != nil {
 
@@ -2765,6 +2918,12 @@ func (c *client) GetPipelineReleasesMemoryUsageMeasurements(ctx context.Context,
 		return
 	}
 
+	innerquery, err = whereClauseGeneratorForTimeRangeFilter(innerquery, "a", "inserted_at", filters)
+	if err != nil {
+
+		return
+	}
+
 	innerquery, err = limitClauseGeneratorForLastFilter(innerquery, filters)
 	if err != nil {
 
@@ -2909,7 +3068,7 @@ func (c *client) GetFrequentLabels(ctx context.Context, pageNumber, pageSize int
 			From("computed_pipelines a").
 			Where("jsonb_typeof(labels) = 'array'")
 
-	arrayElementsQuery, err = whereClauseGeneratorForSinceFilter(arrayElementsQuery, "a", "last_updated_at", filters)
+	arrayElementsQuery, err = whereClauseGeneratorForTimeRangeFilter(arrayElementsQuery, "a", "last_updated_at", filters)
 	if err != nil {
 
 		return
@@ -2998,7 +3157,7 @@ func (c *client) GetFrequentLabelsCount(ctx context.Context, filters map[api.Fil
 			From("computed_pipelines a").
 			Where("jsonb_typeof(labels) = 'array'")
 
-	arrayElementsQuery, err = whereClauseGeneratorForSinceFilter(arrayElementsQuery, "a", "last_updated_at", filters)
+	arrayElementsQuery, err = whereClauseGeneratorForTimeRangeFilter(arrayElementsQuery, "a", "last_updated_at", filters)
 	if err != nil {
 
 		return
@@ -3060,7 +3219,7 @@ func (c *client) GetPipelinesWithMostBuilds(ctx context.Context, pageNumber, pag
 			Limit(uint64(pageSize)).
 			Offset(uint64((pageNumber - 1) * pageSize))
 
-	query, err = whereClauseGeneratorForSinceFilter(query, "a", "inserted_at", filters)
+	query, err = whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", filters)
 	if err != nil {
 
 		return
@@ -3103,7 +3262,7 @@ func (c *client) GetPipelinesWithMostReleases(ctx context.Context, pageNumber, p
 			Limit(uint64(pageSize)).
 			Offset(uint64((pageNumber - 1) * pageSize))
 
-	query, err = whereClauseGeneratorForSinceFilter(query, "a", "inserted_at", filters)
+	query, err = whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", filters)
 	if err != nil {
 
 		return
@@ -3133,7 +3292,7 @@ func (c *client) GetPipelinesWithMostReleasesCount(ctx context.Context, filters
 			From("releases a").
 			GroupBy("a.repo_source, a.repo_owner, a.repo_name")
 
-	innerquery, err = whereClauseGeneratorForSinceFilter(innerquery, "a", "inserted_at", filters)
+	innerquery, err = whereClauseGeneratorForTimeRangeFilter(innerquery, "a", "inserted_at", filters)
 	if err != nil {
 
 		return
@@ -3160,422 +3319,511 @@ func (c *client) GetPipelinesWithMostReleasesCount(ctx context.Context, filters
 	return
 }
 
-func orderByClauseGeneratorForSortings(query sq.SelectBuilder, alias, defaultOrderBy string, sortings []api.OrderField) (sq.SelectBuilder, error) {
-
-	if len(sortings) == 0 {
-		return query.OrderBy(defaultOrderBy), nil
-	}
-
-	for _, s := range sortings {
-		query = query.OrderBy(fmt.Sprintf("%v.%v %v", alias, foundation.ToLowerSnakeCase(s.FieldName), s.Direction))
-	}
-
-	return query, nil
-}
+func (c *client) GetDoraDeployments(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error) {
 
-func whereClauseGeneratorForAllFilters(query sq.SelectBuilder, alias, sinceColumn string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	// generate query; the lead time starts at the first build of the released version, labels come from the pipeline
+	query :=
+		sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Select("a.repo_source, a.repo_owner, a.repo_name, a.release, a.release_version, a.release_status, a.inserted_at, a.updated_at, (SELECT MIN(b.inserted_at) FROM builds b WHERE b.repo_source = a.repo_source AND b.repo_owner = a.repo_owner AND b.repo_name = a.repo_name AND b.build_version = a.release_version), p.labels, a.groups, a.organizations").
+			From("releases a").
+			LeftJoin("computed_pipelines p ON p.repo_source = a.repo_source AND p.repo_owner = a.repo_owner AND p.repo_name = a.repo_name").
+			Where(sq.Eq{"a.release": releaseTargets}).
+			Where(sq.Eq{"a.release_status": []string{"succeeded", "failed"}}).
+			OrderBy("a.updated_at")
 
-	query, err := whereClauseGeneratorForSinceFilter(query, alias, sinceColumn, filters)
-	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForBuildStatusFilter(query, alias, filters)
-	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForLabelsFilter(query, alias, filters)
+	query, err = whereClauseGeneratorForDoraFilters(query, filters)
 	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForSearchFilter(query, alias, filters)
-	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForRecentCommitterFilter(query, alias, filters)
-	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForRecentReleaserFilter(query, alias, filters)
-	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForGroupsFilter(query, alias, filters)
-	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForOrganizationsFilter(query, alias, filters)
-	if err != nil {
-		return query, err
+
+		return
 	}
-	query, err = whereClauseGeneratorForArchivedFilter(query, alias, filters)
+
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
-		return query, err
-	}
 
-	return query, nil
-}
+		return
+	}
 
-func whereClauseGeneratorForAllReleaseFilters(query sq.SelectBuilder, alias, sinceColumn string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	deployments = make([]*api.DoraDeployment, 0)
 
-	query, err := whereClauseGeneratorForReleaseStatusFilter(query, alias, filters)
-	if err != nil {
-		return query, err
-	}
-	query, err = whereClauseGeneratorForSinceFilter(query, alias, sinceColumn, filters)
-	if err != nil {
-		return query, err
-	}
+	defer rows.Close()
+	for rows.Next() {
 
-	return query, nil
-}
+		deployment := api.DoraDeployment{}
+		var labelsData, groupsData, organizationsData []uint8
 
-func whereClauseGeneratorForSinceFilter(query sq.SelectBuilder, alias, sinceColumn string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+		if err = rows.Scan(
+			&deployment.RepoSource,
+			&deployment.RepoOwner,
+			&deployment.RepoName,
+			&deployment.ReleaseTarget,
+			&deployment.ReleaseVersion,
+			&deployment.ReleaseStatus,
+			&deployment.InsertedAt,
+			&deployment.UpdatedAt,
+			&deployment.BuildInsertedAt,
+			&labelsData,
+			&groupsData,
+			&organizationsData); err != nil {
+			return
+		}
 
-	if since, ok := filters[api.FilterSince]; ok && len(since) > 0 && since[0] != "eternity" {
-		sinceValue := since[0]
-		switch sinceValue {
-		case "1h":
-			query = query.Where(sq.GtOrEq{fmt.Sprintf("%v.%v", alias, sinceColumn): time.Now().Add(time.Duration(-1) * time.Hour)})
-		case "1d":
-			query = query.Where(sq.GtOrEq{fmt.Sprintf("%v.%v", alias, sinceColumn): time.Now().AddDate(0, 0, -1)})
-		case "1w":
-			query = query.Where(sq.GtOrEq{fmt.Sprintf("%v.%v", alias, sinceColumn): time.Now().AddDate(0, 0, -7)})
-		case "1m":
-			query = query.Where(sq.GtOrEq{fmt.Sprintf("%v.%v", alias, sinceColumn): time.Now().AddDate(0, -1, 0)})
-		case "1y":
-			query = query.Where(sq.GtOrEq{fmt.Sprintf("%v.%v", alias, sinceColumn): time.Now().AddDate(-1, 0, 0)})
+		if len(labelsData) > 0 {
+			if err = json.Unmarshal(labelsData, &deployment.Labels); err != nil {
+				return
+			}
+		}
+		if len(groupsData) > 0 {
+			if err = json.Unmarshal(groupsData, &deployment.Groups); err != nil {
+				return
+			}
+		}
+		if len(organizationsData) > 0 {
+			if err = json.Unmarshal(organizationsData, &deployment.Organizations); err != nil {
+				return
+			}
 		}
+
+		deployments = append(deployments, &deployment)
 	}
 
-	return query, nil
+	return
 }
 
-func whereClauseGeneratorForSearchFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func (c *client) GetBuildAttempts(ctx context.Context, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	return c.getBuildAttempts(ctx, c.selectBuildAttemptsQuery(), filters)
+}
 
-	if search, ok := filters[api.FilterSearch]; ok && len(search) > 0 && search[0] != "" {
-		searchValue := search[0]
-		query = query.Where(
-			sq.Or{
-				sq.Like{fmt.Sprintf("%v.repo_source", alias): fmt.Sprint("%", searchValue, "%")},
-				sq.Like{fmt.Sprintf("%v.repo_owner", alias): fmt.Sprint("%", searchValue, "%")},
-				sq.Like{fmt.Sprintf("%v.repo_name", alias): fmt.Sprint("%", searchValue, "%")},
-			})
-	}
+func (c *client) GetPipelineBuildAttempts(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
 
-	return query, nil
+	query := c.selectBuildAttemptsQuery().
+		Where(sq.Eq{"a.repo_source": repoSource}).
+		Where(sq.Eq{"a.repo_owner": repoOwner}).
+		Where(sq.Eq{"a.repo_name": repoName})
+
+	return c.getBuildAttempts(ctx, query, filters)
 }
 
-func whereClauseGeneratorForBuildStatusFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func (c *client) getBuildAttempts(ctx context.Context, query sq.SelectBuilder, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
 
-	if statuses, ok := filters[api.FilterStatus]; ok && len(statuses) > 0 && statuses[0] != "all" {
-		query = query.Where(sq.Eq{fmt.Sprintf("%v.build_status", alias): statuses})
+	// dynamically set where clauses for filtering
+	query, err = whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", filters)
+	if err != nil {
+
+		return
 	}
+	query, err = whereClauseGeneratorForLabelsFilter(query, "a", filters)
+	if err != nil {
 
-	return query, nil
-}
+		return
+	}
+	query, err = whereClauseGeneratorForGroupsFilter(query, "a", filters)
+	if err != nil {
 
-func whereClauseGeneratorForReleaseStatusFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+		return
+	}
+	query, err = whereClauseGeneratorForOrganizationsFilter(query, "a", filters)
+	if err != nil {
 
-	if statuses, ok := filters[api.FilterStatus]; ok && len(statuses) > 0 && statuses[0] != "all" {
-		query = query.Where(sq.Eq{fmt.Sprintf("%v.release_status", alias): statuses})
+		return
 	}
 
-	return query, nil
-}
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
+	if err != nil {
 
-func whereClauseGeneratorForLabelsFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+		return
+	}
 
-	if labels, ok := filters[api.FilterLabels]; ok && len(labels) > 0 {
+	attempts = make([]*api.BuildAttempt, 0)
 
-		labelsParam := []contracts.Label{}
+	defer rows.Close()
+	for rows.Next() {
 
-		for _, label := range labels {
-			keyValuePair := strings.Split(label, "=")
+		attempt := api.BuildAttempt{}
+		var id int
 
-			if len(keyValuePair) == 2 {
-				labelsParam = append(labelsParam, contracts.Label{
-					Key:   keyValuePair[0],
-					Value: keyValuePair[1],
-				})
-			}
+		if err = rows.Scan(
+			&id,
+			&attempt.RepoSource,
+			&attempt.RepoOwner,
+			&attempt.RepoName,
+			&attempt.RepoRevision,
+			&attempt.BuildStatus); err != nil {
+			return
 		}
 
-		if len(labelsParam) > 0 {
-			bytes, err := json.Marshal(labelsParam)
-			if err != nil {
-				return query, err
-			}
+		attempt.ID = strconv.Itoa(id)
 
-			query = query.Where(fmt.Sprintf("%v.labels @> ?", alias), string(bytes))
-		}
+		attempts = append(attempts, &attempt)
 	}
 
-	return query, nil
+	return
 }
 
-func whereClauseGeneratorForRecentCommitterFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
-
-	if user, ok := filters[api.FilterRecentCommitter]; ok && len(user) > 0 {
+func (c *client) GetBuildStageStatuses(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error) {
 
-		userParam := []string{user[0]}
+	stageStatuses = map[string]map[string]string{}
+	if len(buildIDs) == 0 {
+		return
+	}
 
-		bytes, err := json.Marshal(userParam)
+	ids := make([]int, 0, len(buildIDs))
+	for _, buildID := range buildIDs {
+		id, err := strconv.Atoi(buildID)
 		if err != nil {
-			return query, err
+			return stageStatuses, err
 		}
-
-		query = query.Where(fmt.Sprintf("%v.recent_committers @> ?", alias), string(bytes))
+		ids = append(ids, id)
 	}
 
-	return query, nil
-}
+	// the last log of a build wins, in case its logs were sent more than once
+	query :=
+		sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Select("a.build_id, a.steps").
+			From("build_logs a").
+			Where(sq.Eq{"a.build_id": ids}).
+			OrderBy("a.inserted_at")
 
-func whereClauseGeneratorForRecentReleaserFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
+	if err != nil {
 
-	if user, ok := filters[api.FilterRecentReleaser]; ok && len(user) > 0 {
+		return
+	}
 
-		userParam := []string{user[0]}
+	defer rows.Close()
+	for rows.Next() {
 
-		bytes, err := json.Marshal(userParam)
-		if err != nil {
-			return query, err
+		var buildID int
+		var stepsData []uint8
+
+		if err = rows.Scan(&buildID, &stepsData); err != nil {
+			return
 		}
 
-		query = query.Where(fmt.Sprintf("%v.recent_releasers @> ?", alias), string(bytes))
+		// logs written to cloud storage only have no steps in the database
+		if len(stepsData) == 0 {
+			continue
+		}
+
+		var steps []*contracts.BuildLogStep
+		if err = json.Unmarshal(stepsData, &steps); err != nil {
+			return
+		}
+		if len(steps) > 0 {
+			stageStatuses[strconv.Itoa(buildID)] = api.GetStageStatuses(steps)
+		}
 	}
 
-	return query, nil
+	return
 }
 
-func whereClauseGeneratorForGroupsFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func (c *client) GetStageRuns(ctx context.Context, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	return c.getStageRuns(ctx, c.selectStageRunsQuery(), filters)
+}
 
-	if groups, ok := filters[api.FilterGroups]; ok && len(groups) > 0 {
+func (c *client) GetPipelineStageRuns(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
 
-		expressions := sq.Or{}
-		for _, g := range groups {
-			groupParam := []*contracts.Group{
-				{
-					Name: g,
-				},
-			}
+	query := c.selectStageRunsQuery().
+		Where(sq.Eq{"a.repo_source": repoSource}).
+		Where(sq.Eq{"a.repo_owner": repoOwner}).
+		Where(sq.Eq{"a.repo_name": repoName})
 
-			bytes, err := json.Marshal(groupParam)
-			if err != nil {
-				return query, err
-			}
+	return c.getStageRuns(ctx, query, filters)
+}
 
-			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.groups @> ?", alias), string(bytes)))
-		}
-		query = query.Where(expressions)
-	}
+func (c *client) getStageRuns(ctx context.Context, query sq.SelectBuilder, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
 
-	return query, nil
-}
+	// logs written to cloud storage only have no steps in the database
+	query = query.
+		Where(sq.NotEq{"a.steps": nil}).
+		OrderBy("a.inserted_at DESC")
 
-func whereClauseGeneratorForOrganizationsFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	// dynamically set where clauses for filtering
+	query, err = whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", filters)
+	if err != nil {
 
-	if organizations, ok := filters[api.FilterOrganizations]; ok && len(organizations) > 0 {
+		return
+	}
 
-		expressions := sq.Or{}
-		for _, o := range organizations {
-			organizationParam := []*contracts.Organization{
-				{
-					Name: o,
-				},
-			}
+	// dynamically set limit for filtering
+	query, err = limitClauseGeneratorForLastFilter(query, filters)
+	if err != nil {
 
-			bytes, err := json.Marshal(organizationParam)
-			if err != nil {
-				return query, err
-			}
+		return
+	}
 
-			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.organizations @> ?", alias), string(bytes)))
-		}
-		query = query.Where(expressions)
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
+	if err != nil {
+
+		return
 	}
 
-	return query, nil
-}
+	runs = make([]*api.StageRun, 0)
 
-func whereClauseGeneratorForOrganizationsInUserDataFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	defer rows.Close()
+	for rows.Next() {
 
-	if organizations, ok := filters[api.FilterOrganizations]; ok && len(organizations) > 0 {
+		buildLog := &contracts.BuildLog{}
+		var stepsData []uint8
+		var rowBuildID sql.NullInt64
 
-		expressions := sq.Or{}
-		for _, o := range organizations {
-			organizationParam := contracts.User{
-				Organizations: []*contracts.Organization{
-					{
-						Name: o,
-					},
-				},
-			}
+		if err = rows.Scan(&buildLog.ID,
+			&buildLog.RepoSource,
+			&buildLog.RepoOwner,
+			&buildLog.RepoName,
+			&buildLog.RepoBranch,
+			&buildLog.RepoRevision,
+			&rowBuildID,
+			&stepsData,
+			&buildLog.InsertedAt); err != nil {
+			return
+		}
 
-			bytes, err := json.Marshal(organizationParam)
-			if err != nil {
-				return query, err
-			}
+		if rowBuildID.Valid {
+			buildLog.BuildID = strconv.FormatInt(rowBuildID.Int64, 10)
+		}
 
-			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.user_data @> ?", alias), string(bytes)))
+		if err = json.Unmarshal(stepsData, &buildLog.Steps); err != nil {
+			return
 		}
-		query = query.Where(expressions)
+
+		runs = append(runs, api.GetStageRuns(buildLog)...)
 	}
 
-	return query, nil
+	return
 }
 
-func whereClauseGeneratorForOrganizationsInGroupDataFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func (c *client) GetJobUsages(ctx context.Context, filters map[api.FilterType][]string) (usages []*api.JobUsage, err error) {
 
-	if organizations, ok := filters[api.FilterOrganizations]; ok && len(organizations) > 0 {
+	// builds carry their own labels
+	buildsQuery :=
+		sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Select("a.repo_source, a.repo_owner, a.repo_name, a.inserted_at, age(a.updated_at, COALESCE(a.started_at,a.inserted_at))::INT, COALESCE(a.cpu_request,0), COALESCE(a.cpu_max_usage,0), COALESCE(a.memory_request,0), COALESCE(a.memory_max_usage,0), a.labels, a.groups, a.organizations").
+			From("builds a").
+			Where(sq.Eq{"a.build_status": []string{"succeeded", "failed", "canceled"}})
 
-		expressions := sq.Or{}
-		for _, o := range organizations {
-			organizationParam := contracts.Group{
-				Organizations: []*contracts.Organization{
-					{
-						Name: o,
-					},
-				},
-			}
+	buildsQuery, err = whereClauseGeneratorForJobUsageFilters(buildsQuery, "a", filters)
+	if err != nil {
+		return
+	}
 
-			bytes, err := json.Marshal(organizationParam)
-			if err != nil {
-				return query, err
-			}
+	buildUsages, err := c.getJobUsages(ctx, api.CostJobTypeBuild, buildsQuery)
+	if err != nil {
+		return
+	}
 
-			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.group_data @> ?", alias), string(bytes)))
-		}
-		query = query.Where(expressions)
+	// releases get their labels from the pipeline
+	releasesQuery :=
+		sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Select("a.repo_source, a.repo_owner, a.repo_name, a.inserted_at, age(a.updated_at, COALESCE(a.started_at,a.inserted_at))::INT, COALESCE(a.cpu_request,0), COALESCE(a.cpu_max_usage,0), COALESCE(a.memory_request,0), COALESCE(a.memory_max_usage,0), p.labels, a.groups, a.organizations").
+			From("releases a").
+			LeftJoin("computed_pipelines p ON p.repo_source = a.repo_source AND p.repo_owner = a.repo_owner AND p.repo_name = a.repo_name").
+			Where(sq.Eq{"a.release_status": []string{"succeeded", "failed", "canceled"}})
+
+	releasesQuery, err = whereClauseGeneratorForJobUsageFilters(releasesQuery, "p", filters)
+	if err != nil {
+		return
 	}
 
-	return query, nil
+	releaseUsages, err := c.getJobUsages(ctx, api.CostJobTypeRelease, releasesQuery)
+	if err != nil {
+		return
+	}
+
+	return append(buildUsages, releaseUsages...), nil
 }
 
-func whereClauseGeneratorForArchivedFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func (c *client) getJobUsages(ctx context.Context, jobType string, query sq.SelectBuilder) (usages []*api.JobUsage, err error) {
 
-	if archiveds, ok := filters[api.FilterArchived]; ok && len(archiveds) > 0 {
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
+	if err != nil {
 
-		hasTrue := false
-		hasFalse := false
-		for _, a := range archiveds {
-			if a == "true" {
-				hasTrue = true
+		return
+	}
+
+	usages = make([]*api.JobUsage, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		usage := api.JobUsage{
+			JobType: jobType,
+		}
+		var durationSeconds int
+		var labelsData, groupsData, organizationsData []uint8
+
+		if err = rows.Scan(
+			&usage.RepoSource,
+			&usage.RepoOwner,
+			&usage.RepoName,
+			&usage.InsertedAt,
+			&durationSeconds,
+			&usage.CPURequest,
+			&usage.CPUMaxUsage,
+			&usage.MemoryRequest,
+			&usage.MemoryMaxUsage,
+			&labelsData,
+			&groupsData,
+			&organizationsData); err != nil {
+			return
+		}
+
+		usage.Duration = time.Duration(durationSeconds) * time.Second
+
+		if len(labelsData) > 0 {
+			if err = json.Unmarshal(labelsData, &usage.Labels); err != nil {
+				return
 			}
-			if a == "false" {
-				hasFalse = true
+		}
+		if len(groupsData) > 0 {
+			if err = json.Unmarshal(groupsData, &usage.Groups); err != nil {
+				return
 			}
 		}
-
-		if hasTrue && !hasFalse {
-			query = query.Where(sq.Eq{"a.archived": true})
-		} else if hasFalse && !hasTrue {
-			query = query.Where(sq.Eq{"a.archived": false})
+		if len(organizationsData) > 0 {
+			if err = json.Unmarshal(organizationsData, &usage.Organizations); err != nil {
+				return
+			}
 		}
+
+		usages = append(usages, &usage)
 	}
 
-	return query, nil
+	return
 }
 
-func whereClauseGeneratorForUserFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
-
-	query, err := whereClauseGeneratorForUserGroupFilters(query, alias, filters)
-	if err != nil {
-		return query, err
-	}
+func orderByClauseGeneratorForSortings(query sq.SelectBuilder, alias, defaultOrderBy string, sortings []api.OrderField) (sq.SelectBuilder, error) {
 
-	query, err = whereClauseGeneratorForUserOrganizationFilters(query, alias, filters)
-	if err != nil {
-		return query, err
+	if len(sortings) == 0 {
+		return query.OrderBy(defaultOrderBy), nil
 	}
 
-	query, err = whereClauseGeneratorForOrganizationsInUserDataFilter(query, "a", filters)
-	if err != nil {
-		return query, err
+	for _, s := range sortings {
+		query = query.OrderBy(fmt.Sprintf("%v.%v %v", alias, foundation.ToLowerSnakeCase(s.FieldName), s.Direction))
 	}
 
 	return query, nil
 }
 
-func whereClauseGeneratorForUserGroupFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
-	if groupIDs, ok := filters[api.FilterGroupID]; ok && len(groupIDs) > 0 {
+// pagingClauseGenerator pages by page number, or by keyset on the key columns if the filters hold a page[after] cursor; keyset paging always orders by the key columns, so custom sorting is ignored
+func pagingClauseGenerator(query sq.SelectBuilder, alias, defaultOrderBy string, keyColumns []string, keyDirection string, pageNumber, pageSize int, sortings []api.OrderField, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-		groupID := groupIDs[0]
+	after, usesCursor := filters[api.FilterAfter]
+	if !usesCursor {
+		query = query.
+			Limit(uint64(pageSize)).
+			Offset(uint64((pageNumber - 1) * pageSize))
 
-		filter := struct {
-			Groups []struct {
-				ID string `json:"id"`
-			} `json:"groups"`
-		}{
-			[]struct {
-				ID string `json:"id"`
-			}{
-				{
-					ID: groupID,
-				},
-			},
-		}
+		return orderByClauseGeneratorForSortings(query, alias, defaultOrderBy, sortings)
+	}
 
-		filterBytes, err := json.Marshal(filter)
-		if err != nil {
-			return query, err
-		}
+	qualifiedKeyColumns := make([]string, len(keyColumns))
+	for i, kc := range keyColumns {
+		qualifiedKeyColumns[i] = fmt.Sprintf("%v.%v", alias, kc)
+		query = query.OrderBy(fmt.Sprintf("%v %v", qualifiedKeyColumns[i], keyDirection))
+	}
+	query = query.Limit(uint64(pageSize))
 
-		query = query.
-			Where(fmt.Sprintf("%v.user_data @> ?", alias), string(filterBytes))
+	if len(after) == 0 || after[0] == "" {
+		return query, nil
 	}
 
-	return query, nil
-}
+	values, err := api.DecodePageCursor(after[0])
+	if err != nil {
+		return query, err
+	}
+	if len(values) != len(keyColumns) {
+		return query, api.ErrInvalidPageCursor
+	}
 
-func whereClauseGeneratorForUserOrganizationFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
-	if organizationIDs, ok := filters[api.FilterOrganizationID]; ok && len(organizationIDs) > 0 {
-
-		organizationID := organizationIDs[0]
+	operator := ">"
+	if keyDirection == "DESC" {
+		operator = "<"
+	}
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	query = query.Where(fmt.Sprintf("(%v) %v (%v)", strings.Join(qualifiedKeyColumns, ","), operator, strings.TrimSuffix(strings.Repeat("?,", len(values)), ",")), args...)
 
-		filter := struct {
-			Organizations []struct {
-				ID string `json:"id"`
-			} `json:"organizations"`
-		}{
-			[]struct {
-				ID string `json:"id"`
-			}{
-				{
-					ID: organizationID,
-				},
-			},
-		}
+	return query, nil
+}
 
-		filterBytes, err := json.Marshal(filter)
-		if err != nil {
-			return query, err
-		}
+func whereClauseGeneratorForAllFilters(query sq.SelectBuilder, alias, timeColumn string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-		query = query.
-			Where(fmt.Sprintf("%v.user_data @> ?", alias), string(filterBytes))
+	query, err := whereClauseGeneratorForTimeRangeFilter(query, alias, timeColumn, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForBuildStatusFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForLabelsFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForSearchFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForRecentCommitterFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForRecentReleaserFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForGroupsFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForOrganizationsFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForArchivedFilter(query, alias, filters)
+	if err != nil {
+		return query, err
 	}
 
 	return query, nil
 }
 
-func whereClauseGeneratorForCatalogEntityFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func whereClauseGeneratorForAllReleaseFilters(query sq.SelectBuilder, alias, timeColumn string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	query, err := whereClauseGeneratorForParentFilter(query, alias, filters)
+	query, err := whereClauseGeneratorForReleaseStatusFilter(query, alias, filters)
 	if err != nil {
 		return query, err
 	}
-
-	query, err = whereClauseGeneratorForEntityFilter(query, alias, filters)
+	query, err = whereClauseGeneratorForTimeRangeFilter(query, alias, timeColumn, filters)
 	if err != nil {
 		return query, err
 	}
 
-	query, err = whereClauseGeneratorForLinkedPipelineFilter(query, alias, filters)
+	return query, nil
+}
+
+func whereClauseGeneratorForDoraFilters(query sq.SelectBuilder, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	query, err := whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", filters)
 	if err != nil {
 		return query, err
 	}
-
-	query, err = whereClauseGeneratorForLabelsFilter(query, alias, filters)
+	query, err = whereClauseGeneratorForLabelsFilter(query, "p", filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForGroupsFilter(query, "a", filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForOrganizationsFilter(query, "a", filters)
 	if err != nil {
 		return query, err
 	}
@@ -3583,821 +3831,1982 @@ func whereClauseGeneratorForCatalogEntityFilters(query sq.SelectBuilder, alias s
 	return query, nil
 }
 
-func whereClauseGeneratorForParentFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func whereClauseGeneratorForJobUsageFilters(query sq.SelectBuilder, labelsAlias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	if parents, ok := filters[api.FilterParent]; ok && len(parents) == 1 {
-		keyValuePair := strings.Split(parents[0], "=")
-		if len(keyValuePair) > 0 {
-			query = query.Where(sq.Eq{fmt.Sprintf("%v.parent_key", alias): keyValuePair[0]})
-		}
-		if len(keyValuePair) > 1 {
-			query = query.Where(sq.Eq{fmt.Sprintf("%v.parent_value", alias): keyValuePair[1]})
-		}
+	query, err := whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForLabelsFilter(query, labelsAlias, filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForGroupsFilter(query, "a", filters)
+	if err != nil {
+		return query, err
+	}
+	query, err = whereClauseGeneratorForOrganizationsFilter(query, "a", filters)
+	if err != nil {
+		return query, err
 	}
 
 	return query, nil
 }
 
-func whereClauseGeneratorForEntityFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func whereClauseGeneratorForTimeRangeFilter(query sq.SelectBuilder, alias, timeColumn string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	if entities, ok := filters[api.FilterEntity]; ok && len(entities) == 1 {
-		keyValuePair := strings.Split(entities[0], "=")
-		if len(keyValuePair) > 0 {
-			query = query.Where(sq.Eq{fmt.Sprintf("%v.entity_key", alias): keyValuePair[0]})
+	now := time.Now().UTC()
+
+	if since, ok := filters[api.FilterSince]; ok && len(since) > 0 {
+		sinceTime, err := api.ParseTimeFilter(since[0], now)
+		if err != nil {
+			return query, err
 		}
-		if len(keyValuePair) > 1 {
-			query = query.Where(sq.Eq{fmt.Sprintf("%v.entity_value", alias): keyValuePair[1]})
+		if !sinceTime.IsZero() {
+			query = query.Where(sq.GtOrEq{fmt.Sprintf("%v.%v", alias, timeColumn): sinceTime})
+		}
+	}
+
+	if until, ok := filters[api.FilterUntil]; ok && len(until) > 0 {
+		untilTime, err := api.ParseTimeFilter(until[0], now)
+		if err != nil {
+			return query, err
+		}
+		if !untilTime.IsZero() {
+			query = query.Where(sq.Lt{fmt.Sprintf("%v.%v", alias, timeColumn): untilTime})
 		}
 	}
 
 	return query, nil
 }
 
-func whereClauseGeneratorForLinkedPipelineFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func whereClauseGeneratorForAuditEventFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	if pipelines, ok := filters[api.FilterPipeline]; ok && len(pipelines) == 1 {
-		query = query.Where(sq.Eq{fmt.Sprintf("%v.linked_pipeline", alias): pipelines[0]})
+	query, err := whereClauseGeneratorForTimeRangeFilter(query, alias, "inserted_at", filters)
+	if err != nil {
+		return query, err
+	}
+
+	if actors, ok := filters[api.FilterActor]; ok && len(actors) > 0 {
+		query = query.Where(sq.Or{
+			sq.Eq{fmt.Sprintf("%v.actor_id", alias): actors},
+			sq.Eq{fmt.Sprintf("%v.actor_email", alias): actors},
+		})
+	}
+	if actions, ok := filters[api.FilterAction]; ok && len(actions) > 0 {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.action", alias): actions})
+	}
+	if targetTypes, ok := filters[api.FilterTargetType]; ok && len(targetTypes) > 0 {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.target_type", alias): targetTypes})
+	}
+	if targetIDs, ok := filters[api.FilterTargetID]; ok && len(targetIDs) > 0 {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.target_id", alias): targetIDs})
 	}
 
 	return query, nil
 }
 
-func limitClauseGeneratorForLastFilter(query sq.SelectBuilder, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+func whereClauseGeneratorForPersonalAccessTokenFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	if last, ok := filters[api.FilterLast]; ok && len(last) == 1 {
-		lastValue := last[0]
-		limitSize, err := strconv.ParseUint(lastValue, 10, 64)
-		if err != nil {
-			return query, err
-		}
+	query, err := whereClauseGeneratorForTimeRangeFilter(query, alias, "inserted_at", filters)
+	if err != nil {
+		return query, err
+	}
 
-		query = query.Limit(limitSize)
+	if statuses, ok := filters[api.FilterStatus]; ok && len(statuses) > 0 {
+		hasActive := foundation.StringArrayContains(statuses, "active")
+		hasRevoked := foundation.StringArrayContains(statuses, "revoked")
+
+		if hasActive && !hasRevoked {
+			query = query.Where(sq.Eq{fmt.Sprintf("%v.active", alias): true})
+		} else if hasRevoked && !hasActive {
+			query = query.Where(sq.Eq{fmt.Sprintf("%v.active", alias): false})
+		}
 	}
 
 	return query, nil
 }
 
-func (c *client) scanItems(ctx context.Context, rows *sql.Rows) (items []map[string]interface{}, err error) {
+func whereClauseGeneratorForSearchFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	items = make([]map[string]interface{}, 0)
+	if search, ok := filters[api.FilterSearch]; ok && len(search) > 0 && search[0] != "" {
+		searchValue := search[0]
+		query = query.Where(
+			sq.Or{
+				sq.Like{fmt.Sprintf("%v.repo_source", alias): fmt.Sprint("%", searchValue, "%")},
+				sq.Like{fmt.Sprintf("%v.repo_owner", alias): fmt.Sprint("%", searchValue, "%")},
+				sq.Like{fmt.Sprintf("%v.repo_name", alias): fmt.Sprint("%", searchValue, "%")},
+			})
+	}
 
-	cols, _ := rows.Columns()
-	for rows.Next() {
-		// Create a slice of interface{}'s to represent each column,
-		// and a second slice to contain pointers to each item in the columns slice.
-		columns := make([]interface{}, len(cols))
-		columnPointers := make([]interface{}, len(cols))
-		for i := range columns {
-			columnPointers[i] = &columns[i]
-		}
+	return query, nil
+}
 
-		// Scan the result into the column pointers...
-		if err = rows.Scan(columnPointers...); err != nil {
+func whereClauseGeneratorForBuildStatusFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-			return nil, err
-		}
+	if statuses, ok := filters[api.FilterStatus]; ok && len(statuses) > 0 && statuses[0] != "all" {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.build_status", alias): statuses})
+	}
 
-		// Create our map, and retrieve the value for each column from the pointers slice,
-		// storing it in the map with the name of the column as the key.
-		m := make(map[string]interface{})
-		for i, colName := range cols {
-			val := columnPointers[i].(*interface{})
-			m[colName] = *val
-		}
+	return query, nil
+}
 
-		items = append(items, m)
+func whereClauseGeneratorForReleaseStatusFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	if statuses, ok := filters[api.FilterStatus]; ok && len(statuses) > 0 && statuses[0] != "all" {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.release_status", alias): statuses})
 	}
 
-	return items, nil
+	return query, nil
 }
 
-func (c *client) scanBuild(ctx context.Context, row sq.RowScanner, optimized, enriched bool) (build *contracts.Build, err error) {
+func whereClauseGeneratorForLabelsFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	build = &contracts.Build{}
-	var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData []uint8
-	var durationPendingSeconds, durationRunningSeconds int
+	if labels, ok := filters[api.FilterLabels]; ok && len(labels) > 0 {
 
-	if err = row.Scan(
-		&build.ID,
-		&build.RepoSource,
-		&build.RepoOwner,
-		&build.RepoName,
-		&build.RepoBranch,
-		&build.RepoRevision,
-		&build.BuildVersion,
-		&build.BuildStatus,
-		&labelsData,
-		&releaseTargetsData,
-		&build.Manifest,
-		&commitsData,
-		&triggersData,
-		&build.InsertedAt,
-		&build.StartedAt,
-		&build.UpdatedAt,
-		&durationPendingSeconds,
-		&durationRunningSeconds,
-		&triggeredByEventsData,
-		&groupsData,
-		&organizationsData); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+		selector, err := api.ParseLabelSelectors(labels)
+		if err != nil {
+			return query, err
+		}
+
+		for _, r := range selector {
+
+			// each label value is matched through json containment, so it can use the inverted index on labels
+			containments := []string{}
+			args := []interface{}{}
+			if len(r.Values) == 0 {
+				bytes, err := json.Marshal([]map[string]string{{"key": r.Key}})
+				if err != nil {
+					return query, err
+				}
+				containments = append(containments, fmt.Sprintf("%v.labels @> ?", alias))
+				args = append(args, string(bytes))
+			}
+			for _, v := range r.Values {
+				bytes, err := json.Marshal([]contracts.Label{{Key: r.Key, Value: v}})
+				if err != nil {
+					return query, err
+				}
+				containments = append(containments, fmt.Sprintf("%v.labels @> ?", alias))
+				args = append(args, string(bytes))
+			}
+
+			switch r.Operator {
+			case api.LabelSelectorEquals, api.LabelSelectorIn, api.LabelSelectorExists:
+				query = query.Where(fmt.Sprintf("(%v)", strings.Join(containments, " OR ")), args...)
+			case api.LabelSelectorNotEquals, api.LabelSelectorNotIn, api.LabelSelectorDoesNotExist:
+				// items without labels match as well
+				query = query.Where(fmt.Sprintf("NOT COALESCE(%v, false)", strings.Join(containments, " OR ")), args...)
+			}
 		}
-		return
 	}
 
-	pendingDuration := time.Duration(durationPendingSeconds) * time.Second
-	runningDuration := time.Duration(durationRunningSeconds) * time.Second
+	return query, nil
+}
 
-	build.PendingDuration = &pendingDuration
-	build.Duration = runningDuration
+func whereClauseGeneratorForRecentCommitterFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	c.setBuildPropertiesFromJSONB(build, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData, optimized)
+	if user, ok := filters[api.FilterRecentCommitter]; ok && len(user) > 0 {
 
-	if enriched {
-		c.enrichBuild(ctx, build)
-	}
+		userParam := []string{user[0]}
 
-	if optimized {
-		// clear some properties for reduced size and improved performance over the network
-		build.Manifest = ""
-		build.ManifestWithDefaults = ""
+		bytes, err := json.Marshal(userParam)
+		if err != nil {
+			return query, err
+		}
+
+		query = query.Where(fmt.Sprintf("%v.recent_committers @> ?", alias), string(bytes))
 	}
 
-	return
+	return query, nil
 }
 
-func (c *client) scanBuilds(rows *sql.Rows, optimized bool) (builds []*contracts.Build, err error) {
+func whereClauseGeneratorForRecentReleaserFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	builds = make([]*contracts.Build, 0)
+	if user, ok := filters[api.FilterRecentReleaser]; ok && len(user) > 0 {
 
-	defer rows.Close()
-	for rows.Next() {
-
-		build := contracts.Build{}
-		var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData []uint8
-		var durationPendingSeconds, durationRunningSeconds int
+		userParam := []string{user[0]}
 
-		if err = rows.Scan(
-			&build.ID,
-			&build.RepoSource,
-			&build.RepoOwner,
-			&build.RepoName,
-			&build.RepoBranch,
-			&build.RepoRevision,
-			&build.BuildVersion,
-			&build.BuildStatus,
-			&labelsData,
-			&releaseTargetsData,
-			&build.Manifest,
-			&commitsData,
-			&triggersData,
-			&build.InsertedAt,
-			&build.StartedAt,
-			&build.UpdatedAt,
-			&durationPendingSeconds,
-			&durationRunningSeconds,
-			&triggeredByEventsData,
-			&groupsData,
-			&organizationsData); err != nil {
-			return
+		bytes, err := json.Marshal(userParam)
+		if err != nil {
+			return query, err
 		}
 
-		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
-		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+		query = query.Where(fmt.Sprintf("%v.recent_releasers @> ?", alias), string(bytes))
+	}
 
-		build.PendingDuration = &pendingDuration
-		build.Duration = runningDuration
+	return query, nil
+}
 
-		c.setBuildPropertiesFromJSONB(&build, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData, optimized)
+func whereClauseGeneratorForGroupsFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-		if optimized {
-			// clear some properties for reduced size and improved performance over the network
-			build.Manifest = ""
-			build.ManifestWithDefaults = ""
-		}
+	if groups, ok := filters[api.FilterGroups]; ok && len(groups) > 0 {
 
-		builds = append(builds, &build)
+		expressions := sq.Or{}
+		for _, g := range groups {
+			groupParam := []*contracts.Group{
+				{
+					Name: g,
+				},
+			}
+
+			bytes, err := json.Marshal(groupParam)
+			if err != nil {
+				return query, err
+			}
+
+			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.groups @> ?", alias), string(bytes)))
+		}
+		query = query.Where(expressions)
 	}
 
-	return
+	return query, nil
 }
 
-func (c *client) scanPipeline(row sq.RowScanner, optimized bool) (pipeline *contracts.Pipeline, err error) {
+func whereClauseGeneratorForOrganizationsFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	pipeline = &contracts.Pipeline{}
-	var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData []uint8
-	var durationPendingSeconds, durationRunningSeconds int
+	if organizations, ok := filters[api.FilterOrganizations]; ok && len(organizations) > 0 {
 
-	if err = row.Scan(
-		&pipeline.ID,
-		&pipeline.RepoSource,
-		&pipeline.RepoOwner,
-		&pipeline.RepoName,
-		&pipeline.RepoBranch,
-		&pipeline.RepoRevision,
-		&pipeline.BuildVersion,
-		&pipeline.BuildStatus,
-		&labelsData,
-		&releaseTargetsData,
-		&pipeline.Manifest,
-		&commitsData,
-		&triggersData,
-		&pipeline.Archived,
-		&pipeline.InsertedAt,
-		&pipeline.StartedAt,
-		&pipeline.UpdatedAt,
-		&durationPendingSeconds,
-		&durationRunningSeconds,
-		&pipeline.LastUpdatedAt,
-		&triggeredByEventsData,
-		&extraInfoData,
-		&groupsData,
-		&organizationsData); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
+		expressions := sq.Or{}
+		for _, o := range organizations {
+			organizationParam := []*contracts.Organization{
+				{
+					Name: o,
+				},
+			}
+
+			bytes, err := json.Marshal(organizationParam)
+			if err != nil {
+				return query, err
+			}
+
+			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.organizations @> ?", alias), string(bytes)))
 		}
-		return
+		query = query.Where(expressions)
 	}
 
-	pendingDuration := time.Duration(durationPendingSeconds) * time.Second
-	runningDuration := time.Duration(durationRunningSeconds) * time.Second
+	return query, nil
+}
 
-	pipeline.PendingDuration = &pendingDuration
-	pipeline.Duration = runningDuration
+func whereClauseGeneratorForOrganizationsInUserDataFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	c.setPipelinePropertiesFromJSONB(pipeline, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData, optimized)
+	if organizations, ok := filters[api.FilterOrganizations]; ok && len(organizations) > 0 {
 
-	if optimized {
-		// clear some properties for reduced size and improved performance over the network
-		pipeline.Manifest = ""
-		pipeline.ManifestWithDefaults = ""
+		expressions := sq.Or{}
+		for _, o := range organizations {
+			organizationParam := contracts.User{
+				Organizations: []*contracts.Organization{
+					{
+						Name: o,
+					},
+				},
+			}
+
+			bytes, err := json.Marshal(organizationParam)
+			if err != nil {
+				return query, err
+			}
+
+			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.user_data @> ?", alias), string(bytes)))
+		}
+		query = query.Where(expressions)
 	}
 
-	return
+	return query, nil
 }
 
-func (c *client) scanPipelines(rows *sql.Rows, optimized bool) (pipelines []*contracts.Pipeline, err error) {
+func whereClauseGeneratorForOrganizationsInGroupDataFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-	pipelines = make([]*contracts.Pipeline, 0)
+	if organizations, ok := filters[api.FilterOrganizations]; ok && len(organizations) > 0 {
 
-	defer rows.Close()
-	for rows.Next() {
+		expressions := sq.Or{}
+		for _, o := range organizations {
+			organizationParam := contracts.Group{
+				Organizations: []*contracts.Organization{
+					{
+						Name: o,
+					},
+				},
+			}
 
-		pipeline := contracts.Pipeline{}
-		var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData []uint8
-		var durationPendingSeconds, durationRunningSeconds int
+			bytes, err := json.Marshal(organizationParam)
+			if err != nil {
+				return query, err
+			}
 
-		if err = rows.Scan(
-			&pipeline.ID,
-			&pipeline.RepoSource,
-			&pipeline.RepoOwner,
-			&pipeline.RepoName,
-			&pipeline.RepoBranch,
-			&pipeline.RepoRevision,
-			&pipeline.BuildVersion,
-			&pipeline.BuildStatus,
-			&labelsData,
-			&releaseTargetsData,
-			&pipeline.Manifest,
-			&commitsData,
-			&triggersData,
-			&pipeline.Archived,
-			&pipeline.InsertedAt,
-			&pipeline.StartedAt,
-			&pipeline.UpdatedAt,
-			&durationPendingSeconds,
-			&durationRunningSeconds,
-			&pipeline.LastUpdatedAt,
-			&triggeredByEventsData,
-			&extraInfoData,
-			&groupsData,
-			&organizationsData); err != nil {
-			return
+			expressions = append(expressions, sq.Expr(fmt.Sprintf("%v.group_data @> ?", alias), string(bytes)))
 		}
+		query = query.Where(expressions)
+	}
 
-		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
-		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+	return query, nil
+}
 
-		pipeline.PendingDuration = &pendingDuration
-		pipeline.Duration = runningDuration
+func whereClauseGeneratorForArchivedFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
 
-		c.setPipelinePropertiesFromJSONB(&pipeline, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData, optimized)
+	if archiveds, ok := filters[api.FilterArchived]; ok && len(archiveds) > 0 {
 
-		if optimized {
-			// clear some properties for reduced size and improved performance over the network
-			pipeline.Manifest = ""
-			pipeline.ManifestWithDefaults = ""
+		hasTrue := false
+		hasFalse := false
+		for _, a := range archiveds {
+			if a == "true" {
+				hasTrue = true
+			}
+			if a == "false" {
+				hasFalse = true
+			}
+		}
+
+		if hasTrue && !hasFalse {
+			query = query.Where(sq.Eq{"a.archived": true})
+		} else if hasFalse && !hasTrue {
+			query = query.Where(sq.Eq{"a.archived": false})
+		}
+	}
+
+	return query, nil
+}
+
+// whereClauseGeneratorForActiveFilter selects only active records, unless the active filter asks for inactive records instead or as well
+func whereClauseGeneratorForActiveFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	actives, ok := filters[api.FilterActive]
+	if !ok || len(actives) == 0 {
+		return query.Where(sq.Eq{fmt.Sprintf("%v.active", alias): true}), nil
+	}
+
+	hasTrue := false
+	hasFalse := false
+	for _, a := range actives {
+		if a == "true" {
+			hasTrue = true
 		}
+		if a == "false" {
+			hasFalse = true
+		}
+	}
+
+	if hasTrue && !hasFalse {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.active", alias): true})
+	} else if hasFalse && !hasTrue {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.active", alias): false})
+	}
+
+	return query, nil
+}
+
+func whereClauseGeneratorForUserFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	query, err := whereClauseGeneratorForUserGroupFilters(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+
+	query, err = whereClauseGeneratorForUserOrganizationFilters(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+
+	query, err = whereClauseGeneratorForOrganizationsInUserDataFilter(query, "a", filters)
+	if err != nil {
+		return query, err
+	}
+
+	query, err = whereClauseGeneratorForUserIdentityFilters(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+// whereClauseGeneratorForUserIdentityFilters matches users with an identity with the email address or id, ignoring case like scim filters do
+func whereClauseGeneratorForUserIdentityFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	if emails, ok := filters[api.FilterIdentityEmail]; ok && len(emails) > 0 {
+		query = query.
+			Where(fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(%v.user_data->'identities') AS i WHERE lower(i->>'email') = lower(?))", alias), emails[0])
+	}
+
+	if ids, ok := filters[api.FilterIdentityID]; ok && len(ids) > 0 {
+		query = query.
+			Where(fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(%v.user_data->'identities') AS i WHERE lower(i->>'id') = lower(?))", alias), ids[0])
+	}
+
+	return query, nil
+}
+
+func whereClauseGeneratorForUserGroupFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	if groupIDs, ok := filters[api.FilterGroupID]; ok && len(groupIDs) > 0 {
+
+		groupID := groupIDs[0]
+
+		filter := struct {
+			Groups []struct {
+				ID string `json:"id"`
+			} `json:"groups"`
+		}{
+			[]struct {
+				ID string `json:"id"`
+			}{
+				{
+					ID: groupID,
+				},
+			},
+		}
+
+		filterBytes, err := json.Marshal(filter)
+		if err != nil {
+			return query, err
+		}
+
+		query = query.
+			Where(fmt.Sprintf("%v.user_data @> ?", alias), string(filterBytes))
+	}
+
+	return query, nil
+}
+
+func whereClauseGeneratorForUserOrganizationFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+	if organizationIDs, ok := filters[api.FilterOrganizationID]; ok && len(organizationIDs) > 0 {
+
+		organizationID := organizationIDs[0]
+
+		filter := struct {
+			Organizations []struct {
+				ID string `json:"id"`
+			} `json:"organizations"`
+		}{
+			[]struct {
+				ID string `json:"id"`
+			}{
+				{
+					ID: organizationID,
+				},
+			},
+		}
+
+		filterBytes, err := json.Marshal(filter)
+		if err != nil {
+			return query, err
+		}
+
+		query = query.
+			Where(fmt.Sprintf("%v.user_data @> ?", alias), string(filterBytes))
+	}
+
+	return query, nil
+}
+
+func whereClauseGeneratorForCatalogEntityFilters(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	query, err := whereClauseGeneratorForParentFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+
+	query, err = whereClauseGeneratorForEntityFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+
+	query, err = whereClauseGeneratorForLinkedPipelineFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+
+	query, err = whereClauseGeneratorForLabelsFilter(query, alias, filters)
+	if err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+func whereClauseGeneratorForParentFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	if parents, ok := filters[api.FilterParent]; ok && len(parents) == 1 {
+		keyValuePair := strings.Split(parents[0], "=")
+		if len(keyValuePair) > 0 {
+			query = query.Where(sq.Eq{fmt.Sprintf("%v.parent_key", alias): keyValuePair[0]})
+		}
+		if len(keyValuePair) > 1 {
+			query = query.Where(sq.Eq{fmt.Sprintf("%v.parent_value", alias): keyValuePair[1]})
+		}
+	}
+
+	return query, nil
+}
+
+func whereClauseGeneratorForEntityFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	if entities, ok := filters[api.FilterEntity]; ok && len(entities) == 1 {
+		keyValuePair := strings.Split(entities[0], "=")
+		if len(keyValuePair) > 0 {
+			query = query.Where(sq.Eq{fmt.Sprintf("%v.entity_key", alias): keyValuePair[0]})
+		}
+		if len(keyValuePair) > 1 {
+			query = query.Where(sq.Eq{fmt.Sprintf("%v.entity_value", alias): keyValuePair[1]})
+		}
+	}
+
+	return query, nil
+}
+
+func whereClauseGeneratorForLinkedPipelineFilter(query sq.SelectBuilder, alias string, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	if pipelines, ok := filters[api.FilterPipeline]; ok && len(pipelines) == 1 {
+		query = query.Where(sq.Eq{fmt.Sprintf("%v.linked_pipeline", alias): pipelines[0]})
+	}
+
+	return query, nil
+}
+
+func limitClauseGeneratorForLastFilter(query sq.SelectBuilder, filters map[api.FilterType][]string) (sq.SelectBuilder, error) {
+
+	if last, ok := filters[api.FilterLast]; ok && len(last) == 1 {
+		lastValue := last[0]
+		limitSize, err := strconv.ParseUint(lastValue, 10, 64)
+		if err != nil {
+			return query, err
+		}
+
+		query = query.Limit(limitSize)
+	}
+
+	return query, nil
+}
+
+func (c *client) scanItems(ctx context.Context, rows *sql.Rows) (items []map[string]interface{}, err error) {
+
+	items = make([]map[string]interface{}, 0)
+
+	cols, _ := rows.Columns()
+	for rows.Next() {
+		// Create a slice of interface{}'s to represent each column,
+		// and a second slice to contain pointers to each item in the columns slice.
+		columns := make([]interface{}, len(cols))
+		columnPointers := make([]interface{}, len(cols))
+		for i := range columns {
+			columnPointers[i] = &columns[i]
+		}
+
+		// Scan the result into the column pointers...
+		if err = rows.Scan(columnPointers...); err != nil {
+
+			return nil, err
+		}
+
+		// Create our map, and retrieve the value for each column from the pointers slice,
+		// storing it in the map with the name of the column as the key.
+		m := make(map[string]interface{})
+		for i, colName := range cols {
+			val := columnPointers[i].(*interface{})
+			m[colName] = *val
+		}
+
+		items = append(items, m)
+	}
+
+	return items, nil
+}
+
+func (c *client) scanBuild(ctx context.Context, row sq.RowScanner, optimized, enriched bool) (build *contracts.Build, err error) {
+
+	build = &contracts.Build{}
+	var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData []uint8
+	var durationPendingSeconds, durationRunningSeconds int
+
+	if err = row.Scan(
+		&build.ID,
+		&build.RepoSource,
+		&build.RepoOwner,
+		&build.RepoName,
+		&build.RepoBranch,
+		&build.RepoRevision,
+		&build.BuildVersion,
+		&build.BuildStatus,
+		&labelsData,
+		&releaseTargetsData,
+		&build.Manifest,
+		&commitsData,
+		&triggersData,
+		&build.InsertedAt,
+		&build.StartedAt,
+		&build.UpdatedAt,
+		&durationPendingSeconds,
+		&durationRunningSeconds,
+		&triggeredByEventsData,
+		&groupsData,
+		&organizationsData); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return
+	}
+
+	pendingDuration := time.Duration(durationPendingSeconds) * time.Second
+	runningDuration := time.Duration(durationRunningSeconds) * time.Second
+
+	build.PendingDuration = &pendingDuration
+	build.Duration = runningDuration
+
+	c.setBuildPropertiesFromJSONB(build, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData, optimized)
+
+	if enriched {
+		c.enrichBuild(ctx, build)
+	}
+
+	if optimized {
+		// clear some properties for reduced size and improved performance over the network
+		build.Manifest = ""
+		build.ManifestWithDefaults = ""
+	}
+
+	return
+}
+
+func (c *client) scanBuilds(rows *sql.Rows, optimized bool) (builds []*contracts.Build, err error) {
+
+	builds = make([]*contracts.Build, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		build := contracts.Build{}
+		var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData []uint8
+		var durationPendingSeconds, durationRunningSeconds int
+
+		if err = rows.Scan(
+			&build.ID,
+			&build.RepoSource,
+			&build.RepoOwner,
+			&build.RepoName,
+			&build.RepoBranch,
+			&build.RepoRevision,
+			&build.BuildVersion,
+			&build.BuildStatus,
+			&labelsData,
+			&releaseTargetsData,
+			&build.Manifest,
+			&commitsData,
+			&triggersData,
+			&build.InsertedAt,
+			&build.StartedAt,
+			&build.UpdatedAt,
+			&durationPendingSeconds,
+			&durationRunningSeconds,
+			&triggeredByEventsData,
+			&groupsData,
+			&organizationsData); err != nil {
+			return
+		}
+
+		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
+		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+
+		build.PendingDuration = &pendingDuration
+		build.Duration = runningDuration
+
+		c.setBuildPropertiesFromJSONB(&build, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, groupsData, organizationsData, optimized)
+
+		if optimized {
+			// clear some properties for reduced size and improved performance over the network
+			build.Manifest = ""
+			build.ManifestWithDefaults = ""
+		}
+
+		builds = append(builds, &build)
+	}
+
+	return
+}
+
+func (c *client) scanPipeline(row sq.RowScanner, optimized bool) (pipeline *contracts.Pipeline, err error) {
+
+	pipeline = &contracts.Pipeline{}
+	var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData []uint8
+	var durationPendingSeconds, durationRunningSeconds int
+
+	if err = row.Scan(
+		&pipeline.ID,
+		&pipeline.RepoSource,
+		&pipeline.RepoOwner,
+		&pipeline.RepoName,
+		&pipeline.RepoBranch,
+		&pipeline.RepoRevision,
+		&pipeline.BuildVersion,
+		&pipeline.BuildStatus,
+		&labelsData,
+		&releaseTargetsData,
+		&pipeline.Manifest,
+		&commitsData,
+		&triggersData,
+		&pipeline.Archived,
+		&pipeline.InsertedAt,
+		&pipeline.StartedAt,
+		&pipeline.UpdatedAt,
+		&durationPendingSeconds,
+		&durationRunningSeconds,
+		&pipeline.LastUpdatedAt,
+		&triggeredByEventsData,
+		&extraInfoData,
+		&groupsData,
+		&organizationsData); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return
+	}
+
+	pendingDuration := time.Duration(durationPendingSeconds) * time.Second
+	runningDuration := time.Duration(durationRunningSeconds) * time.Second
+
+	pipeline.PendingDuration = &pendingDuration
+	pipeline.Duration = runningDuration
+
+	c.setPipelinePropertiesFromJSONB(pipeline, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData, optimized)
+
+	if optimized {
+		// clear some properties for reduced size and improved performance over the network
+		pipeline.Manifest = ""
+		pipeline.ManifestWithDefaults = ""
+	}
+
+	return
+}
+
+func (c *client) scanPipelines(rows *sql.Rows, optimized bool) (pipelines []*contracts.Pipeline, err error) {
+
+	pipelines = make([]*contracts.Pipeline, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		pipeline := contracts.Pipeline{}
+		var labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData []uint8
+		var durationPendingSeconds, durationRunningSeconds int
+
+		if err = rows.Scan(
+			&pipeline.ID,
+			&pipeline.RepoSource,
+			&pipeline.RepoOwner,
+			&pipeline.RepoName,
+			&pipeline.RepoBranch,
+			&pipeline.RepoRevision,
+			&pipeline.BuildVersion,
+			&pipeline.BuildStatus,
+			&labelsData,
+			&releaseTargetsData,
+			&pipeline.Manifest,
+			&commitsData,
+			&triggersData,
+			&pipeline.Archived,
+			&pipeline.InsertedAt,
+			&pipeline.StartedAt,
+			&pipeline.UpdatedAt,
+			&durationPendingSeconds,
+			&durationRunningSeconds,
+			&pipeline.LastUpdatedAt,
+			&triggeredByEventsData,
+			&extraInfoData,
+			&groupsData,
+			&organizationsData); err != nil {
+			return
+		}
+
+		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
+		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+
+		pipeline.PendingDuration = &pendingDuration
+		pipeline.Duration = runningDuration
+
+		c.setPipelinePropertiesFromJSONB(&pipeline, labelsData, releaseTargetsData, commitsData, triggersData, triggeredByEventsData, extraInfoData, groupsData, organizationsData, optimized)
+
+		if optimized {
+			// clear some properties for reduced size and improved performance over the network
+			pipeline.Manifest = ""
+			pipeline.ManifestWithDefaults = ""
+		}
+
+		pipelines = append(pipelines, &pipeline)
+	}
+
+	return
+}
+
+func (c *client) scanRelease(row sq.RowScanner) (release *contracts.Release, err error) {
+
+	release = &contracts.Release{}
+	var durationPendingSeconds, durationRunningSeconds int
+	var id int
+	var triggeredByEventsData, groupsData, organizationsData []uint8
+
+	if err = row.Scan(
+		&id,
+		&release.RepoSource,
+		&release.RepoOwner,
+		&release.RepoName,
+		&release.Name,
+		&release.Action,
+		&release.ReleaseVersion,
+		&release.ReleaseStatus,
+		&release.InsertedAt,
+		&release.StartedAt,
+		&release.UpdatedAt,
+		&durationPendingSeconds,
+		&durationRunningSeconds,
+		&triggeredByEventsData,
+		&groupsData,
+		&organizationsData); err != nil {
+		return nil, err
+	}
+
+	pendingDuration := time.Duration(durationPendingSeconds) * time.Second
+	runningDuration := time.Duration(durationRunningSeconds) * time.Second
+
+	release.PendingDuration = &pendingDuration
+	release.Duration = &runningDuration
+	release.ID = strconv.Itoa(id)
+
+	err = c.setReleasePropertiesFromJSONB(release, triggeredByEventsData, groupsData, organizationsData)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *client) scanReleases(rows *sql.Rows) (releases []*contracts.Release, err error) {
+
+	releases = make([]*contracts.Release, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		release := contracts.Release{}
+		var durationPendingSeconds, durationRunningSeconds int
+		var id int
+		var triggeredByEventsData, groupsData, organizationsData []uint8
+
+		if err = rows.Scan(
+			&id,
+			&release.RepoSource,
+			&release.RepoOwner,
+			&release.RepoName,
+			&release.Name,
+			&release.Action,
+			&release.ReleaseVersion,
+			&release.ReleaseStatus,
+			&release.InsertedAt,
+			&release.StartedAt,
+			&release.UpdatedAt,
+			&durationPendingSeconds,
+			&durationRunningSeconds,
+			&triggeredByEventsData,
+			&groupsData,
+			&organizationsData); err != nil {
+			return
+		}
+
+		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
+		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+
+		release.PendingDuration = &pendingDuration
+		release.Duration = &runningDuration
+		release.ID = strconv.Itoa(id)
+
+		err = c.setReleasePropertiesFromJSONB(&release, triggeredByEventsData, groupsData, organizationsData)
+		if err != nil {
+			return
+		}
+
+		releases = append(releases, &release)
+	}
+
+	return
+}
+
+func (c *client) scanPipelineReleases(rows *sql.Rows) (releases []*contracts.Release, err error) {
+
+	releases = make([]*contracts.Release, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		release := contracts.Release{}
+		var durationPendingSeconds, durationRunningSeconds int
+		var id int
+		var triggeredByEventsData, extraInfoData, groupsData, organizationsData []uint8
+
+		if err = rows.Scan(
+			&id,
+			&release.RepoSource,
+			&release.RepoOwner,
+			&release.RepoName,
+			&release.Name,
+			&release.Action,
+			&release.ReleaseVersion,
+			&release.ReleaseStatus,
+			&release.InsertedAt,
+			&release.StartedAt,
+			&release.UpdatedAt,
+			&durationPendingSeconds,
+			&durationRunningSeconds,
+			&triggeredByEventsData,
+			&extraInfoData,
+			&groupsData,
+			&organizationsData); err != nil {
+			return
+		}
+
+		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
+		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+
+		release.PendingDuration = &pendingDuration
+		release.Duration = &runningDuration
+		release.ID = strconv.Itoa(id)
+
+		if len(triggeredByEventsData) > 0 {
+			if err = json.Unmarshal(triggeredByEventsData, &release.Events); err != nil {
+				return
+			}
+		}
+
+		if len(extraInfoData) > 0 {
+			if err = json.Unmarshal(extraInfoData, &release.ExtraInfo); err != nil {
+				return
+			}
+		}
+
+		releases = append(releases, &release)
+	}
+
+	return
+}
+
+func (c *client) GetTriggers(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error) {
+
+	// generate query
+	query := c.selectPipelinesQuery().
+		Where(sq.Eq{"a.archived": false})
+
+	trigger := manifest.EstafetteTrigger{}
+
+	switch triggerType {
+	case "pipeline":
+
+		trigger.Pipeline = &manifest.EstafettePipelineTrigger{
+			Event: event,
+			Name:  identifier,
+		}
+
+		break
+
+	case "release":
+
+		trigger.Release = &manifest.EstafetteReleaseTrigger{
+			Event: event,
+			Name:  identifier,
+		}
+
+		break
+
+	case "cron":
+
+		trigger.Cron = &manifest.EstafetteCronTrigger{}
+
+		break
+
+	case "git":
+
+		trigger.Git = &manifest.EstafetteGitTrigger{
+			Event:      event,
+			Repository: identifier,
+		}
+
+		break
+
+	case "pubsub":
+
+		trigger.PubSub = &manifest.EstafettePubSubTrigger{}
+
+		break
+
+	default:
+
+		return pipelines, fmt.Errorf("Trigger type %v is not supported", triggerType)
+	}
+
+	bytes, err := json.Marshal([]manifest.EstafetteTrigger{trigger})
+	if err != nil {
+
+		return pipelines, err
+	}
+
+	query = query.Where("a.triggers @> ?", string(bytes))
+
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
+	if err != nil {
+
+		return
+	}
+
+	// read rows
+	if pipelines, err = c.scanPipelines(rows, false); err != nil {
+
+		return
+	}
+
+	return
+}
+
+func (c *client) GetGitTriggers(ctx context.Context, gitEvent manifest.EstafetteGitEvent) ([]*contracts.Pipeline, error) {
+
+	triggerType := "git"
+	name := gitEvent.Repository
+	event := gitEvent.Event
+
+	return c.GetTriggers(ctx, triggerType, name, event)
+}
+
+func (c *client) GetPipelineTriggers(ctx context.Context, build contracts.Build, event string) ([]*contracts.Pipeline, error) {
+
+	triggerType := "pipeline"
+	name := fmt.Sprintf("%v/%v/%v", build.RepoSource, build.RepoOwner, build.RepoName)
+
+	return c.GetTriggers(ctx, triggerType, name, event)
+}
+
+func (c *client) GetReleaseTriggers(ctx context.Context, release contracts.Release, event string) ([]*contracts.Pipeline, error) {
+
+	triggerType := "release"
+	name := fmt.Sprintf("%v/%v/%v", release.RepoSource, release.RepoOwner, release.RepoName)
+
+	return c.GetTriggers(ctx, triggerType, name, event)
+}
+
+func (c *client) GetPubSubTriggers(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) ([]*contracts.Pipeline, error) {
+
+	triggerType := "pubsub"
+
+	return c.GetTriggers(ctx, triggerType, "", "")
+}
+
+func (c *client) GetCronTriggers(ctx context.Context) ([]*contracts.Pipeline, error) {
+
+	triggerType := "cron"
+
+	return c.GetTriggers(ctx, triggerType, "", "")
+}
+
+func (c *client) GetDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error) {
+
+	bytes, err := json.Marshal([]manifest.EstafetteTrigger{{Docker: &manifest.EstafetteDockerTrigger{}}})
+	if err != nil {
+		return
+	}
+
+	// besides pipelines with docker triggers return the ones with stages using the image, the caller checks whether the tag matches as well
+	containerImagesBytes, err := json.Marshal([]string{api.GetDockerEventImageReference(dockerEvent).FullName()})
+	if err != nil {
+		return
+	}
+
+	query := c.selectPipelinesQuery().
+		Where(sq.Eq{"a.archived": false}).
+		Where(sq.Or{
+			sq.Expr("a.triggers @> ?", string(bytes)),
+			sq.Expr("a.container_images @> ?", string(containerImagesBytes)),
+		})
+
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
+	if err != nil {
+		return
+	}
+
+	// read rows
+	if pipelines, err = c.scanPipelines(rows, false); err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *client) Rename(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) error {
+
+	nrOfQueries := 7
+	var wg sync.WaitGroup
+	wg.Add(nrOfQueries)
+
+	errors := make(chan error, nrOfQueries)
+
+	go func(wg *sync.WaitGroup, ctx context.Context, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName string) {
+		defer wg.Done()
+		err := c.RenameBuildVersion(ctx, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName)
+		if err != nil {
+			errors <- err
+		}
+	}(&wg, ctx, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName)
+
+	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
+		defer wg.Done()
+		err := c.RenameBuilds(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+		if err != nil {
+			errors <- err
+		}
+	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+
+	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
+		defer wg.Done()
+		err := c.RenameBuildLogs(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+		if err != nil {
+			errors <- err
+		}
+	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+
+	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
+		defer wg.Done()
+		err := c.RenameReleases(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+		if err != nil {
+			errors <- err
+		}
+	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+
+	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
+		defer wg.Done()
+		err := c.RenameReleaseLogs(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+		if err != nil {
+			errors <- err
+		}
+	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+
+	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
+		defer wg.Done()
+		err := c.RenameComputedPipelines(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+		if err != nil {
+			errors <- err
+		}
+	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+
+	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
+		defer wg.Done()
+		err := c.RenameComputedReleases(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+		if err != nil {
+			errors <- err
+		}
+	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+
+	wg.Wait()
+
+	close(errors)
+	for e := range errors {
+		return e
+	}
+
+	// update computed tables
+	err := c.UpsertComputedPipeline(ctx, toRepoSource, toRepoOwner, toRepoName)
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RenameBuildVersion(ctx context.Context, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName string) error {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("build_versions").
+		Set("repo_source", shortToRepoSource).
+		Set("repo_full_name", fmt.Sprintf("%v/%v", toRepoOwner, toRepoName)).
+		Where(sq.Eq{"repo_source": shortFromRepoSource}).
+		Where(sq.Eq{"repo_full_name": fmt.Sprintf("%v/%v", fromRepoOwner, fromRepoName)}).
+		Limit(uint64(1))
+
+	_, err := query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RenameBuilds(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("builds").
+		Set("repo_source", toRepoSource).
+		Set("repo_owner", toRepoOwner).
+		Set("repo_name", toRepoName).
+		Where(sq.Eq{"repo_source": fromRepoSource}).
+		Where(sq.Eq{"repo_owner": fromRepoOwner}).
+		Where(sq.Eq{"repo_name": fromRepoName})
+
+	_, err := query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RenameBuildLogs(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("build_logs").
+		Set("repo_source", toRepoSource).
+		Set("repo_owner", toRepoOwner).
+		Set("repo_name", toRepoName).
+		Where(sq.Eq{"repo_source": fromRepoSource}).
+		Where(sq.Eq{"repo_owner": fromRepoOwner}).
+		Where(sq.Eq{"repo_name": fromRepoName})
+
+	_, err := query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RenameReleases(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("releases").
+		Set("repo_source", toRepoSource).
+		Set("repo_owner", toRepoOwner).
+		Set("repo_name", toRepoName).
+		Where(sq.Eq{"repo_source": fromRepoSource}).
+		Where(sq.Eq{"repo_owner": fromRepoOwner}).
+		Where(sq.Eq{"repo_name": fromRepoName})
+
+	_, err := query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RenameReleaseLogs(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("release_logs").
+		Set("repo_source", toRepoSource).
+		Set("repo_owner", toRepoOwner).
+		Set("repo_name", toRepoName).
+		Where(sq.Eq{"repo_source": fromRepoSource}).
+		Where(sq.Eq{"repo_owner": fromRepoOwner}).
+		Where(sq.Eq{"repo_name": fromRepoName})
+
+	_, err := query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RenameComputedPipelines(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("computed_pipelines").
+		Set("repo_source", toRepoSource).
+		Set("repo_owner", toRepoOwner).
+		Set("repo_name", toRepoName).
+		Where(sq.Eq{"repo_source": fromRepoSource}).
+		Where(sq.Eq{"repo_owner": fromRepoOwner}).
+		Where(sq.Eq{"repo_name": fromRepoName})
+
+	_, err := query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) RenameComputedReleases(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("computed_releases").
+		Set("repo_source", toRepoSource).
+		Set("repo_owner", toRepoOwner).
+		Set("repo_name", toRepoName).
+		Where(sq.Eq{"repo_source": fromRepoSource}).
+		Where(sq.Eq{"repo_owner": fromRepoOwner}).
+		Where(sq.Eq{"repo_name": fromRepoName})
+
+	_, err := query.RunWith(c.databaseConnection).Exec()
+	if err != nil {
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *client) InsertUser(ctx context.Context, user contracts.User) (u *contracts.User, err error) {
+
+	userBytes, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	// upsert user
+	row := c.databaseConnection.QueryRow(
+		`
+		INSERT INTO
+			users
+		(
+			user_data
+		)
+		VALUES
+		(
+			$1
+		)
+		RETURNING
+			id
+		`,
+		userBytes,
+	)
 
-		pipelines = append(pipelines, &pipeline)
+	u = &user
+
+	if err = row.Scan(&u.ID); err != nil {
+		return nil, err
 	}
 
 	return
 }
 
-func (c *client) scanRelease(row sq.RowScanner) (release *contracts.Release, err error) {
-
-	release = &contracts.Release{}
-	var durationPendingSeconds, durationRunningSeconds int
-	var id int
-	var triggeredByEventsData, groupsData, organizationsData []uint8
+func (c *client) UpdateUser(ctx context.Context, user contracts.User) (err error) {
 
-	if err = row.Scan(
-		&id,
-		&release.RepoSource,
-		&release.RepoOwner,
-		&release.RepoName,
-		&release.Name,
-		&release.Action,
-		&release.ReleaseVersion,
-		&release.ReleaseStatus,
-		&release.InsertedAt,
-		&release.StartedAt,
-		&release.UpdatedAt,
-		&durationPendingSeconds,
-		&durationRunningSeconds,
-		&triggeredByEventsData,
-		&groupsData,
-		&organizationsData); err != nil {
-		return nil, err
+	userBytes, err := json.Marshal(user)
+	if err != nil {
+		return
 	}
 
-	pendingDuration := time.Duration(durationPendingSeconds) * time.Second
-	runningDuration := time.Duration(durationRunningSeconds) * time.Second
-
-	release.PendingDuration = &pendingDuration
-	release.Duration = &runningDuration
-	release.ID = strconv.Itoa(id)
-
-	err = c.setReleasePropertiesFromJSONB(release, triggeredByEventsData, groupsData, organizationsData)
+	userID, err := strconv.Atoi(user.ID)
 	if err != nil {
 		return
 	}
 
-	return
-}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-func (c *client) scanReleases(rows *sql.Rows) (releases []*contracts.Release, err error) {
+	query := psql.
+		Update("users").
+		Set("user_data", userBytes).
+		Set("updated_at", sq.Expr("now()")).
+		Set("active", user.Active).
+		Where(sq.Eq{"id": userID}).
+		Limit(uint64(1))
 
-	releases = make([]*contracts.Release, 0)
+	_, err = query.RunWith(c.databaseConnection).Exec()
 
-	defer rows.Close()
-	for rows.Next() {
+	return
+}
 
-		release := contracts.Release{}
-		var durationPendingSeconds, durationRunningSeconds int
-		var id int
-		var triggeredByEventsData, groupsData, organizationsData []uint8
+func (c *client) DeleteUser(ctx context.Context, user contracts.User) (err error) {
 
-		if err = rows.Scan(
-			&id,
-			&release.RepoSource,
-			&release.RepoOwner,
-			&release.RepoName,
-			&release.Name,
-			&release.Action,
-			&release.ReleaseVersion,
-			&release.ReleaseStatus,
-			&release.InsertedAt,
-			&release.StartedAt,
-			&release.UpdatedAt,
-			&durationPendingSeconds,
-			&durationRunningSeconds,
-			&triggeredByEventsData,
-			&groupsData,
-			&organizationsData); err != nil {
-			return
-		}
+	// deactivate user
+	user.Active = false
 
-		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
-		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+	userBytes, err := json.Marshal(user)
+	if err != nil {
+		return
+	}
 
-		release.PendingDuration = &pendingDuration
-		release.Duration = &runningDuration
-		release.ID = strconv.Itoa(id)
+	userID, err := strconv.Atoi(user.ID)
+	if err != nil {
+		return
+	}
 
-		err = c.setReleasePropertiesFromJSONB(&release, triggeredByEventsData, groupsData, organizationsData)
-		if err != nil {
-			return
-		}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-		releases = append(releases, &release)
-	}
+	query := psql.
+		Update("users").
+		Set("user_data", userBytes).
+		Set("updated_at", sq.Expr("now()")).
+		Set("active", false).
+		Where(sq.Eq{"id": userID}).
+		Limit(uint64(1))
+
+	_, err = query.RunWith(c.databaseConnection).Exec()
 
 	return
 }
+func (c *client) GetUserByID(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
 
-func (c *client) scanPipelineReleases(rows *sql.Rows) (releases []*contracts.Release, err error) {
-
-	releases = make([]*contracts.Release, 0)
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	defer rows.Close()
-	for rows.Next() {
+	query := psql.
+		Select("a.id, a.user_data, a.inserted_at, a.active").
+		From("users a").
+		Where(sq.Eq{"a.id": id}).
+		Limit(uint64(1))
 
-		release := contracts.Release{}
-		var durationPendingSeconds, durationRunningSeconds int
-		var id int
-		var triggeredByEventsData, extraInfoData, groupsData, organizationsData []uint8
+	query, err = whereClauseGeneratorForActiveFilter(query, "a", filters)
+	if err != nil {
+		return nil, err
+	}
 
-		if err = rows.Scan(
-			&id,
-			&release.RepoSource,
-			&release.RepoOwner,
-			&release.RepoName,
-			&release.Name,
-			&release.Action,
-			&release.ReleaseVersion,
-			&release.ReleaseStatus,
-			&release.InsertedAt,
-			&release.StartedAt,
-			&release.UpdatedAt,
-			&durationPendingSeconds,
-			&durationRunningSeconds,
-			&triggeredByEventsData,
-			&extraInfoData,
-			&groupsData,
-			&organizationsData); err != nil {
-			return
-		}
+	query, err = whereClauseGeneratorForOrganizationsInUserDataFilter(query, "a", filters)
+	if err != nil {
+		return nil, err
+	}
 
-		pendingDuration := time.Duration(durationPendingSeconds) * time.Second
-		runningDuration := time.Duration(durationRunningSeconds) * time.Second
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	user, err = c.scanUser(row)
+	if err != nil {
+		return nil, err
+	}
 
-		release.PendingDuration = &pendingDuration
-		release.Duration = &runningDuration
-		release.ID = strconv.Itoa(id)
+	return user, nil
+}
 
-		if len(triggeredByEventsData) > 0 {
-			if err = json.Unmarshal(triggeredByEventsData, &release.Events); err != nil {
-				return
-			}
-		}
+func (c *client) GetUserByIdentity(ctx context.Context, identity contracts.UserIdentity) (user *contracts.User, err error) {
 
-		if len(extraInfoData) > 0 {
-			if err = json.Unmarshal(extraInfoData, &release.ExtraInfo); err != nil {
-				return
-			}
-		}
+	filter := struct {
+		Identities []struct {
+			Provider string `json:"provider"`
+			Email    string `json:"email"`
+		} `json:"identities"`
+	}{
+		[]struct {
+			Provider string `json:"provider"`
+			Email    string `json:"email"`
+		}{
+			{
+				Provider: identity.Provider,
+				Email:    identity.Email,
+			},
+		},
+	}
 
-		releases = append(releases, &release)
+	filterBytes, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	return
-}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-func (c *client) GetTriggers(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error) {
+	query := psql.
+		Select("a.id, a.user_data, a.inserted_at, a.active").
+		From("users a").
+		Where("a.user_data @> ?", string(filterBytes)).
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(1))
 
-	// generate query
-	query := c.selectPipelinesQuery().
-		Where(sq.Eq{"a.archived": false})
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	user, err = c.scanUser(row)
+	if err != nil {
+		return nil, err
+	}
 
-	trigger := manifest.EstafetteTrigger{}
+	return user, nil
+}
 
-	switch triggerType {
-	case "pipeline":
+func (c *client) GetUsers(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (users []*contracts.User, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-		trigger.Pipeline = &manifest.EstafettePipelineTrigger{
-			Event: event,
-			Name:  identifier,
-		}
+	query := psql.
+		Select("a.id, a.user_data, a.inserted_at, a.active").
+		From("users a").
+		Limit(uint64(pageSize)).
+		Offset(uint64((pageNumber - 1) * pageSize))
 
-		break
+	query, err = whereClauseGeneratorForActiveFilter(query, "a", filters)
+	if err != nil {
+		return
+	}
 
-	case "release":
+	// wait for https://github.com/cockroachdb/cockroach/issues/35706 to be implemented for sorting jsonb fields
 
-		trigger.Release = &manifest.EstafetteReleaseTrigger{
-			Event: event,
-			Name:  identifier,
-		}
+	// // fix sortings for fields inside the user_data jsonb object
+	// fixedSortings := []api.OrderField{}
+	// for _, s := range sortings {
+	// 	fieldName := s.FieldName
+	// 	direction := s.Direction
+
+	// 	switch s.FieldName {
+	// 	case "name":
+	// 		fieldName = "user_data-->'name'"
+	// 	case "email":
+	// 		fieldName = "user_data-->'email'"
+	// 	}
 
-		break
+	// 	fixedSortings = append(fixedSortings, api.OrderField{
+	// 		FieldName: fieldName,
+	// 		Direction: direction,
+	// 	})
+	// }
 
-	case "cron":
+	// // dynamically set order by clause
+	// query, err = orderByClauseGeneratorForSortings(query, "a", "a.user_data-->'name'", fixedSortings)
+	// if err != nil {
+	// 	return
+	// }
 
-		trigger.Cron = &manifest.EstafetteCronTrigger{}
+	query, err = whereClauseGeneratorForUserFilters(query, "a", filters)
 
-		break
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
+	if err != nil {
+		return
+	}
 
-	case "git":
+	return c.scanUsers(rows)
+}
 
-		trigger.Git = &manifest.EstafetteGitTrigger{
-			Event:      event,
-			Repository: identifier,
-		}
+func (c *client) GetUsersCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
 
-		break
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	case "pubsub":
+	query := psql.
+		Select("COUNT(a.id)").
+		From("users a").
+		Where(sq.Eq{"a.active": true})
 
-		trigger.PubSub = &manifest.EstafettePubSubTrigger{}
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	if err = row.Scan(&count); err != nil {
+		return
+	}
 
-		break
+	return
+}
 
-	default:
+func (c *client) InsertGroup(ctx context.Context, group contracts.Group) (g *contracts.Group, err error) {
 
-		return pipelines, fmt.Errorf("Trigger type %v is not supported", triggerType)
+	groupBytes, err := json.Marshal(group)
+	if err != nil {
+		return nil, err
 	}
 
-	bytes, err := json.Marshal([]manifest.EstafetteTrigger{trigger})
-	if err != nil {
+	// upsert user
+	row := c.databaseConnection.QueryRow(
+		`
+		INSERT INTO
+			groups
+		(
+			group_data
+		)
+		VALUES
+		(
+			$1
+		)
+		RETURNING
+			id
+		`,
+		groupBytes,
+	)
 
-		return pipelines, err
+	g = &group
+
+	if err = row.Scan(&g.ID); err != nil {
+		return nil, err
 	}
 
-	query = query.Where("a.triggers @> ?", string(bytes))
+	return
+}
 
-	// execute query
-	rows, err := query.RunWith(c.databaseConnection).Query()
+func (c *client) UpdateGroup(ctx context.Context, group contracts.Group) (err error) {
+	groupBytes, err := json.Marshal(group)
 	if err != nil {
-
 		return
 	}
 
-	// read rows
-	if pipelines, err = c.scanPipelines(rows, false); err != nil {
-
+	groupID, err := strconv.Atoi(group.ID)
+	if err != nil {
 		return
 	}
 
-	return
-}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-func (c *client) GetGitTriggers(ctx context.Context, gitEvent manifest.EstafetteGitEvent) ([]*contracts.Pipeline, error) {
+	query := psql.
+		Update("groups").
+		Set("group_data", groupBytes).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": groupID}).
+		Limit(uint64(1))
 
-	triggerType := "git"
-	name := gitEvent.Repository
-	event := gitEvent.Event
+	_, err = query.RunWith(c.databaseConnection).Exec()
 
-	return c.GetTriggers(ctx, triggerType, name, event)
+	return
 }
 
-func (c *client) GetPipelineTriggers(ctx context.Context, build contracts.Build, event string) ([]*contracts.Pipeline, error) {
-
-	triggerType := "pipeline"
-	name := fmt.Sprintf("%v/%v/%v", build.RepoSource, build.RepoOwner, build.RepoName)
+func (c *client) DeleteGroup(ctx context.Context, group contracts.Group) (err error) {
 
-	return c.GetTriggers(ctx, triggerType, name, event)
-}
+	// deactivate group
+	group.Active = false
 
-func (c *client) GetReleaseTriggers(ctx context.Context, release contracts.Release, event string) ([]*contracts.Pipeline, error) {
+	groupBytes, err := json.Marshal(group)
+	if err != nil {
+		return
+	}
 
-	triggerType := "release"
-	name := fmt.Sprintf("%v/%v/%v", release.RepoSource, release.RepoOwner, release.RepoName)
+	groupID, err := strconv.Atoi(group.ID)
+	if err != nil {
+		return
+	}
 
-	return c.GetTriggers(ctx, triggerType, name, event)
-}
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-func (c *client) GetPubSubTriggers(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) ([]*contracts.Pipeline, error) {
+	query := psql.
+		Update("groups").
+		Set("group_data", groupBytes).
+		Set("updated_at", sq.Expr("now()")).
+		Set("active", false).
+		Where(sq.Eq{"id": groupID}).
+		Limit(uint64(1))
 
-	triggerType := "pubsub"
+	_, err = query.RunWith(c.databaseConnection).Exec()
 
-	return c.GetTriggers(ctx, triggerType, "", "")
+	return
 }
 
-func (c *client) GetCronTriggers(ctx context.Context) ([]*contracts.Pipeline, error) {
-
-	triggerType := "cron"
+func (c *client) GetGroupByIdentity(ctx context.Context, identity contracts.GroupIdentity) (group *contracts.Group, err error) {
+	filter := struct {
+		Identities []struct {
+			Provider string `json:"provider"`
+			Name     string `json:"name"`
+		} `json:"identities"`
+	}{
+		[]struct {
+			Provider string `json:"provider"`
+			Name     string `json:"name"`
+		}{
+			{
+				Provider: identity.Provider,
+				Name:     identity.Name,
+			},
+		},
+	}
 
-	return c.GetTriggers(ctx, triggerType, "", "")
-}
+	filterBytes, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
 
-func (c *client) Rename(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) error {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	nrOfQueries := 7
-	var wg sync.WaitGroup
-	wg.Add(nrOfQueries)
+	query := psql.
+		Select("a.id, a.group_data, a.inserted_at, a.active").
+		From("groups a").
+		Where("a.group_data @> ?", string(filterBytes)).
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(1))
 
-	errors := make(chan error, nrOfQueries)
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	group, err = c.scanGroup(row)
+	if err != nil {
+		return nil, err
+	}
 
-	go func(wg *sync.WaitGroup, ctx context.Context, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName string) {
-		defer wg.Done()
-		err := c.RenameBuildVersion(ctx, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName)
-		if err != nil {
-			errors <- err
-		}
-	}(&wg, ctx, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName)
+	return group, nil
+}
 
-	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
-		defer wg.Done()
-		err := c.RenameBuilds(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
-		if err != nil {
-			errors <- err
-		}
-	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+func (c *client) GetGroupByID(ctx context.Context, id string, filters map[api.FilterType][]string) (group *contracts.Group, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
-		defer wg.Done()
-		err := c.RenameBuildLogs(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
-		if err != nil {
-			errors <- err
-		}
-	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+	query := psql.
+		Select("a.id, a.group_data, a.inserted_at, a.active").
+		From("groups a").
+		Where(sq.Eq{"a.id": id}).
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(1))
 
-	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
-		defer wg.Done()
-		err := c.RenameReleases(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
-		if err != nil {
-			errors <- err
-		}
-	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+	query, err = whereClauseGeneratorForOrganizationsInGroupDataFilter(query, "a", filters)
+	if err != nil {
+		return nil, err
+	}
 
-	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
-		defer wg.Done()
-		err := c.RenameReleaseLogs(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
-		if err != nil {
-			errors <- err
-		}
-	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	group, err = c.scanGroup(row)
+	if err != nil {
+		return nil, err
+	}
 
-	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
-		defer wg.Done()
-		err := c.RenameComputedPipelines(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
-		if err != nil {
-			errors <- err
-		}
-	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+	return group, nil
+}
 
-	go func(wg *sync.WaitGroup, ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) {
-		defer wg.Done()
-		err := c.RenameComputedReleases(ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
-		if err != nil {
-			errors <- err
-		}
-	}(&wg, ctx, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName)
+func (c *client) GetGroups(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (groups []*contracts.Group, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
-	wg.Wait()
+	query := psql.
+		Select("a.id, a.group_data, a.inserted_at, a.active").
+		From("groups a").
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(pageSize)).
+		Offset(uint64((pageNumber - 1) * pageSize))
 
-	close(errors)
-	for e := range errors {
-		return e
+	query, err = whereClauseGeneratorForOrganizationsInGroupDataFilter(query, "a", filters)
+	if err != nil {
+		return groups, err
 	}
 
-	// update computed tables
-	err := c.UpsertComputedPipeline(ctx, toRepoSource, toRepoOwner, toRepoName)
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
-
-		return err
+		return
 	}
 
-	return nil
+	return c.scanGroups(rows)
 }
 
-func (c *client) RenameBuildVersion(ctx context.Context, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName string) error {
-
+func (c *client) GetGroupsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("build_versions").
-		Set("repo_source", shortToRepoSource).
-		Set("repo_full_name", fmt.Sprintf("%v/%v", toRepoOwner, toRepoName)).
-		Where(sq.Eq{"repo_source": shortFromRepoSource}).
-		Where(sq.Eq{"repo_full_name": fmt.Sprintf("%v/%v", fromRepoOwner, fromRepoName)}).
-		Limit(uint64(1))
+		Select("COUNT(a.id)").
+		From("groups a").
+		Where(sq.Eq{"a.active": true})
 
-	_, err := query.RunWith(c.databaseConnection).Exec()
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	if err = row.Scan(&count); err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *client) InsertOrganization(ctx context.Context, organization contracts.Organization) (o *contracts.Organization, err error) {
+
+	organizationBytes, err := json.Marshal(organization)
 	if err != nil {
+		return nil, err
+	}
 
-		return err
+	// upsert user
+	row := c.databaseConnection.QueryRow(
+		`
+		INSERT INTO
+		organizations
+		(
+			organization_data
+		)
+		VALUES
+		(
+			$1
+		)
+		RETURNING
+			id
+		`,
+		organizationBytes,
+	)
+
+	o = &organization
+
+	if err = row.Scan(&o.ID); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return
 }
 
-func (c *client) RenameBuilds(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+func (c *client) UpdateOrganization(ctx context.Context, organization contracts.Organization) (err error) {
+	organizationBytes, err := json.Marshal(organization)
+	if err != nil {
+		return
+	}
+
+	organizationID, err := strconv.Atoi(organization.ID)
+	if err != nil {
+		return
+	}
 
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("builds").
-		Set("repo_source", toRepoSource).
-		Set("repo_owner", toRepoOwner).
-		Set("repo_name", toRepoName).
-		Where(sq.Eq{"repo_source": fromRepoSource}).
-		Where(sq.Eq{"repo_owner": fromRepoOwner}).
-		Where(sq.Eq{"repo_name": fromRepoName})
+		Update("organizations").
+		Set("organization_data", organizationBytes).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": organizationID}).
+		Limit(uint64(1))
 
-	_, err := query.RunWith(c.databaseConnection).Exec()
+	_, err = query.RunWith(c.databaseConnection).Exec()
+
+	return
+}
+
+func (c *client) DeleteOrganization(ctx context.Context, organization contracts.Organization) (err error) {
+
+	// deactivate organization
+	organization.Active = false
+
+	organizationBytes, err := json.Marshal(organization)
 	if err != nil {
+		return
+	}
 
-		return err
+	organizationID, err := strconv.Atoi(organization.ID)
+	if err != nil {
+		return
 	}
 
-	return nil
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	query := psql.
+		Update("organizations").
+		Set("organization_data", organizationBytes).
+		Set("updated_at", sq.Expr("now()")).
+		Set("active", false).
+		Where(sq.Eq{"id": organizationID}).
+		Limit(uint64(1))
+
+	_, err = query.RunWith(c.databaseConnection).Exec()
+
+	return
 }
+func (c *client) GetOrganizationByIdentity(ctx context.Context, identity contracts.OrganizationIdentity) (organization *contracts.Organization, err error) {
+	filter := struct {
+		Identities []struct {
+			Provider string `json:"provider"`
+			Name     string `json:"name"`
+		} `json:"identities"`
+	}{
+		[]struct {
+			Provider string `json:"provider"`
+			Name     string `json:"name"`
+		}{
+			{
+				Provider: identity.Provider,
+				Name:     identity.Name,
+			},
+		},
+	}
 
-func (c *client) RenameBuildLogs(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
+	filterBytes, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
 
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("build_logs").
-		Set("repo_source", toRepoSource).
-		Set("repo_owner", toRepoOwner).
-		Set("repo_name", toRepoName).
-		Where(sq.Eq{"repo_source": fromRepoSource}).
-		Where(sq.Eq{"repo_owner": fromRepoOwner}).
-		Where(sq.Eq{"repo_name": fromRepoName})
+		Select("a.id, a.organization_data, a.inserted_at, a.active").
+		From("organizations a").
+		Where("a.organization_data @> ?", string(filterBytes)).
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(1))
 
-	_, err := query.RunWith(c.databaseConnection).Exec()
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	organization, err = c.scanOrganization(row)
 	if err != nil {
-
-		return err
+		return nil, err
 	}
 
-	return nil
+	return organization, nil
 }
 
-func (c *client) RenameReleases(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
-
+func (c *client) GetOrganizationByID(ctx context.Context, id string) (organization *contracts.Organization, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("releases").
-		Set("repo_source", toRepoSource).
-		Set("repo_owner", toRepoOwner).
-		Set("repo_name", toRepoName).
-		Where(sq.Eq{"repo_source": fromRepoSource}).
-		Where(sq.Eq{"repo_owner": fromRepoOwner}).
-		Where(sq.Eq{"repo_name": fromRepoName})
+		Select("a.id, a.organization_data, a.inserted_at, a.active").
+		From("organizations a").
+		Where(sq.Eq{"a.id": id}).
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(1))
 
-	_, err := query.RunWith(c.databaseConnection).Exec()
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	organization, err = c.scanOrganization(row)
 	if err != nil {
-
-		return err
+		return nil, err
 	}
 
-	return nil
+	return organization, nil
 }
 
-func (c *client) RenameReleaseLogs(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
-
+func (c *client) GetOrganizationByName(ctx context.Context, name string) (organization *contracts.Organization, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("release_logs").
-		Set("repo_source", toRepoSource).
-		Set("repo_owner", toRepoOwner).
-		Set("repo_name", toRepoName).
-		Where(sq.Eq{"repo_source": fromRepoSource}).
-		Where(sq.Eq{"repo_owner": fromRepoOwner}).
-		Where(sq.Eq{"repo_name": fromRepoName})
+		Select("a.id, a.organization_data, a.inserted_at, a.active").
+		From("organizations a").
+		Where(sq.Eq{"a.organization_data->>'name'": name}).
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(1))
 
-	_, err := query.RunWith(c.databaseConnection).Exec()
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	organization, err = c.scanOrganization(row)
 	if err != nil {
-
-		return err
+		return nil, err
 	}
 
-	return nil
+	return organization, nil
 }
 
-func (c *client) RenameComputedPipelines(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
-
+func (c *client) GetOrganizations(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (organizations []*contracts.Organization, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("computed_pipelines").
-		Set("repo_source", toRepoSource).
-		Set("repo_owner", toRepoOwner).
-		Set("repo_name", toRepoName).
-		Where(sq.Eq{"repo_source": fromRepoSource}).
-		Where(sq.Eq{"repo_owner": fromRepoOwner}).
-		Where(sq.Eq{"repo_name": fromRepoName})
+		Select("a.id, a.organization_data, a.inserted_at, a.active").
+		From("organizations a").
+		Where(sq.Eq{"a.active": true}).
+		Limit(uint64(pageSize)).
+		Offset(uint64((pageNumber - 1) * pageSize))
 
-	_, err := query.RunWith(c.databaseConnection).Exec()
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
-
-		return err
+		return
 	}
 
-	return nil
+	return c.scanOrganizations(rows)
 }
 
-func (c *client) RenameComputedReleases(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
-
+func (c *client) GetOrganizationsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("computed_releases").
-		Set("repo_source", toRepoSource).
-		Set("repo_owner", toRepoOwner).
-		Set("repo_name", toRepoName).
-		Where(sq.Eq{"repo_source": fromRepoSource}).
-		Where(sq.Eq{"repo_owner": fromRepoOwner}).
-		Where(sq.Eq{"repo_name": fromRepoName})
-
-	_, err := query.RunWith(c.databaseConnection).Exec()
-	if err != nil {
+		Select("COUNT(a.id)").
+		From("organizations a").
+		Where(sq.Eq{"a.active": true})
 
-		return err
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	if err = row.Scan(&count); err != nil {
+		return
 	}
 
-	return nil
+	return
 }
 
-func (c *client) InsertUser(ctx context.Context, user contracts.User) (u *contracts.User, err error) {
+func (c *client) InsertClient(ctx context.Context, client contracts.Client) (cl *contracts.Client, err error) {
 
-	userBytes, err := json.Marshal(user)
+	clientBytes, err := json.Marshal(client)
 	if err != nil {
 		return nil, err
 	}
@@ -4406,9 +5815,9 @@ func (c *client) InsertUser(ctx context.Context, user contracts.User) (u *contra
 	row := c.databaseConnection.QueryRow(
 		`
 		INSERT INTO
-			users
+		clients
 		(
-			user_data
+			client_data
 		)
 		VALUES
 		(
@@ -4417,26 +5826,25 @@ func (c *client) InsertUser(ctx context.Context, user contracts.User) (u *contra
 		RETURNING
 			id
 		`,
-		userBytes,
+		clientBytes,
 	)
 
-	u = &user
+	cl = &client
 
-	if err = row.Scan(&u.ID); err != nil {
+	if err = row.Scan(&cl.ID); err != nil {
 		return nil, err
 	}
 
 	return
 }
 
-func (c *client) UpdateUser(ctx context.Context, user contracts.User) (err error) {
-
-	userBytes, err := json.Marshal(user)
+func (c *client) UpdateClient(ctx context.Context, client contracts.Client) (err error) {
+	clientBytes, err := json.Marshal(client)
 	if err != nil {
 		return
 	}
 
-	userID, err := strconv.Atoi(user.ID)
+	clientID, err := strconv.Atoi(client.ID)
 	if err != nil {
 		return
 	}
@@ -4444,10 +5852,10 @@ func (c *client) UpdateUser(ctx context.Context, user contracts.User) (err error
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("users").
-		Set("user_data", userBytes).
+		Update("clients").
+		Set("client_data", clientBytes).
 		Set("updated_at", sq.Expr("now()")).
-		Where(sq.Eq{"id": userID}).
+		Where(sq.Eq{"id": clientID}).
 		Limit(uint64(1))
 
 	_, err = query.RunWith(c.databaseConnection).Exec()
@@ -4455,17 +5863,17 @@ func (c *client) UpdateUser(ctx context.Context, user contracts.User) (err error
 	return
 }
 
-func (c *client) DeleteUser(ctx context.Context, user contracts.User) (err error) {
+func (c *client) DeleteClient(ctx context.Context, client contracts.Client) (err error) {
 
-	// deactivate user
-	user.Active = false
+	// deactivate client
+	client.Active = false
 
-	userBytes, err := json.Marshal(user)
+	clientBytes, err := json.Marshal(client)
 	if err != nil {
 		return
 	}
 
-	userID, err := strconv.Atoi(user.ID)
+	clientID, err := strconv.Atoi(client.ID)
 	if err != nil {
 		return
 	}
@@ -4473,141 +5881,83 @@ func (c *client) DeleteUser(ctx context.Context, user contracts.User) (err error
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("users").
-		Set("user_data", userBytes).
+		Update("clients").
+		Set("client_data", clientBytes).
 		Set("updated_at", sq.Expr("now()")).
 		Set("active", false).
-		Where(sq.Eq{"id": userID}).
+		Where(sq.Eq{"id": clientID}).
 		Limit(uint64(1))
 
 	_, err = query.RunWith(c.databaseConnection).Exec()
 
 	return
 }
-func (c *client) GetUserByID(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
 
+func (c *client) GetClientByClientID(ctx context.Context, clientID string) (client *contracts.Client, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Select("a.id, a.user_data, a.inserted_at, a.active").
-		From("users a").
-		Where(sq.Eq{"a.id": id}).
+		Select("a.id, a.client_data, a.inserted_at, a.active").
+		From("clients a").
+		Where(sq.Eq{"a.client_data->>'clientID'": clientID}).
 		Where(sq.Eq{"a.active": true}).
 		Limit(uint64(1))
 
-	query, err = whereClauseGeneratorForOrganizationsInUserDataFilter(query, "a", filters)
-	if err != nil {
-		return nil, err
-	}
-
 	// execute query
 	row := query.RunWith(c.databaseConnection).QueryRow()
-	user, err = c.scanUser(row)
+	client, err = c.scanClient(row)
 	if err != nil {
 		return nil, err
 	}
 
-	return user, nil
+	return client, nil
 }
 
-func (c *client) GetUserByIdentity(ctx context.Context, identity contracts.UserIdentity) (user *contracts.User, err error) {
-
-	filter := struct {
-		Identities []struct {
-			Provider string `json:"provider"`
-			Email    string `json:"email"`
-		} `json:"identities"`
-	}{
-		[]struct {
-			Provider string `json:"provider"`
-			Email    string `json:"email"`
-		}{
-			{
-				Provider: identity.Provider,
-				Email:    identity.Email,
-			},
-		},
-	}
-
-	filterBytes, err := json.Marshal(filter)
-	if err != nil {
-		return nil, err
-	}
-
+func (c *client) GetClientByID(ctx context.Context, id string) (client *contracts.Client, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Select("a.id, a.user_data, a.inserted_at, a.active").
-		From("users a").
-		Where("a.user_data @> ?", string(filterBytes)).
+		Select("a.id, a.client_data, a.inserted_at, a.active").
+		From("clients a").
+		Where(sq.Eq{"a.id": id}).
 		Where(sq.Eq{"a.active": true}).
 		Limit(uint64(1))
 
 	// execute query
 	row := query.RunWith(c.databaseConnection).QueryRow()
-	user, err = c.scanUser(row)
+	client, err = c.scanClient(row)
 	if err != nil {
 		return nil, err
 	}
 
-	return user, nil
+	return client, nil
 }
 
-func (c *client) GetUsers(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (users []*contracts.User, err error) {
+func (c *client) GetClients(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (clients []*contracts.Client, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Select("a.id, a.user_data, a.inserted_at, a.active").
-		From("users a").
+		Select("a.id, a.client_data, a.inserted_at, a.active").
+		From("clients a").
 		Where(sq.Eq{"a.active": true}).
 		Limit(uint64(pageSize)).
 		Offset(uint64((pageNumber - 1) * pageSize))
 
-	// wait for https://github.com/cockroachdb/cockroach/issues/35706 to be implemented for sorting jsonb fields
-
-	// // fix sortings for fields inside the user_data jsonb object
-	// fixedSortings := []api.OrderField{}
-	// for _, s := range sortings {
-	// 	fieldName := s.FieldName
-	// 	direction := s.Direction
-
-	// 	switch s.FieldName {
-	// 	case "name":
-	// 		fieldName = "user_data-->'name'"
-	// 	case "email":
-	// 		fieldName = "user_data-->'email'"
-	// 	}
-
-	// 	fixedSortings = append(fixedSortings, api.OrderField{
-	// 		FieldName: fieldName,
-	// 		Direction: direction,
-	// 	})
-	// }
-
-	// // dynamically set order by clause
-	// query, err = orderByClauseGeneratorForSortings(query, "a", "a.user_data-->'name'", fixedSortings)
-	// if err != nil {
-	// 	return
-	// }
-
-	query, err = whereClauseGeneratorForUserFilters(query, "a", filters)
-
 	// execute query
 	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
 		return
 	}
 
-	return c.scanUsers(rows)
+	return c.scanClients(rows)
 }
 
-func (c *client) GetUsersCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
-
+func (c *client) GetClientsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
 		Select("COUNT(a.id)").
-		From("users a").
+		From("clients a").
 		Where(sq.Eq{"a.active": true})
 
 	// execute query
@@ -4619,47 +5969,53 @@ func (c *client) GetUsersCount(ctx context.Context, filters map[api.FilterType][
 	return
 }
 
-func (c *client) InsertGroup(ctx context.Context, group contracts.Group) (g *contracts.Group, err error) {
+func (c *client) InsertPersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
 
-	groupBytes, err := json.Marshal(group)
+	// never store the signed token itself
+	token.Value = ""
+
+	tokenBytes, err := json.Marshal(token)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := strconv.Atoi(token.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	// upsert user
 	row := c.databaseConnection.QueryRow(
 		`
 		INSERT INTO
-			groups
+			personal_access_tokens
 		(
-			group_data
+			user_id,
+			token_data
 		)
 		VALUES
 		(
-			$1
+			$1,
+			$2
 		)
 		RETURNING
-			id
+			id, inserted_at
 		`,
-		groupBytes,
+		userID,
+		tokenBytes,
 	)
 
-	g = &group
+	insertedToken = &token
 
-	if err = row.Scan(&g.ID); err != nil {
+	if err = row.Scan(&insertedToken.ID, &insertedToken.InsertedAt); err != nil {
 		return nil, err
 	}
 
 	return
 }
 
-func (c *client) UpdateGroup(ctx context.Context, group contracts.Group) (err error) {
-	groupBytes, err := json.Marshal(group)
-	if err != nil {
-		return
-	}
+func (c *client) UpdatePersonalAccessTokenLastUsed(ctx context.Context, id, lastUsedFrom string) (err error) {
 
-	groupID, err := strconv.Atoi(group.ID)
+	tokenID, err := strconv.Atoi(id)
 	if err != nil {
 		return
 	}
@@ -4667,10 +6023,10 @@ func (c *client) UpdateGroup(ctx context.Context, group contracts.Group) (err er
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("groups").
-		Set("group_data", groupBytes).
-		Set("updated_at", sq.Expr("now()")).
-		Where(sq.Eq{"id": groupID}).
+		Update("personal_access_tokens").
+		Set("last_used_at", sq.Expr("now()")).
+		Set("last_used_from", lastUsedFrom).
+		Where(sq.Eq{"id": tokenID}).
 		Limit(uint64(1))
 
 	_, err = query.RunWith(c.databaseConnection).Exec()
@@ -4678,17 +6034,18 @@ func (c *client) UpdateGroup(ctx context.Context, group contracts.Group) (err er
 	return
 }
 
-func (c *client) DeleteGroup(ctx context.Context, group contracts.Group) (err error) {
+func (c *client) RevokePersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (err error) {
 
-	// deactivate group
-	group.Active = false
+	// deactivate token
+	token.Active = false
+	token.Value = ""
 
-	groupBytes, err := json.Marshal(group)
+	tokenBytes, err := json.Marshal(token)
 	if err != nil {
 		return
 	}
 
-	groupID, err := strconv.Atoi(group.ID)
+	tokenID, err := strconv.Atoi(token.ID)
 	if err != nil {
 		return
 	}
@@ -4696,11 +6053,11 @@ func (c *client) DeleteGroup(ctx context.Context, group contracts.Group) (err er
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("groups").
-		Set("group_data", groupBytes).
+		Update("personal_access_tokens").
+		Set("token_data", tokenBytes).
 		Set("updated_at", sq.Expr("now()")).
 		Set("active", false).
-		Where(sq.Eq{"id": groupID}).
+		Where(sq.Eq{"id": tokenID}).
 		Limit(uint64(1))
 
 	_, err = query.RunWith(c.databaseConnection).Exec()
@@ -4708,86 +6065,39 @@ func (c *client) DeleteGroup(ctx context.Context, group contracts.Group) (err er
 	return
 }
 
-func (c *client) GetGroupByIdentity(ctx context.Context, identity contracts.GroupIdentity) (group *contracts.Group, err error) {
-	filter := struct {
-		Identities []struct {
-			Provider string `json:"provider"`
-			Name     string `json:"name"`
-		} `json:"identities"`
-	}{
-		[]struct {
-			Provider string `json:"provider"`
-			Name     string `json:"name"`
-		}{
-			{
-				Provider: identity.Provider,
-				Name:     identity.Name,
-			},
-		},
-	}
-
-	filterBytes, err := json.Marshal(filter)
-	if err != nil {
-		return nil, err
-	}
-
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	query := psql.
-		Select("a.id, a.group_data, a.inserted_at, a.active").
-		From("groups a").
-		Where("a.group_data @> ?", string(filterBytes)).
-		Where(sq.Eq{"a.active": true}).
-		Limit(uint64(1))
-
-	// execute query
-	row := query.RunWith(c.databaseConnection).QueryRow()
-	group, err = c.scanGroup(row)
-	if err != nil {
-		return nil, err
-	}
-
-	return group, nil
-}
-
-func (c *client) GetGroupByID(ctx context.Context, id string, filters map[api.FilterType][]string) (group *contracts.Group, err error) {
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+func (c *client) GetPersonalAccessTokenByID(ctx context.Context, id string) (token *api.PersonalAccessToken, err error) {
 
-	query := psql.
-		Select("a.id, a.group_data, a.inserted_at, a.active").
-		From("groups a").
+	query := c.selectPersonalAccessTokensQuery().
 		Where(sq.Eq{"a.id": id}).
-		Where(sq.Eq{"a.active": true}).
 		Limit(uint64(1))
 
-	query, err = whereClauseGeneratorForOrganizationsInGroupDataFilter(query, "a", filters)
-	if err != nil {
-		return nil, err
-	}
-
 	// execute query
 	row := query.RunWith(c.databaseConnection).QueryRow()
-	group, err = c.scanGroup(row)
+	token, err = c.scanPersonalAccessToken(row)
 	if err != nil {
 		return nil, err
 	}
 
-	return group, nil
+	return token, nil
 }
 
-func (c *client) GetGroups(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (groups []*contracts.Group, err error) {
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+func (c *client) GetPersonalAccessTokens(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
 
-	query := psql.
-		Select("a.id, a.group_data, a.inserted_at, a.active").
-		From("groups a").
-		Where(sq.Eq{"a.active": true}).
+	// revoked tokens are returned as well to keep an audit trail
+	query := c.selectPersonalAccessTokensQuery().
+		Where(sq.Eq{"a.user_id": userID}).
 		Limit(uint64(pageSize)).
 		Offset(uint64((pageNumber - 1) * pageSize))
 
-	query, err = whereClauseGeneratorForOrganizationsInGroupDataFilter(query, "a", filters)
+	query, err = whereClauseGeneratorForPersonalAccessTokenFilters(query, "a", filters)
 	if err != nil {
-		return groups, err
+		return
+	}
+
+	// dynamically set order by clause
+	query, err = orderByClauseGeneratorForSortings(query, "a", "a.inserted_at DESC", sortings)
+	if err != nil {
+		return
 	}
 
 	// execute query
@@ -4796,16 +6106,21 @@ func (c *client) GetGroups(ctx context.Context, pageNumber, pageSize int, filter
 		return
 	}
 
-	return c.scanGroups(rows)
+	return c.scanPersonalAccessTokens(rows)
 }
 
-func (c *client) GetGroupsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+func (c *client) GetPersonalAccessTokensCount(ctx context.Context, userID string, filters map[api.FilterType][]string) (count int, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
 		Select("COUNT(a.id)").
-		From("groups a").
-		Where(sq.Eq{"a.active": true})
+		From("personal_access_tokens a").
+		Where(sq.Eq{"a.user_id": userID})
+
+	query, err = whereClauseGeneratorForPersonalAccessTokenFilters(query, "a", filters)
+	if err != nil {
+		return
+	}
 
 	// execute query
 	row := query.RunWith(c.databaseConnection).QueryRow()
@@ -4816,47 +6131,51 @@ func (c *client) GetGroupsCount(ctx context.Context, filters map[api.FilterType]
 	return
 }
 
-func (c *client) InsertOrganization(ctx context.Context, organization contracts.Organization) (o *contracts.Organization, err error) {
+func (c *client) InsertCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
 
-	organizationBytes, err := json.Marshal(organization)
+	customRole.Active = true
+
+	customRoleBytes, err := json.Marshal(customRole)
 	if err != nil {
 		return nil, err
 	}
 
-	// upsert user
 	row := c.databaseConnection.QueryRow(
 		`
 		INSERT INTO
-		organizations
+			custom_roles
 		(
-			organization_data
+			name,
+			role_data
 		)
 		VALUES
 		(
-			$1
+			$1,
+			$2
 		)
 		RETURNING
-			id
+			id, inserted_at
 		`,
-		organizationBytes,
+		customRole.Name,
+		customRoleBytes,
 	)
 
-	o = &organization
+	insertedCustomRole = &customRole
 
-	if err = row.Scan(&o.ID); err != nil {
+	if err = row.Scan(&insertedCustomRole.ID, &insertedCustomRole.InsertedAt); err != nil {
 		return nil, err
 	}
 
 	return
 }
 
-func (c *client) UpdateOrganization(ctx context.Context, organization contracts.Organization) (err error) {
-	organizationBytes, err := json.Marshal(organization)
+func (c *client) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	customRoleBytes, err := json.Marshal(customRole)
 	if err != nil {
 		return
 	}
 
-	organizationID, err := strconv.Atoi(organization.ID)
+	customRoleID, err := strconv.Atoi(customRole.ID)
 	if err != nil {
 		return
 	}
@@ -4864,10 +6183,11 @@ func (c *client) UpdateOrganization(ctx context.Context, organization contracts.
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("organizations").
-		Set("organization_data", organizationBytes).
+		Update("custom_roles").
+		Set("name", customRole.Name).
+		Set("role_data", customRoleBytes).
 		Set("updated_at", sq.Expr("now()")).
-		Where(sq.Eq{"id": organizationID}).
+		Where(sq.Eq{"id": customRoleID}).
 		Limit(uint64(1))
 
 	_, err = query.RunWith(c.databaseConnection).Exec()
@@ -4875,17 +6195,17 @@ func (c *client) UpdateOrganization(ctx context.Context, organization contracts.
 	return
 }
 
-func (c *client) DeleteOrganization(ctx context.Context, organization contracts.Organization) (err error) {
+func (c *client) DeleteCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
 
-	// deactivate organization
-	organization.Active = false
+	// deactivate custom role
+	customRole.Active = false
 
-	organizationBytes, err := json.Marshal(organization)
+	customRoleBytes, err := json.Marshal(customRole)
 	if err != nil {
 		return
 	}
 
-	organizationID, err := strconv.Atoi(organization.ID)
+	customRoleID, err := strconv.Atoi(customRole.ID)
 	if err != nil {
 		return
 	}
@@ -4893,106 +6213,59 @@ func (c *client) DeleteOrganization(ctx context.Context, organization contracts.
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("organizations").
-		Set("organization_data", organizationBytes).
+		Update("custom_roles").
+		Set("role_data", customRoleBytes).
 		Set("updated_at", sq.Expr("now()")).
 		Set("active", false).
-		Where(sq.Eq{"id": organizationID}).
+		Where(sq.Eq{"id": customRoleID}).
 		Limit(uint64(1))
 
 	_, err = query.RunWith(c.databaseConnection).Exec()
 
 	return
 }
-func (c *client) GetOrganizationByIdentity(ctx context.Context, identity contracts.OrganizationIdentity) (organization *contracts.Organization, err error) {
-	filter := struct {
-		Identities []struct {
-			Provider string `json:"provider"`
-			Name     string `json:"name"`
-		} `json:"identities"`
-	}{
-		[]struct {
-			Provider string `json:"provider"`
-			Name     string `json:"name"`
-		}{
-			{
-				Provider: identity.Provider,
-				Name:     identity.Name,
-			},
-		},
-	}
 
-	filterBytes, err := json.Marshal(filter)
-	if err != nil {
-		return nil, err
-	}
+func (c *client) GetCustomRoleByID(ctx context.Context, id string) (customRole *api.CustomRole, err error) {
 
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	query := psql.
-		Select("a.id, a.organization_data, a.inserted_at, a.active").
-		From("organizations a").
-		Where("a.organization_data @> ?", string(filterBytes)).
+	query := c.selectCustomRolesQuery().
+		Where(sq.Eq{"a.id": id}).
 		Where(sq.Eq{"a.active": true}).
 		Limit(uint64(1))
 
 	// execute query
 	row := query.RunWith(c.databaseConnection).QueryRow()
-	organization, err = c.scanOrganization(row)
+	customRole, err = c.scanCustomRole(row)
 	if err != nil {
 		return nil, err
 	}
 
-	return organization, nil
+	return customRole, nil
 }
 
-func (c *client) GetOrganizationByID(ctx context.Context, id string) (organization *contracts.Organization, err error) {
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	query := psql.
-		Select("a.id, a.organization_data, a.inserted_at, a.active").
-		From("organizations a").
-		Where(sq.Eq{"a.id": id}).
-		Where(sq.Eq{"a.active": true}).
-		Limit(uint64(1))
+func (c *client) GetCustomRolesByNames(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error) {
 
-	// execute query
-	row := query.RunWith(c.databaseConnection).QueryRow()
-	organization, err = c.scanOrganization(row)
-	if err != nil {
-		return nil, err
+	if len(names) == 0 {
+		return []*api.CustomRole{}, nil
 	}
 
-	return organization, nil
-}
-
-func (c *client) GetOrganizationByName(ctx context.Context, name string) (organization *contracts.Organization, err error) {
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-
-	query := psql.
-		Select("a.id, a.organization_data, a.inserted_at, a.active").
-		From("organizations a").
-		Where(sq.Eq{"a.organization_data->>'name'": name}).
-		Where(sq.Eq{"a.active": true}).
-		Limit(uint64(1))
+	query := c.selectCustomRolesQuery().
+		Where(sq.Eq{"a.name": names}).
+		Where(sq.Eq{"a.active": true})
 
 	// execute query
-	row := query.RunWith(c.databaseConnection).QueryRow()
-	organization, err = c.scanOrganization(row)
+	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
-		return nil, err
+		return
 	}
 
-	return organization, nil
+	return c.scanCustomRoles(rows)
 }
 
-func (c *client) GetOrganizations(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (organizations []*contracts.Organization, err error) {
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+func (c *client) GetCustomRoles(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (customRoles []*api.CustomRole, err error) {
 
-	query := psql.
-		Select("a.id, a.organization_data, a.inserted_at, a.active").
-		From("organizations a").
+	query := c.selectCustomRolesQuery().
 		Where(sq.Eq{"a.active": true}).
+		OrderBy("a.name").
 		Limit(uint64(pageSize)).
 		Offset(uint64((pageNumber - 1) * pageSize))
 
@@ -5002,15 +6275,15 @@ func (c *client) GetOrganizations(ctx context.Context, pageNumber, pageSize int,
 		return
 	}
 
-	return c.scanOrganizations(rows)
+	return c.scanCustomRoles(rows)
 }
 
-func (c *client) GetOrganizationsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+func (c *client) GetCustomRolesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
 		Select("COUNT(a.id)").
-		From("organizations a").
+		From("custom_roles a").
 		Where(sq.Eq{"a.active": true})
 
 	// execute query
@@ -5022,88 +6295,257 @@ func (c *client) GetOrganizationsCount(ctx context.Context, filters map[api.Filt
 	return
 }
 
-func (c *client) InsertClient(ctx context.Context, client contracts.Client) (cl *contracts.Client, err error) {
+func (c *client) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
 
-	clientBytes, err := json.Marshal(client)
+	auditEventBytes, err := json.Marshal(auditEvent)
 	if err != nil {
 		return nil, err
 	}
 
-	// upsert user
+	// audit events are append-only, so there's no update or delete counterpart
 	row := c.databaseConnection.QueryRow(
 		`
 		INSERT INTO
-		clients
+			audit_events
 		(
-			client_data
+			actor_id,
+			actor_email,
+			action,
+			target_type,
+			target_id,
+			event_data
 		)
 		VALUES
 		(
-			$1
+			$1,
+			$2,
+			$3,
+			$4,
+			$5,
+			$6
 		)
 		RETURNING
-			id
+			id, inserted_at
 		`,
-		clientBytes,
+		auditEvent.ActorID,
+		auditEvent.ActorEmail,
+		auditEvent.Action,
+		auditEvent.TargetType,
+		auditEvent.TargetID,
+		auditEventBytes,
 	)
 
-	cl = &client
+	insertedAuditEvent = &auditEvent
 
-	if err = row.Scan(&cl.ID); err != nil {
+	if err = row.Scan(&insertedAuditEvent.ID, &insertedAuditEvent.InsertedAt); err != nil {
 		return nil, err
 	}
 
 	return
 }
 
-func (c *client) UpdateClient(ctx context.Context, client contracts.Client) (err error) {
-	clientBytes, err := json.Marshal(client)
+func (c *client) GetAuditEvents(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (auditEvents []*api.AuditEvent, err error) {
+
+	query, err := pagingClauseGenerator(c.selectAuditEventsQuery(), "a", "a.inserted_at DESC", []string{"inserted_at", "id"}, "DESC", pageNumber, pageSize, nil, filters)
 	if err != nil {
 		return
 	}
 
-	clientID, err := strconv.Atoi(client.ID)
+	query, err = whereClauseGeneratorForAuditEventFilters(query, "a", filters)
+	if err != nil {
+		return
+	}
+
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
 		return
 	}
 
+	return c.scanAuditEvents(rows)
+}
+
+func (c *client) GetAuditEventsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Update("clients").
-		Set("client_data", clientBytes).
-		Set("updated_at", sq.Expr("now()")).
-		Where(sq.Eq{"id": clientID}).
-		Limit(uint64(1))
+		Select("COUNT(a.id)").
+		From("audit_events a")
 
-	_, err = query.RunWith(c.databaseConnection).Exec()
+	query, err = whereClauseGeneratorForAuditEventFilters(query, "a", filters)
+	if err != nil {
+		return
+	}
+
+	// execute query
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	if err = row.Scan(&count); err != nil {
+		return
+	}
 
 	return
 }
 
-func (c *client) DeleteClient(ctx context.Context, client contracts.Client) (err error) {
+// InsertPipelineWarning records a warning for the pipeline; a warning recorded before with the same status and message gets its timestamp refreshed instead, so warnings raised on every reconciliation don't pile up
+func (c *client) InsertPipelineWarning(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
 
-	// deactivate client
-	client.Active = false
+	result, err := c.databaseConnection.Exec(
+		`
+		UPDATE
+			pipeline_warnings
+		SET
+			inserted_at = now()
+		WHERE
+			repo_source = $1 AND
+			repo_owner = $2 AND
+			repo_name = $3 AND
+			status = $4 AND
+			message = $5
+		`,
+		repoSource,
+		repoOwner,
+		repoName,
+		warning.Status,
+		warning.Message,
+	)
+	if err != nil {
+		return
+	}
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		return nil
+	}
 
-	clientBytes, err := json.Marshal(client)
+	_, err = c.databaseConnection.Exec(
+		`
+		INSERT INTO
+			pipeline_warnings
+		(
+			repo_source,
+			repo_owner,
+			repo_name,
+			status,
+			message
+		)
+		VALUES
+		(
+			$1,
+			$2,
+			$3,
+			$4,
+			$5
+		)
+		`,
+		repoSource,
+		repoOwner,
+		repoName,
+		warning.Status,
+		warning.Message,
+	)
+
+	return
+}
+
+func (c *client) GetPipelineWarnings(ctx context.Context, repoSource, repoOwner, repoName string, since time.Time) (warnings []contracts.Warning, err error) {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	// return each distinct message once, most recent first
+	query := psql.
+		Select("w.status, w.message, MAX(w.inserted_at) AS last_inserted_at").
+		From("pipeline_warnings w").
+		Where(sq.Eq{"w.repo_source": repoSource}).
+		Where(sq.Eq{"w.repo_owner": repoOwner}).
+		Where(sq.Eq{"w.repo_name": repoName}).
+		Where(sq.GtOrEq{"w.inserted_at": since}).
+		GroupBy("w.status, w.message").
+		OrderBy("last_inserted_at DESC")
+
+	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
 		return
 	}
 
-	clientID, err := strconv.Atoi(client.ID)
+	defer rows.Close()
+
+	warnings = make([]contracts.Warning, 0)
+	for rows.Next() {
+		warning := contracts.Warning{}
+		var lastInsertedAt time.Time
+
+		if err = rows.Scan(&warning.Status, &warning.Message, &lastInsertedAt); err != nil {
+			return
+		}
+
+		warnings = append(warnings, warning)
+	}
+
+	return
+}
+
+func (c *client) InsertWebhook(ctx context.Context, webhook api.Webhook) (insertedWebhook *api.Webhook, err error) {
+
+	webhook.Active = true
+
+	// never store the secret token itself, only its hash
+	webhook.Token = ""
+
+	webhookBytes, err := json.Marshal(webhook)
+	if err != nil {
+		return nil, err
+	}
+
+	row := c.databaseConnection.QueryRow(
+		`
+		INSERT INTO
+			webhooks
+		(
+			repo_source,
+			repo_owner,
+			repo_name,
+			token_hash,
+			webhook_data
+		)
+		VALUES
+		(
+			$1,
+			$2,
+			$3,
+			$4,
+			$5
+		)
+		RETURNING
+			id, inserted_at
+		`,
+		webhook.RepoSource,
+		webhook.RepoOwner,
+		webhook.RepoName,
+		webhook.TokenHash,
+		webhookBytes,
+	)
+
+	insertedWebhook = &webhook
+
+	if err = row.Scan(&insertedWebhook.ID, &insertedWebhook.InsertedAt); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (c *client) DeleteWebhook(ctx context.Context, webhook api.Webhook) (err error) {
+
+	webhookID, err := strconv.Atoi(webhook.ID)
 	if err != nil {
 		return
 	}
 
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+	// deactivate instead of delete to keep the delivery history intact
 	query := psql.
-		Update("clients").
-		Set("client_data", clientBytes).
-		Set("updated_at", sq.Expr("now()")).
+		Update("webhooks").
 		Set("active", false).
-		Where(sq.Eq{"id": clientID}).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": webhookID}).
 		Limit(uint64(1))
 
 	_, err = query.RunWith(c.databaseConnection).Exec()
@@ -5111,53 +6553,103 @@ func (c *client) DeleteClient(ctx context.Context, client contracts.Client) (err
 	return
 }
 
-func (c *client) GetClientByClientID(ctx context.Context, clientID string) (client *contracts.Client, err error) {
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+func (c *client) GetWebhookByID(ctx context.Context, id string) (webhook *api.Webhook, err error) {
 
-	query := psql.
-		Select("a.id, a.client_data, a.inserted_at, a.active").
-		From("clients a").
-		Where(sq.Eq{"a.client_data->>'clientID'": clientID}).
-		Where(sq.Eq{"a.active": true}).
+	query := c.selectWebhooksQuery().
+		Where(sq.Eq{"w.id": id}).
 		Limit(uint64(1))
 
 	// execute query
-	row := query.RunWith(c.databaseConnection).QueryRow()
-	client, err = c.scanClient(row)
+	row := query.RunWith(c.databaseConnection).QueryRow()
+	webhook, err = c.scanWebhook(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (c *client) GetPipelineWebhooks(ctx context.Context, repoSource, repoOwner, repoName string) (webhooks []*api.Webhook, err error) {
+
+	query := c.selectWebhooksQuery().
+		Where(sq.Eq{"w.repo_source": repoSource}).
+		Where(sq.Eq{"w.repo_owner": repoOwner}).
+		Where(sq.Eq{"w.repo_name": repoName}).
+		Where(sq.Eq{"w.active": true}).
+		OrderBy("w.inserted_at DESC")
+
+	// execute query
+	rows, err := query.RunWith(c.databaseConnection).Query()
 	if err != nil {
-		return nil, err
+		return
 	}
 
-	return client, nil
+	return c.scanWebhooks(rows)
 }
 
-func (c *client) GetClientByID(ctx context.Context, id string) (client *contracts.Client, err error) {
-	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+func (c *client) InsertWebhookDelivery(ctx context.Context, delivery api.WebhookDelivery) (insertedDelivery *api.WebhookDelivery, err error) {
 
-	query := psql.
-		Select("a.id, a.client_data, a.inserted_at, a.active").
-		From("clients a").
-		Where(sq.Eq{"a.id": id}).
-		Where(sq.Eq{"a.active": true}).
-		Limit(uint64(1))
+	deliveryBytes, err := json.Marshal(delivery)
+	if err != nil {
+		return nil, err
+	}
 
-	// execute query
-	row := query.RunWith(c.databaseConnection).QueryRow()
-	client, err = c.scanClient(row)
+	webhookID, err := strconv.Atoi(delivery.WebhookID)
 	if err != nil {
 		return nil, err
 	}
 
-	return client, nil
+	row := c.databaseConnection.QueryRow(
+		`
+		INSERT INTO
+			webhook_deliveries
+		(
+			webhook_id,
+			repo_source,
+			repo_owner,
+			repo_name,
+			status,
+			delivery_data
+		)
+		VALUES
+		(
+			$1,
+			$2,
+			$3,
+			$4,
+			$5,
+			$6
+		)
+		RETURNING
+			id, inserted_at
+		`,
+		webhookID,
+		delivery.RepoSource,
+		delivery.RepoOwner,
+		delivery.RepoName,
+		delivery.Status,
+		deliveryBytes,
+	)
+
+	insertedDelivery = &delivery
+
+	if err = row.Scan(&insertedDelivery.ID, &insertedDelivery.InsertedAt); err != nil {
+		return nil, err
+	}
+
+	return
 }
 
-func (c *client) GetClients(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (clients []*contracts.Client, err error) {
+func (c *client) GetPipelineWebhookDeliveries(ctx context.Context, repoSource, repoOwner, repoName string, pageNumber, pageSize int) (deliveries []*api.WebhookDelivery, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Select("a.id, a.client_data, a.inserted_at, a.active").
-		From("clients a").
-		Where(sq.Eq{"a.active": true}).
+		Select("d.id, d.webhook_id, d.status, d.delivery_data, d.inserted_at").
+		From("webhook_deliveries d").
+		Where(sq.Eq{"d.repo_source": repoSource}).
+		Where(sq.Eq{"d.repo_owner": repoOwner}).
+		Where(sq.Eq{"d.repo_name": repoName}).
+		OrderBy("d.inserted_at DESC").
 		Limit(uint64(pageSize)).
 		Offset(uint64((pageNumber - 1) * pageSize))
 
@@ -5167,16 +6659,18 @@ func (c *client) GetClients(ctx context.Context, pageNumber, pageSize int, filte
 		return
 	}
 
-	return c.scanClients(rows)
+	return c.scanWebhookDeliveries(rows)
 }
 
-func (c *client) GetClientsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+func (c *client) GetPipelineWebhookDeliveriesCount(ctx context.Context, repoSource, repoOwner, repoName string) (count int, err error) {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
 	query := psql.
-		Select("COUNT(a.id)").
-		From("clients a").
-		Where(sq.Eq{"a.active": true})
+		Select("COUNT(d.id)").
+		From("webhook_deliveries d").
+		Where(sq.Eq{"d.repo_source": repoSource}).
+		Where(sq.Eq{"d.repo_owner": repoOwner}).
+		Where(sq.Eq{"d.repo_name": repoName})
 
 	// execute query
 	row := query.RunWith(c.databaseConnection).QueryRow()
@@ -5759,6 +7253,297 @@ func (c *client) scanClient(row sq.RowScanner) (client *contracts.Client, err er
 	return
 }
 
+func (c *client) scanPersonalAccessTokens(rows *sql.Rows) (tokens []*api.PersonalAccessToken, err error) {
+	tokens = make([]*api.PersonalAccessToken, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		token := &api.PersonalAccessToken{}
+		var id, userID string
+		var tokenData []uint8
+		var insertedAt, lastUsedAt *time.Time
+		var lastUsedFrom sql.NullString
+
+		if err = rows.Scan(
+			&id,
+			&userID,
+			&tokenData,
+			&insertedAt,
+			&lastUsedAt,
+			&lastUsedFrom,
+			&token.Active); err != nil {
+			return
+		}
+
+		if len(tokenData) > 0 {
+			if err = json.Unmarshal(tokenData, &token); err != nil {
+				return nil, err
+			}
+		}
+
+		token.ID = id
+		token.UserID = userID
+		token.InsertedAt = insertedAt
+		token.LastUsedAt = lastUsedAt
+		token.LastUsedFrom = lastUsedFrom.String
+
+		tokens = append(tokens, token)
+	}
+
+	return
+}
+
+func (c *client) scanPersonalAccessToken(row sq.RowScanner) (token *api.PersonalAccessToken, err error) {
+
+	token = &api.PersonalAccessToken{}
+	var id, userID string
+	var tokenData []uint8
+	var insertedAt, lastUsedAt *time.Time
+	var lastUsedFrom sql.NullString
+
+	if err = row.Scan(
+		&id,
+		&userID,
+		&tokenData,
+		&insertedAt,
+		&lastUsedAt,
+		&lastUsedFrom,
+		&token.Active); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPersonalAccessTokenNotFound
+		}
+
+		return
+	}
+
+	if len(tokenData) > 0 {
+		if err = json.Unmarshal(tokenData, &token); err != nil {
+			return nil, err
+		}
+	}
+
+	token.ID = id
+	token.UserID = userID
+	token.InsertedAt = insertedAt
+	token.LastUsedAt = lastUsedAt
+	token.LastUsedFrom = lastUsedFrom.String
+
+	return
+}
+
+func (c *client) scanWebhooks(rows *sql.Rows) (webhooks []*api.Webhook, err error) {
+	webhooks = make([]*api.Webhook, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		webhook := &api.Webhook{}
+		var id, tokenHash string
+		var webhookData []uint8
+		var insertedAt *time.Time
+
+		if err = rows.Scan(
+			&id,
+			&tokenHash,
+			&webhookData,
+			&insertedAt,
+			&webhook.Active); err != nil {
+			return
+		}
+
+		if len(webhookData) > 0 {
+			if err = json.Unmarshal(webhookData, &webhook); err != nil {
+				return nil, err
+			}
+		}
+
+		webhook.ID = id
+		webhook.TokenHash = tokenHash
+		webhook.InsertedAt = insertedAt
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return
+}
+
+func (c *client) scanWebhook(row sq.RowScanner) (webhook *api.Webhook, err error) {
+
+	webhook = &api.Webhook{}
+	var id, tokenHash string
+	var webhookData []uint8
+	var insertedAt *time.Time
+
+	if err = row.Scan(
+		&id,
+		&tokenHash,
+		&webhookData,
+		&insertedAt,
+		&webhook.Active); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWebhookNotFound
+		}
+
+		return
+	}
+
+	if len(webhookData) > 0 {
+		if err = json.Unmarshal(webhookData, &webhook); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook.ID = id
+	webhook.TokenHash = tokenHash
+	webhook.InsertedAt = insertedAt
+
+	return
+}
+
+func (c *client) scanWebhookDeliveries(rows *sql.Rows) (deliveries []*api.WebhookDelivery, err error) {
+	deliveries = make([]*api.WebhookDelivery, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		delivery := &api.WebhookDelivery{}
+		var id, webhookID, status string
+		var deliveryData []uint8
+		var insertedAt *time.Time
+
+		if err = rows.Scan(
+			&id,
+			&webhookID,
+			&status,
+			&deliveryData,
+			&insertedAt); err != nil {
+			return
+		}
+
+		if len(deliveryData) > 0 {
+			if err = json.Unmarshal(deliveryData, &delivery); err != nil {
+				return nil, err
+			}
+		}
+
+		delivery.ID = id
+		delivery.WebhookID = webhookID
+		delivery.Status = status
+		delivery.InsertedAt = insertedAt
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return
+}
+
+func (c *client) scanCustomRoles(rows *sql.Rows) (customRoles []*api.CustomRole, err error) {
+	customRoles = make([]*api.CustomRole, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		customRole := &api.CustomRole{}
+		var id, name string
+		var roleData []uint8
+		var insertedAt, updatedAt *time.Time
+
+		if err = rows.Scan(
+			&id,
+			&name,
+			&roleData,
+			&insertedAt,
+			&updatedAt,
+			&customRole.Active); err != nil {
+			return
+		}
+
+		if len(roleData) > 0 {
+			if err = json.Unmarshal(roleData, &customRole); err != nil {
+				return nil, err
+			}
+		}
+
+		customRole.ID = id
+		customRole.Name = name
+		customRole.InsertedAt = insertedAt
+		customRole.UpdatedAt = updatedAt
+
+		customRoles = append(customRoles, customRole)
+	}
+
+	return
+}
+
+func (c *client) scanCustomRole(row sq.RowScanner) (customRole *api.CustomRole, err error) {
+
+	customRole = &api.CustomRole{}
+	var id, name string
+	var roleData []uint8
+	var insertedAt, updatedAt *time.Time
+
+	if err = row.Scan(
+		&id,
+		&name,
+		&roleData,
+		&insertedAt,
+		&updatedAt,
+		&customRole.Active); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCustomRoleNotFound
+		}
+
+		return
+	}
+
+	if len(roleData) > 0 {
+		if err = json.Unmarshal(roleData, &customRole); err != nil {
+			return nil, err
+		}
+	}
+
+	customRole.ID = id
+	customRole.Name = name
+	customRole.InsertedAt = insertedAt
+	customRole.UpdatedAt = updatedAt
+
+	return
+}
+
+func (c *client) scanAuditEvents(rows *sql.Rows) (auditEvents []*api.AuditEvent, err error) {
+	auditEvents = make([]*api.AuditEvent, 0)
+
+	defer rows.Close()
+	for rows.Next() {
+
+		auditEvent := &api.AuditEvent{}
+		var id string
+		var eventData []uint8
+		var insertedAt *time.Time
+
+		if err = rows.Scan(
+			&id,
+			&eventData,
+			&insertedAt); err != nil {
+			return
+		}
+
+		if len(eventData) > 0 {
+			if err = json.Unmarshal(eventData, &auditEvent); err != nil {
+				return nil, err
+			}
+		}
+
+		auditEvent.ID = id
+		auditEvent.InsertedAt = insertedAt
+
+		auditEvents = append(auditEvents, auditEvent)
+	}
+
+	return
+}
+
 func (c *client) scanCatalogEntities(rows *sql.Rows) (catalogEntities []*contracts.CatalogEntity, err error) {
 	catalogEntities = make([]*contracts.CatalogEntity, 0)
 
@@ -5847,6 +7632,16 @@ func (c *client) selectBuildsQuery() sq.SelectBuilder {
 		From("builds a")
 }
 
+func (c *client) selectBuildAttemptsQuery() sq.SelectBuilder {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	return psql.
+		Select("a.id, a.repo_source, a.repo_owner, a.repo_name, a.repo_revision, a.build_status").
+		From("builds a").
+		Where(sq.Eq{"a.build_status": []string{"succeeded", "failed"}}).
+		OrderBy("a.inserted_at")
+}
+
 func (c *client) selectPipelinesQuery() sq.SelectBuilder {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
@@ -5885,6 +7680,18 @@ func (c *client) selectBuildLogsQuery(readLogFromDatabase bool) sq.SelectBuilder
 		From("build_logs a")
 }
 
+// selectStageRunsQuery selects build logs with only the step fields needed for stage runs, leaving out the log lines that make up the bulk of the steps; nested steps only go one level deep
+func (c *client) selectStageRunsQuery() sq.SelectBuilder {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	return psql.
+		Select("a.id, a.repo_source, a.repo_owner, a.repo_name, a.repo_branch, a.repo_revision, a.build_id, " +
+			"(SELECT jsonb_agg(jsonb_build_object('step', s->'step', 'status', s->'status', 'duration', s->'duration', 'image', s->'image', 'nestedSteps', " +
+			"(SELECT jsonb_agg(jsonb_build_object('step', n->'step', 'status', n->'status', 'duration', n->'duration', 'image', n->'image')) FROM jsonb_array_elements(CASE jsonb_typeof(s->'nestedSteps') WHEN 'array' THEN s->'nestedSteps' END) n))) " +
+			"FROM jsonb_array_elements(CASE jsonb_typeof(a.steps) WHEN 'array' THEN a.steps END) s), a.inserted_at").
+		From("build_logs a")
+}
+
 func (c *client) selectReleaseLogsQuery(readLogFromDatabase bool) sq.SelectBuilder {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
@@ -5915,6 +7722,38 @@ func (c *client) selectCatalogEntityQuery() sq.SelectBuilder {
 		From("catalog_entities a")
 }
 
+func (c *client) selectPersonalAccessTokensQuery() sq.SelectBuilder {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	return psql.
+		Select("a.id, a.user_id, a.token_data, a.inserted_at, a.last_used_at, a.last_used_from, a.active").
+		From("personal_access_tokens a")
+}
+
+func (c *client) selectWebhooksQuery() sq.SelectBuilder {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	return psql.
+		Select("w.id, w.token_hash, w.webhook_data, w.inserted_at, w.active").
+		From("webhooks w")
+}
+
+func (c *client) selectCustomRolesQuery() sq.SelectBuilder {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	return psql.
+		Select("a.id, a.name, a.role_data, a.inserted_at, a.updated_at, a.active").
+		From("custom_roles a")
+}
+
+func (c *client) selectAuditEventsQuery() sq.SelectBuilder {
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	return psql.
+		Select("a.id, a.event_data, a.inserted_at").
+		From("audit_events a")
+}
+
 func (c *client) enrichPipeline(ctx context.Context, pipeline *contracts.Pipeline) {
 	c.getLatestReleasesForPipeline(ctx, pipeline)
 }
@@ -6147,3 +7986,8 @@ func (c *client) mapBuildToPipeline(build *contracts.Build) (pipeline *contracts
 		Events:               build.Events,
 	}
 }
+
+// CheckHealth pings the database to verify the connection is usable
+func (c *client) CheckHealth(ctx context.Context) (err error) {
+	return c.databaseConnection.PingContext(ctx)
+}
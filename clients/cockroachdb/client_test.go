@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/estafette/estafette-ci-api/api"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	manifest "github.com/estafette/estafette-ci-manifest"
@@ -2015,6 +2016,282 @@ func TestIntegrationGetClientsCount(t *testing.T) {
 	})
 }
 
+func TestIntegrationInsertPersonalAccessToken(t *testing.T) {
+	t.Run("ReturnsInsertedPersonalAccessTokenWithID", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		token := getPersonalAccessToken()
+
+		// act
+		insertedToken, err := cockroachdbClient.InsertPersonalAccessToken(ctx, token)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, insertedToken)
+		assert.True(t, insertedToken.ID != "")
+	})
+}
+
+func TestIntegrationRevokePersonalAccessToken(t *testing.T) {
+	t.Run("DeactivatesPersonalAccessToken", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		token := getPersonalAccessToken()
+		insertedToken, err := cockroachdbClient.InsertPersonalAccessToken(ctx, token)
+		assert.Nil(t, err)
+
+		// act
+		err = cockroachdbClient.RevokePersonalAccessToken(ctx, *insertedToken)
+
+		assert.Nil(t, err)
+		retrievedToken, err := cockroachdbClient.GetPersonalAccessTokenByID(ctx, insertedToken.ID)
+		assert.Nil(t, err)
+		assert.False(t, retrievedToken.Active)
+		assert.False(t, retrievedToken.IsValid())
+	})
+}
+
+func TestIntegrationGetPersonalAccessTokenByID(t *testing.T) {
+	t.Run("ReturnsInsertedPersonalAccessToken", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		token := getPersonalAccessToken()
+		insertedToken, err := cockroachdbClient.InsertPersonalAccessToken(ctx, token)
+		assert.Nil(t, err)
+
+		// act
+		retrievedToken, err := cockroachdbClient.GetPersonalAccessTokenByID(ctx, insertedToken.ID)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, retrievedToken)
+		assert.Equal(t, insertedToken.ID, retrievedToken.ID)
+		assert.Equal(t, "ci-scripts", retrievedToken.Name)
+		assert.Equal(t, []string{"ci.builds.list"}, retrievedToken.Scopes)
+	})
+
+	t.Run("ReturnsErrPersonalAccessTokenNotFoundForNonExistingToken", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+
+		// act
+		_, err := cockroachdbClient.GetPersonalAccessTokenByID(ctx, "15")
+
+		assert.True(t, errors.Is(err, ErrPersonalAccessTokenNotFound))
+	})
+}
+
+func TestIntegrationGetPersonalAccessTokens(t *testing.T) {
+	t.Run("ReturnsInsertedPersonalAccessTokensForUser", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		token := getPersonalAccessToken()
+		_, err := cockroachdbClient.InsertPersonalAccessToken(ctx, token)
+		assert.Nil(t, err)
+
+		// act
+		tokens, err := cockroachdbClient.GetPersonalAccessTokens(ctx, token.UserID, 1, 100, map[api.FilterType][]string{}, []api.OrderField{})
+
+		assert.Nil(t, err)
+		assert.True(t, len(tokens) > 0)
+
+		count, err := cockroachdbClient.GetPersonalAccessTokensCount(ctx, token.UserID, map[api.FilterType][]string{})
+
+		assert.Nil(t, err)
+		assert.True(t, count > 0)
+	})
+}
+
+func TestIntegrationInsertCustomRole(t *testing.T) {
+	t.Run("ReturnsInsertedCustomRoleWithID", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		customRole := getCustomRole()
+
+		// act
+		insertedCustomRole, err := cockroachdbClient.InsertCustomRole(ctx, customRole)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, insertedCustomRole)
+		assert.True(t, insertedCustomRole.ID != "")
+		assert.True(t, insertedCustomRole.Active)
+	})
+}
+
+func TestIntegrationDeleteCustomRole(t *testing.T) {
+	t.Run("DeactivatesCustomRole", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		insertedCustomRole, err := cockroachdbClient.InsertCustomRole(ctx, getCustomRole())
+		assert.Nil(t, err)
+
+		// act
+		err = cockroachdbClient.DeleteCustomRole(ctx, *insertedCustomRole)
+
+		assert.Nil(t, err)
+		_, err = cockroachdbClient.GetCustomRoleByID(ctx, insertedCustomRole.ID)
+		assert.True(t, errors.Is(err, ErrCustomRoleNotFound))
+	})
+}
+
+func TestIntegrationGetCustomRoleByID(t *testing.T) {
+	t.Run("ReturnsInsertedCustomRole", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		insertedCustomRole, err := cockroachdbClient.InsertCustomRole(ctx, getCustomRole())
+		assert.Nil(t, err)
+
+		// act
+		retrievedCustomRole, err := cockroachdbClient.GetCustomRoleByID(ctx, insertedCustomRole.ID)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, retrievedCustomRole)
+		assert.Equal(t, insertedCustomRole.Name, retrievedCustomRole.Name)
+		assert.Equal(t, []string{"ci.releases.create"}, retrievedCustomRole.Permissions)
+		assert.Equal(t, []string{"staging"}, retrievedCustomRole.Scope.ReleaseTargets)
+	})
+}
+
+func TestIntegrationGetCustomRolesByNames(t *testing.T) {
+	t.Run("ReturnsCustomRolesWithMatchingNames", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		insertedCustomRole, err := cockroachdbClient.InsertCustomRole(ctx, getCustomRole())
+		assert.Nil(t, err)
+
+		// act
+		customRoles, err := cockroachdbClient.GetCustomRolesByNames(ctx, []string{insertedCustomRole.Name, "administrator"})
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(customRoles)) {
+			assert.Equal(t, insertedCustomRole.ID, customRoles[0].ID)
+		}
+	})
+}
+
+func TestIntegrationGetCustomRoles(t *testing.T) {
+	t.Run("ReturnsInsertedCustomRoles", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		_, err := cockroachdbClient.InsertCustomRole(ctx, getCustomRole())
+		assert.Nil(t, err)
+
+		// act
+		customRoles, err := cockroachdbClient.GetCustomRoles(ctx, 1, 100, map[api.FilterType][]string{}, []api.OrderField{})
+
+		assert.Nil(t, err)
+		assert.True(t, len(customRoles) > 0)
+
+		count, err := cockroachdbClient.GetCustomRolesCount(ctx, map[api.FilterType][]string{})
+
+		assert.Nil(t, err)
+		assert.True(t, count > 0)
+	})
+}
+
+func TestIntegrationInsertAuditEvent(t *testing.T) {
+	t.Run("ReturnsInsertedAuditEventWithID", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		auditEvent := getAuditEvent()
+
+		// act
+		insertedAuditEvent, err := cockroachdbClient.InsertAuditEvent(ctx, auditEvent)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, insertedAuditEvent)
+		assert.True(t, insertedAuditEvent.ID != "")
+		assert.NotNil(t, insertedAuditEvent.InsertedAt)
+	})
+}
+
+func TestIntegrationGetAuditEvents(t *testing.T) {
+	t.Run("ReturnsAuditEventsFilteredByTarget", func(t *testing.T) {
+
+		if testing.Short() {
+			t.Skip("skipping test in short mode.")
+		}
+
+		ctx := context.Background()
+		cockroachdbClient := getCockroachdbClient(ctx, t)
+		auditEvent := getAuditEvent()
+		_, err := cockroachdbClient.InsertAuditEvent(ctx, auditEvent)
+		assert.Nil(t, err)
+
+		filters := map[api.FilterType][]string{
+			api.FilterTargetType: {auditEvent.TargetType},
+			api.FilterTargetID:   {auditEvent.TargetID},
+		}
+
+		// act
+		auditEvents, err := cockroachdbClient.GetAuditEvents(ctx, 1, 100, filters, []api.OrderField{})
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 1, len(auditEvents)) {
+			assert.Equal(t, auditEvent.ActorEmail, auditEvents[0].ActorEmail)
+			assert.Equal(t, auditEvent.Action, auditEvents[0].Action)
+		}
+
+		count, err := cockroachdbClient.GetAuditEventsCount(ctx, filters)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
 func TestIntegrationInsertCatalogEntity(t *testing.T) {
 	t.Run("ReturnsInsertedCatalogEntityWithID", func(t *testing.T) {
 
@@ -2820,6 +3097,42 @@ func getClient() contracts.Client {
 	}
 }
 
+func getPersonalAccessToken() api.PersonalAccessToken {
+	expiresAt := time.Now().UTC().Add(24 * time.Hour)
+	return api.PersonalAccessToken{
+		UserID:    "1",
+		Name:      "ci-scripts",
+		Scopes:    []string{"ci.builds.list"},
+		ExpiresAt: &expiresAt,
+		Active:    true,
+	}
+}
+
+func getCustomRole() api.CustomRole {
+	return api.CustomRole{
+		// names are unique, so avoid clashes between test runs
+		Name:        "release-staging-" + strconv.FormatInt(time.Now().UnixNano(), 10),
+		Permissions: []string{"ci.releases.create"},
+		Scope: &api.CustomRoleScope{
+			ReleaseTargets: []string{"staging"},
+		},
+	}
+}
+
+func getAuditEvent() api.AuditEvent {
+	return api.AuditEvent{
+		ActorID:    "1",
+		ActorEmail: "me@estafette.io",
+		Action:     "rbac.users.update",
+		TargetType: "user",
+		// target ids are filtered on, so avoid clashes between test runs
+		TargetID: strconv.FormatInt(time.Now().UnixNano(), 10),
+		After: map[string]interface{}{
+			"name": "Me",
+		},
+	}
+}
+
 func getCatalogEntity() contracts.CatalogEntity {
 	now := time.Now().UTC()
 	return contracts.CatalogEntity{
@@ -2841,3 +3154,170 @@ func getCatalogEntity() contracts.CatalogEntity {
 		UpdatedAt:  &now,
 	}
 }
+
+func TestSelectStageRunsQuery(t *testing.T) {
+	t.Run("ProjectsStepsWithoutLogLines", func(t *testing.T) {
+
+		c := &client{}
+
+		// act
+		query := c.selectStageRunsQuery()
+
+		sql, _, _ := query.ToSql()
+		assert.NotContains(t, sql, "a.steps,")
+		assert.NotContains(t, sql, "logLines")
+		assert.Contains(t, sql, "jsonb_build_object('step', s->'step', 'status', s->'status', 'duration', s->'duration', 'image', s->'image'")
+	})
+}
+
+func TestPagingClauseGenerator(t *testing.T) {
+	t.Run("ReturnsLimitAndOffsetWithoutPageAfter", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("builds a")
+
+		// act
+		query, err := pagingClauseGenerator(query, "a", "a.inserted_at DESC", []string{"inserted_at", "id"}, "DESC", 3, 20, []api.OrderField{}, map[api.FilterType][]string{})
+
+		assert.Nil(t, err)
+		sql, _, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM builds a ORDER BY a.inserted_at DESC LIMIT 20 OFFSET 40", sql)
+	})
+
+	t.Run("ReturnsKeysetWhereClauseForPageAfter", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("builds a")
+		filters := map[api.FilterType][]string{
+			api.FilterAfter: {api.EncodePageCursor("2020-04-01T10:00:00Z", "551447279823060993")},
+		}
+
+		// act
+		query, err := pagingClauseGenerator(query, "a", "a.inserted_at DESC", []string{"inserted_at", "id"}, "DESC", 3, 21, []api.OrderField{{FieldName: "buildStatus", Direction: "ASC"}}, filters)
+
+		assert.Nil(t, err)
+		sql, args, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM builds a WHERE (a.inserted_at,a.id) < ($1,$2) ORDER BY a.inserted_at DESC, a.id DESC LIMIT 21", sql)
+		assert.Equal(t, []interface{}{"2020-04-01T10:00:00Z", "551447279823060993"}, args)
+	})
+
+	t.Run("ReturnsErrInvalidPageCursorForCursorOfOtherList", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("computed_pipelines a")
+		filters := map[api.FilterType][]string{
+			api.FilterAfter: {api.EncodePageCursor("2020-04-01T10:00:00Z", "551447279823060993")},
+		}
+
+		// act
+		_, err := pagingClauseGenerator(query, "a", "a.repo_source,a.repo_owner,a.repo_name", []string{"repo_source", "repo_owner", "repo_name"}, "ASC", 1, 21, nil, filters)
+
+		assert.True(t, errors.Is(err, api.ErrInvalidPageCursor))
+	})
+}
+
+func TestWhereClauseGeneratorForTimeRangeFilter(t *testing.T) {
+	t.Run("ReturnsSinceAndUntilWhereClauses", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("builds a")
+		filters := map[api.FilterType][]string{
+			api.FilterSince: {"2020-04-01"},
+			api.FilterUntil: {"2020-05-01T00:00:00Z"},
+		}
+
+		// act
+		query, err := whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", filters)
+
+		assert.Nil(t, err)
+		sql, args, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM builds a WHERE a.inserted_at >= $1 AND a.inserted_at < $2", sql)
+		assert.Equal(t, []interface{}{time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)}, args)
+	})
+
+	t.Run("ReturnsNoWhereClauseForEternity", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("builds a")
+
+		// act
+		query, err := whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", map[api.FilterType][]string{api.FilterSince: {"eternity"}})
+
+		assert.Nil(t, err)
+		sql, _, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM builds a", sql)
+	})
+
+	t.Run("ReturnsErrInvalidTimeFilterForUnparseableValue", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("builds a")
+
+		// act
+		_, err := whereClauseGeneratorForTimeRangeFilter(query, "a", "inserted_at", map[api.FilterType][]string{api.FilterSince: {"2w"}})
+
+		assert.True(t, errors.Is(err, api.ErrInvalidTimeFilter))
+	})
+}
+
+func TestWhereClauseGeneratorForUserIdentityFilters(t *testing.T) {
+	t.Run("ReturnsCaseInsensitiveIdentityEmailWhereClause", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("users a")
+
+		// act
+		query, err := whereClauseGeneratorForUserIdentityFilters(query, "a", map[api.FilterType][]string{api.FilterIdentityEmail: {"Me@estafette.io"}})
+
+		assert.Nil(t, err)
+		sql, args, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM users a WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(a.user_data->'identities') AS i WHERE lower(i->>'email') = lower($1))", sql)
+		assert.Equal(t, []interface{}{"Me@estafette.io"}, args)
+	})
+
+	t.Run("ReturnsNoWhereClauseWithoutIdentityFilters", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("users a")
+
+		// act
+		query, err := whereClauseGeneratorForUserIdentityFilters(query, "a", map[api.FilterType][]string{})
+
+		assert.Nil(t, err)
+		sql, _, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM users a", sql)
+	})
+}
+
+func TestWhereClauseGeneratorForLabelsFilter(t *testing.T) {
+	t.Run("ReturnsContainmentWhereClausesForLabelSelector", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("pipelines a")
+		filters := map[api.FilterType][]string{
+			api.FilterLabels: {"team in (estafette,other),tier!=experimental"},
+		}
+
+		// act
+		query, err := whereClauseGeneratorForLabelsFilter(query, "a", filters)
+
+		assert.Nil(t, err)
+		sql, args, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM pipelines a WHERE (a.labels @> $1 OR a.labels @> $2) AND NOT COALESCE(a.labels @> $3, false)", sql)
+		assert.Equal(t, []interface{}{`[{"key":"team","value":"estafette"}]`, `[{"key":"team","value":"other"}]`, `[{"key":"tier","value":"experimental"}]`}, args)
+	})
+
+	t.Run("ReturnsKeyContainmentWhereClauseForExistsRequirement", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("pipelines a")
+
+		// act
+		query, err := whereClauseGeneratorForLabelsFilter(query, "a", map[api.FilterType][]string{api.FilterLabels: {"team"}})
+
+		assert.Nil(t, err)
+		sql, args, _ := query.ToSql()
+		assert.Equal(t, "SELECT a.id FROM pipelines a WHERE (a.labels @> $1)", sql)
+		assert.Equal(t, []interface{}{`[{"key":"team"}]`}, args)
+	})
+
+	t.Run("ReturnsErrInvalidLabelSelectorForUnparseableValue", func(t *testing.T) {
+
+		query := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).Select("a.id").From("pipelines a")
+
+		// act
+		_, err := whereClauseGeneratorForLabelsFilter(query, "a", map[api.FilterType][]string{api.FilterLabels: {"team in ()"}})
+
+		assert.True(t, errors.Is(err, api.ErrInvalidLabelSelector))
+	})
+}
@@ -55,6 +55,18 @@ func (c *loggingClient) UpdateBuildResourceUtilization(ctx context.Context, repo
 	return c.Client.UpdateBuildResourceUtilization(ctx, repoSource, repoOwner, repoName, buildID, jobResources)
 }
 
+func (c *loggingClient) UpdateBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, parameters map[string]string) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "UpdateBuildParameters", err) }()
+
+	return c.Client.UpdateBuildParameters(ctx, repoSource, repoOwner, repoName, buildID, parameters)
+}
+
+func (c *loggingClient) GetBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int) (parameters map[string]string, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetBuildParameters", err) }()
+
+	return c.Client.GetBuildParameters(ctx, repoSource, repoOwner, repoName, buildID)
+}
+
 func (c *loggingClient) InsertRelease(ctx context.Context, release contracts.Release, jobResources JobResources) (r *contracts.Release, err error) {
 	defer func() { api.HandleLogError(c.prefix, "InsertRelease", err) }()
 
@@ -385,6 +397,48 @@ func (c *loggingClient) GetPipelinesWithMostReleasesCount(ctx context.Context, f
 	return c.Client.GetPipelinesWithMostReleasesCount(ctx, filters)
 }
 
+func (c *loggingClient) GetDoraDeployments(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetDoraDeployments", err) }()
+
+	return c.Client.GetDoraDeployments(ctx, releaseTargets, filters)
+}
+
+func (c *loggingClient) GetBuildAttempts(ctx context.Context, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetBuildAttempts", err) }()
+
+	return c.Client.GetBuildAttempts(ctx, filters)
+}
+
+func (c *loggingClient) GetPipelineBuildAttempts(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPipelineBuildAttempts", err) }()
+
+	return c.Client.GetPipelineBuildAttempts(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c *loggingClient) GetBuildStageStatuses(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetBuildStageStatuses", err) }()
+
+	return c.Client.GetBuildStageStatuses(ctx, buildIDs)
+}
+
+func (c *loggingClient) GetStageRuns(ctx context.Context, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetStageRuns", err) }()
+
+	return c.Client.GetStageRuns(ctx, filters)
+}
+
+func (c *loggingClient) GetPipelineStageRuns(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPipelineStageRuns", err) }()
+
+	return c.Client.GetPipelineStageRuns(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c *loggingClient) GetJobUsages(ctx context.Context, filters map[api.FilterType][]string) (usages []*api.JobUsage, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetJobUsages", err) }()
+
+	return c.Client.GetJobUsages(ctx, filters)
+}
+
 func (c *loggingClient) GetTriggers(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error) {
 	defer func() { api.HandleLogError(c.prefix, "GetTriggers", err) }()
 
@@ -421,6 +475,12 @@ func (c *loggingClient) GetCronTriggers(ctx context.Context) (pipelines []*contr
 	return c.Client.GetCronTriggers(ctx)
 }
 
+func (c *loggingClient) GetDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetDockerTriggers", err) }()
+
+	return c.Client.GetDockerTriggers(ctx, dockerEvent)
+}
+
 func (c *loggingClient) Rename(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	defer func() { api.HandleLogError(c.prefix, "Rename", err) }()
 
@@ -643,6 +703,156 @@ func (c *loggingClient) GetClientsCount(ctx context.Context, filters map[api.Fil
 	return c.Client.GetClientsCount(ctx, filters)
 }
 
+func (c *loggingClient) InsertPersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	defer func() { api.HandleLogError(c.prefix, "InsertPersonalAccessToken", err) }()
+
+	return c.Client.InsertPersonalAccessToken(ctx, token)
+}
+
+func (c *loggingClient) UpdatePersonalAccessTokenLastUsed(ctx context.Context, id, lastUsedFrom string) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "UpdatePersonalAccessTokenLastUsed", err) }()
+
+	return c.Client.UpdatePersonalAccessTokenLastUsed(ctx, id, lastUsedFrom)
+}
+
+func (c *loggingClient) RevokePersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "RevokePersonalAccessToken", err) }()
+
+	return c.Client.RevokePersonalAccessToken(ctx, token)
+}
+
+func (c *loggingClient) GetPersonalAccessTokenByID(ctx context.Context, id string) (token *api.PersonalAccessToken, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPersonalAccessTokenByID", err) }()
+
+	return c.Client.GetPersonalAccessTokenByID(ctx, id)
+}
+
+func (c *loggingClient) GetPersonalAccessTokens(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPersonalAccessTokens", err) }()
+
+	return c.Client.GetPersonalAccessTokens(ctx, userID, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *loggingClient) GetPersonalAccessTokensCount(ctx context.Context, userID string, filters map[api.FilterType][]string) (count int, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPersonalAccessTokensCount", err) }()
+
+	return c.Client.GetPersonalAccessTokensCount(ctx, userID, filters)
+}
+
+func (c *loggingClient) InsertCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	defer func() { api.HandleLogError(c.prefix, "InsertCustomRole", err) }()
+
+	return c.Client.InsertCustomRole(ctx, customRole)
+}
+
+func (c *loggingClient) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "UpdateCustomRole", err) }()
+
+	return c.Client.UpdateCustomRole(ctx, customRole)
+}
+
+func (c *loggingClient) DeleteCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "DeleteCustomRole", err) }()
+
+	return c.Client.DeleteCustomRole(ctx, customRole)
+}
+
+func (c *loggingClient) GetCustomRoleByID(ctx context.Context, id string) (customRole *api.CustomRole, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetCustomRoleByID", err) }()
+
+	return c.Client.GetCustomRoleByID(ctx, id)
+}
+
+func (c *loggingClient) GetCustomRolesByNames(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetCustomRolesByNames", err) }()
+
+	return c.Client.GetCustomRolesByNames(ctx, names)
+}
+
+func (c *loggingClient) GetCustomRoles(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (customRoles []*api.CustomRole, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetCustomRoles", err) }()
+
+	return c.Client.GetCustomRoles(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *loggingClient) GetCustomRolesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetCustomRolesCount", err) }()
+
+	return c.Client.GetCustomRolesCount(ctx, filters)
+}
+
+func (c *loggingClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	defer func() { api.HandleLogError(c.prefix, "InsertAuditEvent", err) }()
+
+	return c.Client.InsertAuditEvent(ctx, auditEvent)
+}
+
+func (c *loggingClient) GetAuditEvents(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (auditEvents []*api.AuditEvent, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetAuditEvents", err) }()
+
+	return c.Client.GetAuditEvents(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *loggingClient) GetAuditEventsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetAuditEventsCount", err) }()
+
+	return c.Client.GetAuditEventsCount(ctx, filters)
+}
+
+func (c *loggingClient) InsertPipelineWarning(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "InsertPipelineWarning", err) }()
+
+	return c.Client.InsertPipelineWarning(ctx, repoSource, repoOwner, repoName, warning)
+}
+
+func (c *loggingClient) GetPipelineWarnings(ctx context.Context, repoSource, repoOwner, repoName string, since time.Time) (warnings []contracts.Warning, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPipelineWarnings", err) }()
+
+	return c.Client.GetPipelineWarnings(ctx, repoSource, repoOwner, repoName, since)
+}
+
+func (c *loggingClient) InsertWebhook(ctx context.Context, webhook api.Webhook) (insertedWebhook *api.Webhook, err error) {
+	defer func() { api.HandleLogError(c.prefix, "InsertWebhook", err) }()
+
+	return c.Client.InsertWebhook(ctx, webhook)
+}
+
+func (c *loggingClient) DeleteWebhook(ctx context.Context, webhook api.Webhook) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "DeleteWebhook", err) }()
+
+	return c.Client.DeleteWebhook(ctx, webhook)
+}
+
+func (c *loggingClient) GetWebhookByID(ctx context.Context, id string) (webhook *api.Webhook, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetWebhookByID", err) }()
+
+	return c.Client.GetWebhookByID(ctx, id)
+}
+
+func (c *loggingClient) GetPipelineWebhooks(ctx context.Context, repoSource, repoOwner, repoName string) (webhooks []*api.Webhook, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPipelineWebhooks", err) }()
+
+	return c.Client.GetPipelineWebhooks(ctx, repoSource, repoOwner, repoName)
+}
+
+func (c *loggingClient) InsertWebhookDelivery(ctx context.Context, delivery api.WebhookDelivery) (insertedDelivery *api.WebhookDelivery, err error) {
+	defer func() { api.HandleLogError(c.prefix, "InsertWebhookDelivery", err) }()
+
+	return c.Client.InsertWebhookDelivery(ctx, delivery)
+}
+
+func (c *loggingClient) GetPipelineWebhookDeliveries(ctx context.Context, repoSource, repoOwner, repoName string, pageNumber, pageSize int) (deliveries []*api.WebhookDelivery, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPipelineWebhookDeliveries", err) }()
+
+	return c.Client.GetPipelineWebhookDeliveries(ctx, repoSource, repoOwner, repoName, pageNumber, pageSize)
+}
+
+func (c *loggingClient) GetPipelineWebhookDeliveriesCount(ctx context.Context, repoSource, repoOwner, repoName string) (count int, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetPipelineWebhookDeliveriesCount", err) }()
+
+	return c.Client.GetPipelineWebhookDeliveriesCount(ctx, repoSource, repoOwner, repoName)
+}
+
 func (c *loggingClient) InsertCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
 	defer func() { api.HandleLogError(c.prefix, "InsertCatalogEntity", err) }()
 
@@ -738,3 +948,9 @@ func (c *loggingClient) GetCatalogEntityLabelsCount(ctx context.Context, filters
 
 	return c.Client.GetCatalogEntityLabelsCount(ctx, filters)
 }
+
+func (c *loggingClient) CheckHealth(ctx context.Context) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "CheckHealth", err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
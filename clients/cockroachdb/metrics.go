@@ -65,6 +65,22 @@ func (c *metricsClient) UpdateBuildResourceUtilization(ctx context.Context, repo
 	return c.Client.UpdateBuildResourceUtilization(ctx, repoSource, repoOwner, repoName, buildID, jobResources)
 }
 
+func (c *metricsClient) UpdateBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, parameters map[string]string) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "UpdateBuildParameters", begin)
+	}(time.Now())
+
+	return c.Client.UpdateBuildParameters(ctx, repoSource, repoOwner, repoName, buildID, parameters)
+}
+
+func (c *metricsClient) GetBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int) (parameters map[string]string, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetBuildParameters", begin)
+	}(time.Now())
+
+	return c.Client.GetBuildParameters(ctx, repoSource, repoOwner, repoName, buildID)
+}
+
 func (c *metricsClient) InsertRelease(ctx context.Context, release contracts.Release, jobResources JobResources) (r *contracts.Release, err error) {
 	defer func(begin time.Time) { api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertRelease", begin) }(time.Now())
 
@@ -499,6 +515,62 @@ func (c *metricsClient) GetPipelinesWithMostReleasesCount(ctx context.Context, f
 	return c.Client.GetPipelinesWithMostReleasesCount(ctx, filters)
 }
 
+func (c *metricsClient) GetDoraDeployments(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetDoraDeployments", begin)
+	}(time.Now())
+
+	return c.Client.GetDoraDeployments(ctx, releaseTargets, filters)
+}
+
+func (c *metricsClient) GetBuildAttempts(ctx context.Context, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetBuildAttempts", begin)
+	}(time.Now())
+
+	return c.Client.GetBuildAttempts(ctx, filters)
+}
+
+func (c *metricsClient) GetPipelineBuildAttempts(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPipelineBuildAttempts", begin)
+	}(time.Now())
+
+	return c.Client.GetPipelineBuildAttempts(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c *metricsClient) GetBuildStageStatuses(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetBuildStageStatuses", begin)
+	}(time.Now())
+
+	return c.Client.GetBuildStageStatuses(ctx, buildIDs)
+}
+
+func (c *metricsClient) GetStageRuns(ctx context.Context, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetStageRuns", begin)
+	}(time.Now())
+
+	return c.Client.GetStageRuns(ctx, filters)
+}
+
+func (c *metricsClient) GetPipelineStageRuns(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPipelineStageRuns", begin)
+	}(time.Now())
+
+	return c.Client.GetPipelineStageRuns(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c *metricsClient) GetJobUsages(ctx context.Context, filters map[api.FilterType][]string) (usages []*api.JobUsage, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetJobUsages", begin)
+	}(time.Now())
+
+	return c.Client.GetJobUsages(ctx, filters)
+}
+
 func (c *metricsClient) GetTriggers(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error) {
 	defer func(begin time.Time) { api.UpdateMetrics(c.requestCount, c.requestLatency, "GetTriggers", begin) }(time.Now())
 
@@ -545,6 +617,14 @@ func (c *metricsClient) GetCronTriggers(ctx context.Context) (pipelines []*contr
 	return c.Client.GetCronTriggers(ctx)
 }
 
+func (c *metricsClient) GetDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetDockerTriggers", begin)
+	}(time.Now())
+
+	return c.Client.GetDockerTriggers(ctx, dockerEvent)
+}
+
 func (c *metricsClient) Rename(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	defer func(begin time.Time) { api.UpdateMetrics(c.requestCount, c.requestLatency, "Rename", begin) }(time.Now())
 
@@ -837,6 +917,206 @@ func (c *metricsClient) GetClientsCount(ctx context.Context, filters map[api.Fil
 	return c.Client.GetClientsCount(ctx, filters)
 }
 
+func (c *metricsClient) InsertPersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertPersonalAccessToken", begin)
+	}(time.Now())
+
+	return c.Client.InsertPersonalAccessToken(ctx, token)
+}
+
+func (c *metricsClient) UpdatePersonalAccessTokenLastUsed(ctx context.Context, id, lastUsedFrom string) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "UpdatePersonalAccessTokenLastUsed", begin)
+	}(time.Now())
+
+	return c.Client.UpdatePersonalAccessTokenLastUsed(ctx, id, lastUsedFrom)
+}
+
+func (c *metricsClient) RevokePersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "RevokePersonalAccessToken", begin)
+	}(time.Now())
+
+	return c.Client.RevokePersonalAccessToken(ctx, token)
+}
+
+func (c *metricsClient) GetPersonalAccessTokenByID(ctx context.Context, id string) (token *api.PersonalAccessToken, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPersonalAccessTokenByID", begin)
+	}(time.Now())
+
+	return c.Client.GetPersonalAccessTokenByID(ctx, id)
+}
+
+func (c *metricsClient) GetPersonalAccessTokens(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPersonalAccessTokens", begin)
+	}(time.Now())
+
+	return c.Client.GetPersonalAccessTokens(ctx, userID, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *metricsClient) GetPersonalAccessTokensCount(ctx context.Context, userID string, filters map[api.FilterType][]string) (count int, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPersonalAccessTokensCount", begin)
+	}(time.Now())
+
+	return c.Client.GetPersonalAccessTokensCount(ctx, userID, filters)
+}
+
+func (c *metricsClient) InsertCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertCustomRole", begin)
+	}(time.Now())
+
+	return c.Client.InsertCustomRole(ctx, customRole)
+}
+
+func (c *metricsClient) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "UpdateCustomRole", begin)
+	}(time.Now())
+
+	return c.Client.UpdateCustomRole(ctx, customRole)
+}
+
+func (c *metricsClient) DeleteCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "DeleteCustomRole", begin)
+	}(time.Now())
+
+	return c.Client.DeleteCustomRole(ctx, customRole)
+}
+
+func (c *metricsClient) GetCustomRoleByID(ctx context.Context, id string) (customRole *api.CustomRole, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetCustomRoleByID", begin)
+	}(time.Now())
+
+	return c.Client.GetCustomRoleByID(ctx, id)
+}
+
+func (c *metricsClient) GetCustomRolesByNames(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetCustomRolesByNames", begin)
+	}(time.Now())
+
+	return c.Client.GetCustomRolesByNames(ctx, names)
+}
+
+func (c *metricsClient) GetCustomRoles(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (customRoles []*api.CustomRole, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetCustomRoles", begin)
+	}(time.Now())
+
+	return c.Client.GetCustomRoles(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *metricsClient) GetCustomRolesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetCustomRolesCount", begin)
+	}(time.Now())
+
+	return c.Client.GetCustomRolesCount(ctx, filters)
+}
+
+func (c *metricsClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertAuditEvent", begin)
+	}(time.Now())
+
+	return c.Client.InsertAuditEvent(ctx, auditEvent)
+}
+
+func (c *metricsClient) GetAuditEvents(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (auditEvents []*api.AuditEvent, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetAuditEvents", begin)
+	}(time.Now())
+
+	return c.Client.GetAuditEvents(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *metricsClient) GetAuditEventsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetAuditEventsCount", begin)
+	}(time.Now())
+
+	return c.Client.GetAuditEventsCount(ctx, filters)
+}
+
+func (c *metricsClient) InsertPipelineWarning(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertPipelineWarning", begin)
+	}(time.Now())
+
+	return c.Client.InsertPipelineWarning(ctx, repoSource, repoOwner, repoName, warning)
+}
+
+func (c *metricsClient) GetPipelineWarnings(ctx context.Context, repoSource, repoOwner, repoName string, since time.Time) (warnings []contracts.Warning, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPipelineWarnings", begin)
+	}(time.Now())
+
+	return c.Client.GetPipelineWarnings(ctx, repoSource, repoOwner, repoName, since)
+}
+
+func (c *metricsClient) InsertWebhook(ctx context.Context, webhook api.Webhook) (insertedWebhook *api.Webhook, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertWebhook", begin)
+	}(time.Now())
+
+	return c.Client.InsertWebhook(ctx, webhook)
+}
+
+func (c *metricsClient) DeleteWebhook(ctx context.Context, webhook api.Webhook) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "DeleteWebhook", begin)
+	}(time.Now())
+
+	return c.Client.DeleteWebhook(ctx, webhook)
+}
+
+func (c *metricsClient) GetWebhookByID(ctx context.Context, id string) (webhook *api.Webhook, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetWebhookByID", begin)
+	}(time.Now())
+
+	return c.Client.GetWebhookByID(ctx, id)
+}
+
+func (c *metricsClient) GetPipelineWebhooks(ctx context.Context, repoSource, repoOwner, repoName string) (webhooks []*api.Webhook, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPipelineWebhooks", begin)
+	}(time.Now())
+
+	return c.Client.GetPipelineWebhooks(ctx, repoSource, repoOwner, repoName)
+}
+
+func (c *metricsClient) InsertWebhookDelivery(ctx context.Context, delivery api.WebhookDelivery) (insertedDelivery *api.WebhookDelivery, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertWebhookDelivery", begin)
+	}(time.Now())
+
+	return c.Client.InsertWebhookDelivery(ctx, delivery)
+}
+
+func (c *metricsClient) GetPipelineWebhookDeliveries(ctx context.Context, repoSource, repoOwner, repoName string, pageNumber, pageSize int) (deliveries []*api.WebhookDelivery, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPipelineWebhookDeliveries", begin)
+	}(time.Now())
+
+	return c.Client.GetPipelineWebhookDeliveries(ctx, repoSource, repoOwner, repoName, pageNumber, pageSize)
+}
+
+func (c *metricsClient) GetPipelineWebhookDeliveriesCount(ctx context.Context, repoSource, repoOwner, repoName string) (count int, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetPipelineWebhookDeliveriesCount", begin)
+	}(time.Now())
+
+	return c.Client.GetPipelineWebhookDeliveriesCount(ctx, repoSource, repoOwner, repoName)
+}
+
 func (c *metricsClient) InsertCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
 	defer func(begin time.Time) {
 		api.UpdateMetrics(c.requestCount, c.requestLatency, "InsertCatalogEntity", begin)
@@ -964,3 +1244,11 @@ func (c *metricsClient) GetCatalogEntityLabelsCount(ctx context.Context, filters
 
 	return c.Client.GetCatalogEntityLabelsCount(ctx, filters)
 }
+
+func (c *metricsClient) CheckHealth(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "CheckHealth", begin)
+	}(time.Now())
+
+	return c.Client.CheckHealth(ctx)
+}
@@ -16,6 +16,8 @@ type MockClient struct {
 	InsertBuildFunc                                func(ctx context.Context, build contracts.Build, jobResources JobResources) (b *contracts.Build, err error)
 	UpdateBuildStatusFunc                          func(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error)
 	UpdateBuildResourceUtilizationFunc             func(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, jobResources JobResources) (err error)
+	UpdateBuildParametersFunc                      func(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, parameters map[string]string) (err error)
+	GetBuildParametersFunc                         func(ctx context.Context, repoSource, repoOwner, repoName string, buildID int) (parameters map[string]string, err error)
 	InsertReleaseFunc                              func(ctx context.Context, release contracts.Release, jobResources JobResources) (r *contracts.Release, err error)
 	UpdateReleaseStatusFunc                        func(ctx context.Context, repoSource, repoOwner, repoName string, id int, releaseStatus string) (err error)
 	UpdateReleaseResourceUtilizationFunc           func(ctx context.Context, repoSource, repoOwner, repoName string, id int, jobResources JobResources) (err error)
@@ -71,12 +73,20 @@ type MockClient struct {
 	GetPipelinesWithMostBuildsCountFunc            func(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
 	GetPipelinesWithMostReleasesFunc               func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string) (pipelines []map[string]interface{}, err error)
 	GetPipelinesWithMostReleasesCountFunc          func(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+	GetDoraDeploymentsFunc                         func(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error)
+	GetBuildAttemptsFunc                           func(ctx context.Context, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error)
+	GetPipelineBuildAttemptsFunc                   func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error)
+	GetBuildStageStatusesFunc                      func(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error)
+	GetStageRunsFunc                               func(ctx context.Context, filters map[api.FilterType][]string) (runs []*api.StageRun, err error)
+	GetPipelineStageRunsFunc                       func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (runs []*api.StageRun, err error)
+	GetJobUsagesFunc                               func(ctx context.Context, filters map[api.FilterType][]string) (usages []*api.JobUsage, err error)
 	GetTriggersFunc                                func(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error)
 	GetGitTriggersFunc                             func(ctx context.Context, gitEvent manifest.EstafetteGitEvent) (pipelines []*contracts.Pipeline, err error)
 	GetPipelineTriggersFunc                        func(ctx context.Context, build contracts.Build, event string) (pipelines []*contracts.Pipeline, err error)
 	GetReleaseTriggersFunc                         func(ctx context.Context, release contracts.Release, event string) (pipelines []*contracts.Pipeline, err error)
 	GetPubSubTriggersFunc                          func(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (pipelines []*contracts.Pipeline, err error)
 	GetCronTriggersFunc                            func(ctx context.Context) (pipelines []*contracts.Pipeline, err error)
+	GetDockerTriggersFunc                          func(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error)
 	RenameFunc                                     func(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) (err error)
 	RenameBuildVersionFunc                         func(ctx context.Context, shortFromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoOwner, toRepoName string) (err error)
 	RenameBuildsFunc                               func(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error)
@@ -119,6 +129,35 @@ type MockClient struct {
 	GetClientsFunc          func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (clients []*contracts.Client, err error)
 	GetClientsCountFunc     func(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
 
+	InsertPersonalAccessTokenFunc         func(ctx context.Context, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error)
+	UpdatePersonalAccessTokenLastUsedFunc func(ctx context.Context, id, lastUsedFrom string) (err error)
+	RevokePersonalAccessTokenFunc         func(ctx context.Context, token api.PersonalAccessToken) (err error)
+	GetPersonalAccessTokenByIDFunc        func(ctx context.Context, id string) (token *api.PersonalAccessToken, err error)
+	GetPersonalAccessTokensFunc           func(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error)
+	GetPersonalAccessTokensCountFunc      func(ctx context.Context, userID string, filters map[api.FilterType][]string) (count int, err error)
+
+	InsertCustomRoleFunc      func(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error)
+	UpdateCustomRoleFunc      func(ctx context.Context, customRole api.CustomRole) (err error)
+	DeleteCustomRoleFunc      func(ctx context.Context, customRole api.CustomRole) (err error)
+	GetCustomRoleByIDFunc     func(ctx context.Context, id string) (customRole *api.CustomRole, err error)
+	GetCustomRolesByNamesFunc func(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error)
+	GetCustomRolesFunc        func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (customRoles []*api.CustomRole, err error)
+	GetCustomRolesCountFunc   func(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+
+	InsertAuditEventFunc    func(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error)
+	GetAuditEventsFunc      func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (auditEvents []*api.AuditEvent, err error)
+	GetAuditEventsCountFunc func(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+
+	InsertPipelineWarningFunc             func(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error)
+	GetPipelineWarningsFunc               func(ctx context.Context, repoSource, repoOwner, repoName string, since time.Time) (warnings []contracts.Warning, err error)
+	InsertWebhookFunc                     func(ctx context.Context, webhook api.Webhook) (insertedWebhook *api.Webhook, err error)
+	DeleteWebhookFunc                     func(ctx context.Context, webhook api.Webhook) (err error)
+	GetWebhookByIDFunc                    func(ctx context.Context, id string) (webhook *api.Webhook, err error)
+	GetPipelineWebhooksFunc               func(ctx context.Context, repoSource, repoOwner, repoName string) (webhooks []*api.Webhook, err error)
+	InsertWebhookDeliveryFunc             func(ctx context.Context, delivery api.WebhookDelivery) (insertedDelivery *api.WebhookDelivery, err error)
+	GetPipelineWebhookDeliveriesFunc      func(ctx context.Context, repoSource, repoOwner, repoName string, pageNumber, pageSize int) (deliveries []*api.WebhookDelivery, err error)
+	GetPipelineWebhookDeliveriesCountFunc func(ctx context.Context, repoSource, repoOwner, repoName string) (count int, err error)
+
 	InsertCatalogEntityFunc     func(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error)
 	UpdateCatalogEntityFunc     func(ctx context.Context, catalogEntity contracts.CatalogEntity) (err error)
 	DeleteCatalogEntityFunc     func(ctx context.Context, id string) (err error)
@@ -136,6 +175,7 @@ type MockClient struct {
 	GetCatalogEntityValuesCountFunc       func(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
 	GetCatalogEntityLabelsFunc            func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string) (labels []map[string]interface{}, err error)
 	GetCatalogEntityLabelsCountFunc       func(ctx context.Context, filters map[api.FilterType][]string) (count int, err error)
+	CheckHealthFunc                       func(ctx context.Context) (err error)
 }
 
 func (c MockClient) Connect(ctx context.Context) (err error) {
@@ -180,6 +220,20 @@ func (c MockClient) UpdateBuildResourceUtilization(ctx context.Context, repoSour
 	return c.UpdateBuildResourceUtilizationFunc(ctx, repoSource, repoOwner, repoName, buildID, jobResources)
 }
 
+func (c MockClient) UpdateBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, parameters map[string]string) (err error) {
+	if c.UpdateBuildParametersFunc == nil {
+		return
+	}
+	return c.UpdateBuildParametersFunc(ctx, repoSource, repoOwner, repoName, buildID, parameters)
+}
+
+func (c MockClient) GetBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int) (parameters map[string]string, err error) {
+	if c.GetBuildParametersFunc == nil {
+		return
+	}
+	return c.GetBuildParametersFunc(ctx, repoSource, repoOwner, repoName, buildID)
+}
+
 func (c MockClient) InsertRelease(ctx context.Context, release contracts.Release, jobResources JobResources) (r *contracts.Release, err error) {
 	if c.InsertReleaseFunc == nil {
 		return
@@ -566,6 +620,55 @@ func (c MockClient) GetPipelinesWithMostReleasesCount(ctx context.Context, filte
 	return c.GetPipelinesWithMostReleasesCountFunc(ctx, filters)
 }
 
+func (c MockClient) GetDoraDeployments(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error) {
+	if c.GetDoraDeploymentsFunc == nil {
+		return
+	}
+	return c.GetDoraDeploymentsFunc(ctx, releaseTargets, filters)
+}
+
+func (c MockClient) GetBuildAttempts(ctx context.Context, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	if c.GetBuildAttemptsFunc == nil {
+		return
+	}
+	return c.GetBuildAttemptsFunc(ctx, filters)
+}
+
+func (c MockClient) GetPipelineBuildAttempts(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	if c.GetPipelineBuildAttemptsFunc == nil {
+		return
+	}
+	return c.GetPipelineBuildAttemptsFunc(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c MockClient) GetBuildStageStatuses(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error) {
+	if c.GetBuildStageStatusesFunc == nil {
+		return
+	}
+	return c.GetBuildStageStatusesFunc(ctx, buildIDs)
+}
+
+func (c MockClient) GetStageRuns(ctx context.Context, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	if c.GetStageRunsFunc == nil {
+		return
+	}
+	return c.GetStageRunsFunc(ctx, filters)
+}
+
+func (c MockClient) GetPipelineStageRuns(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	if c.GetPipelineStageRunsFunc == nil {
+		return
+	}
+	return c.GetPipelineStageRunsFunc(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c MockClient) GetJobUsages(ctx context.Context, filters map[api.FilterType][]string) (usages []*api.JobUsage, err error) {
+	if c.GetJobUsagesFunc == nil {
+		return
+	}
+	return c.GetJobUsagesFunc(ctx, filters)
+}
+
 func (c MockClient) GetTriggers(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error) {
 	if c.GetTriggersFunc == nil {
 		return
@@ -608,6 +711,13 @@ func (c MockClient) GetCronTriggers(ctx context.Context) (pipelines []*contracts
 	return c.GetCronTriggersFunc(ctx)
 }
 
+func (c MockClient) GetDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error) {
+	if c.GetDockerTriggersFunc == nil {
+		return
+	}
+	return c.GetDockerTriggersFunc(ctx, dockerEvent)
+}
+
 func (c MockClient) Rename(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	if c.RenameFunc == nil {
 		return
@@ -867,6 +977,181 @@ func (c MockClient) GetClientsCount(ctx context.Context, filters map[api.FilterT
 	return c.GetClientsCountFunc(ctx, filters)
 }
 
+func (c MockClient) InsertPersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	if c.InsertPersonalAccessTokenFunc == nil {
+		return
+	}
+	return c.InsertPersonalAccessTokenFunc(ctx, token)
+}
+
+func (c MockClient) UpdatePersonalAccessTokenLastUsed(ctx context.Context, id, lastUsedFrom string) (err error) {
+	if c.UpdatePersonalAccessTokenLastUsedFunc == nil {
+		return
+	}
+	return c.UpdatePersonalAccessTokenLastUsedFunc(ctx, id, lastUsedFrom)
+}
+
+func (c MockClient) RevokePersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (err error) {
+	if c.RevokePersonalAccessTokenFunc == nil {
+		return
+	}
+	return c.RevokePersonalAccessTokenFunc(ctx, token)
+}
+
+func (c MockClient) GetPersonalAccessTokenByID(ctx context.Context, id string) (token *api.PersonalAccessToken, err error) {
+	if c.GetPersonalAccessTokenByIDFunc == nil {
+		return
+	}
+	return c.GetPersonalAccessTokenByIDFunc(ctx, id)
+}
+
+func (c MockClient) GetPersonalAccessTokens(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
+	if c.GetPersonalAccessTokensFunc == nil {
+		return
+	}
+	return c.GetPersonalAccessTokensFunc(ctx, userID, pageNumber, pageSize, filters, sortings)
+}
+
+func (c MockClient) GetPersonalAccessTokensCount(ctx context.Context, userID string, filters map[api.FilterType][]string) (count int, err error) {
+	if c.GetPersonalAccessTokensCountFunc == nil {
+		return
+	}
+	return c.GetPersonalAccessTokensCountFunc(ctx, userID, filters)
+}
+
+func (c MockClient) InsertCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	if c.InsertCustomRoleFunc == nil {
+		return
+	}
+	return c.InsertCustomRoleFunc(ctx, customRole)
+}
+
+func (c MockClient) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	if c.UpdateCustomRoleFunc == nil {
+		return
+	}
+	return c.UpdateCustomRoleFunc(ctx, customRole)
+}
+
+func (c MockClient) DeleteCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	if c.DeleteCustomRoleFunc == nil {
+		return
+	}
+	return c.DeleteCustomRoleFunc(ctx, customRole)
+}
+
+func (c MockClient) GetCustomRoleByID(ctx context.Context, id string) (customRole *api.CustomRole, err error) {
+	if c.GetCustomRoleByIDFunc == nil {
+		return
+	}
+	return c.GetCustomRoleByIDFunc(ctx, id)
+}
+
+func (c MockClient) GetCustomRolesByNames(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error) {
+	if c.GetCustomRolesByNamesFunc == nil {
+		return
+	}
+	return c.GetCustomRolesByNamesFunc(ctx, names)
+}
+
+func (c MockClient) GetCustomRoles(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (customRoles []*api.CustomRole, err error) {
+	if c.GetCustomRolesFunc == nil {
+		return
+	}
+	return c.GetCustomRolesFunc(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c MockClient) GetCustomRolesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	if c.GetCustomRolesCountFunc == nil {
+		return
+	}
+	return c.GetCustomRolesCountFunc(ctx, filters)
+}
+
+func (c MockClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	if c.InsertAuditEventFunc == nil {
+		return
+	}
+	return c.InsertAuditEventFunc(ctx, auditEvent)
+}
+
+func (c MockClient) GetAuditEvents(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (auditEvents []*api.AuditEvent, err error) {
+	if c.GetAuditEventsFunc == nil {
+		return
+	}
+	return c.GetAuditEventsFunc(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c MockClient) GetAuditEventsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	if c.GetAuditEventsCountFunc == nil {
+		return
+	}
+	return c.GetAuditEventsCountFunc(ctx, filters)
+}
+
+func (c MockClient) InsertPipelineWarning(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+	if c.InsertPipelineWarningFunc == nil {
+		return
+	}
+	return c.InsertPipelineWarningFunc(ctx, repoSource, repoOwner, repoName, warning)
+}
+
+func (c MockClient) GetPipelineWarnings(ctx context.Context, repoSource, repoOwner, repoName string, since time.Time) (warnings []contracts.Warning, err error) {
+	if c.GetPipelineWarningsFunc == nil {
+		return
+	}
+	return c.GetPipelineWarningsFunc(ctx, repoSource, repoOwner, repoName, since)
+}
+
+func (c MockClient) InsertWebhook(ctx context.Context, webhook api.Webhook) (insertedWebhook *api.Webhook, err error) {
+	if c.InsertWebhookFunc == nil {
+		return
+	}
+	return c.InsertWebhookFunc(ctx, webhook)
+}
+
+func (c MockClient) DeleteWebhook(ctx context.Context, webhook api.Webhook) (err error) {
+	if c.DeleteWebhookFunc == nil {
+		return
+	}
+	return c.DeleteWebhookFunc(ctx, webhook)
+}
+
+func (c MockClient) GetWebhookByID(ctx context.Context, id string) (webhook *api.Webhook, err error) {
+	if c.GetWebhookByIDFunc == nil {
+		return
+	}
+	return c.GetWebhookByIDFunc(ctx, id)
+}
+
+func (c MockClient) GetPipelineWebhooks(ctx context.Context, repoSource, repoOwner, repoName string) (webhooks []*api.Webhook, err error) {
+	if c.GetPipelineWebhooksFunc == nil {
+		return
+	}
+	return c.GetPipelineWebhooksFunc(ctx, repoSource, repoOwner, repoName)
+}
+
+func (c MockClient) InsertWebhookDelivery(ctx context.Context, delivery api.WebhookDelivery) (insertedDelivery *api.WebhookDelivery, err error) {
+	if c.InsertWebhookDeliveryFunc == nil {
+		return
+	}
+	return c.InsertWebhookDeliveryFunc(ctx, delivery)
+}
+
+func (c MockClient) GetPipelineWebhookDeliveries(ctx context.Context, repoSource, repoOwner, repoName string, pageNumber, pageSize int) (deliveries []*api.WebhookDelivery, err error) {
+	if c.GetPipelineWebhookDeliveriesFunc == nil {
+		return
+	}
+	return c.GetPipelineWebhookDeliveriesFunc(ctx, repoSource, repoOwner, repoName, pageNumber, pageSize)
+}
+
+func (c MockClient) GetPipelineWebhookDeliveriesCount(ctx context.Context, repoSource, repoOwner, repoName string) (count int, err error) {
+	if c.GetPipelineWebhookDeliveriesCountFunc == nil {
+		return
+	}
+	return c.GetPipelineWebhookDeliveriesCountFunc(ctx, repoSource, repoOwner, repoName)
+}
+
 func (c MockClient) InsertCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
 	if c.InsertCatalogEntityFunc == nil {
 		return
@@ -978,3 +1263,10 @@ func (c MockClient) GetCatalogEntityLabelsCount(ctx context.Context, filters map
 	}
 	return c.GetCatalogEntityLabelsCountFunc(ctx, filters)
 }
+
+func (c MockClient) CheckHealth(ctx context.Context) (err error) {
+	if c.CheckHealthFunc == nil {
+		return
+	}
+	return c.CheckHealthFunc(ctx)
+}
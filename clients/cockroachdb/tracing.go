@@ -62,6 +62,20 @@ func (c *tracingClient) UpdateBuildResourceUtilization(ctx context.Context, repo
 	return c.Client.UpdateBuildResourceUtilization(ctx, repoSource, repoOwner, repoName, buildID, jobResources)
 }
 
+func (c *tracingClient) UpdateBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, parameters map[string]string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "UpdateBuildParameters"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.UpdateBuildParameters(ctx, repoSource, repoOwner, repoName, buildID, parameters)
+}
+
+func (c *tracingClient) GetBuildParameters(ctx context.Context, repoSource, repoOwner, repoName string, buildID int) (parameters map[string]string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetBuildParameters"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetBuildParameters(ctx, repoSource, repoOwner, repoName, buildID)
+}
+
 func (c *tracingClient) InsertRelease(ctx context.Context, release contracts.Release, jobResources JobResources) (r *contracts.Release, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertRelease"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -447,6 +461,55 @@ func (c *tracingClient) GetPipelinesWithMostReleasesCount(ctx context.Context, f
 	return c.Client.GetPipelinesWithMostReleasesCount(ctx, filters)
 }
 
+func (c *tracingClient) GetDoraDeployments(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetDoraDeployments"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetDoraDeployments(ctx, releaseTargets, filters)
+}
+
+func (c *tracingClient) GetBuildAttempts(ctx context.Context, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetBuildAttempts"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetBuildAttempts(ctx, filters)
+}
+
+func (c *tracingClient) GetPipelineBuildAttempts(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPipelineBuildAttempts"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPipelineBuildAttempts(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c *tracingClient) GetBuildStageStatuses(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetBuildStageStatuses"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetBuildStageStatuses(ctx, buildIDs)
+}
+
+func (c *tracingClient) GetStageRuns(ctx context.Context, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetStageRuns"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetStageRuns(ctx, filters)
+}
+
+func (c *tracingClient) GetPipelineStageRuns(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (runs []*api.StageRun, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPipelineStageRuns"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPipelineStageRuns(ctx, repoSource, repoOwner, repoName, filters)
+}
+
+func (c *tracingClient) GetJobUsages(ctx context.Context, filters map[api.FilterType][]string) (usages []*api.JobUsage, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetJobUsages"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetJobUsages(ctx, filters)
+}
+
 func (c *tracingClient) GetTriggers(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetTriggers"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -489,6 +552,13 @@ func (c *tracingClient) GetCronTriggers(ctx context.Context) (pipelines []*contr
 	return c.Client.GetCronTriggers(ctx)
 }
 
+func (c *tracingClient) GetDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetDockerTriggers"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetDockerTriggers(ctx, dockerEvent)
+}
+
 func (c *tracingClient) Rename(ctx context.Context, shortFromRepoSource, fromRepoSource, fromRepoOwner, fromRepoName, shortToRepoSource, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "Rename"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -748,6 +818,181 @@ func (c *tracingClient) GetClientsCount(ctx context.Context, filters map[api.Fil
 	return c.Client.GetClientsCount(ctx, filters)
 }
 
+func (c *tracingClient) InsertPersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertPersonalAccessToken"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.InsertPersonalAccessToken(ctx, token)
+}
+
+func (c *tracingClient) UpdatePersonalAccessTokenLastUsed(ctx context.Context, id, lastUsedFrom string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "UpdatePersonalAccessTokenLastUsed"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.UpdatePersonalAccessTokenLastUsed(ctx, id, lastUsedFrom)
+}
+
+func (c *tracingClient) RevokePersonalAccessToken(ctx context.Context, token api.PersonalAccessToken) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "RevokePersonalAccessToken"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.RevokePersonalAccessToken(ctx, token)
+}
+
+func (c *tracingClient) GetPersonalAccessTokenByID(ctx context.Context, id string) (token *api.PersonalAccessToken, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPersonalAccessTokenByID"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPersonalAccessTokenByID(ctx, id)
+}
+
+func (c *tracingClient) GetPersonalAccessTokens(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPersonalAccessTokens"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPersonalAccessTokens(ctx, userID, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *tracingClient) GetPersonalAccessTokensCount(ctx context.Context, userID string, filters map[api.FilterType][]string) (count int, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPersonalAccessTokensCount"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPersonalAccessTokensCount(ctx, userID, filters)
+}
+
+func (c *tracingClient) InsertCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertCustomRole"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.InsertCustomRole(ctx, customRole)
+}
+
+func (c *tracingClient) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "UpdateCustomRole"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.UpdateCustomRole(ctx, customRole)
+}
+
+func (c *tracingClient) DeleteCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "DeleteCustomRole"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.DeleteCustomRole(ctx, customRole)
+}
+
+func (c *tracingClient) GetCustomRoleByID(ctx context.Context, id string) (customRole *api.CustomRole, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetCustomRoleByID"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetCustomRoleByID(ctx, id)
+}
+
+func (c *tracingClient) GetCustomRolesByNames(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetCustomRolesByNames"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetCustomRolesByNames(ctx, names)
+}
+
+func (c *tracingClient) GetCustomRoles(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (customRoles []*api.CustomRole, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetCustomRoles"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetCustomRoles(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *tracingClient) GetCustomRolesCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetCustomRolesCount"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetCustomRolesCount(ctx, filters)
+}
+
+func (c *tracingClient) InsertAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertAuditEvent"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.InsertAuditEvent(ctx, auditEvent)
+}
+
+func (c *tracingClient) GetAuditEvents(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (auditEvents []*api.AuditEvent, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetAuditEvents"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetAuditEvents(ctx, pageNumber, pageSize, filters, sortings)
+}
+
+func (c *tracingClient) GetAuditEventsCount(ctx context.Context, filters map[api.FilterType][]string) (count int, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetAuditEventsCount"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetAuditEventsCount(ctx, filters)
+}
+
+func (c *tracingClient) InsertPipelineWarning(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertPipelineWarning"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.InsertPipelineWarning(ctx, repoSource, repoOwner, repoName, warning)
+}
+
+func (c *tracingClient) GetPipelineWarnings(ctx context.Context, repoSource, repoOwner, repoName string, since time.Time) (warnings []contracts.Warning, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPipelineWarnings"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPipelineWarnings(ctx, repoSource, repoOwner, repoName, since)
+}
+
+func (c *tracingClient) InsertWebhook(ctx context.Context, webhook api.Webhook) (insertedWebhook *api.Webhook, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertWebhook"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.InsertWebhook(ctx, webhook)
+}
+
+func (c *tracingClient) DeleteWebhook(ctx context.Context, webhook api.Webhook) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "DeleteWebhook"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.DeleteWebhook(ctx, webhook)
+}
+
+func (c *tracingClient) GetWebhookByID(ctx context.Context, id string) (webhook *api.Webhook, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetWebhookByID"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetWebhookByID(ctx, id)
+}
+
+func (c *tracingClient) GetPipelineWebhooks(ctx context.Context, repoSource, repoOwner, repoName string) (webhooks []*api.Webhook, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPipelineWebhooks"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPipelineWebhooks(ctx, repoSource, repoOwner, repoName)
+}
+
+func (c *tracingClient) InsertWebhookDelivery(ctx context.Context, delivery api.WebhookDelivery) (insertedDelivery *api.WebhookDelivery, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertWebhookDelivery"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.InsertWebhookDelivery(ctx, delivery)
+}
+
+func (c *tracingClient) GetPipelineWebhookDeliveries(ctx context.Context, repoSource, repoOwner, repoName string, pageNumber, pageSize int) (deliveries []*api.WebhookDelivery, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPipelineWebhookDeliveries"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPipelineWebhookDeliveries(ctx, repoSource, repoOwner, repoName, pageNumber, pageSize)
+}
+
+func (c *tracingClient) GetPipelineWebhookDeliveriesCount(ctx context.Context, repoSource, repoOwner, repoName string) (count int, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetPipelineWebhookDeliveriesCount"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetPipelineWebhookDeliveriesCount(ctx, repoSource, repoOwner, repoName)
+}
+
 func (c *tracingClient) InsertCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "InsertCatalogEntity"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -859,3 +1104,10 @@ func (c *tracingClient) GetCatalogEntityLabelsCount(ctx context.Context, filters
 
 	return c.Client.GetCatalogEntityLabelsCount(ctx, filters)
 }
+
+func (c *tracingClient) CheckHealth(ctx context.Context) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "CheckHealth"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
@@ -0,0 +1,79 @@
+package dockerhubapi
+
+import (
+	"fmt"
+	"time"
+)
+
+// DockerHubPushEvent is the payload docker hub sends to a repository webhook when an image is pushed
+type DockerHubPushEvent struct {
+	CallbackURL string              `json:"callback_url"`
+	PushData    DockerHubPushData   `json:"push_data"`
+	Repository  DockerHubRepository `json:"repository"`
+}
+
+// DockerHubPushData holds the pushed tag
+type DockerHubPushData struct {
+	PushedAt int64  `json:"pushed_at"`
+	Pusher   string `json:"pusher"`
+	Tag      string `json:"tag"`
+}
+
+// DockerHubRepository is the docker hub repository the image is pushed to
+type DockerHubRepository struct {
+	RepoName  string `json:"repo_name"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// GetImage returns the pushed image name, like estafette/estafette-ci-builder
+func (e *DockerHubPushEvent) GetImage() string {
+	if e.Repository.RepoName != "" {
+		return e.Repository.RepoName
+	}
+
+	return fmt.Sprintf("%v/%v", e.Repository.Namespace, e.Repository.Name)
+}
+
+// RegistryNotificationEnvelope is the payload of notifications sent by registries implementing the docker distribution notification format
+type RegistryNotificationEnvelope struct {
+	Events []RegistryNotificationEvent `json:"events"`
+}
+
+// RegistryNotificationEvent is a single action on a registry repository
+type RegistryNotificationEvent struct {
+	ID        string                      `json:"id"`
+	Timestamp time.Time                   `json:"timestamp"`
+	Action    string                      `json:"action"`
+	Target    RegistryNotificationTarget  `json:"target"`
+	Request   RegistryNotificationRequest `json:"request"`
+}
+
+// RegistryNotificationTarget is the manifest or blob the action applies to
+type RegistryNotificationTarget struct {
+	MediaType  string `json:"mediaType"`
+	Digest     string `json:"digest"`
+	Repository string `json:"repository"`
+	URL        string `json:"url"`
+	Tag        string `json:"tag"`
+}
+
+// RegistryNotificationRequest describes the request causing the event
+type RegistryNotificationRequest struct {
+	ID   string `json:"id"`
+	Host string `json:"host"`
+}
+
+// IsTaggedManifestPush returns true for pushes of an image manifest with a tag; layer blobs are pushed without tag and are ignored
+func (e *RegistryNotificationEvent) IsTaggedManifestPush() bool {
+	return e.Action == "push" && e.Target.Tag != "" && e.Target.Digest != ""
+}
+
+// GetImage returns the pushed image name including the registry host, like registry.example.com/team/app
+func (e *RegistryNotificationEvent) GetImage() string {
+	if e.Request.Host == "" {
+		return e.Target.Repository
+	}
+
+	return fmt.Sprintf("%v/%v", e.Request.Host, e.Target.Repository)
+}
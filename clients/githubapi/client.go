@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -27,7 +28,11 @@ type Client interface {
 	GetInstallationToken(ctx context.Context, installationID int) (token AccessToken, err error)
 	GetAuthenticatedRepositoryURL(ctx context.Context, accesstoken AccessToken, htmlURL string) (url string, err error)
 	GetEstafetteManifest(ctx context.Context, accesstoken AccessToken, event PushEvent) (valid bool, manifest string, err error)
+	GetRevision(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error)
+	IsRevisionOnBranch(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error)
 	JobVarsFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error)
+	ManifestFunc(ctx context.Context) func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error)
+	CheckHealth(ctx context.Context) (err error)
 }
 
 // NewClient creates an githubapi.Client to communicate with the Github api
@@ -176,6 +181,64 @@ func (c *client) GetEstafetteManifest(ctx context.Context, accessToken AccessTok
 	return
 }
 
+// GetRevision returns the commit sha for a branch or (abbreviated) revision; it's empty if the ref doesn't exist
+func (c *client) GetRevision(ctx context.Context, accessToken AccessToken, repoFullName, ref string) (revision string, err error) {
+
+	// https://developer.github.com/v3/repos/commits/#get-a-single-commit
+
+	statusCode, body, err := c.callGithubAPI(ctx, "GET", fmt.Sprintf("https://api.github.com/repos/%v/commits/%v", repoFullName, ref), nil, "token", accessToken.Token)
+	if err != nil {
+		return
+	}
+
+	if statusCode == http.StatusNotFound || statusCode == http.StatusUnprocessableEntity {
+		return
+	}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+
+	// unmarshal json body
+	err = json.Unmarshal(body, &commit)
+	if err != nil {
+		return
+	}
+
+	return commit.SHA, nil
+}
+
+// IsRevisionOnBranch returns true if the revision is the head of the branch or one of its ancestors; getting a commit by sha succeeds for any commit in the fork network, so it doesn't prove the commit is on the branch
+func (c *client) IsRevisionOnBranch(ctx context.Context, accessToken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error) {
+
+	// https://developer.github.com/v3/repos/commits/#compare-two-commits
+
+	statusCode, body, err := c.callGithubAPI(ctx, "GET", fmt.Sprintf("https://api.github.com/repos/%v/compare/%v...%v", repoFullName, url.PathEscape(branch), url.PathEscape(revision)), nil, "token", accessToken.Token)
+	if err != nil {
+		return
+	}
+
+	if statusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if statusCode != http.StatusOK {
+		return false, fmt.Errorf("Comparing revision %v to branch %v of %v returned status code %v", revision, branch, repoFullName, statusCode)
+	}
+
+	var comparison struct {
+		Status string `json:"status"`
+	}
+
+	// unmarshal json body
+	err = json.Unmarshal(body, &comparison)
+	if err != nil {
+		return
+	}
+
+	// the revision is behind or identical to the branch if it's part of its history
+	return comparison.Status == "behind" || comparison.Status == "identical", nil
+}
+
 // JobVarsFunc returns a function that can get an access token and authenticated url for a repository
 func (c *client) JobVarsFunc(ctx context.Context) func(context.Context, string, string, string) (string, string, error) {
 	return func(ctx context.Context, repoSource, repoOwner, repoName string) (token string, url string, err error) {
@@ -201,6 +264,53 @@ func (c *client) JobVarsFunc(ctx context.Context) func(context.Context, string,
 	}
 }
 
+// ManifestFunc returns a function that can resolve a branch or revision of a repository and retrieve the manifest at that revision
+func (c *client) ManifestFunc(ctx context.Context) func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+	return func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (resolvedRevision string, exists bool, manifest string, err error) {
+		// get installation id with just the repo owner
+		installationID, err := c.GetInstallationID(ctx, repoOwner)
+		if err != nil {
+			return
+		}
+
+		// get access token
+		accessToken, err := c.GetInstallationToken(ctx, installationID)
+		if err != nil {
+			return
+		}
+
+		ref := revision
+		if ref == "" {
+			ref = branch
+		}
+
+		repoFullName := fmt.Sprintf("%v/%v", repoOwner, repoName)
+		resolvedRevision, err = c.GetRevision(ctx, accessToken, repoFullName, ref)
+		if err != nil || resolvedRevision == "" {
+			return
+		}
+
+		if revision != "" {
+			onBranch, err := c.IsRevisionOnBranch(ctx, accessToken, repoFullName, branch, resolvedRevision)
+			if err != nil {
+				return "", false, "", err
+			}
+			if !onBranch {
+				return "", false, "", fmt.Errorf("Revision %v of %v is not on branch %v: %w", resolvedRevision, repoFullName, branch, api.ErrRevisionNotOnBranch)
+			}
+		}
+
+		exists, manifest, err = c.GetEstafetteManifest(ctx, accessToken, PushEvent{
+			After: resolvedRevision,
+			Repository: Repository{
+				FullName: repoFullName,
+			},
+		})
+
+		return
+	}
+}
+
 func (c *client) callGithubAPI(ctx context.Context, method, url string, params interface{}, authorizationType, token string) (statusCode int, body []byte, err error) {
 
 	// convert params to json if they're present
@@ -274,3 +384,22 @@ func (c *client) callGithubAPI(ctx context.Context, method, url string, params i
 
 	return
 }
+
+// CheckHealth verifies Github accepts a token signed with the app's private key
+func (c *client) CheckHealth(ctx context.Context) (err error) {
+
+	githubAppToken, err := c.GetGithubAppToken(ctx)
+	if err != nil {
+		return
+	}
+
+	statusCode, _, err := c.callGithubAPI(ctx, "GET", "https://api.github.com/app", nil, "Bearer", githubAppToken)
+	if err != nil {
+		return
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("Retrieving the Github app failed with status code %v", statusCode)
+	}
+
+	return
+}
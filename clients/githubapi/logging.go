@@ -46,6 +46,28 @@ func (c *loggingClient) GetEstafetteManifest(ctx context.Context, accesstoken Ac
 	return c.Client.GetEstafetteManifest(ctx, accesstoken, event)
 }
 
+func (c *loggingClient) GetRevision(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetRevision", err) }()
+
+	return c.Client.GetRevision(ctx, accesstoken, repoFullName, ref)
+}
+
+func (c *loggingClient) IsRevisionOnBranch(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error) {
+	defer func() { api.HandleLogError(c.prefix, "IsRevisionOnBranch", err) }()
+
+	return c.Client.IsRevisionOnBranch(ctx, accesstoken, repoFullName, branch, revision)
+}
+
 func (c *loggingClient) JobVarsFunc(ctx context.Context) func(context.Context, string, string, string) (string, string, error) {
 	return c.Client.JobVarsFunc(ctx)
 }
+
+func (c *loggingClient) ManifestFunc(ctx context.Context) func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+	return c.Client.ManifestFunc(ctx)
+}
+
+func (c *loggingClient) CheckHealth(ctx context.Context) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "CheckHealth", err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
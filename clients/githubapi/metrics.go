@@ -59,8 +59,38 @@ func (c *metricsClient) GetEstafetteManifest(ctx context.Context, accesstoken Ac
 	return c.Client.GetEstafetteManifest(ctx, accesstoken, event)
 }
 
+func (c *metricsClient) GetRevision(ctx context.Context, accesstoken AccessToken, repoFullName, ref string) (revision string, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetRevision", begin)
+	}(time.Now())
+
+	return c.Client.GetRevision(ctx, accesstoken, repoFullName, ref)
+}
+
+func (c *metricsClient) IsRevisionOnBranch(ctx context.Context, accesstoken AccessToken, repoFullName, branch, revision string) (onBranch bool, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "IsRevisionOnBranch", begin)
+	}(time.Now())
+
+	return c.Client.IsRevisionOnBranch(ctx, accesstoken, repoFullName, branch, revision)
+}
+
 func (c *metricsClient) JobVarsFunc(ctx context.Context) func(context.Context, string, string, string) (string, string, error) {
 	defer func(begin time.Time) { api.UpdateMetrics(c.requestCount, c.requestLatency, "JobVarsFunc", begin) }(time.Now())
 
 	return c.Client.JobVarsFunc(ctx)
 }
+
+func (c *metricsClient) ManifestFunc(ctx context.Context) func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+	defer func(begin time.Time) { api.UpdateMetrics(c.requestCount, c.requestLatency, "ManifestFunc", begin) }(time.Now())
+
+	return c.Client.ManifestFunc(ctx)
+}
+
+func (c *metricsClient) CheckHealth(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "CheckHealth", begin)
+	}(time.Now())
+
+	return c.Client.CheckHealth(ctx)
+}
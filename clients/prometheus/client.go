@@ -7,6 +7,7 @@ import (
 
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"strconv"
 
@@ -21,6 +22,7 @@ type Client interface {
 	AwaitScrapeInterval(ctx context.Context)
 	GetMaxMemoryByPodName(ctx context.Context, podName string) (max float64, err error)
 	GetMaxCPUByPodName(ctx context.Context, podName string) (max float64, err error)
+	CheckHealth(ctx context.Context) (err error)
 }
 
 // NewClient creates an prometheus.Client to communicate with Prometheus
@@ -106,3 +108,25 @@ func (c *client) getQueryResult(query string) (float64, error) {
 
 	return f, nil
 }
+
+// CheckHealth verifies the prometheus server reports itself healthy
+func (c *client) CheckHealth(ctx context.Context) (err error) {
+
+	request, err := http.NewRequest("GET", fmt.Sprintf("%v/-/healthy", c.config.Integrations.Prometheus.ServerURL), nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := http.DefaultClient.Do(request.WithContext(ctx))
+	if err != nil {
+		return
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Prometheus health endpoint returned status code %v", resp.StatusCode)
+	}
+
+	return
+}
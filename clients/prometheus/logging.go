@@ -31,3 +31,9 @@ func (c *loggingClient) GetMaxCPUByPodName(ctx context.Context, podName string)
 
 	return c.Client.GetMaxCPUByPodName(ctx, podName)
 }
+
+func (c *loggingClient) CheckHealth(ctx context.Context) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "CheckHealth", err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
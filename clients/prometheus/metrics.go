@@ -42,3 +42,11 @@ func (c *metricsClient) GetMaxCPUByPodName(ctx context.Context, podName string)
 
 	return c.Client.GetMaxCPUByPodName(ctx, podName)
 }
+
+func (c *metricsClient) CheckHealth(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "CheckHealth", begin)
+	}(time.Now())
+
+	return c.Client.CheckHealth(ctx)
+}
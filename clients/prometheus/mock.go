@@ -8,6 +8,7 @@ type MockClient struct {
 	AwaitScrapeIntervalFunc   func(ctx context.Context)
 	GetMaxMemoryByPodNameFunc func(ctx context.Context, podName string) (max float64, err error)
 	GetMaxCPUByPodNameFunc    func(ctx context.Context, podName string) (max float64, err error)
+	CheckHealthFunc           func(ctx context.Context) (err error)
 }
 
 func (c MockClient) AwaitScrapeInterval(ctx context.Context) {
@@ -30,3 +31,10 @@ func (c MockClient) GetMaxCPUByPodName(ctx context.Context, podName string) (max
 	}
 	return c.GetMaxCPUByPodNameFunc(ctx, podName)
 }
+
+func (c MockClient) CheckHealth(ctx context.Context) (err error) {
+	if c.CheckHealthFunc == nil {
+		return
+	}
+	return c.CheckHealthFunc(ctx)
+}
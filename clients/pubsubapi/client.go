@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	stdpubsub "cloud.google.com/go/pubsub"
 	"github.com/estafette/estafette-ci-api/api"
 	manifest "github.com/estafette/estafette-ci-manifest"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/api/iterator"
 )
 
 // Client is the interface for communicating with the pubsub apis
@@ -17,6 +19,9 @@ type Client interface {
 	SubscriptionForTopic(ctx context.Context, message PubSubPushMessage) (event *manifest.EstafettePubSubEvent, err error)
 	SubscribeToTopic(ctx context.Context, projectID, topicID string) (err error)
 	SubscribeToPubsubTriggers(ctx context.Context, manifestString string) (err error)
+	GetSubscriptions(ctx context.Context) (subscriptions []*Subscription, err error)
+	DeleteSubscription(ctx context.Context, projectID, subscriptionName string) (err error)
+	CheckHealth(ctx context.Context) (err error)
 }
 
 // NewClient returns a new pubsub.Client
@@ -30,6 +35,9 @@ func NewClient(config *api.APIConfig, pubsubClient *stdpubsub.Client) Client {
 type client struct {
 	config       *api.APIConfig
 	pubsubClient *stdpubsub.Client
+
+	// subscriptionTopics caches the topic for subscriptions that aren't named after their topic
+	subscriptionTopics sync.Map
 }
 
 func (c *client) SubscriptionForTopic(ctx context.Context, message PubSubPushMessage) (*manifest.EstafettePubSubEvent, error) {
@@ -37,11 +45,11 @@ func (c *client) SubscriptionForTopic(ctx context.Context, message PubSubPushMes
 	projectID := message.GetProject()
 	subscriptionName := message.GetSubscription()
 
-	if strings.HasSuffix(subscriptionName, c.config.Integrations.Pubsub.SubscriptionNameSuffix) {
-		return &manifest.EstafettePubSubEvent{
-			Project: projectID,
-			Topic:   strings.TrimSuffix(subscriptionName, c.config.Integrations.Pubsub.SubscriptionNameSuffix),
-		}, nil
+	// the topic can live in another project than the subscription, so get it from the subscription config rather than from its name
+	subscriptionKey := fmt.Sprintf("projects/%v/subscriptions/%v", projectID, subscriptionName)
+	if event, ok := c.subscriptionTopics.Load(subscriptionKey); ok {
+		e := event.(manifest.EstafettePubSubEvent)
+		return &e, nil
 	}
 
 	subscription := c.pubsubClient.SubscriptionInProject(subscriptionName, projectID)
@@ -53,11 +61,17 @@ func (c *client) SubscriptionForTopic(ctx context.Context, message PubSubPushMes
 	if err != nil {
 		return nil, err
 	}
+	if subscriptionConfig.Topic == nil {
+		return nil, fmt.Errorf("Subscription %v in project %v has no topic", subscriptionName, projectID)
+	}
 
-	return &manifest.EstafettePubSubEvent{
-		Project: projectID,
+	event := manifest.EstafettePubSubEvent{
+		Project: getTopicProject(subscriptionConfig.Topic, projectID),
 		Topic:   subscriptionConfig.Topic.ID(),
-	}, nil
+	}
+	c.subscriptionTopics.Store(subscriptionKey, event)
+
+	return &event, nil
 }
 
 func (c *client) SubscribeToTopic(ctx context.Context, projectID, topicID string) error {
@@ -72,21 +86,22 @@ func (c *client) SubscribeToTopic(ctx context.Context, projectID, topicID string
 		return fmt.Errorf("Pub/Sub topic %v does not exist in project %v, cannot subscribe to it", topicID, projectID)
 	}
 
-	// check if subscription already exists
-	subscriptionName := c.getSubscriptionName(topicID)
-	subscription := c.pubsubClient.SubscriptionInProject(subscriptionName, projectID)
-	log.Info().Msgf("Checking if subscription %v for topic %v in project %v exists...", subscriptionName, topicID, projectID)
+	// subscriptions are always created in the default project, whatever project the topic lives in
+	subscriptionName := c.getSubscriptionName(projectID, topicID)
+	subscriptionProject := c.config.Integrations.Pubsub.DefaultProject
+	subscription := c.pubsubClient.Subscription(subscriptionName)
+	log.Info().Msgf("Checking if subscription %v in project %v for topic %v in project %v exists...", subscriptionName, subscriptionProject, topicID, projectID)
 	subscriptionExists, err := subscription.Exists(context.Background())
 	if err != nil {
 		return err
 	}
 	if subscriptionExists {
-		// already exists, no need to do anything
-		return nil
+		// already exists, no need to do anything as long as it's for this topic
+		return c.checkSubscriptionTopic(ctx, subscription, projectID, topicID)
 	}
 
 	// create a subscription to the topic
-	log.Info().Msgf("Creating subscription %v for topic %v in project %v...", subscriptionName, topicID, projectID)
+	log.Info().Msgf("Creating subscription %v in project %v for topic %v in project %v...", subscriptionName, subscriptionProject, topicID, projectID)
 	_, err = c.pubsubClient.CreateSubscription(context.Background(), subscriptionName, stdpubsub.SubscriptionConfig{
 		Topic: topic,
 		PushConfig: stdpubsub.PushConfig{
@@ -101,17 +116,39 @@ func (c *client) SubscribeToTopic(ctx context.Context, projectID, topicID string
 		ExpirationPolicy:  time.Duration(c.config.Integrations.Pubsub.SubscriptionIdleExpirationDays) * 24 * time.Hour,
 	})
 	if err != nil {
+		// another replica can have created it in the meantime
+		if subscriptionExists, existsErr := subscription.Exists(context.Background()); existsErr == nil && subscriptionExists {
+			return c.checkSubscriptionTopic(ctx, subscription, projectID, topicID)
+		}
 		return err
 	}
 
-	log.Info().Msgf("Created subscription %v in project %v", subscriptionName, projectID)
+	log.Info().Msgf("Created subscription %v in project %v", subscriptionName, subscriptionProject)
+
+	return nil
+}
+
+// checkSubscriptionTopic returns an error if an existing subscription is for another topic than the one it's expected to be for
+func (c *client) checkSubscriptionTopic(ctx context.Context, subscription *stdpubsub.Subscription, projectID, topicID string) error {
+	subscriptionConfig, err := subscription.Config(ctx)
+	if err != nil {
+		return err
+	}
+	if subscriptionConfig.Topic == nil || subscriptionConfig.Topic.ID() != topicID || getTopicProject(subscriptionConfig.Topic, "") != projectID {
+		return fmt.Errorf("Subscription %v already exists for another topic than %v in project %v", subscription.ID(), topicID, projectID)
+	}
 
 	return nil
 }
 
-func (c *client) getSubscriptionName(topicName string) string {
+func (c *client) getSubscriptionName(projectID, topicID string) string {
 	// it must start with a letter, and contain only letters ([A-Za-z]), numbers ([0-9]), dashes (-), underscores (_), periods (.), tildes (~), plus (+) or percent signs (%). It must be between 3 and 255 characters in length, and must not start with "goog".
-	return topicName + c.config.Integrations.Pubsub.SubscriptionNameSuffix
+	// topics in the default project keep the name used before topics in other projects were supported; others get their project as prefix, so equally named topics in different projects don't share a subscription
+	if projectID == c.config.Integrations.Pubsub.DefaultProject {
+		return topicID + c.config.Integrations.Pubsub.SubscriptionNameSuffix
+	}
+
+	return projectID + "." + topicID + c.config.Integrations.Pubsub.SubscriptionNameSuffix
 }
 
 func (c *client) SubscribeToPubsubTriggers(ctx context.Context, manifestString string) error {
@@ -133,3 +170,74 @@ func (c *client) SubscribeToPubsubTriggers(ctx context.Context, manifestString s
 	}
 	return nil
 }
+
+// GetSubscriptions returns all subscriptions created by this api, recognizable by the configured subscription name suffix
+func (c *client) GetSubscriptions(ctx context.Context) (subscriptions []*Subscription, err error) {
+
+	subscriptions = make([]*Subscription, 0)
+
+	// without a suffix there's no telling which subscriptions are created by this api
+	if c.config.Integrations.Pubsub.SubscriptionNameSuffix == "" {
+		return subscriptions, nil
+	}
+
+	it := c.pubsubClient.Subscriptions(ctx)
+	for {
+		subscription, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasSuffix(subscription.ID(), c.config.Integrations.Pubsub.SubscriptionNameSuffix) {
+			continue
+		}
+
+		subscriptionConfig, err := subscription.Config(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		s := &Subscription{
+			Project: c.config.Integrations.Pubsub.DefaultProject,
+			Name:    subscription.ID(),
+		}
+		if subscriptionConfig.Topic != nil {
+			s.TopicProject = getTopicProject(subscriptionConfig.Topic, "")
+			s.Topic = subscriptionConfig.Topic.ID()
+		}
+
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, nil
+}
+
+// getTopicProject returns the project from the topic name, which is formatted as projects/<project>/topics/<topic>
+func getTopicProject(topic *stdpubsub.Topic, defaultProject string) string {
+	topicNameParts := strings.Split(topic.String(), "/")
+	if len(topicNameParts) == 4 {
+		return topicNameParts[1]
+	}
+
+	return defaultProject
+}
+
+func (c *client) DeleteSubscription(ctx context.Context, projectID, subscriptionName string) error {
+
+	log.Info().Msgf("Deleting subscription %v in project %v...", subscriptionName, projectID)
+
+	return c.pubsubClient.SubscriptionInProject(subscriptionName, projectID).Delete(ctx)
+}
+
+// CheckHealth verifies the topics of the default project can be listed
+func (c *client) CheckHealth(ctx context.Context) (err error) {
+	_, err = c.pubsubClient.Topics(ctx).Next()
+	if err == iterator.Done {
+		return nil
+	}
+
+	return
+}
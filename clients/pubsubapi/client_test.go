@@ -0,0 +1,40 @@
+package pubsubapi
+
+import (
+	"testing"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSubscriptionName(t *testing.T) {
+
+	c := &client{
+		config: &api.APIConfig{
+			Integrations: &api.APIConfigIntegrations{
+				Pubsub: &api.PubsubConfig{
+					DefaultProject:         "estafette",
+					SubscriptionNameSuffix: "~estafette-ci-pubsub-trigger",
+				},
+			},
+		},
+	}
+
+	t.Run("ReturnsTopicWithSuffixForTopicInDefaultProject", func(t *testing.T) {
+
+		// act
+		name := c.getSubscriptionName("estafette", "builds")
+
+		assert.Equal(t, "builds~estafette-ci-pubsub-trigger", name)
+	})
+
+	t.Run("ReturnsDistinctNamesForEquallyNamedTopicsInOtherProjects", func(t *testing.T) {
+
+		// act
+		nameA := c.getSubscriptionName("project-a", "builds")
+		nameB := c.getSubscriptionName("project-b", "builds")
+
+		assert.Equal(t, "project-a.builds~estafette-ci-pubsub-trigger", nameA)
+		assert.NotEqual(t, nameA, nameB)
+	})
+}
@@ -34,3 +34,23 @@ func (m PubSubPushMessage) GetDecodedData() string {
 	}
 	return string(data)
 }
+
+// Subscription is a push subscription created for pubsub triggers
+type Subscription struct {
+	Project      string `json:"project"`
+	Name         string `json:"name"`
+	TopicProject string `json:"topicProject"`
+	Topic        string `json:"topic"`
+}
+
+// SubscriptionStatus describes the health of the subscription needed for a pipeline's pubsub trigger
+type SubscriptionStatus struct {
+	RepoSource   string `json:"repoSource"`
+	RepoOwner    string `json:"repoOwner"`
+	RepoName     string `json:"repoName"`
+	TopicProject string `json:"topicProject"`
+	Topic        string `json:"topic"`
+	Subscription string `json:"subscription,omitempty"`
+	Status       string `json:"status"`
+	Message      string `json:"message,omitempty"`
+}
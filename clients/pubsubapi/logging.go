@@ -34,3 +34,21 @@ func (c *loggingClient) SubscribeToPubsubTriggers(ctx context.Context, manifestS
 
 	return c.Client.SubscribeToPubsubTriggers(ctx, manifestString)
 }
+
+func (c *loggingClient) GetSubscriptions(ctx context.Context) (subscriptions []*Subscription, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetSubscriptions", err) }()
+
+	return c.Client.GetSubscriptions(ctx)
+}
+
+func (c *loggingClient) DeleteSubscription(ctx context.Context, projectID, subscriptionName string) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "DeleteSubscription", err) }()
+
+	return c.Client.DeleteSubscription(ctx, projectID, subscriptionName)
+}
+
+func (c *loggingClient) CheckHealth(ctx context.Context) (err error) {
+	defer func() { api.HandleLogError(c.prefix, "CheckHealth", err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
@@ -43,3 +43,27 @@ func (c *metricsClient) SubscribeToPubsubTriggers(ctx context.Context, manifestS
 
 	return c.Client.SubscribeToPubsubTriggers(ctx, manifestString)
 }
+
+func (c *metricsClient) GetSubscriptions(ctx context.Context) (subscriptions []*Subscription, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetSubscriptions", begin)
+	}(time.Now())
+
+	return c.Client.GetSubscriptions(ctx)
+}
+
+func (c *metricsClient) DeleteSubscription(ctx context.Context, projectID, subscriptionName string) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "DeleteSubscription", begin)
+	}(time.Now())
+
+	return c.Client.DeleteSubscription(ctx, projectID, subscriptionName)
+}
+
+func (c *metricsClient) CheckHealth(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "CheckHealth", begin)
+	}(time.Now())
+
+	return c.Client.CheckHealth(ctx)
+}
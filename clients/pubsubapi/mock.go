@@ -10,6 +10,9 @@ type MockClient struct {
 	SubscriptionForTopicFunc      func(ctx context.Context, message PubSubPushMessage) (event *manifest.EstafettePubSubEvent, err error)
 	SubscribeToTopicFunc          func(ctx context.Context, projectID, topicID string) (err error)
 	SubscribeToPubsubTriggersFunc func(ctx context.Context, manifestString string) (err error)
+	GetSubscriptionsFunc          func(ctx context.Context) (subscriptions []*Subscription, err error)
+	DeleteSubscriptionFunc        func(ctx context.Context, projectID, subscriptionName string) (err error)
+	CheckHealthFunc               func(ctx context.Context) (err error)
 }
 
 func (c MockClient) SubscriptionForTopic(ctx context.Context, message PubSubPushMessage) (event *manifest.EstafettePubSubEvent, err error) {
@@ -32,3 +35,24 @@ func (c MockClient) SubscribeToPubsubTriggers(ctx context.Context, manifestStrin
 	}
 	return c.SubscribeToPubsubTriggersFunc(ctx, manifestString)
 }
+
+func (c MockClient) GetSubscriptions(ctx context.Context) (subscriptions []*Subscription, err error) {
+	if c.GetSubscriptionsFunc == nil {
+		return
+	}
+	return c.GetSubscriptionsFunc(ctx)
+}
+
+func (c MockClient) DeleteSubscription(ctx context.Context, projectID, subscriptionName string) (err error) {
+	if c.DeleteSubscriptionFunc == nil {
+		return
+	}
+	return c.DeleteSubscriptionFunc(ctx, projectID, subscriptionName)
+}
+
+func (c MockClient) CheckHealth(ctx context.Context) (err error) {
+	if c.CheckHealthFunc == nil {
+		return
+	}
+	return c.CheckHealthFunc(ctx)
+}
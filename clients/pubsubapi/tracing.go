@@ -38,3 +38,24 @@ func (c *tracingClient) SubscribeToPubsubTriggers(ctx context.Context, manifestS
 
 	return c.Client.SubscribeToPubsubTriggers(ctx, manifestString)
 }
+
+func (c *tracingClient) GetSubscriptions(ctx context.Context) (subscriptions []*Subscription, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetSubscriptions"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetSubscriptions(ctx)
+}
+
+func (c *tracingClient) DeleteSubscription(ctx context.Context, projectID, subscriptionName string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "DeleteSubscription"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.DeleteSubscription(ctx, projectID, subscriptionName)
+}
+
+func (c *tracingClient) CheckHealth(ctx context.Context) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "CheckHealth"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.CheckHealth(ctx)
+}
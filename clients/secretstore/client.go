@@ -0,0 +1,203 @@
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	crypt "github.com/estafette/estafette-ci-crypt"
+	"github.com/rs/zerolog/log"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Client resolves secret references in credentials from external secret stores
+type Client interface {
+	GetSecret(ctx context.Context, providerName, path string) (values map[string]string, err error)
+	ResolveCredentials(ctx context.Context, credentials []*contracts.CredentialConfig) (resolvedCredentials []*contracts.CredentialConfig, err error)
+}
+
+// NewClient returns a new secretstore.Client
+func NewClient(config *api.APIConfig, secretHelper crypt.SecretHelper, kubeClientset *kubernetes.Clientset) Client {
+
+	providers := map[string]provider{}
+	ttls := map[string]time.Duration{}
+
+	for _, pc := range config.SecretProviders {
+		var p provider
+		switch {
+		case pc.Type == "vault" && pc.Vault != nil:
+			p = newVaultProvider(*pc.Vault)
+		case pc.Type == "kubernetes" && pc.Kubernetes != nil && kubeClientset != nil:
+			p = newKubernetesProvider(*pc.Kubernetes, kubeClientset)
+		case pc.Type == "file" && pc.File != nil:
+			p = newFileProvider(*pc.File)
+		default:
+			log.Warn().Msgf("Secret provider %v of type %v is not supported or not configured, skipping it", pc.Name, pc.Type)
+			continue
+		}
+
+		providers[pc.Name] = p
+		ttls[pc.Name] = defaultCacheTTL
+		if pc.CacheTTLSeconds > 0 {
+			ttls[pc.Name] = time.Duration(pc.CacheTTLSeconds) * time.Second
+		}
+	}
+
+	return &client{
+		secretHelper: secretHelper,
+		providers:    providers,
+		ttls:         ttls,
+		cache:        map[string]cachedSecret{},
+	}
+}
+
+type client struct {
+	secretHelper crypt.SecretHelper
+	providers    map[string]provider
+	ttls         map[string]time.Duration
+	cache        map[string]cachedSecret
+	cacheMutex   sync.RWMutex
+}
+
+// GetSecret returns the secret from the cache until its ttl expires; if fetching fails the expired values are returned, so an unavailable secret store doesn't fail builds right away
+func (c *client) GetSecret(ctx context.Context, providerName, path string) (values map[string]string, err error) {
+
+	p, ok := c.providers[providerName]
+	if !ok {
+		return nil, fmt.Errorf("Secret provider %v is not configured", providerName)
+	}
+
+	cacheKey := providerName + ":" + path
+
+	c.cacheMutex.RLock()
+	cached, isCached := c.cache[cacheKey]
+	c.cacheMutex.RUnlock()
+
+	if isCached && time.Now().UTC().Before(cached.expiresAt) {
+		return cached.values, nil
+	}
+
+	values, err = p.GetSecret(ctx, path)
+	if err != nil {
+		if isCached {
+			log.Warn().Err(err).Msgf("Fetching secret %v from secret provider %v failed, using expired cached value", path, providerName)
+			return cached.values, nil
+		}
+		return nil, err
+	}
+
+	c.cacheMutex.Lock()
+	c.cache[cacheKey] = cachedSecret{
+		values:    values,
+		expiresAt: time.Now().UTC().Add(c.ttls[providerName]),
+	}
+	c.cacheMutex.Unlock()
+
+	return values, nil
+}
+
+// ResolveCredentials returns copies of the credentials with secret references replaced by the fetched value, encrypted so it's passed to the job like other credential secrets
+func (c *client) ResolveCredentials(ctx context.Context, credentials []*contracts.CredentialConfig) (resolvedCredentials []*contracts.CredentialConfig, err error) {
+
+	resolvedCredentials = make([]*contracts.CredentialConfig, 0, len(credentials))
+
+	for _, cred := range credentials {
+		if cred == nil {
+			continue
+		}
+
+		resolvedCredential := *cred
+		if cred.AdditionalProperties != nil {
+			resolved, err := c.resolveValue(ctx, cred.AdditionalProperties)
+			if err != nil {
+				return nil, fmt.Errorf("Resolving secrets for credential %v failed: %w", cred.Name, err)
+			}
+			resolvedCredential.AdditionalProperties = resolved.(map[string]interface{})
+		}
+
+		resolvedCredentials = append(resolvedCredentials, &resolvedCredential)
+	}
+
+	return
+}
+
+// resolveValue walks nested credential properties and returns a copy with secret references replaced, leaving the original config untouched
+func (c *client) resolveValue(ctx context.Context, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return c.resolveString(ctx, v)
+
+	case map[string]interface{}:
+		resolved := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			r, err := c.resolveValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[k] = r
+		}
+		return resolved, nil
+
+	case map[interface{}]interface{}:
+		resolved := make(map[interface{}]interface{}, len(v))
+		for k, item := range v {
+			r, err := c.resolveValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[k] = r
+		}
+		return resolved, nil
+
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			r, err := c.resolveValue(ctx, item)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	}
+
+	return value, nil
+}
+
+func (c *client) resolveString(ctx context.Context, value string) (string, error) {
+
+	var resolveErr error
+	resolved := secretReferenceRegex.ReplaceAllStringFunc(value, func(reference string) string {
+		if resolveErr != nil {
+			return reference
+		}
+
+		matches := secretReferenceRegex.FindStringSubmatch(reference)
+		providerName, path, key := matches[1], matches[2], matches[3]
+
+		values, err := c.GetSecret(ctx, providerName, path)
+		if err != nil {
+			resolveErr = err
+			return reference
+		}
+
+		secretValue, ok := values[key]
+		if !ok {
+			resolveErr = fmt.Errorf("Secret %v from secret provider %v has no key %v", path, providerName, key)
+			return reference
+		}
+
+		encryptedSecretValue, err := c.secretHelper.EncryptEnvelope(secretValue, crypt.DefaultPipelineWhitelist)
+		if err != nil {
+			resolveErr = err
+			return reference
+		}
+
+		return encryptedSecretValue
+	})
+
+	return resolved, resolveErr
+}
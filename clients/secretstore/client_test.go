@@ -0,0 +1,153 @@
+package secretstore
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/estafette/estafette-ci-api/api"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	crypt "github.com/estafette/estafette-ci-crypt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSecret(t *testing.T) {
+
+	t.Run("ReturnsValuesFromFileProvider", func(t *testing.T) {
+
+		directory, cleanup := createSecretsDirectory(t, "registry.yaml", "username: estafette\npassword: abc\n")
+		defer cleanup()
+		client := NewClient(&api.APIConfig{SecretProviders: []*api.SecretProviderConfig{{Name: "local", Type: "file", File: &api.FileSecretProviderConfig{Directory: directory}}}}, crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false), nil)
+
+		// act
+		values, err := client.GetSecret(context.Background(), "local", "registry.yaml")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", values["password"])
+	})
+
+	t.Run("ReturnsCachedValuesUntilTTLExpires", func(t *testing.T) {
+
+		directory, cleanup := createSecretsDirectory(t, "registry.yaml", "password: abc\n")
+		defer cleanup()
+		client := NewClient(&api.APIConfig{SecretProviders: []*api.SecretProviderConfig{{Name: "local", Type: "file", CacheTTLSeconds: 300, File: &api.FileSecretProviderConfig{Directory: directory}}}}, crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false), nil)
+		_, err := client.GetSecret(context.Background(), "local", "registry.yaml")
+		assert.Nil(t, err)
+		err = ioutil.WriteFile(filepath.Join(directory, "registry.yaml"), []byte("password: def\n"), 0600)
+		assert.Nil(t, err)
+
+		// act
+		values, err := client.GetSecret(context.Background(), "local", "registry.yaml")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "abc", values["password"])
+	})
+
+	t.Run("ReturnsErrorForUnknownProvider", func(t *testing.T) {
+
+		client := NewClient(&api.APIConfig{}, crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false), nil)
+
+		// act
+		_, err := client.GetSecret(context.Background(), "vault", "secret/data/registry")
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForPathOutsideOfFileProviderDirectory", func(t *testing.T) {
+
+		directory, cleanup := createSecretsDirectory(t, "registry.yaml", "password: abc\n")
+		defer cleanup()
+		client := NewClient(&api.APIConfig{SecretProviders: []*api.SecretProviderConfig{{Name: "local", Type: "file", File: &api.FileSecretProviderConfig{Directory: filepath.Join(directory, "sub")}}}}, crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false), nil)
+
+		// act
+		_, err := client.GetSecret(context.Background(), "local", "../registry.yaml")
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsValuesFromVaultKVVersion2", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/secret/data/registry" || r.Header.Get("X-Vault-Token") != "s.token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data":{"data":{"password":"abc"},"metadata":{"version":3}}}`))
+		}))
+		defer server.Close()
+		client := NewClient(&api.APIConfig{SecretProviders: []*api.SecretProviderConfig{{Name: "vault", Type: "vault", Vault: &api.VaultSecretProviderConfig{Address: server.URL, Token: "s.token"}}}}, crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false), nil)
+
+		// act
+		values, err := client.GetSecret(context.Background(), "vault", "secret/data/registry")
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"password": "abc"}, values)
+	})
+}
+
+func TestResolveCredentials(t *testing.T) {
+
+	t.Run("ReplacesSecretReferencesWithEncryptedValuesWithoutChangingConfig", func(t *testing.T) {
+
+		directory, cleanup := createSecretsDirectory(t, "registry.yaml", "password: abc\n")
+		defer cleanup()
+		secretHelper := crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false)
+		client := NewClient(&api.APIConfig{SecretProviders: []*api.SecretProviderConfig{{Name: "local", Type: "file", File: &api.FileSecretProviderConfig{Directory: directory}}}}, secretHelper, nil)
+		credentials := []*contracts.CredentialConfig{
+			{
+				Name: "container-registry-private",
+				Type: "container-registry",
+				AdditionalProperties: map[string]interface{}{
+					"username": "estafette",
+					"password": "estafette.secretref(local:registry.yaml#password)",
+				},
+			},
+		}
+
+		// act
+		resolvedCredentials, err := client.ResolveCredentials(context.Background(), credentials)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "estafette.secretref(local:registry.yaml#password)", credentials[0].AdditionalProperties["password"])
+		if assert.Equal(t, 1, len(resolvedCredentials)) {
+			assert.Equal(t, "estafette", resolvedCredentials[0].AdditionalProperties["username"])
+			decryptedText, _, err := secretHelper.DecryptEnvelope(resolvedCredentials[0].AdditionalProperties["password"].(string), "github.com/estafette/estafette-ci-api")
+			assert.Nil(t, err)
+			assert.Equal(t, "abc", decryptedText)
+		}
+	})
+
+	t.Run("ReturnsErrorForMissingKey", func(t *testing.T) {
+
+		directory, cleanup := createSecretsDirectory(t, "registry.yaml", "password: abc\n")
+		defer cleanup()
+		client := NewClient(&api.APIConfig{SecretProviders: []*api.SecretProviderConfig{{Name: "local", Type: "file", File: &api.FileSecretProviderConfig{Directory: directory}}}}, crypt.NewSecretHelper("SazbwMf3NZxVVbBqQHebPcXCqrVn3DDp", false), nil)
+		credentials := []*contracts.CredentialConfig{
+			{
+				Name: "container-registry-private",
+				Type: "container-registry",
+				AdditionalProperties: map[string]interface{}{
+					"password": "estafette.secretref(local:registry.yaml#token)",
+				},
+			},
+		}
+
+		// act
+		_, err := client.ResolveCredentials(context.Background(), credentials)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func createSecretsDirectory(t *testing.T, fileName, content string) (directory string, cleanup func()) {
+	directory, err := ioutil.TempDir("", "secretstore")
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(directory, fileName), []byte(content), 0600)
+	assert.Nil(t, err)
+
+	return directory, func() { os.RemoveAll(directory) }
+}
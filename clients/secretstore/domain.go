@@ -0,0 +1,22 @@
+package secretstore
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+const defaultCacheTTL = 60 * time.Second
+
+// secretReferenceRegex matches values like estafette.secretref(vault:secret/data/registry#password) in credentials
+var secretReferenceRegex = regexp.MustCompile(`estafette\.secretref\(([a-zA-Z0-9_-]+):([^#()]+)#([^()]+)\)`)
+
+// provider fetches the key/value pairs of a single secret from a secret store
+type provider interface {
+	GetSecret(ctx context.Context, path string) (values map[string]string, err error)
+}
+
+type cachedSecret struct {
+	values    map[string]string
+	expiresAt time.Time
+}
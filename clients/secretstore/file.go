@@ -0,0 +1,40 @@
+package secretstore
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/estafette/estafette-ci-api/api"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func newFileProvider(config api.FileSecretProviderConfig) provider {
+	return &fileProvider{
+		config: config,
+	}
+}
+
+type fileProvider struct {
+	config api.FileSecretProviderConfig
+}
+
+// GetSecret reads the key/value pairs from the yaml file at path within the configured directory; the file is read on every fetch, so changes are picked up once the cache expires
+func (p *fileProvider) GetSecret(ctx context.Context, path string) (values map[string]string, err error) {
+
+	// cleaning the path as absolute path first keeps it from escaping the directory
+	filePath := filepath.Join(p.config.Directory, filepath.Clean("/"+path))
+
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return
+	}
+
+	values = map[string]string{}
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return
+	}
+
+	return
+}
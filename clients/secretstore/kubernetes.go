@@ -0,0 +1,48 @@
+package secretstore
+
+import (
+	"context"
+	"strings"
+
+	"github.com/estafette/estafette-ci-api/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func newKubernetesProvider(config api.KubernetesSecretProviderConfig, kubeClientset *kubernetes.Clientset) provider {
+	return &kubernetesProvider{
+		config:        config,
+		kubeClientset: kubeClientset,
+	}
+}
+
+type kubernetesProvider struct {
+	config        api.KubernetesSecretProviderConfig
+	kubeClientset *kubernetes.Clientset
+}
+
+// GetSecret reads a Kubernetes secret by <name> from the configured namespace or by <namespace>/<name>
+func (p *kubernetesProvider) GetSecret(ctx context.Context, path string) (values map[string]string, err error) {
+
+	namespace := p.config.Namespace
+	name := path
+	if parts := strings.SplitN(path, "/", 2); len(parts) == 2 {
+		namespace = parts[0]
+		name = parts[1]
+	}
+
+	secret, err := p.kubeClientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return
+	}
+
+	values = make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for k, v := range secret.Data {
+		values[k] = string(v)
+	}
+	for k, v := range secret.StringData {
+		values[k] = v
+	}
+
+	return
+}
@@ -0,0 +1,30 @@
+package secretstore
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/api"
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// NewLoggingClient returns a new instance of a logging Client.
+func NewLoggingClient(c Client) Client {
+	return &loggingClient{c, "secretstore"}
+}
+
+type loggingClient struct {
+	Client
+	prefix string
+}
+
+func (c *loggingClient) GetSecret(ctx context.Context, providerName, path string) (values map[string]string, err error) {
+	defer func() { api.HandleLogError(c.prefix, "GetSecret", err) }()
+
+	return c.Client.GetSecret(ctx, providerName, path)
+}
+
+func (c *loggingClient) ResolveCredentials(ctx context.Context, credentials []*contracts.CredentialConfig) (resolvedCredentials []*contracts.CredentialConfig, err error) {
+	defer func() { api.HandleLogError(c.prefix, "ResolveCredentials", err) }()
+
+	return c.Client.ResolveCredentials(ctx, credentials)
+}
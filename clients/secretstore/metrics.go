@@ -0,0 +1,37 @@
+package secretstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/go-kit/kit/metrics"
+)
+
+// NewMetricsClient returns a new instance of a metrics Client.
+func NewMetricsClient(c Client, requestCount metrics.Counter, requestLatency metrics.Histogram) Client {
+	return &metricsClient{c, requestCount, requestLatency}
+}
+
+type metricsClient struct {
+	Client
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+func (c *metricsClient) GetSecret(ctx context.Context, providerName, path string) (values map[string]string, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "GetSecret", begin)
+	}(time.Now())
+
+	return c.Client.GetSecret(ctx, providerName, path)
+}
+
+func (c *metricsClient) ResolveCredentials(ctx context.Context, credentials []*contracts.CredentialConfig) (resolvedCredentials []*contracts.CredentialConfig, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(c.requestCount, c.requestLatency, "ResolveCredentials", begin)
+	}(time.Now())
+
+	return c.Client.ResolveCredentials(ctx, credentials)
+}
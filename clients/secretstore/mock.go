@@ -0,0 +1,26 @@
+package secretstore
+
+import (
+	"context"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+type MockClient struct {
+	GetSecretFunc          func(ctx context.Context, providerName, path string) (values map[string]string, err error)
+	ResolveCredentialsFunc func(ctx context.Context, credentials []*contracts.CredentialConfig) (resolvedCredentials []*contracts.CredentialConfig, err error)
+}
+
+func (c MockClient) GetSecret(ctx context.Context, providerName, path string) (values map[string]string, err error) {
+	if c.GetSecretFunc == nil {
+		return
+	}
+	return c.GetSecretFunc(ctx, providerName, path)
+}
+
+func (c MockClient) ResolveCredentials(ctx context.Context, credentials []*contracts.CredentialConfig) (resolvedCredentials []*contracts.CredentialConfig, err error) {
+	if c.ResolveCredentialsFunc == nil {
+		return
+	}
+	return c.ResolveCredentialsFunc(ctx, credentials)
+}
@@ -0,0 +1,33 @@
+package secretstore
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/api"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/opentracing/opentracing-go"
+)
+
+// NewTracingClient returns a new instance of a tracing Client.
+func NewTracingClient(c Client) Client {
+	return &tracingClient{c, "secretstore"}
+}
+
+type tracingClient struct {
+	Client
+	prefix string
+}
+
+func (c *tracingClient) GetSecret(ctx context.Context, providerName, path string) (values map[string]string, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "GetSecret"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.GetSecret(ctx, providerName, path)
+}
+
+func (c *tracingClient) ResolveCredentials(ctx context.Context, credentials []*contracts.CredentialConfig) (resolvedCredentials []*contracts.CredentialConfig, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(c.prefix, "ResolveCredentials"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return c.Client.ResolveCredentials(ctx, credentials)
+}
@@ -0,0 +1,120 @@
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	"github.com/opentracing/opentracing-go"
+	"github.com/sethgrid/pester"
+)
+
+func newVaultProvider(config api.VaultSecretProviderConfig) provider {
+	return &vaultProvider{
+		config: config,
+	}
+}
+
+type vaultProvider struct {
+	config api.VaultSecretProviderConfig
+}
+
+// GetSecret reads the secret at path from the Vault http api; both kv version 1 and 2 responses are supported
+func (p *vaultProvider) GetSecret(ctx context.Context, path string) (values map[string]string, err error) {
+
+	token, err := p.getToken()
+	if err != nil {
+		return
+	}
+
+	url := fmt.Sprintf("%v/v1/%v", strings.TrimRight(p.config.Address, "/"), strings.TrimLeft(path, "/"))
+
+	// create client, in order to add headers
+	client := pester.NewExtendedClient(&http.Client{Transport: &nethttp.Transport{}})
+	client.MaxRetries = 3
+	client.Backoff = pester.ExponentialJitterBackoff
+	client.KeepLog = true
+	client.Timeout = time.Second * 10
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return
+	}
+
+	span := opentracing.SpanFromContext(ctx)
+	var ht *nethttp.Tracer
+	if span != nil {
+		// add tracing context
+		request = request.WithContext(opentracing.ContextWithSpan(request.Context(), span))
+
+		// collect additional information on setting up connections
+		request, ht = nethttp.TraceRequest(span.Tracer(), request)
+	}
+
+	request.Header.Add("X-Vault-Token", token)
+	if p.config.Namespace != "" {
+		request.Header.Add("X-Vault-Namespace", p.config.Namespace)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return
+	}
+	defer response.Body.Close()
+	if ht != nil {
+		ht.Finish()
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Reading secret %v from vault returned status code %v", path, response.StatusCode)
+	}
+
+	var secretResponse struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	err = json.Unmarshal(body, &secretResponse)
+	if err != nil {
+		return
+	}
+
+	data := secretResponse.Data
+	if innerData, ok := data["data"].(map[string]interface{}); ok {
+		if _, isKVv2 := data["metadata"]; isKVv2 {
+			data = innerData
+		}
+	}
+
+	values = make(map[string]string, len(data))
+	for k, v := range data {
+		if s, ok := v.(string); ok {
+			values[k] = s
+		} else {
+			values[k] = fmt.Sprint(v)
+		}
+	}
+
+	return
+}
+
+func (p *vaultProvider) getToken() (string, error) {
+	if p.config.TokenPath == "" {
+		return p.config.Token, nil
+	}
+
+	tokenBytes, err := ioutil.ReadFile(p.config.TokenPath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(tokenBytes)), nil
+}
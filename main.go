@@ -44,15 +44,20 @@ import (
 	"github.com/estafette/estafette-ci-api/clients/githubapi"
 	"github.com/estafette/estafette-ci-api/clients/prometheus"
 	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	"github.com/estafette/estafette-ci-api/clients/secretstore"
 	"github.com/estafette/estafette-ci-api/clients/slackapi"
 
+	"github.com/estafette/estafette-ci-api/services/audit"
 	"github.com/estafette/estafette-ci-api/services/bitbucket"
 	"github.com/estafette/estafette-ci-api/services/catalog"
 	"github.com/estafette/estafette-ci-api/services/cloudsource"
+	"github.com/estafette/estafette-ci-api/services/docker"
 	"github.com/estafette/estafette-ci-api/services/estafette"
 	"github.com/estafette/estafette-ci-api/services/github"
+	"github.com/estafette/estafette-ci-api/services/health"
 	"github.com/estafette/estafette-ci-api/services/pubsub"
 	"github.com/estafette/estafette-ci-api/services/rbac"
+	"github.com/estafette/estafette-ci-api/services/scim"
 	"github.com/estafette/estafette-ci-api/services/slack"
 )
 
@@ -68,10 +73,11 @@ var (
 
 var (
 	// flags
-	apiAddress                   = kingpin.Flag("api-listen-address", "The address to listen on for api HTTP requests.").Default(":5000").String()
-	configFilePath               = kingpin.Flag("config-file-path", "The path to yaml config file configuring this application.").Default("/configs/config.yaml").String()
-	secretDecryptionKeyPath      = kingpin.Flag("secret-decryption-key-path", "The path to the AES-256 key used to decrypt secrets that have been encrypted with it.").Default("/secrets/secretDecryptionKey").OverrideDefaultFromEnvar("SECRET_DECRYPTION_KEY_PATH").String()
-	gracefulShutdownDelaySeconds = kingpin.Flag("graceful-shutdown-delay-seconds", "The number of seconds to wait with graceful shutdown in order to let endpoints update propagation finish.").Default("15").OverrideDefaultFromEnvar("GRACEFUL_SHUTDOWN_DELAY_SECONDS").Int()
+	apiAddress                      = kingpin.Flag("api-listen-address", "The address to listen on for api HTTP requests.").Default(":5000").String()
+	configFilePath                  = kingpin.Flag("config-file-path", "The path to yaml config file configuring this application.").Default("/configs/config.yaml").String()
+	secretDecryptionKeyPath         = kingpin.Flag("secret-decryption-key-path", "The path to the AES-256 key used to decrypt secrets that have been encrypted with it.").Default("/secrets/secretDecryptionKey").OverrideDefaultFromEnvar("SECRET_DECRYPTION_KEY_PATH").String()
+	previousSecretDecryptionKeyPath = kingpin.Flag("previous-secret-decryption-key-path", "The path to the AES-256 key used before the current one, to keep decrypting secrets encrypted with it while they're re-encrypted after a key rotation.").Default("").OverrideDefaultFromEnvar("PREVIOUS_SECRET_DECRYPTION_KEY_PATH").String()
+	gracefulShutdownDelaySeconds    = kingpin.Flag("graceful-shutdown-delay-seconds", "The number of seconds to wait with graceful shutdown in order to let endpoints update propagation finish.").Default("15").OverrideDefaultFromEnvar("GRACEFUL_SHUTDOWN_DELAY_SECONDS").Int()
 )
 
 func main() {
@@ -120,12 +126,14 @@ func initRequestHandlers(stopChannel <-chan struct{}, waitGroup *sync.WaitGroup)
 	gitEventTopic := getTopics(ctx, stopChannel)
 	bqClient, pubsubClient, gcsClient, sourcerepoTokenSource, sourcerepoService := getGoogleCloudClients(ctx, config)
 	bigqueryClient, bitbucketapiClient, githubapiClient, slackapiClient, pubsubapiClient, cockroachdbClient, dockerhubapiClient, builderapiClient, cloudstorageClient, prometheusClient, cloudsourceClient := getClients(ctx, config, encryptedConfig, secretHelper, bqClient, pubsubClient, gcsClient, sourcerepoTokenSource, sourcerepoService)
-	estafetteService, rbacService, githubService, bitbucketService, cloudsourceService, catalogService := getServices(ctx, config, encryptedConfig, secretHelper, bigqueryClient, bitbucketapiClient, githubapiClient, slackapiClient, pubsubapiClient, cockroachdbClient, dockerhubapiClient, builderapiClient, cloudstorageClient, prometheusClient, cloudsourceClient, gitEventTopic)
-	bitbucketHandler, githubHandler, estafetteHandler, rbacHandler, pubsubHandler, slackHandler, cloudsourceHandler, catalogHandler := getHandlers(ctx, config, encryptedConfig, secretHelper, bigqueryClient, bitbucketapiClient, githubapiClient, slackapiClient, pubsubapiClient, cockroachdbClient, dockerhubapiClient, builderapiClient, cloudstorageClient, prometheusClient, cloudsourceClient, estafetteService, rbacService, githubService, bitbucketService, cloudsourceService, catalogService)
+	estafetteService, rbacService, githubService, bitbucketService, cloudsourceService, catalogService, auditService, pubsubService := getServices(ctx, config, encryptedConfig, secretHelper, bigqueryClient, bitbucketapiClient, githubapiClient, slackapiClient, pubsubapiClient, cockroachdbClient, dockerhubapiClient, builderapiClient, cloudstorageClient, prometheusClient, cloudsourceClient, gitEventTopic)
+	bitbucketHandler, githubHandler, estafetteHandler, rbacHandler, pubsubHandler, slackHandler, cloudsourceHandler, catalogHandler, auditHandler, scimHandler, dockerHandler, healthHandler := getHandlers(ctx, config, encryptedConfig, secretHelper, bigqueryClient, bitbucketapiClient, githubapiClient, slackapiClient, pubsubapiClient, cockroachdbClient, dockerhubapiClient, builderapiClient, cloudstorageClient, prometheusClient, cloudsourceClient, estafetteService, rbacService, githubService, bitbucketService, cloudsourceService, catalogService, auditService, pubsubService)
 
 	subscribeToTopics(ctx, gitEventTopic, estafetteService)
+	reconcilePubsubSubscriptions(ctx, config, pubsubService, auditService, stopChannel)
+	exportDoraMetrics(ctx, config, estafetteService, stopChannel)
 
-	srv := configureGinGonic(config, bitbucketHandler, githubHandler, estafetteHandler, rbacHandler, pubsubHandler, slackHandler, cloudsourceHandler, catalogHandler)
+	srv := configureGinGonic(config, bitbucketHandler, githubHandler, estafetteHandler, rbacHandler, pubsubHandler, slackHandler, cloudsourceHandler, catalogHandler, auditHandler, scimHandler, dockerHandler, healthHandler)
 
 	// watch for configmap changes
 	foundation.WatchForFileChanges(*configFilePath, func(event fsnotify.Event) {
@@ -180,6 +188,42 @@ func subscribeToTopics(ctx context.Context, gitEventTopic *api.GitEventTopic, es
 	go estafetteService.SubscribeToGitEventsTopic(ctx, gitEventTopic)
 }
 
+func reconcilePubsubSubscriptions(ctx context.Context, config *api.APIConfig, pubsubService pubsub.Service, auditService audit.Service, stopChannel <-chan struct{}) {
+	go func(stopChannel <-chan struct{}) {
+		for {
+			select {
+			case <-stopChannel:
+				return
+			case <-time.After(config.Integrations.Pubsub.GetReconcileInterval()):
+				// errors are logged by the logging service
+				statuses, err := pubsubService.ReconcileSubscriptions(ctx)
+				if err == nil {
+					pubsub.RecordReconciledSubscriptions(ctx, auditService, statuses)
+				}
+			}
+		}
+	}(stopChannel)
+}
+
+func exportDoraMetrics(ctx context.Context, config *api.APIConfig, estafetteService estafette.Service, stopChannel <-chan struct{}) {
+	if config.Dora == nil || !config.Dora.ExportMetrics {
+		return
+	}
+
+	go func(stopChannel <-chan struct{}) {
+		for {
+			// errors are logged by the logging service
+			_ = estafetteService.ExportDoraMetrics(ctx)
+
+			select {
+			case <-stopChannel:
+				return
+			case <-time.After(config.Dora.GetExportInterval()):
+			}
+		}
+	}(stopChannel)
+}
+
 func getConfig(ctx context.Context) (*api.APIConfig, *api.APIConfig, crypt.SecretHelper) {
 
 	// read decryption key from secretDecryptionKeyPath
@@ -194,7 +238,17 @@ func getConfig(ctx context.Context) (*api.APIConfig, *api.APIConfig, crypt.Secre
 
 	log.Debug().Msg("Creating helpers...")
 
-	secretHelper := crypt.NewSecretHelper(string(secretDecryptionKeyBytes), false)
+	// during a key rotation secrets encrypted with the previous key can still be decrypted
+	var previousSecretHelper crypt.SecretHelper
+	if *previousSecretDecryptionKeyPath != "" {
+		previousSecretDecryptionKeyBytes, err := ioutil.ReadFile(*previousSecretDecryptionKeyPath)
+		if err != nil {
+			log.Fatal().Err(err).Msgf("Failed reading previous secret decryption key from path %v", *previousSecretDecryptionKeyPath)
+		}
+		previousSecretHelper = crypt.NewSecretHelper(string(previousSecretDecryptionKeyBytes), false)
+	}
+
+	secretHelper := api.NewKeyRotatingSecretHelper(crypt.NewSecretHelper(string(secretDecryptionKeyBytes), false), previousSecretHelper)
 
 	log.Debug().Msg("Creating config reader...")
 
@@ -335,7 +389,17 @@ func getClients(ctx context.Context, config *api.APIConfig, encryptedConfig *api
 	if err != nil {
 		log.Fatal().Err(err).Msg("Creating kubernetes client failed")
 	}
-	builderapiClient = builderapi.NewClient(config, encryptedConfig, secretHelper, kubeClientset, dockerhubapiClient)
+
+	// secretstore client
+	secretstoreClient := secretstore.NewClient(config, secretHelper, kubeClientset)
+	secretstoreClient = secretstore.NewTracingClient(secretstoreClient)
+	secretstoreClient = secretstore.NewLoggingClient(secretstoreClient)
+	secretstoreClient = secretstore.NewMetricsClient(secretstoreClient,
+		api.NewRequestCounter("secretstore_client"),
+		api.NewRequestHistogram("secretstore_client"),
+	)
+
+	builderapiClient = builderapi.NewClient(config, encryptedConfig, secretHelper, kubeClientset, dockerhubapiClient, secretstoreClient)
 	builderapiClient = builderapi.NewTracingClient(builderapiClient)
 	builderapiClient = builderapi.NewLoggingClient(builderapiClient)
 	builderapiClient = builderapi.NewMetricsClient(builderapiClient,
@@ -376,12 +440,21 @@ func getClients(ctx context.Context, config *api.APIConfig, encryptedConfig *api
 	return
 }
 
-func getServices(ctx context.Context, config *api.APIConfig, encryptedConfig *api.APIConfig, secretHelper crypt.SecretHelper, bigqueryClient bigquery.Client, bitbucketapiClient bitbucketapi.Client, githubapiClient githubapi.Client, slackapiClient slackapi.Client, pubsubapiClient pubsubapi.Client, cockroachdbClient cockroachdb.Client, dockerhubapiClient dockerhubapi.Client, builderapiClient builderapi.Client, cloudstorageClient cloudstorage.Client, prometheusClient prometheus.Client, cloudsourceClient cloudsourceapi.Client, gitEventTopic *api.GitEventTopic) (estafetteService estafette.Service, rbacService rbac.Service, githubService github.Service, bitbucketService bitbucket.Service, cloudsourceService cloudsource.Service, catalogService catalog.Service) {
+func getServices(ctx context.Context, config *api.APIConfig, encryptedConfig *api.APIConfig, secretHelper crypt.SecretHelper, bigqueryClient bigquery.Client, bitbucketapiClient bitbucketapi.Client, githubapiClient githubapi.Client, slackapiClient slackapi.Client, pubsubapiClient pubsubapi.Client, cockroachdbClient cockroachdb.Client, dockerhubapiClient dockerhubapi.Client, builderapiClient builderapi.Client, cloudstorageClient cloudstorage.Client, prometheusClient prometheus.Client, cloudsourceClient cloudsourceapi.Client, gitEventTopic *api.GitEventTopic) (estafetteService estafette.Service, rbacService rbac.Service, githubService github.Service, bitbucketService bitbucket.Service, cloudsourceService cloudsource.Service, catalogService catalog.Service, auditService audit.Service, pubsubService pubsub.Service) {
 
 	log.Debug().Msg("Creating services...")
 
+	// audit service
+	auditService = audit.NewService(config, cockroachdbClient, cloudstorageClient)
+	auditService = audit.NewTracingService(auditService)
+	auditService = audit.NewLoggingService(auditService)
+	auditService = audit.NewMetricsService(auditService,
+		api.NewRequestCounter("audit_service"),
+		api.NewRequestHistogram("audit_service"),
+	)
+
 	// estafette service
-	estafetteService = estafette.NewService(config, cockroachdbClient, prometheusClient, cloudstorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceClient.JobVarsFunc(ctx))
+	estafetteService = estafette.NewService(config, cockroachdbClient, prometheusClient, cloudstorageClient, builderapiClient, api.NewWarningHelper(secretHelper, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceClient.JobVarsFunc(ctx))
 	estafetteService = estafette.NewTracingService(estafetteService)
 	estafetteService = estafette.NewLoggingService(estafetteService)
 	estafetteService = estafette.NewMetricsService(estafetteService,
@@ -434,29 +507,69 @@ func getServices(ctx context.Context, config *api.APIConfig, encryptedConfig *ap
 		api.NewRequestHistogram("catalog_service"),
 	)
 
+	// pubsub service
+	pubsubService = pubsub.NewService(config, pubsubapiClient, cockroachdbClient)
+	pubsubService = pubsub.NewTracingService(pubsubService)
+	pubsubService = pubsub.NewLoggingService(pubsubService)
+	pubsubService = pubsub.NewMetricsService(pubsubService,
+		api.NewRequestCounter("pubsub_service"),
+		api.NewRequestHistogram("pubsub_service"),
+	)
+
 	return
 }
 
-func getHandlers(ctx context.Context, config *api.APIConfig, encryptedConfig *api.APIConfig, secretHelper crypt.SecretHelper, bigqueryClient bigquery.Client, bitbucketapiClient bitbucketapi.Client, githubapiClient githubapi.Client, slackapiClient slackapi.Client, pubsubapiClient pubsubapi.Client, cockroachdbClient cockroachdb.Client, dockerhubapiClient dockerhubapi.Client, builderapiClient builderapi.Client, cloudstorageClient cloudstorage.Client, prometheusClient prometheus.Client, cloudsourceClient cloudsourceapi.Client, estafetteService estafette.Service, rbacService rbac.Service, githubService github.Service, bitbucketService bitbucket.Service, cloudsourceService cloudsource.Service, catalogService catalog.Service) (bitbucketHandler bitbucket.Handler, githubHandler github.Handler, estafetteHandler estafette.Handler, rbacHandler rbac.Handler, pubsubHandler pubsub.Handler, slackHandler slack.Handler, cloudsourceHandler cloudsource.Handler, catalogHandler catalog.Handler) {
+func getHandlers(ctx context.Context, config *api.APIConfig, encryptedConfig *api.APIConfig, secretHelper crypt.SecretHelper, bigqueryClient bigquery.Client, bitbucketapiClient bitbucketapi.Client, githubapiClient githubapi.Client, slackapiClient slackapi.Client, pubsubapiClient pubsubapi.Client, cockroachdbClient cockroachdb.Client, dockerhubapiClient dockerhubapi.Client, builderapiClient builderapi.Client, cloudstorageClient cloudstorage.Client, prometheusClient prometheus.Client, cloudsourceClient cloudsourceapi.Client, estafetteService estafette.Service, rbacService rbac.Service, githubService github.Service, bitbucketService bitbucket.Service, cloudsourceService cloudsource.Service, catalogService catalog.Service, auditService audit.Service, pubsubService pubsub.Service) (bitbucketHandler bitbucket.Handler, githubHandler github.Handler, estafetteHandler estafette.Handler, rbacHandler rbac.Handler, pubsubHandler pubsub.Handler, slackHandler slack.Handler, cloudsourceHandler cloudsource.Handler, catalogHandler catalog.Handler, auditHandler audit.Handler, scimHandler scim.Handler, dockerHandler docker.Handler, healthHandler health.Handler) {
 
 	log.Debug().Msg("Creating http handlers...")
 
-	warningHelper := api.NewWarningHelper(secretHelper)
+	warningHelper := api.NewWarningHelper(secretHelper, config)
 
 	// transport
 	bitbucketHandler = bitbucket.NewHandler(bitbucketService)
 	githubHandler = github.NewHandler(githubService)
-	estafetteHandler = estafette.NewHandler(*configFilePath, config, encryptedConfig, cockroachdbClient, cloudstorageClient, builderapiClient, estafetteService, warningHelper, secretHelper, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceClient.JobVarsFunc(ctx))
-	rbacHandler = rbac.NewHandler(config, rbacService, cockroachdbClient)
-	pubsubHandler = pubsub.NewHandler(pubsubapiClient, estafetteService)
+	estafetteHandler = estafette.NewHandler(*configFilePath, config, encryptedConfig, cockroachdbClient, cloudstorageClient, builderapiClient, estafetteService, auditService, warningHelper, secretHelper, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceClient.JobVarsFunc(ctx), githubapiClient.ManifestFunc(ctx), bitbucketapiClient.ManifestFunc(ctx), cloudsourceClient.ManifestFunc(ctx))
+	rbacHandler = rbac.NewHandler(config, rbacService, cockroachdbClient, auditService)
+	pubsubHandler = pubsub.NewHandler(pubsubapiClient, estafetteService, pubsubService, cockroachdbClient, auditService)
 	slackHandler = slack.NewHandler(secretHelper, config, slackapiClient, cockroachdbClient, estafetteService, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx))
 	cloudsourceHandler = cloudsource.NewHandler(pubsubapiClient, cloudsourceService)
-	catalogHandler = catalog.NewHandler(config, catalogService, cockroachdbClient)
+	catalogHandler = catalog.NewHandler(config, catalogService, cockroachdbClient, auditService)
+	auditHandler = audit.NewHandler(config, auditService, cockroachdbClient)
+	scimHandler = scim.NewHandler(config, rbacService, cockroachdbClient, auditService)
+	dockerHandler = docker.NewHandler(config, dockerhubapiClient, estafetteService)
+	healthHandler = health.NewHandler(getHealthRegistry(config, cockroachdbClient, builderapiClient, cloudstorageClient, pubsubapiClient, githubapiClient, bitbucketapiClient, prometheusClient))
 
 	return
 }
 
-func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler, githubHandler github.Handler, estafetteHandler estafette.Handler, rbacHandler rbac.Handler, pubsubHandler pubsub.Handler, slackHandler slack.Handler, cloudsourceHandler cloudsource.Handler, catalogHandler catalog.Handler) *http.Server {
+func getHealthRegistry(config *api.APIConfig, cockroachdbClient cockroachdb.Client, builderapiClient builderapi.Client, cloudstorageClient cloudstorage.Client, pubsubapiClient pubsubapi.Client, githubapiClient githubapi.Client, bitbucketapiClient bitbucketapi.Client, prometheusClient prometheus.Client) health.Registry {
+
+	log.Debug().Msg("Registering health checks...")
+
+	// without the database and kubernetes api no request can be handled, so only those take the api out of rotation by default
+	healthRegistry := health.NewRegistry(config)
+	healthRegistry.Register("cockroachdb", 5*time.Second, true, cockroachdbClient.CheckHealth)
+	healthRegistry.Register("builderapi", 5*time.Second, true, builderapiClient.CheckHealth)
+	if config.Integrations.CloudStorage != nil {
+		healthRegistry.Register("cloudstorage", 5*time.Second, false, cloudstorageClient.CheckHealth)
+	}
+	if config.Integrations.Pubsub != nil {
+		healthRegistry.Register("pubsubapi", 5*time.Second, false, pubsubapiClient.CheckHealth)
+	}
+	if config.Integrations.Github != nil {
+		healthRegistry.Register("githubapi", 10*time.Second, false, githubapiClient.CheckHealth)
+	}
+	if config.Integrations.Bitbucket != nil {
+		healthRegistry.Register("bitbucketapi", 10*time.Second, false, bitbucketapiClient.CheckHealth)
+	}
+	if config.Integrations.Prometheus != nil {
+		healthRegistry.Register("prometheus", 5*time.Second, false, prometheusClient.CheckHealth)
+	}
+
+	return healthRegistry
+}
+
+func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler, githubHandler github.Handler, estafetteHandler estafette.Handler, rbacHandler rbac.Handler, pubsubHandler pubsub.Handler, slackHandler slack.Handler, cloudsourceHandler cloudsource.Handler, catalogHandler catalog.Handler, auditHandler audit.Handler, scimHandler scim.Handler, dockerHandler docker.Handler, healthHandler health.Handler) *http.Server {
 
 	// run gin in release mode and other defaults
 	gin.SetMode(gin.ReleaseMode)
@@ -475,9 +588,13 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 	log.Debug().Msg("Adding opentracing middleware...")
 	router.Use(api.OpenTracingMiddleware())
 
+	// validate filter[since], filter[until] and filter[labels] for all endpoints
+	log.Debug().Msg("Adding filter validation middleware...")
+	router.Use(api.FilterValidationMiddleware())
+
 	// middleware to handle auth for different endpoints
 	log.Debug().Msg("Adding auth middleware...")
-	authMiddleware := api.NewAuthMiddleware(config)
+	authMiddleware := api.NewAuthMiddleware(config, rbacHandler.HandlePersonalAccessTokenAuthorizator(), rbacHandler.HandleCustomRolesResolver())
 	jwtMiddleware, err := authMiddleware.GinJWTMiddleware(rbacHandler.HandleOAuthLoginProviderAuthenticator())
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed creating JWT middleware")
@@ -507,6 +624,14 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 	routes.POST("/api/integrations/slack/slash", slackHandler.Handle)
 	routes.GET("/api/integrations/slack/status", func(c *gin.Context) { c.String(200, "Slack, I'm cool!") })
 
+	// docker hub and registry push events, authenticated with the configured webhook token
+	routes.POST("/api/integrations/docker/hub/events", dockerHandler.PostDockerHubEvent)
+	routes.POST("/api/integrations/docker/registry/events", dockerHandler.PostRegistryEvents)
+	routes.GET("/api/integrations/docker/status", func(c *gin.Context) { c.String(200, "Docker, I'm cool!") })
+
+	// inbound pipeline webhooks, authenticated with the webhook's secret token
+	routes.POST("/api/integrations/webhooks/:id", estafetteHandler.PostWebhookDelivery)
+
 	// google jwt auth protected endpoints
 	googleAuthorizedRoutes := routes.Group("/", authMiddleware.GoogleJWTMiddlewareFunc())
 	{
@@ -524,17 +649,45 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 	routes.POST("/api/auth/client/login", clientLoginJWTMiddleware.LoginHandler)
 	routes.POST("/api/auth/client/logout", clientLoginJWTMiddleware.LogoutHandler)
 
+	// scim provisioning routes for identity providers, authenticated with client credentials
+	scimRoutes := routes.Group("/scim/v2", scimHandler.Middleware())
+	{
+		scimRoutes.GET("/ServiceProviderConfig", scimHandler.GetServiceProviderConfig)
+
+		scimRoutes.GET("/Users", scimHandler.GetUsers)
+		scimRoutes.GET("/Users/:id", scimHandler.GetUser)
+		scimRoutes.POST("/Users", scimHandler.CreateUser)
+		scimRoutes.PUT("/Users/:id", scimHandler.UpdateUser)
+		scimRoutes.PATCH("/Users/:id", scimHandler.PatchUser)
+		scimRoutes.DELETE("/Users/:id", scimHandler.DeleteUser)
+
+		scimRoutes.GET("/Groups", scimHandler.GetGroups)
+		scimRoutes.GET("/Groups/:id", scimHandler.GetGroup)
+		scimRoutes.POST("/Groups", scimHandler.CreateGroup)
+		scimRoutes.PUT("/Groups/:id", scimHandler.UpdateGroup)
+		scimRoutes.PATCH("/Groups/:id", scimHandler.PatchGroup)
+		scimRoutes.DELETE("/Groups/:id", scimHandler.DeleteGroup)
+	}
+
 	// routes that require to be logged in and have a valid jwt
 	jwtMiddlewareRoutes := routes.Group("/", jwtMiddleware.MiddlewareFunc())
 	{
 		// logged in user endpoints
 		jwtMiddlewareRoutes.GET("/api/me", rbacHandler.GetLoggedInUser)
+		jwtMiddlewareRoutes.GET("/api/me/tokens", rbacHandler.GetPersonalAccessTokens)
+		jwtMiddlewareRoutes.POST("/api/me/tokens", rbacHandler.CreatePersonalAccessToken)
+		jwtMiddlewareRoutes.DELETE("/api/me/tokens/:id", rbacHandler.RevokePersonalAccessToken)
 
 		// actions
 		jwtMiddlewareRoutes.POST("/api/pipelines/:source/:owner/:repo/builds", estafetteHandler.CreatePipelineBuild)
+		jwtMiddlewareRoutes.POST("/api/pipelines/:source/:owner/:repo/dispatch", estafetteHandler.DispatchPipelineBuild)
 		jwtMiddlewareRoutes.POST("/api/pipelines/:source/:owner/:repo/releases", estafetteHandler.CreatePipelineRelease)
 		jwtMiddlewareRoutes.DELETE("/api/pipelines/:source/:owner/:repo/builds/:revisionOrId", estafetteHandler.CancelPipelineBuild)
 		jwtMiddlewareRoutes.DELETE("/api/pipelines/:source/:owner/:repo/releases/:id", estafetteHandler.CancelPipelineRelease)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/webhooks", estafetteHandler.GetPipelineWebhooks)
+		jwtMiddlewareRoutes.POST("/api/pipelines/:source/:owner/:repo/webhooks", estafetteHandler.CreatePipelineWebhook)
+		jwtMiddlewareRoutes.DELETE("/api/pipelines/:source/:owner/:repo/webhooks/:id", estafetteHandler.DeletePipelineWebhook)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/webhookdeliveries", estafetteHandler.GetPipelineWebhookDeliveries)
 
 		// to be removed after changing web frontend to use the /api/admin routes
 		jwtMiddlewareRoutes.GET("/api/roles", rbacHandler.GetRoles)
@@ -566,12 +719,23 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 		// admin section
 		jwtMiddlewareRoutes.GET("/api/auth/impersonate/:id", impersonateJWTMiddleware.LoginHandler)
 		jwtMiddlewareRoutes.GET("/api/admin/roles", rbacHandler.GetRoles)
+		jwtMiddlewareRoutes.GET("/api/admin/audit", auditHandler.GetAuditEvents)
+		jwtMiddlewareRoutes.GET("/health", healthHandler.GetHealth)
+		jwtMiddlewareRoutes.POST("/api/admin/pubsub/reconcile", pubsubHandler.ReconcileSubscriptions)
+
+		jwtMiddlewareRoutes.GET("/api/admin/customroles", rbacHandler.GetCustomRoles)
+		jwtMiddlewareRoutes.GET("/api/admin/customroles/:id", rbacHandler.GetCustomRole)
+		jwtMiddlewareRoutes.POST("/api/admin/customroles", rbacHandler.CreateCustomRole)
+		jwtMiddlewareRoutes.PUT("/api/admin/customroles/:id", rbacHandler.UpdateCustomRole)
+		jwtMiddlewareRoutes.DELETE("/api/admin/customroles/:id", rbacHandler.DeleteCustomRole)
 
 		jwtMiddlewareRoutes.GET("/api/admin/users", rbacHandler.GetUsers)
 		jwtMiddlewareRoutes.GET("/api/admin/users/:id", rbacHandler.GetUser)
 		jwtMiddlewareRoutes.POST("/api/admin/users", rbacHandler.CreateUser)
 		jwtMiddlewareRoutes.PUT("/api/admin/users/:id", rbacHandler.UpdateUser)
 		jwtMiddlewareRoutes.DELETE("/api/admin/users/:id", rbacHandler.DeleteUser)
+		jwtMiddlewareRoutes.GET("/api/admin/users/:id/tokens", rbacHandler.GetUserPersonalAccessTokens)
+		jwtMiddlewareRoutes.DELETE("/api/admin/users/:id/tokens/:tokenId", rbacHandler.RevokeUserPersonalAccessToken)
 
 		jwtMiddlewareRoutes.GET("/api/admin/pipelines", rbacHandler.GetPipelines)
 		jwtMiddlewareRoutes.GET("/api/admin/pipelines/:source/:owner/:repo", rbacHandler.GetPipeline)
@@ -607,8 +771,10 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 		jwtMiddlewareRoutes.GET("/api/catalog/entity-parent-values", catalogHandler.GetCatalogEntityParentValues)
 		jwtMiddlewareRoutes.GET("/api/catalog/entity-keys", catalogHandler.GetCatalogEntityKeys)
 		jwtMiddlewareRoutes.GET("/api/catalog/entity-values", catalogHandler.GetCatalogEntityValues)
+		jwtMiddlewareRoutes.GET("/api/catalog/entity-violations", catalogHandler.GetCatalogEntityViolations)
 		jwtMiddlewareRoutes.GET("/api/catalog/entities", catalogHandler.GetCatalogEntities)
 		jwtMiddlewareRoutes.GET("/api/catalog/entities/:id", catalogHandler.GetCatalogEntity)
+		jwtMiddlewareRoutes.GET("/api/catalog/entities/:id/pipelines", catalogHandler.GetCatalogEntityPipelines)
 		jwtMiddlewareRoutes.POST("/api/catalog/entities", catalogHandler.CreateCatalogEntity)
 		jwtMiddlewareRoutes.PUT("/api/catalog/entities/:id", catalogHandler.UpdateCatalogEntity)
 		jwtMiddlewareRoutes.DELETE("/api/catalog/entities/:id", catalogHandler.DeleteCatalogEntity)
@@ -621,12 +787,15 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 		jwtMiddlewareRoutes.GET("/api/config", estafetteHandler.GetConfig)
 		jwtMiddlewareRoutes.GET("/api/config/credentials", estafetteHandler.GetConfigCredentials)
 		jwtMiddlewareRoutes.GET("/api/config/trustedimages", estafetteHandler.GetConfigTrustedImages)
+		jwtMiddlewareRoutes.GET("/api/config/secrets/rotation", estafetteHandler.GetSecretKeyRotationReport)
+		jwtMiddlewareRoutes.POST("/api/config/secrets/rotation", estafetteHandler.RotateConfigSecrets)
 		jwtMiddlewareRoutes.GET("/api/pipelines", estafetteHandler.GetPipelines)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo", estafetteHandler.GetPipeline)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/recentbuilds", estafetteHandler.GetPipelineRecentBuilds)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/builds", estafetteHandler.GetPipelineBuilds)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/builds/:revisionOrId", estafetteHandler.GetPipelineBuild)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/builds/:revisionOrId/warnings", estafetteHandler.GetPipelineBuildWarnings)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/builds/:revisionOrId/parameters", estafetteHandler.GetPipelineBuildParameters)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/releases", estafetteHandler.GetPipelineReleases)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/releases/:id", estafetteHandler.GetPipelineRelease)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/stats/buildsdurations", estafetteHandler.GetPipelineStatsBuildsDurations)
@@ -635,7 +804,12 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/stats/releasescpu", estafetteHandler.GetPipelineStatsReleasesCPUUsageMeasurements)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/stats/buildsmemory", estafetteHandler.GetPipelineStatsBuildsMemoryUsageMeasurements)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/stats/releasesmemory", estafetteHandler.GetPipelineStatsReleasesMemoryUsageMeasurements)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/stats/flakiness", estafetteHandler.GetPipelineStatsFlakiness)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/stats/stages", estafetteHandler.GetPipelineStatsStages)
 		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/warnings", estafetteHandler.GetPipelineWarnings)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/dependencies", estafetteHandler.GetPipelineDependencies)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/policy", estafetteHandler.GetPipelinePolicy)
+		jwtMiddlewareRoutes.GET("/api/pipelines/:source/:owner/:repo/subscriptions", pubsubHandler.GetPipelineSubscriptions)
 		jwtMiddlewareRoutes.GET("/api/catalog/filters", estafetteHandler.GetCatalogFilters)
 		jwtMiddlewareRoutes.GET("/api/catalog/filtervalues", estafetteHandler.GetCatalogFilterValues)
 		jwtMiddlewareRoutes.GET("/api/stats/pipelinescount", estafetteHandler.GetStatsPipelinesCount)
@@ -646,9 +820,16 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 		jwtMiddlewareRoutes.GET("/api/stats/releasesadoption", estafetteHandler.GetStatsReleasesAdoption)
 		jwtMiddlewareRoutes.GET("/api/stats/mostbuilds", estafetteHandler.GetStatsMostBuilds)
 		jwtMiddlewareRoutes.GET("/api/stats/mostreleases", estafetteHandler.GetStatsMostReleases)
+		jwtMiddlewareRoutes.GET("/api/stats/dora", estafetteHandler.GetStatsDora)
+		jwtMiddlewareRoutes.GET("/api/stats/cost", estafetteHandler.GetStatsCost)
+		jwtMiddlewareRoutes.GET("/api/stats/cost/savings", estafetteHandler.GetStatsCostSavings)
+		jwtMiddlewareRoutes.GET("/api/stats/flakybuilds", estafetteHandler.GetStatsFlakyBuilds)
+		jwtMiddlewareRoutes.GET("/api/stats/stageimages", estafetteHandler.GetStatsStageImages)
 		jwtMiddlewareRoutes.GET("/api/manifest/templates", estafetteHandler.GetManifestTemplates)
 		jwtMiddlewareRoutes.POST("/api/manifest/generate", estafetteHandler.GenerateManifest)
 		jwtMiddlewareRoutes.POST("/api/manifest/validate", estafetteHandler.ValidateManifest)
+		jwtMiddlewareRoutes.GET("/api/manifest/rules", estafetteHandler.GetManifestLintRules)
+		jwtMiddlewareRoutes.POST("/api/triggers/simulate", estafetteHandler.SimulateTriggers)
 		jwtMiddlewareRoutes.POST("/api/manifest/encrypt", estafetteHandler.EncryptSecret)
 		jwtMiddlewareRoutes.GET("/api/labels/frequent", estafetteHandler.GetFrequentLabels)
 
@@ -670,12 +851,8 @@ func configureGinGonic(config *api.APIConfig, bitbucketHandler bitbucket.Handler
 	}
 
 	// default routes
-	routes.GET("/liveness", func(c *gin.Context) {
-		c.String(200, "I'm alive!")
-	})
-	routes.GET("/readiness", func(c *gin.Context) {
-		c.String(200, "I'm ready!")
-	})
+	routes.GET("/liveness", healthHandler.GetLiveness)
+	routes.GET("/readiness", healthHandler.GetReadiness)
 	router.NoRoute(func(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Page not found"})
 	})
@@ -11,17 +11,22 @@ import (
 	"github.com/estafette/estafette-ci-api/clients/cloudsourceapi"
 	"github.com/estafette/estafette-ci-api/clients/cloudstorage"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/clients/dockerhubapi"
 	"github.com/estafette/estafette-ci-api/clients/githubapi"
 	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
 	"github.com/estafette/estafette-ci-api/clients/slackapi"
 
+	"github.com/estafette/estafette-ci-api/services/audit"
 	"github.com/estafette/estafette-ci-api/services/bitbucket"
 	"github.com/estafette/estafette-ci-api/services/catalog"
 	"github.com/estafette/estafette-ci-api/services/cloudsource"
+	"github.com/estafette/estafette-ci-api/services/docker"
 	"github.com/estafette/estafette-ci-api/services/estafette"
 	"github.com/estafette/estafette-ci-api/services/github"
+	"github.com/estafette/estafette-ci-api/services/health"
 	"github.com/estafette/estafette-ci-api/services/pubsub"
 	"github.com/estafette/estafette-ci-api/services/rbac"
+	"github.com/estafette/estafette-ci-api/services/scim"
 	"github.com/estafette/estafette-ci-api/services/slack"
 
 	crypt "github.com/estafette/estafette-ci-crypt"
@@ -46,7 +51,7 @@ func TestConfigureGinGonic(t *testing.T) {
 		builderapiClient := builderapi.MockClient{}
 		estafetteService := estafette.MockService{}
 		secretHelper := crypt.NewSecretHelper("abc", false)
-		warningHelper := api.NewWarningHelper(secretHelper)
+		warningHelper := api.NewWarningHelper(secretHelper, config)
 		githubapiClient := githubapi.MockClient{}
 		bitbucketapiClient := bitbucketapi.MockClient{}
 		cloudsourceapiClient := cloudsourceapi.MockClient{}
@@ -55,15 +60,19 @@ func TestConfigureGinGonic(t *testing.T) {
 
 		bitbucketHandler := bitbucket.NewHandler(bitbucket.MockService{})
 		githubHandler := github.NewHandler(github.MockService{})
-		estafetteHandler := estafette.NewHandler("", config, config, cockroachdbClient, cloudstorageClient, builderapiClient, estafetteService, warningHelper, secretHelper, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		estafetteHandler := estafette.NewHandler("", config, config, cockroachdbClient, cloudstorageClient, builderapiClient, estafetteService, audit.MockService{}, warningHelper, secretHelper, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx), githubapiClient.ManifestFunc(ctx), bitbucketapiClient.ManifestFunc(ctx), cloudsourceapiClient.ManifestFunc(ctx))
 
-		rbacHandler := rbac.NewHandler(config, rbac.MockService{}, cockroachdbClient)
-		pubsubHandler := pubsub.NewHandler(pubsubapiclient, estafetteService)
+		rbacHandler := rbac.NewHandler(config, rbac.MockService{}, cockroachdbClient, audit.MockService{})
+		pubsubHandler := pubsub.NewHandler(pubsubapiclient, estafetteService, pubsub.MockService{}, cockroachdbClient, audit.MockService{})
 		slackHandler := slack.NewHandler(secretHelper, config, slackapiClient, cockroachdbClient, estafetteService, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx))
 		cloudsourceHandler := cloudsource.NewHandler(pubsubapiclient, cloudsource.MockService{})
-		catalogHandler := catalog.NewHandler(config, catalog.MockService{}, cockroachdbClient)
+		catalogHandler := catalog.NewHandler(config, catalog.MockService{}, cockroachdbClient, audit.MockService{})
+		auditHandler := audit.NewHandler(config, audit.MockService{}, cockroachdbClient)
+		scimHandler := scim.NewHandler(config, rbac.MockService{}, cockroachdbClient, audit.MockService{})
+		dockerHandler := docker.NewHandler(config, dockerhubapi.MockClient{}, estafetteService)
+		healthHandler := health.NewHandler(health.NewRegistry(config))
 
 		// act
-		_ = configureGinGonic(config, bitbucketHandler, githubHandler, estafetteHandler, rbacHandler, pubsubHandler, slackHandler, cloudsourceHandler, catalogHandler)
+		_ = configureGinGonic(config, bitbucketHandler, githubHandler, estafetteHandler, rbacHandler, pubsubHandler, slackHandler, cloudsourceHandler, catalogHandler, auditHandler, scimHandler, dockerHandler, healthHandler)
 	})
 }
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/api"
+)
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(s Service) Service {
+	return &loggingService{s, "audit"}
+}
+
+type loggingService struct {
+	Service
+	prefix string
+}
+
+func (s *loggingService) CreateAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	defer func() { api.HandleLogError(s.prefix, "CreateAuditEvent", err) }()
+
+	return s.Service.CreateAuditEvent(ctx, auditEvent)
+}
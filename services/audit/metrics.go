@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/go-kit/kit/metrics"
+)
+
+// NewMetricsService returns a new instance of a metrics Service.
+func NewMetricsService(s Service, requestCount metrics.Counter, requestLatency metrics.Histogram) Service {
+	return &metricsService{s, requestCount, requestLatency}
+}
+
+type metricsService struct {
+	Service
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+func (s *metricsService) CreateAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "CreateAuditEvent", begin)
+	}(time.Now())
+
+	return s.Service.CreateAuditEvent(ctx, auditEvent)
+}
@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/api"
+)
+
+type MockService struct {
+	CreateAuditEventFunc func(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error)
+}
+
+func (s MockService) CreateAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	if s.CreateAuditEventFunc == nil {
+		return
+	}
+	return s.CreateAuditEventFunc(ctx, auditEvent)
+}
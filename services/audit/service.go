@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cloudstorage"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	redactedValue = "***"
+)
+
+// redactedKeys are never stored in the before and after state of an audit event
+var redactedKeys = []string{
+	"clientSecret",
+	"password",
+	"secret",
+}
+
+// Service records changes made through the api in an append-only audit log
+type Service interface {
+	CreateAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error)
+}
+
+// NewService returns a new audit.Service
+func NewService(config *api.APIConfig, cockroachdbClient cockroachdb.Client, cloudstorageClient cloudstorage.Client) Service {
+	return &service{
+		config:             config,
+		cockroachdbClient:  cockroachdbClient,
+		cloudstorageClient: cloudstorageClient,
+	}
+}
+
+type service struct {
+	config             *api.APIConfig
+	cockroachdbClient  cockroachdb.Client
+	cloudstorageClient cloudstorage.Client
+}
+
+func (s *service) CreateAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+
+	// convert before and after state to generic json, so they can be redacted and compared
+	auditEvent.Before, err = s.toRedactedJSON(auditEvent.Before)
+	if err != nil {
+		return
+	}
+	auditEvent.After, err = s.toRedactedJSON(auditEvent.After)
+	if err != nil {
+		return
+	}
+	auditEvent.Changes = s.getChanges("", auditEvent.Before, auditEvent.After)
+
+	insertedAuditEvent, err = s.cockroachdbClient.InsertAuditEvent(ctx, auditEvent)
+	if err != nil {
+		return
+	}
+
+	if s.config != nil && s.config.Audit != nil && s.config.Audit.StreamToCloudStorage && s.config.Integrations != nil && s.config.Integrations.CloudStorage != nil {
+		go func(auditEvent api.AuditEvent) {
+			err := s.cloudstorageClient.InsertAuditEvent(context.Background(), auditEvent)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed streaming audit event %v to cloud storage", auditEvent.ID)
+			}
+		}(*insertedAuditEvent)
+	}
+
+	return
+}
+
+func (s *service) toRedactedJSON(state interface{}) (redactedState interface{}, err error) {
+
+	if state == nil {
+		return nil, nil
+	}
+
+	stateBytes, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(stateBytes, &redactedState)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.redact(redactedState), nil
+}
+
+func (s *service) redact(state interface{}) interface{} {
+	switch v := state.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if api.StringArrayContains(redactedKeys, key) {
+				if value != nil && value != "" {
+					v[key] = redactedValue
+				}
+				continue
+			}
+			v[key] = s.redact(value)
+		}
+		return v
+
+	case []interface{}:
+		for i := range v {
+			v[i] = s.redact(v[i])
+		}
+		return v
+	}
+
+	return state
+}
+
+// getChanges returns the differences between two json states; objects are compared field by field, arrays as a whole
+func (s *service) getChanges(path string, before, after interface{}) (changes []api.AuditChange) {
+
+	beforeMap, beforeIsMap := before.(map[string]interface{})
+	afterMap, afterIsMap := after.(map[string]interface{})
+
+	if beforeIsMap && afterIsMap {
+		keys := []string{}
+		for key := range beforeMap {
+			keys = append(keys, key)
+		}
+		for key := range afterMap {
+			if _, ok := beforeMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			changes = append(changes, s.getChanges(strings.TrimPrefix(path+"."+key, "."), beforeMap[key], afterMap[key])...)
+		}
+
+		return changes
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+
+	return []api.AuditChange{
+		{
+			Path:   path,
+			Before: before,
+			After:  after,
+		},
+	}
+}
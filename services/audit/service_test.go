@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cloudstorage"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAuditEvent(t *testing.T) {
+
+	t.Run("RedactsSecretsAndStoresChanges", func(t *testing.T) {
+
+		var storedAuditEvent api.AuditEvent
+		cockroachdbClient := cockroachdb.MockClient{
+			InsertAuditEventFunc: func(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+				storedAuditEvent = auditEvent
+				return &auditEvent, nil
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient, cloudstorage.MockClient{})
+
+		before := contracts.Client{
+			ID:           "15",
+			Name:         "my-client",
+			ClientSecret: "old-secret",
+			Active:       true,
+		}
+		after := before
+		after.ClientSecret = "new-secret"
+		after.Name = "my-renamed-client"
+
+		// act
+		_, err := service.CreateAuditEvent(context.Background(), api.AuditEvent{Action: "rbac.clients.update", TargetType: "client", TargetID: "15", Before: before, After: after})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "***", storedAuditEvent.Before.(map[string]interface{})["clientSecret"])
+		assert.Equal(t, "***", storedAuditEvent.After.(map[string]interface{})["clientSecret"])
+		assert.Equal(t, 1, len(storedAuditEvent.Changes))
+		assert.Equal(t, "name", storedAuditEvent.Changes[0].Path)
+		assert.Equal(t, "my-client", storedAuditEvent.Changes[0].Before)
+		assert.Equal(t, "my-renamed-client", storedAuditEvent.Changes[0].After)
+	})
+}
+
+func TestGetChanges(t *testing.T) {
+
+	t.Run("ReturnsNoChangesForEqualStates", func(t *testing.T) {
+
+		service := &service{}
+		state := map[string]interface{}{"name": "a", "labels": []interface{}{"x"}}
+
+		// act
+		changes := service.getChanges("", state, state)
+
+		assert.Equal(t, 0, len(changes))
+	})
+
+	t.Run("ReturnsNestedChangesSortedByPath", func(t *testing.T) {
+
+		service := &service{}
+		before := map[string]interface{}{
+			"name":    "a",
+			"details": map[string]interface{}{"team": "x", "removed": "y"},
+		}
+		after := map[string]interface{}{
+			"name":    "b",
+			"details": map[string]interface{}{"team": "z"},
+		}
+
+		// act
+		changes := service.getChanges("", before, after)
+
+		if assert.Equal(t, 3, len(changes)) {
+			assert.Equal(t, "details.removed", changes[0].Path)
+			assert.Equal(t, "y", changes[0].Before)
+			assert.Nil(t, changes[0].After)
+			assert.Equal(t, "details.team", changes[1].Path)
+			assert.Equal(t, "name", changes[2].Path)
+		}
+	})
+
+	t.Run("ReturnsSingleChangeForCreatedTarget", func(t *testing.T) {
+
+		service := &service{}
+		after := map[string]interface{}{"name": "a"}
+
+		// act
+		changes := service.getChanges("", nil, after)
+
+		if assert.Equal(t, 1, len(changes)) {
+			assert.Equal(t, "", changes[0].Path)
+			assert.Nil(t, changes[0].Before)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/opentracing/opentracing-go"
+)
+
+// NewTracingService returns a new instance of a tracing Service.
+func NewTracingService(s Service) Service {
+	return &tracingService{s, "audit"}
+}
+
+type tracingService struct {
+	Service
+	prefix string
+}
+
+func (s *tracingService) CreateAuditEvent(ctx context.Context, auditEvent api.AuditEvent) (insertedAuditEvent *api.AuditEvent, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "CreateAuditEvent"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.CreateAuditEvent(ctx, auditEvent)
+}
@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// NewHandler returns a new audit.Handler
+func NewHandler(config *api.APIConfig, service Service, cockroachdbClient cockroachdb.Client) Handler {
+	return Handler{
+		config:            config,
+		service:           service,
+		cockroachdbClient: cockroachdbClient,
+	}
+}
+
+type Handler struct {
+	config            *api.APIConfig
+	service           Service
+	cockroachdbClient cockroachdb.Client
+}
+
+func (h *Handler) GetAuditEvents(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionAuditEventsList) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	pageNumber, pageSize, filters, sortings := api.GetQueryParameters(c)
+
+	ctx := c.Request.Context()
+
+	response, err := api.GetListResponse(c, pageNumber, pageSize, filters,
+		func(pageSize int) ([]interface{}, error) {
+			auditEvents, err := h.cockroachdbClient.GetAuditEvents(ctx, pageNumber, pageSize, filters, sortings)
+			if err != nil {
+				return nil, err
+			}
+
+			// convert typed array to interface array O(n)
+			items := make([]interface{}, len(auditEvents))
+			for i := range auditEvents {
+				items[i] = auditEvents[i]
+			}
+
+			return items, nil
+		},
+		func() (int, error) {
+			return h.cockroachdbClient.GetAuditEventsCount(ctx, filters)
+		},
+		func(item interface{}) string {
+			auditEvent := item.(*api.AuditEvent)
+			return api.EncodePageCursor(auditEvent.InsertedAt.Format(time.RFC3339Nano), auditEvent.ID)
+		})
+
+	if errors.Is(err, api.ErrInvalidPageCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": "Invalid page[after] cursor"})
+		return
+	}
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed retrieving audit events from db")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RecordRequestEvent stores the change made by the request in the audit log; failing to do so doesn't fail the request
+func RecordRequestEvent(c *gin.Context, service Service, action api.Permission, targetType, targetID string, before, after interface{}) {
+	RecordEvent(c.Request.Context(), service, api.NewAuditEventFromRequest(c, action.String(), targetType, targetID, before, after))
+}
+
+// RecordEvent stores the event in the audit log, for changes that aren't made for a permission or by a request, like the ones of background jobs; failing to do so doesn't fail the caller
+func RecordEvent(ctx context.Context, service Service, auditEvent api.AuditEvent) {
+	_, err := service.CreateAuditEvent(ctx, auditEvent)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed recording audit event %v for %v %v", auditEvent.Action, auditEvent.TargetType, auditEvent.TargetID)
+	}
+}
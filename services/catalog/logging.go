@@ -34,3 +34,21 @@ func (s *loggingService) DeleteCatalogEntity(ctx context.Context, id string) (er
 
 	return s.Service.DeleteCatalogEntity(ctx, id)
 }
+
+func (s *loggingService) GetCatalogEntityLinkedPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetCatalogEntityLinkedPipelines", err) }()
+
+	return s.Service.GetCatalogEntityLinkedPipelines(ctx, catalogEntity)
+}
+
+func (s *loggingService) GetCatalogEntityPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity, filters map[api.FilterType][]string) (pipelines []*api.CatalogEntityPipeline, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetCatalogEntityPipelines", err) }()
+
+	return s.Service.GetCatalogEntityPipelines(ctx, catalogEntity, filters)
+}
+
+func (s *loggingService) GetCatalogEntityViolations(ctx context.Context, filters map[api.FilterType][]string) (violations []*api.CatalogEntityViolation, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetCatalogEntityViolations", err) }()
+
+	return s.Service.GetCatalogEntityViolations(ctx, filters)
+}
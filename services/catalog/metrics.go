@@ -43,3 +43,27 @@ func (s *metricsService) DeleteCatalogEntity(ctx context.Context, id string) (er
 
 	return s.Service.DeleteCatalogEntity(ctx, id)
 }
+
+func (s *metricsService) GetCatalogEntityLinkedPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetCatalogEntityLinkedPipelines", begin)
+	}(time.Now())
+
+	return s.Service.GetCatalogEntityLinkedPipelines(ctx, catalogEntity)
+}
+
+func (s *metricsService) GetCatalogEntityPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity, filters map[api.FilterType][]string) (pipelines []*api.CatalogEntityPipeline, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetCatalogEntityPipelines", begin)
+	}(time.Now())
+
+	return s.Service.GetCatalogEntityPipelines(ctx, catalogEntity, filters)
+}
+
+func (s *metricsService) GetCatalogEntityViolations(ctx context.Context, filters map[api.FilterType][]string) (violations []*api.CatalogEntityViolation, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetCatalogEntityViolations", begin)
+	}(time.Now())
+
+	return s.Service.GetCatalogEntityViolations(ctx, filters)
+}
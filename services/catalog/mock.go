@@ -3,13 +3,17 @@ package catalog
 import (
 	"context"
 
+	"github.com/estafette/estafette-ci-api/api"
 	contracts "github.com/estafette/estafette-ci-contracts"
 )
 
 type MockService struct {
-	CreateCatalogEntityFunc func(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error)
-	UpdateCatalogEntityFunc func(ctx context.Context, catalogEntity contracts.CatalogEntity) (err error)
-	DeleteCatalogEntityFunc func(ctx context.Context, id string) (err error)
+	CreateCatalogEntityFunc             func(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error)
+	UpdateCatalogEntityFunc             func(ctx context.Context, catalogEntity contracts.CatalogEntity) (err error)
+	DeleteCatalogEntityFunc             func(ctx context.Context, id string) (err error)
+	GetCatalogEntityLinkedPipelinesFunc func(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error)
+	GetCatalogEntityPipelinesFunc       func(ctx context.Context, catalogEntity contracts.CatalogEntity, filters map[api.FilterType][]string) (pipelines []*api.CatalogEntityPipeline, err error)
+	GetCatalogEntityViolationsFunc      func(ctx context.Context, filters map[api.FilterType][]string) (violations []*api.CatalogEntityViolation, err error)
 }
 
 func (s MockService) CreateCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
@@ -32,3 +36,24 @@ func (s MockService) DeleteCatalogEntity(ctx context.Context, id string) (err er
 	}
 	return s.DeleteCatalogEntityFunc(ctx, id)
 }
+
+func (s MockService) GetCatalogEntityLinkedPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error) {
+	if s.GetCatalogEntityLinkedPipelinesFunc == nil {
+		return
+	}
+	return s.GetCatalogEntityLinkedPipelinesFunc(ctx, catalogEntity)
+}
+
+func (s MockService) GetCatalogEntityPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity, filters map[api.FilterType][]string) (pipelines []*api.CatalogEntityPipeline, err error) {
+	if s.GetCatalogEntityPipelinesFunc == nil {
+		return
+	}
+	return s.GetCatalogEntityPipelinesFunc(ctx, catalogEntity, filters)
+}
+
+func (s MockService) GetCatalogEntityViolations(ctx context.Context, filters map[api.FilterType][]string) (violations []*api.CatalogEntityViolation, err error) {
+	if s.GetCatalogEntityViolationsFunc == nil {
+		return
+	}
+	return s.GetCatalogEntityViolationsFunc(ctx, filters)
+}
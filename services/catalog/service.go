@@ -2,10 +2,20 @@ package catalog
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
 	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// catalogEntitiesPageSize is the page size used when walking the children of catalog entities
+	catalogEntitiesPageSize = 100
+	// catalogEntityPipelineItems is the number of most recent builds and releases returned per pipeline under a catalog entity
+	catalogEntityPipelineItems = 5
 )
 
 // Service handles http requests for role-based-access-control
@@ -13,6 +23,9 @@ type Service interface {
 	CreateCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error)
 	UpdateCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (err error)
 	DeleteCatalogEntity(ctx context.Context, id string) (err error)
+	GetCatalogEntityLinkedPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error)
+	GetCatalogEntityPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity, filters map[api.FilterType][]string) (pipelines []*api.CatalogEntityPipeline, err error)
+	GetCatalogEntityViolations(ctx context.Context, filters map[api.FilterType][]string) (violations []*api.CatalogEntityViolation, err error)
 }
 
 // NewService returns a github.Service to handle incoming webhook events
@@ -29,13 +42,349 @@ type service struct {
 }
 
 func (s *service) CreateCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
-	return s.cockroachdbClient.InsertCatalogEntity(ctx, catalogEntity)
+
+	if err = api.ValidateCatalogEntitySchema(&catalogEntity, s.getCatalogConfig()); err != nil {
+		return
+	}
+
+	if err = api.ValidateCatalogEntityOwnership(&catalogEntity); err != nil {
+		return
+	}
+
+	if err = s.validateLinkedPipeline(ctx, catalogEntity); err != nil {
+		return
+	}
+
+	linkedPipelines, err := s.getLinkedPipelinePaths(ctx, &catalogEntity)
+	if err != nil {
+		return
+	}
+	ownersBefore := s.getPipelineOwners(ctx, linkedPipelines)
+
+	insertedCatalogEntity, err = s.cockroachdbClient.InsertCatalogEntity(ctx, catalogEntity)
+	if err != nil {
+		return
+	}
+
+	s.updatePipelineOwners(ctx, linkedPipelines, ownersBefore)
+
+	return
 }
 
 func (s *service) UpdateCatalogEntity(ctx context.Context, catalogEntity contracts.CatalogEntity) (err error) {
-	return s.cockroachdbClient.UpdateCatalogEntity(ctx, catalogEntity)
+
+	if err = api.ValidateCatalogEntitySchema(&catalogEntity, s.getCatalogConfig()); err != nil {
+		return
+	}
+
+	if err = api.ValidateCatalogEntityOwnership(&catalogEntity); err != nil {
+		return
+	}
+
+	if err = s.validateLinkedPipeline(ctx, catalogEntity); err != nil {
+		return
+	}
+
+	before, err := s.cockroachdbClient.GetCatalogEntityByID(ctx, catalogEntity.ID)
+	if err != nil {
+		return
+	}
+
+	// pipelines no longer linked under the entity lose its owner, newly linked ones gain it
+	linkedPipelines, err := s.getLinkedPipelinePaths(ctx, before, &catalogEntity)
+	if err != nil {
+		return
+	}
+	ownersBefore := s.getPipelineOwners(ctx, linkedPipelines)
+
+	err = s.cockroachdbClient.UpdateCatalogEntity(ctx, catalogEntity)
+	if err != nil {
+		return
+	}
+
+	s.updatePipelineOwners(ctx, linkedPipelines, ownersBefore)
+
+	return
 }
 
 func (s *service) DeleteCatalogEntity(ctx context.Context, id string) (err error) {
-	return s.cockroachdbClient.DeleteCatalogEntity(ctx, id)
+
+	before, err := s.cockroachdbClient.GetCatalogEntityByID(ctx, id)
+	if err != nil {
+		return
+	}
+
+	linkedPipelines, err := s.getLinkedPipelinePaths(ctx, before)
+	if err != nil {
+		return
+	}
+	ownersBefore := s.getPipelineOwners(ctx, linkedPipelines)
+
+	err = s.cockroachdbClient.DeleteCatalogEntity(ctx, id)
+	if err != nil {
+		return
+	}
+
+	s.updatePipelineOwners(ctx, linkedPipelines, ownersBefore)
+
+	return
+}
+
+// GetCatalogEntityLinkedPipelines returns the pipelines linked to the catalog entity or any of its descendants, whose owner changes along with the entity
+func (s *service) GetCatalogEntityLinkedPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error) {
+
+	linkedPipelines, err := s.getLinkedPipelinePaths(ctx, &catalogEntity)
+	if err != nil {
+		return
+	}
+
+	pipelines = make([]*contracts.Pipeline, 0)
+	for _, lp := range linkedPipelines {
+		repoSource, repoOwner, repoName, _ := splitLinkedPipeline(lp)
+		pipeline, err := s.cockroachdbClient.GetPipeline(ctx, repoSource, repoOwner, repoName, map[api.FilterType][]string{}, true)
+		if err != nil {
+			return nil, err
+		}
+		if pipeline != nil {
+			pipelines = append(pipelines, pipeline)
+		}
+	}
+
+	return
+}
+
+// GetCatalogEntityPipelines returns the pipelines linked to the catalog entity or any of its descendants, with their most recent builds and releases
+func (s *service) GetCatalogEntityPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity, filters map[api.FilterType][]string) (pipelines []*api.CatalogEntityPipeline, err error) {
+
+	catalogEntities, err := s.getCatalogEntityWithDescendants(ctx, catalogEntity)
+	if err != nil {
+		return
+	}
+
+	pipelines = make([]*api.CatalogEntityPipeline, 0)
+	seen := map[string]bool{}
+	for _, e := range catalogEntities {
+		repoSource, repoOwner, repoName, ok := splitLinkedPipeline(e.LinkedPipeline)
+		if !ok || seen[e.LinkedPipeline] {
+			continue
+		}
+		seen[e.LinkedPipeline] = true
+
+		// the permission filters leave out pipelines the caller has no access to
+		pipeline, err := s.cockroachdbClient.GetPipeline(ctx, repoSource, repoOwner, repoName, filters, true)
+		if err != nil {
+			return nil, err
+		}
+		if pipeline == nil {
+			continue
+		}
+
+		builds, err := s.cockroachdbClient.GetPipelineBuilds(ctx, repoSource, repoOwner, repoName, 1, catalogEntityPipelineItems, map[api.FilterType][]string{}, []api.OrderField{}, true)
+		if err != nil {
+			return nil, err
+		}
+
+		releases, err := s.cockroachdbClient.GetPipelineReleases(ctx, repoSource, repoOwner, repoName, 1, catalogEntityPipelineItems, map[api.FilterType][]string{}, []api.OrderField{})
+		if err != nil {
+			return nil, err
+		}
+
+		ownership, err := api.GetInheritedCatalogEntityOwnership(e, s.getCatalogEntityFunc(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		pipelines = append(pipelines, &api.CatalogEntityPipeline{
+			CatalogEntityID: e.ID,
+			Pipeline:        pipeline,
+			Builds:          builds,
+			Releases:        releases,
+			Ownership:       ownership,
+		})
+	}
+
+	return
+}
+
+// GetCatalogEntityViolations returns the stored catalog entities matching the filters that violate the schema for their key
+func (s *service) GetCatalogEntityViolations(ctx context.Context, filters map[api.FilterType][]string) (violations []*api.CatalogEntityViolation, err error) {
+
+	violations = make([]*api.CatalogEntityViolation, 0)
+	catalogConfig := s.getCatalogConfig()
+	if catalogConfig == nil {
+		return
+	}
+
+	for pageNumber := 1; ; pageNumber++ {
+		catalogEntities, err := s.cockroachdbClient.GetCatalogEntities(ctx, pageNumber, catalogEntitiesPageSize, filters, []api.OrderField{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range catalogEntities {
+			if v := api.GetCatalogEntitySchemaViolations(e, catalogConfig); len(v) > 0 {
+				violations = append(violations, &api.CatalogEntityViolation{
+					CatalogEntityID: e.ID,
+					Key:             e.Key,
+					Value:           e.Value,
+					Violations:      v,
+				})
+			}
+		}
+
+		if len(catalogEntities) < catalogEntitiesPageSize {
+			break
+		}
+	}
+
+	return
+}
+
+// getLinkedPipelinePaths returns the linked pipelines of the catalog entities and their descendants, without duplicates
+func (s *service) getLinkedPipelinePaths(ctx context.Context, catalogEntities ...*contracts.CatalogEntity) (linkedPipelines []string, err error) {
+
+	seen := map[string]bool{}
+	for _, ce := range catalogEntities {
+		if ce == nil {
+			continue
+		}
+
+		descendants, err := s.getCatalogEntityWithDescendants(ctx, *ce)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range descendants {
+			if _, _, _, ok := splitLinkedPipeline(e.LinkedPipeline); ok && !seen[e.LinkedPipeline] {
+				seen[e.LinkedPipeline] = true
+				linkedPipelines = append(linkedPipelines, e.LinkedPipeline)
+			}
+		}
+	}
+
+	return
+}
+
+// getPipelineOwners returns the ownership each linked pipeline inherits from the catalog
+// validateLinkedPipeline returns ErrInvalidCatalogEntityOwnership if the pipeline is already linked to another catalog entity, since a pipeline can only inherit ownership from one place
+func (s *service) validateLinkedPipeline(ctx context.Context, catalogEntity contracts.CatalogEntity) error {
+	if catalogEntity.LinkedPipeline == "" {
+		return nil
+	}
+
+	catalogEntities, err := s.cockroachdbClient.GetCatalogEntities(ctx, 1, 2, map[api.FilterType][]string{
+		api.FilterPipeline: {catalogEntity.LinkedPipeline},
+	}, []api.OrderField{})
+	if err != nil {
+		return err
+	}
+	for _, e := range catalogEntities {
+		if e.ID != catalogEntity.ID {
+			return fmt.Errorf("Pipeline %v is already linked to catalog entity %v=%v: %w", catalogEntity.LinkedPipeline, e.Key, e.Value, api.ErrInvalidCatalogEntityOwnership)
+		}
+	}
+
+	return nil
+}
+
+func (s *service) getPipelineOwners(ctx context.Context, linkedPipelines []string) map[string]*api.CatalogEntityOwnership {
+
+	owners := map[string]*api.CatalogEntityOwnership{}
+	for _, lp := range linkedPipelines {
+		repoSource, repoOwner, repoName, _ := splitLinkedPipeline(lp)
+		ownership, err := api.GetPipelineOwnership(repoSource, repoOwner, repoName, s.getCatalogEntityFunc(ctx))
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed retrieving catalog ownership for pipeline %v", lp)
+			continue
+		}
+		owners[lp] = ownership
+	}
+
+	return owners
+}
+
+// updatePipelineOwners replaces the owner each linked pipeline had before a catalog change with the owner it inherits now, so a previous owner doesn't keep access
+func (s *service) updatePipelineOwners(ctx context.Context, linkedPipelines []string, ownersBefore map[string]*api.CatalogEntityOwnership) {
+
+	ownersAfter := s.getPipelineOwners(ctx, linkedPipelines)
+
+	for _, lp := range linkedPipelines {
+		before, after := ownersBefore[lp], ownersAfter[lp]
+		if before.HasSameOwner(after) {
+			continue
+		}
+
+		repoSource, repoOwner, repoName, _ := splitLinkedPipeline(lp)
+		pipeline, err := s.cockroachdbClient.GetPipeline(ctx, repoSource, repoOwner, repoName, map[api.FilterType][]string{}, true)
+		if err != nil || pipeline == nil {
+			continue
+		}
+
+		pipeline.Groups, pipeline.Organizations = before.RemoveOwner(pipeline.Groups, pipeline.Organizations)
+		pipeline.Groups, pipeline.Organizations = after.ApplyOwner(pipeline.Groups, pipeline.Organizations)
+
+		if err = s.cockroachdbClient.UpdateComputedPipelinePermissions(ctx, *pipeline); err != nil {
+			log.Warn().Err(err).Msgf("Failed updating catalog owner of pipeline %v", lp)
+		}
+	}
+}
+
+// getCatalogEntityWithDescendants returns the catalog entity followed by all entities below it, following parent key and value
+func (s *service) getCatalogEntityWithDescendants(ctx context.Context, catalogEntity contracts.CatalogEntity) (catalogEntities []*contracts.CatalogEntity, err error) {
+
+	catalogEntities = []*contracts.CatalogEntity{&catalogEntity}
+	visited := map[string]bool{fmt.Sprintf("%v=%v", catalogEntity.Key, catalogEntity.Value): true}
+
+	for i := 0; i < len(catalogEntities); i++ {
+		parent := catalogEntities[i]
+		for pageNumber := 1; ; pageNumber++ {
+			children, err := s.cockroachdbClient.GetCatalogEntities(ctx, pageNumber, catalogEntitiesPageSize, map[api.FilterType][]string{
+				api.FilterParent: {fmt.Sprintf("%v=%v", parent.Key, parent.Value)},
+			}, []api.OrderField{})
+			if err != nil {
+				return nil, err
+			}
+
+			for _, c := range children {
+				key := fmt.Sprintf("%v=%v", c.Key, c.Value)
+				if !visited[key] {
+					visited[key] = true
+					catalogEntities = append(catalogEntities, c)
+				}
+			}
+
+			if len(children) < catalogEntitiesPageSize {
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func (s *service) getCatalogEntityFunc(ctx context.Context) func(filters map[api.FilterType][]string) (*contracts.CatalogEntity, error) {
+	return func(filters map[api.FilterType][]string) (*contracts.CatalogEntity, error) {
+		catalogEntities, err := s.cockroachdbClient.GetCatalogEntities(ctx, 1, 1, filters, []api.OrderField{})
+		if err != nil || len(catalogEntities) == 0 {
+			return nil, err
+		}
+		return catalogEntities[0], nil
+	}
+}
+
+func (s *service) getCatalogConfig() *api.CatalogConfig {
+	if s.config == nil {
+		return nil
+	}
+
+	return s.config.Catalog
+}
+
+func splitLinkedPipeline(linkedPipeline string) (repoSource, repoOwner, repoName string, ok bool) {
+	parts := strings.SplitN(linkedPipeline, "/", 3)
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+
+	return parts[0], parts[1], parts[2], true
 }
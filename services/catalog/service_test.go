@@ -0,0 +1,68 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCatalogEntityLinkedPipeline(t *testing.T) {
+
+	t.Run("ReturnsErrorIfPipelineIsAlreadyLinkedToOtherCatalogEntity", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetCatalogEntitiesFunc: func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (catalogEntities []*contracts.CatalogEntity, err error) {
+				if values, ok := filters[api.FilterPipeline]; ok && values[0] == "github.com/estafette/estafette-ci-web" {
+					return []*contracts.CatalogEntity{{ID: "7", Key: "service", Value: "web", LinkedPipeline: "github.com/estafette/estafette-ci-web"}}, nil
+				}
+				return nil, nil
+			},
+			InsertCatalogEntityFunc: func(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
+				assert.Fail(t, "Catalog entity shouldn't be inserted")
+				return &catalogEntity, nil
+			},
+		}
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		_, err := service.CreateCatalogEntity(context.Background(), contracts.CatalogEntity{Key: "service", Value: "web-v2", LinkedPipeline: "github.com/estafette/estafette-ci-web"})
+
+		assert.True(t, errors.Is(err, api.ErrInvalidCatalogEntityOwnership))
+	})
+}
+
+func TestUpdateCatalogEntityLinkedPipeline(t *testing.T) {
+
+	t.Run("AllowsCatalogEntityToKeepItsLinkedPipeline", func(t *testing.T) {
+
+		entity := contracts.CatalogEntity{ID: "7", Key: "service", Value: "web", LinkedPipeline: "github.com/estafette/estafette-ci-web"}
+		updated := false
+		cockroachdbClient := cockroachdb.MockClient{
+			GetCatalogEntitiesFunc: func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (catalogEntities []*contracts.CatalogEntity, err error) {
+				if _, ok := filters[api.FilterPipeline]; ok {
+					return []*contracts.CatalogEntity{&entity}, nil
+				}
+				return nil, nil
+			},
+			GetCatalogEntityByIDFunc: func(ctx context.Context, id string) (catalogEntity *contracts.CatalogEntity, err error) {
+				return &entity, nil
+			},
+			UpdateCatalogEntityFunc: func(ctx context.Context, catalogEntity contracts.CatalogEntity) (err error) {
+				updated = true
+				return nil
+			},
+		}
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		err := service.UpdateCatalogEntity(context.Background(), entity)
+
+		assert.Nil(t, err)
+		assert.True(t, updated)
+	})
+}
@@ -38,3 +38,24 @@ func (s *tracingService) DeleteCatalogEntity(ctx context.Context, id string) (er
 
 	return s.Service.DeleteCatalogEntity(ctx, id)
 }
+
+func (s *tracingService) GetCatalogEntityLinkedPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetCatalogEntityLinkedPipelines"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetCatalogEntityLinkedPipelines(ctx, catalogEntity)
+}
+
+func (s *tracingService) GetCatalogEntityPipelines(ctx context.Context, catalogEntity contracts.CatalogEntity, filters map[api.FilterType][]string) (pipelines []*api.CatalogEntityPipeline, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetCatalogEntityPipelines"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetCatalogEntityPipelines(ctx, catalogEntity, filters)
+}
+
+func (s *tracingService) GetCatalogEntityViolations(ctx context.Context, filters map[api.FilterType][]string) (violations []*api.CatalogEntityViolation, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetCatalogEntityViolations"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetCatalogEntityViolations(ctx, filters)
+}
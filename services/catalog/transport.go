@@ -1,23 +1,26 @@
 package catalog
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
 
 	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/services/audit"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
 // NewHandler returns a new rbac.Handler
-func NewHandler(config *api.APIConfig, service Service, cockroachdbClient cockroachdb.Client) Handler {
+func NewHandler(config *api.APIConfig, service Service, cockroachdbClient cockroachdb.Client, auditService audit.Service) Handler {
 	return Handler{
 		config:            config,
 		service:           service,
 		cockroachdbClient: cockroachdbClient,
+		auditService:      auditService,
 	}
 }
 
@@ -25,6 +28,7 @@ type Handler struct {
 	config            *api.APIConfig
 	service           Service
 	cockroachdbClient cockroachdb.Client
+	auditService      audit.Service
 }
 
 func (h *Handler) GetCatalogEntityLabels(c *gin.Context) {
@@ -279,6 +283,30 @@ func (h *Handler) GetCatalogEntities(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *Handler) GetCatalogEntityViolations(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionCatalogEntitiesList) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	filters := api.GetFilters(c)
+
+	ctx := c.Request.Context()
+
+	violations, err := h.service.GetCatalogEntityViolations(ctx, filters)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed validating catalog entities against their schema")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": violations,
+	})
+}
+
 func (h *Handler) GetCatalogEntity(c *gin.Context) {
 
 	// ensure the request has the correct permission
@@ -300,6 +328,38 @@ func (h *Handler) GetCatalogEntity(c *gin.Context) {
 	c.JSON(http.StatusOK, catalogEntity)
 }
 
+func (h *Handler) GetCatalogEntityPipelines(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionCatalogEntitiesGet) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	catalogEntity, err := h.cockroachdbClient.GetCatalogEntityByID(ctx, id)
+	if err != nil || catalogEntity == nil {
+		log.Error().Err(err).Msgf("Failed retrieving catalogEntity with id %v from db", id)
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound)})
+		return
+	}
+
+	filters := api.SetPermissionsFilters(c, map[api.FilterType][]string{})
+
+	pipelines, err := h.service.GetCatalogEntityPipelines(ctx, *catalogEntity, filters)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed retrieving pipelines for catalogEntity with id %v", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"items": pipelines,
+	})
+}
+
 func (h *Handler) CreateCatalogEntity(c *gin.Context) {
 
 	// ensure the request has the correct permission
@@ -319,13 +379,25 @@ func (h *Handler) CreateCatalogEntity(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
+	if !h.requestHasPermissionForLinkedPipelines(c, nil, &catalogEntity) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "Changing the owner of linked pipelines requires permission to update those pipelines"})
+		return
+	}
+
 	insertedCatalogEntity, err := h.service.CreateCatalogEntity(ctx, catalogEntity)
+	if errors.Is(err, api.ErrCatalogEntitySchemaViolation) || errors.Is(err, api.ErrInvalidCatalogEntityOwnership) {
+		log.Error().Err(err).Msg("Failed inserting catalog entity")
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("Failed inserting catalog entity")
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionCatalogEntitiesCreate, "catalog-entity", insertedCatalogEntity.ID, nil, insertedCatalogEntity)
+
 	c.JSON(http.StatusCreated, insertedCatalogEntity)
 }
 
@@ -355,13 +427,32 @@ func (h *Handler) UpdateCatalogEntity(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
+	before, err := h.cockroachdbClient.GetCatalogEntityByID(ctx, id)
+	if err != nil || before == nil {
+		log.Error().Err(err).Msgf("Failed retrieving catalogEntity with id %v from db", id)
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound)})
+		return
+	}
+
+	if !h.requestHasPermissionForLinkedPipelines(c, before, &catalogEntity) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "Changing the owner of linked pipelines requires permission to update those pipelines"})
+		return
+	}
+
 	err = h.service.UpdateCatalogEntity(ctx, catalogEntity)
+	if errors.Is(err, api.ErrCatalogEntitySchemaViolation) || errors.Is(err, api.ErrInvalidCatalogEntityOwnership) {
+		log.Error().Err(err).Msg("Failed updating catalog entity")
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+		return
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("Failed updating catalog entity")
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionCatalogEntitiesUpdate, "catalog-entity", id, before, catalogEntity)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -376,13 +467,27 @@ func (h *Handler) DeleteCatalogEntity(c *gin.Context) {
 	id := c.Param("id")
 	ctx := c.Request.Context()
 
-	err := h.service.DeleteCatalogEntity(ctx, id)
+	before, err := h.cockroachdbClient.GetCatalogEntityByID(ctx, id)
+	if err != nil || before == nil {
+		log.Error().Err(err).Msgf("Failed retrieving catalogEntity with id %v from db", id)
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound)})
+		return
+	}
+
+	if !h.requestHasPermissionForLinkedPipelines(c, before, nil) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "Changing the owner of linked pipelines requires permission to update those pipelines"})
+		return
+	}
+
+	err = h.service.DeleteCatalogEntity(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed deleting catalog entity")
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionCatalogEntitiesDelete, "catalog-entity", id, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -509,3 +614,32 @@ func (h *Handler) GetCatalogGroup(c *gin.Context) {
 
 	c.JSON(http.StatusOK, group)
 }
+
+// requestHasPermissionForLinkedPipelines checks whether the request can update every pipeline linked under the catalog entity before and after the change, if the change can affect who owns them;
+// otherwise catalog permissions alone would be enough to grant any group access to any pipeline
+func (h *Handler) requestHasPermissionForLinkedPipelines(c *gin.Context, before, after *contracts.CatalogEntity) bool {
+
+	if !api.CatalogEntityChangeAffectsOwners(before, after) {
+		return true
+	}
+
+	for _, ce := range []*contracts.CatalogEntity{before, after} {
+		if ce == nil {
+			continue
+		}
+
+		pipelines, err := h.service.GetCatalogEntityLinkedPipelines(c.Request.Context(), *ce)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed retrieving pipelines linked under catalog entity %v=%v", ce.Key, ce.Value)
+			return false
+		}
+
+		for _, p := range pipelines {
+			if !api.RequestTokenHasPermissionForResource(c, api.PermissionPipelinesUpdate, api.GetPermissionResourceForPipeline(p.Organizations, p.Groups, p.Labels, "")) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
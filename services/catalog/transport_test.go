@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/services/audit"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCatalogEntity(t *testing.T) {
+
+	body := `{"key":"service","value":"estafette-ci-web","linkedPipeline":"github.com/estafette/estafette-ci-web","metadata":{"owner":{"type":"group","name":"team-catalog"}}}`
+
+	getContext := func(recorder *httptest.ResponseRecorder, role string) *gin.Context {
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/catalog/entities", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{role},
+		})
+		return c
+	}
+
+	getService := func(created *bool) MockService {
+		return MockService{
+			GetCatalogEntityLinkedPipelinesFunc: func(ctx context.Context, catalogEntity contracts.CatalogEntity) (pipelines []*contracts.Pipeline, err error) {
+				return []*contracts.Pipeline{{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-web", Groups: []*contracts.Group{{Name: "team-web"}}}}, nil
+			},
+			CreateCatalogEntityFunc: func(ctx context.Context, catalogEntity contracts.CatalogEntity) (insertedCatalogEntity *contracts.CatalogEntity, err error) {
+				*created = true
+				return &catalogEntity, nil
+			},
+		}
+	}
+
+	t.Run("ReturnsForbiddenIfRequestCannotUpdateLinkedPipelines", func(t *testing.T) {
+
+		created := false
+		handler := NewHandler(&api.APIConfig{}, getService(&created), cockroachdb.MockClient{}, audit.MockService{})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.CreateCatalogEntity(getContext(recorder, "catalog.entities.admin"))
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.False(t, created)
+	})
+
+	t.Run("CreatesCatalogEntityIfRequestCanUpdateLinkedPipelines", func(t *testing.T) {
+
+		created := false
+		handler := NewHandler(&api.APIConfig{}, getService(&created), cockroachdb.MockClient{}, audit.MockService{})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.CreateCatalogEntity(getContext(recorder, "administrator"))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.True(t, created)
+	})
+}
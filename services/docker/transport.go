@@ -0,0 +1,128 @@
+package docker
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/dockerhubapi"
+	"github.com/estafette/estafette-ci-api/services/estafette"
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// NewHandler returns a docker.Handler
+func NewHandler(config *api.APIConfig, dockerhubapiClient dockerhubapi.Client, estafetteService estafette.Service) Handler {
+	return Handler{
+		config:             config,
+		dockerhubapiClient: dockerhubapiClient,
+		estafetteService:   estafetteService,
+	}
+}
+
+type Handler struct {
+	config             *api.APIConfig
+	dockerhubapiClient dockerhubapi.Client
+	estafetteService   estafette.Service
+}
+
+// PostDockerHubEvent handles docker hub repository webhooks
+func (h *Handler) PostDockerHubEvent(c *gin.Context) {
+
+	if !h.hasValidWebhookToken(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "Webhook token is invalid"})
+		return
+	}
+
+	var pushEvent dockerhubapi.DockerHubPushEvent
+	err := c.BindJSON(&pushEvent)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding PostDockerHubEvent body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	image := pushEvent.GetImage()
+	tag := pushEvent.PushData.Tag
+	if tag == "" {
+		tag = "latest"
+	}
+
+	// firing triggers is rate limited and can take minutes for popular images, so acknowledge the webhook before docker hub times out and redelivers
+	go func(image, tag string) {
+		// docker hub doesn't send the digest, so retrieve it for the pushed tag
+		ctx := context.Background()
+		digest := ""
+		token, err := h.dockerhubapiClient.GetToken(ctx, image)
+		if err == nil {
+			var imageDigest dockerhubapi.DockerImageDigest
+			imageDigest, err = h.dockerhubapiClient.GetDigest(ctx, token, image, tag)
+			digest = imageDigest.Digest
+		}
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed retrieving digest for pushed image %v:%v, firing triggers without digest", image, tag)
+		}
+
+		h.fireDockerTriggers(ctx, []manifest.EstafetteDockerEvent{api.NewDockerPushEvent(image, tag, digest)})
+	}(image, tag)
+
+	c.String(http.StatusAccepted, "Aye aye!")
+}
+
+// PostRegistryEvents handles notifications from registries implementing the docker distribution notification format
+func (h *Handler) PostRegistryEvents(c *gin.Context) {
+
+	if !h.hasValidWebhookToken(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "Webhook token is invalid"})
+		return
+	}
+
+	var envelope dockerhubapi.RegistryNotificationEnvelope
+	err := c.ShouldBindJSON(&envelope)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding PostRegistryEvents body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	dockerEvents := []manifest.EstafetteDockerEvent{}
+	for _, e := range envelope.Events {
+		if e.IsTaggedManifestPush() {
+			dockerEvents = append(dockerEvents, api.NewDockerPushEvent(e.GetImage(), e.Target.Tag, e.Target.Digest))
+		}
+	}
+
+	// fire in the background, since the registry redelivers notifications it doesn't get a timely response to
+	go h.fireDockerTriggers(context.Background(), dockerEvents)
+
+	c.String(http.StatusAccepted, "Aye aye!")
+}
+
+// fireDockerTriggers runs outside of the request, so a cancelled request doesn't stop the remaining builds from being triggered
+func (h *Handler) fireDockerTriggers(ctx context.Context, dockerEvents []manifest.EstafetteDockerEvent) {
+	for _, e := range dockerEvents {
+		err := h.estafetteService.FireDockerTriggers(ctx, e)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed firing docker triggers for image %v:%v", e.Image, e.Tag)
+		}
+	}
+}
+
+// hasValidWebhookToken checks the token passed in the X-Estafette-Webhook-Token header or the token query parameter, since docker hub webhooks can't set headers
+func (h *Handler) hasValidWebhookToken(c *gin.Context) bool {
+	if h.config == nil || h.config.Integrations == nil || h.config.Integrations.Docker == nil || h.config.Integrations.Docker.WebhookToken == "" {
+		return false
+	}
+
+	token := c.GetHeader("X-Estafette-Webhook-Token")
+	if token == "" {
+		token = c.Query("token")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.config.Integrations.Docker.WebhookToken)) == 1
+}
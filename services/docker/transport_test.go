@@ -0,0 +1,118 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/dockerhubapi"
+	"github.com/estafette/estafette-ci-api/services/estafette"
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostRegistryEvents(t *testing.T) {
+
+	config := &api.APIConfig{
+		Integrations: &api.APIConfigIntegrations{
+			Docker: &api.DockerConfig{
+				WebhookToken: "secret",
+			},
+		},
+	}
+
+	getContext := func(recorder *httptest.ResponseRecorder, token, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/integrations/docker/registry/events?token="+token, strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/vnd.docker.distribution.events.v1+json")
+		return c
+	}
+
+	body := `{"events":[
+		{"action":"push","target":{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","digest":"sha256:layer","repository":"team/app"},"request":{"host":"registry.example.com"}},
+		{"action":"push","target":{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","digest":"sha256:abc","repository":"team/app","tag":"1.0.0"},"request":{"host":"registry.example.com"}},
+		{"action":"pull","target":{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","digest":"sha256:abc","repository":"team/app","tag":"1.0.0"},"request":{"host":"registry.example.com"}}
+	]}`
+
+	t.Run("FiresDockerTriggersForTaggedManifestPushesOnly", func(t *testing.T) {
+
+		firedEvents := make(chan manifest.EstafetteDockerEvent, 3)
+		estafetteService := estafette.MockService{
+			FireDockerTriggersFunc: func(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) error {
+				firedEvents <- dockerEvent
+				return nil
+			},
+		}
+		handler := NewHandler(config, dockerhubapi.MockClient{}, estafetteService)
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.PostRegistryEvents(getContext(recorder, "secret", body))
+
+		assert.Equal(t, http.StatusAccepted, recorder.Result().StatusCode)
+		select {
+		case firedEvent := <-firedEvents:
+			assert.Equal(t, "push", firedEvent.Event)
+			assert.Equal(t, "registry.example.com/team/app", firedEvent.Image)
+			assert.Equal(t, "1.0.0@sha256:abc", firedEvent.Tag)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Docker triggers were not fired")
+		}
+		select {
+		case firedEvent := <-firedEvents:
+			assert.Fail(t, "Fired docker triggers for event that isn't a tagged manifest push", "%v", firedEvent)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("FiresDockerTriggersAfterRequestIsCancelled", func(t *testing.T) {
+
+		firedEvents := make(chan error, 1)
+		estafetteService := estafette.MockService{
+			FireDockerTriggersFunc: func(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) error {
+				firedEvents <- ctx.Err()
+				return nil
+			},
+		}
+		handler := NewHandler(config, dockerhubapi.MockClient{}, estafetteService)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, "secret", body)
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		// act
+		handler.PostRegistryEvents(c)
+		cancel()
+
+		select {
+		case err := <-firedEvents:
+			assert.Nil(t, err)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Docker triggers were not fired")
+		}
+	})
+
+	t.Run("ReturnsUnauthorizedForInvalidToken", func(t *testing.T) {
+
+		fired := false
+		estafetteService := estafette.MockService{
+			FireDockerTriggersFunc: func(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) error {
+				fired = true
+				return nil
+			},
+		}
+		handler := NewHandler(config, dockerhubapi.MockClient{}, estafetteService)
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.PostRegistryEvents(getContext(recorder, "other", body))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Result().StatusCode)
+		assert.False(t, fired)
+	})
+}
@@ -25,6 +25,12 @@ func (s *loggingService) CreateBuild(ctx context.Context, build contracts.Build,
 	return s.Service.CreateBuild(ctx, build, waitForJobToStart)
 }
 
+func (s *loggingService) CreateManualBuild(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error) {
+	defer func() { api.HandleLogError(s.prefix, "CreateManualBuild", err) }()
+
+	return s.Service.CreateManualBuild(ctx, build, parameters)
+}
+
 func (s *loggingService) FinishBuild(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error) {
 	defer func() { api.HandleLogError(s.prefix, "FinishBuild", err) }()
 
@@ -73,6 +79,54 @@ func (s *loggingService) FireCronTriggers(ctx context.Context) (err error) {
 	return s.Service.FireCronTriggers(ctx)
 }
 
+func (s *loggingService) FireWebhookTriggers(ctx context.Context, webhook api.Webhook, variables map[string]string) (err error) {
+	defer func() { api.HandleLogError(s.prefix, "FireWebhookTriggers", err) }()
+
+	return s.Service.FireWebhookTriggers(ctx, webhook, variables)
+}
+
+func (s *loggingService) FireDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (err error) {
+	defer func() { api.HandleLogError(s.prefix, "FireDockerTriggers", err) }()
+
+	return s.Service.FireDockerTriggers(ctx, dockerEvent)
+}
+
+func (s *loggingService) SimulateTriggers(ctx context.Context, e manifest.EstafetteEvent) (firings []*api.TriggerFiring, err error) {
+	defer func() { api.HandleLogError(s.prefix, "SimulateTriggers", err) }()
+
+	return s.Service.SimulateTriggers(ctx, e)
+}
+
+func (s *loggingService) GetTriggerGraph(ctx context.Context, pipeline contracts.Pipeline) (graph *api.TriggerGraph, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetTriggerGraph", err) }()
+
+	return s.Service.GetTriggerGraph(ctx, pipeline)
+}
+
+func (s *loggingService) GetDoraMetrics(ctx context.Context, groupBy string, filters map[api.FilterType][]string) (metrics []*api.DoraMetrics, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetDoraMetrics", err) }()
+
+	return s.Service.GetDoraMetrics(ctx, groupBy, filters)
+}
+
+func (s *loggingService) ExportDoraMetrics(ctx context.Context) (err error) {
+	defer func() { api.HandleLogError(s.prefix, "ExportDoraMetrics", err) }()
+
+	return s.Service.ExportDoraMetrics(ctx)
+}
+
+func (s *loggingService) GetFlakyPipelines(ctx context.Context, filters map[api.FilterType][]string) (reports []*api.FlakinessReport, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetFlakyPipelines", err) }()
+
+	return s.Service.GetFlakyPipelines(ctx, filters)
+}
+
+func (s *loggingService) GetPipelineFlakiness(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (report *api.FlakinessReport, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetPipelineFlakiness", err) }()
+
+	return s.Service.GetPipelineFlakiness(ctx, repoSource, repoOwner, repoName, filters)
+}
+
 func (s *loggingService) Rename(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	defer func() { api.HandleLogError(s.prefix, "Rename", err) }()
 
@@ -28,6 +28,14 @@ func (s *metricsService) CreateBuild(ctx context.Context, build contracts.Build,
 	return s.Service.CreateBuild(ctx, build, waitForJobToStart)
 }
 
+func (s *metricsService) CreateManualBuild(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "CreateManualBuild", begin)
+	}(time.Now())
+
+	return s.Service.CreateManualBuild(ctx, build, parameters)
+}
+
 func (s *metricsService) FinishBuild(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error) {
 	defer func(begin time.Time) { api.UpdateMetrics(s.requestCount, s.requestLatency, "FinishBuild", begin) }(time.Now())
 
@@ -86,6 +94,70 @@ func (s *metricsService) FireCronTriggers(ctx context.Context) (err error) {
 	return s.Service.FireCronTriggers(ctx)
 }
 
+func (s *metricsService) FireWebhookTriggers(ctx context.Context, webhook api.Webhook, variables map[string]string) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "FireWebhookTriggers", begin)
+	}(time.Now())
+
+	return s.Service.FireWebhookTriggers(ctx, webhook, variables)
+}
+
+func (s *metricsService) FireDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "FireDockerTriggers", begin)
+	}(time.Now())
+
+	return s.Service.FireDockerTriggers(ctx, dockerEvent)
+}
+
+func (s *metricsService) SimulateTriggers(ctx context.Context, e manifest.EstafetteEvent) (firings []*api.TriggerFiring, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "SimulateTriggers", begin)
+	}(time.Now())
+
+	return s.Service.SimulateTriggers(ctx, e)
+}
+
+func (s *metricsService) GetTriggerGraph(ctx context.Context, pipeline contracts.Pipeline) (graph *api.TriggerGraph, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetTriggerGraph", begin)
+	}(time.Now())
+
+	return s.Service.GetTriggerGraph(ctx, pipeline)
+}
+
+func (s *metricsService) GetDoraMetrics(ctx context.Context, groupBy string, filters map[api.FilterType][]string) (metrics []*api.DoraMetrics, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetDoraMetrics", begin)
+	}(time.Now())
+
+	return s.Service.GetDoraMetrics(ctx, groupBy, filters)
+}
+
+func (s *metricsService) ExportDoraMetrics(ctx context.Context) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "ExportDoraMetrics", begin)
+	}(time.Now())
+
+	return s.Service.ExportDoraMetrics(ctx)
+}
+
+func (s *metricsService) GetFlakyPipelines(ctx context.Context, filters map[api.FilterType][]string) (reports []*api.FlakinessReport, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetFlakyPipelines", begin)
+	}(time.Now())
+
+	return s.Service.GetFlakyPipelines(ctx, filters)
+}
+
+func (s *metricsService) GetPipelineFlakiness(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (report *api.FlakinessReport, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetPipelineFlakiness", begin)
+	}(time.Now())
+
+	return s.Service.GetPipelineFlakiness(ctx, repoSource, repoOwner, repoName, filters)
+}
+
 func (s *metricsService) Rename(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	defer func(begin time.Time) { api.UpdateMetrics(s.requestCount, s.requestLatency, "Rename", begin) }(time.Now())
 
@@ -11,6 +11,7 @@ import (
 
 type MockService struct {
 	CreateBuildFunc               func(ctx context.Context, build contracts.Build, waitForJobToStart bool) (b *contracts.Build, err error)
+	CreateManualBuildFunc         func(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error)
 	FinishBuildFunc               func(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error)
 	CreateReleaseFunc             func(ctx context.Context, release contracts.Release, mft manifest.EstafetteManifest, repoBranch, repoRevision string, waitForJobToStart bool) (r *contracts.Release, err error)
 	FinishReleaseFunc             func(ctx context.Context, repoSource, repoOwner, repoName string, releaseID int, releaseStatus string) (err error)
@@ -19,6 +20,14 @@ type MockService struct {
 	FireReleaseTriggersFunc       func(ctx context.Context, release contracts.Release, event string) (err error)
 	FirePubSubTriggersFunc        func(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (err error)
 	FireCronTriggersFunc          func(ctx context.Context) (err error)
+	FireWebhookTriggersFunc       func(ctx context.Context, webhook api.Webhook, variables map[string]string) (err error)
+	FireDockerTriggersFunc        func(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (err error)
+	SimulateTriggersFunc          func(ctx context.Context, e manifest.EstafetteEvent) (firings []*api.TriggerFiring, err error)
+	GetTriggerGraphFunc           func(ctx context.Context, pipeline contracts.Pipeline) (graph *api.TriggerGraph, err error)
+	GetDoraMetricsFunc            func(ctx context.Context, groupBy string, filters map[api.FilterType][]string) (metrics []*api.DoraMetrics, err error)
+	ExportDoraMetricsFunc         func(ctx context.Context) (err error)
+	GetFlakyPipelinesFunc         func(ctx context.Context, filters map[api.FilterType][]string) (reports []*api.FlakinessReport, err error)
+	GetPipelineFlakinessFunc      func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (report *api.FlakinessReport, err error)
 	RenameFunc                    func(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error)
 	ArchiveFunc                   func(ctx context.Context, repoSource, repoOwner, repoName string) (err error)
 	UnarchiveFunc                 func(ctx context.Context, repoSource, repoOwner, repoName string) (err error)
@@ -34,6 +43,13 @@ func (s MockService) CreateBuild(ctx context.Context, build contracts.Build, wai
 	return s.CreateBuildFunc(ctx, build, waitForJobToStart)
 }
 
+func (s MockService) CreateManualBuild(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error) {
+	if s.CreateManualBuildFunc == nil {
+		return
+	}
+	return s.CreateManualBuildFunc(ctx, build, parameters)
+}
+
 func (s MockService) FinishBuild(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error) {
 	if s.FinishBuildFunc == nil {
 		return
@@ -90,6 +106,62 @@ func (s MockService) FireCronTriggers(ctx context.Context) (err error) {
 	return s.FireCronTriggersFunc(ctx)
 }
 
+func (s MockService) FireWebhookTriggers(ctx context.Context, webhook api.Webhook, variables map[string]string) (err error) {
+	if s.FireWebhookTriggersFunc == nil {
+		return
+	}
+	return s.FireWebhookTriggersFunc(ctx, webhook, variables)
+}
+
+func (s MockService) FireDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (err error) {
+	if s.FireDockerTriggersFunc == nil {
+		return
+	}
+	return s.FireDockerTriggersFunc(ctx, dockerEvent)
+}
+
+func (s MockService) SimulateTriggers(ctx context.Context, e manifest.EstafetteEvent) (firings []*api.TriggerFiring, err error) {
+	if s.SimulateTriggersFunc == nil {
+		return
+	}
+	return s.SimulateTriggersFunc(ctx, e)
+}
+
+func (s MockService) GetTriggerGraph(ctx context.Context, pipeline contracts.Pipeline) (graph *api.TriggerGraph, err error) {
+	if s.GetTriggerGraphFunc == nil {
+		return
+	}
+	return s.GetTriggerGraphFunc(ctx, pipeline)
+}
+
+func (s MockService) GetDoraMetrics(ctx context.Context, groupBy string, filters map[api.FilterType][]string) (metrics []*api.DoraMetrics, err error) {
+	if s.GetDoraMetricsFunc == nil {
+		return
+	}
+	return s.GetDoraMetricsFunc(ctx, groupBy, filters)
+}
+
+func (s MockService) ExportDoraMetrics(ctx context.Context) (err error) {
+	if s.ExportDoraMetricsFunc == nil {
+		return
+	}
+	return s.ExportDoraMetricsFunc(ctx)
+}
+
+func (s MockService) GetFlakyPipelines(ctx context.Context, filters map[api.FilterType][]string) (reports []*api.FlakinessReport, err error) {
+	if s.GetFlakyPipelinesFunc == nil {
+		return
+	}
+	return s.GetFlakyPipelinesFunc(ctx, filters)
+}
+
+func (s MockService) GetPipelineFlakiness(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (report *api.FlakinessReport, err error) {
+	if s.GetPipelineFlakinessFunc == nil {
+		return
+	}
+	return s.GetPipelineFlakinessFunc(ctx, repoSource, repoOwner, repoName, filters)
+}
+
 func (s MockService) Rename(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	if s.RenameFunc == nil {
 		return
@@ -2,6 +2,7 @@ package estafette
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"regexp"
@@ -24,13 +25,18 @@ import (
 )
 
 var (
-	ErrNoBuildCreated   = errors.New("No build is created")
-	ErrNoReleaseCreated = errors.New("No release is created")
+	ErrNoBuildCreated      = errors.New("No build is created")
+	ErrNoReleaseCreated    = errors.New("No release is created")
+	ErrTriggerChainTooLong = errors.New("The trigger is part of a chain exceeding the maximum chain depth")
+	ErrTriggerLoop         = errors.New("The trigger would fire a build or release that caused it")
+	ErrUnsupportedEvent    = errors.New("The event type is not supported for triggers")
+	ErrSecretInParameters  = errors.New("Parameters can't contain secrets")
 )
 
 // Service encapsulates build and release creation and re-triggering
 type Service interface {
 	CreateBuild(ctx context.Context, build contracts.Build, waitForJobToStart bool) (b *contracts.Build, err error)
+	CreateManualBuild(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error)
 	FinishBuild(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error)
 	CreateRelease(ctx context.Context, release contracts.Release, mft manifest.EstafetteManifest, repoBranch, repoRevision string, waitForJobToStart bool) (r *contracts.Release, err error)
 	FinishRelease(ctx context.Context, repoSource, repoOwner, repoName string, releaseID int, releaseStatus string) (err error)
@@ -39,6 +45,14 @@ type Service interface {
 	FireReleaseTriggers(ctx context.Context, release contracts.Release, event string) (err error)
 	FirePubSubTriggers(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (err error)
 	FireCronTriggers(ctx context.Context) (err error)
+	FireWebhookTriggers(ctx context.Context, webhook api.Webhook, variables map[string]string) (err error)
+	FireDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (err error)
+	SimulateTriggers(ctx context.Context, e manifest.EstafetteEvent) (firings []*api.TriggerFiring, err error)
+	GetTriggerGraph(ctx context.Context, pipeline contracts.Pipeline) (graph *api.TriggerGraph, err error)
+	GetDoraMetrics(ctx context.Context, groupBy string, filters map[api.FilterType][]string) (metrics []*api.DoraMetrics, err error)
+	ExportDoraMetrics(ctx context.Context) (err error)
+	GetFlakyPipelines(ctx context.Context, filters map[api.FilterType][]string) (reports []*api.FlakinessReport, err error)
+	GetPipelineFlakiness(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (report *api.FlakinessReport, err error)
 	Rename(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error)
 	Archive(ctx context.Context, repoSource, repoOwner, repoName string) (err error)
 	Unarchive(ctx context.Context, repoSource, repoOwner, repoName string) (err error)
@@ -48,7 +62,7 @@ type Service interface {
 }
 
 // NewService returns a new estafette.Service
-func NewService(config *api.APIConfig, cockroachdbClient cockroachdb.Client, prometheusClient prometheus.Client, cloudStorageClient cloudstorage.Client, builderapiClient builderapi.Client, githubJobVarsFunc func(context.Context, string, string, string) (string, string, error), bitbucketJobVarsFunc func(context.Context, string, string, string) (string, string, error), cloudsourceJobVarsFunc func(context.Context, string, string, string) (string, string, error)) Service {
+func NewService(config *api.APIConfig, cockroachdbClient cockroachdb.Client, prometheusClient prometheus.Client, cloudStorageClient cloudstorage.Client, builderapiClient builderapi.Client, warningHelper api.WarningHelper, githubJobVarsFunc func(context.Context, string, string, string) (string, string, error), bitbucketJobVarsFunc func(context.Context, string, string, string) (string, string, error), cloudsourceJobVarsFunc func(context.Context, string, string, string) (string, string, error)) Service {
 
 	return &service{
 		config:                 config,
@@ -56,6 +70,7 @@ func NewService(config *api.APIConfig, cockroachdbClient cockroachdb.Client, pro
 		prometheusClient:       prometheusClient,
 		cloudStorageClient:     cloudStorageClient,
 		builderapiClient:       builderapiClient,
+		warningHelper:          warningHelper,
 		githubJobVarsFunc:      githubJobVarsFunc,
 		bitbucketJobVarsFunc:   bitbucketJobVarsFunc,
 		cloudsourceJobVarsFunc: cloudsourceJobVarsFunc,
@@ -68,17 +83,38 @@ type service struct {
 	prometheusClient       prometheus.Client
 	cloudStorageClient     cloudstorage.Client
 	builderapiClient       builderapi.Client
+	warningHelper          api.WarningHelper
 	githubJobVarsFunc      func(context.Context, string, string, string) (string, string, error)
 	bitbucketJobVarsFunc   func(context.Context, string, string, string) (string, string, error)
 	cloudsourceJobVarsFunc func(context.Context, string, string, string) (string, string, error)
 }
 
 func (s *service) CreateBuild(ctx context.Context, build contracts.Build, waitForJobToStart bool) (createdBuild *contracts.Build, err error) {
+	return s.createBuild(ctx, build, map[string]string{}, waitForJobToStart)
+}
+
+// CreateManualBuild creates a build dispatched by hand, with the parameters set as environment variables for all stages
+func (s *service) CreateManualBuild(ctx context.Context, build contracts.Build, parameters map[string]string) (createdBuild *contracts.Build, err error) {
+	return s.createBuild(ctx, build, parameters, false)
+}
+
+func (s *service) createBuild(ctx context.Context, build contracts.Build, parameters map[string]string, waitForJobToStart bool) (createdBuild *contracts.Build, err error) {
+
+	if err = validateParameterValues(parameters); err != nil {
+		return nil, err
+	}
 
 	// validate manifest
 	mft, manifestError := manifest.ReadManifest(s.config.ManifestPreferences, build.Manifest, true)
 	hasValidManifest := manifestError == nil
 
+	// check the manifest against the lint rules before injecting steps; findings of error rules block the build
+	blockingLintFindings := []api.LintFinding{}
+	if hasValidManifest {
+		blockingLintFindings = s.getBlockingLintFindings(build, mft)
+	}
+	passesLintRules := len(blockingLintFindings) == 0
+
 	// if manifest is invalid get the pipeline in order to use same labels, release targets and triggers as before
 	var pipeline *contracts.Pipeline
 	if !hasValidManifest {
@@ -98,7 +134,7 @@ func (s *service) CreateBuild(ctx context.Context, build contracts.Build, waitFo
 
 	// set build status
 	buildStatus := "failed"
-	if hasValidManifest {
+	if hasValidManifest && passesLintRules {
 		buildStatus = "pending"
 	}
 
@@ -112,6 +148,16 @@ func (s *service) CreateBuild(ctx context.Context, build contracts.Build, waitFo
 		}
 	}
 
+	// expose parameters as global environment variables
+	if hasValidManifest && len(parameters) > 0 {
+		if mft.GlobalEnvVars == nil {
+			mft.GlobalEnvVars = map[string]string{}
+		}
+		for name, value := range parameters {
+			mft.GlobalEnvVars[name] = value
+		}
+	}
+
 	autoincrement, build, err := s.getBuildAutoIncrement(ctx, build, shortRepoSource, hasValidManifest, mft, pipeline)
 	if err != nil {
 		return nil, err
@@ -121,6 +167,13 @@ func (s *service) CreateBuild(ctx context.Context, build contracts.Build, waitFo
 	build.ReleaseTargets = s.getBuildReleaseTargets(build, hasValidManifest, mft, pipeline)
 	build.Triggers = s.getBuildTriggers(build, hasValidManifest, mft, pipeline)
 
+	// the owner of the catalog entity the pipeline is linked to gets access to the build and is exposed to notification stages
+	ownership := s.getPipelineOwnership(ctx, build.RepoSource, build.RepoOwner, build.RepoName)
+	build.Groups, build.Organizations = ownership.ApplyOwner(build.Groups, build.Organizations)
+	if hasValidManifest {
+		mft = setOwnershipEnvironmentVariables(mft, ownership)
+	}
+
 	// get authenticated url
 	authenticatedRepositoryURL, environmentVariableWithToken, err := s.getAuthenticatedRepositoryURL(ctx, build.RepoSource, build.RepoOwner, build.RepoName)
 	if err != nil {
@@ -159,6 +212,14 @@ func (s *service) CreateBuild(ctx context.Context, build contracts.Build, waitFo
 		return
 	}
 
+	// store the parameters with the build, since they only end up in the job's environment otherwise
+	if len(parameters) > 0 {
+		err = s.cockroachdbClient.UpdateBuildParameters(ctx, createdBuild.RepoSource, createdBuild.RepoOwner, createdBuild.RepoName, buildID, parameters)
+		if err != nil {
+			return
+		}
+	}
+
 	// define ci builder params
 	ciBuilderParams := builderapi.CiBuilderParams{
 		JobType:              "build",
@@ -175,12 +236,14 @@ func (s *service) CreateBuild(ctx context.Context, build contracts.Build, waitFo
 		VersionNumber:        build.BuildVersion,
 		Manifest:             mft,
 		BuildID:              buildID,
-		TriggeredByEvents:    build.Events,
+		TriggeredByEvents:    getDirectTriggerEvents(build.Events),
 		JobResources:         jobResources,
+		Organizations:        build.Organizations,
+		Groups:               build.Groups,
 	}
 
 	// create ci builder job
-	if hasValidManifest {
+	if hasValidManifest && passesLintRules {
 		log.Debug().Msgf("Pipeline %v/%v/%v revision %v has valid manifest, creating build job...", build.RepoSource, build.RepoOwner, build.RepoName, build.RepoRevision)
 		// create ci builder job
 		if waitForJobToStart {
@@ -197,56 +260,130 @@ func (s *service) CreateBuild(ctx context.Context, build contracts.Build, waitFo
 			}(ciBuilderParams)
 		}
 
-		// handle triggers
-		go func() {
+		// handle triggers in the background, after the request is done
+		go func(ctx context.Context) {
 			err := s.FirePipelineTriggers(ctx, build, "started")
 			if err != nil {
 				log.Error().Err(err).Msgf("Failed firing pipeline triggers for build %v/%v/%v revision %v", build.RepoSource, build.RepoOwner, build.RepoName, build.RepoRevision)
 			}
-		}()
+		}(api.DetachContext(ctx))
 	} else if manifestError != nil {
 		log.Debug().Msgf("Pipeline %v/%v/%v revision %v with build id %v has invalid manifest, storing log...", build.RepoSource, build.RepoOwner, build.RepoName, build.RepoRevision, build.ID)
 		// store log with manifest unmarshalling error
-		buildLog := contracts.BuildLog{
-			BuildID:      createdBuild.ID,
-			RepoSource:   createdBuild.RepoSource,
-			RepoOwner:    createdBuild.RepoOwner,
-			RepoName:     createdBuild.RepoName,
-			RepoBranch:   createdBuild.RepoBranch,
-			RepoRevision: createdBuild.RepoRevision,
-			Steps: []*contracts.BuildLogStep{
-				&contracts.BuildLogStep{
-					Step:         "validate-manifest",
-					ExitCode:     1,
-					Status:       "FAILED",
-					AutoInjected: true,
-					RunIndex:     0,
-					LogLines: []contracts.BuildLogLine{
-						contracts.BuildLogLine{
-							LineNumber: 1,
-							Timestamp:  time.Now().UTC(),
-							StreamType: "stderr",
-							Text:       manifestError.Error(),
-						},
-					},
-				},
-			},
+		s.insertFailedBuildStepLog(ctx, createdBuild, "validate-manifest", []string{manifestError.Error()})
+	} else {
+		log.Debug().Msgf("Pipeline %v/%v/%v revision %v with build id %v violates lint rules, storing log...", build.RepoSource, build.RepoOwner, build.RepoName, build.RepoRevision, build.ID)
+		// store log with the findings blocking the build
+		lines := []string{}
+		for _, f := range blockingLintFindings {
+			lines = append(lines, fmt.Sprintf("[%v] %v", f.RuleID, f.Message))
 		}
+		s.insertFailedBuildStepLog(ctx, createdBuild, "lint-manifest", lines)
+	}
 
-		insertedBuildLog, err := s.cockroachdbClient.InsertBuildLog(ctx, buildLog, s.config.APIServer.WriteLogToDatabase())
-		if err != nil {
-			log.Warn().Err(err).Msgf("Failed inserting build log for invalid manifest")
+	return
+}
+
+// getPipelineOwnership returns the ownership of the catalog entity the pipeline is linked to, or nil if it isn't linked or the catalog can't be read
+func (s *service) getPipelineOwnership(ctx context.Context, repoSource, repoOwner, repoName string) *api.CatalogEntityOwnership {
+
+	ownership, err := api.GetPipelineOwnership(repoSource, repoOwner, repoName, func(filters map[api.FilterType][]string) (*contracts.CatalogEntity, error) {
+		catalogEntities, err := s.cockroachdbClient.GetCatalogEntities(ctx, 1, 1, filters, []api.OrderField{})
+		if err != nil || len(catalogEntities) == 0 {
+			return nil, err
 		}
+		return catalogEntities[0], nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed retrieving catalog ownership for pipeline %v/%v/%v", repoSource, repoOwner, repoName)
+		return nil
+	}
 
-		if s.config.APIServer.WriteLogToCloudStorage() {
-			err = s.cloudStorageClient.InsertBuildLog(ctx, insertedBuildLog)
-			if err != nil {
-				log.Warn().Err(err).Msgf("Failed inserting build log into cloud storage for invalid manifest")
-			}
+	return ownership
+}
+
+// setOwnershipEnvironmentVariables exposes the ownership as global environment variables, without overriding the ones set in the manifest
+func setOwnershipEnvironmentVariables(mft manifest.EstafetteManifest, ownership *api.CatalogEntityOwnership) manifest.EstafetteManifest {
+
+	envvars := ownership.GetEnvironmentVariables()
+	if len(envvars) == 0 {
+		return mft
+	}
+
+	if mft.GlobalEnvVars == nil {
+		mft.GlobalEnvVars = map[string]string{}
+	}
+	for name, value := range envvars {
+		if _, ok := mft.GlobalEnvVars[name]; !ok {
+			mft.GlobalEnvVars[name] = value
 		}
 	}
 
-	return
+	return mft
+}
+
+// getBlockingLintFindings returns the findings of lint rules at error level; failing to lint doesn't block the build
+func (s *service) getBlockingLintFindings(build contracts.Build, mft manifest.EstafetteManifest) []api.LintFinding {
+	if s.warningHelper == nil {
+		return []api.LintFinding{}
+	}
+
+	findings, err := s.warningHelper.Lint(api.LintInput{
+		Manifest:      &mft,
+		FullRepoPath:  build.GetFullRepoPath(),
+		Organizations: build.Organizations,
+	})
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed linting manifest for pipeline %v/%v/%v revision %v", build.RepoSource, build.RepoOwner, build.RepoName, build.RepoRevision)
+		return []api.LintFinding{}
+	}
+
+	return api.GetBlockingLintFindings(findings)
+}
+
+// insertFailedBuildStepLog stores the log for a build that failed before a job got created, with a single failed step
+func (s *service) insertFailedBuildStepLog(ctx context.Context, build *contracts.Build, step string, lines []string) {
+
+	logLines := []contracts.BuildLogLine{}
+	for i, l := range lines {
+		logLines = append(logLines, contracts.BuildLogLine{
+			LineNumber: i + 1,
+			Timestamp:  time.Now().UTC(),
+			StreamType: "stderr",
+			Text:       l,
+		})
+	}
+
+	buildLog := contracts.BuildLog{
+		BuildID:      build.ID,
+		RepoSource:   build.RepoSource,
+		RepoOwner:    build.RepoOwner,
+		RepoName:     build.RepoName,
+		RepoBranch:   build.RepoBranch,
+		RepoRevision: build.RepoRevision,
+		Steps: []*contracts.BuildLogStep{
+			&contracts.BuildLogStep{
+				Step:         step,
+				ExitCode:     1,
+				Status:       "FAILED",
+				AutoInjected: true,
+				RunIndex:     0,
+				LogLines:     logLines,
+			},
+		},
+	}
+
+	insertedBuildLog, err := s.cockroachdbClient.InsertBuildLog(ctx, buildLog, s.config.APIServer.WriteLogToDatabase())
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed inserting build log for %v step", step)
+	}
+
+	if s.config.APIServer.WriteLogToCloudStorage() {
+		err = s.cloudStorageClient.InsertBuildLog(ctx, insertedBuildLog)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed inserting build log into cloud storage for %v step", step)
+		}
+	}
 }
 
 func (s *service) FinishBuild(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) error {
@@ -256,8 +393,8 @@ func (s *service) FinishBuild(ctx context.Context, repoSource, repoOwner, repoNa
 		return err
 	}
 
-	// handle triggers
-	go func() {
+	// handle triggers in the background, after the request is done; firings are paced, so they can take longer than the request
+	go func(ctx context.Context) {
 		build, err := s.cockroachdbClient.GetPipelineBuildByID(ctx, repoSource, repoOwner, repoName, buildID, false)
 		if err != nil {
 			return
@@ -268,7 +405,7 @@ func (s *service) FinishBuild(ctx context.Context, repoSource, repoOwner, repoNa
 				log.Error().Err(err).Msgf("Failed firing pipeline triggers for build %v/%v/%v id %v", repoSource, repoOwner, repoName, buildID)
 			}
 		}
-	}()
+	}(api.DetachContext(ctx))
 
 	return nil
 }
@@ -307,6 +444,11 @@ func (s *service) CreateRelease(ctx context.Context, release contracts.Release,
 	// get autoincrement from release version
 	autoincrement := s.getReleaseAutoIncrement(ctx, release, mft)
 
+	// the owner of the catalog entity the pipeline is linked to gets access to the release and is exposed to notification stages
+	ownership := s.getPipelineOwnership(ctx, release.RepoSource, release.RepoOwner, release.RepoName)
+	release.Groups, release.Organizations = ownership.ApplyOwner(release.Groups, release.Organizations)
+	mft = setOwnershipEnvironmentVariables(mft, ownership)
+
 	// get authenticated url
 	authenticatedRepositoryURL, environmentVariableWithToken, err := s.getAuthenticatedRepositoryURL(ctx, release.RepoSource, release.RepoOwner, release.RepoName)
 	if err != nil {
@@ -343,7 +485,7 @@ func (s *service) CreateRelease(ctx context.Context, release contracts.Release,
 	// get triggered by from events
 	triggeredBy := ""
 	if len(release.Events) > 0 {
-		for _, e := range release.Events {
+		for _, e := range getDirectTriggerEvents(release.Events) {
 			if e.Manual != nil {
 				triggeredBy = e.Manual.UserID
 			}
@@ -369,8 +511,10 @@ func (s *service) CreateRelease(ctx context.Context, release contracts.Release,
 		ReleaseName:          release.Name,
 		ReleaseAction:        release.Action,
 		ReleaseTriggeredBy:   triggeredBy,
-		TriggeredByEvents:    release.Events,
+		TriggeredByEvents:    getDirectTriggerEvents(release.Events),
 		JobResources:         jobResources,
+		Organizations:        release.Organizations,
+		Groups:               release.Groups,
 	}
 
 	// create ci release job
@@ -388,13 +532,13 @@ func (s *service) CreateRelease(ctx context.Context, release contracts.Release,
 		}(ciBuilderParams)
 	}
 
-	// handle triggers
-	go func() {
+	// handle triggers in the background, after the request is done
+	go func(ctx context.Context) {
 		err := s.FireReleaseTriggers(ctx, release, "started")
 		if err != nil {
 			log.Error().Err(err).Msgf("Failed firing release triggers for %v/%v/%v to target %v", release.RepoSource, release.RepoOwner, release.RepoName, release.Name)
 		}
-	}()
+	}(api.DetachContext(ctx))
 
 	return
 }
@@ -405,8 +549,8 @@ func (s *service) FinishRelease(ctx context.Context, repoSource, repoOwner, repo
 		return err
 	}
 
-	// handle triggers
-	go func() {
+	// handle triggers in the background, after the request is done; firings are paced, so they can take longer than the request
+	go func(ctx context.Context) {
 		release, err := s.cockroachdbClient.GetPipelineRelease(ctx, repoSource, repoOwner, repoName, releaseID)
 		if err != nil {
 			return
@@ -417,7 +561,7 @@ func (s *service) FinishRelease(ctx context.Context, repoSource, repoOwner, repo
 				log.Error().Err(err).Msgf("Failed firing release triggers for %v/%v/%v id %v", repoSource, repoOwner, repoName, releaseID)
 			}
 		}
-	}()
+	}(api.DetachContext(ctx))
 
 	return nil
 }
@@ -458,13 +602,13 @@ func (s *service) FireGitTriggers(ctx context.Context, gitEvent manifest.Estafet
 				// create new build for t.Run
 				if t.BuildAction != nil {
 					log.Info().Msgf("[trigger:git(%v-%v:%v)] Firing build action '%v/%v/%v', branch '%v'...", gitEvent.Repository, gitEvent.Branch, gitEvent.Event, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
-					err := s.fireBuild(ctx, *p, t, e)
+					err := s.fireBuild(ctx, *p, t, []manifest.EstafetteEvent{e}, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:git(%v-%v:%v)] Failed starting build action'%v/%v/%v', branch '%v'", gitEvent.Repository, gitEvent.Branch, gitEvent.Event, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
 					}
 				} else if t.ReleaseAction != nil {
 					log.Info().Msgf("[trigger:git(%v-%v:%v)] Firing release action '%v/%v/%v', target '%v', action '%v'...", gitEvent.Repository, gitEvent.Branch, gitEvent.Event, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
-					err := s.fireRelease(ctx, *p, t, e)
+					err := s.fireRelease(ctx, *p, t, []manifest.EstafetteEvent{e}, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:git(%v-%v:%v)] Failed starting release action '%v/%v/%v', target '%v', action '%v'", gitEvent.Repository, gitEvent.Branch, gitEvent.Event, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
 					}
@@ -502,6 +646,10 @@ func (s *service) FirePipelineTriggers(ctx context.Context, build contracts.Buil
 		Pipeline: &pe,
 	}
 
+	// carry the events that led to this build into the builds and releases it triggers
+	events := append([]manifest.EstafetteEvent{e}, build.Events...)
+	limiter := newFanOutLimiter(s.config.Triggers)
+
 	triggerCount := 0
 	firedTriggerCount := 0
 
@@ -520,17 +668,18 @@ func (s *service) FirePipelineTriggers(ctx context.Context, build contracts.Buil
 			if t.Pipeline.Fires(&pe) {
 
 				firedTriggerCount++
+				limiter.wait()
 
 				// create new build for t.Run
 				if t.BuildAction != nil {
 					log.Info().Msgf("[trigger:pipeline(%v/%v/%v:%v)] Firing build action '%v/%v/%v', branch '%v'...", build.RepoSource, build.RepoOwner, build.RepoName, event, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
-					err := s.fireBuild(ctx, *p, t, e)
+					err := s.fireBuild(ctx, *p, t, events, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:pipeline(%v/%v/%v:%v)] Failed starting build action'%v/%v/%v', branch '%v'", build.RepoSource, build.RepoOwner, build.RepoName, event, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
 					}
 				} else if t.ReleaseAction != nil {
 					log.Info().Msgf("[trigger:pipeline(%v/%v/%v:%v)] Firing release action '%v/%v/%v', target '%v', action '%v'...", build.RepoSource, build.RepoOwner, build.RepoName, event, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
-					err := s.fireRelease(ctx, *p, t, e)
+					err := s.fireRelease(ctx, *p, t, events, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:pipeline(%v/%v/%v:%v)] Failed starting release action '%v/%v/%v', target '%v', action '%v'", build.RepoSource, build.RepoOwner, build.RepoName, event, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
 					}
@@ -567,6 +716,10 @@ func (s *service) FireReleaseTriggers(ctx context.Context, release contracts.Rel
 		Release: &re,
 	}
 
+	// carry the events that led to this release into the builds and releases it triggers
+	events := append([]manifest.EstafetteEvent{e}, release.Events...)
+	limiter := newFanOutLimiter(s.config.Triggers)
+
 	triggerCount := 0
 	firedTriggerCount := 0
 
@@ -585,16 +738,17 @@ func (s *service) FireReleaseTriggers(ctx context.Context, release contracts.Rel
 			if t.Release.Fires(&re) {
 
 				firedTriggerCount++
+				limiter.wait()
 
 				if t.BuildAction != nil {
 					log.Info().Msgf("[trigger:release(%v/%v/%v-%v:%v)] Firing build action '%v/%v/%v', branch '%v'...", release.RepoSource, release.RepoOwner, release.RepoName, release.Name, event, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
-					err := s.fireBuild(ctx, *p, t, e)
+					err := s.fireBuild(ctx, *p, t, events, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:release(%v/%v/%v-%v:%v)] Failed starting build action '%v/%v/%v', branch '%v'", release.RepoSource, release.RepoOwner, release.RepoName, release.Name, event, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
 					}
 				} else if t.ReleaseAction != nil {
 					log.Info().Msgf("[trigger:release(%v/%v/%v-%v:%v)] Firing release action '%v/%v/%v', target '%v', action '%v'...", release.RepoSource, release.RepoOwner, release.RepoName, release.Name, event, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
-					err := s.fireRelease(ctx, *p, t, e)
+					err := s.fireRelease(ctx, *p, t, events, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:release(%v/%v/%v-%v:%v)] Failed starting release action '%v/%v/%v', target '%v', action '%v'", release.RepoSource, release.RepoOwner, release.RepoName, release.Name, event, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
 					}
@@ -644,13 +798,13 @@ func (s *service) FirePubSubTriggers(ctx context.Context, pubsubEvent manifest.E
 				// create new build for t.Run
 				if t.BuildAction != nil {
 					log.Info().Msgf("[trigger:pubsub(projects/%v/topics/%v)] Firing build action '%v/%v/%v', branch '%v'...", pubsubEvent.Project, pubsubEvent.Topic, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
-					err := s.fireBuild(ctx, *p, t, e)
+					err := s.fireBuild(ctx, *p, t, []manifest.EstafetteEvent{e}, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:pubsub(projects/%v/topics/%v)] Failed starting build action'%v/%v/%v', branch '%v'", pubsubEvent.Project, pubsubEvent.Topic, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
 					}
 				} else if t.ReleaseAction != nil {
 					log.Info().Msgf("[trigger:pubsub(projects/%v/topics/%v)] Firing release action '%v/%v/%v', target '%v', action '%v'...", pubsubEvent.Project, pubsubEvent.Topic, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
-					err := s.fireRelease(ctx, *p, t, e)
+					err := s.fireRelease(ctx, *p, t, []manifest.EstafetteEvent{e}, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:pubsub(projects/%v/topics/%v)] Failed starting release action '%v/%v/%v', target '%v', action '%v'", pubsubEvent.Project, pubsubEvent.Topic, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
 					}
@@ -703,13 +857,13 @@ func (s *service) FireCronTriggers(ctx context.Context) error {
 				// create new build for t.Run
 				if t.BuildAction != nil {
 					log.Info().Msgf("[trigger:cron(%v)] Firing build action '%v/%v/%v', branch '%v'...", ce.Time, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
-					err := s.fireBuild(ctx, *p, t, e)
+					err := s.fireBuild(ctx, *p, t, []manifest.EstafetteEvent{e}, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:cron(%v)] Failed starting build action'%v/%v/%v', branch '%v'", ce.Time, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
 					}
 				} else if t.ReleaseAction != nil {
 					log.Info().Msgf("[trigger:cron(%v)] Firing release action '%v/%v/%v', target '%v', action '%v'...", ce.Time, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
-					err := s.fireRelease(ctx, *p, t, e)
+					err := s.fireRelease(ctx, *p, t, []manifest.EstafetteEvent{e}, nil)
 					if err != nil {
 						log.Error().Err(err).Msgf("[trigger:cron(%v)] Failed starting release action '%v/%v/%v', target '%v', action '%v'", ce.Time, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
 					}
@@ -723,11 +877,319 @@ func (s *service) FireCronTriggers(ctx context.Context) error {
 	return nil
 }
 
-func (s *service) fireBuild(ctx context.Context, p contracts.Pipeline, t manifest.EstafetteTrigger, e manifest.EstafetteEvent) error {
+func (s *service) FireDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) error {
+
+	log.Info().Msgf("[trigger:docker(%v:%v)] Checking if triggers need to be fired...", dockerEvent.Image, dockerEvent.Tag)
+
+	pipelines, err := s.cockroachdbClient.GetDockerTriggers(ctx, dockerEvent)
+	if err != nil {
+		return err
+	}
+
+	e := manifest.EstafetteEvent{
+		Docker: &dockerEvent,
+	}
+	variables := getDockerEventVariables(dockerEvent)
+
+	limiter := newFanOutLimiter(s.config.Triggers)
+
+	firedTriggerCount := 0
+
+	// check for each pipeline which triggers fire, including the ones implied by stages using the image
+	for _, p := range pipelines {
+		for _, t := range getDockerTriggers(*p, dockerEvent) {
+
+			firedTriggerCount++
+			limiter.wait()
+
+			// create new build for t.Run
+			if t.BuildAction != nil {
+				log.Info().Msgf("[trigger:docker(%v:%v)] Firing build action '%v/%v/%v', branch '%v'...", dockerEvent.Image, dockerEvent.Tag, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
+				err := s.fireBuild(ctx, *p, t, []manifest.EstafetteEvent{e}, variables)
+				if err != nil {
+					log.Error().Err(err).Msgf("[trigger:docker(%v:%v)] Failed starting build action '%v/%v/%v', branch '%v'", dockerEvent.Image, dockerEvent.Tag, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
+				}
+			} else if t.ReleaseAction != nil {
+				log.Info().Msgf("[trigger:docker(%v:%v)] Firing release action '%v/%v/%v', target '%v', action '%v'...", dockerEvent.Image, dockerEvent.Tag, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
+				err := s.fireRelease(ctx, *p, t, []manifest.EstafetteEvent{e}, variables)
+				if err != nil {
+					log.Error().Err(err).Msgf("[trigger:docker(%v:%v)] Failed starting release action '%v/%v/%v', target '%v', action '%v'", dockerEvent.Image, dockerEvent.Tag, p.RepoSource, p.RepoOwner, p.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
+				}
+			}
+		}
+	}
+
+	log.Info().Msgf("[trigger:docker(%v:%v)] Fired %v triggers for %v pipelines", dockerEvent.Image, dockerEvent.Tag, firedTriggerCount, len(pipelines))
+
+	return nil
+}
+
+// FireWebhookTriggers fires the build or release action of a webhook called by an external system, with the webhook's payload exposed as variables
+func (s *service) FireWebhookTriggers(ctx context.Context, webhook api.Webhook, variables map[string]string) error {
+
+	log.Info().Msgf("[trigger:webhook(%v)] Firing webhook for pipeline '%v/%v/%v'...", webhook.Name, webhook.RepoSource, webhook.RepoOwner, webhook.RepoName)
+
+	pipeline, err := s.cockroachdbClient.GetPipeline(ctx, webhook.RepoSource, webhook.RepoOwner, webhook.RepoName, map[api.FilterType][]string{}, false)
+	if err != nil {
+		return err
+	}
+	if pipeline == nil {
+		return fmt.Errorf("Pipeline '%v/%v/%v' for webhook %v can't be found", webhook.RepoSource, webhook.RepoOwner, webhook.RepoName, webhook.Name)
+	}
+
+	t := webhook.GetTrigger()
+	e := webhook.GetEvent()
+
+	if t.BuildAction != nil {
+		log.Info().Msgf("[trigger:webhook(%v)] Firing build action '%v/%v/%v', branch '%v'...", webhook.Name, pipeline.RepoSource, pipeline.RepoOwner, pipeline.RepoName, t.BuildAction.Branch)
+		return s.fireBuild(ctx, *pipeline, t, []manifest.EstafetteEvent{e}, variables)
+	} else if t.ReleaseAction != nil {
+		log.Info().Msgf("[trigger:webhook(%v)] Firing release action '%v/%v/%v', target '%v', action '%v'...", webhook.Name, pipeline.RepoSource, pipeline.RepoOwner, pipeline.RepoName, t.ReleaseAction.Target, t.ReleaseAction.Action)
+		return s.fireRelease(ctx, *pipeline, t, []manifest.EstafetteEvent{e}, variables)
+	}
+
+	return fmt.Errorf("Webhook %v has no 'builds' or 'releases' action to fire", webhook.Name)
+}
+
+// SimulateTriggers returns the pipeline actions the triggers would fire for an event, without firing them
+func (s *service) SimulateTriggers(ctx context.Context, e manifest.EstafetteEvent) (firings []*api.TriggerFiring, err error) {
+
+	var pipelines []*contracts.Pipeline
+	switch {
+	case e.Git != nil:
+		pipelines, err = s.cockroachdbClient.GetGitTriggers(ctx, *e.Git)
+	case e.Pipeline != nil:
+		pipelines, err = s.cockroachdbClient.GetPipelineTriggers(ctx, contracts.Build{RepoSource: e.Pipeline.RepoSource, RepoOwner: e.Pipeline.RepoOwner, RepoName: e.Pipeline.RepoName}, e.Pipeline.Event)
+	case e.Release != nil:
+		pipelines, err = s.cockroachdbClient.GetReleaseTriggers(ctx, contracts.Release{RepoSource: e.Release.RepoSource, RepoOwner: e.Release.RepoOwner, RepoName: e.Release.RepoName}, e.Release.Event)
+	case e.PubSub != nil:
+		pipelines, err = s.cockroachdbClient.GetPubSubTriggers(ctx, *e.PubSub)
+	case e.Cron != nil:
+		pipelines, err = s.cockroachdbClient.GetCronTriggers(ctx)
+	case e.Docker != nil:
+		pipelines, err = s.cockroachdbClient.GetDockerTriggers(ctx, *e.Docker)
+	default:
+		return nil, ErrUnsupportedEvent
+	}
+	if err != nil {
+		return
+	}
+
+	firings = make([]*api.TriggerFiring, 0)
+	for _, p := range pipelines {
+		triggers := p.Triggers
+		if e.Docker != nil {
+			triggers = getDockerTriggers(*p, *e.Docker)
+		}
+
+		for _, t := range triggers {
+			if !triggerFires(t, e) {
+				continue
+			}
+
+			firing := &api.TriggerFiring{
+				RepoSource: p.RepoSource,
+				RepoOwner:  p.RepoOwner,
+				RepoName:   p.RepoName,
+				Trigger:    t,
+			}
+			if t.BuildAction != nil {
+				firing.Action = "build"
+			} else if t.ReleaseAction != nil {
+				firing.Action = "release"
+			} else {
+				continue
+			}
+
+			firings = append(firings, firing)
+		}
+	}
+
+	return firings, nil
+}
+
+// triggerFires applies the same checks as the Fire...Triggers functions to find out whether a trigger fires for an event
+func triggerFires(t manifest.EstafetteTrigger, e manifest.EstafetteEvent) bool {
+	switch {
+	case e.Git != nil:
+		return t.Git != nil && t.Git.Fires(e.Git)
+	case e.Pipeline != nil:
+		return t.Pipeline != nil && t.Pipeline.Fires(e.Pipeline)
+	case e.Release != nil:
+		return t.Release != nil && t.Release.Fires(e.Release)
+	case e.PubSub != nil:
+		return t.PubSub != nil && t.PubSub.Fires(e.PubSub)
+	case e.Cron != nil:
+		return t.Cron != nil && t.Cron.Fires(e.Cron)
+	case e.Docker != nil:
+		return t.Docker != nil && api.DockerTriggerFires(t.Docker, e.Docker)
+	}
+
+	return false
+}
+
+// getDockerTriggers returns the pipeline's docker triggers firing for the event; a pipeline without any of those still builds its most recent branch if a build stage or service uses the pushed image and tag
+func getDockerTriggers(p contracts.Pipeline, dockerEvent manifest.EstafetteDockerEvent) []manifest.EstafetteTrigger {
+
+	triggers := []manifest.EstafetteTrigger{}
+	for _, t := range p.Triggers {
+		if t.Docker != nil && api.DockerTriggerFires(t.Docker, &dockerEvent) {
+			triggers = append(triggers, t)
+		}
+	}
+	if len(triggers) > 0 || p.ManifestObject == nil {
+		return triggers
+	}
+
+	pushed := api.GetDockerEventImageReference(dockerEvent)
+	if api.ManifestReferencesImage(*p.ManifestObject, pushed) {
+		triggers = append(triggers, manifest.EstafetteTrigger{
+			Docker: &manifest.EstafetteDockerTrigger{
+				Event: api.DockerEventPush,
+				Image: pushed.Name(),
+				Tag:   pushed.Tag,
+			},
+			BuildAction: &manifest.EstafetteTriggerBuildAction{
+				Branch: p.RepoBranch,
+			},
+		})
+	}
+
+	return triggers
+}
+
+// getDockerEventVariables exposes the pushed image to the triggered build or release
+func getDockerEventVariables(dockerEvent manifest.EstafetteDockerEvent) map[string]string {
+	pushed := api.GetDockerEventImageReference(dockerEvent)
+
+	return map[string]string{
+		"ESTAFETTE_DOCKER_IMAGE":  pushed.Name(),
+		"ESTAFETTE_DOCKER_TAG":    pushed.Tag,
+		"ESTAFETTE_DOCKER_DIGEST": pushed.Digest,
+	}
+}
+
+// GetTriggerGraph returns the pipelines and other event sources upstream and downstream of a pipeline, following triggers up to the maximum chain depth
+func (s *service) GetTriggerGraph(ctx context.Context, pipeline contracts.Pipeline) (graph *api.TriggerGraph, err error) {
+
+	graph = api.NewTriggerGraph()
+	graph.AddNode(&api.TriggerGraphNode{ID: strings.ToLower(pipeline.GetFullRepoPath()), Type: "pipeline", Name: pipeline.GetFullRepoPath()})
+
+	maxDepth := s.config.Triggers.GetMaxChainDepth()
+
+	err = s.addUpstreamTriggers(ctx, graph, pipeline, map[string]bool{}, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addDownstreamTriggers(ctx, graph, pipeline, map[string]bool{}, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	return graph, nil
+}
+
+// addUpstreamTriggers adds the event sources of the pipeline's triggers to the graph, and the event sources of upstream pipelines after that
+func (s *service) addUpstreamTriggers(ctx context.Context, graph *api.TriggerGraph, pipeline contracts.Pipeline, visited map[string]bool, depth int) error {
+
+	id := strings.ToLower(pipeline.GetFullRepoPath())
+	visited[id] = true
+
+	for _, t := range pipeline.Triggers {
+		if t.BuildAction == nil && t.ReleaseAction == nil {
+			continue
+		}
+		source := api.GetTriggerSourceNode(t)
+		if source == nil {
+			continue
+		}
+
+		graph.AddNode(source)
+		graph.AddEdge(&api.TriggerGraphEdge{From: source.ID, To: id, Trigger: t})
+
+		if source.Type != "pipeline" || visited[source.ID] || depth <= 1 {
+			continue
+		}
+
+		nameParts := strings.Split(source.Name, "/")
+		if len(nameParts) != 3 {
+			continue
+		}
+		upstreamPipeline, err := s.cockroachdbClient.GetPipeline(ctx, nameParts[0], nameParts[1], nameParts[2], map[api.FilterType][]string{}, false)
+		if err != nil {
+			return err
+		}
+		if upstreamPipeline == nil {
+			// trigger refers to a pipeline that doesn't exist (anymore)
+			visited[source.ID] = true
+			continue
+		}
+
+		err = s.addUpstreamTriggers(ctx, graph, *upstreamPipeline, visited, depth-1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addDownstreamTriggers adds the pipelines with triggers firing for builds or releases of the pipeline to the graph, and their downstream pipelines after that
+func (s *service) addDownstreamTriggers(ctx context.Context, graph *api.TriggerGraph, pipeline contracts.Pipeline, visited map[string]bool, depth int) error {
+
+	id := strings.ToLower(pipeline.GetFullRepoPath())
+	visited[id] = true
+
+	pipelineTriggered, err := s.cockroachdbClient.GetTriggers(ctx, "pipeline", pipeline.GetFullRepoPath(), "")
+	if err != nil {
+		return err
+	}
+	releaseTriggered, err := s.cockroachdbClient.GetTriggers(ctx, "release", pipeline.GetFullRepoPath(), "")
+	if err != nil {
+		return err
+	}
+
+	for _, p := range append(pipelineTriggered, releaseTriggered...) {
+		downstreamID := strings.ToLower(p.GetFullRepoPath())
+
+		for _, t := range p.Triggers {
+			if t.BuildAction == nil && t.ReleaseAction == nil {
+				continue
+			}
+			source := api.GetTriggerSourceNode(t)
+			if source == nil || source.ID != id {
+				continue
+			}
+
+			graph.AddNode(&api.TriggerGraphNode{ID: downstreamID, Type: "pipeline", Name: p.GetFullRepoPath()})
+			graph.AddEdge(&api.TriggerGraphEdge{From: id, To: downstreamID, Trigger: t})
+		}
+
+		if visited[downstreamID] || depth <= 1 {
+			continue
+		}
+
+		err = s.addDownstreamTriggers(ctx, graph, *p, visited, depth-1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fireBuild starts a build for the trigger; events holds the event that fired the trigger, followed by the events of the build or release causing it
+func (s *service) fireBuild(ctx context.Context, p contracts.Pipeline, t manifest.EstafetteTrigger, events []manifest.EstafetteEvent, parameters map[string]string) error {
 	if t.BuildAction == nil {
 		return fmt.Errorf("Trigger to fire does not have a 'builds' property, shouldn't get to here")
 	}
 
+	err := s.checkTriggerChain(ctx, p, t, events)
+	if err != nil {
+		return err
+	}
+
 	// get last build for branch defined in 'builds' section
 	lastBuildForBranch, err := s.cockroachdbClient.GetLastPipelineBuildForBranch(ctx, p.RepoSource, p.RepoOwner, p.RepoName, t.BuildAction.Branch)
 
@@ -738,21 +1200,29 @@ func (s *service) fireBuild(ctx context.Context, p contracts.Pipeline, t manifes
 	// empty the build version so a new one gets created
 	lastBuildForBranch.BuildVersion = ""
 
-	// set event that triggers the build
-	lastBuildForBranch.Events = []manifest.EstafetteEvent{e}
+	// set events that trigger the build
+	lastBuildForBranch.Events = events
 
-	_, err = s.CreateBuild(ctx, *lastBuildForBranch, true)
+	_, err = s.createBuild(ctx, *lastBuildForBranch, parameters, true)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (s *service) fireRelease(ctx context.Context, p contracts.Pipeline, t manifest.EstafetteTrigger, e manifest.EstafetteEvent) error {
+// fireRelease starts a release for the trigger; events holds the event that fired the trigger, followed by the events of the build or release causing it
+func (s *service) fireRelease(ctx context.Context, p contracts.Pipeline, t manifest.EstafetteTrigger, events []manifest.EstafetteEvent, parameters map[string]string) error {
 	if t.ReleaseAction == nil {
 		return fmt.Errorf("Trigger to fire does not have a 'releases' property, shouldn't get to here")
 	}
 
+	err := s.checkTriggerChain(ctx, p, t, events)
+	if err != nil {
+		return err
+	}
+
+	e := events[0]
+
 	// determine version to release
 	versionToRelease := p.BuildVersion
 
@@ -805,21 +1275,152 @@ func (s *service) fireRelease(ctx context.Context, p contracts.Pipeline, t manif
 		repoRevision = succeededBuilds[0].RepoRevision
 	}
 
-	_, err := s.CreateRelease(ctx, contracts.Release{
+	if err := validateParameterValues(parameters); err != nil {
+		return err
+	}
+
+	// expose parameters as global environment variables, without altering the pipeline's manifest
+	mft := *p.ManifestObject
+	if len(parameters) > 0 {
+		globalEnvVars := map[string]string{}
+		for name, value := range mft.GlobalEnvVars {
+			globalEnvVars[name] = value
+		}
+		for name, value := range parameters {
+			globalEnvVars[name] = value
+		}
+		mft.GlobalEnvVars = globalEnvVars
+	}
+
+	_, err = s.CreateRelease(ctx, contracts.Release{
 		Name:           t.ReleaseAction.Target,
 		Action:         t.ReleaseAction.Action,
 		RepoSource:     p.RepoSource,
 		RepoOwner:      p.RepoOwner,
 		RepoName:       p.RepoName,
 		ReleaseVersion: versionToRelease,
-		Events:         []manifest.EstafetteEvent{e},
-	}, *p.ManifestObject, repoBranch, repoRevision, true)
+		Events:         events,
+	}, mft, repoBranch, repoRevision, true)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// checkTriggerChain refuses to fire a trigger if the chain of builds and releases triggering each other gets too long or loops back; the refusal is recorded as a warning on the pipeline
+func (s *service) checkTriggerChain(ctx context.Context, p contracts.Pipeline, t manifest.EstafetteTrigger, events []manifest.EstafetteEvent) error {
+
+	chainID := getTriggerChainID(events)
+	chainDepth := getTriggerChainDepth(events)
+	maxChainDepth := s.config.Triggers.GetMaxChainDepth()
+
+	var refusal error
+	var message string
+	switch {
+	case chainDepth > maxChainDepth:
+		refusal = ErrTriggerChainTooLong
+		message = fmt.Sprintf("A trigger of this pipeline did not fire, because it's preceded by a chain of **%v** builds and releases triggering each other (chain %v), exceeding the maximum of %v.", chainDepth, chainID, maxChainDepth)
+	case triggerChainContains(p, t, events):
+		refusal = ErrTriggerLoop
+		message = fmt.Sprintf("A trigger of this pipeline did not fire, because the build or release it starts already caused the trigger (chain %v). Please make sure pipelines don't trigger each other in a loop.", chainID)
+	default:
+		return nil
+	}
+
+	log.Warn().Msgf("Refusing to fire trigger for pipeline %v/%v/%v in chain %v: %v", p.RepoSource, p.RepoOwner, p.RepoName, chainID, refusal)
+
+	err := s.cockroachdbClient.InsertPipelineWarning(ctx, p.RepoSource, p.RepoOwner, p.RepoName, contracts.Warning{
+		Status:  "warning",
+		Message: message,
+	})
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed recording trigger warning for pipeline %v/%v/%v", p.RepoSource, p.RepoOwner, p.RepoName)
+	}
+
+	return refusal
+}
+
+// getTriggerChainDepth returns the number of builds and releases that led to a build or release
+func getTriggerChainDepth(events []manifest.EstafetteEvent) (depth int) {
+	for _, e := range events {
+		if e.Pipeline != nil || e.Release != nil {
+			depth++
+		}
+	}
+
+	return
+}
+
+// getTriggerChainID identifies a chain of builds and releases triggering each other by the build or release that started it
+func getTriggerChainID(events []manifest.EstafetteEvent) string {
+
+	// events are ordered from most recent to the original cause
+	for i := len(events) - 1; i >= 0; i-- {
+		var origin string
+		switch {
+		case events[i].Pipeline != nil:
+			origin = fmt.Sprintf("pipeline:%v/%v/%v:%v:%v", events[i].Pipeline.RepoSource, events[i].Pipeline.RepoOwner, events[i].Pipeline.RepoName, events[i].Pipeline.Branch, events[i].Pipeline.BuildVersion)
+		case events[i].Release != nil:
+			origin = fmt.Sprintf("release:%v/%v/%v:%v:%v", events[i].Release.RepoSource, events[i].Release.RepoOwner, events[i].Release.RepoName, events[i].Release.Target, events[i].Release.ReleaseVersion)
+		default:
+			continue
+		}
+
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(origin)))[:12]
+	}
+
+	return ""
+}
+
+// triggerChainContains returns true if the build or release a trigger starts already happened earlier in the chain
+func triggerChainContains(p contracts.Pipeline, t manifest.EstafetteTrigger, events []manifest.EstafetteEvent) bool {
+	for _, e := range events {
+		switch {
+		case t.BuildAction != nil && e.Pipeline != nil:
+			if e.Pipeline.RepoSource == p.RepoSource && e.Pipeline.RepoOwner == p.RepoOwner && e.Pipeline.RepoName == p.RepoName && e.Pipeline.Branch == t.BuildAction.Branch {
+				return true
+			}
+		case t.ReleaseAction != nil && e.Release != nil:
+			if e.Release.RepoSource == p.RepoSource && e.Release.RepoOwner == p.RepoOwner && e.Release.RepoName == p.RepoName && e.Release.Target == t.ReleaseAction.Target {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getDirectTriggerEvents returns the event that directly triggered a build or release, leaving out the events of the chain preceding it
+func getDirectTriggerEvents(events []manifest.EstafetteEvent) []manifest.EstafetteEvent {
+	if len(events) > 1 {
+		return events[:1]
+	}
+
+	return events
+}
+
+// fanOutLimiter paces the builds and releases fired by a single trigger event, so a popular pipeline doesn't start all its dependents at once
+type fanOutLimiter struct {
+	burst    int
+	interval time.Duration
+	fired    int
+}
+
+func newFanOutLimiter(config *api.TriggersConfig) *fanOutLimiter {
+	return &fanOutLimiter{
+		burst:    config.GetFanOutBurst(),
+		interval: config.GetFanOutInterval(),
+	}
+}
+
+// wait returns immediately until the burst is used up and blocks for the interval after that
+func (l *fanOutLimiter) wait() {
+	if l.fired >= l.burst {
+		time.Sleep(l.interval)
+	}
+	l.fired++
+}
+
 func (s *service) getShortRepoSource(repoSource string) string {
 
 	repoSourceArray := strings.Split(repoSource, ".")
@@ -865,6 +1466,108 @@ func (s *service) getAuthenticatedRepositoryURL(ctx context.Context, repoSource,
 	return authenticatedRepositoryURL, environmentVariableWithToken, fmt.Errorf("Source %v not supported for generating authenticated repository url", repoSource)
 }
 
+// GetDoraMetrics returns the DORA metrics for deployments to the production release targets in the window set by filter[since] and filter[until], grouped by pipeline, group, organization or label:<key>
+func (s *service) GetDoraMetrics(ctx context.Context, groupBy string, filters map[api.FilterType][]string) (metrics []*api.DoraMetrics, err error) {
+
+	if err = api.ValidateGroupBy(groupBy); err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	var since, until time.Time
+	if values, ok := filters[api.FilterSince]; ok && len(values) > 0 {
+		if since, err = api.ParseTimeFilter(values[0], now); err != nil {
+			return
+		}
+	}
+	if values, ok := filters[api.FilterUntil]; ok && len(values) > 0 {
+		if until, err = api.ParseTimeFilter(values[0], now); err != nil {
+			return
+		}
+	}
+
+	deployments, err := s.cockroachdbClient.GetDoraDeployments(ctx, s.config.Dora.GetProductionReleaseTargets(), filters)
+	if err != nil {
+		return
+	}
+
+	return api.ComputeDoraMetrics(deployments, groupBy, since, until), nil
+}
+
+// ExportDoraMetrics sets the prometheus gauges for the DORA metrics over the default window, per pipeline, group, organization and configured label key
+func (s *service) ExportDoraMetrics(ctx context.Context) (err error) {
+
+	since, err := api.ParseTimeFilter(s.config.Dora.GetDefaultWindow(), time.Now().UTC())
+	if err != nil {
+		return
+	}
+
+	filters := map[api.FilterType][]string{
+		api.FilterSince: {s.config.Dora.GetDefaultWindow()},
+	}
+
+	deployments, err := s.cockroachdbClient.GetDoraDeployments(ctx, s.config.Dora.GetProductionReleaseTargets(), filters)
+	if err != nil {
+		return
+	}
+
+	deploymentFrequencyGauge := api.NewDoraGauge("deployment_frequency_per_day", "Number of succeeded production deployments per day.")
+	leadTimeGauge := api.NewDoraGauge("lead_time_for_changes_seconds", "Median time from building a version to deploying it to production in seconds.")
+	changeFailureRateGauge := api.NewDoraGauge("change_failure_rate", "Fraction of production deployments that failed.")
+	timeToRestoreGauge := api.NewDoraGauge("time_to_restore_seconds", "Median time from a failed production deployment to the next succeeded one in seconds.")
+
+	groupBys := []string{api.GroupByPipeline, api.GroupByGroup, api.GroupByOrganization}
+	for _, labelKey := range s.config.Dora.ExportLabelKeys {
+		groupBys = append(groupBys, api.GroupByLabelPrefix+labelKey)
+	}
+
+	for _, groupBy := range groupBys {
+		for _, m := range api.ComputeDoraMetrics(deployments, groupBy, since, time.Time{}) {
+			deploymentFrequencyGauge.With("group_by", groupBy, "key", m.Key).Set(m.DeploymentFrequency)
+			leadTimeGauge.With("group_by", groupBy, "key", m.Key).Set(m.LeadTimeForChanges.Seconds())
+			changeFailureRateGauge.With("group_by", groupBy, "key", m.Key).Set(m.ChangeFailureRate)
+			timeToRestoreGauge.With("group_by", groupBy, "key", m.Key).Set(m.TimeToRestore.Seconds())
+		}
+	}
+
+	return nil
+}
+
+// GetFlakyPipelines returns the flakiness of all pipelines built in the window set by filter[since] and filter[until], flakiest first
+func (s *service) GetFlakyPipelines(ctx context.Context, filters map[api.FilterType][]string) (reports []*api.FlakinessReport, err error) {
+
+	attempts, err := s.cockroachdbClient.GetBuildAttempts(ctx, filters)
+	if err != nil {
+		return
+	}
+
+	return api.ComputeFlakinessReports(attempts), nil
+}
+
+// GetPipelineFlakiness returns the flakiness of a pipeline and its stages, reading the stage outcomes from the build logs of retried revisions
+func (s *service) GetPipelineFlakiness(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (report *api.FlakinessReport, err error) {
+
+	attempts, err := s.cockroachdbClient.GetPipelineBuildAttempts(ctx, repoSource, repoOwner, repoName, filters)
+	if err != nil {
+		return
+	}
+
+	stageStatuses, err := s.cockroachdbClient.GetBuildStageStatuses(ctx, api.GetRetriedBuildIDs(attempts))
+	if err != nil {
+		return
+	}
+	for _, a := range attempts {
+		a.StageStatuses = stageStatuses[a.ID]
+	}
+
+	reports := api.ComputeFlakinessReports(attempts)
+	if len(reports) == 0 {
+		return &api.FlakinessReport{RepoSource: repoSource, RepoOwner: repoOwner, RepoName: repoName}, nil
+	}
+
+	return reports[0], nil
+}
+
 func (s *service) Rename(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) error {
 
 	nrOfGoroutines := 1
@@ -1267,3 +1970,14 @@ func (s *service) supportsBuildStatus(repoSource string) bool {
 
 	return false
 }
+
+// validateParameterValues returns ErrSecretInParameters if a parameter carries a secret envelope; parameters become global environment variables of the job, so a secret copied from another pipeline's manifest would otherwise be decrypted in this pipeline's scope
+func validateParameterValues(parameters map[string]string) error {
+	for name, value := range parameters {
+		if api.ContainsSecretEnvelope(value) {
+			return fmt.Errorf("%w: parameter %v", ErrSecretInParameters, name)
+		}
+	}
+
+	return nil
+}
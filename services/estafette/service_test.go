@@ -2,7 +2,9 @@ package estafette
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/bitbucketapi"
@@ -43,7 +45,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -82,7 +84,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -120,7 +122,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -159,7 +161,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -203,7 +205,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -253,7 +255,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -306,7 +308,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -324,6 +326,75 @@ func TestCreateBuild(t *testing.T) {
 		assert.Equal(t, 1, insertbuildlogCallCount)
 	})
 
+	t.Run("CallsInsertBuildLogOnCockroachdbClientInsteadOfCreateCiBuilderJobOnBuilderapiClientIfManifestViolatesErrorLintRule", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		enabled := true
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+			Linting: &api.LintingConfig{
+				Rules: []*api.LintRuleConfig{
+					{
+						ID:      "allowed-image-registries",
+						Enabled: &enabled,
+						Parameters: map[string]interface{}{
+							"registries": []interface{}{"eu.gcr.io/my-project"},
+						},
+					},
+				},
+			},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		insertedBuildStatus := ""
+		cockroachdbClient.InsertBuildFunc = func(ctx context.Context, build contracts.Build, jobResources cockroachdb.JobResources) (b *contracts.Build, err error) {
+			insertedBuildStatus = build.BuildStatus
+			b = &build
+			b.ID = "5"
+			return
+		}
+		createcibuilderjobCallCount := 0
+		builderapiClient.CreateCiBuilderJobFunc = func(ctx context.Context, params builderapi.CiBuilderParams) (job *batchv1.Job, err error) {
+			createcibuilderjobCallCount++
+			return
+		}
+
+		var insertedBuildLog contracts.BuildLog
+		cockroachdbClient.InsertBuildLogFunc = func(ctx context.Context, buildLog contracts.BuildLog, writeLogToDatabase bool) (log contracts.BuildLog, err error) {
+			insertedBuildLog = buildLog
+			return
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		build := contracts.Build{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "estafette-ci-api",
+			RepoBranch: "master",
+			Manifest:   "stages:\n  build:\n    image: golang:1.14.2-alpine3.11",
+		}
+
+		// act
+		_, err := service.CreateBuild(context.Background(), build, true)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "failed", insertedBuildStatus)
+		assert.Equal(t, 0, createcibuilderjobCallCount)
+		if assert.Equal(t, 1, len(insertedBuildLog.Steps)) {
+			assert.Equal(t, "lint-manifest", insertedBuildLog.Steps[0].Step)
+			assert.Equal(t, 1, len(insertedBuildLog.Steps[0].LogLines))
+		}
+	})
+
 	t.Run("CallsInsertBuildOnCockroachdbClientWithBuildVersionGeneratedFromAutoincrement", func(t *testing.T) {
 
 		ctx := context.Background()
@@ -352,7 +423,7 @@ func TestCreateBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		build := contracts.Build{
 			RepoSource: "github.com",
@@ -395,7 +466,7 @@ func TestFinishBuild(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		repoSource := "github.com"
 		repoOwner := "estafette"
@@ -411,6 +482,602 @@ func TestFinishBuild(t *testing.T) {
 	})
 }
 
+func TestFirePipelineTriggers(t *testing.T) {
+
+	t.Run("RefusesBuildActionForPipelineThatStartedTheChain", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		cockroachdbClient.GetPipelineTriggersFunc = func(ctx context.Context, build contracts.Build, event string) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-a",
+					Triggers: []manifest.EstafetteTrigger{
+						{
+							Pipeline: &manifest.EstafettePipelineTrigger{
+								Name:   "github.com/estafette/repo-b",
+								Event:  "finished",
+								Status: "succeeded",
+								Branch: "master",
+							},
+							BuildAction: &manifest.EstafetteTriggerBuildAction{
+								Branch: "master",
+							},
+						},
+					},
+				},
+			}, nil
+		}
+
+		getLastBuildCallCount := 0
+		cockroachdbClient.GetLastPipelineBuildForBranchFunc = func(ctx context.Context, repoSource, repoOwner, repoName, branch string) (build *contracts.Build, err error) {
+			getLastBuildCallCount++
+			return
+		}
+
+		var recordedWarnings []contracts.Warning
+		cockroachdbClient.InsertPipelineWarningFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+			recordedWarnings = append(recordedWarnings, warning)
+			return
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		// build of repo-b was triggered by a build of repo-a
+		build := contracts.Build{
+			RepoSource:   "github.com",
+			RepoOwner:    "estafette",
+			RepoName:     "repo-b",
+			RepoBranch:   "master",
+			BuildVersion: "1.0.3",
+			BuildStatus:  "succeeded",
+			Events: []manifest.EstafetteEvent{
+				{
+					Pipeline: &manifest.EstafettePipelineEvent{
+						RepoSource:   "github.com",
+						RepoOwner:    "estafette",
+						RepoName:     "repo-a",
+						Branch:       "master",
+						BuildVersion: "2.4.0",
+						Status:       "succeeded",
+						Event:        "finished",
+					},
+				},
+			},
+		}
+
+		// act
+		err := service.FirePipelineTriggers(ctx, build, "finished")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, getLastBuildCallCount)
+		if assert.Equal(t, 1, len(recordedWarnings)) {
+			assert.Equal(t, "warning", recordedWarnings[0].Status)
+			assert.Contains(t, recordedWarnings[0].Message, getTriggerChainID(build.Events))
+		}
+	})
+
+	t.Run("RefusesBuildActionExceedingMaxChainDepth", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+			Triggers: &api.TriggersConfig{
+				MaxChainDepth: 2,
+			},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		cockroachdbClient.GetPipelineTriggersFunc = func(ctx context.Context, build contracts.Build, event string) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-d",
+					Triggers: []manifest.EstafetteTrigger{
+						{
+							Pipeline: &manifest.EstafettePipelineTrigger{
+								Name:   "github.com/estafette/repo-c",
+								Event:  "finished",
+								Status: "succeeded",
+								Branch: "master",
+							},
+							BuildAction: &manifest.EstafetteTriggerBuildAction{
+								Branch: "master",
+							},
+						},
+					},
+				},
+			}, nil
+		}
+
+		getLastBuildCallCount := 0
+		cockroachdbClient.GetLastPipelineBuildForBranchFunc = func(ctx context.Context, repoSource, repoOwner, repoName, branch string) (build *contracts.Build, err error) {
+			getLastBuildCallCount++
+			return
+		}
+
+		insertWarningCallCount := 0
+		cockroachdbClient.InsertPipelineWarningFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+			insertWarningCallCount++
+			return
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		// build of repo-c was triggered by a build of repo-b, which was triggered by a build of repo-a
+		build := contracts.Build{
+			RepoSource:   "github.com",
+			RepoOwner:    "estafette",
+			RepoName:     "repo-c",
+			RepoBranch:   "master",
+			BuildVersion: "1.0.3",
+			BuildStatus:  "succeeded",
+			Events: []manifest.EstafetteEvent{
+				{
+					Pipeline: &manifest.EstafettePipelineEvent{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-b", Branch: "master", BuildVersion: "0.1.7", Status: "succeeded", Event: "finished"},
+				},
+				{
+					Pipeline: &manifest.EstafettePipelineEvent{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", Branch: "master", BuildVersion: "2.4.0", Status: "succeeded", Event: "finished"},
+				},
+			},
+		}
+
+		// act
+		err := service.FirePipelineTriggers(ctx, build, "finished")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, getLastBuildCallCount)
+		assert.Equal(t, 1, insertWarningCallCount)
+	})
+
+	t.Run("CarriesEventsOfTriggeringBuildIntoFiredBuild", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		cockroachdbClient.GetPipelineTriggersFunc = func(ctx context.Context, build contracts.Build, event string) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-c",
+					Triggers: []manifest.EstafetteTrigger{
+						{
+							Pipeline: &manifest.EstafettePipelineTrigger{
+								Name:   "github.com/estafette/repo-b",
+								Event:  "finished",
+								Status: "succeeded",
+								Branch: "master",
+							},
+							BuildAction: &manifest.EstafetteTriggerBuildAction{
+								Branch: "master",
+							},
+						},
+					},
+				},
+			}, nil
+		}
+
+		cockroachdbClient.GetLastPipelineBuildForBranchFunc = func(ctx context.Context, repoSource, repoOwner, repoName, branch string) (build *contracts.Build, err error) {
+			return &contracts.Build{
+				RepoSource:   repoSource,
+				RepoOwner:    repoOwner,
+				RepoName:     repoName,
+				RepoBranch:   branch,
+				BuildVersion: "0.0.12",
+				Manifest:     "builder:\n  track: dev\nstages:\n  stage-1:\n    image: extensions/doesnothing:dev",
+			}, nil
+		}
+
+		var insertedEvents []manifest.EstafetteEvent
+		cockroachdbClient.InsertBuildFunc = func(ctx context.Context, build contracts.Build, jobResources cockroachdb.JobResources) (b *contracts.Build, err error) {
+			insertedEvents = build.Events
+			return
+		}
+
+		insertWarningCallCount := 0
+		cockroachdbClient.InsertPipelineWarningFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+			insertWarningCallCount++
+			return
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		build := contracts.Build{
+			RepoSource:   "github.com",
+			RepoOwner:    "estafette",
+			RepoName:     "repo-b",
+			RepoBranch:   "master",
+			BuildVersion: "1.0.3",
+			BuildStatus:  "succeeded",
+			Events: []manifest.EstafetteEvent{
+				{
+					Pipeline: &manifest.EstafettePipelineEvent{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", Branch: "master", BuildVersion: "2.4.0", Status: "succeeded", Event: "finished"},
+				},
+			},
+		}
+
+		// act
+		err := service.FirePipelineTriggers(ctx, build, "finished")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, insertWarningCallCount)
+		if assert.Equal(t, 2, len(insertedEvents)) {
+			assert.Equal(t, "repo-b", insertedEvents[0].Pipeline.RepoName)
+			assert.Equal(t, "repo-a", insertedEvents[1].Pipeline.RepoName)
+		}
+	})
+}
+
+func TestGetTriggerChainID(t *testing.T) {
+
+	t.Run("ReturnsSameIDForEventsOfTheSameChain", func(t *testing.T) {
+
+		origin := manifest.EstafetteEvent{
+			Pipeline: &manifest.EstafettePipelineEvent{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", Branch: "master", BuildVersion: "2.4.0", Status: "succeeded", Event: "finished"},
+		}
+		next := manifest.EstafetteEvent{
+			Release: &manifest.EstafetteReleaseEvent{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-b", Target: "production", ReleaseVersion: "1.0.3", Status: "succeeded", Event: "finished"},
+		}
+
+		// act
+		chainID := getTriggerChainID([]manifest.EstafetteEvent{origin})
+
+		assert.Equal(t, 12, len(chainID))
+		assert.Equal(t, chainID, getTriggerChainID([]manifest.EstafetteEvent{next, origin}))
+	})
+
+	t.Run("ReturnsEmptyIDForEventsWithoutBuildOrRelease", func(t *testing.T) {
+
+		// act
+		chainID := getTriggerChainID([]manifest.EstafetteEvent{{Manual: &manifest.EstafetteManualEvent{UserID: "me@estafette.io"}}})
+
+		assert.Equal(t, "", chainID)
+	})
+}
+
+func TestFanOutLimiter(t *testing.T) {
+
+	t.Run("DelaysFiringOnceBurstIsUsedUp", func(t *testing.T) {
+
+		limiter := newFanOutLimiter(&api.TriggersConfig{FanOutBurst: 2, FanOutIntervalSeconds: 1})
+		limiter.interval = 20 * time.Millisecond
+
+		start := time.Now()
+		limiter.wait()
+		limiter.wait()
+		burstDuration := time.Since(start)
+
+		// act
+		limiter.wait()
+
+		assert.True(t, burstDuration < 20*time.Millisecond)
+		assert.True(t, time.Since(start) >= 20*time.Millisecond)
+	})
+}
+
+func TestSimulateTriggers(t *testing.T) {
+
+	t.Run("ReturnsFiringTriggersWithoutCreatingBuilds", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		cockroachdbClient.GetPipelineTriggersFunc = func(ctx context.Context, build contracts.Build, event string) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-b",
+					Triggers: []manifest.EstafetteTrigger{
+						{
+							Pipeline:    &manifest.EstafettePipelineTrigger{Name: "github.com/estafette/repo-a", Event: "finished", Status: "succeeded", Branch: "master"},
+							BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"},
+						},
+						{
+							Pipeline:      &manifest.EstafettePipelineTrigger{Name: "github.com/estafette/repo-a", Event: "finished", Status: "failed", Branch: "master"},
+							ReleaseAction: &manifest.EstafetteTriggerReleaseAction{Target: "development"},
+						},
+					},
+				},
+			}, nil
+		}
+
+		insertBuildCallCount := 0
+		cockroachdbClient.InsertBuildFunc = func(ctx context.Context, build contracts.Build, jobResources cockroachdb.JobResources) (b *contracts.Build, err error) {
+			insertBuildCallCount++
+			return
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		event := manifest.EstafetteEvent{
+			Pipeline: &manifest.EstafettePipelineEvent{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", Branch: "master", BuildVersion: "2.4.0", Status: "succeeded", Event: "finished"},
+		}
+
+		// act
+		firings, err := service.SimulateTriggers(ctx, event)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, insertBuildCallCount)
+		if assert.Equal(t, 1, len(firings)) {
+			assert.Equal(t, "repo-b", firings[0].RepoName)
+			assert.Equal(t, "build", firings[0].Action)
+		}
+	})
+
+	t.Run("ReturnsErrorForManualEvent", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		// act
+		_, err := service.SimulateTriggers(ctx, manifest.EstafetteEvent{Manual: &manifest.EstafetteManualEvent{UserID: "me@estafette.io"}})
+
+		assert.True(t, errors.Is(err, ErrUnsupportedEvent))
+	})
+}
+
+func TestGetTriggerGraph(t *testing.T) {
+
+	t.Run("ReturnsUpstreamAndDownstreamPipelines", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		// repo-a triggers repo-b, which triggers repo-c
+		pipelineA := &contracts.Pipeline{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a"}
+		pipelineB := &contracts.Pipeline{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "repo-b",
+			Triggers: []manifest.EstafetteTrigger{
+				{
+					Pipeline:    &manifest.EstafettePipelineTrigger{Name: "github.com/estafette/repo-a", Event: "finished", Status: "succeeded", Branch: "master"},
+					BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"},
+				},
+				{
+					Cron:        &manifest.EstafetteCronTrigger{Schedule: "0 1 * * *"},
+					BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"},
+				},
+			},
+		}
+		pipelineC := &contracts.Pipeline{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "repo-c",
+			Triggers: []manifest.EstafetteTrigger{
+				{
+					Release:       &manifest.EstafetteReleaseTrigger{Name: "github.com/estafette/repo-b", Target: "production", Event: "finished", Status: "succeeded"},
+					ReleaseAction: &manifest.EstafetteTriggerReleaseAction{Target: "production"},
+				},
+			},
+		}
+
+		cockroachdbClient.GetPipelineFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string, optimized bool) (pipeline *contracts.Pipeline, err error) {
+			if repoName == "repo-a" {
+				return pipelineA, nil
+			}
+			return
+		}
+		cockroachdbClient.GetTriggersFunc = func(ctx context.Context, triggerType, identifier, event string) (pipelines []*contracts.Pipeline, err error) {
+			if triggerType == "release" && identifier == "github.com/estafette/repo-b" {
+				return []*contracts.Pipeline{pipelineC}, nil
+			}
+			return
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		// act
+		graph, err := service.GetTriggerGraph(ctx, *pipelineB)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(graph.Nodes))
+		assert.True(t, graph.HasNode("github.com/estafette/repo-a"))
+		assert.True(t, graph.HasNode("github.com/estafette/repo-c"))
+		assert.True(t, graph.HasNode("cron:0 1 * * *"))
+		if assert.Equal(t, 3, len(graph.Edges)) {
+			assert.Equal(t, "github.com/estafette/repo-a", graph.Edges[0].From)
+			assert.Equal(t, "github.com/estafette/repo-b", graph.Edges[0].To)
+			assert.Equal(t, "github.com/estafette/repo-b", graph.Edges[2].From)
+			assert.Equal(t, "github.com/estafette/repo-c", graph.Edges[2].To)
+		}
+	})
+}
+
+func TestGetDoraMetrics(t *testing.T) {
+
+	t.Run("ReturnsMetricsForDeploymentsToProductionReleaseTargets", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+			Dora: &api.DoraConfig{
+				ProductionReleaseTargets: []string{"production", "production-eu"},
+			},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		builtAt := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+		var requestedReleaseTargets []string
+		cockroachdbClient.GetDoraDeploymentsFunc = func(ctx context.Context, releaseTargets []string, filters map[api.FilterType][]string) (deployments []*api.DoraDeployment, err error) {
+			requestedReleaseTargets = releaseTargets
+			return []*api.DoraDeployment{
+				{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "repo-a", ReleaseTarget: "production", ReleaseStatus: "succeeded", UpdatedAt: builtAt.Add(2 * time.Hour), BuildInsertedAt: &builtAt},
+			}, nil
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		// act
+		metrics, err := service.GetDoraMetrics(ctx, api.GroupByPipeline, map[api.FilterType][]string{
+			api.FilterSince: {"2020-05-01"},
+			api.FilterUntil: {"2020-05-03"},
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"production", "production-eu"}, requestedReleaseTargets)
+		if assert.Equal(t, 1, len(metrics)) {
+			assert.Equal(t, "github.com/estafette/repo-a", metrics[0].Key)
+			assert.Equal(t, 1, metrics[0].Deployments)
+			assert.Equal(t, 0.5, metrics[0].DeploymentFrequency)
+			assert.Equal(t, 2*time.Hour, metrics[0].LeadTimeForChanges)
+		}
+	})
+
+	t.Run("ReturnsErrInvalidGroupByForUnknownGroupBy", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		// act
+		_, err := service.GetDoraMetrics(ctx, "team", map[api.FilterType][]string{})
+
+		assert.True(t, errors.Is(err, api.ErrInvalidGroupBy))
+	})
+}
+
+func TestGetPipelineFlakiness(t *testing.T) {
+
+	t.Run("ReturnsStageFlakinessFromBuildLogsOfRetriedRevisions", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		cockroachdbClient := cockroachdb.MockClient{}
+		prometheusClient := prometheus.MockClient{}
+		cloudStorageClient := cloudstorage.MockClient{}
+		builderapiClient := builderapi.MockClient{}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		cockroachdbClient.GetPipelineBuildAttemptsFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (attempts []*api.BuildAttempt, err error) {
+			return []*api.BuildAttempt{
+				{ID: "1", RepoSource: repoSource, RepoOwner: repoOwner, RepoName: repoName, RepoRevision: "r1", BuildStatus: "failed"},
+				{ID: "2", RepoSource: repoSource, RepoOwner: repoOwner, RepoName: repoName, RepoRevision: "r1", BuildStatus: "succeeded"},
+				{ID: "3", RepoSource: repoSource, RepoOwner: repoOwner, RepoName: repoName, RepoRevision: "r2", BuildStatus: "succeeded"},
+			}, nil
+		}
+		var requestedBuildIDs []string
+		cockroachdbClient.GetBuildStageStatusesFunc = func(ctx context.Context, buildIDs []string) (stageStatuses map[string]map[string]string, err error) {
+			requestedBuildIDs = buildIDs
+			return map[string]map[string]string{
+				"1": {"test": contracts.StatusFailed},
+				"2": {"test": contracts.StatusSucceeded},
+			}, nil
+		}
+
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+
+		// act
+		report, err := service.GetPipelineFlakiness(ctx, "github.com", "estafette", "repo-a", map[api.FilterType][]string{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"1", "2"}, requestedBuildIDs)
+		assert.Equal(t, 1, report.Revisions)
+		assert.Equal(t, 1, report.FlakyRevisions)
+		if assert.Equal(t, 1, len(report.Stages)) {
+			assert.Equal(t, "test", report.Stages[0].Name)
+			assert.Equal(t, 1, report.Stages[0].FlakyRevisions)
+		}
+	})
+}
+
 func TestCreateRelease(t *testing.T) {
 
 	t.Run("CallsInsertBuildOnCockroachdbClient", func(t *testing.T) {
@@ -435,7 +1102,7 @@ func TestCreateRelease(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		release := contracts.Release{
 			RepoSource:     "github.com",
@@ -481,7 +1148,7 @@ func TestCreateRelease(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		release := contracts.Release{
 			RepoSource:     "github.com",
@@ -525,7 +1192,7 @@ func TestFinishRelease(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		repoSource := "github.com"
 		repoOwner := "estafette"
@@ -566,7 +1233,7 @@ func TestRename(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		// act
 		err := service.Rename(context.Background(), "github.com", "estafette", "estafette-ci-contracts", "github.com", "estafette", "estafette-ci-protos")
@@ -600,7 +1267,7 @@ func TestRename(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		// act
 		err := service.Rename(context.Background(), "github.com", "estafette", "estafette-ci-contracts", "github.com", "estafette", "estafette-ci-protos")
@@ -634,7 +1301,7 @@ func TestUpdateBuildStatus(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		event := builderapi.CiBuilderEvent{
 			BuildID:     "123456",
@@ -671,7 +1338,7 @@ func TestUpdateBuildStatus(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		event := builderapi.CiBuilderEvent{
 			ReleaseID:   "123456",
@@ -710,7 +1377,7 @@ func TestUpdateJobResources(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		event := builderapi.CiBuilderEvent{
 			PodName:     "build-estafette-estafette-ci-api-123456-mhrzk",
@@ -748,7 +1415,7 @@ func TestUpdateJobResources(t *testing.T) {
 			return
 		}
 
-		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+		service := NewService(config, cockroachdbClient, prometheusClient, cloudStorageClient, builderapiClient, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
 
 		event := builderapi.CiBuilderEvent{
 			PodName:     "release-estafette-estafette-ci-api-123456-mhrzk",
@@ -764,3 +1431,153 @@ func TestUpdateJobResources(t *testing.T) {
 	})
 
 }
+
+func TestFireWebhookTriggers(t *testing.T) {
+
+	getService := func(cockroachdbClient cockroachdb.MockClient) Service {
+		ctx := context.Background()
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		return NewService(config, cockroachdbClient, prometheus.MockClient{}, cloudstorage.MockClient{}, builderapi.MockClient{}, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+	}
+
+	webhook := api.Webhook{
+		RepoSource:  "github.com",
+		RepoOwner:   "estafette",
+		RepoName:    "repo-a",
+		Name:        "monitoring",
+		BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "release"},
+	}
+
+	t.Run("ReturnsErrorIfPipelineDoesNotExist", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{}
+		cockroachdbClient.GetPipelineFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string, optimized bool) (pipeline *contracts.Pipeline, err error) {
+			return nil, nil
+		}
+		service := getService(cockroachdbClient)
+
+		// act
+		err := service.FireWebhookTriggers(context.Background(), webhook, map[string]string{})
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("FiresBuildActionForWebhookBranch", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{}
+		cockroachdbClient.GetPipelineFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string, optimized bool) (pipeline *contracts.Pipeline, err error) {
+			return &contracts.Pipeline{RepoSource: repoSource, RepoOwner: repoOwner, RepoName: repoName}, nil
+		}
+		var requestedBranch string
+		cockroachdbClient.GetLastPipelineBuildForBranchFunc = func(ctx context.Context, repoSource, repoOwner, repoName, branch string) (build *contracts.Build, err error) {
+			requestedBranch = branch
+			return
+		}
+		service := getService(cockroachdbClient)
+
+		// act
+		err := service.FireWebhookTriggers(context.Background(), webhook, map[string]string{})
+
+		// no build exists for the branch, so it can't fire after looking it up
+		assert.NotNil(t, err)
+		assert.Equal(t, "release", requestedBranch)
+	})
+}
+
+func TestFireDockerTriggers(t *testing.T) {
+
+	getService := func(cockroachdbClient cockroachdb.MockClient) Service {
+		ctx := context.Background()
+		config := &api.APIConfig{
+			Jobs:      &api.JobsConfig{},
+			APIServer: &api.APIServerConfig{},
+		}
+		githubapiClient := githubapi.MockClient{}
+		bitbucketapiClient := bitbucketapi.MockClient{}
+		cloudsourceapiClient := cloudsourceapi.MockClient{}
+
+		return NewService(config, cockroachdbClient, prometheus.MockClient{}, cloudstorage.MockClient{}, builderapi.MockClient{}, api.NewWarningHelper(nil, config), githubapiClient.JobVarsFunc(ctx), bitbucketapiClient.JobVarsFunc(ctx), cloudsourceapiClient.JobVarsFunc(ctx))
+	}
+
+	t.Run("BuildsPipelinesUsingPushedImageInStages", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{}
+		cockroachdbClient.GetDockerTriggersFunc = func(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-a",
+					RepoBranch: "main",
+					ManifestObject: &manifest.EstafetteManifest{
+						Stages: []*manifest.EstafetteStage{{Name: "build", ContainerImage: "golang:1.14-alpine"}},
+					},
+				},
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-b",
+					RepoBranch: "main",
+					ManifestObject: &manifest.EstafetteManifest{
+						Stages: []*manifest.EstafetteStage{{Name: "build", ContainerImage: "golang:1.15-alpine"}},
+					},
+				},
+			}, nil
+		}
+		requestedBuilds := []string{}
+		cockroachdbClient.GetLastPipelineBuildForBranchFunc = func(ctx context.Context, repoSource, repoOwner, repoName, branch string) (build *contracts.Build, err error) {
+			requestedBuilds = append(requestedBuilds, repoName+"@"+branch)
+			return
+		}
+		service := getService(cockroachdbClient)
+
+		// act
+		err := service.FireDockerTriggers(context.Background(), api.NewDockerPushEvent("golang", "1.14-alpine", "sha256:abc"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"repo-a@main"}, requestedBuilds)
+	})
+
+	t.Run("PrefersExplicitDockerTriggerOverStageImage", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{}
+		cockroachdbClient.GetDockerTriggersFunc = func(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-a",
+					RepoBranch: "feature",
+					Triggers: []manifest.EstafetteTrigger{
+						{
+							Docker:      &manifest.EstafetteDockerTrigger{Image: "golang", Tag: "1.14-alpine"},
+							BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "main"},
+						},
+					},
+					ManifestObject: &manifest.EstafetteManifest{
+						Stages: []*manifest.EstafetteStage{{Name: "build", ContainerImage: "golang:1.14-alpine"}},
+					},
+				},
+			}, nil
+		}
+		requestedBuilds := []string{}
+		cockroachdbClient.GetLastPipelineBuildForBranchFunc = func(ctx context.Context, repoSource, repoOwner, repoName, branch string) (build *contracts.Build, err error) {
+			requestedBuilds = append(requestedBuilds, repoName+"@"+branch)
+			return
+		}
+		service := getService(cockroachdbClient)
+
+		// act
+		err := service.FireDockerTriggers(context.Background(), api.NewDockerPushEvent("golang", "1.14-alpine", "sha256:abc"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"repo-a@main"}, requestedBuilds)
+	})
+}
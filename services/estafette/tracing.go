@@ -27,6 +27,13 @@ func (s *tracingService) CreateBuild(ctx context.Context, build contracts.Build,
 	return s.Service.CreateBuild(ctx, build, waitForJobToStart)
 }
 
+func (s *tracingService) CreateManualBuild(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "CreateManualBuild"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.CreateManualBuild(ctx, build, parameters)
+}
+
 func (s *tracingService) FinishBuild(ctx context.Context, repoSource, repoOwner, repoName string, buildID int, buildStatus string) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "FinishBuild"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -83,6 +90,62 @@ func (s *tracingService) FireCronTriggers(ctx context.Context) (err error) {
 	return s.Service.FireCronTriggers(ctx)
 }
 
+func (s *tracingService) FireWebhookTriggers(ctx context.Context, webhook api.Webhook, variables map[string]string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "FireWebhookTriggers"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.FireWebhookTriggers(ctx, webhook, variables)
+}
+
+func (s *tracingService) FireDockerTriggers(ctx context.Context, dockerEvent manifest.EstafetteDockerEvent) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "FireDockerTriggers"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.FireDockerTriggers(ctx, dockerEvent)
+}
+
+func (s *tracingService) SimulateTriggers(ctx context.Context, e manifest.EstafetteEvent) (firings []*api.TriggerFiring, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "SimulateTriggers"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.SimulateTriggers(ctx, e)
+}
+
+func (s *tracingService) GetTriggerGraph(ctx context.Context, pipeline contracts.Pipeline) (graph *api.TriggerGraph, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetTriggerGraph"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetTriggerGraph(ctx, pipeline)
+}
+
+func (s *tracingService) GetDoraMetrics(ctx context.Context, groupBy string, filters map[api.FilterType][]string) (metrics []*api.DoraMetrics, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetDoraMetrics"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetDoraMetrics(ctx, groupBy, filters)
+}
+
+func (s *tracingService) ExportDoraMetrics(ctx context.Context) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "ExportDoraMetrics"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.ExportDoraMetrics(ctx)
+}
+
+func (s *tracingService) GetFlakyPipelines(ctx context.Context, filters map[api.FilterType][]string) (reports []*api.FlakinessReport, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetFlakyPipelines"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetFlakyPipelines(ctx, filters)
+}
+
+func (s *tracingService) GetPipelineFlakiness(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string) (report *api.FlakinessReport, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetPipelineFlakiness"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetPipelineFlakiness(ctx, repoSource, repoOwner, repoName, filters)
+}
+
 func (s *tracingService) Rename(ctx context.Context, fromRepoSource, fromRepoOwner, fromRepoName, toRepoSource, toRepoOwner, toRepoName string) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "Rename"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -21,9 +21,13 @@ import (
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/bitbucketapi"
 	"github.com/estafette/estafette-ci-api/clients/builderapi"
+	"github.com/estafette/estafette-ci-api/clients/cloudsourceapi"
 	"github.com/estafette/estafette-ci-api/clients/cloudstorage"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/clients/githubapi"
+	"github.com/estafette/estafette-ci-api/services/audit"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	crypt "github.com/estafette/estafette-ci-crypt"
 	manifest "github.com/estafette/estafette-ci-manifest"
@@ -33,37 +37,45 @@ import (
 )
 
 // NewHandler returns a new estafette.Handler
-func NewHandler(configFilePath string, config *api.APIConfig, encryptedConfig *api.APIConfig, cockroachDBClient cockroachdb.Client, cloudStorageClient cloudstorage.Client, ciBuilderClient builderapi.Client, buildService Service, warningHelper api.WarningHelper, secretHelper crypt.SecretHelper, githubJobVarsFunc func(context.Context, string, string, string) (string, string, error), bitbucketJobVarsFunc func(context.Context, string, string, string) (string, string, error), cloudsourceJobVarsFunc func(context.Context, string, string, string) (string, string, error)) Handler {
+func NewHandler(configFilePath string, config *api.APIConfig, encryptedConfig *api.APIConfig, cockroachDBClient cockroachdb.Client, cloudStorageClient cloudstorage.Client, ciBuilderClient builderapi.Client, buildService Service, auditService audit.Service, warningHelper api.WarningHelper, secretHelper crypt.SecretHelper, githubJobVarsFunc func(context.Context, string, string, string) (string, string, error), bitbucketJobVarsFunc func(context.Context, string, string, string) (string, string, error), cloudsourceJobVarsFunc func(context.Context, string, string, string) (string, string, error), githubManifestFunc func(context.Context, string, string, string, string, string) (string, bool, string, error), bitbucketManifestFunc func(context.Context, string, string, string, string, string) (string, bool, string, error), cloudsourceManifestFunc func(context.Context, string, string, string, string, string) (string, bool, string, error)) Handler {
 
 	return Handler{
-		configFilePath:         configFilePath,
-		config:                 config,
-		encryptedConfig:        encryptedConfig,
-		cockroachDBClient:      cockroachDBClient,
-		cloudStorageClient:     cloudStorageClient,
-		ciBuilderClient:        ciBuilderClient,
-		buildService:           buildService,
-		warningHelper:          warningHelper,
-		secretHelper:           secretHelper,
-		githubJobVarsFunc:      githubJobVarsFunc,
-		bitbucketJobVarsFunc:   bitbucketJobVarsFunc,
-		cloudsourceJobVarsFunc: cloudsourceJobVarsFunc,
+		configFilePath:          configFilePath,
+		config:                  config,
+		encryptedConfig:         encryptedConfig,
+		cockroachDBClient:       cockroachDBClient,
+		cloudStorageClient:      cloudStorageClient,
+		ciBuilderClient:         ciBuilderClient,
+		buildService:            buildService,
+		auditService:            auditService,
+		warningHelper:           warningHelper,
+		secretHelper:            secretHelper,
+		githubJobVarsFunc:       githubJobVarsFunc,
+		bitbucketJobVarsFunc:    bitbucketJobVarsFunc,
+		cloudsourceJobVarsFunc:  cloudsourceJobVarsFunc,
+		githubManifestFunc:      githubManifestFunc,
+		bitbucketManifestFunc:   bitbucketManifestFunc,
+		cloudsourceManifestFunc: cloudsourceManifestFunc,
 	}
 }
 
 type Handler struct {
-	configFilePath         string
-	config                 *api.APIConfig
-	encryptedConfig        *api.APIConfig
-	cockroachDBClient      cockroachdb.Client
-	cloudStorageClient     cloudstorage.Client
-	ciBuilderClient        builderapi.Client
-	buildService           Service
-	warningHelper          api.WarningHelper
-	secretHelper           crypt.SecretHelper
-	githubJobVarsFunc      func(context.Context, string, string, string) (string, string, error)
-	bitbucketJobVarsFunc   func(context.Context, string, string, string) (string, string, error)
-	cloudsourceJobVarsFunc func(context.Context, string, string, string) (string, string, error)
+	configFilePath          string
+	config                  *api.APIConfig
+	encryptedConfig         *api.APIConfig
+	cockroachDBClient       cockroachdb.Client
+	cloudStorageClient      cloudstorage.Client
+	ciBuilderClient         builderapi.Client
+	buildService            Service
+	auditService            audit.Service
+	warningHelper           api.WarningHelper
+	secretHelper            crypt.SecretHelper
+	githubJobVarsFunc       func(context.Context, string, string, string) (string, string, error)
+	bitbucketJobVarsFunc    func(context.Context, string, string, string) (string, string, error)
+	cloudsourceJobVarsFunc  func(context.Context, string, string, string) (string, string, error)
+	githubManifestFunc      func(context.Context, string, string, string, string, string) (string, bool, string, error)
+	bitbucketManifestFunc   func(context.Context, string, string, string, string, string) (string, bool, string, error)
+	cloudsourceManifestFunc func(context.Context, string, string, string, string, string) (string, bool, string, error)
 }
 
 func (h *Handler) GetPipelines(c *gin.Context) {
@@ -73,8 +85,8 @@ func (h *Handler) GetPipelines(c *gin.Context) {
 	// filter on organizations / groups
 	filters = api.SetPermissionsFilters(c, filters)
 
-	response, err := api.GetPagedListResponse(
-		func() ([]interface{}, error) {
+	response, err := api.GetListResponse(c, pageNumber, pageSize, filters,
+		func(pageSize int) ([]interface{}, error) {
 			pipelines, err := h.cockroachDBClient.GetPipelines(c.Request.Context(), pageNumber, pageSize, filters, sortings, true)
 			if err != nil {
 				return nil, err
@@ -91,8 +103,15 @@ func (h *Handler) GetPipelines(c *gin.Context) {
 		func() (int, error) {
 			return h.cockroachDBClient.GetPipelinesCount(c.Request.Context(), filters)
 		},
-		pageNumber,
-		pageSize)
+		func(item interface{}) string {
+			pipeline := item.(*contracts.Pipeline)
+			return api.EncodePageCursor(pipeline.RepoSource, pipeline.RepoOwner, pipeline.RepoName)
+		})
+
+	if errors.Is(err, api.ErrInvalidPageCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": "Invalid page[after] cursor"})
+		return
+	}
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed retrieving pipelines or count from db")
@@ -148,8 +167,8 @@ func (h *Handler) GetPipelineBuilds(c *gin.Context) {
 
 	pageNumber, pageSize, filters, sortings := api.GetQueryParameters(c)
 
-	response, err := api.GetPagedListResponse(
-		func() ([]interface{}, error) {
+	response, err := api.GetListResponse(c, pageNumber, pageSize, filters,
+		func(pageSize int) ([]interface{}, error) {
 			builds, err := h.cockroachDBClient.GetPipelineBuilds(c.Request.Context(), source, owner, repo, pageNumber, pageSize, filters, sortings, true)
 			if err != nil {
 				return nil, err
@@ -166,8 +185,15 @@ func (h *Handler) GetPipelineBuilds(c *gin.Context) {
 		func() (int, error) {
 			return h.cockroachDBClient.GetPipelineBuildsCount(c.Request.Context(), source, owner, repo, filters)
 		},
-		pageNumber,
-		pageSize)
+		func(item interface{}) string {
+			build := item.(*contracts.Build)
+			return api.EncodePageCursor(build.InsertedAt.Format(time.RFC3339Nano), build.ID)
+		})
+
+	if errors.Is(err, api.ErrInvalidPageCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": "Invalid page[after] cursor"})
+		return
+	}
 
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed retrieving builds for %v/%v/%v from db", source, owner, repo)
@@ -314,6 +340,11 @@ func (h *Handler) CreatePipelineBuild(c *gin.Context) {
 		return
 	}
 
+	if !h.requestHasPipelinePermission(c, api.PermissionBuildsRebuild, failedBuild.Organizations, failedBuild.Groups, failedBuild.Labels, "") {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
 	// set trigger event to manual
 	failedBuild.Events = []manifest.EstafetteEvent{
 		manifest.EstafetteEvent{
@@ -332,9 +363,183 @@ func (h *Handler) CreatePipelineBuild(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionBuildsRebuild, "build", fmt.Sprintf("%v/%v/%v/%v", createdBuild.RepoSource, createdBuild.RepoOwner, createdBuild.RepoName, createdBuild.ID), nil, createdBuild)
+
+	c.JSON(http.StatusCreated, createdBuild)
+}
+
+// DispatchPipelineBuild starts a build for the head of a branch or for a specific revision, without the need to push a commit
+func (h *Handler) DispatchPipelineBuild(c *gin.Context) {
+
+	if !api.RequestTokenIsValid(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "JWT is invalid"})
+		return
+	}
+
+	// clients don't have an email address, so fall back to their client id
+	claims := jwt.ExtractClaims(c)
+	requester, _ := claims["email"].(string)
+	if requester == "" {
+		requester, _ = claims["clientID"].(string)
+	}
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	var body struct {
+		Branch     string            `json:"branch"`
+		Revision   string            `json:"revision,omitempty"`
+		Parameters map[string]string `json:"parameters,omitempty"`
+	}
+
+	err := c.BindJSON(&body)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding DispatchPipelineBuild body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	if body.Branch == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": "Branch is required, revision is optional and defaults to the head of the branch"})
+		return
+	}
+
+	for name, value := range body.Parameters {
+		if !isValidBuildParameterName(name) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Parameter %v is not a valid environment variable name or uses the reserved ESTAFETTE_ prefix", name)})
+			return
+		}
+		if api.ContainsSecretEnvelope(value) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Parameter %v contains a secret, which isn't allowed", name)})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+
+	pipeline, err := h.cockroachDBClient.GetPipeline(ctx, source, owner, repo, map[api.FilterType][]string{}, false)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving pipeline %v/%v/%v for build dispatch issued by %v", source, owner, repo, requester)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
+		return
+	}
+
+	// dispatching injects parameters into the build's environment, so unlike other pipeline operations it always requires the permission, whether pipeline permissions are enforced or not
+	if !api.RequestTokenHasPermissionForResource(c, api.PermissionBuildsCreate, api.GetPermissionResourceForPipeline(pipeline.Organizations, pipeline.Groups, pipeline.Labels, "")) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	manifestFunc := h.getManifestFunc(source)
+	if manifestFunc == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Source %v is not supported for dispatching builds", source)})
+		return
+	}
+
+	revision, manifestExists, manifestString, err := manifestFunc(ctx, source, owner, repo, body.Branch, body.Revision)
+	if errors.Is(err, api.ErrRevisionNotOnBranch) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Revision %v is not on branch %v of pipeline %v/%v/%v", body.Revision, body.Branch, source, owner, repo)})
+		return
+	}
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving manifest for pipeline %v/%v/%v branch %v revision %v for build dispatch issued by %v", source, owner, repo, body.Branch, body.Revision, requester)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	if revision == "" {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": fmt.Sprintf("Branch %v or revision %v not found for pipeline %v/%v/%v", body.Branch, body.Revision, source, owner, repo)})
+		return
+	}
+	if !manifestExists {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Revision %v of pipeline %v/%v/%v has no manifest", revision, source, owner, repo)})
+		return
+	}
+
+	// hand off to build service
+	createdBuild, err := h.buildService.CreateManualBuild(ctx, contracts.Build{
+		RepoSource:    source,
+		RepoOwner:     owner,
+		RepoName:      repo,
+		RepoBranch:    body.Branch,
+		RepoRevision:  revision,
+		Manifest:      manifestString,
+		Organizations: pipeline.Organizations,
+		Groups:        pipeline.Groups,
+		Events: []manifest.EstafetteEvent{
+			{
+				Manual: &manifest.EstafetteManualEvent{
+					UserID: requester,
+				},
+			},
+		},
+	}, body.Parameters)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed creating build for pipeline %v/%v/%v branch %v revision %v for build dispatch issued by %v", source, owner, repo, body.Branch, revision, requester)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionBuildsCreate, "build", fmt.Sprintf("%v/%v/%v/%v", createdBuild.RepoSource, createdBuild.RepoOwner, createdBuild.RepoName, createdBuild.ID), nil, gin.H{
+		"build":      createdBuild,
+		"parameters": body.Parameters,
+	})
+
 	c.JSON(http.StatusCreated, createdBuild)
 }
 
+// GetPipelineBuildParameters returns the parameters a build was dispatched or triggered with
+func (h *Handler) GetPipelineBuildParameters(c *gin.Context) {
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+	revisionOrID := c.Param("revisionOrId")
+
+	id, err := strconv.Atoi(revisionOrID)
+	if err != nil {
+		log.Error().Err(err).
+			Msgf("Failed reading id from path parameter for %v/%v/%v/builds/%v", source, owner, repo, revisionOrID)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": "Path parameter id is not of type integer"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	build, err := h.cockroachDBClient.GetPipelineBuildByID(ctx, source, owner, repo, id, false)
+	if err != nil {
+		log.Error().Err(err).
+			Msgf("Failed retrieving build for %v/%v/%v/builds/%v from db", source, owner, repo, id)
+	}
+	if build == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline build not found"})
+		return
+	}
+
+	if !api.RequestTokenHasPermissionForResource(c, api.PermissionBuildsGet, api.GetPermissionResourceForPipeline(build.Organizations, build.Groups, build.Labels, "")) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	parameters, err := h.cockroachDBClient.GetBuildParameters(ctx, source, owner, repo, id)
+	if err != nil {
+		log.Error().Err(err).
+			Msgf("Failed retrieving parameters for %v/%v/%v/builds/%v from db", source, owner, repo, id)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": "Retrieving build parameters failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"parameters": parameters})
+}
+
 func (h *Handler) CancelPipelineBuild(c *gin.Context) {
 
 	if !api.RequestTokenIsValid(c) {
@@ -369,11 +574,16 @@ func (h *Handler) CancelPipelineBuild(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline build not found"})
 		return
 	}
+	if !h.requestHasPipelinePermission(c, api.PermissionBuildsCancel, build.Organizations, build.Groups, build.Labels, "") {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
 	if build.BuildStatus == "canceling" {
 		// apparently cancel was already clicked, but somehow the job didn't update the status to canceled
 		jobName := h.ciBuilderClient.GetJobName(c.Request.Context(), "build", build.RepoOwner, build.RepoName, build.ID)
 		h.ciBuilderClient.CancelCiBuilderJob(c.Request.Context(), jobName)
 		h.cockroachDBClient.UpdateBuildStatus(c.Request.Context(), build.RepoSource, build.RepoOwner, build.RepoName, id, "canceled")
+		audit.RecordRequestEvent(c, h.auditService, api.PermissionBuildsCancel, "build", fmt.Sprintf("%v/%v/%v/%v", source, owner, repo, id), gin.H{"buildStatus": build.BuildStatus}, gin.H{"buildStatus": "canceled"})
 		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Canceled build by user %v", email)})
 		return
 	}
@@ -409,6 +619,8 @@ func (h *Handler) CancelPipelineBuild(c *gin.Context) {
 		}
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionBuildsCancel, "build", fmt.Sprintf("%v/%v/%v/%v", source, owner, repo, id), gin.H{"buildStatus": build.BuildStatus}, gin.H{"buildStatus": buildStatus})
+
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Canceled build by user %v", email)})
 }
 
@@ -595,7 +807,11 @@ func (h *Handler) GetPipelineBuildWarnings(c *gin.Context) {
 		return
 	}
 
-	warnings, err := h.warningHelper.GetManifestWarnings(build.ManifestObject, build.GetFullRepoPath())
+	findings, err := h.warningHelper.Lint(api.LintInput{
+		Manifest:      build.ManifestObject,
+		FullRepoPath:  build.GetFullRepoPath(),
+		Organizations: build.Organizations,
+	})
 	if err != nil {
 		log.Error().Err(err).
 			Msgf("Failed getting warnings for %v/%v/%v/builds/%v manifest", source, owner, repo, revisionOrID)
@@ -603,6 +819,11 @@ func (h *Handler) GetPipelineBuildWarnings(c *gin.Context) {
 		return
 	}
 
+	warnings := []contracts.Warning{}
+	for _, f := range findings {
+		warnings = append(warnings, f.ToWarning())
+	}
+
 	c.JSON(http.StatusOK, gin.H{"warnings": warnings})
 }
 
@@ -614,8 +835,8 @@ func (h *Handler) GetPipelineReleases(c *gin.Context) {
 
 	pageNumber, pageSize, filters, sortings := api.GetQueryParameters(c)
 
-	response, err := api.GetPagedListResponse(
-		func() ([]interface{}, error) {
+	response, err := api.GetListResponse(c, pageNumber, pageSize, filters,
+		func(pageSize int) ([]interface{}, error) {
 			releases, err := h.cockroachDBClient.GetPipelineReleases(c.Request.Context(), source, owner, repo, pageNumber, pageSize, filters, sortings)
 			if err != nil {
 				return nil, err
@@ -632,8 +853,15 @@ func (h *Handler) GetPipelineReleases(c *gin.Context) {
 		func() (int, error) {
 			return h.cockroachDBClient.GetPipelineReleasesCount(c.Request.Context(), source, owner, repo, filters)
 		},
-		pageNumber,
-		pageSize)
+		func(item interface{}) string {
+			release := item.(*contracts.Release)
+			return api.EncodePageCursor(release.InsertedAt.Format(time.RFC3339Nano), release.ID)
+		})
+
+	if errors.Is(err, api.ErrInvalidPageCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": "Invalid page[after] cursor"})
+		return
+	}
 
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed retrieving releases for %v/%v/%v from db", source, owner, repo)
@@ -762,6 +990,11 @@ func (h *Handler) CreatePipelineRelease(c *gin.Context) {
 		return
 	}
 
+	if !h.requestHasPipelinePermission(c, api.PermissionReleasesCreate, build.Organizations, build.Groups, pipeline.Labels, releaseCommand.Name) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
 	// create release object and hand off to build service
 	createdRelease, err := h.buildService.CreateRelease(c.Request.Context(), contracts.Release{
 		Name:           releaseCommand.Name,
@@ -790,6 +1023,8 @@ func (h *Handler) CreatePipelineRelease(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionReleasesCreate, "release", fmt.Sprintf("%v/%v/%v/%v", createdRelease.RepoSource, createdRelease.RepoOwner, createdRelease.RepoName, createdRelease.ID), nil, createdRelease)
+
 	c.JSON(http.StatusCreated, createdRelease)
 }
 
@@ -825,10 +1060,24 @@ func (h *Handler) CancelPipelineRelease(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline release not found"})
 		return
 	}
+	if h.pipelinePermissionsAreEnforced() {
+		// releases don't carry labels, so get them from the pipeline
+		pipeline, err := h.cockroachDBClient.GetPipeline(c.Request.Context(), source, owner, repo, map[api.FilterType][]string{}, false)
+		if err != nil || pipeline == nil {
+			log.Error().Err(err).Msgf("Failed retrieving pipeline %v/%v/%v to check release permissions", source, owner, repo)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": "Retrieving pipeline failed"})
+			return
+		}
+		if !h.requestHasPipelinePermission(c, api.PermissionReleasesCancel, release.Organizations, release.Groups, pipeline.Labels, release.Name) {
+			c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+			return
+		}
+	}
 	if release.ReleaseStatus == "canceling" {
 		jobName := h.ciBuilderClient.GetJobName(c.Request.Context(), "release", release.RepoOwner, release.RepoName, release.ID)
 		h.ciBuilderClient.CancelCiBuilderJob(c.Request.Context(), jobName)
 		h.cockroachDBClient.UpdateReleaseStatus(c.Request.Context(), release.RepoSource, release.RepoOwner, release.RepoName, id, "canceled")
+		audit.RecordRequestEvent(c, h.auditService, api.PermissionReleasesCancel, "release", fmt.Sprintf("%v/%v/%v/%v", source, owner, repo, id), gin.H{"releaseStatus": release.ReleaseStatus}, gin.H{"releaseStatus": "canceled"})
 		c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Canceled release by user %v", email)})
 		return
 	}
@@ -863,6 +1112,8 @@ func (h *Handler) CancelPipelineRelease(c *gin.Context) {
 		}
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionReleasesCancel, "release", fmt.Sprintf("%v/%v/%v/%v", source, owner, repo, id), gin.H{"releaseStatus": release.ReleaseStatus}, gin.H{"releaseStatus": releaseStatus})
+
 	c.JSON(http.StatusOK, gin.H{"message": fmt.Sprintf("Canceled release by user %v", email)})
 }
 
@@ -1067,10 +1318,12 @@ func (h *Handler) GetPipelineStatsBuildsDurations(c *gin.Context) {
 	owner := c.Param("owner")
 	repo := c.Param("repo")
 
-	// get filters (?filter[last]=100)
+	// get filters (?filter[last]=100&filter[since]=P30D&filter[until]=2020-05-01)
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c, "succeeded")
 	filters[api.FilterLast] = api.GetLastFilter(c, 100)
+	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	durations, err := h.cockroachDBClient.GetPipelineBuildsDurations(c.Request.Context(), source, owner, repo, filters)
 	if err != nil {
@@ -1085,16 +1338,65 @@ func (h *Handler) GetPipelineStatsBuildsDurations(c *gin.Context) {
 	})
 }
 
+func (h *Handler) GetPipelineStatsFlakiness(c *gin.Context) {
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	// get filters (?filter[since]=P4W&filter[until]=2020-05-01)
+	filters := map[api.FilterType][]string{}
+	filters[api.FilterSince] = api.GetGenericFilter(c, api.FilterSince, api.DefaultFlakinessWindow)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
+
+	report, err := h.buildService.GetPipelineFlakiness(c.Request.Context(), source, owner, repo, filters)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving flakiness from db for %v/%v/%v", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) GetPipelineStatsStages(c *gin.Context) {
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	// get filters (?filter[last]=25&filter[since]=P30D&filter[until]=2020-05-01)
+	filters := map[api.FilterType][]string{}
+	filters[api.FilterLast] = api.GetCappedLastFilter(c, 25, maxStageRunBuildLogs)
+	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
+
+	runs, err := h.cockroachDBClient.GetPipelineStageRuns(c.Request.Context(), source, owner, repo, filters)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving stage runs from db for %v/%v/%v", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stages": api.ComputeStageStats(runs),
+	})
+}
+
 func (h *Handler) GetPipelineStatsReleasesDurations(c *gin.Context) {
 
 	source := c.Param("source")
 	owner := c.Param("owner")
 	repo := c.Param("repo")
 
-	// get filters (?filter[last]=100)
+	// get filters (?filter[last]=100&filter[since]=P30D&filter[until]=2020-05-01)
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c, "succeeded")
 	filters[api.FilterLast] = api.GetLastFilter(c, 100)
+	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	durations, err := h.cockroachDBClient.GetPipelineReleasesDurations(c.Request.Context(), source, owner, repo, filters)
 	if err != nil {
@@ -1115,10 +1417,12 @@ func (h *Handler) GetPipelineStatsBuildsCPUUsageMeasurements(c *gin.Context) {
 	owner := c.Param("owner")
 	repo := c.Param("repo")
 
-	// get filters (?filter[last]=100)
+	// get filters (?filter[last]=100&filter[since]=P30D&filter[until]=2020-05-01)
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c, "succeeded")
 	filters[api.FilterLast] = api.GetLastFilter(c, 100)
+	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	measurements, err := h.cockroachDBClient.GetPipelineBuildsCPUUsageMeasurements(c.Request.Context(), source, owner, repo, filters)
 	if err != nil {
@@ -1139,10 +1443,12 @@ func (h *Handler) GetPipelineStatsReleasesCPUUsageMeasurements(c *gin.Context) {
 	owner := c.Param("owner")
 	repo := c.Param("repo")
 
-	// get filters (?filter[last]=100)
+	// get filters (?filter[last]=100&filter[since]=P30D&filter[until]=2020-05-01)
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c, "succeeded")
 	filters[api.FilterLast] = api.GetLastFilter(c, 100)
+	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	measurements, err := h.cockroachDBClient.GetPipelineReleasesCPUUsageMeasurements(c.Request.Context(), source, owner, repo, filters)
 	if err != nil {
@@ -1163,10 +1469,12 @@ func (h *Handler) GetPipelineStatsBuildsMemoryUsageMeasurements(c *gin.Context)
 	owner := c.Param("owner")
 	repo := c.Param("repo")
 
-	// get filters (?filter[last]=100)
+	// get filters (?filter[last]=100&filter[since]=P30D&filter[until]=2020-05-01)
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c, "succeeded")
 	filters[api.FilterLast] = api.GetLastFilter(c, 100)
+	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	measurements, err := h.cockroachDBClient.GetPipelineBuildsMemoryUsageMeasurements(c.Request.Context(), source, owner, repo, filters)
 	if err != nil {
@@ -1187,10 +1495,12 @@ func (h *Handler) GetPipelineStatsReleasesMemoryUsageMeasurements(c *gin.Context
 	owner := c.Param("owner")
 	repo := c.Param("repo")
 
-	// get filters (?filter[last]=100)
+	// get filters (?filter[last]=100&filter[since]=P30D&filter[until]=2020-05-01)
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c, "succeeded")
 	filters[api.FilterLast] = api.GetLastFilter(c, 100)
+	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	measurements, err := h.cockroachDBClient.GetPipelineReleasesMemoryUsageMeasurements(c.Request.Context(), source, owner, repo, filters)
 	if err != nil {
@@ -1241,6 +1551,12 @@ func (h *Handler) GetPipelineWarnings(c *gin.Context) {
 		return
 	}
 
+	lintInput := api.LintInput{
+		Manifest:      pipeline.ManifestObject,
+		FullRepoPath:  pipeline.GetFullRepoPath(),
+		Organizations: pipeline.Organizations,
+	}
+
 	if len(durations) > 0 {
 		// pick the item at half of the length
 		medianIndex := len(durations)/2 - 1
@@ -1248,63 +1564,483 @@ func (h *Handler) GetPipelineWarnings(c *gin.Context) {
 			medianIndex = 0
 		}
 		duration := durations[medianIndex]["duration"].(time.Duration)
-		durationInSeconds := duration.Seconds()
-
-		if durationInSeconds > 300.0 {
-			warnings = append(warnings, contracts.Warning{
-				Status:  "danger",
-				Message: fmt.Sprintf("The [median build time](/pipelines/%v/%v/%v/statistics?last=25) of this pipeline is **%v**. This is too slow, please optimize your build speed by using smaller images or running less intensive steps to ensure it finishes at least within 5 minutes, but preferably within 2 minutes.", source, owner, repo, duration),
-			})
-		} else if durationInSeconds > 120.0 {
-			warnings = append(warnings, contracts.Warning{
-				Status:  "warning",
-				Message: fmt.Sprintf("The [median build time](/pipelines/%v/%v/%v/statistics?last=25) of this pipeline is **%v**. This is a bit too slow, please optimize your build speed by using smaller images or running less intensive steps to ensure it finishes within 2 minutes.", source, owner, repo, duration),
-			})
-		}
+		lintInput.MedianBuildTime = &duration
+	}
+
+	flakiness, err := h.buildService.GetPipelineFlakiness(c.Request.Context(), source, owner, repo, map[api.FilterType][]string{api.FilterSince: {api.DefaultFlakinessWindow}})
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving flakiness from db for pipeline %v/%v/%v warnings", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
 	}
+	lintInput.Flakiness = flakiness
 
-	manifestWarnings, err := h.warningHelper.GetManifestWarnings(pipeline.ManifestObject, pipeline.GetFullRepoPath())
+	findings, err := h.warningHelper.Lint(lintInput)
 	if err != nil {
 		log.Error().Err(err).
 			Msgf("Failed getting warnings for %v/%v/%v manifest", source, owner, repo)
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": "Failed getting warnings for manifest"})
 		return
 	}
-	warnings = append(warnings, manifestWarnings...)
+	for _, f := range findings {
+		warnings = append(warnings, f.ToWarning())
+	}
+
+	// add warnings recorded while running the pipeline, like refused triggers, from the last week
+	recordedWarnings, err := h.cockroachDBClient.GetPipelineWarnings(c.Request.Context(), source, owner, repo, time.Now().UTC().Add(-7*24*time.Hour))
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving recorded warnings from db for pipeline %v/%v/%v", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	warnings = append(warnings, recordedWarnings...)
 
 	c.JSON(http.StatusOK, gin.H{"warnings": warnings})
 }
 
-func (h *Handler) GetCatalogFilters(c *gin.Context) {
+func (h *Handler) GetPipelineDependencies(c *gin.Context) {
 
-	if h.config == nil || h.config.Catalog == nil {
-		c.JSON(http.StatusOK, []string{})
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	pipeline, err := h.cockroachDBClient.GetPipeline(c.Request.Context(), source, owner, repo, map[api.FilterType][]string{}, false)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed retrieving pipeline for %v/%v/%v from db", source, owner, repo)
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, h.config.Catalog.Filters)
+	graph, err := h.buildService.GetTriggerGraph(c.Request.Context(), *pipeline)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving trigger graph for pipeline %v/%v/%v", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	// ?format=dot returns the graph in the graphviz dot language
+	if c.DefaultQuery("format", "json") == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(graph.DOT(pipeline.GetFullRepoPath())))
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
 }
 
-func (h *Handler) GetCatalogFilterValues(c *gin.Context) {
+// GetPipelinePolicy shows per stage which trusted image privileges, credentials and secrets the current manifest gets, and why any are refused
+func (h *Handler) GetPipelinePolicy(c *gin.Context) {
 
-	labelKey := c.DefaultQuery("filter[labels]", "type")
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
 
-	labels, err := h.cockroachDBClient.GetLabelValues(c.Request.Context(), labelKey)
+	pipeline, err := h.cockroachDBClient.GetPipeline(c.Request.Context(), source, owner, repo, map[api.FilterType][]string{}, false)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed retrieving label values from db")
-		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		log.Error().Err(err).Msgf("Failed retrieving pipeline for %v/%v/%v from db", source, owner, repo)
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, labels)
+	// scoped secrets are checked against the branch of the last build
+	policy, err := api.EvaluatePipelinePolicy(pipeline.ManifestObject, pipeline.GetFullRepoPath(), api.NewSecretScopeContext(pipeline.Organizations, pipeline.Groups, pipeline.RepoBranch, ""), h.encryptedConfig.TrustedImages, h.encryptedConfig.Credentials, h.secretHelper)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed evaluating policy for pipeline %v/%v/%v", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
 }
 
-func (h *Handler) GetStatsPipelinesCount(c *gin.Context) {
+func (h *Handler) GetPipelineWebhooks(c *gin.Context) {
 
-	// get filters (?filter[status]=running,succeeded&filter[since]=1w
+	if !api.RequestTokenIsValid(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "JWT is invalid"})
+		return
+	}
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	ctx := c.Request.Context()
+
+	pipeline, err := h.cockroachDBClient.GetPipeline(ctx, source, owner, repo, map[api.FilterType][]string{}, false)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving pipeline %v/%v/%v for retrieving webhooks", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
+		return
+	}
+
+	if !h.requestHasPipelinePermission(c, api.PermissionPipelinesGet, pipeline.Organizations, pipeline.Groups, pipeline.Labels, "") {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	webhooks, err := h.cockroachDBClient.GetPipelineWebhooks(ctx, source, owner, repo)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving webhooks for pipeline %v/%v/%v from db", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks})
+}
+
+func (h *Handler) CreatePipelineWebhook(c *gin.Context) {
+
+	if !api.RequestTokenIsValid(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "JWT is invalid"})
+		return
+	}
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	var webhook api.Webhook
+	err := c.BindJSON(&webhook)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding CreatePipelineWebhook body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	err = webhook.Validate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	pipeline, err := h.cockroachDBClient.GetPipeline(ctx, source, owner, repo, map[api.FilterType][]string{}, false)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving pipeline %v/%v/%v for creating webhook", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
+		return
+	}
+
+	if !h.requestHasPipelinePermission(c, api.PermissionPipelinesUpdate, pipeline.Organizations, pipeline.Groups, pipeline.Labels, "") {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	if webhook.ReleaseAction != nil && !pipelineHasReleaseTarget(*pipeline, webhook.ReleaseAction.Target) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": fmt.Sprintf("Pipeline %v/%v/%v has no release target %v", source, owner, repo, webhook.ReleaseAction.Target)})
+		return
+	}
+
+	// clients don't have an email address, so fall back to their client id
+	claims := jwt.ExtractClaims(c)
+	webhook.CreatedBy, _ = claims["email"].(string)
+	if webhook.CreatedBy == "" {
+		webhook.CreatedBy, _ = claims["clientID"].(string)
+	}
+
+	webhook.ID = ""
+	webhook.RepoSource = pipeline.RepoSource
+	webhook.RepoOwner = pipeline.RepoOwner
+	webhook.RepoName = pipeline.RepoName
+
+	err = webhook.SetToken()
+	if err != nil {
+		errorMessage := "Failed generating webhook token"
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	token := webhook.Token
+
+	insertedWebhook, err := h.cockroachDBClient.InsertWebhook(ctx, webhook)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed inserting webhook for pipeline %v/%v/%v", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionPipelinesUpdate, "webhook", insertedWebhook.ID, nil, insertedWebhook)
+
+	// the token is returned only once, together with the url to call; callers pass it in the X-Estafette-Webhook-Token header
+	insertedWebhook.Token = token
+
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": insertedWebhook,
+		"url":     fmt.Sprintf("%v/api/integrations/webhooks/%v", strings.TrimSuffix(h.config.APIServer.BaseURL, "/"), insertedWebhook.ID),
+	})
+}
+
+func (h *Handler) DeletePipelineWebhook(c *gin.Context) {
+
+	if !api.RequestTokenIsValid(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "JWT is invalid"})
+		return
+	}
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+	id := c.Param("id")
+
+	ctx := c.Request.Context()
+
+	webhook, err := h.cockroachDBClient.GetWebhookByID(ctx, id)
+	if err != nil || webhook == nil || !webhook.Active || webhook.RepoSource != source || webhook.RepoOwner != owner || webhook.RepoName != repo {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Webhook not found"})
+		return
+	}
+
+	pipeline, err := h.cockroachDBClient.GetPipeline(ctx, source, owner, repo, map[api.FilterType][]string{}, false)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving pipeline %v/%v/%v for deleting webhook", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
+		return
+	}
+
+	if !h.requestHasPipelinePermission(c, api.PermissionPipelinesUpdate, pipeline.Organizations, pipeline.Groups, pipeline.Labels, "") {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	err = h.cockroachDBClient.DeleteWebhook(ctx, *webhook)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed deleting webhook %v for pipeline %v/%v/%v", id, source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionPipelinesUpdate, "webhook", webhook.ID, webhook, nil)
+
+	c.String(http.StatusOK, "Webhook deleted")
+}
+
+func (h *Handler) GetPipelineWebhookDeliveries(c *gin.Context) {
+
+	if !api.RequestTokenIsValid(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "JWT is invalid"})
+		return
+	}
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	ctx := c.Request.Context()
+
+	pipeline, err := h.cockroachDBClient.GetPipeline(ctx, source, owner, repo, map[api.FilterType][]string{}, false)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving pipeline %v/%v/%v for retrieving webhook deliveries", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
+		return
+	}
+
+	if !h.requestHasPipelinePermission(c, api.PermissionPipelinesGet, pipeline.Organizations, pipeline.Groups, pipeline.Labels, "") {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	pageNumber, pageSize, _, _ := api.GetQueryParameters(c)
+
+	response, err := api.GetPagedListResponse(
+		func() ([]interface{}, error) {
+			deliveries, err := h.cockroachDBClient.GetPipelineWebhookDeliveries(ctx, source, owner, repo, pageNumber, pageSize)
+			if err != nil {
+				return nil, err
+			}
+
+			// convert typed array to interface array O(n)
+			items := make([]interface{}, len(deliveries))
+			for i := range deliveries {
+				items[i] = deliveries[i]
+			}
+
+			return items, nil
+		},
+		func() (int, error) {
+			return h.cockroachDBClient.GetPipelineWebhookDeliveriesCount(ctx, source, owner, repo)
+		},
+		pageNumber,
+		pageSize)
+
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed retrieving webhook deliveries or count for pipeline %v/%v/%v from db", source, owner, repo)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// PostWebhookDelivery is called by external systems; it's authorized by the webhook's secret token, passed in the X-Estafette-Webhook-Token header so it doesn't end up in access logs
+func (h *Handler) PostWebhookDelivery(c *gin.Context) {
+
+	id := c.Param("id")
+
+	token := c.GetHeader("X-Estafette-Webhook-Token")
+
+	ctx := c.Request.Context()
+
+	// don't reveal whether the webhook exists if the token doesn't match
+	webhook, err := h.cockroachDBClient.GetWebhookByID(ctx, id)
+	if err != nil || webhook == nil || !webhook.Active || !webhook.HasToken(token) {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": http.StatusText(http.StatusUnauthorized), "message": "Webhook token is invalid"})
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxWebhookPayloadBytes+1))
+	if err != nil {
+		errorMessage := fmt.Sprint("Reading PostWebhookDelivery body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+	if len(body) > maxWebhookPayloadBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"code": http.StatusText(http.StatusRequestEntityTooLarge), "message": fmt.Sprintf("Webhook payload exceeds the maximum of %v bytes", maxWebhookPayloadBytes)})
+		return
+	}
+
+	delivery := api.WebhookDelivery{
+		WebhookID:   webhook.ID,
+		WebhookName: webhook.Name,
+		RepoSource:  webhook.RepoSource,
+		RepoOwner:   webhook.RepoOwner,
+		RepoName:    webhook.RepoName,
+		Payload:     getWebhookPayload(body),
+	}
+	delivery.Variables = api.GetWebhookVariables(webhook.Name, delivery.Payload)
+
+	// the payload comes from outside the pipeline, so it can't carry secrets to be decrypted for the build or release
+	for name, value := range delivery.Variables {
+		if api.ContainsSecretEnvelope(value) {
+			errorMessage := fmt.Sprintf("Webhook payload field %v contains a secret, which isn't allowed", name)
+			delivery.Status = api.WebhookDeliveryStatusFailed
+			delivery.Message = errorMessage
+			delivery.Variables = nil
+			if _, err := h.cockroachDBClient.InsertWebhookDelivery(ctx, delivery); err != nil {
+				log.Error().Err(err).Msgf("Failed recording delivery of webhook %v for pipeline %v/%v/%v", webhook.Name, webhook.RepoSource, webhook.RepoOwner, webhook.RepoName)
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+			return
+		}
+	}
+
+	// respond right away and fire in the background, so a slow build or release doesn't keep the caller waiting; the outcome is recorded as delivery
+	go h.fireWebhook(context.Background(), *webhook, delivery)
+
+	c.JSON(http.StatusAccepted, gin.H{"code": http.StatusText(http.StatusAccepted), "message": fmt.Sprintf("Delivery of webhook %v accepted", webhook.Name)})
+}
+
+func (h *Handler) fireWebhook(ctx context.Context, webhook api.Webhook, delivery api.WebhookDelivery) {
+
+	err := h.buildService.FireWebhookTriggers(ctx, webhook, delivery.Variables)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed firing webhook %v for pipeline %v/%v/%v", webhook.Name, webhook.RepoSource, webhook.RepoOwner, webhook.RepoName)
+		delivery.Status = api.WebhookDeliveryStatusFailed
+		delivery.Message = err.Error()
+	} else {
+		delivery.Status = api.WebhookDeliveryStatusFired
+	}
+
+	_, err = h.cockroachDBClient.InsertWebhookDelivery(ctx, delivery)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed recording delivery of webhook %v for pipeline %v/%v/%v", webhook.Name, webhook.RepoSource, webhook.RepoOwner, webhook.RepoName)
+	}
+}
+
+func (h *Handler) SimulateTriggers(c *gin.Context) {
+
+	var event manifest.EstafetteEvent
+	err := c.BindJSON(&event)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding SimulateTriggers body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	// cron triggers fire depending on the time of the event
+	if event.Cron != nil && event.Cron.Time.IsZero() {
+		event.Cron.Time = time.Now().UTC()
+	}
+
+	firings, err := h.buildService.SimulateTriggers(c.Request.Context(), event)
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedEvent) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": "Event needs to be a git, pipeline, release, pubsub or cron event"})
+			return
+		}
+		errorMessage := fmt.Sprint("Failed simulating triggers")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"firings": firings})
+}
+
+func (h *Handler) GetCatalogFilters(c *gin.Context) {
+
+	if h.config == nil || h.config.Catalog == nil {
+		c.JSON(http.StatusOK, []string{})
+		return
+	}
+
+	c.JSON(http.StatusOK, h.config.Catalog.Filters)
+}
+
+func (h *Handler) GetCatalogFilterValues(c *gin.Context) {
+
+	labelKey := c.DefaultQuery("filter[labels]", "type")
+
+	labels, err := h.cockroachDBClient.GetLabelValues(c.Request.Context(), labelKey)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed retrieving label values from db")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	c.JSON(http.StatusOK, labels)
+}
+
+func (h *Handler) GetStatsPipelinesCount(c *gin.Context) {
+
+	// get filters (?filter[status]=running,succeeded&filter[since]=1w&filter[until]=2020-05-01
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c)
 	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	pipelinesCount, err := h.cockroachDBClient.GetPipelinesCount(c.Request.Context(), filters)
 	if err != nil {
@@ -1319,10 +2055,11 @@ func (h *Handler) GetStatsPipelinesCount(c *gin.Context) {
 
 func (h *Handler) GetStatsReleasesCount(c *gin.Context) {
 
-	// get filters (?filter[status]=running,succeeded&filter[since]=1w
+	// get filters (?filter[status]=running,succeeded&filter[since]=1w&filter[until]=2020-05-01
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c)
 	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	releasesCount, err := h.cockroachDBClient.GetReleasesCount(c.Request.Context(), filters)
 	if err != nil {
@@ -1337,10 +2074,11 @@ func (h *Handler) GetStatsReleasesCount(c *gin.Context) {
 
 func (h *Handler) GetStatsBuildsCount(c *gin.Context) {
 
-	// get filters (?filter[status]=running,succeeded&filter[since]=1w
+	// get filters (?filter[status]=running,succeeded&filter[since]=1w&filter[until]=2020-05-01
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c)
 	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	buildsCount, err := h.cockroachDBClient.GetBuildsCount(c.Request.Context(), filters)
 	if err != nil {
@@ -1425,12 +2163,81 @@ func (h *Handler) GetStatsMostReleases(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *Handler) GetStatsFlakyBuilds(c *gin.Context) {
+
+	pageNumber := api.GetPageNumber(c)
+	pageSize := api.GetPageSize(c)
+
+	// get filters (?filter[since]=P4W&filter[until]=2020-05-01&filter[labels]=team%3Destafette-team)
+	filters := map[api.FilterType][]string{}
+	filters[api.FilterSince] = api.GetGenericFilter(c, api.FilterSince, api.DefaultFlakinessWindow)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
+	filters[api.FilterLabels] = api.GetLabelsFilter(c)
+
+	// filter on organizations / groups
+	filters = api.SetPermissionsFilters(c, filters)
+
+	reports, err := h.buildService.GetFlakyPipelines(c.Request.Context(), filters)
+	if err != nil {
+		errorMessage := "Failed retrieving flaky pipelines from db"
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	// only pipelines with at least one flaky revision are reported
+	flakyReports := []*api.FlakinessReport{}
+	for _, r := range reports {
+		if r.FlakyRevisions > 0 {
+			flakyReports = append(flakyReports, r)
+		}
+	}
+
+	response := contracts.ListResponse{
+		Pagination: contracts.Pagination{
+			Page:       pageNumber,
+			Size:       pageSize,
+			TotalItems: len(flakyReports),
+			TotalPages: int(math.Ceil(float64(len(flakyReports)) / float64(pageSize))),
+		},
+	}
+
+	response.Items = []interface{}{}
+	for i := (pageNumber - 1) * pageSize; i >= 0 && i < len(flakyReports) && i < pageNumber*pageSize; i++ {
+		response.Items = append(response.Items, flakyReports[i])
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) GetStatsStageImages(c *gin.Context) {
+
+	// get filters (?filter[last]=1000&filter[since]=1w&filter[until]=2020-05-01)
+	filters := map[api.FilterType][]string{}
+	filters[api.FilterLast] = api.GetCappedLastFilter(c, 1000, maxStageRunBuildLogs)
+	filters[api.FilterSince] = api.GetGenericFilter(c, api.FilterSince, "1w")
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
+
+	runs, err := h.cockroachDBClient.GetStageRuns(c.Request.Context(), filters)
+	if err != nil {
+		errorMessage := "Failed retrieving stage runs from db"
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"images": api.ComputeStageImageStats(runs),
+	})
+}
+
 func (h *Handler) GetStatsBuildsDuration(c *gin.Context) {
 
-	// get filters (?filter[status]=running,succeeded&filter[since]=1w
+	// get filters (?filter[status]=running,succeeded&filter[since]=1w&filter[until]=2020-05-01
 	filters := map[api.FilterType][]string{}
 	filters[api.FilterStatus] = api.GetStatusFilter(c)
 	filters[api.FilterSince] = api.GetSinceFilter(c)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
 
 	buildsDuration, err := h.cockroachDBClient.GetBuildsDuration(c.Request.Context(), filters)
 	if err != nil {
@@ -1473,6 +2280,108 @@ func (h *Handler) GetStatsReleasesAdoption(c *gin.Context) {
 	})
 }
 
+func (h *Handler) GetStatsDora(c *gin.Context) {
+
+	// get filters (?filter[since]=P4W&filter[until]=2020-05-01&filter[labels]=team%3Destafette-team&groupBy=label:team)
+	filters := map[api.FilterType][]string{}
+	filters[api.FilterSince] = api.GetGenericFilter(c, api.FilterSince, h.config.Dora.GetDefaultWindow())
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
+	filters[api.FilterLabels] = api.GetLabelsFilter(c)
+
+	// filter on organizations / groups
+	filters = api.SetPermissionsFilters(c, filters)
+
+	metrics, err := h.buildService.GetDoraMetrics(c.Request.Context(), c.Query("groupBy"), filters)
+	if err != nil {
+		if errors.Is(err, api.ErrInvalidGroupBy) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+			return
+		}
+		errorMessage := "Failed retrieving dora metrics from db"
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"productionReleaseTargets": h.config.Dora.GetProductionReleaseTargets(),
+		"metrics":                  metrics,
+	})
+}
+
+func (h *Handler) GetStatsCost(c *gin.Context) {
+
+	// get filters (?filter[since]=P4W&filter[until]=2020-05-01&filter[labels]=team%3Destafette-team&groupBy=label:team&format=csv)
+	filters := map[api.FilterType][]string{}
+	filters[api.FilterSince] = api.GetGenericFilter(c, api.FilterSince, api.DefaultCostWindow)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
+	filters[api.FilterLabels] = api.GetLabelsFilter(c)
+
+	// filter on organizations / groups
+	filters = api.SetPermissionsFilters(c, filters)
+
+	groupBy := c.Query("groupBy")
+	if err := api.ValidateGroupBy(groupBy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+		return
+	}
+
+	usages, err := h.cockroachDBClient.GetJobUsages(c.Request.Context(), filters)
+	if err != nil {
+		errorMessage := "Failed retrieving job usages from db"
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	reports := api.ComputeCostReports(usages, groupBy, h.config.Cost)
+
+	if c.Query("format") == "csv" {
+		var buffer bytes.Buffer
+		if err := api.WriteCostReportsCSV(&buffer, reports, h.config.Cost.GetCurrency()); err != nil {
+			errorMessage := "Failed writing cost reports as csv"
+			log.Error().Err(err).Msg(errorMessage)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+			return
+		}
+
+		c.Header("Content-Disposition", "attachment; filename=cost.csv")
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buffer.Bytes())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currency": h.config.Cost.GetCurrency(),
+		"reports":  reports,
+	})
+}
+
+func (h *Handler) GetStatsCostSavings(c *gin.Context) {
+
+	// get filters (?filter[since]=P4W&filter[until]=2020-05-01&filter[labels]=team%3Destafette-team)
+	filters := map[api.FilterType][]string{}
+	filters[api.FilterSince] = api.GetGenericFilter(c, api.FilterSince, api.DefaultCostWindow)
+	filters[api.FilterUntil] = api.GetUntilFilter(c)
+	filters[api.FilterLabels] = api.GetLabelsFilter(c)
+
+	// filter on organizations / groups
+	filters = api.SetPermissionsFilters(c, filters)
+
+	usages, err := h.cockroachDBClient.GetJobUsages(c.Request.Context(), filters)
+	if err != nil {
+		errorMessage := "Failed retrieving job usages from db"
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"currency":         h.config.Cost.GetCurrency(),
+		"savingsThreshold": h.config.Cost.GetSavingsThreshold(),
+		"opportunities":    api.ComputeSavingsOpportunities(usages, h.config.Cost),
+	})
+}
+
 func (h *Handler) GetConfig(c *gin.Context) {
 
 	configBytes, err := yaml.Marshal(h.encryptedConfig)
@@ -1663,13 +2572,25 @@ func (h *Handler) ValidateManifest(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": status, "errors": errorString})
 }
 
+// GetManifestLintRules returns the manifest lint rules as configured; pass ?organization=name to get them with the overrides for that organization
+func (h *Handler) GetManifestLintRules(c *gin.Context) {
+
+	organizations := []*contracts.Organization{}
+	for _, name := range c.QueryArray("organization") {
+		organizations = append(organizations, &contracts.Organization{Name: name})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": h.warningHelper.GetLintRules(organizations)})
+}
+
 func (h *Handler) EncryptSecret(c *gin.Context) {
 
 	var aux struct {
-		Base64Encode      bool   `json:"base64"`
-		DoubleEncrypt     bool   `json:"double"`
-		PipelineWhitelist string `json:"pipelineWhitelist"`
-		Value             string `json:"value"`
+		Base64Encode      bool             `json:"base64"`
+		DoubleEncrypt     bool             `json:"double"`
+		PipelineWhitelist string           `json:"pipelineWhitelist"`
+		Value             string           `json:"value"`
+		Scope             *api.SecretScope `json:"scope,omitempty"`
 	}
 
 	err := c.BindJSON(&aux)
@@ -1680,6 +2601,12 @@ func (h *Handler) EncryptSecret(c *gin.Context) {
 		return
 	}
 
+	err = aux.Scope.Validate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+		return
+	}
+
 	value := aux.Value
 
 	// trim any whitespace and newlines at beginning and end of string
@@ -1690,7 +2617,7 @@ func (h *Handler) EncryptSecret(c *gin.Context) {
 		value = base64.StdEncoding.EncodeToString([]byte(value))
 	}
 
-	encryptedString, err := h.secretHelper.EncryptEnvelope(value, aux.PipelineWhitelist)
+	encryptedString, err := api.EncryptScopedSecretEnvelope(h.secretHelper, value, aux.PipelineWhitelist, aux.Scope)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed encrypting secret")
 		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
@@ -1709,6 +2636,105 @@ func (h *Handler) EncryptSecret(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"secret": encryptedString})
 }
 
+// GetSecretKeyRotationReport lists the config and pipelines that still hold secrets encrypted with the previous or an unknown key
+func (h *Handler) GetSecretKeyRotationReport(c *gin.Context) {
+
+	// ensure the user has administrator role
+	if !api.RequestTokenHasRole(c, api.RoleAdministrator) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or user does not have administrator role"})
+		return
+	}
+
+	secretHelper := h.getKeyRotatingSecretHelper()
+
+	report := api.SecretKeyRotationReport{
+		Pipelines: []*api.PipelineSecretKeyUsage{},
+	}
+
+	configData, err := ioutil.ReadFile(h.configFilePath)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed reading config file %v", h.configFilePath)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+	report.Config, err = api.GetSecretKeyUsage(secretHelper, string(configData), "")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed checking secrets in config")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	pageSize := 100
+	for pageNumber := 1; ; pageNumber++ {
+		pipelines, err := h.cockroachDBClient.GetPipelines(c.Request.Context(), pageNumber, pageSize, map[api.FilterType][]string{}, []api.OrderField{}, false)
+		if err != nil {
+			errorMessage := "Failed retrieving pipelines from db"
+			log.Error().Err(err).Msg(errorMessage)
+			c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+			return
+		}
+
+		for _, p := range pipelines {
+			usage, err := api.GetSecretKeyUsage(secretHelper, p.Manifest, p.GetFullRepoPath())
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed checking secrets in manifest for pipeline %v", p.GetFullRepoPath())
+				continue
+			}
+			if usage.NeedsRotation() {
+				report.Pipelines = append(report.Pipelines, &api.PipelineSecretKeyUsage{
+					RepoSource:     p.RepoSource,
+					RepoOwner:      p.RepoOwner,
+					RepoName:       p.RepoName,
+					SecretKeyUsage: usage,
+				})
+			}
+		}
+
+		if len(pipelines) < pageSize {
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RotateConfigSecrets returns the config file with the secrets encrypted with the previous key re-encrypted with the current key, to replace the config file with before the previous key is removed
+func (h *Handler) RotateConfigSecrets(c *gin.Context) {
+
+	// ensure the user has administrator role
+	if !api.RequestTokenHasRole(c, api.RoleAdministrator) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or user does not have administrator role"})
+		return
+	}
+
+	configData, err := ioutil.ReadFile(h.configFilePath)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed reading config file %v", h.configFilePath)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	rotatedConfig, rotatedSecrets, err := h.getKeyRotatingSecretHelper().RotateAllEnvelopes(string(configData), "")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed rotating secrets in config")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	// rotating is restricted to the administrator role instead of a permission, so the event is recorded with its own action
+	audit.RecordEvent(c.Request.Context(), h.auditService, api.NewAuditEventFromRequest(c, "config.secrets.rotate", "config", h.configFilePath, nil, gin.H{"rotatedSecrets": rotatedSecrets}))
+
+	c.JSON(http.StatusOK, gin.H{"config": rotatedConfig, "rotatedSecrets": rotatedSecrets})
+}
+
+func (h *Handler) getKeyRotatingSecretHelper() api.KeyRotatingSecretHelper {
+	if secretHelper, ok := h.secretHelper.(api.KeyRotatingSecretHelper); ok {
+		return secretHelper
+	}
+
+	return api.NewKeyRotatingSecretHelper(h.secretHelper, nil)
+}
+
 func (h *Handler) PostCronEvent(c *gin.Context) {
 
 	// ensure the user has administrator role
@@ -1827,6 +2853,33 @@ func (h *Handler) CopyLogsToCloudStorage(c *gin.Context) {
 	c.String(http.StatusOK, "Aye aye!")
 }
 
+func (h *Handler) pipelinePermissionsAreEnforced() bool {
+	return h.config != nil && h.config.Auth != nil && h.config.Auth.EnforcePipelinePermissions
+}
+
+// getManifestFunc returns the function to resolve a revision and retrieve its manifest for the repository source
+func (h *Handler) getManifestFunc(repoSource string) func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+	switch {
+	case githubapi.IsRepoSourceGithub(repoSource):
+		return h.githubManifestFunc
+	case bitbucketapi.IsRepoSourceBitbucket(repoSource):
+		return h.bitbucketManifestFunc
+	case cloudsourceapi.IsRepoSourceCloudSource(repoSource):
+		return h.cloudsourceManifestFunc
+	}
+
+	return nil
+}
+
+// requestHasPipelinePermission checks the permission for a pipeline if pipeline permissions are enforced; otherwise any valid token is allowed to operate pipelines
+func (h *Handler) requestHasPipelinePermission(c *gin.Context, permission api.Permission, organizations []*contracts.Organization, groups []*contracts.Group, labels []contracts.Label, releaseTarget string) bool {
+	if !h.pipelinePermissionsAreEnforced() {
+		return true
+	}
+
+	return api.RequestTokenHasPermissionForResource(c, permission, api.GetPermissionResourceForPipeline(organizations, groups, labels, releaseTarget))
+}
+
 func (h *Handler) obfuscateSecrets(input string) (string, error) {
 
 	r, err := regexp.Compile(`estafette\.secret\(([a-zA-Z0-9.=_-]+)\)`)
@@ -1927,3 +2980,41 @@ func (h *Handler) Commands(c *gin.Context) {
 
 	c.String(http.StatusOK, "Aye aye!")
 }
+
+// maxStageRunBuildLogs limits the number of build logs stage stats are computed from, since each one is read from the database
+const maxStageRunBuildLogs = 5000
+
+// maxWebhookPayloadBytes limits the size of webhook payloads, since they end up as environment variables
+const maxWebhookPayloadBytes = 64 * 1024
+
+// pipelineHasReleaseTarget checks whether the pipeline's manifest defines the release target
+func pipelineHasReleaseTarget(pipeline contracts.Pipeline, target string) bool {
+	for _, rt := range pipeline.ReleaseTargets {
+		if rt.Name == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getWebhookPayload returns the parsed body if it's json, otherwise the body as is
+func getWebhookPayload(body []byte) interface{} {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		return payload
+	}
+
+	return string(body)
+}
+
+var buildParameterNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// isValidBuildParameterName checks whether a parameter can be used as environment variable without overriding the ones set by estafette
+func isValidBuildParameterName(name string) bool {
+	return buildParameterNameRegex.MatchString(name) && !strings.HasPrefix(strings.ToUpper(name), "ESTAFETTE_")
+}
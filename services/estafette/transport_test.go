@@ -3,19 +3,25 @@ package estafette
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/builderapi"
 	"github.com/estafette/estafette-ci-api/clients/cloudstorage"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/services/audit"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	crypt "github.com/estafette/estafette-ci-crypt"
+	manifest "github.com/estafette/estafette-ci-manifest"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -102,15 +108,21 @@ func TestGetCatalogFilters(t *testing.T) {
 		builderapiClient := builderapi.MockClient{}
 
 		buildService := MockService{}
+		auditService := audit.MockService{}
 		secretHelper := crypt.NewSecretHelper("abc", false)
-		warningHelper := api.NewWarningHelper(secretHelper)
+		warningHelper := api.NewWarningHelper(secretHelper, cfg)
 		githubJobVarsFunc := func(context.Context, string, string, string) (string, string, error) {
 			return "", "", nil
 		}
 		bitbucketJobVarsFunc := githubJobVarsFunc
 		cloudsourceJobVarsFunc := githubJobVarsFunc
+		githubManifestFunc := func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+			return "", false, "", nil
+		}
+		bitbucketManifestFunc := githubManifestFunc
+		cloudsourceManifestFunc := githubManifestFunc
 
-		handler := NewHandler(configFilePath, cfg, encryptedConfig, cockroachdbClient, cloudStorageClient, builderapiClient, buildService, warningHelper, secretHelper, githubJobVarsFunc, bitbucketJobVarsFunc, cloudsourceJobVarsFunc)
+		handler := NewHandler(configFilePath, cfg, encryptedConfig, cockroachdbClient, cloudStorageClient, builderapiClient, buildService, auditService, warningHelper, secretHelper, githubJobVarsFunc, bitbucketJobVarsFunc, cloudsourceJobVarsFunc, githubManifestFunc, bitbucketManifestFunc, cloudsourceManifestFunc)
 		recorder := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(recorder)
 
@@ -163,15 +175,21 @@ func TestGetPipeline(t *testing.T) {
 		cloudStorageClient := cloudstorage.MockClient{}
 		builderapiClient := builderapi.MockClient{}
 		buildService := MockService{}
+		auditService := audit.MockService{}
 		secretHelper := crypt.NewSecretHelper("abc", false)
-		warningHelper := api.NewWarningHelper(secretHelper)
+		warningHelper := api.NewWarningHelper(secretHelper, cfg)
 		githubJobVarsFunc := func(context.Context, string, string, string) (string, string, error) {
 			return "", "", nil
 		}
 		bitbucketJobVarsFunc := githubJobVarsFunc
 		cloudsourceJobVarsFunc := githubJobVarsFunc
+		githubManifestFunc := func(context.Context, string, string, string, string, string) (string, bool, string, error) {
+			return "", false, "", nil
+		}
+		bitbucketManifestFunc := githubManifestFunc
+		cloudsourceManifestFunc := githubManifestFunc
 
-		handler := NewHandler(configFilePath, cfg, encryptedConfig, cockroachdbClient, cloudStorageClient, builderapiClient, buildService, warningHelper, secretHelper, githubJobVarsFunc, bitbucketJobVarsFunc, cloudsourceJobVarsFunc)
+		handler := NewHandler(configFilePath, cfg, encryptedConfig, cockroachdbClient, cloudStorageClient, builderapiClient, buildService, auditService, warningHelper, secretHelper, githubJobVarsFunc, bitbucketJobVarsFunc, cloudsourceJobVarsFunc, githubManifestFunc, bitbucketManifestFunc, cloudsourceManifestFunc)
 		recorder := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(recorder)
 		bodyReader := strings.NewReader("")
@@ -210,3 +228,358 @@ func TestGetPipeline(t *testing.T) {
 		// assert.Equal(t, "{\"id\":\"\",\"repoSource\":\"\",\"repoOwner\":\"\",\"repoName\":\"\",\"repoBranch\":\"\",\"repoRevision\":\"\",\"buildStatus\":\"failed\",\"insertedAt\":\"0001-01-01T00:00:00Z\",\"updatedAt\":\"0001-01-01T00:00:00Z\",\"duration\":0,\"lastUpdatedAt\":\"0001-01-01T00:00:00Z\"}\n", string(body))
 	})
 }
+
+func TestDispatchPipelineBuild(t *testing.T) {
+
+	getHandler := func(buildService Service, githubManifestFunc func(context.Context, string, string, string, string, string) (string, bool, string, error)) Handler {
+		cockroachdbClient := cockroachdb.MockClient{
+			GetPipelineFunc: func(ctx context.Context, repoSource, repoOwner, repoName string, filters map[api.FilterType][]string, optimized bool) (pipeline *contracts.Pipeline, err error) {
+				return &contracts.Pipeline{
+					RepoSource: repoSource,
+					RepoOwner:  repoOwner,
+					RepoName:   repoName,
+					Groups:     []*contracts.Group{{ID: "5", Name: "team"}},
+				}, nil
+			},
+		}
+		secretHelper := crypt.NewSecretHelper("abc", false)
+		jobVarsFunc := func(context.Context, string, string, string) (string, string, error) {
+			return "", "", nil
+		}
+
+		return NewHandler("", &api.APIConfig{}, &api.APIConfig{}, cockroachdbClient, cloudstorage.MockClient{}, builderapi.MockClient{}, buildService, audit.MockService{}, api.NewWarningHelper(secretHelper, &api.APIConfig{}), secretHelper, jobVarsFunc, jobVarsFunc, jobVarsFunc, githubManifestFunc, nil, nil)
+	}
+
+	getContext := func(recorder *httptest.ResponseRecorder, body string, roles ...interface{}) *gin.Context {
+		if len(roles) == 0 {
+			roles = []interface{}{"administrator"}
+		}
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/pipelines/github.com/estafette/estafette-ci-api/dispatch", strings.NewReader(body))
+		c.Params = gin.Params{{Key: "source", Value: "github.com"}, {Key: "owner", Value: "estafette"}, {Key: "repo", Value: "estafette-ci-api"}}
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{jwt.IdentityKey: "15", "email": "me@estafette.io", "roles": roles})
+		return c
+	}
+
+	t.Run("CreatesManualBuildForHeadOfBranch", func(t *testing.T) {
+
+		var createdBuild contracts.Build
+		var createdParameters map[string]string
+		buildService := MockService{
+			CreateManualBuildFunc: func(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error) {
+				createdBuild = build
+				createdParameters = parameters
+				return &build, nil
+			},
+		}
+		githubManifestFunc := func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (string, bool, string, error) {
+			return "4e4bc3f36e3e30ed6ca2d1e3e1d3a98cb1a3f2ab", true, "stages: {}", nil
+		}
+		handler := getHandler(buildService, githubManifestFunc)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, `{"branch":"main","parameters":{"DEPLOY_REGION":"europe-west1"}}`)
+
+		// act
+		handler.DispatchPipelineBuild(c)
+
+		assert.Equal(t, http.StatusCreated, recorder.Result().StatusCode)
+		assert.Equal(t, "main", createdBuild.RepoBranch)
+		assert.Equal(t, "4e4bc3f36e3e30ed6ca2d1e3e1d3a98cb1a3f2ab", createdBuild.RepoRevision)
+		assert.Equal(t, "stages: {}", createdBuild.Manifest)
+		if assert.Equal(t, 1, len(createdBuild.Groups)) {
+			assert.Equal(t, "team", createdBuild.Groups[0].Name)
+		}
+		if assert.Equal(t, 1, len(createdBuild.Events)) && assert.NotNil(t, createdBuild.Events[0].Manual) {
+			assert.Equal(t, "me@estafette.io", createdBuild.Events[0].Manual.UserID)
+		}
+		assert.Equal(t, "europe-west1", createdParameters["DEPLOY_REGION"])
+	})
+
+	t.Run("ReturnsNotFoundIfRevisionDoesNotExist", func(t *testing.T) {
+
+		buildService := MockService{}
+		githubManifestFunc := func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (string, bool, string, error) {
+			return "", false, "", nil
+		}
+		handler := getHandler(buildService, githubManifestFunc)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, `{"branch":"main","revision":"abc123"}`)
+
+		// act
+		handler.DispatchPipelineBuild(c)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Result().StatusCode)
+	})
+
+	t.Run("ReturnsBadRequestIfRevisionIsNotOnBranch", func(t *testing.T) {
+
+		buildService := MockService{}
+		githubManifestFunc := func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (string, bool, string, error) {
+			return "", false, "", fmt.Errorf("Revision %v is not on branch %v: %w", revision, branch, api.ErrRevisionNotOnBranch)
+		}
+		handler := getHandler(buildService, githubManifestFunc)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, `{"branch":"main","revision":"abc123"}`)
+
+		// act
+		handler.DispatchPipelineBuild(c)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+	})
+
+	t.Run("ReturnsForbiddenWithoutBuildsCreatePermission", func(t *testing.T) {
+
+		dispatched := false
+		buildService := MockService{
+			CreateManualBuildFunc: func(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error) {
+				dispatched = true
+				return &build, nil
+			},
+		}
+		githubManifestFunc := func(ctx context.Context, repoSource, repoOwner, repoName, branch, revision string) (string, bool, string, error) {
+			return "4e4bc3f36e3e30ed6ca2d1e3e1d3a98cb1a3f2ab", true, "stages: {}", nil
+		}
+		handler := getHandler(buildService, githubManifestFunc)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, `{"branch":"main","parameters":{"DEPLOY_REGION":"europe-west1"}}`, "catalog.entities.viewer")
+
+		// act
+		handler.DispatchPipelineBuild(c)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Result().StatusCode)
+		assert.False(t, dispatched)
+	})
+
+	t.Run("ReturnsBadRequestForReservedParameterName", func(t *testing.T) {
+
+		buildService := MockService{}
+		handler := getHandler(buildService, nil)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, `{"branch":"main","parameters":{"ESTAFETTE_GIT_BRANCH":"other"}}`)
+
+		// act
+		handler.DispatchPipelineBuild(c)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+	})
+
+	t.Run("ReturnsBadRequestForSecretInParameterValue", func(t *testing.T) {
+
+		buildService := MockService{
+			CreateManualBuildFunc: func(ctx context.Context, build contracts.Build, parameters map[string]string) (b *contracts.Build, err error) {
+				assert.Fail(t, "Build shouldn't be dispatched with a secret in its parameters")
+				return &build, nil
+			},
+		}
+		handler := getHandler(buildService, nil)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, `{"branch":"main","parameters":{"DEPLOY_TOKEN":"estafette.secret(deFTz5Bdjg6SUe29.oPIkXbze5G9PNEWS2-ZnArl8BCqHnx4MdTdxHg37th9u)"}}`)
+
+		// act
+		handler.DispatchPipelineBuild(c)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+	})
+}
+
+func TestPostWebhookDelivery(t *testing.T) {
+
+	webhook := api.Webhook{
+		ID:            "12",
+		RepoSource:    "github.com",
+		RepoOwner:     "estafette",
+		RepoName:      "estafette-ci-api",
+		Name:          "vendor",
+		ReleaseAction: &manifest.EstafetteTriggerReleaseAction{Target: "production"},
+		Active:        true,
+	}
+	err := webhook.SetToken()
+	assert.Nil(t, err)
+
+	getHandler := func(buildService Service, insertedDeliveries chan api.WebhookDelivery) Handler {
+		cockroachdbClient := cockroachdb.MockClient{
+			GetWebhookByIDFunc: func(ctx context.Context, id string) (*api.Webhook, error) {
+				if id != webhook.ID {
+					return nil, cockroachdb.ErrWebhookNotFound
+				}
+				w := webhook
+				w.Token = ""
+				return &w, nil
+			},
+			InsertWebhookDeliveryFunc: func(ctx context.Context, delivery api.WebhookDelivery) (*api.WebhookDelivery, error) {
+				insertedDeliveries <- delivery
+				return &delivery, nil
+			},
+		}
+		secretHelper := crypt.NewSecretHelper("abc", false)
+
+		return NewHandler("", &api.APIConfig{}, &api.APIConfig{}, cockroachdbClient, cloudstorage.MockClient{}, builderapi.MockClient{}, buildService, audit.MockService{}, api.NewWarningHelper(secretHelper, &api.APIConfig{}), secretHelper, nil, nil, nil, nil, nil, nil)
+	}
+
+	getContext := func(recorder *httptest.ResponseRecorder, id, token, body string) *gin.Context {
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/integrations/webhooks/"+id, strings.NewReader(body))
+		c.Request.Header.Set("X-Estafette-Webhook-Token", token)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		return c
+	}
+
+	t.Run("FiresWebhookWithPayloadAsVariablesInBackground", func(t *testing.T) {
+
+		buildService := MockService{
+			FireWebhookTriggersFunc: func(ctx context.Context, w api.Webhook, variables map[string]string) error {
+				return nil
+			},
+		}
+		insertedDeliveries := make(chan api.WebhookDelivery, 1)
+		handler := getHandler(buildService, insertedDeliveries)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, webhook.ID, webhook.Token, `{"version":"1.2.3"}`)
+
+		// act
+		handler.PostWebhookDelivery(c)
+
+		assert.Equal(t, http.StatusAccepted, recorder.Result().StatusCode)
+		select {
+		case delivery := <-insertedDeliveries:
+			assert.Equal(t, api.WebhookDeliveryStatusFired, delivery.Status)
+			assert.Equal(t, "github.com", delivery.RepoSource)
+			assert.Equal(t, "1.2.3", delivery.Variables["ESTAFETTE_WEBHOOK_PAYLOAD_VERSION"])
+		case <-time.After(time.Second):
+			assert.Fail(t, "Webhook delivery wasn't recorded")
+		}
+	})
+
+	t.Run("RecordsFailedDelivery", func(t *testing.T) {
+
+		buildService := MockService{
+			FireWebhookTriggersFunc: func(ctx context.Context, w api.Webhook, variables map[string]string) error {
+				return ErrTriggerLoop
+			},
+		}
+		insertedDeliveries := make(chan api.WebhookDelivery, 1)
+		handler := getHandler(buildService, insertedDeliveries)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, webhook.ID, webhook.Token, ``)
+
+		// act
+		handler.PostWebhookDelivery(c)
+
+		assert.Equal(t, http.StatusAccepted, recorder.Result().StatusCode)
+		select {
+		case delivery := <-insertedDeliveries:
+			assert.Equal(t, api.WebhookDeliveryStatusFailed, delivery.Status)
+			assert.Equal(t, ErrTriggerLoop.Error(), delivery.Message)
+		case <-time.After(time.Second):
+			assert.Fail(t, "Webhook delivery wasn't recorded")
+		}
+	})
+
+	t.Run("ReturnsBadRequestForSecretInPayload", func(t *testing.T) {
+
+		buildService := MockService{
+			FireWebhookTriggersFunc: func(ctx context.Context, w api.Webhook, variables map[string]string) error {
+				assert.Fail(t, "Webhook shouldn't fire for a payload with a secret")
+				return nil
+			},
+		}
+		insertedDeliveries := make(chan api.WebhookDelivery, 1)
+		handler := getHandler(buildService, insertedDeliveries)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, webhook.ID, webhook.Token, `{"image":{"tag":"estafette.secret(deFTz5Bdjg6SUe29.oPIkXbze5G9PNEWS2-ZnArl8BCqHnx4MdTdxHg37th9u)"}}`)
+
+		// act
+		handler.PostWebhookDelivery(c)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Result().StatusCode)
+		if assert.Equal(t, 1, len(insertedDeliveries)) {
+			delivery := <-insertedDeliveries
+			assert.Equal(t, api.WebhookDeliveryStatusFailed, delivery.Status)
+			assert.Nil(t, delivery.Variables)
+		}
+	})
+
+	t.Run("ReturnsUnauthorizedForInvalidToken", func(t *testing.T) {
+
+		buildService := MockService{
+			FireWebhookTriggersFunc: func(ctx context.Context, w api.Webhook, variables map[string]string) error {
+				assert.Fail(t, "Webhook shouldn't fire for an invalid token")
+				return nil
+			},
+		}
+		insertedDeliveries := make(chan api.WebhookDelivery, 1)
+		handler := getHandler(buildService, insertedDeliveries)
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, webhook.ID, "invalid", `{}`)
+
+		// act
+		handler.PostWebhookDelivery(c)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Result().StatusCode)
+		assert.Equal(t, 0, len(insertedDeliveries))
+	})
+
+	t.Run("ReturnsUnauthorizedForTokenInQueryString", func(t *testing.T) {
+
+		buildService := MockService{
+			FireWebhookTriggersFunc: func(ctx context.Context, w api.Webhook, variables map[string]string) error {
+				assert.Fail(t, "Webhook shouldn't fire for a token in the query string")
+				return nil
+			},
+		}
+		insertedDeliveries := make(chan api.WebhookDelivery, 1)
+		handler := getHandler(buildService, insertedDeliveries)
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/integrations/webhooks/"+webhook.ID+"?token="+webhook.Token, strings.NewReader(`{}`))
+		c.Params = gin.Params{{Key: "id", Value: webhook.ID}}
+
+		// act
+		handler.PostWebhookDelivery(c)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Result().StatusCode)
+		assert.Equal(t, 0, len(insertedDeliveries))
+	})
+}
+
+func TestRotateConfigSecrets(t *testing.T) {
+
+	t.Run("RecordsAuditEvent", func(t *testing.T) {
+
+		configFile, err := ioutil.TempFile("", "config-*.yaml")
+		assert.Nil(t, err)
+		defer os.Remove(configFile.Name())
+		_, err = configFile.WriteString("integrations: {}\n")
+		assert.Nil(t, err)
+		configFile.Close()
+
+		var recordedEvents []api.AuditEvent
+		auditService := audit.MockService{
+			CreateAuditEventFunc: func(ctx context.Context, auditEvent api.AuditEvent) (*api.AuditEvent, error) {
+				recordedEvents = append(recordedEvents, auditEvent)
+				return &auditEvent, nil
+			},
+		}
+		secretHelper := crypt.NewSecretHelper("abc", false)
+		handler := NewHandler(configFile.Name(), &api.APIConfig{}, &api.APIConfig{}, cockroachdb.MockClient{}, cloudstorage.MockClient{}, builderapi.MockClient{}, MockService{}, auditService, api.NewWarningHelper(secretHelper, &api.APIConfig{}), secretHelper, nil, nil, nil, nil, nil, nil)
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/config/secrets/rotation", nil)
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"email":         "admin@estafette.io",
+			"roles":         []interface{}{"administrator"},
+		})
+
+		// act
+		handler.RotateConfigSecrets(c)
+
+		assert.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+		if assert.Equal(t, 1, len(recordedEvents)) {
+			assert.Equal(t, "config.secrets.rotate", recordedEvents[0].Action)
+			assert.Equal(t, "config", recordedEvents[0].TargetType)
+			assert.Equal(t, "5", recordedEvents[0].ActorID)
+		}
+	})
+}
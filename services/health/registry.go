@@ -0,0 +1,163 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+)
+
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// CheckFunc verifies a dependency of the api is available
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of checking a single dependency
+type Result struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Critical bool          `json:"critical"`
+	Latency  time.Duration `json:"latency"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Registry holds the health checks of the dependencies of the api and runs them on demand
+type Registry interface {
+	Register(name string, timeout time.Duration, critical bool, check CheckFunc)
+	Check(ctx context.Context, criticalOnly bool) []*Result
+}
+
+// NewRegistry returns a health.Registry; the timeout and criticality of registered checks can be overridden in config
+func NewRegistry(config *api.APIConfig) Registry {
+	return &registry{
+		config: config,
+	}
+}
+
+type registry struct {
+	config *api.APIConfig
+	checks []*check
+	mutex  sync.RWMutex
+}
+
+type check struct {
+	name     string
+	timeout  time.Duration
+	critical bool
+	run      CheckFunc
+}
+
+func (r *registry) Register(name string, timeout time.Duration, critical bool, run CheckFunc) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.checks = append(r.checks, &check{
+		name:     name,
+		timeout:  timeout,
+		critical: critical,
+		run:      run,
+	})
+}
+
+// Check runs the checks concurrently and returns their results in the order they were registered
+func (r *registry) Check(ctx context.Context, criticalOnly bool) []*Result {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	results := []*Result{}
+	var wg sync.WaitGroup
+	for _, c := range r.checks {
+		timeout, critical := r.getSettings(c)
+		if criticalOnly && !critical {
+			continue
+		}
+
+		result := &Result{
+			Name:     c.name,
+			Critical: critical,
+		}
+		results = append(results, result)
+
+		wg.Add(1)
+		go func(c *check, timeout time.Duration, result *Result) {
+			defer wg.Done()
+			r.runCheck(ctx, c, timeout, result)
+		}(c, timeout, result)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runCheck stops waiting for the check once its timeout expires, since not all clients pass the context on to their requests
+func (r *registry) runCheck(ctx context.Context, c *check, timeout time.Duration, result *Result) {
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	begin := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("Health check panicked: %v", rec)
+			}
+		}()
+		done <- c.run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("Health check timed out after %v", timeout)
+	}
+
+	result.Latency = time.Since(begin)
+	result.Status = StatusHealthy
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+}
+
+func (r *registry) getSettings(c *check) (timeout time.Duration, critical bool) {
+	timeout, critical = c.timeout, c.critical
+
+	if r.config == nil {
+		return
+	}
+
+	if checkConfig := r.config.Health.GetCheck(c.name); checkConfig != nil {
+		if checkConfig.TimeoutSeconds > 0 {
+			timeout = time.Duration(checkConfig.TimeoutSeconds) * time.Second
+		}
+		if checkConfig.Critical != nil {
+			critical = *checkConfig.Critical
+		}
+	}
+
+	return
+}
+
+// GetStatus returns unhealthy if any critical check failed, degraded if any other check failed and healthy otherwise
+func GetStatus(results []*Result) string {
+	status := StatusHealthy
+	for _, r := range results {
+		if r.Status == StatusHealthy {
+			continue
+		}
+		if r.Critical {
+			return StatusUnhealthy
+		}
+		status = StatusDegraded
+	}
+
+	return status
+}
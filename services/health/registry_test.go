@@ -0,0 +1,125 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+
+	t.Run("ReturnsResultPerCheckInRegistrationOrder", func(t *testing.T) {
+
+		registry := NewRegistry(&api.APIConfig{})
+		registry.Register("cockroachdb", time.Second, true, func(ctx context.Context) error { return nil })
+		registry.Register("githubapi", time.Second, false, func(ctx context.Context) error { return errors.New("Bad credentials") })
+
+		// act
+		results := registry.Check(context.Background(), false)
+
+		if assert.Equal(t, 2, len(results)) {
+			assert.Equal(t, "cockroachdb", results[0].Name)
+			assert.Equal(t, StatusHealthy, results[0].Status)
+			assert.True(t, results[0].Critical)
+			assert.Equal(t, "githubapi", results[1].Name)
+			assert.Equal(t, StatusUnhealthy, results[1].Status)
+			assert.Equal(t, "Bad credentials", results[1].Error)
+		}
+	})
+
+	t.Run("OnlyRunsCriticalChecksIfCriticalOnlyIsTrue", func(t *testing.T) {
+
+		registry := NewRegistry(&api.APIConfig{})
+		registry.Register("cockroachdb", time.Second, true, func(ctx context.Context) error { return nil })
+		registry.Register("githubapi", time.Second, false, func(ctx context.Context) error { return nil })
+
+		// act
+		results := registry.Check(context.Background(), true)
+
+		if assert.Equal(t, 1, len(results)) {
+			assert.Equal(t, "cockroachdb", results[0].Name)
+		}
+	})
+
+	t.Run("ReturnsUnhealthyForCheckExceedingItsTimeout", func(t *testing.T) {
+
+		registry := NewRegistry(&api.APIConfig{})
+		registry.Register("prometheus", 10*time.Millisecond, false, func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+		// act
+		results := registry.Check(context.Background(), false)
+
+		if assert.Equal(t, 1, len(results)) {
+			assert.Equal(t, StatusUnhealthy, results[0].Status)
+			assert.True(t, results[0].Latency < time.Second)
+		}
+	})
+
+	t.Run("ReturnsUnhealthyForPanickingCheck", func(t *testing.T) {
+
+		registry := NewRegistry(&api.APIConfig{})
+		registry.Register("bitbucketapi", time.Second, false, func(ctx context.Context) error {
+			panic("nil config")
+		})
+
+		// act
+		results := registry.Check(context.Background(), false)
+
+		if assert.Equal(t, 1, len(results)) {
+			assert.Equal(t, StatusUnhealthy, results[0].Status)
+		}
+	})
+
+	t.Run("AppliesConfiguredCriticality", func(t *testing.T) {
+
+		critical := true
+		config := &api.APIConfig{
+			Health: &api.HealthConfig{
+				Checks: []*api.HealthCheckConfig{
+					{Name: "githubapi", Critical: &critical},
+				},
+			},
+		}
+
+		registry := NewRegistry(config)
+		registry.Register("githubapi", time.Second, false, func(ctx context.Context) error { return nil })
+
+		// act
+		results := registry.Check(context.Background(), true)
+
+		if assert.Equal(t, 1, len(results)) {
+			assert.True(t, results[0].Critical)
+		}
+	})
+}
+
+func TestGetStatus(t *testing.T) {
+
+	t.Run("ReturnsHealthyIfAllChecksAreHealthy", func(t *testing.T) {
+
+		status := GetStatus([]*Result{{Status: StatusHealthy, Critical: true}, {Status: StatusHealthy}})
+
+		assert.Equal(t, StatusHealthy, status)
+	})
+
+	t.Run("ReturnsDegradedIfANonCriticalCheckIsUnhealthy", func(t *testing.T) {
+
+		status := GetStatus([]*Result{{Status: StatusHealthy, Critical: true}, {Status: StatusUnhealthy}})
+
+		assert.Equal(t, StatusDegraded, status)
+	})
+
+	t.Run("ReturnsUnhealthyIfACriticalCheckIsUnhealthy", func(t *testing.T) {
+
+		status := GetStatus([]*Result{{Status: StatusUnhealthy}, {Status: StatusUnhealthy, Critical: true}})
+
+		assert.Equal(t, StatusUnhealthy, status)
+	})
+}
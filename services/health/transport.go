@@ -0,0 +1,66 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// NewHandler returns a health.Handler
+func NewHandler(registry Registry) Handler {
+	return Handler{
+		registry: registry,
+	}
+}
+
+type Handler struct {
+	registry Registry
+}
+
+// GetLiveness doesn't check any dependencies, since restarting the api doesn't fix those
+func (h *Handler) GetLiveness(c *gin.Context) {
+	c.String(http.StatusOK, "I'm alive!")
+}
+
+// GetReadiness takes the api out of rotation while any of the critical dependencies is unhealthy
+func (h *Handler) GetReadiness(c *gin.Context) {
+
+	results := h.registry.Check(c.Request.Context(), true)
+
+	if GetStatus(results) == StatusUnhealthy {
+		for _, r := range results {
+			if r.Status != StatusHealthy {
+				log.Warn().Str("check", r.Name).Dur("latency", r.Latency).Msgf("Critical dependency is unhealthy: %v", r.Error)
+			}
+		}
+		c.String(http.StatusServiceUnavailable, "I'm not ready!")
+		return
+	}
+
+	c.String(http.StatusOK, "I'm ready!")
+}
+
+// GetHealth reports the status and latency of every dependency
+func (h *Handler) GetHealth(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionHealthGet) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	results := h.registry.Check(c.Request.Context(), false)
+
+	status := GetStatus(results)
+	statusCode := http.StatusOK
+	if status == StatusUnhealthy {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.JSON(statusCode, gin.H{
+		"status": status,
+		"checks": results,
+	})
+}
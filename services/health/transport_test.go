@@ -0,0 +1,50 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetReadiness(t *testing.T) {
+
+	getContext := func(recorder *httptest.ResponseRecorder) *gin.Context {
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("GET", "https://ci.estafette.io/readiness", nil)
+		return c
+	}
+
+	t.Run("ReturnsOKIfOnlyNonCriticalChecksFail", func(t *testing.T) {
+
+		registry := NewRegistry(&api.APIConfig{})
+		registry.Register("cockroachdb", time.Second, true, func(ctx context.Context) error { return nil })
+		registry.Register("githubapi", time.Second, false, func(ctx context.Context) error { return errors.New("Bad credentials") })
+		handler := NewHandler(registry)
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.GetReadiness(getContext(recorder))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("ReturnsServiceUnavailableIfACriticalCheckFails", func(t *testing.T) {
+
+		registry := NewRegistry(&api.APIConfig{})
+		registry.Register("cockroachdb", time.Second, true, func(ctx context.Context) error { return errors.New("connection refused") })
+		handler := NewHandler(registry)
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.GetReadiness(getContext(recorder))
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+}
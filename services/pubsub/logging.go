@@ -0,0 +1,31 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+// NewLoggingService returns a new instance of a logging Service.
+func NewLoggingService(s Service) Service {
+	return &loggingService{s, "pubsub"}
+}
+
+type loggingService struct {
+	Service
+	prefix string
+}
+
+func (s *loggingService) ReconcileSubscriptions(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	defer func() { api.HandleLogError(s.prefix, "ReconcileSubscriptions", err) }()
+
+	return s.Service.ReconcileSubscriptions(ctx)
+}
+
+func (s *loggingService) GetPipelineSubscriptionStatuses(ctx context.Context, pipeline contracts.Pipeline) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetPipelineSubscriptionStatuses", err) }()
+
+	return s.Service.GetPipelineSubscriptionStatuses(ctx, pipeline)
+}
@@ -0,0 +1,38 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/go-kit/kit/metrics"
+)
+
+// NewMetricsService returns a new instance of a metrics Service.
+func NewMetricsService(s Service, requestCount metrics.Counter, requestLatency metrics.Histogram) Service {
+	return &metricsService{s, requestCount, requestLatency}
+}
+
+type metricsService struct {
+	Service
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+func (s *metricsService) ReconcileSubscriptions(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "ReconcileSubscriptions", begin)
+	}(time.Now())
+
+	return s.Service.ReconcileSubscriptions(ctx)
+}
+
+func (s *metricsService) GetPipelineSubscriptionStatuses(ctx context.Context, pipeline contracts.Pipeline) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetPipelineSubscriptionStatuses", begin)
+	}(time.Now())
+
+	return s.Service.GetPipelineSubscriptionStatuses(ctx, pipeline)
+}
@@ -0,0 +1,27 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+type MockService struct {
+	ReconcileSubscriptionsFunc          func(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error)
+	GetPipelineSubscriptionStatusesFunc func(ctx context.Context, pipeline contracts.Pipeline) (statuses []*pubsubapi.SubscriptionStatus, err error)
+}
+
+func (s MockService) ReconcileSubscriptions(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	if s.ReconcileSubscriptionsFunc == nil {
+		return
+	}
+	return s.ReconcileSubscriptionsFunc(ctx)
+}
+
+func (s MockService) GetPipelineSubscriptionStatuses(ctx context.Context, pipeline contracts.Pipeline) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	if s.GetPipelineSubscriptionStatusesFunc == nil {
+		return
+	}
+	return s.GetPipelineSubscriptionStatusesFunc(ctx, pipeline)
+}
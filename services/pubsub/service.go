@@ -0,0 +1,275 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	subscriptionStatusHealthy = "healthy"
+	subscriptionStatusCreated = "created"
+	subscriptionStatusMissing = "missing"
+	subscriptionStatusFailed  = "failed"
+	subscriptionStatusDeleted = "deleted"
+)
+
+var (
+	// ErrReconcileInProgress is returned if subscriptions are reconciled while a previous reconciliation is still running
+	ErrReconcileInProgress = errors.New("Reconciling subscriptions is already in progress")
+)
+
+// Service keeps the pubsub subscriptions in line with the pubsub triggers of all active pipelines
+type Service interface {
+	ReconcileSubscriptions(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error)
+	GetPipelineSubscriptionStatuses(ctx context.Context, pipeline contracts.Pipeline) (statuses []*pubsubapi.SubscriptionStatus, err error)
+}
+
+// NewService returns a new pubsub.Service
+func NewService(config *api.APIConfig, pubsubapiClient pubsubapi.Client, cockroachdbClient cockroachdb.Client) Service {
+	return &service{
+		config:            config,
+		pubsubapiClient:   pubsubapiClient,
+		cockroachdbClient: cockroachdbClient,
+		reconciling:       make(chan struct{}, 1),
+	}
+}
+
+type service struct {
+	config            *api.APIConfig
+	pubsubapiClient   pubsubapi.Client
+	cockroachdbClient cockroachdb.Client
+
+	// reconciling holds a token while subscriptions are reconciled
+	reconciling chan struct{}
+}
+
+// ReconcileSubscriptions creates the subscriptions missing for pubsub triggers of active pipelines and deletes the ones no longer used by any trigger; deleted subscriptions are returned with status deleted and without pipeline
+func (s *service) ReconcileSubscriptions(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+
+	// the periodic reconciliation and the admin endpoint can overlap, so only let one of them run at a time
+	select {
+	case s.reconciling <- struct{}{}:
+		defer func() { <-s.reconciling }()
+	default:
+		return nil, ErrReconcileInProgress
+	}
+
+	// archived pipelines are left out, so their subscriptions get deleted
+	pipelines, err := s.cockroachdbClient.GetPubSubTriggers(ctx, manifest.EstafettePubSubEvent{})
+	if err != nil {
+		return
+	}
+
+	subscriptions, err := s.pubsubapiClient.GetSubscriptions(ctx)
+	if err != nil {
+		return
+	}
+
+	statuses = make([]*pubsubapi.SubscriptionStatus, 0)
+
+	// multiple pipelines can trigger on the same topic, so each topic is only handled once
+	topicStatuses := map[string]pubsubapi.SubscriptionStatus{}
+
+	for _, p := range pipelines {
+		for _, t := range p.Triggers {
+			if t.PubSub == nil {
+				continue
+			}
+
+			key := getTopicKey(t.PubSub.Project, t.PubSub.Topic)
+			topicStatus, ok := topicStatuses[key]
+			if !ok {
+				topicStatus = s.ensureSubscription(ctx, t.PubSub.Project, t.PubSub.Topic, subscriptions)
+				topicStatuses[key] = topicStatus
+			}
+
+			status := topicStatus
+			status.RepoSource = p.RepoSource
+			status.RepoOwner = p.RepoOwner
+			status.RepoName = p.RepoName
+			statuses = append(statuses, &status)
+
+			if status.Status == subscriptionStatusFailed {
+				s.recordWarning(ctx, *p, status)
+			}
+		}
+	}
+
+	// delete subscriptions for topics no pipeline triggers on anymore
+	orphanedSubscriptions := []*pubsubapi.Subscription{}
+	for _, subscription := range subscriptions {
+		if _, ok := topicStatuses[getTopicKey(subscription.TopicProject, subscription.Topic)]; !ok {
+			orphanedSubscriptions = append(orphanedSubscriptions, subscription)
+		}
+	}
+	if len(orphanedSubscriptions) == 0 {
+		return statuses, nil
+	}
+
+	// other replicas reconcile as well and pipelines can have changed in the meantime, so check the current triggers before deleting anything
+	currentPipelines, err := s.cockroachdbClient.GetPubSubTriggers(ctx, manifest.EstafettePubSubEvent{})
+	if err != nil {
+		return
+	}
+	currentTopics := getTriggerTopicKeys(currentPipelines)
+
+	for _, subscription := range orphanedSubscriptions {
+		if _, ok := currentTopics[getTopicKey(subscription.TopicProject, subscription.Topic)]; ok {
+			continue
+		}
+
+		err := s.pubsubapiClient.DeleteSubscription(ctx, subscription.Project, subscription.Name)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed deleting orphaned subscription %v in project %v", subscription.Name, subscription.Project)
+			continue
+		}
+
+		statuses = append(statuses, &pubsubapi.SubscriptionStatus{
+			TopicProject: subscription.TopicProject,
+			Topic:        subscription.Topic,
+			Subscription: subscription.Name,
+			Status:       subscriptionStatusDeleted,
+		})
+	}
+
+	return statuses, nil
+}
+
+// GetPipelineSubscriptionStatuses returns whether the subscriptions for the pipeline's pubsub triggers exist
+func (s *service) GetPipelineSubscriptionStatuses(ctx context.Context, pipeline contracts.Pipeline) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+
+	statuses = make([]*pubsubapi.SubscriptionStatus, 0)
+
+	hasPubSubTriggers := false
+	for _, t := range pipeline.Triggers {
+		if t.PubSub != nil {
+			hasPubSubTriggers = true
+			break
+		}
+	}
+	if !hasPubSubTriggers {
+		return statuses, nil
+	}
+
+	subscriptions, err := s.pubsubapiClient.GetSubscriptions(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, t := range pipeline.Triggers {
+		if t.PubSub == nil {
+			continue
+		}
+
+		status := &pubsubapi.SubscriptionStatus{
+			RepoSource:   pipeline.RepoSource,
+			RepoOwner:    pipeline.RepoOwner,
+			RepoName:     pipeline.RepoName,
+			TopicProject: t.PubSub.Project,
+			Topic:        t.PubSub.Topic,
+			Status:       subscriptionStatusMissing,
+			Message:      "The subscription gets created when subscriptions are reconciled next",
+		}
+
+		if subscription := getSubscriptionForTopic(subscriptions, t.PubSub.Project, t.PubSub.Topic); subscription != nil {
+			status.Subscription = subscription.Name
+			status.Status = subscriptionStatusHealthy
+			status.Message = ""
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+func (s *service) ensureSubscription(ctx context.Context, projectID, topicID string, subscriptions []*pubsubapi.Subscription) pubsubapi.SubscriptionStatus {
+
+	status := pubsubapi.SubscriptionStatus{
+		TopicProject: projectID,
+		Topic:        topicID,
+	}
+
+	if subscription := getSubscriptionForTopic(subscriptions, projectID, topicID); subscription != nil {
+		status.Subscription = subscription.Name
+		status.Status = subscriptionStatusHealthy
+		return status
+	}
+
+	err := s.pubsubapiClient.SubscribeToTopic(ctx, projectID, topicID)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed subscribing to topic %v in project %v", topicID, projectID)
+		status.Status = subscriptionStatusFailed
+		status.Message = err.Error()
+		return status
+	}
+
+	status.Status = subscriptionStatusCreated
+
+	return status
+}
+
+// recordWarning shows the failed subscription as warning for the pipeline
+func (s *service) recordWarning(ctx context.Context, pipeline contracts.Pipeline, status pubsubapi.SubscriptionStatus) {
+	err := s.cockroachdbClient.InsertPipelineWarning(ctx, pipeline.RepoSource, pipeline.RepoOwner, pipeline.RepoName, contracts.Warning{
+		Status:  "danger",
+		Message: fmt.Sprintf("Subscribing to Pub/Sub topic **%v** in project **%v** for this pipeline's pubsub trigger failed: %v", status.Topic, status.TopicProject, status.Message),
+	})
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed recording subscription warning for pipeline %v/%v/%v", pipeline.RepoSource, pipeline.RepoOwner, pipeline.RepoName)
+	}
+}
+
+// getSubscriptionChanges returns the subscriptions created or deleted by a reconciliation, once per topic
+func getSubscriptionChanges(statuses []*pubsubapi.SubscriptionStatus) (changes []*pubsubapi.SubscriptionStatus) {
+
+	changedTopics := map[string]bool{}
+	for _, status := range statuses {
+		if status.Status != subscriptionStatusCreated && status.Status != subscriptionStatusDeleted {
+			continue
+		}
+
+		key := getTopicKey(status.TopicProject, status.Topic)
+		if !changedTopics[key] {
+			changedTopics[key] = true
+			changes = append(changes, status)
+		}
+	}
+
+	return
+}
+
+func getSubscriptionForTopic(subscriptions []*pubsubapi.Subscription, projectID, topicID string) *pubsubapi.Subscription {
+	for _, s := range subscriptions {
+		if s.TopicProject == projectID && s.Topic == topicID {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func getTriggerTopicKeys(pipelines []*contracts.Pipeline) map[string]bool {
+	topicKeys := map[string]bool{}
+	for _, p := range pipelines {
+		for _, t := range p.Triggers {
+			if t.PubSub != nil {
+				topicKeys[getTopicKey(t.PubSub.Project, t.PubSub.Topic)] = true
+			}
+		}
+	}
+
+	return topicKeys
+}
+
+func getTopicKey(projectID, topicID string) string {
+	return fmt.Sprintf("projects/%v/topics/%v", projectID, topicID)
+}
@@ -0,0 +1,261 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileSubscriptions(t *testing.T) {
+
+	t.Run("CreatesMissingAndDeletesOrphanedSubscriptions", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{}
+		cockroachdbClient := cockroachdb.MockClient{}
+		pubsubapiClient := pubsubapi.MockClient{}
+
+		cockroachdbClient.GetPubSubTriggersFunc = func(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-a",
+					Triggers: []manifest.EstafetteTrigger{
+						{PubSub: &manifest.EstafettePubSubTrigger{Project: "project-a", Topic: "existing-topic"}, BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"}},
+						{PubSub: &manifest.EstafettePubSubTrigger{Project: "project-a", Topic: "new-topic"}, BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"}},
+					},
+				},
+			}, nil
+		}
+		pubsubapiClient.GetSubscriptionsFunc = func(ctx context.Context) (subscriptions []*pubsubapi.Subscription, err error) {
+			return []*pubsubapi.Subscription{
+				{Project: "default", Name: "existing-topic~estafette", TopicProject: "project-a", Topic: "existing-topic"},
+				{Project: "default", Name: "removed-topic~estafette", TopicProject: "project-a", Topic: "removed-topic"},
+			}, nil
+		}
+
+		var subscribedTopics []string
+		pubsubapiClient.SubscribeToTopicFunc = func(ctx context.Context, projectID, topicID string) (err error) {
+			subscribedTopics = append(subscribedTopics, topicID)
+			return
+		}
+		var deletedSubscriptions []string
+		pubsubapiClient.DeleteSubscriptionFunc = func(ctx context.Context, projectID, subscriptionName string) (err error) {
+			deletedSubscriptions = append(deletedSubscriptions, subscriptionName)
+			return
+		}
+
+		service := NewService(config, pubsubapiClient, cockroachdbClient)
+
+		// act
+		statuses, err := service.ReconcileSubscriptions(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"new-topic"}, subscribedTopics)
+		assert.Equal(t, []string{"removed-topic~estafette"}, deletedSubscriptions)
+		if assert.Equal(t, 3, len(statuses)) {
+			assert.Equal(t, "healthy", statuses[0].Status)
+			assert.Equal(t, "created", statuses[1].Status)
+			assert.Equal(t, "repo-a", statuses[1].RepoName)
+			assert.Equal(t, "deleted", statuses[2].Status)
+			assert.Equal(t, "removed-topic~estafette", statuses[2].Subscription)
+		}
+	})
+
+	t.Run("RecordsWarningForEachPipelineIfSubscribingFails", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{}
+		cockroachdbClient := cockroachdb.MockClient{}
+		pubsubapiClient := pubsubapi.MockClient{}
+
+		cockroachdbClient.GetPubSubTriggersFunc = func(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (pipelines []*contracts.Pipeline, err error) {
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-a",
+					Triggers:   []manifest.EstafetteTrigger{{PubSub: &manifest.EstafettePubSubTrigger{Project: "project-a", Topic: "missing-topic"}, BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"}}},
+				},
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-b",
+					Triggers:   []manifest.EstafetteTrigger{{PubSub: &manifest.EstafettePubSubTrigger{Project: "project-a", Topic: "missing-topic"}, BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"}}},
+				},
+			}, nil
+		}
+
+		subscribeCallCount := 0
+		pubsubapiClient.SubscribeToTopicFunc = func(ctx context.Context, projectID, topicID string) (err error) {
+			subscribeCallCount++
+			return fmt.Errorf("Pub/Sub topic %v does not exist in project %v, cannot subscribe to it", topicID, projectID)
+		}
+		var warnedPipelines []string
+		cockroachdbClient.InsertPipelineWarningFunc = func(ctx context.Context, repoSource, repoOwner, repoName string, warning contracts.Warning) (err error) {
+			warnedPipelines = append(warnedPipelines, repoName)
+			return
+		}
+
+		service := NewService(config, pubsubapiClient, cockroachdbClient)
+
+		// act
+		statuses, err := service.ReconcileSubscriptions(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, subscribeCallCount)
+		assert.Equal(t, []string{"repo-a", "repo-b"}, warnedPipelines)
+		if assert.Equal(t, 2, len(statuses)) {
+			assert.Equal(t, "failed", statuses[0].Status)
+			assert.Equal(t, "failed", statuses[1].Status)
+		}
+	})
+
+	t.Run("KeepsSubscriptionForTopicTriggeredSinceStartOfReconciliation", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{}
+		cockroachdbClient := cockroachdb.MockClient{}
+		pubsubapiClient := pubsubapi.MockClient{}
+
+		// the second call returns a pipeline that started triggering on the topic in the meantime
+		getPubSubTriggersCallCount := 0
+		cockroachdbClient.GetPubSubTriggersFunc = func(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (pipelines []*contracts.Pipeline, err error) {
+			getPubSubTriggersCallCount++
+			if getPubSubTriggersCallCount == 1 {
+				return []*contracts.Pipeline{}, nil
+			}
+			return []*contracts.Pipeline{
+				{
+					RepoSource: "github.com",
+					RepoOwner:  "estafette",
+					RepoName:   "repo-a",
+					Triggers:   []manifest.EstafetteTrigger{{PubSub: &manifest.EstafettePubSubTrigger{Project: "project-a", Topic: "new-topic"}, BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"}}},
+				},
+			}, nil
+		}
+		pubsubapiClient.GetSubscriptionsFunc = func(ctx context.Context) (subscriptions []*pubsubapi.Subscription, err error) {
+			return []*pubsubapi.Subscription{
+				{Project: "default", Name: "new-topic~estafette", TopicProject: "project-a", Topic: "new-topic"},
+				{Project: "default", Name: "removed-topic~estafette", TopicProject: "project-a", Topic: "removed-topic"},
+			}, nil
+		}
+		var deletedSubscriptions []string
+		pubsubapiClient.DeleteSubscriptionFunc = func(ctx context.Context, projectID, subscriptionName string) (err error) {
+			deletedSubscriptions = append(deletedSubscriptions, subscriptionName)
+			return
+		}
+
+		service := NewService(config, pubsubapiClient, cockroachdbClient)
+
+		// act
+		_, err := service.ReconcileSubscriptions(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"removed-topic~estafette"}, deletedSubscriptions)
+	})
+
+	t.Run("ReturnsErrorIfReconciliationIsInProgress", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{}
+		cockroachdbClient := cockroachdb.MockClient{}
+		pubsubapiClient := pubsubapi.MockClient{}
+
+		var service Service
+		var innerErr error
+		cockroachdbClient.GetPubSubTriggersFunc = func(ctx context.Context, pubsubEvent manifest.EstafettePubSubEvent) (pipelines []*contracts.Pipeline, err error) {
+			if innerErr == nil {
+				_, innerErr = service.ReconcileSubscriptions(ctx)
+			}
+			return []*contracts.Pipeline{}, nil
+		}
+
+		service = NewService(config, pubsubapiClient, cockroachdbClient)
+
+		// act
+		_, err := service.ReconcileSubscriptions(ctx)
+
+		assert.Nil(t, err)
+		assert.True(t, errors.Is(innerErr, ErrReconcileInProgress))
+
+		_, err = service.ReconcileSubscriptions(ctx)
+		assert.Nil(t, err)
+	})
+}
+
+func TestGetSubscriptionChanges(t *testing.T) {
+
+	t.Run("ReturnsCreatedAndDeletedSubscriptionsOncePerTopic", func(t *testing.T) {
+
+		statuses := []*pubsubapi.SubscriptionStatus{
+			{RepoName: "repo-a", TopicProject: "project-a", Topic: "existing-topic", Status: "healthy"},
+			{RepoName: "repo-a", TopicProject: "project-a", Topic: "new-topic", Status: "created"},
+			{RepoName: "repo-b", TopicProject: "project-a", Topic: "new-topic", Status: "created"},
+			{RepoName: "repo-c", TopicProject: "project-a", Topic: "failing-topic", Status: "failed"},
+			{TopicProject: "project-a", Topic: "removed-topic", Subscription: "removed-topic~estafette", Status: "deleted"},
+		}
+
+		// act
+		changes := getSubscriptionChanges(statuses)
+
+		if assert.Equal(t, 2, len(changes)) {
+			assert.Equal(t, "new-topic", changes[0].Topic)
+			assert.Equal(t, "repo-a", changes[0].RepoName)
+			assert.Equal(t, "removed-topic", changes[1].Topic)
+		}
+	})
+}
+
+func TestGetPipelineSubscriptionStatuses(t *testing.T) {
+
+	t.Run("ReturnsMissingForTopicsWithoutSubscription", func(t *testing.T) {
+
+		ctx := context.Background()
+
+		config := &api.APIConfig{}
+		cockroachdbClient := cockroachdb.MockClient{}
+		pubsubapiClient := pubsubapi.MockClient{}
+
+		pubsubapiClient.GetSubscriptionsFunc = func(ctx context.Context) (subscriptions []*pubsubapi.Subscription, err error) {
+			return []*pubsubapi.Subscription{
+				{Project: "default", Name: "existing-topic~estafette", TopicProject: "project-a", Topic: "existing-topic"},
+			}, nil
+		}
+
+		service := NewService(config, pubsubapiClient, cockroachdbClient)
+
+		pipeline := contracts.Pipeline{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "repo-a",
+			Triggers: []manifest.EstafetteTrigger{
+				{PubSub: &manifest.EstafettePubSubTrigger{Project: "project-a", Topic: "existing-topic"}, BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"}},
+				{PubSub: &manifest.EstafettePubSubTrigger{Project: "project-b", Topic: "existing-topic"}, BuildAction: &manifest.EstafetteTriggerBuildAction{Branch: "master"}},
+			},
+		}
+
+		// act
+		statuses, err := service.GetPipelineSubscriptionStatuses(ctx, pipeline)
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 2, len(statuses)) {
+			assert.Equal(t, "healthy", statuses[0].Status)
+			assert.Equal(t, "existing-topic~estafette", statuses[0].Subscription)
+			assert.Equal(t, "missing", statuses[1].Status)
+		}
+	})
+}
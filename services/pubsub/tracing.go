@@ -0,0 +1,34 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/opentracing/opentracing-go"
+)
+
+// NewTracingService returns a new instance of a tracing Service.
+func NewTracingService(s Service) Service {
+	return &tracingService{s, "pubsub"}
+}
+
+type tracingService struct {
+	Service
+	prefix string
+}
+
+func (s *tracingService) ReconcileSubscriptions(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "ReconcileSubscriptions"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.ReconcileSubscriptions(ctx)
+}
+
+func (s *tracingService) GetPipelineSubscriptionStatuses(ctx context.Context, pipeline contracts.Pipeline) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetPipelineSubscriptionStatuses"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetPipelineSubscriptionStatuses(ctx, pipeline)
+}
@@ -1,26 +1,38 @@
 package pubsub
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
 	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	"github.com/estafette/estafette-ci-api/services/audit"
 	"github.com/estafette/estafette-ci-api/services/estafette"
+	manifest "github.com/estafette/estafette-ci-manifest"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
 // NewHandler returns a pubsub.Handler
-func NewHandler(pubsubapiClient pubsubapi.Client, estafetteService estafette.Service) Handler {
+func NewHandler(pubsubapiClient pubsubapi.Client, estafetteService estafette.Service, service Service, cockroachdbClient cockroachdb.Client, auditService audit.Service) Handler {
 	return Handler{
-		pubsubapiClient:  pubsubapiClient,
-		estafetteService: estafetteService,
+		pubsubapiClient:   pubsubapiClient,
+		estafetteService:  estafetteService,
+		service:           service,
+		cockroachdbClient: cockroachdbClient,
+		auditService:      auditService,
 	}
 }
 
 type Handler struct {
-	pubsubapiClient  pubsubapi.Client
-	estafetteService estafette.Service
+	pubsubapiClient   pubsubapi.Client
+	estafetteService  estafette.Service
+	service           Service
+	cockroachdbClient cockroachdb.Client
+	auditService      audit.Service
 }
 
 func (eh *Handler) PostPubsubEvent(c *gin.Context) {
@@ -59,13 +71,91 @@ func (eh *Handler) PostPubsubEvent(c *gin.Context) {
 		Str("topic", pubsubEvent.Topic).
 		Msg("Successfully binded pubsub push event")
 
-	err = eh.estafetteService.FirePubSubTriggers(c.Request.Context(), *pubsubEvent)
+	// acknowledge the message right away and fire the triggers in the background, since pacing their fan-out can take longer than pubsub waits for a push response
+	go func(pubsubEvent manifest.EstafettePubSubEvent) {
+		err := eh.estafetteService.FirePubSubTriggers(context.Background(), pubsubEvent)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed firing pubsub triggers for topic %v in project %v", pubsubEvent.Topic, pubsubEvent.Project)
+		}
+	}(*pubsubEvent)
+
+	c.String(http.StatusOK, "Aye aye!")
+	return
+}
+
+func (eh *Handler) GetPipelineSubscriptions(c *gin.Context) {
+
+	source := c.Param("source")
+	owner := c.Param("owner")
+	repo := c.Param("repo")
+
+	pipeline, err := eh.cockroachdbClient.GetPipeline(c.Request.Context(), source, owner, repo, map[api.FilterType][]string{}, false)
 	if err != nil {
-		log.Error().Err(err).Msgf("Failed firing pubsub triggers for topic %v in project %v", pubsubEvent.Topic, pubsubEvent.Project)
-		c.String(http.StatusInternalServerError, "Oop, something's wrong!")
+		log.Error().Err(err).Msgf("Failed retrieving pipeline for %v/%v/%v from db", source, owner, repo)
+	}
+	if pipeline == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound), "message": "Pipeline not found"})
 		return
 	}
 
-	c.String(http.StatusOK, "Aye aye!")
-	return
+	statuses, err := eh.service.GetPipelineSubscriptionStatuses(c.Request.Context(), *pipeline)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Failed retrieving subscriptions for pipeline %v/%v/%v", source, owner, repo)
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": statuses})
+}
+
+func (eh *Handler) ReconcileSubscriptions(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionPipelinesUpdate) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	statuses, err := eh.service.ReconcileSubscriptions(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, ErrReconcileInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"code": http.StatusText(http.StatusConflict), "message": err.Error()})
+			return
+		}
+		errorMessage := fmt.Sprint("Failed reconciling pubsub subscriptions")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError), "message": errorMessage})
+		return
+	}
+
+	for _, change := range getSubscriptionChanges(statuses) {
+		before, after := getSubscriptionAuditStates(change)
+		audit.RecordRequestEvent(c, eh.auditService, api.PermissionPipelinesUpdate, "pubsub-subscription", getTopicKey(change.TopicProject, change.Topic), before, after)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": statuses})
+}
+
+// RecordReconciledSubscriptions stores the subscriptions created or deleted by a periodic reconciliation in the audit log
+func RecordReconciledSubscriptions(ctx context.Context, auditService audit.Service, statuses []*pubsubapi.SubscriptionStatus) {
+	for _, change := range getSubscriptionChanges(statuses) {
+		before, after := getSubscriptionAuditStates(change)
+		audit.RecordEvent(ctx, auditService, api.AuditEvent{
+			Action:     api.PermissionPipelinesUpdate.String(),
+			TargetType: "pubsub-subscription",
+			TargetID:   getTopicKey(change.TopicProject, change.Topic),
+			Before:     before,
+			After:      after,
+		})
+	}
+}
+
+func getSubscriptionAuditStates(change *pubsubapi.SubscriptionStatus) (before, after interface{}) {
+	state := gin.H{"topicProject": change.TopicProject, "topic": change.Topic, "subscription": change.Subscription}
+	if change.Status == subscriptionStatusDeleted {
+		return state, nil
+	}
+
+	return nil, state
 }
@@ -0,0 +1,65 @@
+package pubsub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/clients/pubsubapi"
+	"github.com/estafette/estafette-ci-api/services/audit"
+	"github.com/estafette/estafette-ci-api/services/estafette"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerReconcileSubscriptions(t *testing.T) {
+
+	t.Run("RecordsAuditEventPerCreatedOrDeletedSubscription", func(t *testing.T) {
+
+		service := MockService{
+			ReconcileSubscriptionsFunc: func(ctx context.Context) (statuses []*pubsubapi.SubscriptionStatus, err error) {
+				return []*pubsubapi.SubscriptionStatus{
+					{RepoName: "repo-a", TopicProject: "project-a", Topic: "existing-topic", Subscription: "existing-topic~estafette", Status: "healthy"},
+					{RepoName: "repo-a", TopicProject: "project-a", Topic: "new-topic", Status: "created"},
+					{TopicProject: "project-a", Topic: "removed-topic", Subscription: "removed-topic~estafette", Status: "deleted"},
+				}, nil
+			},
+		}
+		var recordedEvents []api.AuditEvent
+		auditService := audit.MockService{
+			CreateAuditEventFunc: func(ctx context.Context, auditEvent api.AuditEvent) (*api.AuditEvent, error) {
+				recordedEvents = append(recordedEvents, auditEvent)
+				return &auditEvent, nil
+			},
+		}
+		handler := NewHandler(pubsubapi.MockClient{}, estafette.MockService{}, service, cockroachdb.MockClient{}, auditService)
+
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/admin/pubsub/reconcile", nil)
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey: "5",
+			"roles":         []interface{}{"administrator"},
+		})
+
+		// act
+		handler.ReconcileSubscriptions(c)
+
+		assert.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+		if assert.Equal(t, 2, len(recordedEvents)) {
+			assert.Equal(t, "ci.pipelines.update", recordedEvents[0].Action)
+			assert.Equal(t, "pubsub-subscription", recordedEvents[0].TargetType)
+			assert.Equal(t, "projects/project-a/topics/new-topic", recordedEvents[0].TargetID)
+			assert.Equal(t, "5", recordedEvents[0].ActorID)
+			assert.Nil(t, recordedEvents[0].Before)
+			assert.NotNil(t, recordedEvents[0].After)
+			assert.Equal(t, "projects/project-a/topics/removed-topic", recordedEvents[1].TargetID)
+			assert.NotNil(t, recordedEvents[1].Before)
+			assert.Nil(t, recordedEvents[1].After)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package rbac
 import (
 	"context"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/estafette/estafette-ci-api/api"
 	contracts "github.com/estafette/estafette-ci-contracts"
 )
@@ -59,6 +60,12 @@ func (s *loggingService) UpdateUser(ctx context.Context, user contracts.User) (e
 	return s.Service.UpdateUser(ctx, user)
 }
 
+func (s *loggingService) SetUserActive(ctx context.Context, id string, active bool) (err error) {
+	defer func() { api.HandleLogError(s.prefix, "SetUserActive", err) }()
+
+	return s.Service.SetUserActive(ctx, id, active)
+}
+
 func (s *loggingService) DeleteUser(ctx context.Context, id string) (err error) {
 	defer func() { api.HandleLogError(s.prefix, "DeleteUser", err) }()
 
@@ -125,6 +132,48 @@ func (s *loggingService) UpdatePipeline(ctx context.Context, pipeline contracts.
 	return s.Service.UpdatePipeline(ctx, pipeline)
 }
 
+func (s *loggingService) CreatePersonalAccessToken(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	defer func() { api.HandleLogError(s.prefix, "CreatePersonalAccessToken", err) }()
+
+	return s.Service.CreatePersonalAccessToken(ctx, userID, token)
+}
+
+func (s *loggingService) RevokePersonalAccessToken(ctx context.Context, userID, id, revokedBy string) (err error) {
+	defer func() { api.HandleLogError(s.prefix, "RevokePersonalAccessToken", err) }()
+
+	return s.Service.RevokePersonalAccessToken(ctx, userID, id, revokedBy)
+}
+
+func (s *loggingService) GetPersonalAccessTokenClaims(ctx context.Context, token api.PersonalAccessToken) (claims jwt.MapClaims, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetPersonalAccessTokenClaims", err) }()
+
+	return s.Service.GetPersonalAccessTokenClaims(ctx, token)
+}
+
+func (s *loggingService) CreateCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	defer func() { api.HandleLogError(s.prefix, "CreateCustomRole", err) }()
+
+	return s.Service.CreateCustomRole(ctx, customRole)
+}
+
+func (s *loggingService) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	defer func() { api.HandleLogError(s.prefix, "UpdateCustomRole", err) }()
+
+	return s.Service.UpdateCustomRole(ctx, customRole)
+}
+
+func (s *loggingService) DeleteCustomRole(ctx context.Context, id string) (err error) {
+	defer func() { api.HandleLogError(s.prefix, "DeleteCustomRole", err) }()
+
+	return s.Service.DeleteCustomRole(ctx, id)
+}
+
+func (s *loggingService) GetCustomRolesForRoles(ctx context.Context, roles []*string) (customRoles []*api.CustomRole, err error) {
+	defer func() { api.HandleLogError(s.prefix, "GetCustomRolesForRoles", err) }()
+
+	return s.Service.GetCustomRolesForRoles(ctx, roles)
+}
+
 func (s *loggingService) GetInheritedRolesForUser(ctx context.Context, user contracts.User) (roles []*string, err error) {
 	defer func() { api.HandleLogError(s.prefix, "GetInheritedRolesForUser", err) }()
 
@@ -136,3 +185,9 @@ func (s *loggingService) GetInheritedOrganizationsForUser(ctx context.Context, u
 
 	return s.Service.GetInheritedOrganizationsForUser(ctx, user)
 }
+
+func (s *loggingService) SyncGroupsFromIdentityProvider(ctx context.Context, user contracts.User, provider string, identityProviderGroups []string) (groups []*contracts.Group, err error) {
+	defer func() { api.HandleLogError(s.prefix, "SyncGroupsFromIdentityProvider", err) }()
+
+	return s.Service.SyncGroupsFromIdentityProvider(ctx, user, provider, identityProviderGroups)
+}
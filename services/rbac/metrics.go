@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/estafette/estafette-ci-api/api"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	"github.com/go-kit/kit/metrics"
@@ -72,6 +73,14 @@ func (s *metricsService) UpdateUser(ctx context.Context, user contracts.User) (e
 	return s.Service.UpdateUser(ctx, user)
 }
 
+func (s *metricsService) SetUserActive(ctx context.Context, id string, active bool) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "SetUserActive", begin)
+	}(time.Now())
+
+	return s.Service.SetUserActive(ctx, id, active)
+}
+
 func (s *metricsService) DeleteUser(ctx context.Context, id string) (err error) {
 	defer func(begin time.Time) {
 		api.UpdateMetrics(s.requestCount, s.requestLatency, "DeleteUser", begin)
@@ -160,6 +169,62 @@ func (s *metricsService) UpdatePipeline(ctx context.Context, pipeline contracts.
 	return s.Service.UpdatePipeline(ctx, pipeline)
 }
 
+func (s *metricsService) CreatePersonalAccessToken(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "CreatePersonalAccessToken", begin)
+	}(time.Now())
+
+	return s.Service.CreatePersonalAccessToken(ctx, userID, token)
+}
+
+func (s *metricsService) RevokePersonalAccessToken(ctx context.Context, userID, id, revokedBy string) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "RevokePersonalAccessToken", begin)
+	}(time.Now())
+
+	return s.Service.RevokePersonalAccessToken(ctx, userID, id, revokedBy)
+}
+
+func (s *metricsService) GetPersonalAccessTokenClaims(ctx context.Context, token api.PersonalAccessToken) (claims jwt.MapClaims, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetPersonalAccessTokenClaims", begin)
+	}(time.Now())
+
+	return s.Service.GetPersonalAccessTokenClaims(ctx, token)
+}
+
+func (s *metricsService) CreateCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "CreateCustomRole", begin)
+	}(time.Now())
+
+	return s.Service.CreateCustomRole(ctx, customRole)
+}
+
+func (s *metricsService) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "UpdateCustomRole", begin)
+	}(time.Now())
+
+	return s.Service.UpdateCustomRole(ctx, customRole)
+}
+
+func (s *metricsService) DeleteCustomRole(ctx context.Context, id string) (err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "DeleteCustomRole", begin)
+	}(time.Now())
+
+	return s.Service.DeleteCustomRole(ctx, id)
+}
+
+func (s *metricsService) GetCustomRolesForRoles(ctx context.Context, roles []*string) (customRoles []*api.CustomRole, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetCustomRolesForRoles", begin)
+	}(time.Now())
+
+	return s.Service.GetCustomRolesForRoles(ctx, roles)
+}
+
 func (s *metricsService) GetInheritedRolesForUser(ctx context.Context, user contracts.User) (roles []*string, err error) {
 	defer func(begin time.Time) {
 		api.UpdateMetrics(s.requestCount, s.requestLatency, "GetInheritedRolesForUser", begin)
@@ -175,3 +240,11 @@ func (s *metricsService) GetInheritedOrganizationsForUser(ctx context.Context, u
 
 	return s.Service.GetInheritedOrganizationsForUser(ctx, user)
 }
+
+func (s *metricsService) SyncGroupsFromIdentityProvider(ctx context.Context, user contracts.User, provider string, identityProviderGroups []string) (groups []*contracts.Group, err error) {
+	defer func(begin time.Time) {
+		api.UpdateMetrics(s.requestCount, s.requestLatency, "SyncGroupsFromIdentityProvider", begin)
+	}(time.Now())
+
+	return s.Service.SyncGroupsFromIdentityProvider(ctx, user, provider, identityProviderGroups)
+}
@@ -3,6 +3,7 @@ package rbac
 import (
 	"context"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/estafette/estafette-ci-api/api"
 	contracts "github.com/estafette/estafette-ci-contracts"
 )
@@ -15,6 +16,7 @@ type MockService struct {
 	CreateUserFromIdentityFunc           func(ctx context.Context, identity contracts.UserIdentity) (user *contracts.User, err error)
 	CreateUserFunc                       func(ctx context.Context, user contracts.User) (insertedUser *contracts.User, err error)
 	UpdateUserFunc                       func(ctx context.Context, user contracts.User) (err error)
+	SetUserActiveFunc                    func(ctx context.Context, id string, active bool) (err error)
 	DeleteUserFunc                       func(ctx context.Context, id string) (err error)
 	CreateGroupFunc                      func(ctx context.Context, group contracts.Group) (insertedGroup *contracts.Group, err error)
 	UpdateGroupFunc                      func(ctx context.Context, group contracts.Group) (err error)
@@ -26,8 +28,16 @@ type MockService struct {
 	UpdateClientFunc                     func(ctx context.Context, client contracts.Client) (err error)
 	DeleteClientFunc                     func(ctx context.Context, id string) (err error)
 	UpdatePipelineFunc                   func(ctx context.Context, pipeline contracts.Pipeline) (err error)
+	CreatePersonalAccessTokenFunc        func(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error)
+	RevokePersonalAccessTokenFunc        func(ctx context.Context, userID, id, revokedBy string) (err error)
+	GetPersonalAccessTokenClaimsFunc     func(ctx context.Context, token api.PersonalAccessToken) (claims jwt.MapClaims, err error)
+	CreateCustomRoleFunc                 func(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error)
+	UpdateCustomRoleFunc                 func(ctx context.Context, customRole api.CustomRole) (err error)
+	DeleteCustomRoleFunc                 func(ctx context.Context, id string) (err error)
+	GetCustomRolesForRolesFunc           func(ctx context.Context, roles []*string) (customRoles []*api.CustomRole, err error)
 	GetInheritedRolesForUserFunc         func(ctx context.Context, user contracts.User) (roles []*string, err error)
 	GetInheritedOrganizationsForUserFunc func(ctx context.Context, user contracts.User) (organizations []*contracts.Organization, err error)
+	SyncGroupsFromIdentityProviderFunc   func(ctx context.Context, user contracts.User, provider string, identityProviderGroups []string) (groups []*contracts.Group, err error)
 }
 
 func (s MockService) GetRoles(ctx context.Context) (roles []string, err error) {
@@ -79,6 +89,13 @@ func (s MockService) UpdateUser(ctx context.Context, user contracts.User) (err e
 	return s.UpdateUserFunc(ctx, user)
 }
 
+func (s MockService) SetUserActive(ctx context.Context, id string, active bool) (err error) {
+	if s.SetUserActiveFunc == nil {
+		return
+	}
+	return s.SetUserActiveFunc(ctx, id, active)
+}
+
 func (s MockService) DeleteUser(ctx context.Context, id string) (err error) {
 	if s.DeleteUserFunc == nil {
 		return
@@ -156,6 +173,55 @@ func (s MockService) UpdatePipeline(ctx context.Context, pipeline contracts.Pipe
 	return s.UpdatePipelineFunc(ctx, pipeline)
 }
 
+func (s MockService) CreatePersonalAccessToken(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	if s.CreatePersonalAccessTokenFunc == nil {
+		return
+	}
+	return s.CreatePersonalAccessTokenFunc(ctx, userID, token)
+}
+
+func (s MockService) RevokePersonalAccessToken(ctx context.Context, userID, id, revokedBy string) (err error) {
+	if s.RevokePersonalAccessTokenFunc == nil {
+		return
+	}
+	return s.RevokePersonalAccessTokenFunc(ctx, userID, id, revokedBy)
+}
+
+func (s MockService) GetPersonalAccessTokenClaims(ctx context.Context, token api.PersonalAccessToken) (claims jwt.MapClaims, err error) {
+	if s.GetPersonalAccessTokenClaimsFunc == nil {
+		return
+	}
+	return s.GetPersonalAccessTokenClaimsFunc(ctx, token)
+}
+
+func (s MockService) CreateCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	if s.CreateCustomRoleFunc == nil {
+		return
+	}
+	return s.CreateCustomRoleFunc(ctx, customRole)
+}
+
+func (s MockService) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	if s.UpdateCustomRoleFunc == nil {
+		return
+	}
+	return s.UpdateCustomRoleFunc(ctx, customRole)
+}
+
+func (s MockService) DeleteCustomRole(ctx context.Context, id string) (err error) {
+	if s.DeleteCustomRoleFunc == nil {
+		return
+	}
+	return s.DeleteCustomRoleFunc(ctx, id)
+}
+
+func (s MockService) GetCustomRolesForRoles(ctx context.Context, roles []*string) (customRoles []*api.CustomRole, err error) {
+	if s.GetCustomRolesForRolesFunc == nil {
+		return
+	}
+	return s.GetCustomRolesForRolesFunc(ctx, roles)
+}
+
 func (s MockService) GetInheritedRolesForUser(ctx context.Context, user contracts.User) (roles []*string, err error) {
 	if s.GetInheritedRolesForUserFunc == nil {
 		return
@@ -169,3 +235,10 @@ func (s MockService) GetInheritedOrganizationsForUser(ctx context.Context, user
 	}
 	return s.GetInheritedOrganizationsForUserFunc(ctx, user)
 }
+
+func (s MockService) SyncGroupsFromIdentityProvider(ctx context.Context, user contracts.User, provider string, identityProviderGroups []string) (groups []*contracts.Group, err error) {
+	if s.SyncGroupsFromIdentityProviderFunc == nil {
+		return
+	}
+	return s.SyncGroupsFromIdentityProviderFunc(ctx, user, provider, identityProviderGroups)
+}
@@ -2,10 +2,13 @@ package rbac
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
+	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
 	contracts "github.com/estafette/estafette-ci-contracts"
@@ -17,6 +20,23 @@ import (
 var (
 	// ErrUserNotFound indicates that a user cannot be found in the database
 	ErrUserNotFound = errors.New("The user can't be found")
+
+	// ErrUserInactive indicates that a user has been deactivated
+	ErrUserInactive = errors.New("The user is inactive")
+
+	// ErrInvalidPersonalAccessTokenScope indicates a personal access token is requested with scopes the user doesn't have permission for
+	ErrInvalidPersonalAccessTokenScope = errors.New("The personal access token scopes are invalid")
+
+	// ErrInvalidPersonalAccessTokenExpiry indicates a personal access token is requested without an expiry or with one too far in the future
+	ErrInvalidPersonalAccessTokenExpiry = errors.New("The personal access token expiry is invalid")
+
+	// ErrInvalidCustomRole indicates a custom role has no name, clashes with another role or has unknown permissions
+	ErrInvalidCustomRole = errors.New("The custom role is invalid")
+)
+
+const (
+	// maxPersonalAccessTokenValidity is the longest period a personal access token can be valid for
+	maxPersonalAccessTokenValidity = 366 * 24 * time.Hour
 )
 
 // Service handles http requests for role-based-access-control
@@ -30,6 +50,7 @@ type Service interface {
 	CreateUserFromIdentity(ctx context.Context, identity contracts.UserIdentity) (user *contracts.User, err error)
 	CreateUser(ctx context.Context, user contracts.User) (insertedUser *contracts.User, err error)
 	UpdateUser(ctx context.Context, user contracts.User) (err error)
+	SetUserActive(ctx context.Context, id string, active bool) (err error)
 	DeleteUser(ctx context.Context, id string) (err error)
 
 	CreateGroup(ctx context.Context, group contracts.Group) (insertedGroup *contracts.Group, err error)
@@ -46,8 +67,18 @@ type Service interface {
 
 	UpdatePipeline(ctx context.Context, pipeline contracts.Pipeline) (err error)
 
+	CreatePersonalAccessToken(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error)
+	RevokePersonalAccessToken(ctx context.Context, userID, id, revokedBy string) (err error)
+	GetPersonalAccessTokenClaims(ctx context.Context, token api.PersonalAccessToken) (claims jwt.MapClaims, err error)
+
+	CreateCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error)
+	UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error)
+	DeleteCustomRole(ctx context.Context, id string) (err error)
+	GetCustomRolesForRoles(ctx context.Context, roles []*string) (customRoles []*api.CustomRole, err error)
+
 	GetInheritedRolesForUser(ctx context.Context, user contracts.User) (roles []*string, err error)
 	GetInheritedOrganizationsForUser(ctx context.Context, user contracts.User) (organizations []*contracts.Organization, err error)
+	SyncGroupsFromIdentityProvider(ctx context.Context, user contracts.User, provider string, identityProviderGroups []string) (groups []*contracts.Group, err error)
 }
 
 // NewService returns a github.Service to handle incoming webhook events
@@ -64,7 +95,26 @@ type service struct {
 }
 
 func (s *service) GetRoles(ctx context.Context) (roles []string, err error) {
-	return api.Roles(), nil
+
+	roles = api.Roles()
+
+	// custom roles are assigned by name as well, so list them alongside the built-in roles
+	pageNumber, pageSize := 1, 100
+	for {
+		customRoles, err := s.cockroachdbClient.GetCustomRoles(ctx, pageNumber, pageSize, map[api.FilterType][]string{}, []api.OrderField{})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range customRoles {
+			roles = append(roles, r.Name)
+		}
+		if len(customRoles) < pageSize {
+			break
+		}
+		pageNumber++
+	}
+
+	return roles, nil
 }
 
 func (s *service) GetProviders(ctx context.Context) (providers map[string][]*api.OAuthProvider, err error) {
@@ -165,8 +215,8 @@ func (s *service) CreateUser(ctx context.Context, user contracts.User) (inserted
 
 func (s *service) UpdateUser(ctx context.Context, user contracts.User) (err error) {
 
-	// get user from db
-	currentUser, err := s.cockroachdbClient.GetUserByID(ctx, user.ID, map[api.FilterType][]string{})
+	// get user from db, including inactive users so they can be reactivated
+	currentUser, err := s.cockroachdbClient.GetUserByID(ctx, user.ID, map[api.FilterType][]string{api.FilterActive: {"true", "false"}})
 	if err != nil {
 		return
 	}
@@ -174,10 +224,9 @@ func (s *service) UpdateUser(ctx context.Context, user contracts.User) (err erro
 		return fmt.Errorf("User is nil")
 	}
 
-	// copy updateable fields
+	// copy updateable fields; whether the user is active is only changed through SetUserActive, since an update leaving it out can't be told apart from one deactivating the user
 	currentUser.Name = user.Name
 	currentUser.Email = user.Email
-	currentUser.Active = user.Active
 	currentUser.Identities = user.Identities
 	currentUser.Groups = user.Groups
 	currentUser.Organizations = user.Organizations
@@ -192,6 +241,33 @@ func (s *service) UpdateUser(ctx context.Context, user contracts.User) (err erro
 	return s.cockroachdbClient.UpdateUser(ctx, *currentUser)
 }
 
+// SetUserActive activates or deactivates a user; deactivating revokes its personal access tokens, like deleting does
+func (s *service) SetUserActive(ctx context.Context, id string, active bool) (err error) {
+
+	// get user from db, including inactive users so they can be reactivated
+	currentUser, err := s.cockroachdbClient.GetUserByID(ctx, id, map[api.FilterType][]string{api.FilterActive: {"true", "false"}})
+	if err != nil {
+		return
+	}
+	if currentUser == nil {
+		return fmt.Errorf("User is nil")
+	}
+	if currentUser.Active == active {
+		return nil
+	}
+
+	if !active {
+		err = s.revokePersonalAccessTokensForUser(ctx, currentUser.ID)
+		if err != nil {
+			return
+		}
+	}
+
+	currentUser.Active = active
+
+	return s.cockroachdbClient.UpdateUser(ctx, *currentUser)
+}
+
 func (s *service) DeleteUser(ctx context.Context, id string) (err error) {
 
 	// get user from db
@@ -203,8 +279,15 @@ func (s *service) DeleteUser(ctx context.Context, id string) (err error) {
 		return fmt.Errorf("User is nil")
 	}
 
+	// personal access tokens outlive the user's session, so revoke them before the user is gone
+	err = s.revokePersonalAccessTokensForUser(ctx, currentUser.ID)
+	if err != nil {
+		return
+	}
+
 	return s.cockroachdbClient.DeleteUser(ctx, *currentUser)
 }
+
 func (s *service) CreateGroup(ctx context.Context, group contracts.Group) (insertedGroup *contracts.Group, err error) {
 
 	log.Info().Msgf("Creating record for group %v", group.Name)
@@ -375,6 +458,311 @@ func (s *service) UpdatePipeline(ctx context.Context, pipeline contracts.Pipelin
 	return s.cockroachdbClient.UpdateComputedPipelinePermissions(ctx, *currentPipeline)
 }
 
+func (s *service) CreatePersonalAccessToken(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+
+	log.Info().Msgf("Creating personal access token %v for user %v", token.Name, userID)
+
+	now := time.Now().UTC()
+	if token.ExpiresAt == nil || !token.ExpiresAt.After(now) || token.ExpiresAt.After(now.Add(maxPersonalAccessTokenValidity)) {
+		return nil, ErrInvalidPersonalAccessTokenExpiry
+	}
+
+	user, inheritedRoles, inheritedOrganizations, customRoles, err := s.getCurrentUserWithRoles(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	// ensure the token doesn't grant more than the user's own permissions
+	roles := []api.Role{}
+	for _, r := range inheritedRoles {
+		if r == nil {
+			continue
+		}
+		if role := api.ToRole(*r); role != nil {
+			roles = append(roles, *role)
+		}
+	}
+	userPermissions := api.GetPermissionsForRoles(roles)
+	for _, r := range customRoles {
+		for _, p := range r.Permissions {
+			if permission := api.ToPermission(p); permission != nil && !api.PermissionArrayContains(userPermissions, *permission) {
+				userPermissions = append(userPermissions, *permission)
+			}
+		}
+	}
+
+	if len(token.Scopes) == 0 {
+		return nil, ErrInvalidPersonalAccessTokenScope
+	}
+	for _, scope := range token.Scopes {
+		permission := api.ToPermission(scope)
+		if permission == nil || !api.PermissionArrayContains(userPermissions, *permission) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPersonalAccessTokenScope, scope)
+		}
+	}
+
+	insertedToken, err = s.cockroachdbClient.InsertPersonalAccessToken(ctx, api.PersonalAccessToken{
+		UserID:    user.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		ExpiresAt: token.ExpiresAt,
+		Active:    true,
+	})
+	if err != nil {
+		return
+	}
+
+	// sign the token with the same claims as a login session, but restricted by its scopes
+	insertedToken.Value, err = api.GenerateJWT(s.config, token.ExpiresAt.Sub(now), getPersonalAccessTokenClaims(*user, inheritedRoles, inheritedOrganizations, customRoles, insertedToken.ID, token.Scopes))
+	if err != nil {
+		return nil, err
+	}
+
+	return insertedToken, nil
+}
+
+func (s *service) RevokePersonalAccessToken(ctx context.Context, userID, id, revokedBy string) (err error) {
+
+	// get token from db
+	currentToken, err := s.cockroachdbClient.GetPersonalAccessTokenByID(ctx, id)
+	if err != nil {
+		return
+	}
+	if currentToken == nil {
+		return fmt.Errorf("Personal access token is nil")
+	}
+
+	// users can only revoke their own tokens
+	if currentToken.UserID != userID {
+		return cockroachdb.ErrPersonalAccessTokenNotFound
+	}
+
+	log.Info().Msgf("Revoking personal access token %v for user %v by %v", currentToken.ID, currentToken.UserID, revokedBy)
+
+	revokedAt := time.Now().UTC()
+	currentToken.RevokedAt = &revokedAt
+	currentToken.RevokedBy = revokedBy
+
+	return s.cockroachdbClient.RevokePersonalAccessToken(ctx, *currentToken)
+}
+
+func (s *service) GetPersonalAccessTokenClaims(ctx context.Context, token api.PersonalAccessToken) (claims jwt.MapClaims, err error) {
+
+	user, inheritedRoles, inheritedOrganizations, customRoles, err := s.getCurrentUserWithRoles(ctx, token.UserID)
+	if err != nil {
+		return
+	}
+
+	// round-trip the claims through json, so they have the same types as claims read from a jwt
+	claimsBytes, err := json.Marshal(getPersonalAccessTokenClaims(*user, inheritedRoles, inheritedOrganizations, customRoles, token.ID, token.Scopes))
+	if err != nil {
+		return
+	}
+	claims = jwt.MapClaims{}
+	if err = json.Unmarshal(claimsBytes, &claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// getCurrentUserWithRoles loads an active user with the roles and organizations it currently has, directly or inherited from groups and organizations
+func (s *service) getCurrentUserWithRoles(ctx context.Context, userID string) (user *contracts.User, inheritedRoles []*string, inheritedOrganizations []*contracts.Organization, customRoles []*api.CustomRole, err error) {
+
+	// get user from db
+	user, err = s.cockroachdbClient.GetUserByID(ctx, userID, map[api.FilterType][]string{})
+	if err != nil {
+		return
+	}
+	if user == nil || !user.Active {
+		err = ErrUserInactive
+		return
+	}
+
+	s.setAdminRoleForUserIfConfigured(user)
+
+	// get all roles the user inherits from groups and organizations
+	inheritedRoles, err = s.GetInheritedRolesForUser(ctx, *user)
+	if err != nil {
+		return
+	}
+
+	// get all organizations the user inherits from groups
+	inheritedOrganizations, err = s.GetInheritedOrganizationsForUser(ctx, *user)
+	if err != nil {
+		return
+	}
+
+	customRoles, err = s.GetCustomRolesForRoles(ctx, inheritedRoles)
+
+	return
+}
+
+func getPersonalAccessTokenClaims(user contracts.User, inheritedRoles []*string, inheritedOrganizations []*contracts.Organization, customRoles []*api.CustomRole, tokenID string, scopes []string) jwtgo.MapClaims {
+
+	groups := []string{}
+	for _, g := range user.Groups {
+		groups = append(groups, g.Name)
+	}
+
+	organizations := []string{}
+	for _, o := range inheritedOrganizations {
+		organizations = append(organizations, o.Name)
+	}
+
+	return jwtgo.MapClaims{
+		jwt.IdentityKey: user.ID,
+		"email":         user.GetEmail(),
+		"roles":         inheritedRoles,
+		"customRoles":   customRoles,
+		"groups":        groups,
+		"organizations": organizations,
+		"tokenID":       tokenID,
+		"scopes":        scopes,
+	}
+}
+
+func (s *service) revokePersonalAccessTokensForUser(ctx context.Context, userID string) (err error) {
+
+	activeFilter := map[api.FilterType][]string{api.FilterStatus: {"active"}}
+
+	// revoked tokens drop out of the active filter, so keep fetching the first page until it's empty
+	for {
+		tokens, err := s.cockroachdbClient.GetPersonalAccessTokens(ctx, userID, 1, 100, activeFilter, []api.OrderField{})
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			return nil
+		}
+
+		for _, t := range tokens {
+			log.Info().Msgf("Revoking personal access token %v for deactivated user %v", t.ID, userID)
+
+			revokedAt := time.Now().UTC()
+			t.RevokedAt = &revokedAt
+			err = s.cockroachdbClient.RevokePersonalAccessToken(ctx, *t)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *service) CreateCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+
+	err = s.validateCustomRole(ctx, customRole)
+	if err != nil {
+		return
+	}
+
+	return s.cockroachdbClient.InsertCustomRole(ctx, api.CustomRole{
+		Name:        customRole.Name,
+		Description: customRole.Description,
+		Permissions: customRole.Permissions,
+		Scope:       customRole.Scope,
+	})
+}
+
+func (s *service) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+
+	// get custom role from db
+	currentCustomRole, err := s.cockroachdbClient.GetCustomRoleByID(ctx, customRole.ID)
+	if err != nil {
+		return
+	}
+	if currentCustomRole == nil {
+		return fmt.Errorf("Custom role is nil")
+	}
+
+	// roles are assigned by name, so renaming would silently strip the role from everyone it's assigned to
+	if customRole.Name != currentCustomRole.Name {
+		return fmt.Errorf("%w: name %v can't be changed since roles are assigned by name; create a new custom role instead", ErrInvalidCustomRole, currentCustomRole.Name)
+	}
+
+	err = s.validateCustomRole(ctx, customRole)
+	if err != nil {
+		return
+	}
+
+	// copy updateable fields
+	currentCustomRole.Description = customRole.Description
+	currentCustomRole.Permissions = customRole.Permissions
+	currentCustomRole.Scope = customRole.Scope
+
+	return s.cockroachdbClient.UpdateCustomRole(ctx, *currentCustomRole)
+}
+
+func (s *service) DeleteCustomRole(ctx context.Context, id string) (err error) {
+
+	// get custom role from db
+	currentCustomRole, err := s.cockroachdbClient.GetCustomRoleByID(ctx, id)
+	if err != nil {
+		return
+	}
+	if currentCustomRole == nil {
+		return fmt.Errorf("Custom role is nil")
+	}
+
+	return s.cockroachdbClient.DeleteCustomRole(ctx, *currentCustomRole)
+}
+
+func (s *service) GetCustomRolesForRoles(ctx context.Context, roles []*string) (customRoles []*api.CustomRole, err error) {
+
+	// built-in roles take precedence, so only look up the remaining names
+	names := []string{}
+	for _, r := range roles {
+		if r != nil && api.ToRole(*r) == nil {
+			names = append(names, *r)
+		}
+	}
+
+	if len(names) == 0 {
+		return []*api.CustomRole{}, nil
+	}
+
+	return s.cockroachdbClient.GetCustomRolesByNames(ctx, names)
+}
+
+func (s *service) validateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+
+	if customRole.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCustomRole)
+	}
+	if api.ToRole(customRole.Name) != nil {
+		return fmt.Errorf("%w: name %v is a built-in role", ErrInvalidCustomRole, customRole.Name)
+	}
+	if len(customRole.Permissions) == 0 {
+		return fmt.Errorf("%w: at least one permission is required", ErrInvalidCustomRole)
+	}
+	for _, p := range customRole.Permissions {
+		if api.ToPermission(p) == nil {
+			return fmt.Errorf("%w: unknown permission %v", ErrInvalidCustomRole, p)
+		}
+	}
+	if customRole.Scope.IsScoped() && (s.config == nil || s.config.Auth == nil || !s.config.Auth.EnforcePipelinePermissions) {
+		return fmt.Errorf("%w: a scope only applies with auth.enforcePipelinePermissions enabled", ErrInvalidCustomRole)
+	}
+	if customRole.Scope != nil && customRole.Scope.LabelSelector != "" {
+		if _, err := api.ParseLabelSelectors([]string{customRole.Scope.LabelSelector}); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCustomRole, err)
+		}
+	}
+
+	// names need to be unique since roles are assigned by name
+	existingCustomRoles, err := s.cockroachdbClient.GetCustomRolesByNames(ctx, []string{customRole.Name})
+	if err != nil {
+		return
+	}
+	for _, r := range existingCustomRoles {
+		if r.ID != customRole.ID {
+			return fmt.Errorf("%w: name %v is already in use", ErrInvalidCustomRole, customRole.Name)
+		}
+	}
+
+	return nil
+}
+
 func (s *service) GetInheritedRolesForUser(ctx context.Context, user contracts.User) (roles []*string, err error) {
 
 	retrievedRoles := make([]*string, 0)
@@ -409,7 +797,36 @@ func (s *service) GetInheritedRolesForUser(ctx context.Context, user contracts.U
 		retrievedRoles = append(retrievedRoles, organization.Roles...)
 	}
 
-	return s.dedupeRoles(retrievedRoles), nil
+	roles = s.dedupeRoles(retrievedRoles)
+
+	// resolve custom roles, dropping names of custom roles that have been deleted in the meantime
+	customRoles, err := s.GetCustomRolesForRoles(ctx, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.filterUnknownRoles(roles, customRoles), nil
+}
+
+func (s *service) filterUnknownRoles(retrievedRoles []*string, customRoles []*api.CustomRole) (roles []*string) {
+	roles = make([]*string, 0)
+	for _, r := range retrievedRoles {
+		if r == nil {
+			continue
+		}
+		if api.ToRole(*r) != nil {
+			roles = append(roles, r)
+			continue
+		}
+		for _, cr := range customRoles {
+			if cr.Name == *r {
+				roles = append(roles, r)
+				break
+			}
+		}
+	}
+
+	return roles
 }
 
 func (s *service) dedupeRoles(retrievedRoles []*string) (roles []*string) {
@@ -447,6 +864,75 @@ func (s *service) GetInheritedOrganizationsForUser(ctx context.Context, user con
 	return s.dedupeOrganizations(retrievedOrganizations), nil
 }
 
+// SyncGroupsFromIdentityProvider returns the user's groups updated with the groups claimed by the identity provider; only groups having an identity for the provider are added or removed
+func (s *service) SyncGroupsFromIdentityProvider(ctx context.Context, user contracts.User, provider string, identityProviderGroups []string) (groups []*contracts.Group, err error) {
+
+	// get the groups linked to the identity provider groups
+	claimedGroups := make([]*contracts.Group, 0)
+	for _, name := range identityProviderGroups {
+		group, err := s.cockroachdbClient.GetGroupByIdentity(ctx, contracts.GroupIdentity{Provider: provider, Name: name})
+		if err != nil {
+			if errors.Is(err, cockroachdb.ErrGroupNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		claimedGroups = append(claimedGroups, group)
+	}
+
+	groups = make([]*contracts.Group, 0)
+	for _, g := range user.Groups {
+		if g == nil {
+			continue
+		}
+		if groupsContain(claimedGroups, g.ID) {
+			groups = append(groups, g)
+			continue
+		}
+
+		// keep groups that aren't managed by the identity provider
+		group, err := s.cockroachdbClient.GetGroupByID(ctx, g.ID, map[api.FilterType][]string{})
+		if err != nil && !errors.Is(err, cockroachdb.ErrGroupNotFound) {
+			return nil, err
+		}
+		if group == nil || !groupHasIdentityForProvider(*group, provider) {
+			groups = append(groups, g)
+		}
+	}
+
+	// add groups the user isn't a member of yet
+	for _, g := range claimedGroups {
+		if !groupsContain(groups, g.ID) {
+			groups = append(groups, &contracts.Group{
+				ID:   g.ID,
+				Name: g.Name,
+			})
+		}
+	}
+
+	return groups, nil
+}
+
+func groupsContain(groups []*contracts.Group, id string) bool {
+	for _, g := range groups {
+		if g != nil && g.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func groupHasIdentityForProvider(group contracts.Group, provider string) bool {
+	for _, i := range group.Identities {
+		if i != nil && i.Provider == provider {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s *service) dedupeOrganizations(retrievedOrganizations []*contracts.Organization) (organizations []*contracts.Organization) {
 	organizations = make([]*contracts.Organization, 0)
 	for _, o := range retrievedOrganizations {
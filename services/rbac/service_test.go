@@ -1,8 +1,13 @@
 package rbac
 
 import (
+	"context"
+	"errors"
 	"testing"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	"github.com/stretchr/testify/assert"
 )
@@ -38,6 +43,40 @@ func TestDedupeRoles(t *testing.T) {
 	})
 }
 
+func TestFilterUnknownRoles(t *testing.T) {
+
+	t.Run("KeepsBuiltInAndExistingCustomRoles", func(t *testing.T) {
+
+		role1 := "administrator"
+		role2 := "release-staging"
+		role3 := "deleted-custom-role"
+
+		retrievedRoles := []*string{
+			&role1,
+			&role2,
+			&role3,
+			nil,
+		}
+
+		customRoles := []*api.CustomRole{
+			{
+				Name:        "release-staging",
+				Permissions: []string{"ci.releases.create"},
+			},
+		}
+
+		service := &service{}
+
+		// act
+		roles := service.filterUnknownRoles(retrievedRoles, customRoles)
+
+		if assert.Equal(t, 2, len(roles)) {
+			assert.Equal(t, "administrator", *roles[0])
+			assert.Equal(t, "release-staging", *roles[1])
+		}
+	})
+}
+
 func TestDedupeOrganizations(t *testing.T) {
 
 	t.Run("DedupesDoubleOrganizationsOnID", func(t *testing.T) {
@@ -69,3 +108,300 @@ func TestDedupeOrganizations(t *testing.T) {
 		assert.Equal(t, 2, len(organizations))
 	})
 }
+
+func TestSyncGroupsFromIdentityProvider(t *testing.T) {
+
+	t.Run("AddsClaimedGroupsAndRemovesOnlyGroupsManagedByProvider", func(t *testing.T) {
+
+		groups := map[string]*contracts.Group{
+			"1": {ID: "1", Name: "team-a", Identities: []*contracts.GroupIdentity{{Provider: "okta", Name: "team-a"}}},
+			"2": {ID: "2", Name: "team-b", Identities: []*contracts.GroupIdentity{{Provider: "okta", Name: "team-b"}}},
+			"3": {ID: "3", Name: "manual"},
+			"4": {ID: "4", Name: "team-d", Identities: []*contracts.GroupIdentity{{Provider: "okta", Name: "team-d"}}},
+		}
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetGroupByIdentityFunc: func(ctx context.Context, identity contracts.GroupIdentity) (group *contracts.Group, err error) {
+				for _, g := range groups {
+					for _, i := range g.Identities {
+						if i.Provider == identity.Provider && i.Name == identity.Name {
+							return g, nil
+						}
+					}
+				}
+				return nil, cockroachdb.ErrGroupNotFound
+			},
+			GetGroupByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (group *contracts.Group, err error) {
+				return groups[id], nil
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		user := contracts.User{
+			Groups: []*contracts.Group{
+				{ID: "1", Name: "team-a"},
+				{ID: "2", Name: "team-b"},
+				{ID: "3", Name: "manual"},
+			},
+		}
+
+		// act
+		syncedGroups, err := service.SyncGroupsFromIdentityProvider(context.Background(), user, "okta", []string{"team-a", "team-d", "unknown"})
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 3, len(syncedGroups)) {
+			assert.Equal(t, "1", syncedGroups[0].ID)
+			assert.Equal(t, "3", syncedGroups[1].ID)
+			assert.Equal(t, "4", syncedGroups[2].ID)
+		}
+	})
+}
+
+func TestGetPersonalAccessTokenClaims(t *testing.T) {
+
+	t.Run("ReturnsCurrentRolesOfUser", func(t *testing.T) {
+
+		role := "user.viewer"
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				return &contracts.User{ID: id, Active: true, Roles: []*string{&role}}, nil
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		claims, err := service.GetPersonalAccessTokenClaims(context.Background(), api.PersonalAccessToken{ID: "3", UserID: "15", Scopes: []string{"ci.builds.list"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "15", claims[jwt.IdentityKey])
+		assert.Equal(t, "3", claims["tokenID"])
+		assert.Equal(t, []interface{}{"user.viewer"}, claims["roles"])
+		assert.Equal(t, []interface{}{"ci.builds.list"}, claims["scopes"])
+	})
+
+	t.Run("ReturnsErrorForInactiveUser", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				return &contracts.User{ID: id, Active: false}, nil
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		_, err := service.GetPersonalAccessTokenClaims(context.Background(), api.PersonalAccessToken{ID: "3", UserID: "15"})
+
+		assert.True(t, errors.Is(err, ErrUserInactive))
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+
+	t.Run("RevokesAllActivePersonalAccessTokensOfUser", func(t *testing.T) {
+
+		activeTokens := map[string]*api.PersonalAccessToken{
+			"1": {ID: "1", UserID: "15", Active: true},
+			"2": {ID: "2", UserID: "15", Active: true},
+		}
+		deleted := false
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				return &contracts.User{ID: id, Active: true}, nil
+			},
+			GetPersonalAccessTokensFunc: func(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
+				for _, t := range activeTokens {
+					tokens = append(tokens, t)
+				}
+				return
+			},
+			RevokePersonalAccessTokenFunc: func(ctx context.Context, token api.PersonalAccessToken) (err error) {
+				assert.NotNil(t, token.RevokedAt)
+				delete(activeTokens, token.ID)
+				return
+			},
+			DeleteUserFunc: func(ctx context.Context, user contracts.User) (err error) {
+				deleted = true
+				return
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		err := service.DeleteUser(context.Background(), "15")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(activeTokens))
+		assert.True(t, deleted)
+	})
+}
+
+func TestCreateCustomRole(t *testing.T) {
+
+	t.Run("ReturnsErrInvalidCustomRoleForScopeWithoutEnforcedPipelinePermissions", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			InsertCustomRoleFunc: func(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+				assert.Fail(t, "Scoped custom role shouldn't be inserted")
+				return &customRole, nil
+			},
+		}
+
+		service := NewService(&api.APIConfig{Auth: &api.AuthConfig{}}, cockroachdbClient)
+
+		// act
+		_, err := service.CreateCustomRole(context.Background(), api.CustomRole{Name: "release-staging", Permissions: []string{"ci.releases.create"}, Scope: &api.CustomRoleScope{ReleaseTargets: []string{"staging"}}})
+
+		assert.True(t, errors.Is(err, ErrInvalidCustomRole))
+	})
+
+	t.Run("InsertsScopedCustomRoleWithEnforcedPipelinePermissions", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetCustomRolesByNamesFunc: func(ctx context.Context, names []string) (customRoles []*api.CustomRole, err error) {
+				return []*api.CustomRole{}, nil
+			},
+			InsertCustomRoleFunc: func(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+				return &customRole, nil
+			},
+		}
+
+		service := NewService(&api.APIConfig{Auth: &api.AuthConfig{EnforcePipelinePermissions: true}}, cockroachdbClient)
+
+		// act
+		insertedCustomRole, err := service.CreateCustomRole(context.Background(), api.CustomRole{Name: "release-staging", Permissions: []string{"ci.releases.create"}, Scope: &api.CustomRoleScope{ReleaseTargets: []string{"staging"}}})
+
+		assert.Nil(t, err)
+		assert.NotNil(t, insertedCustomRole)
+	})
+}
+
+func TestUpdateCustomRole(t *testing.T) {
+
+	t.Run("ReturnsErrInvalidCustomRoleForRename", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetCustomRoleByIDFunc: func(ctx context.Context, id string) (customRole *api.CustomRole, err error) {
+				return &api.CustomRole{ID: id, Name: "release-staging", Permissions: []string{"ci.releases.create"}, Active: true}, nil
+			},
+			UpdateCustomRoleFunc: func(ctx context.Context, customRole api.CustomRole) (err error) {
+				assert.Fail(t, "Renamed custom role shouldn't be updated")
+				return nil
+			},
+		}
+
+		service := NewService(&api.APIConfig{Auth: &api.AuthConfig{}}, cockroachdbClient)
+
+		// act
+		err := service.UpdateCustomRole(context.Background(), api.CustomRole{ID: "3", Name: "release-all", Permissions: []string{"ci.releases.create"}})
+
+		assert.True(t, errors.Is(err, ErrInvalidCustomRole))
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+
+	t.Run("KeepsActiveStateOfUser", func(t *testing.T) {
+
+		var updatedUser *contracts.User
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				return &contracts.User{ID: id, Active: true}, nil
+			},
+			GetPersonalAccessTokensFunc: func(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
+				assert.Fail(t, "Personal access tokens shouldn't be revoked when updating a user")
+				return
+			},
+			UpdateUserFunc: func(ctx context.Context, user contracts.User) (err error) {
+				updatedUser = &user
+				return
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		err := service.UpdateUser(context.Background(), contracts.User{ID: "15", Name: "Me"})
+
+		assert.Nil(t, err)
+		if assert.NotNil(t, updatedUser) {
+			assert.True(t, updatedUser.Active)
+			assert.Equal(t, "Me", updatedUser.Name)
+		}
+	})
+}
+
+func TestSetUserActive(t *testing.T) {
+
+	t.Run("RevokesActivePersonalAccessTokensWhenDeactivatingUser", func(t *testing.T) {
+
+		activeTokens := map[string]*api.PersonalAccessToken{
+			"1": {ID: "1", UserID: "15", Active: true},
+		}
+		var updatedUser *contracts.User
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				return &contracts.User{ID: id, Active: true}, nil
+			},
+			GetPersonalAccessTokensFunc: func(ctx context.Context, userID string, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (tokens []*api.PersonalAccessToken, err error) {
+				for _, t := range activeTokens {
+					tokens = append(tokens, t)
+				}
+				return
+			},
+			RevokePersonalAccessTokenFunc: func(ctx context.Context, token api.PersonalAccessToken) (err error) {
+				delete(activeTokens, token.ID)
+				return
+			},
+			UpdateUserFunc: func(ctx context.Context, user contracts.User) (err error) {
+				updatedUser = &user
+				return
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		err := service.SetUserActive(context.Background(), "15", false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(activeTokens))
+		if assert.NotNil(t, updatedUser) {
+			assert.False(t, updatedUser.Active)
+		}
+	})
+
+	t.Run("ReactivatesInactiveUser", func(t *testing.T) {
+
+		var updatedUser *contracts.User
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				if len(filters[api.FilterActive]) != 2 {
+					return nil, cockroachdb.ErrUserNotFound
+				}
+				return &contracts.User{ID: id, Active: false}, nil
+			},
+			UpdateUserFunc: func(ctx context.Context, user contracts.User) (err error) {
+				updatedUser = &user
+				return
+			},
+		}
+
+		service := NewService(&api.APIConfig{}, cockroachdbClient)
+
+		// act
+		err := service.SetUserActive(context.Background(), "15", true)
+
+		assert.Nil(t, err)
+		if assert.NotNil(t, updatedUser) {
+			assert.True(t, updatedUser.Active)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package rbac
 import (
 	"context"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/estafette/estafette-ci-api/api"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	"github.com/opentracing/opentracing-go"
@@ -67,6 +68,13 @@ func (s *tracingService) UpdateUser(ctx context.Context, user contracts.User) (e
 	return s.Service.UpdateUser(ctx, user)
 }
 
+func (s *tracingService) SetUserActive(ctx context.Context, id string, active bool) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "SetUserActive"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.SetUserActive(ctx, id, active)
+}
+
 func (s *tracingService) DeleteUser(ctx context.Context, id string) (err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "DeleteUser"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -144,6 +152,55 @@ func (s *tracingService) UpdatePipeline(ctx context.Context, pipeline contracts.
 	return s.Service.UpdatePipeline(ctx, pipeline)
 }
 
+func (s *tracingService) CreatePersonalAccessToken(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "CreatePersonalAccessToken"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.CreatePersonalAccessToken(ctx, userID, token)
+}
+
+func (s *tracingService) RevokePersonalAccessToken(ctx context.Context, userID, id, revokedBy string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "RevokePersonalAccessToken"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.RevokePersonalAccessToken(ctx, userID, id, revokedBy)
+}
+
+func (s *tracingService) GetPersonalAccessTokenClaims(ctx context.Context, token api.PersonalAccessToken) (claims jwt.MapClaims, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetPersonalAccessTokenClaims"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetPersonalAccessTokenClaims(ctx, token)
+}
+
+func (s *tracingService) CreateCustomRole(ctx context.Context, customRole api.CustomRole) (insertedCustomRole *api.CustomRole, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "CreateCustomRole"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.CreateCustomRole(ctx, customRole)
+}
+
+func (s *tracingService) UpdateCustomRole(ctx context.Context, customRole api.CustomRole) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "UpdateCustomRole"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.UpdateCustomRole(ctx, customRole)
+}
+
+func (s *tracingService) DeleteCustomRole(ctx context.Context, id string) (err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "DeleteCustomRole"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.DeleteCustomRole(ctx, id)
+}
+
+func (s *tracingService) GetCustomRolesForRoles(ctx context.Context, roles []*string) (customRoles []*api.CustomRole, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetCustomRolesForRoles"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.GetCustomRolesForRoles(ctx, roles)
+}
+
 func (s *tracingService) GetInheritedRolesForUser(ctx context.Context, user contracts.User) (roles []*string, err error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "GetInheritedRolesForUser"))
 	defer func() { api.FinishSpanWithError(span, err) }()
@@ -157,3 +214,10 @@ func (s *tracingService) GetInheritedOrganizationsForUser(ctx context.Context, u
 
 	return s.Service.GetInheritedOrganizationsForUser(ctx, user)
 }
+
+func (s *tracingService) SyncGroupsFromIdentityProvider(ctx context.Context, user contracts.User, provider string, identityProviderGroups []string) (groups []*contracts.Group, err error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, api.GetSpanName(s.prefix, "SyncGroupsFromIdentityProvider"))
+	defer func() { api.FinishSpanWithError(span, err) }()
+
+	return s.Service.SyncGroupsFromIdentityProvider(ctx, user, provider, identityProviderGroups)
+}
@@ -1,6 +1,7 @@
 package rbac
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,17 +13,19 @@ import (
 	jwtgo "github.com/dgrijalva/jwt-go"
 	"github.com/estafette/estafette-ci-api/api"
 	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/services/audit"
 	contracts "github.com/estafette/estafette-ci-contracts"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
 // NewHandler returns a new rbac.Handler
-func NewHandler(config *api.APIConfig, service Service, cockroachdbClient cockroachdb.Client) Handler {
+func NewHandler(config *api.APIConfig, service Service, cockroachdbClient cockroachdb.Client, auditService audit.Service) Handler {
 	return Handler{
 		config:            config,
 		service:           service,
 		cockroachdbClient: cockroachdbClient,
+		auditService:      auditService,
 	}
 }
 
@@ -30,6 +33,7 @@ type Handler struct {
 	config            *api.APIConfig
 	service           Service
 	cockroachdbClient cockroachdb.Client
+	auditService      audit.Service
 }
 
 func (h *Handler) GetLoggedInUser(c *gin.Context) {
@@ -47,6 +51,204 @@ func (h *Handler) GetLoggedInUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+func (h *Handler) GetPersonalAccessTokens(c *gin.Context) {
+
+	if !h.requestIsOwnUser(c) {
+		return
+	}
+
+	claims := jwt.ExtractClaims(c)
+	userID := claims[jwt.IdentityKey].(string)
+
+	h.getPersonalAccessTokensForUser(c, userID)
+}
+
+func (h *Handler) CreatePersonalAccessToken(c *gin.Context) {
+
+	// prevent personal access tokens from being used to extend their own lifetime
+	if !api.RequestTokenIsValid(c) || api.RequestTokenIsPersonalAccessToken(c) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or personal access tokens can't be used to create other tokens"})
+		return
+	}
+
+	if !h.requestIsOwnUser(c) {
+		return
+	}
+
+	claims := jwt.ExtractClaims(c)
+	userID := claims[jwt.IdentityKey].(string)
+
+	var token api.PersonalAccessToken
+	err := c.BindJSON(&token)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding CreatePersonalAccessToken body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	insertedToken, err := h.service.CreatePersonalAccessToken(ctx, userID, token)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPersonalAccessTokenScope) || errors.Is(err, ErrInvalidPersonalAccessTokenExpiry) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed inserting personal access token")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	// the signed token value is only returned to the caller and never recorded
+	auditedToken := *insertedToken
+	auditedToken.Value = ""
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersUpdate, "personal-access-token", auditedToken.ID, nil, auditedToken)
+
+	c.JSON(http.StatusCreated, insertedToken)
+}
+
+func (h *Handler) RevokePersonalAccessToken(c *gin.Context) {
+
+	if !h.requestIsOwnUser(c) {
+		return
+	}
+
+	claims := jwt.ExtractClaims(c)
+	userID := claims[jwt.IdentityKey].(string)
+
+	h.revokePersonalAccessTokenForUser(c, userID, c.Param("id"))
+}
+
+// requestIsOwnUser responds with 403 if the request identity is an impersonated user or an api client, since personal access tokens should only be managed by the user themselves
+func (h *Handler) requestIsOwnUser(c *gin.Context) bool {
+	if !api.RequestTokenIsValid(c) || api.RequestTokenIsImpersonationOrClient(c) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "Personal access tokens can only be managed by the user themselves, not while impersonating or as a client"})
+		return false
+	}
+
+	return true
+}
+
+func (h *Handler) GetUserPersonalAccessTokens(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersGet) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	h.getPersonalAccessTokensForUser(c, c.Param("id"))
+}
+
+func (h *Handler) RevokeUserPersonalAccessToken(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersUpdate) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	h.revokePersonalAccessTokenForUser(c, c.Param("id"), c.Param("tokenId"))
+}
+
+func (h *Handler) getPersonalAccessTokensForUser(c *gin.Context, userID string) {
+
+	pageNumber, pageSize, filters, sortings := api.GetQueryParameters(c)
+
+	err := validatePersonalAccessTokenQueryParameters(filters, sortings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	response, err := api.GetPagedListResponse(
+		func() ([]interface{}, error) {
+			tokens, err := h.cockroachdbClient.GetPersonalAccessTokens(ctx, userID, pageNumber, pageSize, filters, sortings)
+			if err != nil {
+				return nil, err
+			}
+
+			// convert typed array to interface array O(n)
+			items := make([]interface{}, len(tokens))
+			for i := range tokens {
+				items[i] = tokens[i]
+			}
+
+			return items, nil
+		},
+		func() (int, error) {
+			return h.cockroachdbClient.GetPersonalAccessTokensCount(ctx, userID, filters)
+		},
+		pageNumber,
+		pageSize)
+
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed retrieving personal access tokens for user %v from db", userID)
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// validatePersonalAccessTokenQueryParameters rejects filters and sortings that aren't supported for personal access tokens, rather than silently ignoring them
+func validatePersonalAccessTokenQueryParameters(filters map[api.FilterType][]string, sortings []api.OrderField) error {
+
+	for f, values := range filters {
+		switch f {
+		case api.FilterSince, api.FilterUntil:
+			continue
+		case api.FilterStatus:
+			for _, v := range values {
+				if v != "active" && v != "revoked" {
+					return fmt.Errorf("Filter status value %v is not supported for personal access tokens, use active or revoked", v)
+				}
+			}
+		default:
+			if len(values) > 0 {
+				return fmt.Errorf("Filter %v is not supported for personal access tokens", f.String())
+			}
+		}
+	}
+
+	for _, s := range sortings {
+		if s.FieldName != "insertedAt" && s.FieldName != "lastUsedAt" {
+			return fmt.Errorf("Sorting on %v is not supported for personal access tokens, use insertedAt or lastUsedAt", s.FieldName)
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) revokePersonalAccessTokenForUser(c *gin.Context, userID, id string) {
+
+	claims := jwt.ExtractClaims(c)
+	revokedBy, _ := claims["email"].(string)
+
+	ctx := c.Request.Context()
+
+	before, _ := h.cockroachdbClient.GetPersonalAccessTokenByID(ctx, id)
+
+	err := h.service.RevokePersonalAccessToken(ctx, userID, id, revokedBy)
+	if err != nil {
+		if errors.Is(err, cockroachdb.ErrPersonalAccessTokenNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound)})
+			return
+		}
+		log.Error().Err(err).Msg("Failed revoking personal access token")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersUpdate, "personal-access-token", id, before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
+}
+
 func (h *Handler) GetRoles(c *gin.Context) {
 
 	// ensure the request has the correct permission
@@ -72,6 +274,180 @@ func (h *Handler) GetRoles(c *gin.Context) {
 	c.JSON(http.StatusOK, roles)
 }
 
+func (h *Handler) GetCustomRoles(c *gin.Context) {
+
+	pageNumber, pageSize, filters, sortings := api.GetQueryParameters(c)
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionRolesList) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	response, err := api.GetPagedListResponse(
+		func() ([]interface{}, error) {
+			customRoles, err := h.cockroachdbClient.GetCustomRoles(ctx, pageNumber, pageSize, filters, sortings)
+			if err != nil {
+				return nil, err
+			}
+
+			// convert typed array to interface array O(n)
+			items := make([]interface{}, len(customRoles))
+			for i := range customRoles {
+				items[i] = customRoles[i]
+			}
+
+			return items, nil
+		},
+		func() (int, error) {
+			return h.cockroachdbClient.GetCustomRolesCount(ctx, filters)
+		},
+		pageNumber,
+		pageSize)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed retrieving custom roles from db")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) GetCustomRole(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionRolesGet) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := c.Param("id")
+
+	customRole, err := h.cockroachdbClient.GetCustomRoleByID(ctx, id)
+	if err != nil || customRole == nil {
+		log.Error().Err(err).Msgf("Failed retrieving custom role with id %v from db", id)
+		c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound)})
+		return
+	}
+
+	c.JSON(http.StatusOK, customRole)
+}
+
+func (h *Handler) CreateCustomRole(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionRolesCreate) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	var customRole api.CustomRole
+	err := c.BindJSON(&customRole)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding CreateCustomRole body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	insertedCustomRole, err := h.service.CreateCustomRole(ctx, customRole)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCustomRole) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+			return
+		}
+		log.Error().Err(err).Msg("Failed inserting custom role")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionRolesCreate, "role", insertedCustomRole.ID, nil, insertedCustomRole)
+
+	c.JSON(http.StatusCreated, insertedCustomRole)
+}
+
+func (h *Handler) UpdateCustomRole(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionRolesUpdate) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	var customRole api.CustomRole
+	err := c.BindJSON(&customRole)
+	if err != nil {
+		errorMessage := fmt.Sprint("Binding UpdateCustomRole body failed")
+		log.Error().Err(err).Msg(errorMessage)
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": errorMessage})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	id := c.Param("id")
+	if customRole.ID != id {
+		log.Error().Err(err).Msg("Custom role id is incorrect")
+		c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest)})
+		return
+	}
+
+	before, _ := h.cockroachdbClient.GetCustomRoleByID(ctx, id)
+
+	err = h.service.UpdateCustomRole(ctx, customRole)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCustomRole) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": http.StatusText(http.StatusBadRequest), "message": err.Error()})
+			return
+		}
+		if errors.Is(err, cockroachdb.ErrCustomRoleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound)})
+			return
+		}
+		log.Error().Err(err).Msg("Failed updating custom role")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionRolesUpdate, "role", id, before, customRole)
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
+}
+
+func (h *Handler) DeleteCustomRole(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionRolesDelete) {
+		c.JSON(http.StatusForbidden, gin.H{"code": http.StatusText(http.StatusForbidden), "message": "JWT is invalid or request does not have correct permission"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	before, _ := h.cockroachdbClient.GetCustomRoleByID(ctx, id)
+
+	err := h.service.DeleteCustomRole(ctx, id)
+	if err != nil {
+		if errors.Is(err, cockroachdb.ErrCustomRoleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"code": http.StatusText(http.StatusNotFound)})
+			return
+		}
+		log.Error().Err(err).Msg("Failed deleting custom role")
+		c.JSON(http.StatusInternalServerError, gin.H{"code": http.StatusText(http.StatusInternalServerError)})
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionRolesDelete, "role", id, before, nil)
+
+	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
+}
+
 func (h *Handler) GetProviders(c *gin.Context) {
 
 	ctx := c.Request.Context()
@@ -129,6 +505,12 @@ func (h *Handler) LoginProvider(c *gin.Context) {
 		c.String(http.StatusInternalServerError, "Failed generating JWT to use as state")
 	}
 
+	if provider.GetConfig(h.config.APIServer.BaseURL) == nil {
+		log.Error().Msgf("Retrieving oauth config for provider %v failed", provider.Name)
+		c.String(http.StatusInternalServerError, "Retrieving oauth config for provider failed")
+		return
+	}
+
 	c.Redirect(http.StatusTemporaryRedirect, provider.AuthCodeURL(h.config.APIServer.BaseURL, state))
 }
 
@@ -165,6 +547,9 @@ func (h *Handler) HandleOAuthLoginProviderAuthenticator() func(c *gin.Context) (
 
 		// retrieve oauth config
 		cfg := provider.GetConfig(h.config.APIServer.BaseURL)
+		if cfg == nil {
+			return nil, fmt.Errorf("Retrieving oauth config for provider %v failed", provider.Name)
+		}
 		token, err := cfg.Exchange(ctx, code)
 		if err != nil {
 			return nil, err
@@ -259,6 +644,18 @@ func (h *Handler) HandleOAuthLoginProviderAuthenticator() func(c *gin.Context) (
 			}
 		}
 
+		// keep group membership in sync with the groups claimed by the identity provider
+		if provider.GroupsClaim != "" {
+			identityProviderGroups, err := provider.GetUserGroups(ctx, cfg, token)
+			if err != nil {
+				return nil, err
+			}
+			user.Groups, err = h.service.SyncGroupsFromIdentityProvider(ctx, *user, provider.Name, identityProviderGroups)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		go func(user contracts.User) {
 			err = h.service.UpdateUser(ctx, user)
 			if err != nil {
@@ -355,7 +752,66 @@ func (h *Handler) HandleImpersonateAuthenticator() func(c *gin.Context) (interfa
 		}
 		user.Organizations = inheritedOrganizations
 
-		return user, nil
+		audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersImpersonate, "user", user.ID, nil, nil)
+
+		// keep track of the original user in the impersonation token
+		claims := jwt.ExtractClaims(c)
+		impersonatorID, _ := claims[jwt.IdentityKey].(string)
+		impersonatorEmail, _ := claims["email"].(string)
+
+		return &api.ImpersonatedUser{
+			User:              user,
+			ImpersonatorID:    impersonatorID,
+			ImpersonatorEmail: impersonatorEmail,
+		}, nil
+	}
+}
+
+func (h *Handler) HandleCustomRolesResolver() api.CustomRolesResolver {
+	return func(ctx context.Context, roles []*string) ([]*api.CustomRole, error) {
+		return h.service.GetCustomRolesForRoles(ctx, roles)
+	}
+}
+
+func (h *Handler) HandlePersonalAccessTokenAuthorizator() api.PersonalAccessTokenAuthorizator {
+	return func(c *gin.Context, tokenID string) bool {
+
+		ctx := c.Request.Context()
+
+		token, err := h.cockroachdbClient.GetPersonalAccessTokenByID(ctx, tokenID)
+		if err != nil || token == nil {
+			log.Warn().Err(err).Msgf("Failed retrieving personal access token %v", tokenID)
+			return false
+		}
+
+		// ensure the token belongs to the identity in the jwt and hasn't been revoked
+		claims := jwt.ExtractClaims(c)
+		if identity, ok := claims[jwt.IdentityKey].(string); !ok || identity != token.UserID {
+			return false
+		}
+		if !token.IsValid() {
+			return false
+		}
+
+		// the token outlives the user's session, so use the current roles of the user instead of those at the time of creation
+		currentClaims, err := h.service.GetPersonalAccessTokenClaims(ctx, *token)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed retrieving current claims for personal access token %v", tokenID)
+			return false
+		}
+		c.Set("JWT_PAYLOAD", currentClaims)
+
+		// keep track of last usage without writing to the db on every single request
+		if token.LastUsedAt == nil || token.LastUsedAt.Add(time.Minute).Before(time.Now().UTC()) {
+			go func(id, lastUsedFrom string) {
+				err := h.cockroachdbClient.UpdatePersonalAccessTokenLastUsed(context.Background(), id, lastUsedFrom)
+				if err != nil {
+					log.Warn().Err(err).Msgf("Failed updating last usage of personal access token %v", id)
+				}
+			}(token.ID, c.ClientIP())
+		}
+
+		return true
 	}
 }
 
@@ -448,6 +904,8 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersCreate, "user", insertedUser.ID, nil, insertedUser)
+
 	c.JSON(http.StatusCreated, insertedUser)
 }
 
@@ -477,6 +935,8 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.cockroachdbClient.GetUserByID(ctx, id, map[api.FilterType][]string{})
+
 	err = h.service.UpdateUser(ctx, user)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed updating user")
@@ -484,6 +944,8 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersUpdate, "user", id, before, user)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -497,6 +959,8 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	id := c.Param("id")
+	before, _ := h.cockroachdbClient.GetUserByID(ctx, id, map[api.FilterType][]string{})
+
 	err := h.service.DeleteUser(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed deleting user")
@@ -504,6 +968,8 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersDelete, "user", id, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -602,6 +1068,8 @@ func (h *Handler) CreateGroup(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionGroupsCreate, "group", insertedGroup.ID, nil, insertedGroup)
+
 	c.JSON(http.StatusCreated, insertedGroup)
 }
 
@@ -631,6 +1099,8 @@ func (h *Handler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.cockroachdbClient.GetGroupByID(ctx, id, map[api.FilterType][]string{})
+
 	err = h.service.UpdateGroup(ctx, group)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed updating group")
@@ -638,6 +1108,8 @@ func (h *Handler) UpdateGroup(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionGroupsUpdate, "group", id, before, group)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -651,6 +1123,8 @@ func (h *Handler) DeleteGroup(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	id := c.Param("id")
+	before, _ := h.cockroachdbClient.GetGroupByID(ctx, id, map[api.FilterType][]string{})
+
 	err := h.service.DeleteGroup(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed deleting group")
@@ -658,6 +1132,8 @@ func (h *Handler) DeleteGroup(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionGroupsDelete, "group", id, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -756,6 +1232,8 @@ func (h *Handler) CreateOrganization(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionOrganizationsCreate, "organization", insertedOrganization.ID, nil, insertedOrganization)
+
 	c.JSON(http.StatusCreated, insertedOrganization)
 }
 
@@ -785,6 +1263,8 @@ func (h *Handler) UpdateOrganization(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
+	before, _ := h.cockroachdbClient.GetOrganizationByID(ctx, id)
+
 	err = h.service.UpdateOrganization(ctx, organization)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed updating organization")
@@ -792,6 +1272,8 @@ func (h *Handler) UpdateOrganization(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionOrganizationsUpdate, "organization", id, before, organization)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -805,6 +1287,8 @@ func (h *Handler) DeleteOrganization(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	id := c.Param("id")
+	before, _ := h.cockroachdbClient.GetOrganizationByID(ctx, id)
+
 	err := h.service.DeleteOrganization(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed deleting organization")
@@ -812,6 +1296,8 @@ func (h *Handler) DeleteOrganization(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionOrganizationsDelete, "organization", id, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -913,6 +1399,8 @@ func (h *Handler) CreateClient(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionClientsCreate, "client", insertedClient.ID, nil, insertedClient)
+
 	c.JSON(http.StatusCreated, insertedClient)
 }
 
@@ -942,6 +1430,8 @@ func (h *Handler) UpdateClient(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
+	before, _ := h.cockroachdbClient.GetClientByID(ctx, id)
+
 	err = h.service.UpdateClient(ctx, client)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed updating client")
@@ -949,6 +1439,8 @@ func (h *Handler) UpdateClient(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionClientsUpdate, "client", id, before, client)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -962,6 +1454,8 @@ func (h *Handler) DeleteClient(c *gin.Context) {
 
 	ctx := c.Request.Context()
 	id := c.Param("id")
+	before, _ := h.cockroachdbClient.GetClientByID(ctx, id)
+
 	err := h.service.DeleteClient(ctx, id)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed deleting client")
@@ -969,6 +1463,8 @@ func (h *Handler) DeleteClient(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionClientsDelete, "client", id, before, nil)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -1065,6 +1561,8 @@ func (h *Handler) UpdatePipeline(c *gin.Context) {
 		return
 	}
 
+	before, _ := h.cockroachdbClient.GetPipeline(ctx, source, owner, repo, map[api.FilterType][]string{}, false)
+
 	err = h.service.UpdatePipeline(ctx, pipeline)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed updating pipeline")
@@ -1072,6 +1570,8 @@ func (h *Handler) UpdatePipeline(c *gin.Context) {
 		return
 	}
 
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionPipelinesUpdate, "pipeline", fmt.Sprintf("%v/%v/%v", source, owner, repo), before, pipeline)
+
 	c.JSON(http.StatusOK, gin.H{"code": http.StatusText(http.StatusOK)})
 }
 
@@ -1131,6 +1631,7 @@ func (h *Handler) BatchUpdateUsers(c *gin.Context) {
 				resultChannel <- err
 				return
 			}
+			before := *user
 
 			// add role if not present
 			if body.Role != nil {
@@ -1249,6 +1750,8 @@ func (h *Handler) BatchUpdateUsers(c *gin.Context) {
 				return
 			}
 
+			audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersUpdate, "user", user.ID, before, user)
+
 			resultChannel <- nil
 		}(u)
 	}
@@ -1323,6 +1826,7 @@ func (h *Handler) BatchUpdateGroups(c *gin.Context) {
 				resultChannel <- err
 				return
 			}
+			before := *group
 
 			// add role if not present
 			if body.Role != nil {
@@ -1404,6 +1908,8 @@ func (h *Handler) BatchUpdateGroups(c *gin.Context) {
 				return
 			}
 
+			audit.RecordRequestEvent(c, h.auditService, api.PermissionGroupsUpdate, "group", group.ID, before, group)
+
 			resultChannel <- nil
 		}(g)
 	}
@@ -1475,6 +1981,7 @@ func (h *Handler) BatchUpdateOrganizations(c *gin.Context) {
 				resultChannel <- err
 				return
 			}
+			before := *organization
 
 			// add role if not present
 			if body.Role != nil {
@@ -1519,6 +2026,8 @@ func (h *Handler) BatchUpdateOrganizations(c *gin.Context) {
 				return
 			}
 
+			audit.RecordRequestEvent(c, h.auditService, api.PermissionOrganizationsUpdate, "organization", organization.ID, before, organization)
+
 			resultChannel <- nil
 		}(o)
 	}
@@ -1577,19 +2086,20 @@ func (h *Handler) BatchUpdateClients(c *gin.Context) {
 
 	resultChannel := make(chan error, len(body.Clients))
 
-	for _, c := range body.Clients {
+	for _, id := range body.Clients {
 		// try to fill semaphore up to it's full size otherwise wait for a routine to finish
 		semaphore <- true
 
-		go func(c string) {
+		go func(id string) {
 			// lower semaphore once the routine's finished, making room for another one to start
 			defer func() { <-semaphore }()
 
-			client, err := h.cockroachdbClient.GetClientByID(ctx, c)
+			client, err := h.cockroachdbClient.GetClientByID(ctx, id)
 			if err != nil {
 				resultChannel <- err
 				return
 			}
+			before := *client
 
 			// add role if not present
 			if body.Role != nil {
@@ -1634,8 +2144,10 @@ func (h *Handler) BatchUpdateClients(c *gin.Context) {
 				return
 			}
 
+			audit.RecordRequestEvent(c, h.auditService, api.PermissionClientsUpdate, "client", client.ID, before, client)
+
 			resultChannel <- nil
-		}(c)
+		}(id)
 	}
 
 	// try to fill semaphore up to it's full size which only succeeds if all routines have finished
@@ -1715,6 +2227,7 @@ func (h *Handler) BatchUpdatePipelines(c *gin.Context) {
 				resultChannel <- err
 				return
 			}
+			before := *pipeline
 
 			// add group if not present
 			if body.Group != nil {
@@ -1796,6 +2309,8 @@ func (h *Handler) BatchUpdatePipelines(c *gin.Context) {
 				return
 			}
 
+			audit.RecordRequestEvent(c, h.auditService, api.PermissionPipelinesUpdate, "pipeline", p, before, pipeline)
+
 			resultChannel <- nil
 		}(p)
 	}
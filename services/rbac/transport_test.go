@@ -0,0 +1,152 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/services/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePersonalAccessTokenQueryParameters(t *testing.T) {
+
+	t.Run("ReturnsNilForSupportedFiltersAndSortings", func(t *testing.T) {
+
+		filters := map[api.FilterType][]string{
+			api.FilterStatus: {"active"},
+			api.FilterSince:  {"1w"},
+			api.FilterSearch: nil,
+		}
+		sortings := []api.OrderField{{FieldName: "lastUsedAt", Direction: "DESC"}}
+
+		// act
+		err := validatePersonalAccessTokenQueryParameters(filters, sortings)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorForUnsupportedFilter", func(t *testing.T) {
+
+		filters := map[api.FilterType][]string{api.FilterLabels: {"team=estafette"}}
+
+		// act
+		err := validatePersonalAccessTokenQueryParameters(filters, nil)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForUnsupportedStatus", func(t *testing.T) {
+
+		filters := map[api.FilterType][]string{api.FilterStatus: {"succeeded"}}
+
+		// act
+		err := validatePersonalAccessTokenQueryParameters(filters, nil)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForUnsupportedSorting", func(t *testing.T) {
+
+		sortings := []api.OrderField{{FieldName: "name", Direction: "ASC"}}
+
+		// act
+		err := validatePersonalAccessTokenQueryParameters(map[api.FilterType][]string{}, sortings)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCreatePersonalAccessToken(t *testing.T) {
+
+	getContext := func(recorder *httptest.ResponseRecorder, claims jwt.MapClaims) *gin.Context {
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "https://ci.estafette.io/api/users/me/tokens", strings.NewReader(`{"name":"ci","scopes":["pipelines.get"]}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("JWT_PAYLOAD", claims)
+		return c
+	}
+
+	getService := func(created *bool) MockService {
+		return MockService{
+			CreatePersonalAccessTokenFunc: func(ctx context.Context, userID string, token api.PersonalAccessToken) (insertedToken *api.PersonalAccessToken, err error) {
+				*created = true
+				return &token, nil
+			},
+		}
+	}
+
+	t.Run("ReturnsForbiddenWhileImpersonating", func(t *testing.T) {
+
+		created := false
+		handler := NewHandler(&api.APIConfig{}, getService(&created), cockroachdb.MockClient{}, audit.MockService{})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.CreatePersonalAccessToken(getContext(recorder, jwt.MapClaims{
+			jwt.IdentityKey:  "5",
+			"impersonatorID": "1",
+		}))
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.False(t, created)
+	})
+
+	t.Run("ReturnsForbiddenForClient", func(t *testing.T) {
+
+		created := false
+		handler := NewHandler(&api.APIConfig{}, getService(&created), cockroachdb.MockClient{}, audit.MockService{})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.CreatePersonalAccessToken(getContext(recorder, jwt.MapClaims{
+			jwt.IdentityKey: "7",
+			"clientID":      "estafette-ci-cron",
+		}))
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.False(t, created)
+	})
+
+	t.Run("CreatesTokenForUserThemselves", func(t *testing.T) {
+
+		created := false
+		handler := NewHandler(&api.APIConfig{}, getService(&created), cockroachdb.MockClient{}, audit.MockService{})
+		recorder := httptest.NewRecorder()
+
+		// act
+		handler.CreatePersonalAccessToken(getContext(recorder, jwt.MapClaims{
+			jwt.IdentityKey: "5",
+		}))
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.True(t, created)
+	})
+}
+
+func TestRevokePersonalAccessToken(t *testing.T) {
+
+	t.Run("ReturnsForbiddenWhileImpersonating", func(t *testing.T) {
+
+		handler := NewHandler(&api.APIConfig{}, MockService{}, cockroachdb.MockClient{}, audit.MockService{})
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("DELETE", "https://ci.estafette.io/api/users/me/tokens/3", nil)
+		c.Params = gin.Params{{Key: "id", Value: "3"}}
+		c.Set("JWT_PAYLOAD", jwt.MapClaims{
+			jwt.IdentityKey:  "5",
+			"impersonatorID": "1",
+		})
+
+		// act
+		handler.RevokePersonalAccessToken(c)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
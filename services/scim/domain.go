@@ -0,0 +1,350 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+
+	contentType = "application/scim+json"
+)
+
+// User is the scim representation of a contracts.User
+type User struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *Name       `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []Reference `json:"emails,omitempty"`
+	Active      *Bool       `json:"active,omitempty"`
+	Groups      []Reference `json:"groups,omitempty"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+// Name holds the components of a user's name
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// Group is the scim representation of a contracts.Group
+type Group struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	DisplayName string      `json:"displayName"`
+	Members     []Reference `json:"members"`
+	Meta        *Meta       `json:"meta,omitempty"`
+}
+
+// Reference is a multi-valued attribute, used for emails, group members and a user's groups
+type Reference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// Meta holds resource metadata
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// ListResponse wraps a page of resources
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// PatchRequest holds the operations of a PATCH request
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is a single add, replace or remove operation
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Error is returned for any failed request
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// Bool accepts both json booleans and the string values some identity providers send instead
+type Bool bool
+
+// UnmarshalJSON parses true, false, "true" and "false"
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	switch strings.ToLower(strings.Trim(string(data), `"`)) {
+	case "true":
+		*b = true
+	case "false", "null":
+		*b = false
+	default:
+		return fmt.Errorf("Value %v is not a boolean", string(data))
+	}
+
+	return nil
+}
+
+func newBool(value bool) *Bool {
+	b := Bool(value)
+	return &b
+}
+
+// toUser maps a contracts.User to its scim representation; the identity for the provisioning provider takes precedence over other identities
+func toUser(user contracts.User, provider, baseURL string) User {
+
+	identity := getUserIdentity(user, provider)
+
+	scimUser := User{
+		Schemas:     []string{schemaUser},
+		ID:          user.ID,
+		UserName:    user.GetEmail(),
+		DisplayName: user.GetName(),
+		Active:      newBool(user.Active),
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      user.FirstVisit,
+			LastModified: user.LastVisit,
+			Location:     fmt.Sprintf("%v/scim/v2/Users/%v", strings.TrimSuffix(baseURL, "/"), user.ID),
+		},
+	}
+
+	if identity != nil {
+		scimUser.ExternalID = identity.ID
+		if identity.Email != "" {
+			scimUser.UserName = identity.Email
+		}
+		if identity.Name != "" {
+			scimUser.DisplayName = identity.Name
+		}
+	}
+
+	if scimUser.DisplayName != "" {
+		scimUser.Name = &Name{
+			Formatted: scimUser.DisplayName,
+		}
+	}
+	if scimUser.UserName != "" {
+		scimUser.Emails = []Reference{
+			{
+				Value:   scimUser.UserName,
+				Type:    "work",
+				Primary: true,
+			},
+		}
+	}
+
+	for _, g := range user.Groups {
+		if g == nil {
+			continue
+		}
+		scimUser.Groups = append(scimUser.Groups, Reference{
+			Value:   g.ID,
+			Display: g.Name,
+			Ref:     fmt.Sprintf("%v/scim/v2/Groups/%v", strings.TrimSuffix(baseURL, "/"), g.ID),
+		})
+	}
+
+	return scimUser
+}
+
+// applyToUser copies the scim attributes into the identity of the user for the provisioning provider
+func applyToUser(scimUser User, user *contracts.User, provider string) {
+
+	identity := getUserIdentity(*user, provider)
+	if identity == nil {
+		identity = &contracts.UserIdentity{
+			Provider: provider,
+		}
+		user.Identities = append(user.Identities, identity)
+	}
+
+	identity.ID = scimUser.ExternalID
+	identity.Email = scimUser.getEmail()
+	identity.Name = scimUser.getDisplayName()
+
+	// users are active unless explicitly deactivated
+	user.Active = scimUser.Active == nil || bool(*scimUser.Active)
+	user.Name = user.GetName()
+	user.Email = user.GetEmail()
+}
+
+func getUserIdentity(user contracts.User, provider string) *contracts.UserIdentity {
+	for _, i := range user.Identities {
+		if i != nil && i.Provider == provider {
+			return i
+		}
+	}
+
+	return nil
+}
+
+// getEmail returns the user name if it's an email address, falling back to the primary email address
+func (u User) getEmail() string {
+	if strings.Contains(u.UserName, "@") {
+		return u.UserName
+	}
+	for _, e := range u.Emails {
+		if e.Primary && e.Value != "" {
+			return e.Value
+		}
+	}
+	for _, e := range u.Emails {
+		if e.Value != "" {
+			return e.Value
+		}
+	}
+
+	return u.UserName
+}
+
+func (u User) getDisplayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		if u.Name.GivenName != "" || u.Name.FamilyName != "" {
+			return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+		}
+	}
+
+	return u.getEmail()
+}
+
+// toGroup maps a contracts.Group and its members to the scim representation
+func toGroup(group contracts.Group, members []*contracts.User, provider, baseURL string) Group {
+
+	scimGroup := Group{
+		Schemas:     []string{schemaGroup},
+		ID:          group.ID,
+		DisplayName: group.Name,
+		Members:     []Reference{},
+		Meta: &Meta{
+			ResourceType: "Group",
+			Location:     fmt.Sprintf("%v/scim/v2/Groups/%v", strings.TrimSuffix(baseURL, "/"), group.ID),
+		},
+	}
+
+	if identity := getGroupIdentity(group, provider); identity != nil {
+		scimGroup.ExternalID = identity.ID
+	}
+
+	for _, m := range members {
+		if m == nil {
+			continue
+		}
+		scimGroup.Members = append(scimGroup.Members, Reference{
+			Value:   m.ID,
+			Display: m.GetName(),
+			Ref:     fmt.Sprintf("%v/scim/v2/Users/%v", strings.TrimSuffix(baseURL, "/"), m.ID),
+		})
+	}
+
+	return scimGroup
+}
+
+// applyToGroup copies the scim attributes into the group; the identity for the provisioning provider links the group to identity provider group claims
+func applyToGroup(scimGroup Group, group *contracts.Group, provider string) {
+
+	group.Name = scimGroup.DisplayName
+
+	identity := getGroupIdentity(*group, provider)
+	if identity == nil {
+		identity = &contracts.GroupIdentity{
+			Provider: provider,
+		}
+		group.Identities = append(group.Identities, identity)
+	}
+
+	identity.ID = scimGroup.ExternalID
+	identity.Name = scimGroup.DisplayName
+}
+
+func getGroupIdentity(group contracts.Group, provider string) *contracts.GroupIdentity {
+	for _, i := range group.Identities {
+		if i != nil && i.Provider == provider {
+			return i
+		}
+	}
+
+	return nil
+}
+
+// memberIDs returns the distinct user ids of the group members
+func (g Group) memberIDs() (ids []string) {
+	for _, m := range g.Members {
+		if m.Value == "" {
+			continue
+		}
+		isDuplicate := false
+		for _, id := range ids {
+			if id == m.Value {
+				isDuplicate = true
+				break
+			}
+		}
+		if !isDuplicate {
+			ids = append(ids, m.Value)
+		}
+	}
+
+	return ids
+}
+
+// toMap converts a resource to generic json, so filters and patch operations can be applied to it
+func toMap(resource interface{}) (resourceMap map[string]interface{}, err error) {
+	bytes, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(bytes, &resourceMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourceMap, nil
+}
+
+// fromMap converts generic json back into a typed resource
+func fromMap(resourceMap map[string]interface{}, resource interface{}) (err error) {
+	bytes, err := json.Marshal(resourceMap)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(bytes, resource)
+}
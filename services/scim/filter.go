@@ -0,0 +1,423 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/estafette/estafette-ci-api/api"
+)
+
+var (
+	// ErrInvalidFilter is returned if a filter or a patch path can't be parsed
+	ErrInvalidFilter = errors.New("The filter is invalid")
+)
+
+// filter is a parsed scim filter expression as described in rfc 7644 section 3.4.2.2
+type filter interface {
+	matches(resource map[string]interface{}) bool
+}
+
+type logicalFilter struct {
+	operator string
+	left     filter
+	right    filter
+}
+
+type notFilter struct {
+	filter filter
+}
+
+type attributeFilter struct {
+	path     string
+	operator string
+	value    interface{}
+}
+
+func (f logicalFilter) matches(resource map[string]interface{}) bool {
+	if f.operator == "and" {
+		return f.left.matches(resource) && f.right.matches(resource)
+	}
+	return f.left.matches(resource) || f.right.matches(resource)
+}
+
+func (f notFilter) matches(resource map[string]interface{}) bool {
+	return !f.filter.matches(resource)
+}
+
+func (f attributeFilter) matches(resource map[string]interface{}) bool {
+
+	values := getAttributeValues(resource, f.path)
+
+	if f.operator == "pr" {
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+		return false
+	}
+
+	// multi-valued attributes match if any of their values matches
+	for _, v := range values {
+		if compareValues(v, f.operator, f.value) {
+			return true
+		}
+	}
+
+	// a missing attribute is not equal to anything
+	return len(values) == 0 && f.operator == "ne" && f.value != nil
+}
+
+// getUserDatabaseFilters returns database filters narrowing down the users the filter can match, so not all users have to be retrieved; the filter itself still needs to be applied to the users that are returned
+func getUserDatabaseFilters(f filter) map[api.FilterType][]string {
+
+	filters := map[api.FilterType][]string{}
+
+	switch v := f.(type) {
+	case attributeFilter:
+		value, ok := v.value.(string)
+		if v.operator != "eq" || !ok {
+			break
+		}
+		switch strings.ToLower(v.path) {
+		case "username", "emails", "emails.value":
+			filters[api.FilterIdentityEmail] = []string{value}
+		case "externalid":
+			filters[api.FilterIdentityID] = []string{value}
+		}
+
+	case logicalFilter:
+		// only both sides of an and have to match, so either one narrows down the users
+		if v.operator != "and" {
+			break
+		}
+		for _, side := range []filter{v.right, v.left} {
+			for filterType, values := range getUserDatabaseFilters(side) {
+				filters[filterType] = values
+			}
+		}
+	}
+
+	return filters
+}
+
+// getAttributeValues returns all values for a dotted attribute path; attribute names are case insensitive and arrays are flattened
+func getAttributeValues(resource map[string]interface{}, path string) (values []interface{}) {
+
+	parts := strings.SplitN(path, ".", 2)
+	key, ok := findKey(resource, parts[0])
+	if !ok {
+		return nil
+	}
+
+	value := resource[key]
+
+	items := []interface{}{value}
+	if array, ok := value.([]interface{}); ok {
+		items = array
+	}
+
+	for _, item := range items {
+		if len(parts) == 1 {
+			values = append(values, item)
+			continue
+		}
+		if itemMap, ok := item.(map[string]interface{}); ok {
+			values = append(values, getAttributeValues(itemMap, parts[1])...)
+		}
+	}
+
+	return values
+}
+
+// findKey returns the key in the map matching the attribute name case insensitively
+func findKey(resource map[string]interface{}, name string) (key string, ok bool) {
+	if _, ok := resource[name]; ok {
+		return name, true
+	}
+	for k := range resource {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+
+	return name, false
+}
+
+func compareValues(actual interface{}, operator string, expected interface{}) bool {
+
+	switch expectedValue := expected.(type) {
+	case string:
+		actualValue, ok := actual.(string)
+		if !ok {
+			return operator == "ne"
+		}
+		// string attributes of users and groups aren't case exact
+		actualValue = strings.ToLower(actualValue)
+		expectedValue = strings.ToLower(expectedValue)
+
+		switch operator {
+		case "eq":
+			return actualValue == expectedValue
+		case "ne":
+			return actualValue != expectedValue
+		case "co":
+			return strings.Contains(actualValue, expectedValue)
+		case "sw":
+			return strings.HasPrefix(actualValue, expectedValue)
+		case "ew":
+			return strings.HasSuffix(actualValue, expectedValue)
+		case "gt":
+			return actualValue > expectedValue
+		case "ge":
+			return actualValue >= expectedValue
+		case "lt":
+			return actualValue < expectedValue
+		case "le":
+			return actualValue <= expectedValue
+		}
+
+	case float64:
+		actualValue, ok := actual.(float64)
+		if !ok {
+			return operator == "ne"
+		}
+
+		switch operator {
+		case "eq":
+			return actualValue == expectedValue
+		case "ne":
+			return actualValue != expectedValue
+		case "gt":
+			return actualValue > expectedValue
+		case "ge":
+			return actualValue >= expectedValue
+		case "lt":
+			return actualValue < expectedValue
+		case "le":
+			return actualValue <= expectedValue
+		}
+
+	default:
+		// booleans and null only support equality
+		switch operator {
+		case "eq":
+			return actual == expected
+		case "ne":
+			return actual != expected
+		}
+	}
+
+	return false
+}
+
+// parseFilter parses a filter like userName eq "john@estafette.io" and active eq true
+func parseFilter(input string) (f filter, err error) {
+
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := filterParser{tokens: tokens}
+	f, err = parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if parser.position != len(parser.tokens) {
+		return nil, fmt.Errorf("%w: unexpected token %v", ErrInvalidFilter, parser.tokens[parser.position])
+	}
+
+	return f, nil
+}
+
+type filterParser struct {
+	tokens   []string
+	position int
+}
+
+func (p *filterParser) peek() string {
+	if p.position >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.position]
+}
+
+func (p *filterParser) next() string {
+	token := p.peek()
+	p.position++
+	return token
+}
+
+func (p *filterParser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{operator: "or", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filter, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "and") {
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalFilter{operator: "and", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseTerm() (filter, error) {
+
+	token := p.next()
+
+	if strings.EqualFold(token, "not") {
+		if p.next() != "(" {
+			return nil, fmt.Errorf("%w: expected ( after not", ErrInvalidFilter)
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidFilter)
+		}
+		return notFilter{filter: f}, nil
+	}
+
+	if token == "(" {
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidFilter)
+		}
+		return f, nil
+	}
+
+	if token == "" || token == ")" || strings.HasPrefix(token, `"`) {
+		return nil, fmt.Errorf("%w: expected attribute path", ErrInvalidFilter)
+	}
+
+	path := stripSchema(token)
+	operator := strings.ToLower(p.next())
+
+	switch operator {
+	case "pr":
+		return attributeFilter{path: path, operator: operator}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+		value, err := parseFilterValue(p.next())
+		if err != nil {
+			return nil, err
+		}
+		return attributeFilter{path: path, operator: operator, value: value}, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown operator %v", ErrInvalidFilter, operator)
+}
+
+func parseFilterValue(token string) (value interface{}, err error) {
+
+	if strings.HasPrefix(token, `"`) {
+		unquoted, err := strconv.Unquote(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid string %v", ErrInvalidFilter, token)
+		}
+		return unquoted, nil
+	}
+
+	switch strings.ToLower(token) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+
+	number, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid value %v", ErrInvalidFilter, token)
+	}
+
+	return number, nil
+}
+
+// tokenizeFilter splits a filter in attribute paths, operators, values and parentheses
+func tokenizeFilter(input string) (tokens []string, err error) {
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+
+		case r == '"':
+			j := i + 1
+			for ; j < len(runes); j++ {
+				if runes[j] == '\\' {
+					j++
+					continue
+				}
+				if runes[j] == '"' {
+					break
+				}
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+			}
+			tokens = append(tokens, string(runes[i:j+1]))
+			i = j + 1
+
+		default:
+			j := i
+			for ; j < len(runes) && !unicode.IsSpace(runes[j]) && runes[j] != '(' && runes[j] != ')'; j++ {
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty filter", ErrInvalidFilter)
+	}
+
+	return tokens, nil
+}
+
+// stripSchema removes the core schema urn attribute names can be prefixed with
+func stripSchema(path string) string {
+	for _, schema := range []string{schemaUser, schemaGroup} {
+		if len(path) > len(schema) && strings.EqualFold(path[:len(schema)+1], schema+":") {
+			return path[len(schema)+1:]
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,118 @@
+package scim
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+
+	resource := map[string]interface{}{
+		"userName":    "John@estafette.io",
+		"displayName": "John Doe",
+		"active":      true,
+		"emails": []interface{}{
+			map[string]interface{}{"value": "john@estafette.io", "type": "work"},
+			map[string]interface{}{"value": "john@example.com", "type": "home"},
+		},
+		"meta": map[string]interface{}{"resourceType": "User"},
+	}
+
+	t.Run("MatchesEqualityCaseInsensitively", func(t *testing.T) {
+
+		// act
+		f, err := parseFilter(`username eq "john@estafette.io"`)
+
+		assert.Nil(t, err)
+		assert.True(t, f.matches(resource))
+	})
+
+	t.Run("MatchesAnyValueOfMultiValuedAttribute", func(t *testing.T) {
+
+		// act
+		f, err := parseFilter(`emails.value ew "example.com"`)
+
+		assert.Nil(t, err)
+		assert.True(t, f.matches(resource))
+	})
+
+	t.Run("AppliesLogicalOperatorsAndParentheses", func(t *testing.T) {
+
+		// act
+		f, err := parseFilter(`(displayName sw "Jane" or meta.resourceType eq "User") and not (active eq false)`)
+
+		assert.Nil(t, err)
+		assert.True(t, f.matches(resource))
+	})
+
+	t.Run("MatchesPresenceOfAttribute", func(t *testing.T) {
+
+		// act
+		present, err1 := parseFilter(`displayName pr`)
+		missing, err2 := parseFilter(`externalId pr`)
+
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		assert.True(t, present.matches(resource))
+		assert.False(t, missing.matches(resource))
+	})
+
+	t.Run("StripsCoreSchemaFromAttributePath", func(t *testing.T) {
+
+		// act
+		f, err := parseFilter(`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "john@estafette.io"`)
+
+		assert.Nil(t, err)
+		assert.True(t, f.matches(resource))
+	})
+
+	t.Run("ReturnsErrorForInvalidFilter", func(t *testing.T) {
+
+		for _, input := range []string{`userName eq`, `userName xx "a"`, `(userName eq "a"`, `userName eq "a`, `userName eq "a" "b"`} {
+			// act
+			_, err := parseFilter(input)
+
+			assert.True(t, errors.Is(err, ErrInvalidFilter), input)
+		}
+	})
+}
+
+func TestGetUserDatabaseFilters(t *testing.T) {
+
+	t.Run("ReturnsIdentityEmailFilterForUserNameEquality", func(t *testing.T) {
+
+		f, err := parseFilter(`userName eq "john@estafette.io"`)
+		assert.Nil(t, err)
+
+		// act
+		filters := getUserDatabaseFilters(f)
+
+		assert.Equal(t, map[api.FilterType][]string{api.FilterIdentityEmail: {"john@estafette.io"}}, filters)
+	})
+
+	t.Run("ReturnsFiltersForBothSidesOfAnd", func(t *testing.T) {
+
+		f, err := parseFilter(`externalId eq "123" and userName eq "john@estafette.io"`)
+		assert.Nil(t, err)
+
+		// act
+		filters := getUserDatabaseFilters(f)
+
+		assert.Equal(t, []string{"123"}, filters[api.FilterIdentityID])
+		assert.Equal(t, []string{"john@estafette.io"}, filters[api.FilterIdentityEmail])
+	})
+
+	t.Run("ReturnsNoFiltersForOr", func(t *testing.T) {
+
+		f, err := parseFilter(`userName eq "john@estafette.io" or userName eq "jane@estafette.io"`)
+		assert.Nil(t, err)
+
+		// act
+		filters := getUserDatabaseFilters(f)
+
+		assert.Equal(t, 0, len(filters))
+	})
+}
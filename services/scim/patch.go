@@ -0,0 +1,228 @@
+package scim
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// patchPath is a parsed patch path like members[value eq "5"] or name.givenName
+type patchPath struct {
+	attribute    string
+	valueFilter  filter
+	subAttribute string
+}
+
+func parsePatchPath(path string) (p patchPath, err error) {
+
+	path = stripSchema(strings.TrimSpace(path))
+
+	if i := strings.Index(path, "["); i >= 0 {
+		j := strings.LastIndex(path, "]")
+		if j < i {
+			return p, fmt.Errorf("%w: missing ] in path %v", ErrInvalidFilter, path)
+		}
+		p.attribute = path[:i]
+		p.valueFilter, err = parseFilter(path[i+1 : j])
+		if err != nil {
+			return p, err
+		}
+		p.subAttribute = strings.TrimPrefix(path[j+1:], ".")
+	} else {
+		parts := strings.SplitN(path, ".", 2)
+		p.attribute = parts[0]
+		if len(parts) == 2 {
+			p.subAttribute = parts[1]
+		}
+	}
+
+	if p.attribute == "" {
+		return p, fmt.Errorf("%w: missing attribute in path %v", ErrInvalidFilter, path)
+	}
+
+	return p, nil
+}
+
+// applyPatchOperations applies add, replace and remove operations as described in rfc 7644 section 3.5.2 to a resource in its generic json form
+func applyPatchOperations(resource map[string]interface{}, operations []PatchOperation) (err error) {
+	for _, o := range operations {
+		err = applyPatchOperation(resource, o)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func applyPatchOperation(resource map[string]interface{}, operation PatchOperation) (err error) {
+
+	op := strings.ToLower(operation.Op)
+	if op != "add" && op != "replace" && op != "remove" {
+		return fmt.Errorf("%w: unknown operation %v", ErrInvalidFilter, operation.Op)
+	}
+
+	// without a path the value holds the attributes to add or replace
+	if operation.Path == "" {
+		if op == "remove" {
+			return fmt.Errorf("%w: remove requires a path", ErrInvalidFilter)
+		}
+		values, ok := operation.Value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: value without path has to be an object", ErrInvalidFilter)
+		}
+		for name, value := range values {
+			err = applyPatchOperation(resource, PatchOperation{Op: op, Path: name, Value: value})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(operation.Path)
+	if err != nil {
+		return err
+	}
+
+	key, _ := findKey(resource, path.attribute)
+
+	if path.valueFilter != nil {
+		return applyFilteredPatchOperation(resource, key, path, op, operation.Value)
+	}
+
+	if path.subAttribute != "" {
+		subResource, ok := resource[key].(map[string]interface{})
+		if !ok {
+			if op == "remove" {
+				return nil
+			}
+			subResource = map[string]interface{}{}
+			resource[key] = subResource
+		}
+		return applyPatchOperation(subResource, PatchOperation{Op: op, Path: path.subAttribute, Value: operation.Value})
+	}
+
+	switch op {
+	case "remove":
+		// some identity providers pass the values to remove from a multi-valued attribute instead of using a value filter
+		if existing, ok := resource[key].([]interface{}); ok && operation.Value != nil {
+			resource[key] = removeValues(existing, operation.Value)
+			return nil
+		}
+		delete(resource, key)
+
+	case "add":
+		// values are appended to multi-valued attributes
+		if existing, ok := resource[key].([]interface{}); ok {
+			resource[key] = appendDistinct(existing, operation.Value)
+			return nil
+		}
+		resource[key] = operation.Value
+
+	case "replace":
+		resource[key] = operation.Value
+	}
+
+	return nil
+}
+
+// applyFilteredPatchOperation applies an operation to the values of a multi-valued attribute matching the value filter
+func applyFilteredPatchOperation(resource map[string]interface{}, key string, path patchPath, op string, value interface{}) error {
+
+	items, _ := resource[key].([]interface{})
+
+	remainingItems := make([]interface{}, 0)
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok || !path.valueFilter.matches(itemMap) {
+			remainingItems = append(remainingItems, item)
+			continue
+		}
+
+		switch {
+		case op == "remove" && path.subAttribute == "":
+			// drop the matching value
+			continue
+		case op == "remove":
+			subKey, _ := findKey(itemMap, path.subAttribute)
+			delete(itemMap, subKey)
+		case path.subAttribute != "":
+			subKey, _ := findKey(itemMap, path.subAttribute)
+			itemMap[subKey] = value
+		default:
+			values, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%w: value for filtered path has to be an object", ErrInvalidFilter)
+			}
+			for k, v := range values {
+				itemMap[k] = v
+			}
+		}
+
+		remainingItems = append(remainingItems, itemMap)
+	}
+
+	resource[key] = remainingItems
+
+	return nil
+}
+
+// appendDistinct adds one or more values to a multi-valued attribute, skipping values already present
+func appendDistinct(existing []interface{}, value interface{}) []interface{} {
+
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	for _, v := range values {
+		isPresent := false
+		for _, e := range existing {
+			if reflect.DeepEqual(e, v) || (getReferenceValue(e) != "" && getReferenceValue(e) == getReferenceValue(v)) {
+				isPresent = true
+				break
+			}
+		}
+		if !isPresent {
+			existing = append(existing, v)
+		}
+	}
+
+	return existing
+}
+
+func getReferenceValue(item interface{}) string {
+	if itemMap, ok := item.(map[string]interface{}); ok {
+		if value, ok := itemMap["value"].(string); ok {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// removeValues removes one or more values from a multi-valued attribute
+func removeValues(existing []interface{}, value interface{}) []interface{} {
+
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	remaining := make([]interface{}, 0)
+	for _, e := range existing {
+		isRemoved := false
+		for _, v := range values {
+			if reflect.DeepEqual(e, v) || (getReferenceValue(e) != "" && getReferenceValue(e) == getReferenceValue(v)) {
+				isRemoved = true
+				break
+			}
+		}
+		if !isRemoved {
+			remaining = append(remaining, e)
+		}
+	}
+
+	return remaining
+}
@@ -0,0 +1,79 @@
+package scim
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPatchOperations(t *testing.T) {
+
+	t.Run("ReplacesAttributesWithoutPath", func(t *testing.T) {
+
+		resource := map[string]interface{}{"userName": "john@estafette.io", "active": true}
+
+		// act
+		err := applyPatchOperations(resource, []PatchOperation{{Op: "Replace", Value: map[string]interface{}{"active": false}}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, false, resource["active"])
+		assert.Equal(t, "john@estafette.io", resource["userName"])
+	})
+
+	t.Run("ReplacesSubAttribute", func(t *testing.T) {
+
+		resource := map[string]interface{}{"name": map[string]interface{}{"givenName": "John"}}
+
+		// act
+		err := applyPatchOperations(resource, []PatchOperation{{Op: "replace", Path: "name.familyName", Value: "Doe"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"givenName": "John", "familyName": "Doe"}, resource["name"])
+	})
+
+	t.Run("AddsMembersWithoutDuplicates", func(t *testing.T) {
+
+		resource := map[string]interface{}{"members": []interface{}{map[string]interface{}{"value": "1", "display": "John"}}}
+
+		// act
+		err := applyPatchOperations(resource, []PatchOperation{{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": "1"}, map[string]interface{}{"value": "2"}}}})
+
+		assert.Nil(t, err)
+		if assert.Equal(t, 2, len(resource["members"].([]interface{}))) {
+			assert.Equal(t, "2", getReferenceValue(resource["members"].([]interface{})[1]))
+		}
+	})
+
+	t.Run("RemovesMemberMatchingValueFilter", func(t *testing.T) {
+
+		resource := map[string]interface{}{"members": []interface{}{map[string]interface{}{"value": "1"}, map[string]interface{}{"value": "2"}}}
+
+		// act
+		err := applyPatchOperations(resource, []PatchOperation{{Op: "remove", Path: `members[value eq "1"]`}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"value": "2"}}, resource["members"])
+	})
+
+	t.Run("RemovesMembersPassedAsValue", func(t *testing.T) {
+
+		resource := map[string]interface{}{"members": []interface{}{map[string]interface{}{"value": "1"}, map[string]interface{}{"value": "2"}}}
+
+		// act
+		err := applyPatchOperations(resource, []PatchOperation{{Op: "remove", Path: "members", Value: []interface{}{map[string]interface{}{"value": "2"}}}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{map[string]interface{}{"value": "1"}}, resource["members"])
+	})
+
+	t.Run("ReturnsErrorForUnknownOperation", func(t *testing.T) {
+
+		resource := map[string]interface{}{}
+
+		// act
+		err := applyPatchOperations(resource, []PatchOperation{{Op: "move", Path: "userName"}})
+
+		assert.True(t, errors.Is(err, ErrInvalidFilter))
+	})
+}
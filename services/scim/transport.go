@@ -0,0 +1,911 @@
+package scim
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/services/audit"
+	"github.com/estafette/estafette-ci-api/services/rbac"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrUnknownMember is returned if a group member refers to a user that doesn't exist
+	ErrUnknownMember = errors.New("The group member is not a known user")
+)
+
+const (
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+// NewHandler returns a new scim.Handler
+func NewHandler(config *api.APIConfig, rbacService rbac.Service, cockroachdbClient cockroachdb.Client, auditService audit.Service) Handler {
+	return Handler{
+		config:            config,
+		rbacService:       rbacService,
+		cockroachdbClient: cockroachdbClient,
+		auditService:      auditService,
+	}
+}
+
+type Handler struct {
+	config            *api.APIConfig
+	rbacService       rbac.Service
+	cockroachdbClient cockroachdb.Client
+	auditService      audit.Service
+}
+
+// Middleware authenticates requests by a bearer token in the form <clientID>:<clientSecret> of an active client; the client's roles determine what it's allowed to provision
+func (h *Handler) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+
+		authorization := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authorization, "Bearer ") {
+			h.abortWithError(c, http.StatusUnauthorized, "", "Bearer token is missing")
+			return
+		}
+
+		credentials := strings.SplitN(strings.TrimPrefix(authorization, "Bearer "), ":", 2)
+		if len(credentials) != 2 || credentials[0] == "" || credentials[1] == "" {
+			h.abortWithError(c, http.StatusUnauthorized, "", "Bearer token is not of form <clientID>:<clientSecret>")
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		client, err := h.cockroachdbClient.GetClientByClientID(ctx, credentials[0])
+		if err != nil || client == nil || !client.Active || subtle.ConstantTimeCompare([]byte(client.ClientSecret), []byte(credentials[1])) != 1 {
+			log.Warn().Err(err).Msgf("Scim authentication failed for client id %v", credentials[0])
+			h.abortWithError(c, http.StatusUnauthorized, "", "Bearer token is invalid")
+			return
+		}
+
+		customRoles, err := h.rbacService.GetCustomRolesForRoles(ctx, client.Roles)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed retrieving custom roles for client id %v", client.ClientID)
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+
+		// set the same claims as a client login, so the regular permission checks apply; they're round-tripped through json to get the same types as a decoded jwt
+		claimsBytes, err := json.Marshal(map[string]interface{}{
+			jwt.IdentityKey: client.ID,
+			"clientID":      client.ClientID,
+			"roles":         client.Roles,
+			"customRoles":   customRoles,
+		})
+		if err != nil {
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+		claims := jwt.MapClaims{}
+		if err = json.Unmarshal(claimsBytes, &claims); err != nil {
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+		c.Set("JWT_PAYLOAD", claims)
+
+		c.Next()
+	}
+}
+
+func (h *Handler) GetServiceProviderConfig(c *gin.Context) {
+	h.respond(c, http.StatusOK, gin.H{
+		"schemas":        []string{schemaServiceProviderConfig},
+		"patch":          gin.H{"supported": true},
+		"bulk":           gin.H{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         gin.H{"supported": true, "maxResults": maxCount},
+		"changePassword": gin.H{"supported": false},
+		"sort":           gin.H{"supported": false},
+		"etag":           gin.H{"supported": false},
+		"authenticationSchemes": []gin.H{
+			{
+				"type":        "oauthbearertoken",
+				"name":        "Client credentials",
+				"description": "Bearer token of form <clientID>:<clientSecret> for a client with permissions to manage users and groups",
+			},
+		},
+	})
+}
+
+func (h *Handler) GetUsers(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersList) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to list users")
+		return
+	}
+
+	startIndex, count := getPagingParameters(c)
+	f, err := getFilter(c)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// let the database narrow down the users for common filters like userName eq, so not every user is retrieved
+	filters := getUserDatabaseFilters(f)
+	filters[api.FilterActive] = []string{"true", "false"}
+
+	users, err := h.getUsers(ctx, filters)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed retrieving users from db")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	resources := make([]interface{}, 0)
+	for _, u := range users {
+		scimUser := toUser(*u, h.provider(), h.baseURL())
+		isMatch, err := matchesFilter(f, scimUser)
+		if err != nil {
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+		if isMatch {
+			resources = append(resources, scimUser)
+		}
+	}
+
+	h.respond(c, http.StatusOK, getListResponse(resources, startIndex, count))
+}
+
+func (h *Handler) GetUser(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersGet) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to get users")
+		return
+	}
+
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	h.respond(c, http.StatusOK, toUser(*user, h.provider(), h.baseURL()))
+}
+
+func (h *Handler) CreateUser(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersCreate) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to create users")
+		return
+	}
+
+	var scimUser User
+	err := c.BindJSON(&scimUser)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidSyntax", "Binding CreateUser body failed")
+		return
+	}
+	if scimUser.getEmail() == "" {
+		h.abortWithError(c, http.StatusBadRequest, "invalidValue", "User name is required")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// user names have to be unique
+	existingUser, err := h.getUserByEmail(ctx, scimUser.getEmail())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed retrieving user by identity from db")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	if existingUser != nil && existingUser.Active {
+		h.abortWithError(c, http.StatusConflict, "uniqueness", fmt.Sprintf("User %v already exists", scimUser.getEmail()))
+		return
+	}
+	if existingUser != nil {
+		// a user deprovisioned earlier is provisioned again, so reactivate it instead of creating a duplicate
+		h.saveUser(c, existingUser, scimUser, http.StatusCreated)
+		return
+	}
+
+	user := contracts.User{}
+	applyToUser(scimUser, &user, h.provider())
+
+	insertedUser, err := h.rbacService.CreateUser(ctx, user)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed inserting user")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	// users are always created active, so deactivate them afterwards if requested
+	if !user.Active {
+		err = h.rbacService.DeleteUser(ctx, insertedUser.ID)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed deactivating user")
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+		insertedUser.Active = false
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersCreate, "user", insertedUser.ID, nil, insertedUser)
+
+	h.respond(c, http.StatusCreated, toUser(*insertedUser, h.provider(), h.baseURL()))
+}
+
+func (h *Handler) UpdateUser(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersUpdate) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to update users")
+		return
+	}
+
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var scimUser User
+	err := c.BindJSON(&scimUser)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidSyntax", "Binding UpdateUser body failed")
+		return
+	}
+
+	h.saveUser(c, user, scimUser, http.StatusOK)
+}
+
+func (h *Handler) PatchUser(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersUpdate) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to update users")
+		return
+	}
+
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	var patchRequest PatchRequest
+	err := c.BindJSON(&patchRequest)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidSyntax", "Binding PatchUser body failed")
+		return
+	}
+
+	resource, err := toMap(toUser(*user, h.provider(), h.baseURL()))
+	if err != nil {
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	err = applyPatchOperations(resource, patchRequest.Operations)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidPath", err.Error())
+		return
+	}
+
+	var scimUser User
+	err = fromMap(resource, &scimUser)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	h.saveUser(c, user, scimUser, http.StatusOK)
+}
+
+func (h *Handler) DeleteUser(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionUsersDelete) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to delete users")
+		return
+	}
+
+	user, ok := h.getUser(c)
+	if !ok {
+		return
+	}
+
+	err := h.rbacService.DeleteUser(c.Request.Context(), user.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed deleting user")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersDelete, "user", user.ID, user, nil)
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) GetGroups(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionGroupsList) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to list groups")
+		return
+	}
+
+	startIndex, count := getPagingParameters(c)
+	f, err := getFilter(c)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	// retrieving members is relatively expensive, so skip it if the identity provider doesn't need them
+	excludeMembers := strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+
+	ctx := c.Request.Context()
+
+	groups, err := h.getGroups(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed retrieving groups from db")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	resources := make([]interface{}, 0)
+	for _, g := range groups {
+		members := []*contracts.User{}
+		if !excludeMembers {
+			members, err = h.getUsers(ctx, map[api.FilterType][]string{api.FilterGroupID: {g.ID}})
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed retrieving members for group %v from db", g.ID)
+				h.abortWithError(c, http.StatusInternalServerError, "", "")
+				return
+			}
+		}
+
+		scimGroup := toGroup(*g, members, h.provider(), h.baseURL())
+		isMatch, err := matchesFilter(f, scimGroup)
+		if err != nil {
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+		if isMatch {
+			resources = append(resources, scimGroup)
+		}
+	}
+
+	h.respond(c, http.StatusOK, getListResponse(resources, startIndex, count))
+}
+
+func (h *Handler) GetGroup(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionGroupsGet) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to get groups")
+		return
+	}
+
+	group, members, ok := h.getGroup(c)
+	if !ok {
+		return
+	}
+
+	h.respond(c, http.StatusOK, toGroup(*group, members, h.provider(), h.baseURL()))
+}
+
+func (h *Handler) CreateGroup(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionGroupsCreate) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to create groups")
+		return
+	}
+
+	var scimGroup Group
+	err := c.BindJSON(&scimGroup)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidSyntax", "Binding CreateGroup body failed")
+		return
+	}
+	if scimGroup.DisplayName == "" {
+		h.abortWithError(c, http.StatusBadRequest, "invalidValue", "Display name is required")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// display names have to be unique for provisioned groups
+	existingGroup, err := h.cockroachdbClient.GetGroupByIdentity(ctx, contracts.GroupIdentity{Provider: h.provider(), Name: scimGroup.DisplayName})
+	if err != nil && !errors.Is(err, cockroachdb.ErrGroupNotFound) {
+		log.Error().Err(err).Msg("Failed retrieving group by identity from db")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	if existingGroup != nil {
+		h.abortWithError(c, http.StatusConflict, "uniqueness", fmt.Sprintf("Group %v already exists", scimGroup.DisplayName))
+		return
+	}
+
+	members, ok := h.resolveMembers(c, scimGroup.memberIDs())
+	if !ok {
+		return
+	}
+
+	group := contracts.Group{}
+	applyToGroup(scimGroup, &group, h.provider())
+
+	insertedGroup, err := h.rbacService.CreateGroup(ctx, group)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed inserting group")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	err = h.updateGroupMembers(ctx, *insertedGroup, []*contracts.User{}, members)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed updating members of group %v", insertedGroup.ID)
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionGroupsCreate, "group", insertedGroup.ID, nil, toGroup(*insertedGroup, members, h.provider(), h.baseURL()))
+
+	h.respond(c, http.StatusCreated, toGroup(*insertedGroup, members, h.provider(), h.baseURL()))
+}
+
+func (h *Handler) UpdateGroup(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionGroupsUpdate) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to update groups")
+		return
+	}
+
+	group, currentMembers, ok := h.getGroup(c)
+	if !ok {
+		return
+	}
+
+	var scimGroup Group
+	err := c.BindJSON(&scimGroup)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidSyntax", "Binding UpdateGroup body failed")
+		return
+	}
+
+	h.saveGroup(c, group, currentMembers, scimGroup)
+}
+
+func (h *Handler) PatchGroup(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionGroupsUpdate) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to update groups")
+		return
+	}
+
+	group, currentMembers, ok := h.getGroup(c)
+	if !ok {
+		return
+	}
+
+	var patchRequest PatchRequest
+	err := c.BindJSON(&patchRequest)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidSyntax", "Binding PatchGroup body failed")
+		return
+	}
+
+	resource, err := toMap(toGroup(*group, currentMembers, h.provider(), h.baseURL()))
+	if err != nil {
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+	err = applyPatchOperations(resource, patchRequest.Operations)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidPath", err.Error())
+		return
+	}
+
+	var scimGroup Group
+	err = fromMap(resource, &scimGroup)
+	if err != nil {
+		h.abortWithError(c, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	h.saveGroup(c, group, currentMembers, scimGroup)
+}
+
+func (h *Handler) DeleteGroup(c *gin.Context) {
+
+	// ensure the request has the correct permission
+	if !api.RequestTokenHasPermission(c, api.PermissionGroupsDelete) {
+		h.abortWithError(c, http.StatusForbidden, "", "Client does not have permission to delete groups")
+		return
+	}
+
+	group, currentMembers, ok := h.getGroup(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// remove the group from its members first, so they don't refer to an inactive group
+	err := h.updateGroupMembers(ctx, *group, currentMembers, []*contracts.User{})
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed removing members of group %v", group.ID)
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	err = h.rbacService.DeleteGroup(ctx, group.ID)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed deleting group")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionGroupsDelete, "group", group.ID, toGroup(*group, currentMembers, h.provider(), h.baseURL()), nil)
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) saveUser(c *gin.Context, user *contracts.User, scimUser User, status int) {
+
+	ctx := c.Request.Context()
+
+	before := *user
+	before.Identities = make([]*contracts.UserIdentity, 0, len(user.Identities))
+	for _, i := range user.Identities {
+		identity := *i
+		before.Identities = append(before.Identities, &identity)
+	}
+
+	applyToUser(scimUser, user, h.provider())
+
+	err := h.rbacService.UpdateUser(ctx, *user)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed updating user")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	// deprovisioning deactivates the user, which revokes the roles granted to it and through its groups; updating an inactive user to active reactivates it
+	if user.Active != before.Active {
+		err = h.rbacService.SetUserActive(ctx, user.ID, user.Active)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed changing whether user is active")
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return
+		}
+	}
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionUsersUpdate, "user", user.ID, before, user)
+
+	h.respond(c, status, toUser(*user, h.provider(), h.baseURL()))
+}
+
+func (h *Handler) saveGroup(c *gin.Context, group *contracts.Group, currentMembers []*contracts.User, scimGroup Group) {
+
+	if scimGroup.DisplayName == "" {
+		h.abortWithError(c, http.StatusBadRequest, "invalidValue", "Display name is required")
+		return
+	}
+
+	members, ok := h.resolveMembers(c, scimGroup.memberIDs())
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	before := toGroup(*group, currentMembers, h.provider(), h.baseURL())
+
+	applyToGroup(scimGroup, group, h.provider())
+
+	err := h.rbacService.UpdateGroup(ctx, *group)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed updating group")
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	err = h.updateGroupMembers(ctx, *group, currentMembers, members)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed updating members of group %v", group.ID)
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return
+	}
+
+	after := toGroup(*group, members, h.provider(), h.baseURL())
+
+	audit.RecordRequestEvent(c, h.auditService, api.PermissionGroupsUpdate, "group", group.ID, before, after)
+
+	h.respond(c, http.StatusOK, after)
+}
+
+// resolveMembers retrieves the users for the member ids, so unknown members are rejected before anything is changed
+func (h *Handler) resolveMembers(c *gin.Context, ids []string) (members []*contracts.User, ok bool) {
+
+	members = make([]*contracts.User, 0)
+	for _, id := range ids {
+		user, err := h.cockroachdbClient.GetUserByID(c.Request.Context(), id, map[api.FilterType][]string{api.FilterActive: {"true", "false"}})
+		if err != nil {
+			if errors.Is(err, cockroachdb.ErrUserNotFound) {
+				h.abortWithError(c, http.StatusBadRequest, "invalidValue", fmt.Sprintf("%v: %v", ErrUnknownMember.Error(), id))
+				return nil, false
+			}
+			log.Error().Err(err).Msgf("Failed retrieving user %v from db", id)
+			h.abortWithError(c, http.StatusInternalServerError, "", "")
+			return nil, false
+		}
+		members = append(members, user)
+	}
+
+	return members, true
+}
+
+// updateGroupMembers links the group to new members and unlinks it from members that are no longer part of it; membership is stored with the user
+func (h *Handler) updateGroupMembers(ctx context.Context, group contracts.Group, currentMembers, members []*contracts.User) (err error) {
+
+	for _, u := range currentMembers {
+		if usersContain(members, u.ID) {
+			continue
+		}
+
+		groups := make([]*contracts.Group, 0)
+		for _, g := range u.Groups {
+			if g != nil && g.ID != group.ID {
+				groups = append(groups, g)
+			}
+		}
+		u.Groups = groups
+
+		err = h.rbacService.UpdateUser(ctx, *u)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, u := range members {
+		if usersContain(currentMembers, u.ID) {
+			continue
+		}
+
+		u.Groups = append(u.Groups, &contracts.Group{
+			ID:   group.ID,
+			Name: group.Name,
+		})
+
+		err = h.rbacService.UpdateUser(ctx, *u)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *Handler) getUser(c *gin.Context) (user *contracts.User, ok bool) {
+
+	id := c.Param("id")
+
+	user, err := h.cockroachdbClient.GetUserByID(c.Request.Context(), id, map[api.FilterType][]string{api.FilterActive: {"true", "false"}})
+	if err != nil {
+		if errors.Is(err, cockroachdb.ErrUserNotFound) {
+			h.abortWithError(c, http.StatusNotFound, "", fmt.Sprintf("User %v not found", id))
+			return nil, false
+		}
+		log.Error().Err(err).Msgf("Failed retrieving user %v from db", id)
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return nil, false
+	}
+
+	return user, true
+}
+
+func (h *Handler) getGroup(c *gin.Context) (group *contracts.Group, members []*contracts.User, ok bool) {
+
+	id := c.Param("id")
+	ctx := c.Request.Context()
+
+	group, err := h.cockroachdbClient.GetGroupByID(ctx, id, map[api.FilterType][]string{})
+	if err != nil {
+		if errors.Is(err, cockroachdb.ErrGroupNotFound) {
+			h.abortWithError(c, http.StatusNotFound, "", fmt.Sprintf("Group %v not found", id))
+			return nil, nil, false
+		}
+		log.Error().Err(err).Msgf("Failed retrieving group %v from db", id)
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return nil, nil, false
+	}
+
+	members, err = h.getUsers(ctx, map[api.FilterType][]string{api.FilterGroupID: {group.ID}, api.FilterActive: {"true", "false"}})
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed retrieving members for group %v from db", group.ID)
+		h.abortWithError(c, http.StatusInternalServerError, "", "")
+		return nil, nil, false
+	}
+
+	return group, members, true
+}
+
+// getUserByEmail retrieves the user with an identity for the provisioning provider with the email address, whether active or not; it returns nil if there's no such user
+func (h *Handler) getUserByEmail(ctx context.Context, email string) (user *contracts.User, err error) {
+
+	user, err = h.cockroachdbClient.GetUserByIdentity(ctx, contracts.UserIdentity{Provider: h.provider(), Email: email})
+	if err == nil || !errors.Is(err, cockroachdb.ErrUserNotFound) {
+		return user, err
+	}
+
+	// the identity lookup only finds active users, so look for a deactivated one as well
+	inactiveUsers, err := h.getUsers(ctx, map[api.FilterType][]string{api.FilterActive: {"false"}, api.FilterIdentityEmail: {email}})
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range inactiveUsers {
+		if identity := getUserIdentity(*u, h.provider()); identity != nil && strings.EqualFold(identity.Email, email) {
+			return u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getUsers retrieves all users matching the database filters; scim filters are applied in memory since they can refer to any attribute
+func (h *Handler) getUsers(ctx context.Context, filters map[api.FilterType][]string) (users []*contracts.User, err error) {
+
+	users = make([]*contracts.User, 0)
+
+	pageNumber, pageSize := 1, 100
+	for {
+		pagedUsers, err := h.cockroachdbClient.GetUsers(ctx, pageNumber, pageSize, filters, []api.OrderField{})
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, pagedUsers...)
+		if len(pagedUsers) < pageSize {
+			break
+		}
+		pageNumber++
+	}
+
+	return users, nil
+}
+
+func (h *Handler) getGroups(ctx context.Context) (groups []*contracts.Group, err error) {
+
+	groups = make([]*contracts.Group, 0)
+
+	pageNumber, pageSize := 1, 100
+	for {
+		pagedGroups, err := h.cockroachdbClient.GetGroups(ctx, pageNumber, pageSize, map[api.FilterType][]string{}, []api.OrderField{})
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, pagedGroups...)
+		if len(pagedGroups) < pageSize {
+			break
+		}
+		pageNumber++
+	}
+
+	return groups, nil
+}
+
+func (h *Handler) provider() string {
+	if h.config == nil || h.config.Auth == nil {
+		return (*api.SCIMConfig)(nil).GetIdentityProvider()
+	}
+
+	return h.config.Auth.SCIM.GetIdentityProvider()
+}
+
+func (h *Handler) baseURL() string {
+	if h.config == nil || h.config.APIServer == nil {
+		return ""
+	}
+
+	return h.config.APIServer.BaseURL
+}
+
+func (h *Handler) respond(c *gin.Context, status int, body interface{}) {
+	c.Header("Content-Type", contentType)
+	c.JSON(status, body)
+}
+
+func (h *Handler) abortWithError(c *gin.Context, status int, scimType, detail string) {
+	if detail == "" {
+		detail = http.StatusText(status)
+	}
+
+	c.Header("Content-Type", contentType)
+	c.AbortWithStatusJSON(status, Error{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// getPagingParameters returns the 1-based start index and the page size
+func getPagingParameters(c *gin.Context) (startIndex, count int) {
+
+	startIndex, err := strconv.Atoi(c.DefaultQuery("startIndex", "1"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	count, err = strconv.Atoi(c.DefaultQuery("count", strconv.Itoa(defaultCount)))
+	if err != nil || count < 0 {
+		count = defaultCount
+	}
+	if count > maxCount {
+		count = maxCount
+	}
+
+	return
+}
+
+func getFilter(c *gin.Context) (f filter, err error) {
+	filterValue := strings.TrimSpace(c.Query("filter"))
+	if filterValue == "" {
+		return nil, nil
+	}
+
+	return parseFilter(filterValue)
+}
+
+func matchesFilter(f filter, resource interface{}) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+
+	resourceMap, err := toMap(resource)
+	if err != nil {
+		return false, err
+	}
+
+	return f.matches(resourceMap), nil
+}
+
+func getListResponse(resources []interface{}, startIndex, count int) ListResponse {
+
+	pagedResources := make([]interface{}, 0)
+	if startIndex-1 < len(resources) {
+		end := startIndex - 1 + count
+		if end > len(resources) {
+			end = len(resources)
+		}
+		pagedResources = resources[startIndex-1 : end]
+	}
+
+	return ListResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(pagedResources),
+		Resources:    pagedResources,
+	}
+}
+
+func usersContain(users []*contracts.User, id string) bool {
+	for _, u := range users {
+		if u != nil && u.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
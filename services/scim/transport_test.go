@@ -0,0 +1,150 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/estafette/estafette-ci-api/api"
+	"github.com/estafette/estafette-ci-api/clients/cockroachdb"
+	"github.com/estafette/estafette-ci-api/services/audit"
+	"github.com/estafette/estafette-ci-api/services/rbac"
+	contracts "github.com/estafette/estafette-ci-contracts"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatchUser(t *testing.T) {
+
+	t.Run("ReactivatesDeactivatedUser", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				if len(filters[api.FilterActive]) != 2 {
+					return nil, cockroachdb.ErrUserNotFound
+				}
+				return &contracts.User{ID: id, Active: false, Identities: []*contracts.UserIdentity{{Provider: "scim", Email: "me@estafette.io"}}}, nil
+			},
+		}
+		activatedUserIDs := []string{}
+		rbacService := rbac.MockService{
+			SetUserActiveFunc: func(ctx context.Context, id string, active bool) (err error) {
+				if active {
+					activatedUserIDs = append(activatedUserIDs, id)
+				}
+				return nil
+			},
+		}
+		handler := NewHandler(&api.APIConfig{}, rbacService, cockroachdbClient, audit.MockService{})
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, "PATCH", "/scim/v2/Users/5", `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"active","value":true}]}`)
+		c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+		// act
+		handler.PatchUser(c)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, []string{"5"}, activatedUserIDs)
+	})
+
+	t.Run("DoesNotChangeActiveStateIfPatchLeavesItOut", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIDFunc: func(ctx context.Context, id string, filters map[api.FilterType][]string) (user *contracts.User, err error) {
+				return &contracts.User{ID: id, Active: true, Identities: []*contracts.UserIdentity{{Provider: "scim", Email: "me@estafette.io"}}}, nil
+			},
+		}
+		rbacService := rbac.MockService{
+			SetUserActiveFunc: func(ctx context.Context, id string, active bool) (err error) {
+				assert.Fail(t, "Active state shouldn't change")
+				return nil
+			},
+		}
+		handler := NewHandler(&api.APIConfig{}, rbacService, cockroachdbClient, audit.MockService{})
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, "PATCH", "/scim/v2/Users/5", `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","path":"displayName","value":"Me"}]}`)
+		c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+		// act
+		handler.PatchUser(c)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestCreateUser(t *testing.T) {
+
+	t.Run("ReactivatesDeactivatedUserWithSameEmailInsteadOfCreatingDuplicate", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIdentityFunc: func(ctx context.Context, identity contracts.UserIdentity) (user *contracts.User, err error) {
+				return nil, cockroachdb.ErrUserNotFound
+			},
+			GetUsersFunc: func(ctx context.Context, pageNumber, pageSize int, filters map[api.FilterType][]string, sortings []api.OrderField) (users []*contracts.User, err error) {
+				assert.Equal(t, []string{"me@estafette.io"}, filters[api.FilterIdentityEmail])
+				return []*contracts.User{{ID: "5", Active: false, Identities: []*contracts.UserIdentity{{Provider: "scim", Email: "me@estafette.io"}}}}, nil
+			},
+		}
+		var updatedUser *contracts.User
+		activatedUserIDs := []string{}
+		rbacService := rbac.MockService{
+			CreateUserFunc: func(ctx context.Context, user contracts.User) (insertedUser *contracts.User, err error) {
+				assert.Fail(t, "User shouldn't be created again")
+				return &user, nil
+			},
+			UpdateUserFunc: func(ctx context.Context, user contracts.User) (err error) {
+				updatedUser = &user
+				return nil
+			},
+			SetUserActiveFunc: func(ctx context.Context, id string, active bool) (err error) {
+				if active {
+					activatedUserIDs = append(activatedUserIDs, id)
+				}
+				return nil
+			},
+		}
+		handler := NewHandler(&api.APIConfig{}, rbacService, cockroachdbClient, audit.MockService{})
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, "POST", "/scim/v2/Users", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"me@estafette.io","active":true}`)
+
+		// act
+		handler.CreateUser(c)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		if assert.NotNil(t, updatedUser) {
+			assert.Equal(t, "5", updatedUser.ID)
+		}
+		assert.Equal(t, []string{"5"}, activatedUserIDs)
+	})
+
+	t.Run("ReturnsConflictForActiveUserWithSameEmail", func(t *testing.T) {
+
+		cockroachdbClient := cockroachdb.MockClient{
+			GetUserByIdentityFunc: func(ctx context.Context, identity contracts.UserIdentity) (user *contracts.User, err error) {
+				return &contracts.User{ID: "5", Active: true}, nil
+			},
+		}
+		handler := NewHandler(&api.APIConfig{}, rbac.MockService{}, cockroachdbClient, audit.MockService{})
+		recorder := httptest.NewRecorder()
+		c := getContext(recorder, "POST", "/scim/v2/Users", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"me@estafette.io"}`)
+
+		// act
+		handler.CreateUser(c)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+	})
+}
+
+func getContext(recorder *httptest.ResponseRecorder, method, path, body string) *gin.Context {
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(method, "https://ci.estafette.io"+path, strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/scim+json")
+	c.Set("JWT_PAYLOAD", jwt.MapClaims{
+		jwt.IdentityKey: "scim-client",
+		"roles":         []interface{}{"administrator"},
+	})
+	return c
+}